@@ -33,16 +33,37 @@ type Application struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
+	// Description is a human readable explanation of the application, copied to generated experiments.
+	Description string `json:"description,omitempty"`
+	// Owner identifies the person or team responsible for the application, copied to generated experiments.
+	Owner string `json:"owner,omitempty"`
+	// TicketURL is a link to the issue or ticket tracking the optimization, copied to generated experiments.
+	TicketURL string `json:"ticketURL,omitempty"`
+
 	// Resources are references to application resources to consider in the generation of the experiment.
-	// These strings are the same format as used by Kustomize.
+	// These strings are the same format as used by Kustomize. Resources may span multiple namespaces (e.g. a
+	// front-end and a back-end), resources without a namespace are assumed to be in the application namespace.
 	Resources konjure.Resources `json:"resources,omitempty"`
 
+	// Excludes are patterns matching resources which should be ignored in the generation of the experiment.
+	Excludes []ResourceExclusion `json:"excludes,omitempty"`
+
 	// Parameters specifies additional details about the experiment parameters.
 	Parameters []Parameter `json:"parameters,omitempty"`
 
 	// Ingress specifies how to find the entry point to the application.
 	Ingress *Ingress `json:"ingress,omitempty"`
 
+	// Credentials references secrets needed to test or measure the application.
+	Credentials []Credential `json:"credentials,omitempty"`
+
+	// ImageRegistry replaces the registry of the trial and setup task images, e.g. when the images are mirrored
+	// for use in an air-gapped cluster.
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// ImagePullSecrets are references to secrets used to pull the trial and setup task images.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// The list of scenarios to optimize the application for.
 	Scenarios []Scenario `json:"scenarios,omitempty"`
 
@@ -51,6 +72,12 @@ type Application struct {
 
 	// StormForger allows you to configure StormForger to apply load on your application.
 	StormForger *StormForger `json:"stormForger,omitempty"`
+
+	// Prometheus allows you to configure the built-in Prometheus used to measure your application.
+	Prometheus *Prometheus `json:"prometheus,omitempty"`
+
+	// TrialNamespace constrains the resources available in the namespace the trials run in.
+	TrialNamespace *TrialNamespace `json:"trialNamespace,omitempty"`
 }
 
 // Parameter describes the strategy for tuning the application.
@@ -61,21 +88,49 @@ type Parameter struct {
 	Replicas *Replicas `json:"replicas,omitempty"`
 	// Information related to the discovery of environment variables.
 	EnvironmentVariable *EnvironmentVariable `json:"environmentVariable,omitempty"`
+	// Information related to the discovery of Java virtual machine options.
+	JVM *JVM `json:"jvm,omitempty"`
+	// Information related to the discovery of ConfigMap keys.
+	ConfigMap *ConfigMap `json:"configMap,omitempty"`
 }
 
 // ContainerResources specifies which resources in the application should have their container
-// resources (CPU and memory) optimized.
+// resources (e.g. CPU and memory) optimized.
 type ContainerResources struct {
 	// Label selector of Kubernetes objects to consider when generating container resources patches.
 	Selector string `json:"selector,omitempty"`
-	// The names of the resources to optimize. Defaults to ["memory", "cpu"].
+	// The names of the resources to optimize. Defaults to ["memory", "cpu"]. Extended resources
+	// (e.g. "nvidia.com/gpu") and huge pages always have equal requests and limits.
 	Resources []corev1.ResourceName `json:"resources,omitempty"`
+	// The resource requirements to optimize. Can be one of the following values: `requests`, `limits`
+	// or `both`. Defaults to `both`.
+	Mode ContainerResourcesMode `json:"mode,omitempty"`
+	// The ratio of limits to requests for each resource when optimizing both. By default, limits and
+	// requests are set to the same value.
+	LimitRequestRatio corev1.ResourceList `json:"limitRequestRatio,omitempty"`
+	// The maximum total of each resource across all of the matched containers, accounting for the
+	// current replica count of each workload. For example, `cpu: 4` will keep the combined CPU of
+	// the optimized containers at or below 4 cores.
+	MaxTotal corev1.ResourceList `json:"maxTotal,omitempty"`
 }
 
+// ContainerResourcesMode describes which container resource requirements should be optimized.
+type ContainerResourcesMode string
+
+const (
+	ContainerResourcesRequests ContainerResourcesMode = "requests"
+	ContainerResourcesLimits   ContainerResourcesMode = "limits"
+	ContainerResourcesBoth     ContainerResourcesMode = "both"
+)
+
 // Replicas specifies which resources in the application should have their replica count optimized.
 type Replicas struct {
 	// Label selector of Kubernetes objects to consider when generating replica patches.
 	Selector string `json:"selector,omitempty"`
+	// The minimum number of replicas to consider. Defaults to 1.
+	Min int32 `json:"min,omitempty"`
+	// The maximum number of replicas to consider. Defaults to 5 or the current replica count, whichever is larger.
+	Max int32 `json:"max,omitempty"`
 }
 
 // EnvironmentVariable specifies which environment variables in the application should have their value optimized.
@@ -92,12 +147,86 @@ type EnvironmentVariable struct {
 	Values []string `json:"values,omitempty"`
 }
 
+// JVM specifies which Java workloads in the application should have their heap size and garbage collector optimized.
+type JVM struct {
+	// Label selector of Kubernetes objects to consider when looking for Java containers.
+	Selector string `json:"selector,omitempty"`
+	// The name of the environment variable used to pass options to the JVM. Defaults to
+	// "JAVA_OPTS" if the container already defines it, otherwise "JAVA_TOOL_OPTIONS".
+	VariableName string `json:"variableName,omitempty"`
+	// The garbage collectors to consider. Defaults to ["G1GC", "ParallelGC", "SerialGC"].
+	GarbageCollectors []string `json:"garbageCollectors,omitempty"`
+}
+
+// ConfigMap specifies which keys of a ConfigMap in the application should have their value optimized. Workloads
+// using the ConfigMap are restarted when the optimized values change.
+type ConfigMap struct {
+	// Label selector of Kubernetes objects to consider when looking for the ConfigMap and the workloads using it.
+	Selector string `json:"selector,omitempty"`
+	// The name of the ConfigMap.
+	Name string `json:"name"`
+	// The keys of the ConfigMap to optimize.
+	Keys []ConfigMapKey `json:"keys,omitempty"`
+}
+
+// ConfigMapKey specifies how the value of a single ConfigMap key should be optimized.
+type ConfigMapKey struct {
+	// The key of the ConfigMap data to optimize.
+	Key string `json:"key"`
+	// The minimum value of a numeric key. Defaults to half the current value.
+	Min int32 `json:"min,omitempty"`
+	// The maximum value of a numeric key. Defaults to twice the current value.
+	Max int32 `json:"max,omitempty"`
+	// The discrete values of a categorical key.
+	Values []string `json:"values,omitempty"`
+	// The suffix of the value when the key is numeric, e.g. "MB" for a value of "128MB".
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// ResourceExclusion describes resources which should not be considered during generation. A resource
+// is excluded only if it matches all of the specified fields, an exclusion with no fields is ignored.
+type ResourceExclusion struct {
+	// Regular expression matching the API group of the resource.
+	Group string `json:"group,omitempty"`
+	// Regular expression matching the kind of the resource.
+	Kind string `json:"kind,omitempty"`
+	// Regular expression matching the name of the resource.
+	Name string `json:"name,omitempty"`
+	// Regular expression matching the namespace of the resource.
+	Namespace string `json:"namespace,omitempty"`
+	// Label selector matching the resource.
+	Selector string `json:"selector,omitempty"`
+}
+
 // Ingress describes the point of ingress to the application.
 type Ingress struct {
 	// The URL used to access the application from outside the cluster.
 	URL string `json:"url,omitempty"`
 }
 
+// CredentialUsage describes how a credential is used by the generated experiment.
+type CredentialUsage string
+
+const (
+	// CredentialTrialJob credentials are exposed to the trial job containers as an environment variable.
+	CredentialTrialJob CredentialUsage = "trialJob"
+	// CredentialMetrics credentials are used to authenticate metric queries to external services.
+	CredentialMetrics CredentialUsage = "metrics"
+)
+
+// Credential references a secret containing a value needed to test or measure the application, for example
+// a password for the ingress basic authentication, a load test API token or a key for the metrics provider.
+type Credential struct {
+	// The name of the environment variable used to expose the credential to the trial job.
+	Name string `json:"name,omitempty"`
+	// How the credential is used, one of: `trialJob` (default) or `metrics`.
+	Usage CredentialUsage `json:"usage,omitempty"`
+	// Reference to the secret key containing the credential. Metric credentials only use the secret name, the
+	// keys depend on the metric type: "token" for InfluxDB, "api-key" and "app-key" for Datadog or "token" (or
+	// "username" and "password") for Prometheus.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
 // Scenario describes a specific pattern of load to optimize the application for.
 type Scenario struct {
 	// The name of scenario.
@@ -108,6 +237,12 @@ type Scenario struct {
 	Locust *LocustScenario `json:"locust,omitempty"`
 	// Custom configuration for the scenario.
 	Custom *CustomScenario `json:"custom,omitempty"`
+	// Placement of the trial job pods for the scenario.
+	Placement *Placement `json:"placement,omitempty"`
+	// Compute resources for each of the generated trial job containers.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Compute resources for each of the setup task containers.
+	SetupResources *corev1.ResourceRequirements `json:"setupResources,omitempty"`
 }
 
 // StormForgerScenario is used to generate load using StormForger.
@@ -136,7 +271,10 @@ type LocustScenario struct {
 type CustomScenario struct {
 	// Enables Prometheus Push Gateway support for objectives that require it.
 	// The `PUSHGATEWAY_URL` environment variable will be added to all
-	// containers when the trial job starts.
+	// containers when the trial job starts. Latency and error rate goals
+	// query the same samples pushed by Locust (e.g. "p95", "failure_count"
+	// and "request_count"); goals without any configuration query a sample
+	// with the same name as the goal.
 	UsePushGateway bool `json:"pushGateway,omitempty"`
 	// The default specification of a pod to use for executing a trial.
 	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
@@ -148,6 +286,29 @@ type CustomScenario struct {
 	Image string `json:"image,omitempty"`
 }
 
+// Placement controls which nodes the trial job pods are scheduled on, for example to keep load generators
+// away from the workload under test.
+type Placement struct {
+	// Node labels the trial job pods must match to be scheduled.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations allowing the trial job pods to be scheduled on tainted nodes.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity scheduling rules for the trial job pods.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Constraints describing how the trial job pods are spread across topology domains.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// TrialNamespace describes the resource constraints of the namespace the trials run in, for example to protect a
+// shared cluster from runaway load generators. The constraints are added to each namespace created for the trials; if
+// the application resources already include a quota or limit range, it is validated against these constraints instead.
+type TrialNamespace struct {
+	// ResourceQuota limits the aggregate resource consumption of the trial namespace.
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+	// LimitRange constrains the resources of the individual pods and containers in the trial namespace.
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+}
+
 // Objective describes the goals of the optimization in terms of specific metrics.
 type Objective struct {
 	// The name of the objective. If omitted, a default name will be generated
@@ -167,8 +328,15 @@ type Goal struct {
 	Max *resource.Quantity `json:"max,omitempty"`
 	// The lower bound for the objective.
 	Min *resource.Quantity `json:"min,omitempty"`
+	// The value to optimize toward, e.g. a latency of "200m" instead of the lowest possible latency.
+	Target *resource.Quantity `json:"target,omitempty"`
 	// Flag indicating that this objective should optimized instead of monitored (default: true).
 	Optimize *bool `json:"optimize,omitempty"`
+	// The limit for intermediate samples of the goal, a running trial is failed as soon as a sample is worse than
+	// the limit. Intermediate samples are only collected when a sample interval is also specified.
+	FailFast *resource.Quantity `json:"failFast,omitempty"`
+	// The amount of time between intermediate samples of the goal while the trial is running.
+	SampleInterval *metav1.Duration `json:"sampleInterval,omitempty"`
 
 	// Requests is used to optimize the resources consumed by an application.
 	Requests *RequestsGoal `json:"requests,omitempty"`
@@ -281,6 +449,21 @@ type DatadogGoal struct {
 	Maximize bool `json:"maximize,omitempty"`
 }
 
+// Prometheus describes the configuration of the built-in Prometheus.
+type Prometheus struct {
+	// The URL of an existing Prometheus to use instead of deploying a built-in Prometheus for each trial, e.g.
+	// "http://prometheus-operated.monitoring:9090". When set, the remaining configuration is ignored.
+	URL string `json:"url,omitempty"`
+	// The interval at which metrics are scraped.
+	ScrapeInterval *metav1.Duration `json:"scrapeInterval,omitempty"`
+	// How long metrics are retained.
+	Retention *metav1.Duration `json:"retention,omitempty"`
+	// The compute resources of the Prometheus server.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Additional scrape configurations, a YAML list in the Prometheus "scrape_configs" format.
+	ExtraScrapeConfigs string `json:"extraScrapeConfigs,omitempty"`
+}
+
 // StormForger describes global configuration related to StormForger.
 type StormForger struct {
 	// The name of the StormForger organization.
@@ -30,4 +30,8 @@ const (
 
 	// AnnotationLastScanned is the timestamp of the last application scan.
 	AnnotationLastScanned = "apps.stormforge.io/last-scanned"
+
+	// AnnotationConfigMapPrefix is the prefix of the pod template annotation used to trigger a
+	// rollout when the optimized values of a ConfigMap change; the ConfigMap name is appended.
+	AnnotationConfigMapPrefix = "apps.stormforge.io/configmap-"
 )
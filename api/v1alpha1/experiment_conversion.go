@@ -73,6 +73,21 @@ func Convert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(in *v1beta1.Exper
 	return autoConvert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(in, out, s)
 }
 
+func Convert_v1beta1_Constraint_To_v1alpha1_Constraint(in *v1beta1.Constraint, out *Constraint, s conversion.Scope) error {
+	// v1alpha1 only had order and sum constraints, expression constraints are dropped
+	return autoConvert_v1beta1_Constraint_To_v1alpha1_Constraint(in, out, s)
+}
+
+func Convert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(in *v1beta1.ExperimentStatus, out *ExperimentStatus, s conversion.Scope) error {
+	// v1alpha1 only tracks the phase, active trial count and conditions; everything else is recomputed by the controller
+	return autoConvert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(in, out, s)
+}
+
+func Convert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(in *v1beta1.NamespaceTemplateSpec, out *NamespaceTemplateSpec, s conversion.Scope) error {
+	// v1alpha1 did not create supporting namespace objects or delete namespaces
+	return autoConvert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(in, out, s)
+}
+
 func Convert_v1alpha1_Parameter_To_v1beta1_Parameter(in *Parameter, out *v1beta1.Parameter, s conversion.Scope) error {
 	err := autoConvert_v1alpha1_Parameter_To_v1beta1_Parameter(in, out, s)
 	if err != nil {
@@ -94,9 +109,15 @@ func Convert_v1beta1_Parameter_To_v1alpha1_Parameter(in *v1beta1.Parameter, out
 		return err
 	}
 
+	// v1alpha1 didn't have boolean, represent it as a categorical
+	if in.Type == v1beta1.ParameterTypeBoolean {
+		out.Values = in.GetValues()
+		out.Baseline = in.GetBaseline()
+	}
+
 	// v1alpha1 didn't have categorical (it's only there for round tripping) enforce it via a max
-	if len(in.Values) > 0 {
-		out.Max = int32(len(in.Values) - 1)
+	if len(out.Values) > 0 {
+		out.Max = int32(len(out.Values) - 1)
 	}
 
 	return nil
@@ -110,6 +110,27 @@ func Convert_v1alpha1_TrialSpec_To_v1beta1_TrialSpec(in *TrialSpec, out *v1beta1
 	return autoConvert_v1alpha1_TrialSpec_To_v1beta1_TrialSpec(in, out, s)
 }
 
+func Convert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(in *v1beta1.ReadinessCheck, out *ReadinessCheck, s conversion.Scope) error {
+	// NOTE: The readiness probe is dropped, only the conditions are checked
+
+	// Continue
+	return autoConvert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(in, out, s)
+}
+
+func Convert_v1beta1_SetupTask_To_v1alpha1_SetupTask(in *v1beta1.SetupTask, out *SetupTask, s conversion.Scope) error {
+	// NOTE: The built-in Prometheus configuration is dropped, the default configuration will be used
+
+	// Continue
+	return autoConvert_v1beta1_SetupTask_To_v1alpha1_SetupTask(in, out, s)
+}
+
+func Convert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(in *v1beta1.TrialReadinessGate, out *TrialReadinessGate, s conversion.Scope) error {
+	// NOTE: The readiness probe is dropped, only the conditions are checked
+
+	// Continue
+	return autoConvert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(in, out, s)
+}
+
 func Convert_v1beta1_TrialSpec_To_v1alpha1_TrialSpec(in *v1beta1.TrialSpec, out *TrialSpec, s conversion.Scope) error {
 	// Rename `JobTemplate` to `Template`
 	out.Template = in.JobTemplate
@@ -60,11 +60,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Constraint)(nil), (*Constraint)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Constraint_To_v1alpha1_Constraint(a.(*v1beta1.Constraint), b.(*Constraint), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Experiment)(nil), (*v1beta1.Experiment)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Experiment_To_v1beta1_Experiment(a.(*Experiment), b.(*v1beta1.Experiment), scope)
 	}); err != nil {
@@ -100,11 +95,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.ExperimentStatus)(nil), (*ExperimentStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(a.(*v1beta1.ExperimentStatus), b.(*ExperimentStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*HelmValue)(nil), (*v1beta1.HelmValue)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_HelmValue_To_v1beta1_HelmValue(a.(*HelmValue), b.(*v1beta1.HelmValue), scope)
 	}); err != nil {
@@ -140,11 +130,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.NamespaceTemplateSpec)(nil), (*NamespaceTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(a.(*v1beta1.NamespaceTemplateSpec), b.(*NamespaceTemplateSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Optimization)(nil), (*v1beta1.Optimization)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_Optimization_To_v1beta1_Optimization(a.(*Optimization), b.(*v1beta1.Optimization), scope)
 	}); err != nil {
@@ -210,21 +195,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.ReadinessCheck)(nil), (*ReadinessCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(a.(*v1beta1.ReadinessCheck), b.(*ReadinessCheck), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*SetupTask)(nil), (*v1beta1.SetupTask)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_SetupTask_To_v1beta1_SetupTask(a.(*SetupTask), b.(*v1beta1.SetupTask), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.SetupTask)(nil), (*SetupTask)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_SetupTask_To_v1alpha1_SetupTask(a.(*v1beta1.SetupTask), b.(*SetupTask), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*SumConstraint)(nil), (*v1beta1.SumConstraint)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_SumConstraint_To_v1beta1_SumConstraint(a.(*SumConstraint), b.(*v1beta1.SumConstraint), scope)
 	}); err != nil {
@@ -270,11 +245,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.TrialReadinessGate)(nil), (*TrialReadinessGate)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(a.(*v1beta1.TrialReadinessGate), b.(*TrialReadinessGate), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*TrialStatus)(nil), (*v1beta1.TrialStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_TrialStatus_To_v1beta1_TrialStatus(a.(*TrialStatus), b.(*v1beta1.TrialStatus), scope)
 	}); err != nil {
@@ -325,21 +295,51 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.Constraint)(nil), (*Constraint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Constraint_To_v1alpha1_Constraint(a.(*v1beta1.Constraint), b.(*Constraint), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.ExperimentSpec)(nil), (*ExperimentSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(a.(*v1beta1.ExperimentSpec), b.(*ExperimentSpec), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.ExperimentStatus)(nil), (*ExperimentStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(a.(*v1beta1.ExperimentStatus), b.(*ExperimentStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.Metric)(nil), (*Metric)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_Metric_To_v1alpha1_Metric(a.(*v1beta1.Metric), b.(*Metric), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.NamespaceTemplateSpec)(nil), (*NamespaceTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(a.(*v1beta1.NamespaceTemplateSpec), b.(*NamespaceTemplateSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.Parameter)(nil), (*Parameter)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_Parameter_To_v1alpha1_Parameter(a.(*v1beta1.Parameter), b.(*Parameter), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.ReadinessCheck)(nil), (*ReadinessCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(a.(*v1beta1.ReadinessCheck), b.(*ReadinessCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.SetupTask)(nil), (*SetupTask)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_SetupTask_To_v1alpha1_SetupTask(a.(*v1beta1.SetupTask), b.(*SetupTask), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.TrialReadinessGate)(nil), (*TrialReadinessGate)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(a.(*v1beta1.TrialReadinessGate), b.(*TrialReadinessGate), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.TrialSpec)(nil), (*TrialSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_TrialSpec_To_v1alpha1_TrialSpec(a.(*v1beta1.TrialSpec), b.(*TrialSpec), scope)
 	}); err != nil {
@@ -448,14 +448,10 @@ func autoConvert_v1beta1_Constraint_To_v1alpha1_Constraint(in *v1beta1.Constrain
 	} else {
 		out.Sum = nil
 	}
+	// WARNING: in.Expression requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_Constraint_To_v1alpha1_Constraint is an autogenerated conversion function.
-func Convert_v1beta1_Constraint_To_v1alpha1_Constraint(in *v1beta1.Constraint, out *Constraint, s conversion.Scope) error {
-	return autoConvert_v1beta1_Constraint_To_v1alpha1_Constraint(in, out, s)
-}
-
 func autoConvert_v1alpha1_Experiment_To_v1beta1_Experiment(in *Experiment, out *v1beta1.Experiment, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_ExperimentSpec_To_v1beta1_ExperimentSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -633,7 +629,13 @@ func autoConvert_v1alpha1_ExperimentSpec_To_v1beta1_ExperimentSpec(in *Experimen
 }
 
 func autoConvert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(in *v1beta1.ExperimentSpec, out *ExperimentSpec, s conversion.Scope) error {
+	// WARNING: in.Description requires manual conversion: does not exist in peer-type
+	// WARNING: in.Owner requires manual conversion: does not exist in peer-type
+	// WARNING: in.TicketURL requires manual conversion: does not exist in peer-type
+	// WARNING: in.DependsOn requires manual conversion: does not exist in peer-type
 	out.Replicas = in.Replicas
+	// WARNING: in.MaxConcurrentTrials requires manual conversion: does not exist in peer-type
+	// WARNING: in.Repetitions requires manual conversion: does not exist in peer-type
 	if in.Optimization != nil {
 		in, out := &in.Optimization, &out.Optimization
 		*out = make([]Optimization, len(*in))
@@ -645,6 +647,7 @@ func autoConvert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(in *v1beta1.E
 	} else {
 		out.Optimization = nil
 	}
+	// WARNING: in.LocalOptimizer requires manual conversion: does not exist in peer-type
 	if in.Parameters != nil {
 		in, out := &in.Parameters, &out.Parameters
 		*out = make([]Parameter, len(*in))
@@ -678,6 +681,8 @@ func autoConvert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(in *v1beta1.E
 	} else {
 		out.Metrics = nil
 	}
+	// WARNING: in.ValueWebhook requires manual conversion: does not exist in peer-type
+	// WARNING: in.Derived requires manual conversion: does not exist in peer-type
 	if in.Patches != nil {
 		in, out := &in.Patches, &out.Patches
 		*out = make([]PatchTemplate, len(*in))
@@ -700,6 +705,9 @@ func autoConvert_v1beta1_ExperimentSpec_To_v1alpha1_ExperimentSpec(in *v1beta1.E
 		out.NamespaceTemplate = nil
 	}
 	out.Selector = in.Selector
+	// WARNING: in.TrialNaming requires manual conversion: does not exist in peer-type
+	// WARNING: in.TrialRetention requires manual conversion: does not exist in peer-type
+	// WARNING: in.Schedule requires manual conversion: does not exist in peer-type
 	// WARNING: in.TrialTemplate requires manual conversion: does not exist in peer-type
 	return nil
 }
@@ -729,6 +737,9 @@ func Convert_v1alpha1_ExperimentStatus_To_v1beta1_ExperimentStatus(in *Experimen
 func autoConvert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(in *v1beta1.ExperimentStatus, out *ExperimentStatus, s conversion.Scope) error {
 	out.Phase = in.Phase
 	out.ActiveTrials = in.ActiveTrials
+	// WARNING: in.CompletedTrials requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailedTrials requires manual conversion: does not exist in peer-type
+	// WARNING: in.BestValues requires manual conversion: does not exist in peer-type
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]ExperimentCondition, len(*in))
@@ -740,14 +751,10 @@ func autoConvert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(in *v1bet
 	} else {
 		out.Conditions = nil
 	}
+	// WARNING: in.ServerSyncFailures requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus is an autogenerated conversion function.
-func Convert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(in *v1beta1.ExperimentStatus, out *ExperimentStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_ExperimentStatus_To_v1alpha1_ExperimentStatus(in, out, s)
-}
-
 func autoConvert_v1alpha1_HelmValue_To_v1beta1_HelmValue(in *HelmValue, out *v1beta1.HelmValue, s conversion.Scope) error {
 	out.Name = in.Name
 	out.ForceString = in.ForceString
@@ -883,11 +890,22 @@ func autoConvert_v1beta1_Metric_To_v1alpha1_Metric(in *v1beta1.Metric, out *Metr
 	out.Minimize = in.Minimize
 	out.Min = in.Min
 	out.Max = in.Max
+	// WARNING: in.TargetValue requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailFast requires manual conversion: does not exist in peer-type
 	out.Optimize = in.Optimize
 	out.Type = MetricType(in.Type)
 	out.Query = in.Query
 	out.ErrorQuery = in.ErrorQuery
+	// WARNING: in.Aggregation requires manual conversion: does not exist in peer-type
+	// WARNING: in.RepetitionAggregation requires manual conversion: does not exist in peer-type
 	// WARNING: in.URL requires manual conversion: does not exist in peer-type
+	// WARNING: in.HTTP requires manual conversion: does not exist in peer-type
+	// WARNING: in.TLS requires manual conversion: does not exist in peer-type
+	// WARNING: in.Retries requires manual conversion: does not exist in peer-type
+	// WARNING: in.RetryDelay requires manual conversion: does not exist in peer-type
+	// WARNING: in.Timeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.SampleInterval requires manual conversion: does not exist in peer-type
+	// WARNING: in.Timestamps requires manual conversion: does not exist in peer-type
 	// WARNING: in.Target requires manual conversion: does not exist in peer-type
 	return nil
 }
@@ -906,14 +924,13 @@ func Convert_v1alpha1_NamespaceTemplateSpec_To_v1beta1_NamespaceTemplateSpec(in
 func autoConvert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(in *v1beta1.NamespaceTemplateSpec, out *NamespaceTemplateSpec, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	out.Spec = in.Spec
+	// WARNING: in.ResourceQuota requires manual conversion: does not exist in peer-type
+	// WARNING: in.LimitRange requires manual conversion: does not exist in peer-type
+	// WARNING: in.NetworkPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeleteAfterTrial requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec is an autogenerated conversion function.
-func Convert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(in *v1beta1.NamespaceTemplateSpec, out *NamespaceTemplateSpec, s conversion.Scope) error {
-	return autoConvert_v1beta1_NamespaceTemplateSpec_To_v1alpha1_NamespaceTemplateSpec(in, out, s)
-}
-
 func autoConvert_v1alpha1_Optimization_To_v1beta1_Optimization(in *Optimization, out *v1beta1.Optimization, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Value = in.Value
@@ -973,6 +990,13 @@ func autoConvert_v1beta1_Parameter_To_v1alpha1_Parameter(in *v1beta1.Parameter,
 	out.Min = in.Min
 	out.Max = in.Max
 	out.Values = in.Values
+	// WARNING: in.ValueInfo requires manual conversion: does not exist in peer-type
+	// WARNING: in.Type requires manual conversion: does not exist in peer-type
+	// WARNING: in.Encoding requires manual conversion: does not exist in peer-type
+	// WARNING: in.RestartPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.Scale requires manual conversion: does not exist in peer-type
+	// WARNING: in.Step requires manual conversion: does not exist in peer-type
+	// WARNING: in.DependsOn requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1116,14 +1140,10 @@ func autoConvert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(in *v1beta1.R
 	out.PeriodSeconds = in.PeriodSeconds
 	out.AttemptsRemaining = in.AttemptsRemaining
 	out.LastCheckTime = in.LastCheckTime
+	// WARNING: in.Probe requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck is an autogenerated conversion function.
-func Convert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(in *v1beta1.ReadinessCheck, out *ReadinessCheck, s conversion.Scope) error {
-	return autoConvert_v1beta1_ReadinessCheck_To_v1alpha1_ReadinessCheck(in, out, s)
-}
-
 func autoConvert_v1alpha1_SetupTask_To_v1beta1_SetupTask(in *SetupTask, out *v1beta1.SetupTask, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Image = in.Image
@@ -1173,6 +1193,7 @@ func autoConvert_v1beta1_SetupTask_To_v1alpha1_SetupTask(in *v1beta1.SetupTask,
 	out.SkipCreate = in.SkipCreate
 	out.SkipDelete = in.SkipDelete
 	out.VolumeMounts = in.VolumeMounts
+	// WARNING: in.Resources requires manual conversion: does not exist in peer-type
 	out.HelmChart = in.HelmChart
 	out.HelmChartVersion = in.HelmChartVersion
 	if in.HelmValues != nil {
@@ -1198,14 +1219,10 @@ func autoConvert_v1beta1_SetupTask_To_v1alpha1_SetupTask(in *v1beta1.SetupTask,
 		out.HelmValuesFrom = nil
 	}
 	out.HelmRepository = in.HelmRepository
+	// WARNING: in.Prometheus requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_SetupTask_To_v1alpha1_SetupTask is an autogenerated conversion function.
-func Convert_v1beta1_SetupTask_To_v1alpha1_SetupTask(in *v1beta1.SetupTask, out *SetupTask, s conversion.Scope) error {
-	return autoConvert_v1beta1_SetupTask_To_v1alpha1_SetupTask(in, out, s)
-}
-
 func autoConvert_v1alpha1_SumConstraint_To_v1beta1_SumConstraint(in *SumConstraint, out *v1beta1.SumConstraint, s conversion.Scope) error {
 	out.Bound = in.Bound
 	out.IsUpperBound = in.IsUpperBound
@@ -1392,14 +1409,10 @@ func autoConvert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(in *v
 	out.InitialDelaySeconds = in.InitialDelaySeconds
 	out.PeriodSeconds = in.PeriodSeconds
 	out.FailureThreshold = in.FailureThreshold
+	// WARNING: in.Probe requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate is an autogenerated conversion function.
-func Convert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(in *v1beta1.TrialReadinessGate, out *TrialReadinessGate, s conversion.Scope) error {
-	return autoConvert_v1beta1_TrialReadinessGate_To_v1alpha1_TrialReadinessGate(in, out, s)
-}
-
 func autoConvert_v1alpha1_TrialSpec_To_v1beta1_TrialSpec(in *TrialSpec, out *v1beta1.TrialSpec, s conversion.Scope) error {
 	out.ExperimentRef = in.ExperimentRef
 	if in.Assignments != nil {
@@ -1480,6 +1493,7 @@ func autoConvert_v1beta1_TrialSpec_To_v1alpha1_TrialSpec(in *v1beta1.TrialSpec,
 	out.InitialDelaySeconds = in.InitialDelaySeconds
 	out.StartTimeOffset = in.StartTimeOffset
 	out.ApproximateRuntime = in.ApproximateRuntime
+	// WARNING: in.WarmupDuration requires manual conversion: does not exist in peer-type
 	out.TTLSecondsAfterFinished = in.TTLSecondsAfterFinished
 	out.TTLSecondsAfterFailure = in.TTLSecondsAfterFailure
 	if in.ReadinessGates != nil {
@@ -1493,6 +1507,10 @@ func autoConvert_v1beta1_TrialSpec_To_v1alpha1_TrialSpec(in *v1beta1.TrialSpec,
 	} else {
 		out.ReadinessGates = nil
 	}
+	// WARNING: in.Preemption requires manual conversion: does not exist in peer-type
+	// WARNING: in.Artifacts requires manual conversion: does not exist in peer-type
+	// WARNING: in.Hooks requires manual conversion: does not exist in peer-type
+	// WARNING: in.AssignmentsFormat requires manual conversion: does not exist in peer-type
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = make([]Value, len(*in))
@@ -1566,6 +1584,7 @@ func autoConvert_v1beta1_TrialStatus_To_v1alpha1_TrialStatus(in *v1beta1.TrialSt
 	}
 	// WARNING: in.PatchOperations requires manual conversion: does not exist in peer-type
 	// WARNING: in.ReadinessChecks requires manual conversion: does not exist in peer-type
+	// WARNING: in.Events requires manual conversion: does not exist in peer-type
 	return nil
 }
 
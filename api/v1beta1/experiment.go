@@ -17,7 +17,11 @@ limitations under the License.
 package v1beta1
 
 import (
+	"sort"
+	"strconv"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Replicas returns the effective replica (trial) count for the experiment
@@ -25,8 +29,20 @@ func (in *Experiment) Replicas() int32 {
 	if in == nil || !in.DeletionTimestamp.IsZero() {
 		return 0
 	}
+	replicas := int32(1)
 	if in.Spec.Replicas != nil {
-		return *in.Spec.Replicas
+		replicas = *in.Spec.Replicas
+	}
+	if in.Spec.MaxConcurrentTrials != nil && *in.Spec.MaxConcurrentTrials < replicas {
+		replicas = *in.Spec.MaxConcurrentTrials
+	}
+	return replicas
+}
+
+// Repetitions returns the effective number of trials to run for each suggested assignment
+func (in *Experiment) Repetitions() int32 {
+	if in != nil && in.Spec.Repetitions != nil && *in.Spec.Repetitions > 1 {
+		return *in.Spec.Repetitions
 	}
 	return 1
 }
@@ -54,3 +70,43 @@ func (in *Experiment) TrialSelector() *metav1.LabelSelector {
 		},
 	}
 }
+
+// GetValues returns the discrete allowed values of the parameter; boolean parameters are
+// represented using the categorical values "false" and "true". Values with an ordinal hint
+// are sorted first, all other values retain their original order.
+func (in *Parameter) GetValues() []string {
+	if in.Type == ParameterTypeBoolean {
+		return []string{"false", "true"}
+	}
+
+	ordinals := make(map[string]int32, len(in.ValueInfo))
+	for i := range in.ValueInfo {
+		if in.ValueInfo[i].Ordinal != nil {
+			ordinals[in.ValueInfo[i].Value] = *in.ValueInfo[i].Ordinal
+		}
+	}
+	if len(ordinals) == 0 {
+		return in.Values
+	}
+
+	values := make([]string, len(in.Values))
+	copy(values, in.Values)
+	sort.SliceStable(values, func(i, j int) bool {
+		oi, iok := ordinals[values[i]]
+		oj, jok := ordinals[values[j]]
+		if iok && jok {
+			return oi < oj
+		}
+		return iok && !jok
+	})
+	return values
+}
+
+// GetBaseline returns the baseline value of the parameter, boolean parameters may express
+// the baseline numerically (zero for "false") but always return the categorical value
+func (in *Parameter) GetBaseline() *intstr.IntOrString {
+	if in.Baseline == nil || in.Baseline.Type != intstr.Int || in.Type != ParameterTypeBoolean {
+		return in.Baseline
+	}
+	return &intstr.IntOrString{Type: intstr.String, StrVal: strconv.FormatBool(in.Baseline.IntVal != 0)}
+}
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -83,6 +84,91 @@ type Parameter struct {
 	Max int32 `json:"max,omitempty"`
 	// The discrete allowed values of the parameter
 	Values []string `json:"values,omitempty"`
+	// Additional information about the discrete allowed values of the parameter
+	ValueInfo []CategoricalValue `json:"valueInfo,omitempty"`
+	// The type of the parameter, only required for boolean parameters; otherwise inferred from the range
+	Type ParameterType `json:"type,omitempty"`
+	// The encoding applied to assigned values when they are used in templates
+	Encoding *ParameterEncoding `json:"encoding,omitempty"`
+	// The restart policy of the parameter, one of: Always|Never, default: Always; patches which only reference
+	// parameters that never require a restart are applied live, without waiting for the patched objects to roll out
+	RestartPolicy ParameterRestartPolicy `json:"restartPolicy,omitempty"`
+	// The scale used to explore the numeric range of the parameter, one of: linear|log, default: linear
+	Scale ParameterScale `json:"scale,omitempty"`
+	// The distance between adjacent values of the numeric range of the parameter; for a linear scale the step is
+	// added to the previous value (default: 1), for a log scale the previous value is multiplied by the step
+	// (default: 2, the minimum must be positive). When the range is not an exact number of steps, the largest
+	// value that does not exceed the maximum is used as the upper bound. The server only sees the step index of a
+	// scaled parameter (a log scale or a step greater than one), so scaled parameters cannot be used in order or sum
+	// constraints and the server reports the step index as the assigned value.
+	Step int32 `json:"step,omitempty"`
+	// The condition under which the parameter is active, inactive parameters are omitted from patch templates
+	DependsOn *ParameterDependency `json:"dependsOn,omitempty"`
+}
+
+// ParameterDependency describes the values of a categorical parameter that activate a dependent parameter
+type ParameterDependency struct {
+	// The name of the categorical parameter the dependent parameter depends on
+	Name string `json:"name"`
+	// The values of the categorical parameter that activate the dependent parameter
+	Values []string `json:"values"`
+}
+
+// CategoricalValue describes one of the discrete allowed values of a parameter
+type CategoricalValue struct {
+	// The allowed value being described
+	Value string `json:"value"`
+	// An ordinal hint for the value, values with an ordinal are sorted before being sent to the server
+	Ordinal *int32 `json:"ordinal,omitempty"`
+}
+
+// ParameterType represents the allowable types of parameters
+type ParameterType string
+
+const (
+	// ParameterTypeBoolean is a parameter whose assignments are either "true" or "false"
+	ParameterTypeBoolean ParameterType = "boolean"
+)
+
+// ParameterScale represents the allowable scales of numeric parameters
+type ParameterScale string
+
+const (
+	// ScaleLinear is a numeric parameter whose values are evenly spaced
+	ScaleLinear ParameterScale = "linear"
+	// ScaleLog is a numeric parameter whose values are spaced by a constant factor (e.g. powers of two)
+	ScaleLog ParameterScale = "log"
+)
+
+// ParameterEncodingType represents the allowable types of parameter encodings
+type ParameterEncodingType string
+
+const (
+	// EncodingDuration renders numeric assignments as Go durations using the encoding unit, e.g. "1500ms"
+	EncodingDuration ParameterEncodingType = "duration"
+	// EncodingBoolean renders numeric assignments as "false" (zero) or "true" (non-zero)
+	EncodingBoolean ParameterEncodingType = "boolean"
+	// EncodingBase64 renders assignments using standard base64 encoding
+	EncodingBase64 ParameterEncodingType = "base64"
+)
+
+// ParameterRestartPolicy describes how a change to the assigned value of a parameter is picked up by the application
+type ParameterRestartPolicy string
+
+const (
+	// RestartPolicyAlways indicates the application must be restarted (e.g. a rollout) to pick up a new value
+	RestartPolicyAlways ParameterRestartPolicy = "Always"
+	// RestartPolicyNever indicates the application picks up a new value without restarting (e.g. HPA settings or
+	// configuration files that are reloaded by the application)
+	RestartPolicyNever ParameterRestartPolicy = "Never"
+)
+
+// ParameterEncoding describes how an assigned value is rendered in patch templates
+type ParameterEncoding struct {
+	// The encoding type, one of: duration|boolean|base64
+	Type ParameterEncodingType `json:"type"`
+	// The unit of numeric assignments for the "duration" encoding type, e.g. "ms", default: "s"
+	Unit string `json:"unit,omitempty"`
 }
 
 // Constraint represents a constraint to the domain of the parameters
@@ -93,6 +179,10 @@ type Constraint struct {
 	Order *OrderConstraint `json:"order,omitempty"`
 	// The sum constraint to impose
 	Sum *SumConstraint `json:"sum,omitempty"`
+	// The expression constraint to impose, e.g. `cpu * replicas <= 32`; the expression compares arithmetic
+	// (+, -, *, /) over numeric parameters and constants using one of: <|<=|>|>=. Only linear expressions over unscaled
+	// parameters can be used with the server, any expression can be used with the local optimizer
+	Expression string `json:"expression,omitempty"`
 }
 
 // OrderConstraint defines a constraint between the ordering of two parameters in the experiment
@@ -137,6 +227,20 @@ const (
 	MetricJSONPath MetricType = "jsonpath"
 	// MetricNewRelic metrics issue queries to the New Relic service. Requires API and application key configuration.
 	MetricNewRelic MetricType = "newrelic"
+	// MetricInfluxDB metrics issue Flux queries to an InfluxDB server. The URL should include the "org" query parameter.
+	MetricInfluxDB MetricType = "influxdb"
+	// MetricElasticsearch metrics issue search requests to an Elasticsearch (or OpenSearch) server. The URL should
+	// include the index (e.g. "http://elasticsearch:9200/logs-*"), queries are search request bodies which are limited
+	// to documents whose "@timestamp" falls within the trial (use the "timestampField" URL query parameter to change
+	// the field). The value is taken from the single aggregation in the response or the total number of hits.
+	MetricElasticsearch MetricType = "elasticsearch"
+	// MetricMetricsServer metrics average the resource usage reported by the Kubernetes metrics server for the
+	// target pods or nodes over the course of the trial. Queries are resource names, e.g. "cpu" or "memory".
+	MetricMetricsServer MetricType = "metricsserver"
+	// MetricDerived metrics are computed from the other metric values of the same trial after they have been collected.
+	// Queries are Go Templates evaluated against the trial, the collected values are available using `.Metrics`;
+	// referencing a metric without a collected value is an error.
+	MetricDerived MetricType = "derived"
 )
 
 // Metric represents an observable outcome from a trial run
@@ -149,22 +253,130 @@ type Metric struct {
 	Min *resource.Quantity `json:"min,omitempty"`
 	// The inclusive maximum allowed value for the metric
 	Max *resource.Quantity `json:"max,omitempty"`
+	// The value to optimize toward, values better than the target are recorded as the target value; targets are
+	// applied by the controller and are not sent to the server
+	TargetValue *resource.Quantity `json:"targetValue,omitempty"`
+	// The limit for intermediate samples of the metric, the running trial is failed as soon as a sample is worse than
+	// the limit (i.e. above the limit for minimized metrics or below the limit for maximized metrics)
+	FailFast *resource.Quantity `json:"failFast,omitempty"`
 	// Indicator that this metric should be optimized (default: true)
 	Optimize *bool `json:"optimize,omitempty"`
 
-	// The metric collection type, one of: kubernetes|prometheus|datadog|jsonpath, default: kubernetes
+	// The metric collection type, one of: kubernetes|prometheus|datadog|jsonpath|newrelic|influxdb|elasticsearch|metricsserver|derived, default: kubernetes
 	Type MetricType `json:"type,omitempty"`
-	// Collection type specific query, e.g. Go template for "kubernetes", PromQL for "prometheus" or a JSON pointer expression (with curly braces) for "jsonpath"
+	// Collection type specific query, e.g. Go template for "kubernetes" or "derived", PromQL for "prometheus", Flux for "influxdb", a search request body for "elasticsearch", a resource name for "metricsserver" or a JSON pointer expression (with curly braces) for "jsonpath"
 	Query string `json:"query"`
 	// Collection type specific query for the error associated with collected metric value
 	ErrorQuery string `json:"errorQuery,omitempty"`
+	// Aggregation applied to the values returned by a "prometheus" range query, the query is evaluated over a
+	// window of the trial instead of at the trial completion time
+	Aggregation *MetricAggregation `json:"aggregation,omitempty"`
+	// The function used to combine the values of repeated trials, one of: avg|min|max|sum or a percentile such as
+	// "p90", default: p50 (the median)
+	RepetitionAggregation MetricAggregationFunction `json:"repetitionAggregation,omitempty"`
 
 	// URL to use when querying remote metric sources.
 	URL string `json:"url,omitempty"`
-	// Target reference of the Kubernetes object to query for metric information.
+	// HTTP request options used when querying "jsonpath" metrics, the headers are also used for "prometheus" metrics.
+	HTTP *MetricHTTP `json:"http,omitempty"`
+	// TLS configuration used when querying "prometheus", "jsonpath", "influxdb" or "elasticsearch" metrics.
+	TLS *MetricTLS `json:"tls,omitempty"`
+	// The number of times to retry collection of the metric before failing the trial, default: 2
+	Retries *int32 `json:"retries,omitempty"`
+	// The amount of time to wait before retrying collection, the delay is doubled after each failed attempt
+	RetryDelay *metav1.Duration `json:"retryDelay,omitempty"`
+	// The maximum amount of time allowed for each collection attempt
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// The amount of time between intermediate samples of the metric while the trial is running, intermediate samples
+	// are recorded on the trial for monitoring but are not used as the final value of the metric
+	SampleInterval *metav1.Duration `json:"sampleInterval,omitempty"`
+	// Queries for the timestamps that bound the measurement window, use when the clocks of the application (or load
+	// generator) and the cluster are not synchronized, default: the trial start and completion times
+	Timestamps *MetricTimestamps `json:"timestamps,omitempty"`
+	// Target reference of the Kubernetes object to query for metric information. For "datadog" metrics, the target
+	// may be a secret containing the "api-key" and "app-key" values used for authentication; for "influxdb" metrics
+	// the secret should contain a "token" value, for "elasticsearch" metrics either an "apiKey" or a "username" and
+	// "password" and for "prometheus" or "jsonpath" metrics either a "token" or a "username" and "password".
 	Target *ResourceTarget `json:"target,omitempty"`
 }
 
+// MetricAggregationFunction is the function used to reduce a range of values to a single metric value.
+type MetricAggregationFunction string
+
+const (
+	// MetricAggregationAverage is the mean of the values.
+	MetricAggregationAverage MetricAggregationFunction = "avg"
+	// MetricAggregationMinimum is the smallest value.
+	MetricAggregationMinimum MetricAggregationFunction = "min"
+	// MetricAggregationMaximum is the largest value.
+	MetricAggregationMaximum MetricAggregationFunction = "max"
+	// MetricAggregationSum is the total of the values.
+	MetricAggregationSum MetricAggregationFunction = "sum"
+)
+
+// MetricAggregation describes how the values observed over a window of the trial are combined.
+type MetricAggregation struct {
+	// The aggregation function, one of: avg|min|max|sum or a percentile such as "p99", default: avg
+	Function MetricAggregationFunction `json:"function,omitempty"`
+	// The length of the window, default: the entire duration of the trial
+	Window *metav1.Duration `json:"window,omitempty"`
+	// The point in the trial the window is anchored to, one of: start|completion, default: completion. A window
+	// anchored to the start of the trial begins at the start time, otherwise it ends at the completion time.
+	RelativeTo string `json:"relativeTo,omitempty"`
+}
+
+// MetricHTTP describes the HTTP request used to fetch a remote metric.
+type MetricHTTP struct {
+	// The HTTP method of the request, default: GET
+	Method string `json:"method,omitempty"`
+	// The body of the request.
+	Body string `json:"body,omitempty"`
+	// Additional headers to include with the request.
+	Headers []MetricHTTPHeader `json:"headers,omitempty"`
+}
+
+// MetricHTTPHeader is a header included with the HTTP request used to fetch a remote metric.
+type MetricHTTPHeader struct {
+	// The name of the header.
+	Name string `json:"name"`
+	// The literal value of the header.
+	Value string `json:"value,omitempty"`
+	// Reference to a secret key containing the value of the header.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// MetricTLS describes the TLS configuration used to fetch a remote metric.
+type MetricTLS struct {
+	// The PEM encoded certificate authorities used to verify the server certificate.
+	CA *MetricTLSData `json:"ca,omitempty"`
+	// The PEM encoded client certificate.
+	Cert *MetricTLSData `json:"cert,omitempty"`
+	// The PEM encoded client certificate key.
+	Key *MetricTLSData `json:"key,omitempty"`
+	// Disable verification of the server certificate; this should only be used for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// MetricTLSData is a value used to configure TLS.
+type MetricTLSData struct {
+	// The literal value.
+	Value string `json:"value,omitempty"`
+	// Reference to a config map key containing the value.
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Reference to a secret key containing the value.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// MetricTimestamps describes queries for the timestamps reported by the application (e.g. pushed by a load generator)
+// which bound the measurement window of a metric. The queries are evaluated like the metric query and must produce a
+// Unix timestamp in seconds.
+type MetricTimestamps struct {
+	// The query for the start of the measurement window.
+	StartQuery string `json:"startQuery,omitempty"`
+	// The query for the end of the measurement window.
+	EndQuery string `json:"endQuery,omitempty"`
+}
+
 // PatchReadinessGate contains a reference to a condition
 type PatchReadinessGate struct {
 	// ConditionType refers to a condition in the patched target's condition list
@@ -199,12 +411,123 @@ type PatchTemplate struct {
 	ReadinessGates []PatchReadinessGate `json:"readinessGates,omitempty"`
 }
 
+// DerivedValue defines a value computed from the trial assignments
+type DerivedValue struct {
+	// The name of the derived value, must not match the name of a parameter
+	Name string `json:"name"`
+	// A Go Template that evaluates to the derived value, e.g. `{{ mulf (float64 .Values.memory) 0.8 | floor }}`; the
+	// template may reference any assignment or any of the derived values defined before it
+	Value string `json:"value"`
+}
+
 // NamespaceTemplateSpec is used as a template for creating new namespaces
 type NamespaceTemplateSpec struct {
 	// Standard object metadata
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	// Specification of the namespace
 	Spec corev1.NamespaceSpec `json:"spec,omitempty"`
+	// ResourceQuota limits the aggregate resource consumption of each namespace created from the template
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+	// LimitRange constrains the resources of the individual pods and containers of each namespace created from the template
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+	// NetworkPolicy isolates the network traffic of each namespace created from the template
+	NetworkPolicy *networkingv1.NetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// DeleteAfterTrial tears down each namespace created from the template once the trial running in it has finished,
+	// every trial runs in a new namespace
+	DeleteAfterTrial bool `json:"deleteAfterTrial,omitempty"`
+}
+
+// TrialNameSuffix is the strategy used to generate the end of a trial name
+type TrialNameSuffix string
+
+const (
+	// TrialNameNumber uses the zero padded trial number reported by the server
+	TrialNameNumber TrialNameSuffix = "number"
+	// TrialNameHash uses a short hash of the trial number reported by the server
+	TrialNameHash TrialNameSuffix = "hash"
+)
+
+// WebhookFailurePolicy specifies how failures to invoke a webhook are handled.
+type WebhookFailurePolicy string
+
+const (
+	// WebhookFailurePolicyFail causes the trial to fail if the webhook cannot be invoked.
+	WebhookFailurePolicyFail WebhookFailurePolicy = "Fail"
+	// WebhookFailurePolicyIgnore reports the unmodified values if the webhook cannot be invoked.
+	WebhookFailurePolicyIgnore WebhookFailurePolicy = "Ignore"
+)
+
+// ValueWebhook describes an HTTP endpoint used to post-process the metric values of a trial
+type ValueWebhook struct {
+	// The URL of the webhook, the trial values are sent as the JSON body of a POST request
+	URL string `json:"url"`
+	// The maximum amount of time to wait for a response, defaults to 10s
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// The policy for handling webhook failures, one of: Fail|Ignore, defaults to "Fail"
+	FailurePolicy WebhookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// TrialNaming controls how the names of trials (and by default, their jobs) are generated. Trial names are always
+// derived from the trial number so a trial can be found by name given only the experiment and trial number.
+type TrialNaming struct {
+	// Prefix of the trial name, defaults to the trial template's generate name or the experiment name followed by a dash
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix is the strategy used to generate the end of the trial name, defaults to "number"
+	Suffix TrialNameSuffix `json:"suffix,omitempty"`
+}
+
+// TrialRetention controls how many finished trials (and their associated objects) are kept in the cluster
+type TrialRetention struct {
+	// KeepCompletedTrials is the maximum number of finished trials to keep, the oldest trials are deleted first
+	KeepCompletedTrials *int32 `json:"keepCompletedTrials,omitempty"`
+	// TTLSecondsAfterFinished is the minimum number of seconds after a trial finishes before its jobs and config maps
+	// are deleted; the trial itself (including the metric values) is not affected
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// TrialSchedule defines when new trials are allowed to start
+type TrialSchedule struct {
+	// TimeZone is the IANA name of the location used to evaluate the windows, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// Windows are the periods during which new trials can be started
+	Windows []TrialScheduleWindow `json:"windows"`
+}
+
+// TrialScheduleWindow is a recurring period of time during which new trials can be started
+type TrialScheduleWindow struct {
+	// Days is a cron style day of the week field (e.g. "1-5" or "MON,WED,FRI") the window opens on, defaults to every day
+	Days string `json:"days,omitempty"`
+	// Start is the time of day the window opens, in 24-hour "HH:MM" format
+	Start string `json:"start"`
+	// End is the time of day the window closes, in 24-hour "HH:MM" format; a window ending at or before its start
+	// closes on the following day
+	End string `json:"end"`
+}
+
+// LocalOptimizerStrategy represents the allowable strategies of the local optimizer
+type LocalOptimizerStrategy string
+
+const (
+	// LocalOptimizerRandom chooses independent random assignments for each trial
+	LocalOptimizerRandom LocalOptimizerStrategy = "random"
+	// LocalOptimizerLatinHypercube spreads the assignments of each parameter evenly over its range
+	LocalOptimizerLatinHypercube LocalOptimizerStrategy = "latinHypercube"
+	// LocalOptimizerGrid evaluates every combination of evenly spaced assignments
+	LocalOptimizerGrid LocalOptimizerStrategy = "grid"
+)
+
+// LocalOptimizer generates trial suggestions in the controller instead of the remote server, it does not learn from
+// the trial results and is intended for evaluating experiments in environments without access to the server
+type LocalOptimizer struct {
+	// Strategy is used to choose the parameter assignments, defaults to random
+	Strategy LocalOptimizerStrategy `json:"strategy,omitempty"`
+	// Budget is the number of trials to create, defaults to the "experimentBudget" optimization value; a grid search
+	// defaults to the number of grid points
+	Budget *int32 `json:"budget,omitempty"`
+	// Seed initializes the pseudo-random number generator, the same seed always produces the same suggestions
+	Seed int64 `json:"seed,omitempty"`
+	// GridPoints is the maximum number of values of each numeric parameter used for a grid search, defaults to 5
+	GridPoints int32 `json:"gridPoints,omitempty"`
 }
 
 // TrialTemplateSpec is used as a template for creating new trials
@@ -223,6 +546,14 @@ const (
 	ExperimentComplete ExperimentConditionType = "redskyops.dev/experiment-complete"
 	// ExperimentFailed is a condition that indicates an experiment failed
 	ExperimentFailed ExperimentConditionType = "redskyops.dev/experiment-failed"
+	// ExperimentServerSynced is a condition that indicates the experiment is synchronized with the remote server
+	ExperimentServerSynced ExperimentConditionType = "redskyops.dev/experiment-server-synced"
+	// ExperimentWaiting is a condition that indicates the experiment is waiting for the experiments it depends on
+	ExperimentWaiting ExperimentConditionType = "redskyops.dev/experiment-waiting"
+	// ExperimentDrifted is a condition that indicates the cluster and server experiment definitions have diverged
+	ExperimentDrifted ExperimentConditionType = "redskyops.dev/experiment-drifted"
+	// ExperimentScheduleInvalid is a condition that indicates the trial schedule cannot be evaluated, no new trials are started
+	ExperimentScheduleInvalid ExperimentConditionType = "redskyops.dev/experiment-schedule-invalid"
 )
 
 // ExperimentCondition represents an observed condition of an experiment
@@ -243,16 +574,40 @@ type ExperimentCondition struct {
 
 // ExperimentSpec defines the desired state of Experiment
 type ExperimentSpec struct {
+	// Description is a human readable explanation of the purpose of the experiment
+	Description string `json:"description,omitempty"`
+	// Owner identifies the person or team responsible for the experiment
+	Owner string `json:"owner,omitempty"`
+	// TicketURL is a link to the issue or ticket tracking the experiment
+	TicketURL string `json:"ticketURL,omitempty"`
+	// DependsOn is the list of experiment names (in the same namespace) that must complete before this experiment
+	// starts running trials
+	DependsOn []string `json:"dependsOn,omitempty"`
 	// Replicas is the number of trials to execute concurrently, defaults to 1
 	Replicas *int32 `json:"replicas,omitempty"`
+	// MaxConcurrentTrials is an upper bound on the number of trials that can be active at the same time, it takes
+	// precedence over the number of replicas
+	MaxConcurrentTrials *int32 `json:"maxConcurrentTrials,omitempty"`
+	// Repetitions is the number of trials to execute (sequentially) for each suggested assignment, the metric values
+	// of the repeated trials are aggregated before they are reported, defaults to 1
+	Repetitions *int32 `json:"repetitions,omitempty"`
 	// Optimization defines additional configuration for the optimization
 	Optimization []Optimization `json:"optimization,omitempty"`
+	// LocalOptimizer generates trial suggestions without a connection to the remote server, experiments using a
+	// local optimizer are not synchronized with the server
+	LocalOptimizer *LocalOptimizer `json:"localOptimizer,omitempty"`
 	// Parameters defines the search space for the experiment
 	Parameters []Parameter `json:"parameters"`
 	// Constraints defines restrictions on the parameter domain for the experiment
 	Constraints []Constraint `json:"constraints,omitempty"`
 	// Metrics defines the outcomes for the experiment
 	Metrics []Metric `json:"metrics"`
+	// ValueWebhook is invoked with the collected metric values of each trial, the response may adjust the values
+	// before they are reported
+	ValueWebhook *ValueWebhook `json:"valueWebhook,omitempty"`
+	// Derived is a sequence of values computed from the parameter assignments that are made available to the patch
+	// templates alongside the assignments
+	Derived []DerivedValue `json:"derived,omitempty"`
 	// Patches is a sequence of templates written against the experiment parameters that will be used to put the
 	// cluster into the desired state
 	Patches []PatchTemplate `json:"patches,omitempty"`
@@ -263,6 +618,12 @@ type ExperimentSpec struct {
 	NamespaceTemplate *NamespaceTemplateSpec `json:"namespaceTemplate,omitempty"`
 	// Selector locates trial resources that are part of this experiment
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// TrialNaming controls how the names of new trials are generated
+	TrialNaming *TrialNaming `json:"trialNaming,omitempty"`
+	// TrialRetention limits the number of finished trials and trial jobs that accumulate in the cluster
+	TrialRetention *TrialRetention `json:"trialRetention,omitempty"`
+	// Schedule restricts new trials to the specified windows, trials which have already started are not interrupted
+	Schedule *TrialSchedule `json:"schedule,omitempty"`
 	// TrialTemplate for creating a new trial. The resulting trial must be matched by Selector. The template can provide an
 	// initial namespace, however other namespaces (matched by NamespaceSelector) will be used if the effective
 	// replica count is more then one
@@ -275,9 +636,16 @@ type ExperimentStatus struct {
 	Phase string `json:"phase"`
 	// ActiveTrials is the observed number of running trials
 	ActiveTrials int32 `json:"activeTrials"`
+	// CompletedTrials is the observed number of successfully completed trials still present in the cluster
+	CompletedTrials int32 `json:"completedTrials,omitempty"`
+	// FailedTrials is the observed number of failed trials still present in the cluster
+	FailedTrials int32 `json:"failedTrials,omitempty"`
+	// BestValues is a string representation of the best observed value for each optimized metric
+	BestValues string `json:"bestValues,omitempty"`
 	// Conditions is the current state of the experiment
 	Conditions []ExperimentCondition `json:"conditions,omitempty"`
-	// TODO Number of trials: Succeeded, Failed int32 (this would need to be fetch remotely, falling back to the in cluster count)
+	// ServerSyncFailures is the number of consecutive failed attempts to synchronize with the remote server
+	ServerSyncFailures int32 `json:"serverSyncFailures,omitempty"`
 }
 
 // +genclient
@@ -287,6 +655,10 @@ type ExperimentStatus struct {
 // Experiment is the Schema for the experiments API
 // +kubebuilder:resource:shortName=exp
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase",description="Experiment status"
+// +kubebuilder:printcolumn:name="Active",type="integer",JSONPath=".status.activeTrials",description="Active trials"
+// +kubebuilder:printcolumn:name="Completed",type="integer",JSONPath=".status.completedTrials",description="Completed trials"
+// +kubebuilder:printcolumn:name="Best",type="string",JSONPath=".status.bestValues",description="Best observed values"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type Experiment struct {
 	metav1.TypeMeta `json:",inline"`
 	// Standard object metadata
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ArchivedTrial is the outcome of a single trial
+type ArchivedTrial struct {
+	// Name of the trial
+	Name string `json:"name"`
+	// Phase is the final phase of the trial
+	Phase string `json:"phase,omitempty"`
+	// Assignments are the parameter values used by the trial
+	Assignments []Assignment `json:"assignments,omitempty"`
+	// Values are the metric values collected for the trial
+	Values []Value `json:"values,omitempty"`
+	// StartTime is the time the trial run started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the trial run finished
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// FailureReason is the reason a failed trial failed
+	FailureReason string `json:"failureReason,omitempty"`
+	// FailureMessage is a human readable description of why a failed trial failed
+	FailureMessage string `json:"failureMessage,omitempty"`
+}
+
+// ArchiveProvenance describes where the archived results came from
+type ArchiveProvenance struct {
+	// ExperimentName is the name of the archived experiment
+	ExperimentName string `json:"experimentName"`
+	// ExperimentUID is the unique identifier of the archived experiment
+	ExperimentUID types.UID `json:"experimentUID,omitempty"`
+	// ExperimentGeneration is the generation of the archived experiment specification
+	ExperimentGeneration int64 `json:"experimentGeneration,omitempty"`
+	// ExperimentCreationTimestamp is the time the archived experiment was created
+	ExperimentCreationTimestamp metav1.Time `json:"experimentCreationTimestamp,omitempty"`
+	// ExperimentURL is the location of the experiment on the optimization server
+	ExperimentURL string `json:"experimentURL,omitempty"`
+	// ArchiveTime is the time the archive was produced
+	ArchiveTime metav1.Time `json:"archiveTime"`
+	// ControllerVersion is the version of the controller that produced the archive
+	ControllerVersion string `json:"controllerVersion,omitempty"`
+	// Digest is the SHA-256 digest of the archived experiment, status and trials; it can be used to verify the
+	// archive has not been modified
+	Digest string `json:"digest"`
+}
+
+// ExperimentArchiveSpec is the archived state of an experiment
+type ExperimentArchiveSpec struct {
+	// Experiment is the final specification of the experiment
+	Experiment ExperimentSpec `json:"experiment"`
+	// ExperimentStatus is the final status of the experiment
+	ExperimentStatus ExperimentStatus `json:"experimentStatus"`
+	// Trials are the outcomes of the trials that were present when the experiment finished
+	Trials []ArchivedTrial `json:"trials,omitempty"`
+	// Provenance describes where the archived results came from
+	Provenance ArchiveProvenance `json:"provenance"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+
+// ExperimentArchive is the Schema for the experimentarchives API, archives are immutable records of finished experiments
+// +kubebuilder:resource:shortName=exparchive
+// +kubebuilder:printcolumn:name="Experiment",type="string",JSONPath=".spec.provenance.experimentName",description="Archived experiment"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".spec.experimentStatus.phase",description="Final experiment status"
+// +kubebuilder:printcolumn:name="Best",type="string",JSONPath=".spec.experimentStatus.bestValues",description="Best observed values"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ExperimentArchive struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// The archived experiment
+	Spec ExperimentArchiveSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExperimentArchiveList contains a list of ExperimentArchive
+type ExperimentArchiveList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// The list of experiment archives
+	Items []ExperimentArchive `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExperimentArchive{}, &ExperimentArchiveList{})
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecommendedContainerResources is the recommended compute resources for a single container, the structure matches
+// the container recommendations of a vertical pod autoscaler
+type RecommendedContainerResources struct {
+	// ContainerName is the name of the container the recommendation applies to
+	ContainerName string `json:"containerName,omitempty"`
+	// Target is the recommended resource requests
+	Target corev1.ResourceList `json:"target"`
+	// UpperBound is the recommended resource limits, if any
+	UpperBound corev1.ResourceList `json:"upperBound,omitempty"`
+}
+
+// RecommendedPodResources is the recommended compute resources for the containers of a pod
+type RecommendedPodResources struct {
+	// ContainerRecommendations are the resources recommended for each container
+	ContainerRecommendations []RecommendedContainerResources `json:"containerRecommendations,omitempty"`
+}
+
+// OptimizeRecommendationSpec identifies the workload a recommendation applies to
+type OptimizeRecommendationSpec struct {
+	// TargetRef is the workload the recommendation applies to
+	TargetRef corev1.ObjectReference `json:"targetRef"`
+}
+
+// OptimizeRecommendationStatus is the current recommendation for a workload
+type OptimizeRecommendationStatus struct {
+	// Recommendation is the most recently recommended compute resources
+	Recommendation *RecommendedPodResources `json:"recommendation,omitempty"`
+	// Experiment is the name of the experiment that produced the recommendation
+	Experiment string `json:"experiment,omitempty"`
+	// Trial is the name of the trial whose assignments are recommended
+	Trial string `json:"trial,omitempty"`
+	// LastUpdateTime is the time the recommendation was last changed
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+
+// OptimizeRecommendation is the Schema for the optimizerecommendations API, it exposes the compute resources
+// recommended by finished experiments in the same form as a vertical pod autoscaler
+// +kubebuilder:resource:shortName=optrec
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetRef.name",description="Target workload"
+// +kubebuilder:printcolumn:name="Experiment",type="string",JSONPath=".status.experiment",description="Recommending experiment"
+// +kubebuilder:printcolumn:name="Trial",type="string",JSONPath=".status.trial",description="Recommended trial"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type OptimizeRecommendation struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object metadata
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// The workload the recommendation applies to
+	Spec OptimizeRecommendationSpec `json:"spec,omitempty"`
+	// The current recommendation
+	Status OptimizeRecommendationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OptimizeRecommendationList contains a list of OptimizeRecommendation
+type OptimizeRecommendationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// The list of recommendations
+	Items []OptimizeRecommendation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OptimizeRecommendation{}, &OptimizeRecommendationList{})
+}
@@ -56,6 +56,20 @@ type TrialReadinessGate struct {
 	// FailureThreshold is number of times that any of the specified ready conditions may be "False";
 	// defaults to 3, minimum value is 1
 	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+	// Probe is a request made against the readiness target (or the pods of the target) for applications whose
+	// readiness is not reflected by their status conditions
+	Probe *ReadinessProbe `json:"probe,omitempty"`
+}
+
+// ReadinessProbe describes a request made by the controller to determine if a target is ready
+type ReadinessProbe struct {
+	// HTTPGet performs an HTTP GET request, a response status code of at least 200 and less then 400 indicates the
+	// target is ready
+	HTTPGet *corev1.HTTPGetAction `json:"httpGet,omitempty"`
+	// TCPSocket opens a TCP connection, the target is ready if the connection is established
+	TCPSocket *corev1.TCPSocketAction `json:"tcpSocket,omitempty"`
+	// TimeoutSeconds is the number of seconds after which the probe times out; defaults to 1 second
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // HelmValue represents a value in a Helm template
@@ -98,6 +112,18 @@ type ConfigMapHelmValuesFromSource struct {
 	corev1.LocalObjectReference `json:",inline"`
 }
 
+// PrometheusSetup is the configuration of the built-in Prometheus deployed by a setup task
+type PrometheusSetup struct {
+	// The interval at which metrics are scraped, defaults to 5 seconds
+	ScrapeInterval *metav1.Duration `json:"scrapeInterval,omitempty"`
+	// How long to retain metrics, defaults to 1 day
+	Retention *metav1.Duration `json:"retention,omitempty"`
+	// The compute resources of the Prometheus server
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Additional scrape configurations, a YAML list in the Prometheus "scrape_configs" format
+	ExtraScrapeConfigs string `json:"extraScrapeConfigs,omitempty"`
+}
+
 // SetupTask represents the configuration necessary to apply application state to the cluster
 // prior to each trial run and remove that state after the run concludes
 type SetupTask struct {
@@ -115,6 +141,8 @@ type SetupTask struct {
 	SkipDelete bool `json:"skipDelete,omitempty"`
 	// Volume mounts for the setup task
 	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Compute resources required by the setup task container
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 	// The Helm chart reference to release as part of this task
 	HelmChart string `json:"helmChart,omitempty"`
 	// The Helm chart version, empty means use the latest
@@ -125,6 +153,8 @@ type SetupTask struct {
 	HelmValuesFrom []HelmValuesFromSource `json:"helmValuesFrom,omitempty"`
 	// The Helm repository to fetch the chart from
 	HelmRepository string `json:"helmRepository,omitempty"`
+	// The configuration of the built-in Prometheus, ignored unless this task deploys the built-in Prometheus
+	Prometheus *PrometheusSetup `json:"prometheus,omitempty"`
 }
 
 // PatchOperation represents a patch used to prepare the cluster for a trial run, includes the evaluated
@@ -162,6 +192,8 @@ type ReadinessCheck struct {
 	AttemptsRemaining int32 `json:"attemptsRemaining,omitempty"`
 	// LastCheckTime is the timestamp of the last evaluation attempt
 	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Probe is a request made against the target object (or the pods of the target)
+	Probe *ReadinessProbe `json:"probe,omitempty"`
 }
 
 // Value represents an observed metric value after a trial run has completed successfully. Value names
@@ -194,8 +226,14 @@ const (
 	TrialPatched TrialConditionType = "redskyops.dev/trial-patched"
 	// TrialReady is a condition that indicates the application is ready after patches were applied
 	TrialReady TrialConditionType = "redskyops.dev/trial-ready"
+	// TrialPreempted is a condition that indicates the trial run was aborted to make room for a higher priority workload
+	TrialPreempted TrialConditionType = "redskyops.dev/trial-preempted"
+	// TrialPreHooksComplete is a condition that indicates all of the pre-trial hook jobs have finished
+	TrialPreHooksComplete TrialConditionType = "redskyops.dev/trial-pre-hooks-complete"
 	// TrialObserved is a condition that indicates a trial has had metrics collected
 	TrialObserved TrialConditionType = "redskyops.dev/trial-observed"
+	// TrialPostHooksComplete is a condition that indicates all of the post-trial hook jobs have finished
+	TrialPostHooksComplete TrialConditionType = "redskyops.dev/trial-post-hooks-complete"
 )
 
 // TrialCondition represents an observed condition of a trial
@@ -214,6 +252,78 @@ type TrialCondition struct {
 	Message string `json:"message,omitempty"`
 }
 
+// TrialPreemption describes the workloads which are allowed to preempt a running trial.
+type TrialPreemption struct {
+	// PriorityClassNames are the priority classes of workloads which preempt the trial when they cannot be
+	// scheduled due to insufficient resources
+	PriorityClassNames []string `json:"priorityClassNames"`
+	// Priority of the trial, when multiple trials are running the trial with the lowest priority is preempted first
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// TrialArtifacts describes the files and logs uploaded to object storage at the end of each trial run
+type TrialArtifacts struct {
+	// URL of the object storage location artifacts are uploaded to, e.g. "s3://bucket/prefix"; the artifacts of each
+	// trial are uploaded using the trial name as an additional prefix
+	URL string `json:"url"`
+	// SecretName is the name of the secret used to configure the uploader, the secret must contain an
+	// "MC_HOST_artifacts" entry with the object storage endpoint and credentials, e.g.
+	// "https://<access key>:<secret key>@s3.amazonaws.com"
+	SecretName string `json:"secretName"`
+	// Paths are the files or directories to upload, relative to the artifacts directory shared with the trial run
+	// containers (exposed as the "ARTIFACTS_DIR" environment variable), default: the entire artifacts directory
+	Paths []string `json:"paths,omitempty"`
+	// ContainerLogs are the names of the trial run containers whose logs are uploaded
+	ContainerLogs []string `json:"containerLogs,omitempty"`
+	// Image is the uploader image, it must include a shell and the MinIO client, default: "minio/mc"
+	Image string `json:"image,omitempty"`
+}
+
+// TrialHookPhase identifies when a trial hook runs
+type TrialHookPhase string
+
+const (
+	// TrialHookPreTrial hooks run after the trial is ready, before the trial run job is created
+	TrialHookPreTrial TrialHookPhase = "preTrial"
+	// TrialHookPostTrial hooks run after the trial metrics are collected, before the trial is complete
+	TrialHookPostTrial TrialHookPhase = "postTrial"
+)
+
+// TrialHookFailurePolicy controls what happens when a trial hook job fails
+type TrialHookFailurePolicy string
+
+const (
+	// TrialHookFail marks the trial as failed when the hook job fails
+	TrialHookFail TrialHookFailurePolicy = "Fail"
+	// TrialHookRetry runs a new hook job when the hook job fails, the trial is only failed once the retries are exhausted
+	TrialHookRetry TrialHookFailurePolicy = "Retry"
+)
+
+// TrialHook is a job run before or after the trial run, e.g. to reset the state of a stateful application
+// between trials; hooks of the same phase are run one at a time, in the order they are defined
+type TrialHook struct {
+	// The name that uniquely identifies the hook
+	Name string `json:"name"`
+	// When the hook runs, one of: preTrial|postTrial
+	Phase TrialHookPhase `json:"phase"`
+	// JobTemplate is the job template used to create the hook job
+	JobTemplate batchv1beta1.JobTemplateSpec `json:"jobTemplate"`
+	// FailurePolicy determines the outcome of a failed hook job, one of: Fail|Retry; default: Fail
+	FailurePolicy TrialHookFailurePolicy `json:"failurePolicy,omitempty"`
+	// Retries is the number of additional hook jobs to run when using the retry failure policy; default: 3
+	Retries *int32 `json:"retries,omitempty"`
+}
+
+// TrialAssignmentsFormat controls how the assignments are rendered on the trial status
+type TrialAssignmentsFormat string
+
+const (
+	// TrialAssignmentsText renders a comma separated list of "name=value" pairs, values containing separators are quoted
+	TrialAssignmentsText TrialAssignmentsFormat = "text"
+	// TrialAssignmentsJSON renders a JSON object of the parameter names and values
+	TrialAssignmentsJSON TrialAssignmentsFormat = "json"
+)
+
 // TrialSpec defines the desired state of Trial
 type TrialSpec struct {
 	// ExperimentRef is the reference to the experiment that contains the definitions to use for this trial,
@@ -231,12 +341,23 @@ type TrialSpec struct {
 	StartTimeOffset *metav1.Duration `json:"startTimeOffset,omitempty"`
 	// The approximate amount of time the trial run should execute (not inclusive of the start time offset)
 	ApproximateRuntime *metav1.Duration `json:"approximateRuntime,omitempty"`
+	// The amount of time at the start of the trial run that is excluded from the metric measurement window, e.g. to
+	// allow for JIT compilation or cache filling
+	WarmupDuration *metav1.Duration `json:"warmupDuration,omitempty"`
 	// The minimum number of seconds before an attempt should be made to clean up the trial, if unset or negative no attempt is made to clean up the trial
 	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 	// The minimum number of seconds before an attempt should be made to clean up a failed trial, defaults to TTLSecondsAfterFinished
 	TTLSecondsAfterFailure *int32 `json:"ttlSecondsAfterFailure,omitempty"`
 	// The readiness gates to check before running the trial job
 	ReadinessGates []TrialReadinessGate `json:"readinessGates,omitempty"`
+	// Preemption allows the trial run to be aborted and retried later when higher priority workloads cannot be scheduled
+	Preemption *TrialPreemption `json:"preemption,omitempty"`
+	// Artifacts are the files and logs uploaded to object storage after the trial run
+	Artifacts *TrialArtifacts `json:"artifacts,omitempty"`
+	// Hooks are the jobs run before or after the trial run job
+	Hooks []TrialHook `json:"hooks,omitempty"`
+	// AssignmentsFormat controls how the assignments are rendered on the trial status, one of: text|json; default: text
+	AssignmentsFormat TrialAssignmentsFormat `json:"assignmentsFormat,omitempty"`
 
 	// Values are the collected metrics at the end of the trial run
 	Values []Value `json:"values,omitempty"`
@@ -257,7 +378,7 @@ type TrialSpec struct {
 type TrialStatus struct {
 	// Phase is a brief human readable description of the trial status
 	Phase string `json:"phase"`
-	// Assignments is a string representation of the trial assignments for reporting purposes
+	// Assignments is a string representation of the trial assignments for reporting purposes, see AssignmentsFormat
 	Assignments string `json:"assignments"`
 	// Values is a string representation of the trial values for reporting purposes
 	Values string `json:"values"`
@@ -271,6 +392,24 @@ type TrialStatus struct {
 	PatchOperations []PatchOperation `json:"patchOperations,omitempty"`
 	// ReadinessChecks are the all of the objects whose conditions need to be inspected for this trial
 	ReadinessChecks []ReadinessCheck `json:"readinessChecks,omitempty"`
+	// Events are the recent cluster events that may be related to the failure of the trial
+	Events []TrialEvent `json:"events,omitempty"`
+}
+
+// TrialEvent is a summary of a cluster event recorded while the trial was running
+type TrialEvent struct {
+	// Type of the event, e.g. "Normal" or "Warning"
+	Type string `json:"type,omitempty"`
+	// Reason is a short machine readable description of the event
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the event
+	Message string `json:"message,omitempty"`
+	// InvolvedObject is the kind and name of the object the event is about, e.g. "Pod/my-app-1234"
+	InvolvedObject string `json:"involvedObject,omitempty"`
+	// Count is the number of times the event has occurred
+	Count int32 `json:"count,omitempty"`
+	// LastTimestamp is the time of the most recent occurrence of the event
+	LastTimestamp metav1.Time `json:"lastTimestamp,omitempty"`
 }
 
 // +genclient
@@ -281,6 +420,7 @@ type TrialStatus struct {
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase",description="Trial status"
 // +kubebuilder:printcolumn:name="Assignments",type="string",JSONPath=".status.assignments",description="Current assignments"
 // +kubebuilder:printcolumn:name="Values",type="string",JSONPath=".status.values",description="Current values"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type Trial struct {
 	metav1.TypeMeta `json:",inline"`
 	// Standard object metadata
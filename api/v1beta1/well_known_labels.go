@@ -27,6 +27,11 @@ const (
 	AnnotationReportTrialURL = "redskyops.dev/report-trial-url"
 	// AnnotationServerSync controls additional behavior around synchronizing the experiment remotely
 	AnnotationServerSync = "redskyops.dev/server-sync"
+	// AnnotationPauseSuggestions prevents new trial suggestions from being requested when set to "true"; unlike
+	// reducing the replica count to zero, active trials continue to run and report their results
+	AnnotationPauseSuggestions = "redskyops.dev/pause-suggestions"
+	// AnnotationPausedReplicas is the replica count of a paused experiment, it is restored when the experiment resumes
+	AnnotationPausedReplicas = "redskyops.dev/paused-replicas"
 
 	// LabelExperiment is the name of the experiment associated with an object
 	LabelExperiment = "redskyops.dev/experiment"
@@ -38,9 +43,37 @@ const (
 	// AnnotationInitializer is a comma-delimited list of initializing processes. Similar to a "finalizer", the trial
 	// will not start executing until the initializer is empty.
 	AnnotationInitializer = "redskyops.dev/initializer"
+	// AnnotationMetricSamples contains the resource usage sampled from the metrics server while the trial is running
+	AnnotationMetricSamples = "redskyops.dev/metric-samples"
+	// AnnotationArtifactsURL is the location of the artifacts uploaded after the trial run
+	AnnotationArtifactsURL = "redskyops.dev/artifacts-url"
+	// AnnotationPublishedTime is the time the finished trial was published to the trial sink
+	AnnotationPublishedTime = "redskyops.dev/published-time"
+	// AnnotationLocalSuggestion is the index of the local optimizer suggestion used to create the trial
+	AnnotationLocalSuggestion = "redskyops.dev/local-suggestion"
+	// AnnotationNextLocalSuggestion is the index of the next local optimizer suggestion for the experiment
+	AnnotationNextLocalSuggestion = "redskyops.dev/next-local-suggestion"
 
 	// LabelTrial contains the name of the trial associated with an object
 	LabelTrial = "redskyops.dev/trial"
 	// LabelTrialRole contains the role in trial execution
 	LabelTrialRole = "redskyops.dev/trial-role"
+	// LabelTrialHook contains the name of the trial hook associated with an object
+	LabelTrialHook = "redskyops.dev/trial-hook"
+	// LabelRepetitionOf contains the name of the trial whose assignments are being repeated
+	LabelRepetitionOf = "redskyops.dev/repetition-of"
+)
+
+// Recommendation labels and annotations
+
+const (
+	// AnnotationRecommendedTrial is the name of the trial whose assignments are recommended
+	AnnotationRecommendedTrial = "redskyops.dev/recommended-trial"
+	// AnnotationRecommendedValues contains the metric values observed for the recommended trial
+	AnnotationRecommendedValues = "redskyops.dev/recommended-values"
+	// AnnotationRecommendationTime is the time the experiment producing the recommendation finished
+	AnnotationRecommendationTime = "redskyops.dev/recommendation-time"
+
+	// LabelRecommendation identifies objects containing a recommended configuration
+	LabelRecommendation = "redskyops.dev/recommendation"
 )
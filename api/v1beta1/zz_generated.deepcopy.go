@@ -23,12 +23,63 @@ package v1beta1
 import (
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiveProvenance) DeepCopyInto(out *ArchiveProvenance) {
+	*out = *in
+	in.ExperimentCreationTimestamp.DeepCopyInto(&out.ExperimentCreationTimestamp)
+	in.ArchiveTime.DeepCopyInto(&out.ArchiveTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveProvenance.
+func (in *ArchiveProvenance) DeepCopy() *ArchiveProvenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiveProvenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchivedTrial) DeepCopyInto(out *ArchivedTrial) {
+	*out = *in
+	if in.Assignments != nil {
+		in, out := &in.Assignments, &out.Assignments
+		*out = make([]Assignment, len(*in))
+		copy(*out, *in)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]Value, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchivedTrial.
+func (in *ArchivedTrial) DeepCopy() *ArchivedTrial {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchivedTrial)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Assignment) DeepCopyInto(out *Assignment) {
 	*out = *in
@@ -45,6 +96,26 @@ func (in *Assignment) DeepCopy() *Assignment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CategoricalValue) DeepCopyInto(out *CategoricalValue) {
+	*out = *in
+	if in.Ordinal != nil {
+		in, out := &in.Ordinal, &out.Ordinal
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CategoricalValue.
+func (in *CategoricalValue) DeepCopy() *CategoricalValue {
+	if in == nil {
+		return nil
+	}
+	out := new(CategoricalValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapHelmValuesFromSource) DeepCopyInto(out *ConfigMapHelmValuesFromSource) {
 	*out = *in
@@ -86,6 +157,21 @@ func (in *Constraint) DeepCopy() *Constraint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DerivedValue) DeepCopyInto(out *DerivedValue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DerivedValue.
+func (in *DerivedValue) DeepCopy() *DerivedValue {
+	if in == nil {
+		return nil
+	}
+	out := new(DerivedValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Experiment) DeepCopyInto(out *Experiment) {
 	*out = *in
@@ -113,6 +199,89 @@ func (in *Experiment) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExperimentArchive) DeepCopyInto(out *ExperimentArchive) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExperimentArchive.
+func (in *ExperimentArchive) DeepCopy() *ExperimentArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(ExperimentArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExperimentArchive) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExperimentArchiveList) DeepCopyInto(out *ExperimentArchiveList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ExperimentArchive, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExperimentArchiveList.
+func (in *ExperimentArchiveList) DeepCopy() *ExperimentArchiveList {
+	if in == nil {
+		return nil
+	}
+	out := new(ExperimentArchiveList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExperimentArchiveList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExperimentArchiveSpec) DeepCopyInto(out *ExperimentArchiveSpec) {
+	*out = *in
+	in.Experiment.DeepCopyInto(&out.Experiment)
+	in.ExperimentStatus.DeepCopyInto(&out.ExperimentStatus)
+	if in.Trials != nil {
+		in, out := &in.Trials, &out.Trials
+		*out = make([]ArchivedTrial, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Provenance.DeepCopyInto(&out.Provenance)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExperimentArchiveSpec.
+func (in *ExperimentArchiveSpec) DeepCopy() *ExperimentArchiveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExperimentArchiveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExperimentCondition) DeepCopyInto(out *ExperimentCondition) {
 	*out = *in
@@ -165,16 +334,36 @@ func (in *ExperimentList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExperimentSpec) DeepCopyInto(out *ExperimentSpec) {
 	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxConcurrentTrials != nil {
+		in, out := &in.MaxConcurrentTrials, &out.MaxConcurrentTrials
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Repetitions != nil {
+		in, out := &in.Repetitions, &out.Repetitions
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Optimization != nil {
 		in, out := &in.Optimization, &out.Optimization
 		*out = make([]Optimization, len(*in))
 		copy(*out, *in)
 	}
+	if in.LocalOptimizer != nil {
+		in, out := &in.LocalOptimizer, &out.LocalOptimizer
+		*out = new(LocalOptimizer)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Parameters != nil {
 		in, out := &in.Parameters, &out.Parameters
 		*out = make([]Parameter, len(*in))
@@ -196,6 +385,16 @@ func (in *ExperimentSpec) DeepCopyInto(out *ExperimentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ValueWebhook != nil {
+		in, out := &in.ValueWebhook, &out.ValueWebhook
+		*out = new(ValueWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Derived != nil {
+		in, out := &in.Derived, &out.Derived
+		*out = make([]DerivedValue, len(*in))
+		copy(*out, *in)
+	}
 	if in.Patches != nil {
 		in, out := &in.Patches, &out.Patches
 		*out = make([]PatchTemplate, len(*in))
@@ -218,6 +417,21 @@ func (in *ExperimentSpec) DeepCopyInto(out *ExperimentSpec) {
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TrialNaming != nil {
+		in, out := &in.TrialNaming, &out.TrialNaming
+		*out = new(TrialNaming)
+		**out = **in
+	}
+	if in.TrialRetention != nil {
+		in, out := &in.TrialRetention, &out.TrialRetention
+		*out = new(TrialRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(TrialSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 	in.TrialTemplate.DeepCopyInto(&out.TrialTemplate)
 }
 
@@ -314,6 +528,26 @@ func (in *HelmValuesFromSource) DeepCopy() *HelmValuesFromSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalOptimizer) DeepCopyInto(out *LocalOptimizer) {
+	*out = *in
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalOptimizer.
+func (in *LocalOptimizer) DeepCopy() *LocalOptimizer {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalOptimizer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metric) DeepCopyInto(out *Metric) {
 	*out = *in
@@ -327,11 +561,61 @@ func (in *Metric) DeepCopyInto(out *Metric) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.TargetValue != nil {
+		in, out := &in.TargetValue, &out.TargetValue
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FailFast != nil {
+		in, out := &in.FailFast, &out.FailFast
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	if in.Optimize != nil {
 		in, out := &in.Optimize, &out.Optimize
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Aggregation != nil {
+		in, out := &in.Aggregation, &out.Aggregation
+		*out = new(MetricAggregation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(MetricHTTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(MetricTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryDelay != nil {
+		in, out := &in.RetryDelay, &out.RetryDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SampleInterval != nil {
+		in, out := &in.SampleInterval, &out.SampleInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timestamps != nil {
+		in, out := &in.Timestamps, &out.Timestamps
+		*out = new(MetricTimestamps)
+		**out = **in
+	}
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(ResourceTarget)
@@ -349,11 +633,158 @@ func (in *Metric) DeepCopy() *Metric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricAggregation) DeepCopyInto(out *MetricAggregation) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricAggregation.
+func (in *MetricAggregation) DeepCopy() *MetricAggregation {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricAggregation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricHTTP) DeepCopyInto(out *MetricHTTP) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]MetricHTTPHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricHTTP.
+func (in *MetricHTTP) DeepCopy() *MetricHTTP {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricHTTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricHTTPHeader) DeepCopyInto(out *MetricHTTPHeader) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricHTTPHeader.
+func (in *MetricHTTPHeader) DeepCopy() *MetricHTTPHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricHTTPHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTLS) DeepCopyInto(out *MetricTLS) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(MetricTLSData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = new(MetricTLSData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(MetricTLSData)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTLS.
+func (in *MetricTLS) DeepCopy() *MetricTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTLSData) DeepCopyInto(out *MetricTLSData) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTLSData.
+func (in *MetricTLSData) DeepCopy() *MetricTLSData {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTLSData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTimestamps) DeepCopyInto(out *MetricTimestamps) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTimestamps.
+func (in *MetricTimestamps) DeepCopy() *MetricTimestamps {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTimestamps)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceTemplateSpec) DeepCopyInto(out *NamespaceTemplateSpec) {
 	*out = *in
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		*out = new(corev1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(corev1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(networkingv1.NetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceTemplateSpec.
@@ -367,16 +798,115 @@ func (in *NamespaceTemplateSpec) DeepCopy() *NamespaceTemplateSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Optimization) DeepCopyInto(out *Optimization) {
+func (in *Optimization) DeepCopyInto(out *Optimization) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Optimization.
+func (in *Optimization) DeepCopy() *Optimization {
+	if in == nil {
+		return nil
+	}
+	out := new(Optimization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizeRecommendation) DeepCopyInto(out *OptimizeRecommendation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizeRecommendation.
+func (in *OptimizeRecommendation) DeepCopy() *OptimizeRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizeRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OptimizeRecommendation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizeRecommendationList) DeepCopyInto(out *OptimizeRecommendationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OptimizeRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizeRecommendationList.
+func (in *OptimizeRecommendationList) DeepCopy() *OptimizeRecommendationList {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizeRecommendationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OptimizeRecommendationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizeRecommendationSpec) DeepCopyInto(out *OptimizeRecommendationSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizeRecommendationSpec.
+func (in *OptimizeRecommendationSpec) DeepCopy() *OptimizeRecommendationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizeRecommendationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizeRecommendationStatus) DeepCopyInto(out *OptimizeRecommendationStatus) {
 	*out = *in
+	if in.Recommendation != nil {
+		in, out := &in.Recommendation, &out.Recommendation
+		*out = new(RecommendedPodResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Optimization.
-func (in *Optimization) DeepCopy() *Optimization {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizeRecommendationStatus.
+func (in *OptimizeRecommendationStatus) DeepCopy() *OptimizeRecommendationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Optimization)
+	out := new(OptimizeRecommendationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -409,6 +939,23 @@ func (in *Parameter) DeepCopyInto(out *Parameter) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ValueInfo != nil {
+		in, out := &in.ValueInfo, &out.ValueInfo
+		*out = make([]CategoricalValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Encoding != nil {
+		in, out := &in.Encoding, &out.Encoding
+		*out = new(ParameterEncoding)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = new(ParameterDependency)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
@@ -421,6 +968,41 @@ func (in *Parameter) DeepCopy() *Parameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterDependency) DeepCopyInto(out *ParameterDependency) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterDependency.
+func (in *ParameterDependency) DeepCopy() *ParameterDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterEncoding) DeepCopyInto(out *ParameterEncoding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterEncoding.
+func (in *ParameterEncoding) DeepCopy() *ParameterEncoding {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterEncoding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ParameterSelector) DeepCopyInto(out *ParameterSelector) {
 	*out = *in
@@ -497,6 +1079,36 @@ func (in *PatchTemplate) DeepCopy() *PatchTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusSetup) DeepCopyInto(out *PrometheusSetup) {
+	*out = *in
+	if in.ScrapeInterval != nil {
+		in, out := &in.ScrapeInterval, &out.ScrapeInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSetup.
+func (in *PrometheusSetup) DeepCopy() *PrometheusSetup {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusSetup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadinessCheck) DeepCopyInto(out *ReadinessCheck) {
 	*out = *in
@@ -515,6 +1127,11 @@ func (in *ReadinessCheck) DeepCopyInto(out *ReadinessCheck) {
 		in, out := &in.LastCheckTime, &out.LastCheckTime
 		*out = (*in).DeepCopy()
 	}
+	if in.Probe != nil {
+		in, out := &in.Probe, &out.Probe
+		*out = new(ReadinessProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessCheck.
@@ -527,6 +1144,82 @@ func (in *ReadinessCheck) DeepCopy() *ReadinessCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessProbe) DeepCopyInto(out *ReadinessProbe) {
+	*out = *in
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(corev1.HTTPGetAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TCPSocket != nil {
+		in, out := &in.TCPSocket, &out.TCPSocket
+		*out = new(corev1.TCPSocketAction)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessProbe.
+func (in *ReadinessProbe) DeepCopy() *ReadinessProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendedContainerResources) DeepCopyInto(out *RecommendedContainerResources) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.UpperBound != nil {
+		in, out := &in.UpperBound, &out.UpperBound
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendedContainerResources.
+func (in *RecommendedContainerResources) DeepCopy() *RecommendedContainerResources {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendedContainerResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendedPodResources) DeepCopyInto(out *RecommendedPodResources) {
+	*out = *in
+	if in.ContainerRecommendations != nil {
+		in, out := &in.ContainerRecommendations, &out.ContainerRecommendations
+		*out = make([]RecommendedContainerResources, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendedPodResources.
+func (in *RecommendedPodResources) DeepCopy() *RecommendedPodResources {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendedPodResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceTarget) DeepCopyInto(out *ResourceTarget) {
 	*out = *in
@@ -567,6 +1260,11 @@ func (in *SetupTask) DeepCopyInto(out *SetupTask) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.HelmValues != nil {
 		in, out := &in.HelmValues, &out.HelmValues
 		*out = make([]HelmValue, len(*in))
@@ -581,6 +1279,11 @@ func (in *SetupTask) DeepCopyInto(out *SetupTask) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(PrometheusSetup)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SetupTask.
@@ -659,6 +1362,31 @@ func (in *Trial) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialArtifacts) DeepCopyInto(out *TrialArtifacts) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerLogs != nil {
+		in, out := &in.ContainerLogs, &out.ContainerLogs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialArtifacts.
+func (in *TrialArtifacts) DeepCopy() *TrialArtifacts {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialArtifacts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrialCondition) DeepCopyInto(out *TrialCondition) {
 	*out = *in
@@ -676,6 +1404,43 @@ func (in *TrialCondition) DeepCopy() *TrialCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialEvent) DeepCopyInto(out *TrialEvent) {
+	*out = *in
+	in.LastTimestamp.DeepCopyInto(&out.LastTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialEvent.
+func (in *TrialEvent) DeepCopy() *TrialEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialHook) DeepCopyInto(out *TrialHook) {
+	*out = *in
+	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialHook.
+func (in *TrialHook) DeepCopy() *TrialHook {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrialList) DeepCopyInto(out *TrialList) {
 	*out = *in
@@ -708,6 +1473,41 @@ func (in *TrialList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialNaming) DeepCopyInto(out *TrialNaming) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialNaming.
+func (in *TrialNaming) DeepCopy() *TrialNaming {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialNaming)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialPreemption) DeepCopyInto(out *TrialPreemption) {
+	*out = *in
+	if in.PriorityClassNames != nil {
+		in, out := &in.PriorityClassNames, &out.PriorityClassNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialPreemption.
+func (in *TrialPreemption) DeepCopy() *TrialPreemption {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialPreemption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrialReadinessGate) DeepCopyInto(out *TrialReadinessGate) {
 	*out = *in
@@ -721,6 +1521,11 @@ func (in *TrialReadinessGate) DeepCopyInto(out *TrialReadinessGate) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Probe != nil {
+		in, out := &in.Probe, &out.Probe
+		*out = new(ReadinessProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialReadinessGate.
@@ -733,6 +1538,66 @@ func (in *TrialReadinessGate) DeepCopy() *TrialReadinessGate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialRetention) DeepCopyInto(out *TrialRetention) {
+	*out = *in
+	if in.KeepCompletedTrials != nil {
+		in, out := &in.KeepCompletedTrials, &out.KeepCompletedTrials
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialRetention.
+func (in *TrialRetention) DeepCopy() *TrialRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialSchedule) DeepCopyInto(out *TrialSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]TrialScheduleWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialSchedule.
+func (in *TrialSchedule) DeepCopy() *TrialSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrialScheduleWindow) DeepCopyInto(out *TrialScheduleWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialScheduleWindow.
+func (in *TrialScheduleWindow) DeepCopy() *TrialScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TrialScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrialSpec) DeepCopyInto(out *TrialSpec) {
 	*out = *in
@@ -766,6 +1631,11 @@ func (in *TrialSpec) DeepCopyInto(out *TrialSpec) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.WarmupDuration != nil {
+		in, out := &in.WarmupDuration, &out.WarmupDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.TTLSecondsAfterFinished != nil {
 		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
 		*out = new(int32)
@@ -783,6 +1653,23 @@ func (in *TrialSpec) DeepCopyInto(out *TrialSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Preemption != nil {
+		in, out := &in.Preemption, &out.Preemption
+		*out = new(TrialPreemption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = new(TrialArtifacts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]TrialHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = make([]Value, len(*in))
@@ -853,6 +1740,13 @@ func (in *TrialStatus) DeepCopyInto(out *TrialStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]TrialEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrialStatus.
@@ -896,3 +1790,23 @@ func (in *Value) DeepCopy() *Value {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueWebhook) DeepCopyInto(out *ValueWebhook) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueWebhook.
+func (in *ValueWebhook) DeepCopy() *ValueWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
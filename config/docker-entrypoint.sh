@@ -12,6 +12,38 @@ case "$1" in
     fi
 
     kustomize edit set nameprefix "$namePrefix"
+
+    # Apply the built-in Prometheus configuration
+    if [ -n "$PROMETHEUS_SCRAPE_INTERVAL" ]; then
+      sed -i "s/scrape_interval: .*/scrape_interval: $PROMETHEUS_SCRAPE_INTERVAL/" prometheus-server-configmap.yaml
+    fi
+    if [ -n "$PROMETHEUS_SCRAPE_TIMEOUT" ]; then
+      sed -i "s/scrape_timeout: .*/scrape_timeout: $PROMETHEUS_SCRAPE_TIMEOUT/" prometheus-server-configmap.yaml
+    fi
+    if [ -n "$PROMETHEUS_SCRAPE_CONFIGS" ]; then
+      # The additional scrape configurations are appended to the end of the "scrape_configs" list
+      echo "$PROMETHEUS_SCRAPE_CONFIGS" | base64 -d | sed 's/^/    /' >> prometheus-server-configmap.yaml
+    fi
+    if [ -n "$PROMETHEUS_RETENTION" ]; then
+      sed -i "s/--storage.tsdb.retention.time=.*/--storage.tsdb.retention.time=$PROMETHEUS_RETENTION/" prometheus-server-deployment.yaml
+    fi
+    if [ -n "$PROMETHEUS_RESOURCES" ]; then
+      # Note, this heredoc block must be indented with tabs
+      cat <<-EOF >"prometheus_resources.yaml"
+		apiVersion: apps/v1
+		kind: Deployment
+		metadata:
+		  name: prometheus-server
+		spec:
+		  template:
+		    spec:
+		      containers:
+		      - name: prometheus-server
+		        resources: $PROMETHEUS_RESOURCES
+		EOF
+      kustomize edit add patch --path prometheus_resources.yaml
+    fi
+
     waitFn() {
       kubectl wait --for condition=Available=true --timeout 120s deployment.apps ${namePrefix}prometheus-server
     }
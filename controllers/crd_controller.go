@@ -0,0 +1,117 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/thestormforge/optimize-controller/internal/controller"
+	"github.com/thestormforge/optimize-controller/internal/crd"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CRDReconciler checks the installed custom resource definitions against the versions the controller was built with
+type CRDReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// AutoPatch enables updating the installed custom resource definitions when they do not match, the controller
+	// must also be granted permission to update custom resource definitions
+	AutoPatch bool
+
+	expected map[string]*unstructured.Unstructured
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
+func (r *CRDReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	// Ignore everything that isn't ours
+	expected, ok := r.expected[req.Name]
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	installed := &unstructured.Unstructured{}
+	installed.SetGroupVersionKind(crd.GroupVersionKind)
+	if err := r.Get(ctx, req.NamespacedName, installed); err != nil {
+		return ctrl.Result{}, controller.IgnoreNotFound(err)
+	}
+
+	if result, err := r.checkDrift(ctx, expected, installed); result != nil {
+		return *result, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *CRDReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	var err error
+	if r.expected, err = crd.Expected(); err != nil {
+		return err
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(crd.GroupVersionKind)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("crd").
+		For(u).
+		Complete(r)
+}
+
+// checkDrift compares the installed custom resource definition to the expected definition, reporting (and optionally
+// patching) any differences
+func (r *CRDReconciler) checkDrift(ctx context.Context, expected, installed *unstructured.Unstructured) (*ctrl.Result, error) {
+	gauge := controller.CustomResourceDefinitionDrift.WithLabelValues(installed.GetName())
+
+	drift := crd.Drift(expected, installed)
+	if len(drift) == 0 {
+		gauge.Set(0)
+		return nil, nil
+	}
+
+	gauge.Set(1)
+	versions := strings.Join(drift, ", ")
+	r.Log.Info("Custom resource definition does not match the controller", "crd", installed.GetName(), "versions", versions)
+	if !r.AutoPatch {
+		r.Recorder.Eventf(installed, corev1.EventTypeWarning, "SchemaDrift", "Installed versions (%s) do not match the controller, the custom resource definition should be re-installed", versions)
+		return nil, nil
+	}
+
+	if err := crd.Patch(expected, installed); err != nil {
+		return &ctrl.Result{}, err
+	}
+	if err := r.Update(ctx, installed); err != nil {
+		if apierrs.IsForbidden(err) {
+			r.Recorder.Eventf(installed, corev1.EventTypeWarning, "SchemaDrift", "Installed versions (%s) do not match the controller and the controller is not allowed to update them", versions)
+			return nil, nil
+		}
+		return controller.RequeueConflict(err)
+	}
+
+	r.Recorder.Eventf(installed, corev1.EventTypeNormal, "SchemaPatched", "Updated versions (%s) to match the controller", versions)
+	gauge.Set(0)
+	return &ctrl.Result{}, nil
+}
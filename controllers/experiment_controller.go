@@ -18,6 +18,8 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
@@ -25,8 +27,13 @@ import (
 	"github.com/thestormforge/optimize-controller/internal/experiment"
 	"github.com/thestormforge/optimize-controller/internal/meta"
 	"github.com/thestormforge/optimize-controller/internal/trial"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -38,10 +45,20 @@ import (
 type ExperimentReconciler struct {
 	client.Client
 	Log logr.Logger
+	// Retention is the optional policy used to prune the results of finished experiments
+	Retention *experiment.RetentionPolicy
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
 }
 
-// +kubebuilder:rbac:groups=redskyops.dev,resources=experiments;experiments/finalizers,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=redskyops.dev,resources=experiments;experiments/finalizers,verbs=get;list;watch;update;delete
 // +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=list;watch;update;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=list
+// +kubebuilder:rbac:groups=redskyops.dev,resources=experimentarchives,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update;deletecollection
+// +kubebuilder:rbac:groups=batch;extensions,resources=jobs,verbs=list;watch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=list
+// +kubebuilder:rbac:groups=redskyops.dev,resources=optimizerecommendations,verbs=get;list;watch;create;update
 
 func (r *ExperimentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -52,10 +69,18 @@ func (r *ExperimentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	}
 
 	trialList := &redskyv1beta1.TrialList{}
-	if err := r.listTrials(ctx, trialList, exp.TrialSelector()); err != nil {
+	if err := listTrials(ctx, r, trialList, exp); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	if result, err := r.checkDependencies(ctx, exp, trialList); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.checkSchedule(ctx, exp); result != nil {
+		return *result, err
+	}
+
 	if result, err := r.updateStatus(ctx, exp, trialList); result != nil {
 		return *result, err
 	}
@@ -64,10 +89,30 @@ func (r *ExperimentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		return *result, err
 	}
 
+	if result, err := r.archive(ctx, exp, trialList); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.recommend(ctx, exp, trialList); result != nil {
+		return *result, err
+	}
+
 	if result, err := r.cleanupTrials(ctx, exp, trialList); result != nil {
 		return *result, err
 	}
 
+	if result, err := r.teardownNamespaces(ctx, exp, trialList); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.collectGarbage(ctx, exp, trialList); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.enforceRetention(ctx, exp, trialList); result != nil {
+		return *result, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -87,6 +132,68 @@ func trialToExperimentRequest(o handler.MapObject) []reconcile.Request {
 	return nil
 }
 
+// checkDependencies will hold the experiment in a waiting state until all of the experiments it depends on complete
+func (r *ExperimentReconciler) checkDependencies(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	if !experiment.IsWaiting(exp) || !exp.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+
+	blocker, err := experiment.WaitingFor(exp, func(name string) (*redskyv1beta1.Experiment, error) {
+		dep := &redskyv1beta1.Experiment{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: exp.Namespace, Name: name}, dep); err != nil {
+			return nil, controller.IgnoreNotFound(err)
+		}
+		return dep, nil
+	})
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	// Record the dependency we are blocked on, or clear the condition so trials can start
+	if blocker != "" {
+		msg := fmt.Sprintf("Waiting for experiment %q to complete", blocker)
+		for _, c := range exp.Status.Conditions {
+			if c.Type == redskyv1beta1.ExperimentWaiting && c.Status == corev1.ConditionTrue && c.Message == msg {
+				return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).DependencyCheck}, nil
+			}
+		}
+		experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentWaiting, corev1.ConditionTrue, "DependencyNotComplete", msg, nil)
+	} else {
+		experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentWaiting, corev1.ConditionFalse, "DependenciesComplete", "", nil)
+	}
+
+	experiment.UpdateStatus(exp, trialList)
+	if err := r.Update(ctx, exp); err != nil {
+		return controller.RequeueConflict(err)
+	}
+	if blocker != "" {
+		return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).DependencyCheck}, nil
+	}
+	return &ctrl.Result{}, nil
+}
+
+// checkSchedule will record an invalid trial schedule on the experiment status, only the creation of new trials is
+// blocked by an invalid schedule
+func (r *ExperimentReconciler) checkSchedule(ctx context.Context, exp *redskyv1beta1.Experiment) (*ctrl.Result, error) {
+	if err := experiment.ValidateSchedule(exp.Spec.Schedule); err != nil {
+		for _, c := range exp.Status.Conditions {
+			if c.Type == redskyv1beta1.ExperimentScheduleInvalid && c.Status == corev1.ConditionTrue && c.Message == err.Error() {
+				return nil, nil
+			}
+		}
+		experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentScheduleInvalid, corev1.ConditionTrue, "InvalidSchedule", err.Error(), nil)
+	} else if experiment.CheckCondition(&exp.Status, redskyv1beta1.ExperimentScheduleInvalid, corev1.ConditionTrue) {
+		experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentScheduleInvalid, corev1.ConditionFalse, "ScheduleValid", "", nil)
+	} else {
+		return nil, nil
+	}
+
+	if err := r.Update(ctx, exp); err != nil {
+		return controller.RequeueConflict(err)
+	}
+	return &ctrl.Result{}, nil
+}
+
 // updateStatus will ensure the experiment and trial status matches the current state
 func (r *ExperimentReconciler) updateStatus(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
 	var dirty bool
@@ -117,13 +224,24 @@ func (r *ExperimentReconciler) updateTrialStatus(ctx context.Context, trialList
 
 		var dirty bool
 
-		// If the trial is not finished, but it has been observed, mark it as complete
-		if !trial.IsFinished(t) && trial.CheckCondition(&t.Status, redskyv1beta1.TrialObserved, corev1.ConditionTrue) {
+		// If the trial is not finished, but it has been observed (and the post-trial hooks are done), mark it as complete
+		if !trial.IsFinished(t) && trial.CheckCondition(&t.Status, redskyv1beta1.TrialObserved, corev1.ConditionTrue) &&
+			!trial.HooksPending(t, redskyv1beta1.TrialHookPostTrial) {
 			now := metav1.Now()
 			trial.ApplyCondition(&t.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue, "", "", &now)
 			dirty = true
 		}
 
+		// Attach recent cluster events to failed trials to help explain the failure
+		if trial.NeedsEvents(t, metav1.Now().Time) {
+			events, err := r.listEvents(ctx, t.Namespace)
+			if err != nil {
+				return &ctrl.Result{}, err
+			}
+			t.Status.Events = trial.FailureEvents(t, events)
+			dirty = len(t.Status.Events) > 0 || dirty
+		}
+
 		// Update the trial status
 		dirty = trial.UpdateStatus(t) || dirty
 
@@ -137,6 +255,142 @@ func (r *ExperimentReconciler) updateTrialStatus(ctx context.Context, trialList
 	return nil, nil
 }
 
+// listEvents returns the events from the supplied namespace along with any node events
+func (r *ExperimentReconciler) listEvents(ctx context.Context, namespace string) ([]corev1.Event, error) {
+	var events []corev1.Event
+	for _, opt := range []client.ListOption{client.InNamespace(namespace), client.MatchingFields{"involvedObject.kind": "Node"}} {
+		// Use an unstructured list so the lookup does not go through the cache
+		ul := &unstructured.UnstructuredList{}
+		ul.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("EventList"))
+		if err := r.List(ctx, ul, opt); err != nil {
+			return nil, err
+		}
+
+		el := &corev1.EventList{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(ul.UnstructuredContent(), el); err != nil {
+			return nil, err
+		}
+		events = append(events, el.Items...)
+	}
+	return events, nil
+}
+
+// archive will record the outcome of a finished experiment once all of its trials have finished
+func (r *ExperimentReconciler) archive(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	if !experiment.IsFinished(exp) || !exp.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	for i := range trialList.Items {
+		if !trial.IsFinished(&trialList.Items[i]) && trialList.Items[i].DeletionTimestamp.IsZero() {
+			return nil, nil
+		}
+	}
+
+	// Archives are immutable, only create one if it does not exist yet
+	key := client.ObjectKey{Namespace: exp.Namespace, Name: experiment.ArchiveName(exp)}
+	if err := r.Get(ctx, key, &redskyv1beta1.ExperimentArchive{}); err == nil {
+		return nil, nil
+	} else if !apierrs.IsNotFound(err) {
+		return &ctrl.Result{}, err
+	}
+
+	archive, err := experiment.NewArchive(exp, trialList, metav1.Now())
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, archive); err != nil && !apierrs.IsAlreadyExists(err) {
+		return &ctrl.Result{}, err
+	}
+
+	r.Log.Info("Archived experiment", "experiment", fmt.Sprintf("%s/%s", exp.Namespace, exp.Name), "archive", archive.Name)
+	return nil, nil
+}
+
+// recommend will publish the best configuration found by a finished experiment for other consumers
+func (r *ExperimentReconciler) recommend(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	if !experiment.IsFinished(exp) || !exp.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	if recommendation := experiment.NewRecommendation(exp, trialList); recommendation != nil {
+		if result, err := r.publishConfigMap(ctx, exp, recommendation); result != nil {
+			return result, err
+		}
+	}
+
+	recommendations, err := experiment.NewResourceRecommendations(exp, trialList)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	for _, recommendation := range recommendations {
+		if result, err := r.publishResourceRecommendation(ctx, exp, recommendation); result != nil {
+			return result, err
+		}
+	}
+
+	return nil, nil
+}
+
+// publishConfigMap creates or replaces the config map containing the recommended assignments
+func (r *ExperimentReconciler) publishConfigMap(ctx context.Context, exp *redskyv1beta1.Experiment, recommendation *corev1.ConfigMap) (*ctrl.Result, error) {
+	// Use an unstructured config map so the lookup does not go through the cache
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	current := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: recommendation.Namespace, Name: recommendation.Name}, u); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return &ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, recommendation); err != nil {
+			return &ctrl.Result{}, err
+		}
+	} else if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), current); err != nil {
+		return &ctrl.Result{}, err
+	} else if experiment.ReplacesRecommendation(current, recommendation) {
+		current.Labels = recommendation.Labels
+		current.Annotations = recommendation.Annotations
+		current.Data = recommendation.Data
+		if err := r.Update(ctx, current); err != nil {
+			return controller.RequeueConflict(err)
+		}
+	} else {
+		return nil, nil
+	}
+
+	r.Log.Info("Published recommendation", "experiment", fmt.Sprintf("%s/%s", exp.Namespace, exp.Name), "configMap", recommendation.Name)
+	return nil, nil
+}
+
+// publishResourceRecommendation creates or replaces the recommended resources of a single workload
+func (r *ExperimentReconciler) publishResourceRecommendation(ctx context.Context, exp *redskyv1beta1.Experiment, recommendation *redskyv1beta1.OptimizeRecommendation) (*ctrl.Result, error) {
+	now := metav1.Now()
+	recommendation.Status.LastUpdateTime = &now
+
+	current := &redskyv1beta1.OptimizeRecommendation{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: recommendation.Namespace, Name: recommendation.Name}, current); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return &ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, recommendation); err != nil {
+			return &ctrl.Result{}, err
+		}
+	} else if experiment.ReplacesRecommendation(current, recommendation) {
+		current.Labels = recommendation.Labels
+		current.Annotations = recommendation.Annotations
+		current.Spec = recommendation.Spec
+		current.Status = recommendation.Status
+		if err := r.Update(ctx, current); err != nil {
+			return controller.RequeueConflict(err)
+		}
+	} else {
+		return nil, nil
+	}
+
+	r.Log.Info("Published recommendation", "experiment", fmt.Sprintf("%s/%s", exp.Namespace, exp.Name), "optimizeRecommendation", recommendation.Name)
+	return nil, nil
+}
+
 // cleanupTrials will delete any trials whose TTL has expired or are active past
 func (r *ExperimentReconciler) cleanupTrials(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
 	for i := range trialList.Items {
@@ -158,11 +412,128 @@ func (r *ExperimentReconciler) cleanupTrials(ctx context.Context, exp *redskyv1b
 	return nil, nil
 }
 
-// listTrials retrieves the list of trial objects matching the specified selector
-func (r *ExperimentReconciler) listTrials(ctx context.Context, trialList *redskyv1beta1.TrialList, selector *metav1.LabelSelector) error {
-	matchingSelector, err := meta.MatchingSelector(selector)
-	if err != nil {
-		return err
+// teardownNamespaces will delete namespaces created from the namespace template once their trials have finished
+func (r *ExperimentReconciler) teardownNamespaces(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	if exp.Spec.NamespaceTemplate == nil || !exp.Spec.NamespaceTemplate.DeleteAfterTrial {
+		return nil, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	matchingLabels := client.MatchingLabels{redskyv1beta1.LabelExperiment: exp.Name, redskyv1beta1.LabelTrialRole: "trialSetup"}
+	if err := r.List(ctx, namespaceList, matchingLabels); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	for _, n := range experiment.ExpiredTrialNamespaces(exp, namespaceList, trialList) {
+		// Like namespace creation, deleting namespaces requires permissions which must be explicitly granted
+		if err := r.Delete(ctx, n); apierrs.IsForbidden(err) {
+			r.Log.Info("Unable to delete trial namespace", "namespace", n.Name, "message", err.Error())
+			continue
+		} else if controller.IgnoreNotFound(err) != nil {
+			return &ctrl.Result{}, err
+		}
+		r.Log.Info("Deleted trial namespace", "namespace", n.Name)
+	}
+
+	return nil, nil
+}
+
+// collectGarbage will delete excess finished trials and the jobs (and config maps) of expired trials according to the
+// trial retention of the experiment
+func (r *ExperimentReconciler) collectGarbage(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	if exp.Spec.TrialRetention == nil || !exp.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+
+	for _, t := range experiment.ExcessTrials(exp, trialList) {
+		if err := r.Delete(ctx, t, client.PropagationPolicy(metav1.DeletePropagationBackground)); controller.IgnoreNotFound(err) != nil {
+			return &ctrl.Result{}, err
+		}
+		r.Log.Info("Deleted excess trial", "trial", fmt.Sprintf("%s/%s", t.Namespace, t.Name))
 	}
-	return r.List(ctx, trialList, matchingSelector)
+
+	// Trials can be in any namespace, only the experiment name is used to find their jobs
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.MatchingLabels{redskyv1beta1.LabelExperiment: exp.Name}); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	trials := make(map[types.NamespacedName]*redskyv1beta1.Trial, len(trialList.Items))
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		trials[types.NamespacedName{Namespace: t.Namespace, Name: t.Name}] = t
+	}
+
+	now := time.Now()
+	var requeueAfter time.Duration
+	collected := make(map[*redskyv1beta1.Trial]bool)
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+
+		// Setup jobs are left for the setup controller, it would otherwise re-create them
+		if job.Labels[redskyv1beta1.LabelTrialRole] == "trialSetup" || !job.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		t := trials[types.NamespacedName{Namespace: job.Namespace, Name: job.Labels[redskyv1beta1.LabelTrial]}]
+		if t == nil {
+			continue
+		}
+
+		expiration := experiment.TrialObjectsExpiration(exp, t)
+		if expiration.IsZero() {
+			continue
+		}
+		if d := expiration.Sub(now); d > 0 {
+			if requeueAfter == 0 || d < requeueAfter {
+				requeueAfter = d
+			}
+			continue
+		}
+
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); controller.IgnoreNotFound(err) != nil {
+			return &ctrl.Result{}, err
+		}
+
+		// Config maps are collected along with the first job of the trial
+		if !collected[t] {
+			collected[t] = true
+			if err := r.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace(t.Namespace), client.MatchingLabels{redskyv1beta1.LabelTrial: t.Name}); err != nil {
+				return &ctrl.Result{}, err
+			}
+			r.Log.Info("Deleted expired trial jobs", "trial", fmt.Sprintf("%s/%s", t.Namespace, t.Name))
+		}
+	}
+
+	// Come back when the next trial expires
+	if requeueAfter > 0 {
+		return &ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	return nil, nil
+}
+
+// enforceRetention will prune the results of finished experiments according to the retention policy
+func (r *ExperimentReconciler) enforceRetention(ctx context.Context, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	if !r.Retention.Enabled() || !experiment.IsFinished(exp) || !exp.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if r.Retention.PruneExperiment(exp, now) {
+		if err := r.Delete(ctx, exp); err != nil {
+			return &ctrl.Result{}, controller.IgnoreNotFound(err)
+		}
+		r.Log.Info("Pruned experiment", "experiment", fmt.Sprintf("%s/%s", exp.Namespace, exp.Name))
+		return &ctrl.Result{}, nil
+	}
+
+	for _, t := range r.Retention.PruneTrials(exp, trialList, now) {
+		if err := r.Delete(ctx, t); controller.IgnoreNotFound(err) != nil {
+			return &ctrl.Result{}, err
+		}
+		r.Log.Info("Pruned trial", "trial", fmt.Sprintf("%s/%s", t.Namespace, t.Name))
+	}
+
+	// Keep checking as the remaining results age
+	return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).RetentionCheck}, nil
 }
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/controller"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// HookReconciler reconciles a Trial object for pre-trial and post-trial hooks
+type HookReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// Jobs is the cache of jobs created by the controller, the manager cache is used if nil
+	Jobs *controller.JobCache
+}
+
+// +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=batch;extensions,resources=jobs,verbs=list;watch;create
+
+func (r *HookReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	now := metav1.Now()
+
+	t := &redskyv1beta1.Trial{}
+	if err := r.Get(ctx, req.NamespacedName, t); err != nil {
+		return ctrl.Result{}, controller.IgnoreNotFound(err)
+	}
+
+	// Determine which hooks (if any) need to run
+	phase, ok := r.hookPhase(t)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	// Find the hook jobs for this trial
+	jobList := &batchv1.JobList{}
+	hookJobLabels := map[string]string{redskyv1beta1.LabelTrial: t.Name, redskyv1beta1.LabelTrialRole: "trialHook"}
+	if err := r.List(ctx, jobList, client.InNamespace(t.Namespace), client.MatchingLabels(hookJobLabels)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Run the hooks
+	if result, err := r.runHooks(ctx, t, phase, jobList, &now); result != nil {
+		return *result, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *HookReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("hook").
+		For(&redskyv1beta1.Trial{})
+	return controller.WatchJobs(b, r.Jobs).
+		Complete(r)
+}
+
+// hookPhase returns the phase of the hooks that should be running for the supplied trial
+func (r *HookReconciler) hookPhase(t *redskyv1beta1.Trial) (redskyv1beta1.TrialHookPhase, bool) {
+	// Ignore deleted and finished trials
+	if !t.DeletionTimestamp.IsZero() || trial.IsFinished(t) {
+		return "", false
+	}
+
+	// Pre-trial hooks run once the trial is ready but before the trial run job starts
+	if trial.HooksPending(t, redskyv1beta1.TrialHookPreTrial) &&
+		trial.CheckCondition(&t.Status, redskyv1beta1.TrialReady, corev1.ConditionTrue) &&
+		t.Status.StartTime == nil {
+		return redskyv1beta1.TrialHookPreTrial, true
+	}
+
+	// Post-trial hooks run once the metrics are collected but before the trial is complete
+	if trial.HooksPending(t, redskyv1beta1.TrialHookPostTrial) &&
+		trial.CheckCondition(&t.Status, redskyv1beta1.TrialObserved, corev1.ConditionTrue) {
+		return redskyv1beta1.TrialHookPostTrial, true
+	}
+
+	return "", false
+}
+
+// runHooks creates the next hook job and records the progress of the hooks on the trial
+func (r *HookReconciler) runHooks(ctx context.Context, t *redskyv1beta1.Trial, phase redskyv1beta1.TrialHookPhase, jobList *batchv1.JobList, probeTime *metav1.Time) (*ctrl.Result, error) {
+	p := trial.CheckHooks(t, phase, jobList)
+	conditionType := trial.HookConditionType(phase)
+
+	if p.Next != nil {
+		if err := controllerutil.SetControllerReference(t, p.Next, r.Scheme); err != nil {
+			return &ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, p.Next); controller.IgnoreAlreadyExists(err) != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	switch {
+	case p.Failure != "":
+		trial.ApplyCondition(&t.Status, conditionType, corev1.ConditionFalse, "HookFailed", p.Failure, probeTime)
+		trial.ApplyCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue, "HookFailed", p.Failure, probeTime)
+	case p.Finished():
+		trial.ApplyCondition(&t.Status, conditionType, corev1.ConditionTrue, "", "", probeTime)
+	case trial.CheckCondition(&t.Status, conditionType, corev1.ConditionFalse):
+		// Wait for the hook job to finish
+		return &ctrl.Result{}, nil
+	default:
+		trial.ApplyCondition(&t.Status, conditionType, corev1.ConditionFalse, "", "", probeTime)
+	}
+
+	err := r.Update(ctx, t)
+	return controller.RequeueConflict(err)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// RequeueIntervals are the delays used by the reconcilers when they need to poll for changes that are not watched.
+// Shorter intervals reduce latency at the expense of additional load on the API server.
+type RequeueIntervals struct {
+	// Preemption is the delay between checks for workloads which may preempt a trial
+	Preemption time.Duration
+	// SetupJob is the delay between checks for the completion of a trial setup job
+	SetupJob time.Duration
+	// TrialCreation is the minimum delay between the creation of trials from server suggestions
+	TrialCreation time.Duration
+	// ReadinessCheck is the minimum delay between attempts to evaluate a readiness check
+	ReadinessCheck time.Duration
+	// DependencyCheck is the delay between checks of the experiments a waiting experiment depends on
+	DependencyCheck time.Duration
+	// SinkRetry is the delay before trying to publish a trial again
+	SinkRetry time.Duration
+	// RetentionCheck is the delay between checks of finished experiments against the retention policy
+	RetentionCheck time.Duration
+	// DriftCheck is the delay between comparisons of the cluster and server experiment definitions
+	DriftCheck time.Duration
+}
+
+// DefaultRequeueIntervals are the requeue intervals used when nothing is configured.
+var DefaultRequeueIntervals = RequeueIntervals{
+	Preemption:      15 * time.Second,
+	SetupJob:        1 * time.Second,
+	TrialCreation:   1 * time.Second,
+	ReadinessCheck:  1 * time.Second,
+	DependencyCheck: time.Minute,
+	SinkRetry:       30 * time.Second,
+	RetentionCheck:  time.Hour,
+	DriftCheck:      5 * time.Minute,
+}
+
+// requeueInterval describes a single configurable interval.
+type requeueInterval struct {
+	value    *time.Duration
+	flag     string
+	usage    string
+	min, max time.Duration
+}
+
+// intervals returns the descriptions of the individual intervals.
+func (ri *RequeueIntervals) intervals() []requeueInterval {
+	return []requeueInterval{
+		{&ri.Preemption, "preemption-poll-interval", "The `duration` between checks for workloads which may preempt a trial.", time.Second, 10 * time.Minute},
+		{&ri.SetupJob, "setup-poll-interval", "The `duration` between checks for the completion of a trial setup job.", 100 * time.Millisecond, time.Minute},
+		{&ri.TrialCreation, "trial-creation-interval", "The minimum `duration` between the creation of trials from server suggestions.", time.Second, time.Hour},
+		{&ri.ReadinessCheck, "readiness-check-interval", "The minimum `duration` between attempts to evaluate a readiness check.", time.Second, 10 * time.Minute},
+		{&ri.DependencyCheck, "dependency-check-interval", "The `duration` between checks of the experiments a waiting experiment depends on.", time.Second, time.Hour},
+		{&ri.SinkRetry, "sink-retry-interval", "The `duration` to wait before trying to publish a trial to the trial sink again.", time.Second, time.Hour},
+		{&ri.RetentionCheck, "retention-check-interval", "The `duration` between checks of finished experiments against the retention policy.", time.Minute, 24 * time.Hour},
+		{&ri.DriftCheck, "drift-check-interval", "The `duration` between comparisons of the cluster and server experiment definitions.", time.Minute, 24 * time.Hour},
+	}
+}
+
+// AddFlags registers a flag for each of the intervals, the current values are used as the defaults.
+func (ri *RequeueIntervals) AddFlags(fs *flag.FlagSet) {
+	for _, i := range ri.intervals() {
+		fs.DurationVar(i.value, i.flag, *i.value, i.usage)
+	}
+}
+
+// Validate ensures each of the intervals is within a reasonable range.
+func (ri *RequeueIntervals) Validate() error {
+	for _, i := range ri.intervals() {
+		if *i.value < i.min || *i.value > i.max {
+			return fmt.Errorf("invalid %s %s, must be between %s and %s", i.flag, *i.value, i.min, i.max)
+		}
+	}
+	return nil
+}
+
+// requeueIntervals returns the supplied intervals or the defaults if they are not configured.
+func requeueIntervals(ri *RequeueIntervals) *RequeueIntervals {
+	if ri == nil {
+		return &DefaultRequeueIntervals
+	}
+	return ri
+}
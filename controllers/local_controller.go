@@ -0,0 +1,170 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/controller"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/internal/server"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// LocalReconciler creates trials for experiments using the local optimizer instead of the remote server
+type LocalReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
+}
+
+// +kubebuilder:rbac:groups=redskyops.dev,resources=experiments,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=list;watch;create
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=list
+
+func (r *LocalReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("experiment", req.NamespacedName)
+
+	exp := &redskyv1beta1.Experiment{}
+	if err := r.Get(ctx, req.NamespacedName, exp); err != nil {
+		return ctrl.Result{}, controller.IgnoreNotFound(err)
+	}
+
+	// Only experiments using the local optimizer which are still running are considered
+	if exp.Spec.LocalOptimizer == nil || !exp.DeletionTimestamp.IsZero() || experiment.IsFinished(exp) {
+		return ctrl.Result{}, nil
+	}
+
+	trialList := &redskyv1beta1.TrialList{}
+	if err := listTrials(ctx, r, trialList, exp); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// An invalid schedule (recorded on the experiment status by the experiment controller) only prevents new trials
+	scheduleDelay, err := experiment.ScheduleDelay(exp, time.Now())
+	scheduleOpen := err == nil && scheduleDelay == 0
+
+	var activeTrials int32
+	for i := range trialList.Items {
+		if trial.IsActive(&trialList.Items[i]) {
+			activeTrials++
+		}
+	}
+
+	// Stop once the budget is exhausted
+	index := server.NextLocalSuggestion(exp, trialList)
+	if index >= server.LocalBudget(exp) {
+		if result, err := r.completeExperiment(ctx, log, exp, activeTrials); result != nil {
+			return *result, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if activeTrials < exp.Replicas() && !experiment.SuggestionsPaused(exp) && !experiment.IsWaiting(exp) && scheduleOpen {
+		if result, err := r.nextTrial(ctx, log, exp, trialList, index); result != nil {
+			return *result, err
+		}
+	}
+
+	// Check again when the next scheduled window opens
+	if scheduleDelay > 0 {
+		return ctrl.Result{RequeueAfter: scheduleDelay}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *LocalReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("local").
+		For(&redskyv1beta1.Experiment{}).
+		Watches(&source.Kind{Type: &redskyv1beta1.Trial{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(trialToExperimentRequest)}).
+		Complete(r)
+}
+
+// completeExperiment marks the experiment as complete once the last of the trials have finished
+func (r *LocalReconciler) completeExperiment(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, activeTrials int32) (*ctrl.Result, error) {
+	if activeTrials > 0 {
+		return nil, nil
+	}
+
+	experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentComplete, corev1.ConditionTrue, "BudgetExhausted", "", nil)
+	exp.SetReplicas(0)
+	if err := r.Update(ctx, exp); err != nil {
+		return controller.RequeueConflict(err)
+	}
+
+	log.Info("Local optimizer budget exhausted", "budget", server.LocalBudget(exp))
+	return &ctrl.Result{}, nil
+}
+
+// nextTrial creates a new trial from the local optimizer suggestion with the specified index
+func (r *LocalReconciler) nextTrial(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList, index int) (*ctrl.Result, error) {
+	// Determine the namespace (if any) to use for the trial
+	namespace, err := experiment.NextTrialNamespace(ctx, r, exp, trialList)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if namespace == "" {
+		return nil, nil
+	}
+
+	// Generate a new trial from the template on the experiment and apply the local suggestion
+	// NOTE: The trial TTLs are not defaulted, without a server the trials are the only record of the results
+	t := &redskyv1beta1.Trial{}
+	experiment.PopulateTrialFromTemplate(exp, t)
+	t.Namespace = namespace
+	if err := server.ToClusterLocalTrial(t, exp, index); err != nil {
+		if server.FailExperiment(exp, "InvalidLocalOptimizer", fmt.Errorf("unable to generate suggestion %d: %w", index, err)) {
+			err := r.Update(ctx, exp)
+			return controller.RequeueConflict(err)
+		}
+		return &ctrl.Result{}, err
+	}
+
+	// Record the next index before creating the trial so the suggestions do not rewind when trials are deleted
+	if server.RecordLocalSuggestion(exp, index+1) {
+		if err := r.Update(ctx, exp); err != nil {
+			return controller.RequeueConflict(err)
+		}
+	}
+
+	if err := r.Create(ctx, t); err != nil {
+		return &ctrl.Result{}, controller.IgnoreAlreadyExists(err)
+	}
+
+	if server.IsBaseline(t) {
+		log = log.WithValues("baseline", true)
+	}
+	log.Info("Created new trial", "localSuggestion", index, "assignments", t.Spec.Assignments)
+
+	// Pace the creation of trials, the trial list may not include the new trial yet
+	return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).TrialCreation}, nil
+}
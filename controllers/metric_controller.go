@@ -18,10 +18,12 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"math"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	batchv1 "k8s.io/api/batch/v1"
@@ -34,9 +36,13 @@ import (
 	"github.com/thestormforge/optimize-controller/internal/trial"
 	"github.com/thestormforge/optimize-controller/internal/validation"
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -52,16 +58,27 @@ type MetricReconciler struct {
 // +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
 // +kubebuilder:rbac:groups="",resources=services,verbs=list
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods;nodes,verbs=get;list
 
 func (r *MetricReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
 	now := metav1.Now()
 
 	t := &redskyv1beta1.Trial{}
-	if err := r.Get(ctx, req.NamespacedName, t); err != nil || r.ignoreTrial(t) {
+	if err := r.Get(ctx, req.NamespacedName, t); err != nil {
 		return ctrl.Result{}, controller.IgnoreNotFound(err)
 	}
 
+	if result, err := r.sampleMetrics(ctx, t, &now); result != nil {
+		return *result, err
+	}
+
+	if r.ignoreTrial(t) {
+		return ctrl.Result{}, nil
+	}
+
 	if result, err := r.evaluateMetrics(ctx, t, &now); result != nil {
 		return *result, err
 	}
@@ -113,6 +130,134 @@ func (r *MetricReconciler) ignoreTrial(t *redskyv1beta1.Trial) bool {
 	return true
 }
 
+// sampleMetrics periodically records the resource usage reported by the metrics server while the trial is running.
+func (r *MetricReconciler) sampleMetrics(ctx context.Context, t *redskyv1beta1.Trial, probeTime *metav1.Time) (*ctrl.Result, error) {
+	// Only sample running trials
+	if !t.DeletionTimestamp.IsZero() || t.Status.StartTime == nil || t.Status.CompletionTime != nil ||
+		trial.CheckCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue) {
+		return nil, nil
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	if err := r.Get(ctx, t.ExperimentNamespacedName(), exp); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	var usageMetrics, seriesMetrics []*redskyv1beta1.Metric
+	for i := range exp.Spec.Metrics {
+		switch m := &exp.Spec.Metrics[i]; {
+		case m.Type == redskyv1beta1.MetricMetricsServer:
+			usageMetrics = append(usageMetrics, m.DeepCopy())
+		case m.SampleInterval != nil && m.Type != redskyv1beta1.MetricDerived:
+			seriesMetrics = append(seriesMetrics, m.DeepCopy())
+		}
+	}
+	if len(usageMetrics) == 0 && len(seriesMetrics) == 0 {
+		return nil, nil
+	}
+
+	samples, err := metric.GetSamples(t)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	// Do not sample more frequently then the intervals (updating the trial triggers another reconcile)
+	var sampled bool
+	var next time.Time
+	if len(usageMetrics) > 0 {
+		if due := samples.LastSampleTime.Add(metric.MetricsServerSampleInterval); due.After(probeTime.Time) {
+			next = due
+		} else {
+			if err := r.sampleUsage(ctx, t, usageMetrics, samples); err != nil {
+				return &ctrl.Result{}, err
+			}
+			samples.LastSampleTime = *probeTime
+			next = probeTime.Add(metric.MetricsServerSampleInterval)
+			sampled = true
+		}
+	}
+
+	for _, m := range seriesMetrics {
+		due := samples.NextPointTime(m, *t.Status.StartTime)
+		if !due.After(probeTime.Time) {
+			// Intermediate values are best effort, failures are not retried until the next interval
+			log := r.Log.WithValues("trial", fmt.Sprintf("%s/%s", t.Namespace, t.Name), "metric", m.Name)
+			if value, err := r.samplePoint(ctx, log, t, m, probeTime); err != nil {
+				log.V(1).Info("Failed to sample metric", "error", err.Error())
+			} else {
+				samples.AddPoint(m.Name, *probeTime, value)
+				sampled = true
+
+				// Abort the trial as soon as a sample exceeds the fail fast limit
+				// NOTE: We allow baseline trials to go through no matter what
+				if err := validation.CheckFailFast(m, value); err != nil && !trial.IsBaseline(t, exp) {
+					trial.ApplyCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue, "FailFast", err.Error(), probeTime)
+					break
+				}
+			}
+			due = probeTime.Add(m.SampleInterval.Duration)
+		}
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+
+	if sampled {
+		if err := metric.SetSamples(t, samples); err != nil {
+			return &ctrl.Result{}, err
+		}
+
+		if err := r.Update(ctx, t); err != nil {
+			return controller.RequeueConflict(err)
+		}
+	}
+	return &ctrl.Result{RequeueAfter: next.Sub(probeTime.Time)}, nil
+}
+
+// sampleUsage records the current usage reported by the metrics server.
+func (r *MetricReconciler) sampleUsage(ctx context.Context, t *redskyv1beta1.Trial, metrics []*redskyv1beta1.Metric, samples *metric.Samples) error {
+	for _, m := range metrics {
+		target, err := r.metricsServerTarget(ctx, t, m)
+		if err != nil {
+			// Usage may not be available yet (e.g. new pods), just wait for the next sample
+			r.Log.V(1).Info("Failed to sample the metrics server", "trial", t.Name, "metric", m.Name, "error", err.Error())
+			continue
+		}
+
+		value, err := metric.MetricsServerUsage(m, target)
+		if err != nil {
+			return err
+		}
+		samples.Add(m.Name, value)
+	}
+	return nil
+}
+
+// samplePoint captures an intermediate value of the metric.
+func (r *MetricReconciler) samplePoint(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, probeTime *metav1.Time) (float64, error) {
+	if err := r.applyMetricDefaults(ctx, t, m); err != nil {
+		return 0, err
+	}
+
+	target, err := r.target(ctx, t, m)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.resolveHeaders(ctx, t, m); err != nil {
+		return 0, err
+	}
+	if err := r.resolveTLS(ctx, t, m); err != nil {
+		return 0, err
+	}
+
+	if m.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Timeout.Duration)
+		defer cancel()
+	}
+	return metric.CaptureSample(ctx, log, t, m, target, probeTime.Time)
+}
+
 func (r *MetricReconciler) evaluateMetrics(ctx context.Context, t *redskyv1beta1.Trial, probeTime *metav1.Time) (*ctrl.Result, error) {
 	// TODO This check precludes manual additions of Values
 	if len(t.Spec.Values) > 0 {
@@ -126,10 +271,10 @@ func (r *MetricReconciler) evaluateMetrics(ctx context.Context, t *redskyv1beta1
 	}
 
 	// Evaluate the metrics
-	for _, m := range exp.Spec.Metrics {
+	for i := range exp.Spec.Metrics {
 		t.Spec.Values = append(t.Spec.Values, redskyv1beta1.Value{
-			Name:              m.Name,
-			AttemptsRemaining: 3,
+			Name:              exp.Spec.Metrics[i].Name,
+			AttemptsRemaining: metric.Attempts(&exp.Spec.Metrics[i]),
 		})
 	}
 
@@ -172,6 +317,17 @@ func (r *MetricReconciler) collectMetrics(ctx context.Context, t *redskyv1beta1.
 
 		// Apply defaults to our local copy of the metric definition
 		m := metrics[v.Name]
+
+		// Derived metrics are computed from the other values so they must be collected last
+		if m.Type == redskyv1beta1.MetricDerived && hasPendingValues(t, metrics) {
+			continue
+		}
+
+		// Wait before retrying a failed attempt
+		if d := retryAfter(t, m, v, probeTime); d > 0 {
+			return &ctrl.Result{RequeueAfter: d}, nil
+		}
+
 		if err := r.applyMetricDefaults(ctx, t, m); err != nil {
 			return r.collectionAttempt(ctx, log, t, v, probeTime, err)
 		}
@@ -181,15 +337,27 @@ func (r *MetricReconciler) collectMetrics(ctx context.Context, t *redskyv1beta1.
 		if err != nil {
 			return r.collectionAttempt(ctx, log, t, v, probeTime, err)
 		}
+		if err := r.resolveHeaders(ctx, t, m); err != nil {
+			return r.collectionAttempt(ctx, log, t, v, probeTime, err)
+		}
+		if err := r.resolveTLS(ctx, t, m); err != nil {
+			return r.collectionAttempt(ctx, log, t, v, probeTime, err)
+		}
 
 		// Capture the metric value
-		value, valueError, err := metric.CaptureMetric(ctx, log, t, m, target)
+		captureCtx := ctx
+		if m.Timeout != nil {
+			var cancel context.CancelFunc
+			captureCtx, cancel = context.WithTimeout(ctx, m.Timeout.Duration)
+			defer cancel()
+		}
+		value, valueError, err := metric.CaptureMetric(captureCtx, log, t, m, target)
 		if err != nil {
 			return r.collectionAttempt(ctx, log, t, v, probeTime, err)
 		}
 
-		// Success, record the value
-		v.Value = strconv.FormatFloat(value, 'f', -1, 64)
+		// Success, record the value (values better than the target are not an improvement)
+		v.Value = strconv.FormatFloat(metric.ApplyTarget(m, value), 'f', -1, 64)
 		if !math.IsNaN(valueError) {
 			v.Error = strconv.FormatFloat(valueError, 'f', -1, 64)
 		}
@@ -197,6 +365,16 @@ func (r *MetricReconciler) collectMetrics(ctx context.Context, t *redskyv1beta1.
 		return r.collectionAttempt(ctx, log, t, v, probeTime, nil)
 	}
 
+	// Allow the values to be adjusted before they are checked and reported
+	if err := metric.InvokeWebhook(ctx, exp, t); err != nil {
+		if exp.Spec.ValueWebhook.FailurePolicy != redskyv1beta1.WebhookFailurePolicyIgnore {
+			trial.ApplyCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue, "WebhookFailed", err.Error(), probeTime)
+			err := r.Update(ctx, t)
+			return controller.RequeueConflict(err)
+		}
+		log.Info("Ignoring value webhook failure", "error", err.Error())
+	}
+
 	// Wait until all metrics have been collected to fail the trial for an out of bounds metric
 	// NOTE: We allow baseline trials to go through no matter what
 	if !trial.IsBaseline(t, exp) {
@@ -219,8 +397,8 @@ func (r *MetricReconciler) collectMetrics(ctx context.Context, t *redskyv1beta1.
 // collectionAttempt updates the status of the trial based on the outcome of an attempt to collect metric values.
 func (r *MetricReconciler) collectionAttempt(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial, v *redskyv1beta1.Value, probeTime *metav1.Time, err error) (*ctrl.Result, error) {
 	// Do not count retries against the remaining attempts
-	if merr, ok := err.(*metric.CaptureError); ok && merr.RetryAfter > 0 {
-		return &ctrl.Result{RequeueAfter: merr.RetryAfter}, nil
+	if d := metric.RetryAfter(err); d > 0 {
+		return &ctrl.Result{RequeueAfter: d}, nil
 	}
 
 	// Update the number of remaining attempts
@@ -246,9 +424,54 @@ func (r *MetricReconciler) collectionAttempt(ctx context.Context, log logr.Logge
 	return controller.RequeueConflict(r.Update(ctx, t))
 }
 
+// hasPendingValues checks to see if any of the non-derived metric values have not been collected yet.
+func hasPendingValues(t *redskyv1beta1.Trial, metrics map[string]*redskyv1beta1.Metric) bool {
+	for _, v := range t.Spec.Values {
+		if m := metrics[v.Name]; v.AttemptsRemaining > 0 && m != nil && m.Type != redskyv1beta1.MetricDerived {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter returns the amount of time remaining before the next attempt to collect a metric value.
+func retryAfter(t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, v *redskyv1beta1.Value, probeTime *metav1.Time) time.Duration {
+	delay := metric.RetryDelay(m, metric.Attempts(m)-v.AttemptsRemaining)
+	if delay <= 0 || probeTime == nil {
+		return 0
+	}
+
+	// The observed condition is updated on every collection attempt
+	for _, c := range t.Status.Conditions {
+		if c.Type != redskyv1beta1.TrialObserved {
+			continue
+		}
+
+		lastAttempt := c.LastProbeTime.Time
+		if c.LastTransitionTime.After(lastAttempt) {
+			lastAttempt = c.LastTransitionTime.Time
+		}
+		return lastAttempt.Add(delay).Sub(probeTime.Time)
+	}
+
+	return 0
+}
+
 // target looks up the Kubernetes object (if any) associated with a metric.
 func (r *MetricReconciler) target(ctx context.Context, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric) (runtime.Object, error) {
-	if m.Type != redskyv1beta1.MetricKubernetes && m.Type != "" {
+	switch m.Type {
+	case redskyv1beta1.MetricKubernetes, "":
+	case redskyv1beta1.MetricDatadog, redskyv1beta1.MetricInfluxDB, redskyv1beta1.MetricElasticsearch:
+		// Datadog, InfluxDB and Elasticsearch metrics may reference a secret containing the credentials
+		if m.Target == nil {
+			return nil, nil
+		}
+	case redskyv1beta1.MetricPrometheus, redskyv1beta1.MetricJSONPath:
+		// Prometheus and JSON path metrics may reference a secret containing the credentials (other targets are legacy selectors)
+		if m.Target == nil || m.Target.Kind != "Secret" {
+			return nil, nil
+		}
+	default:
 		return nil, nil
 	}
 
@@ -288,6 +511,152 @@ func (r *MetricReconciler) target(ctx context.Context, t *redskyv1beta1.Trial, m
 	return target, nil
 }
 
+// metricsServerTarget looks up the pod or node metrics for a metric using the metrics server. If the metric
+// does not specify a target, all of the pods in the trial namespace are used.
+func (r *MetricReconciler) metricsServerTarget(ctx context.Context, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric) (runtime.Object, error) {
+	kind, namespace, name, sel := "PodMetrics", t.Namespace, "", &meta.Selector{Selector: labels.Everything()}
+	if m.Target != nil {
+		switch m.Target.Kind {
+		case "Node", "NodeList", "NodeMetrics", "NodeMetricsList":
+			kind, namespace = "NodeMetrics", ""
+		default:
+			if m.Target.Namespace != "" {
+				namespace = m.Target.Namespace
+			}
+		}
+
+		name = m.Target.Name
+		if m.Target.LabelSelector != nil {
+			var err error
+			if sel, err = meta.MatchingSelector(m.Target.LabelSelector); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	gv := schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}
+
+	if name != "" {
+		target := &unstructured.Unstructured{}
+		target.SetGroupVersionKind(gv.WithKind(kind))
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, target); err != nil {
+			return nil, err
+		}
+		return target, nil
+	}
+
+	target := &unstructured.UnstructuredList{}
+	target.SetGroupVersionKind(gv.WithKind(kind + "List"))
+	if err := r.List(ctx, target, client.InNamespace(namespace), sel); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// resolveHeaders replaces the secret key references on the metric HTTP headers with their values.
+func (r *MetricReconciler) resolveHeaders(ctx context.Context, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric) error {
+	if m.HTTP == nil {
+		return nil
+	}
+
+	for i := range m.HTTP.Headers {
+		h := &m.HTTP.Headers[i]
+		if h.SecretKeyRef == nil {
+			continue
+		}
+
+		value, err := r.secretKeyValue(ctx, t.Namespace, h.SecretKeyRef)
+		if err != nil {
+			return err
+		}
+
+		h.Value, h.SecretKeyRef = value, nil
+	}
+
+	return nil
+}
+
+// resolveTLS replaces the key references on the metric TLS configuration with their values.
+func (r *MetricReconciler) resolveTLS(ctx context.Context, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric) error {
+	if m.TLS == nil {
+		return nil
+	}
+
+	for _, d := range []*redskyv1beta1.MetricTLSData{m.TLS.CA, m.TLS.Cert, m.TLS.Key} {
+		if d == nil {
+			continue
+		}
+
+		var err error
+		switch {
+		case d.SecretKeyRef != nil:
+			d.Value, err = r.secretKeyValue(ctx, t.Namespace, d.SecretKeyRef)
+		case d.ConfigMapKeyRef != nil:
+			d.Value, err = r.configMapKeyValue(ctx, t.Namespace, d.ConfigMapKeyRef)
+		}
+		if err != nil {
+			return err
+		}
+
+		d.SecretKeyRef, d.ConfigMapKeyRef = nil, nil
+	}
+
+	return nil
+}
+
+// configMapKeyValue returns the value of a config map key, optional keys which cannot be found are empty.
+func (r *MetricReconciler) configMapKeyValue(ctx context.Context, namespace string, sel *corev1.ConfigMapKeySelector) (string, error) {
+	optional := sel.Optional != nil && *sel.Optional
+
+	// Use an unstructured config map so the lookup does not go through the cache
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: sel.Name}, cm); err != nil {
+		if optional && apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	value, ok, err := unstructured.NestedString(cm.Object, "data", sel.Key)
+	if err != nil {
+		return "", err
+	} else if !ok && !optional {
+		return "", fmt.Errorf("config map %q does not contain the key %q", sel.Name, sel.Key)
+	}
+
+	return value, nil
+}
+
+// secretKeyValue returns the decoded value of a secret key, optional keys which cannot be found are empty.
+func (r *MetricReconciler) secretKeyValue(ctx context.Context, namespace string, sel *corev1.SecretKeySelector) (string, error) {
+	optional := sel.Optional != nil && *sel.Optional
+
+	// Use an unstructured secret so the lookup does not go through the cache
+	secret := &unstructured.Unstructured{}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: sel.Name}, secret); err != nil {
+		if optional && apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	encoded, ok, err := unstructured.NestedString(secret.Object, "data", sel.Key)
+	if err != nil {
+		return "", err
+	} else if !ok && !optional {
+		return "", fmt.Errorf("secret %q does not contain the key %q", sel.Name, sel.Key)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
 // applyMetricDefaults fills in default values for the supplied metric.
 func (r *MetricReconciler) applyMetricDefaults(ctx context.Context, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric) error {
 	// Give Prometheus metrics a default URL
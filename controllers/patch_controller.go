@@ -129,12 +129,19 @@ func (r *PatchReconciler) evaluatePatchOperations(ctx context.Context, t *redsky
 		return &ctrl.Result{}, err
 	}
 
+	// Suggestions should already satisfy the constraints, but manually created trials may not
+	if err := validation.CheckConstraints(t, exp); err != nil {
+		trial.ApplyCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue, "ConstraintViolated", err.Error(), probeTime)
+		err := r.Update(ctx, t)
+		return controller.RequeueConflict(err)
+	}
+
 	// Readiness checks from patches should always be applied first
 	readinessChecks := t.Status.ReadinessChecks
 	t.Status.ReadinessChecks = nil
 
 	// Evaluate the patches
-	te := template.New()
+	te := template.New().WithParameters(exp.Spec.Parameters).WithDerived(exp.Spec.Derived)
 	for i := range exp.Spec.Patches {
 		p := &exp.Spec.Patches[i]
 
@@ -151,6 +158,13 @@ func (r *PatchReconciler) evaluatePatchOperations(ctx context.Context, t *redsky
 			t.Status.PatchOperations = append(t.Status.PatchOperations, *po)
 		}
 
+		// Patches which are applied live do not roll out, there is nothing to wait for
+		if live, err := patch.IsLivePatch(te, exp.Spec.Parameters, p); err != nil {
+			return &ctrl.Result{}, err
+		} else if live {
+			continue
+		}
+
 		// Add a readiness check if necessary
 		if rc, err := r.createReadinessCheck(t, ref, p.ReadinessGates); err != nil {
 			return &ctrl.Result{}, err
@@ -46,6 +46,9 @@ type ReadyReconciler struct {
 	// requires list/watch. If we ever get a way to disable the cache or the cache becomes smart enough to handle
 	// permission errors without hanging we can go back to using standard reader.
 	apiReader client.Reader
+
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
 }
 
 // +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=get;list;watch;update
@@ -131,6 +134,7 @@ func (r *ReadyReconciler) evaluateReadinessChecks(ctx context.Context, t *redsky
 			InitialDelaySeconds: c.InitialDelaySeconds,
 			PeriodSeconds:       c.PeriodSeconds,
 			AttemptsRemaining:   c.FailureThreshold,
+			Probe:               c.Probe,
 		}
 
 		// Adjust for defaults/minimums
@@ -162,7 +166,7 @@ func (r *ReadyReconciler) checkReadiness(ctx context.Context, t *redskyv1beta1.T
 	}
 
 	// Create a new "checker" to maintain state while looping over the readiness checks
-	checker := newReadinessChecker(r.Client, t)
+	checker := newReadinessChecker(r.Client, t, requeueIntervals(r.Intervals).ReadinessCheck)
 	for i := range t.Status.ReadinessChecks {
 		c := &t.Status.ReadinessChecks[i]
 		if checker.skipCheck(c, probeTime) {
@@ -262,10 +266,12 @@ type readinessChecker struct {
 	requeue bool
 	// after is the delay after which all of the readiness checks can be evaluated
 	after time.Duration
+	// minPeriod is the minimum delay between attempts to evaluate a readiness check
+	minPeriod time.Duration
 }
 
 // newReadinessChecker returns a new checker for the supplied trial
-func newReadinessChecker(reader client.Reader, t *redskyv1beta1.Trial) *readinessChecker {
+func newReadinessChecker(reader client.Reader, t *redskyv1beta1.Trial, minPeriod time.Duration) *readinessChecker {
 	checker := ready.ReadinessChecker{Reader: reader}
 	epoch := t.GetCreationTimestamp()
 	for i := range t.Status.Conditions {
@@ -273,7 +279,7 @@ func newReadinessChecker(reader client.Reader, t *redskyv1beta1.Trial) *readines
 			epoch = t.Status.Conditions[i].LastTransitionTime
 		}
 	}
-	return &readinessChecker{checker: checker, epoch: epoch, ready: true, requeue: true}
+	return &readinessChecker{checker: checker, epoch: epoch, ready: true, requeue: true, minPeriod: minPeriod}
 }
 
 // skipCheck determines if a check should be evaluated, recording the results internally
@@ -291,7 +297,7 @@ func (rc *readinessChecker) skipCheck(c *redskyv1beta1.ReadinessCheck, now *meta
 		d := next.Time.Sub(now.Time)
 
 		// Avoid excessive sleeps so we can still detect failures
-		if p := time.Duration(c.PeriodSeconds) * time.Second; d > p {
+		if p := rc.period(c); d > p {
 			d = p
 		}
 
@@ -318,15 +324,18 @@ func (rc *readinessChecker) check(ctx context.Context, c *redskyv1beta1.Readines
 	var err error
 	for i := range ul.Items {
 		msg, ok, err = rc.checker.CheckConditions(ctx, &ul.Items[i], c.ConditionTypes)
+		if ok && err == nil {
+			msg, ok, err = rc.checker.CheckProbe(ctx, &ul.Items[i], c.Probe)
+		}
 		if !ok || err != nil {
 			break
 		}
 	}
 
 	// If a check is missing it's kind, just mark it as completed (e.g. if this
-	// is just a "sleep" based on the initial delay)
+	// is just a "sleep" based on the initial delay) unless it has a probe to run
 	if c.TargetRef.Kind == "" {
-		ok = true
+		msg, ok, err = rc.checker.CheckProbe(ctx, nil, c.Probe)
 	}
 
 	// Check is done, it is either ok or had a hard failure
@@ -337,7 +346,7 @@ func (rc *readinessChecker) check(ctx context.Context, c *redskyv1beta1.Readines
 	}
 
 	// If there are no items to check, try to provide a useful message
-	if len(ul.Items) == 0 {
+	if len(ul.Items) == 0 && c.TargetRef.Kind != "" {
 		var missingTargetMsg strings.Builder
 		missingTargetMsg.WriteString("No matching resources found")
 		missingTargetMsg.WriteString("; apiVersion=")
@@ -369,12 +378,16 @@ func (rc *readinessChecker) check(ctx context.Context, c *redskyv1beta1.Readines
 // nextCheckTime returns the approximate time that an attempt should be made to evaluate a check
 func (rc *readinessChecker) nextCheckTime(c *redskyv1beta1.ReadinessCheck) *metav1.Time {
 	if c.LastCheckTime != nil {
-		periodSeconds := c.PeriodSeconds
-		if periodSeconds < 1 {
-			periodSeconds = 1
-		}
-		return &metav1.Time{Time: c.LastCheckTime.Add(time.Duration(periodSeconds) * time.Second)}
+		return &metav1.Time{Time: c.LastCheckTime.Add(rc.period(c))}
 	}
 
 	return &metav1.Time{Time: rc.epoch.Add(time.Duration(c.InitialDelaySeconds) * time.Second)}
 }
+
+// period returns the delay between attempts to evaluate a check, never less then the configured minimum
+func (rc *readinessChecker) period(c *redskyv1beta1.ReadinessCheck) time.Duration {
+	if p := time.Duration(c.PeriodSeconds) * time.Second; p > rc.minPeriod {
+		return p
+	}
+	return rc.minPeriod
+}
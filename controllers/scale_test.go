@@ -0,0 +1,206 @@
+// +build scale
+
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// The scale tests drive a large number of fake experiments and trials through the reconcilers using a
+// local control plane (see `make scale-test`). Trials are never actually run, the goal is to measure the
+// cost of reconciling the experiment and trial objects themselves.
+
+var (
+	scaleTestConfig *rest.Config
+	scaleTestScheme = runtime.NewScheme()
+)
+
+func TestMain(m *testing.M) {
+	_ = clientgoscheme.AddToScheme(scaleTestScheme)
+	_ = redskyv1beta1.AddToScheme(scaleTestScheme)
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+	}
+
+	var err error
+	scaleTestConfig, err = testEnv.Start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to start test environment: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	_ = testEnv.Stop()
+	os.Exit(code)
+}
+
+func BenchmarkExperimentReconciler(b *testing.B) {
+	for _, c := range []struct {
+		experiments int
+		trials      int
+	}{
+		{experiments: 1, trials: 100},
+		{experiments: 1, trials: 500},
+		{experiments: 10, trials: 100},
+		{experiments: 100, trials: 10},
+	} {
+		b.Run(fmt.Sprintf("experiments=%d,trials=%d", c.experiments, c.trials), func(b *testing.B) {
+			ns := scaleTestNamespace(b)
+			mgr, stop := scaleTestManager(b, ns)
+			defer close(stop)
+
+			// Only the cache is started, the reconciler is invoked directly (i.e. not by the controller)
+			if err := indexTrialsByExperiment(mgr); err != nil {
+				b.Fatal(err)
+			}
+			r := &ExperimentReconciler{
+				Client: mgr.GetClient(),
+				Log:    ctrl.Log.WithName("scale-test"),
+			}
+
+			reqs := scaleTestCreate(b, ns, c.experiments, c.trials)
+			go func() { _ = mgr.Start(stop) }()
+			mgr.GetCache().WaitForCacheSync(stop)
+
+			// The first round of reconciles will perform the actual status updates
+			for _, req := range reqs {
+				if _, err := r.Reconcile(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				for _, req := range reqs {
+					if _, err := r.Reconcile(req); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.N*len(reqs))/time.Since(start).Seconds(), "reconciles/s")
+		})
+	}
+}
+
+// scaleTestNamespace creates a new namespace to isolate a single benchmark.
+func scaleTestNamespace(b *testing.B) string {
+	c, err := client.New(scaleTestConfig, client.Options{Scheme: scaleTestScheme})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	n := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "scale-test-"}}
+	if err := c.Create(context.TODO(), n); err != nil {
+		b.Fatal(err)
+	}
+	return n.Name
+}
+
+// scaleTestManager creates a new manager whose cache is restricted to a single namespace.
+func scaleTestManager(b *testing.B, namespace string) (ctrl.Manager, chan struct{}) {
+	mgr, err := ctrl.NewManager(scaleTestConfig, ctrl.Options{
+		Scheme:             scaleTestScheme,
+		Namespace:          namespace,
+		MetricsBindAddress: "0",
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return mgr, make(chan struct{})
+}
+
+// scaleTestCreate creates the requested number of experiments and trials, returning the reconcile requests
+// for the experiments.
+func scaleTestCreate(b *testing.B, namespace string, experiments, trials int) []ctrl.Request {
+	ctx := context.TODO()
+	c, err := client.New(scaleTestConfig, client.Options{Scheme: scaleTestScheme})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	reqs := make([]ctrl.Request, 0, experiments)
+	for i := 0; i < experiments; i++ {
+		exp := &redskyv1beta1.Experiment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("experiment-%03d", i),
+				Namespace: namespace,
+			},
+			Spec: redskyv1beta1.ExperimentSpec{
+				Parameters: []redskyv1beta1.Parameter{{Name: "x", Min: 0, Max: 100}},
+				Metrics:    []redskyv1beta1.Metric{{Name: "y", Query: "{{ duration .StartTime .CompletionTime }}"}},
+			},
+		}
+		if err := c.Create(ctx, exp); err != nil {
+			b.Fatal(err)
+		}
+		reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: exp.Name}})
+
+		for j := 0; j < trials; j++ {
+			t := &redskyv1beta1.Trial{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%03d", exp.Name, j),
+					Namespace: namespace,
+					Labels:    map[string]string{redskyv1beta1.LabelExperiment: exp.Name},
+				},
+				Spec: redskyv1beta1.TrialSpec{
+					Assignments: []redskyv1beta1.Assignment{{Name: "x", Value: intstr.FromInt(j % 100)}},
+				},
+			}
+
+			// Most of the trials are finished, the remainder are still active
+			if j < trials-int(exp.Replicas()) {
+				now := metav1.Now()
+				t.Spec.Values = []redskyv1beta1.Value{{Name: "y", Value: fmt.Sprintf("%d", j)}}
+				t.Status.Conditions = []redskyv1beta1.TrialCondition{{
+					Type:               redskyv1beta1.TrialComplete,
+					Status:             corev1.ConditionTrue,
+					LastProbeTime:      now,
+					LastTransitionTime: now,
+				}}
+			}
+
+			if err := c.Create(ctx, t); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	return reqs
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -37,9 +38,10 @@ import (
 	"github.com/thestormforge/optimize-go/pkg/config"
 	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -51,18 +53,18 @@ var (
 )
 
 // trialCreationRateLimit returns the configured rate for allowing trial creations, the
-// default (and minimum allowed) rate is 1 trial per second.
-func trialCreationRateLimit(log logr.Logger) rate.Limit {
-	// NOTE: If we are changing this to a lot of different values, it should be moved to the configuration
+// minimum allowed interval is 1 second.
+func trialCreationRateLimit(log logr.Logger, interval time.Duration) rate.Limit {
+	// NOTE: The environment variable predates the controller flags, it still takes precedence when set
 	trialCreationInterval, ok := os.LookupEnv("REDSKY_TRIAL_CREATION_INTERVAL")
 	if !ok {
-		return rate.Every(time.Second)
+		return rate.Every(interval)
 	}
 
 	d, err := time.ParseDuration(trialCreationInterval)
 	if err != nil || d < time.Second {
 		log.Info("Ignoring invalid custom trial creation interval", "trialCreationInterval", trialCreationInterval)
-		return rate.Every(time.Second)
+		return rate.Every(interval)
 	}
 
 	log.Info("Using custom trial creation interval", "trialCreationInterval", trialCreationInterval)
@@ -75,13 +77,23 @@ type ServerReconciler struct {
 	Log            logr.Logger
 	Scheme         *runtime.Scheme
 	ExperimentsAPI experimentsv1alpha1.API
+	Recorder       record.EventRecorder
+
+	// FinalizerTimeout is the amount of time a deleted object waits for the server before the finalizer is removed
+	// anyway, zero waits indefinitely
+	FinalizerTimeout time.Duration
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
 
 	trialCreation *rate.Limiter
+	// driftChecks records the time of the last drift check for each experiment
+	driftChecks sync.Map
 }
 
 // +kubebuilder:rbac:groups=redskyops.dev,resources=experiments,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=list;watch;create;update
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=list
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *ServerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -103,13 +115,32 @@ func (r *ServerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return *result, err
 	}
 
+	// Periodically make sure the server still has the same definition as the cluster
+	if result, err := r.checkDrift(ctx, log, exp); result != nil {
+		return *result, err
+	}
+
 	// Get the current list of trials
 	// NOTE: No need to use limits, the cache will just return the full list anyway
 	trialList := &redskyv1beta1.TrialList{}
-	if err := r.listTrials(ctx, trialList, exp.TrialSelector()); err != nil {
+	if err := listTrials(ctx, r, trialList, exp); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// Stop waiting on the server if it has been blocking deletion for too long
+	if result, err := r.expireFinalizers(ctx, log, exp, trialList); result != nil {
+		return *result, err
+	}
+
+	// Determine how long until new trials can be started, an invalid schedule (recorded on the experiment status by
+	// the experiment controller) only prevents new trials from being created
+	scheduleDelay, err := experiment.ScheduleDelay(exp, time.Now())
+	scheduleOpen := err == nil && scheduleDelay == 0
+
+	// A drifted definition only holds up the experiment while it is live, a stale condition must not block reporting
+	// the remaining trials once the experiment is being deleted or has finished
+	drifted := r.checksDrift(exp) && experiment.IsDrifted(exp)
+
 	// Look for active, finished or abandoned trials
 	var activeTrials int32
 	var trialHasFinalizer bool
@@ -125,12 +156,24 @@ func (r *ServerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		// Trials that have the server finalizer may need to be reported
 		if meta.HasFinalizer(t, server.Finalizer) {
 			// TODO Combine report and abandon into one function
-			if trial.IsFinished(t) {
-				if result, err := r.reportTrial(ctx, tlog, t); result != nil {
+			if server.IsHeldRepetition(t, trialList) {
+				trialHasFinalizer = true
+			} else if trial.IsFinished(t) && !server.RepetitionsFinished(exp, t, trialList) {
+				if scheduleOpen {
+					if result, err := r.repeatTrial(ctx, tlog, exp, t, trialList); result != nil {
+						return *result, err
+					}
+				}
+				trialHasFinalizer = true
+			} else if trial.IsFinished(t) && drifted {
+				// Hold the report until the definitions match again
+				trialHasFinalizer = true
+			} else if trial.IsFinished(t) {
+				if result, err := r.reportTrial(ctx, tlog, exp, t, trialList); result != nil {
 					return *result, err
 				}
 			} else if trial.IsAbandoned(t) {
-				if result, err := r.abandonTrial(ctx, tlog, t); result != nil {
+				if result, err := r.abandonTrial(ctx, tlog, exp, t); result != nil {
 					return *result, err
 				}
 			} else {
@@ -139,8 +182,9 @@ func (r *ServerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 	}
 
-	// Create a new trial if necessary
-	if exp.GetAnnotations()[redskyv1beta1.AnnotationNextTrialURL] != "" && activeTrials < exp.Replicas() {
+	// Create a new trial if necessary (finished trials are still reported while suggestions are paused)
+	// NOTE: No other suggestions are accepted until the baseline trial has been reported
+	if exp.GetAnnotations()[redskyv1beta1.AnnotationNextTrialURL] != "" && activeTrials < exp.Replicas() && !experiment.SuggestionsPaused(exp) && !experiment.IsWaiting(exp) && !drifted && scheduleOpen && !server.BaselinePending(trialList) {
 		if result, err := r.nextTrial(ctx, log, exp, trialList); result != nil {
 			return *result, err
 		}
@@ -153,6 +197,16 @@ func (r *ServerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 	}
 
+	// Check again when the next scheduled window opens
+	if scheduleDelay > 0 && exp.DeletionTimestamp.IsZero() {
+		return ctrl.Result{RequeueAfter: scheduleDelay}, nil
+	}
+
+	// Check again for drift while the experiment is linked to the server
+	if r.checksDrift(exp) {
+		return ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).DriftCheck}, nil
+	}
+
 	// Nothing to do
 	return ctrl.Result{}, nil
 }
@@ -185,7 +239,7 @@ func (r *ServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			}
 		}
 
-		rt, err := cfg.Authorize(ctx, version.UserAgent("optimize-controller", comment, nil))
+		rt, err := cfg.Authorize(ctx, server.IdempotencyTransport(version.UserAgent("optimize-controller", comment, nil)))
 		if err != nil {
 			return err
 		}
@@ -206,10 +260,7 @@ func (r *ServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	// Enforce trial creation rate limit (no burst! that is the whole point)
-	r.trialCreation = rate.NewLimiter(trialCreationRateLimit(r.Log), 1)
-
-	// To search for namespaces by name, we need to index them
-	_ = mgr.GetCache().IndexField(&corev1.Namespace{}, "metadata.name", func(obj runtime.Object) []string { return []string{obj.(*corev1.Namespace).Name} })
+	r.trialCreation = rate.NewLimiter(trialCreationRateLimit(r.Log, requeueIntervals(r.Intervals).TrialCreation), 1)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("server").
@@ -226,15 +277,6 @@ func (*createFilter) Delete(event.DeleteEvent) bool   { return true }
 func (*createFilter) Update(event.UpdateEvent) bool   { return true }
 func (*createFilter) Generic(event.GenericEvent) bool { return true }
 
-// listTrials retrieves the list of trial objects matching the specified selector
-func (r *ServerReconciler) listTrials(ctx context.Context, trialList *redskyv1beta1.TrialList, selector *metav1.LabelSelector) error {
-	s, err := metav1.LabelSelectorAsSelector(selector)
-	if err != nil {
-		return err
-	}
-	return r.List(ctx, trialList, client.MatchingLabelsSelector{Selector: s})
-}
-
 // createExperiment will create a new experiment on the server using the cluster state; any default values from the
 // server will be copied back into cluster along with the URLs needed for future interactions with server.
 func (r *ServerReconciler) createExperiment(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment) (*ctrl.Result, error) {
@@ -262,6 +304,7 @@ func (r *ServerReconciler) createExperiment(ctx context.Context, log logr.Logger
 	// TODO This should check for an existing URL annotation before using the name (needs a new version of optimize-go)
 	ee, err := r.ExperimentsAPI.CreateExperiment(ctx, n, *e)
 	if err != nil {
+		server.SyncFailed(exp, "ServerCreateFailed", err)
 		if server.FailExperiment(exp, "ServerCreateFailed", err) {
 			err := r.Update(ctx, exp)
 			return controller.RequeueConflict(err)
@@ -274,15 +317,20 @@ func (r *ServerReconciler) createExperiment(ctx context.Context, log logr.Logger
 		return &ctrl.Result{}, err
 	}
 
-	// Best effort to send a baseline suggestion along with the experiment creation
+	// Send a baseline suggestion along with the experiment creation, it will be the first trial suggested by the server
 	if b != nil {
 		if _, err := r.ExperimentsAPI.CreateTrial(ctx, ee.TrialsURL, *b); err != nil {
+			// Retry if the server could not be reached, otherwise the experiment runs without a baseline
+			if _, ok := err.(*experimentsv1alpha1.Error); !ok {
+				return r.syncFailed(ctx, exp, "ServerBaselineFailed", err)
+			}
 			log.Error(err, "Failed to suggest experiment baseline")
 		}
 	}
 
 	// Apply the server response to the cluster state
 	server.ToCluster(exp, &ee)
+	server.SyncSucceeded(exp)
 
 	// Update the experiment
 	if err = r.Update(ctx, exp); err != nil {
@@ -325,73 +373,136 @@ func (r *ServerReconciler) unlinkExperiment(ctx context.Context, log logr.Logger
 // nextTrial will try to obtain a suggestion from the server and create the corresponding cluster state in the form of
 // a trial; if the cluster can not accommodate additional trials at the time of invocation, not action will be taken
 func (r *ServerReconciler) nextTrial(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
-	// Enforce a rate limit on trial creation
-	res := r.trialCreation.Reserve()
-	if !res.OK() {
-		// This should never happen, if it does, just drop the reconciliation
-		log.Info("Trial creation reservation failed", "limit", r.trialCreation.Limit(), "burst", r.trialCreation.Burst())
-		return nil, nil
-	}
-	if d := res.Delay(); d > 0 {
-		res.Cancel()
-		return &ctrl.Result{RequeueAfter: d}, nil
-	}
-
-	// Determine the namespace (if any) to use for the trial
-	namespace, err := experiment.NextTrialNamespace(ctx, r, exp, trialList)
+	// Determine the namespaces (if any) to use for the trials, when multiple trials can run concurrently a suggestion
+	// is requested for every available namespace instead of waiting for the next reconciliation
+	namespaces, err := experiment.NextTrialNamespaces(ctx, r, exp, trialList, exp.Replicas())
 	if err != nil {
 		return &ctrl.Result{}, err
 	}
-	if namespace == "" {
+	if len(namespaces) == 0 {
 		return nil, nil
 	}
 
-	// Obtain a suggestion from the server
-	suggestion, err := r.ExperimentsAPI.NextTrial(ctx, exp.GetAnnotations()[redskyv1beta1.AnnotationNextTrialURL])
-	if err != nil {
-		if server.StopExperiment(exp, err) {
-			err := r.Update(ctx, exp)
+	// NOTE: The Experiments API does not support fetching multiple suggestions in a single request, instead each
+	// suggestion is requested separately and subject to the trial creation rate limit
+	var result *ctrl.Result
+	created := 0
+	for _, namespace := range namespaces {
+		// Enforce a rate limit on trial creation, any remaining namespaces are filled on a later reconciliation
+		res := r.trialCreation.Reserve()
+		if !res.OK() {
+			// This should never happen, if it does, just stop creating trials
+			log.Info("Trial creation reservation failed", "limit", r.trialCreation.Limit(), "burst", r.trialCreation.Burst())
+			break
+		}
+		if d := res.Delay(); d > 0 {
+			res.Cancel()
+			result = &ctrl.Result{RequeueAfter: d}
+			break
+		}
+
+		// Obtain a suggestion from the server
+		suggestion, err := r.ExperimentsAPI.NextTrial(ctx, exp.GetAnnotations()[redskyv1beta1.AnnotationNextTrialURL])
+		if err != nil {
+			if server.StopExperiment(exp, err) {
+				err := r.Update(ctx, exp)
+				return controller.RequeueConflict(err)
+			}
+			if result, err := controller.RequeueIfUnavailable(err); err == nil {
+				return result, nil
+			}
+			return r.syncFailed(ctx, exp, "ServerNextTrialFailed", err)
+		}
+
+		// Generate a new trial from the template on the experiment and apply the server response
+		t := &redskyv1beta1.Trial{}
+		experiment.PopulateTrialFromTemplate(exp, t)
+		t.Namespace = namespace
+		server.ToClusterTrial(t, &suggestion, exp)
+
+		// Since the trial originated from the server, we can delete it out of the cluster (require both TTLs to be unset)
+		if t.Spec.TTLSecondsAfterFinished == nil && t.Spec.TTLSecondsAfterFailure == nil {
+			t.Spec.TTLSecondsAfterFinished = &defaultServerTrialTTLSecondsAfterFinished
+			t.Spec.TTLSecondsAfterFailure = &defaultServerTrialTTLSecondsAfterFailure
+		}
+
+		// Create the trial
+		if err := r.Create(ctx, t); err != nil {
+			// If creation fails, abandon the suggestion (ignoring those errors)
+			if url := t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL]; url != "" {
+				_ = r.ExperimentsAPI.AbandonRunningTrial(ctx, url)
+			}
+			return &ctrl.Result{}, err
+		}
+
+		tlog := log
+		if server.IsBaseline(t) {
+			tlog = tlog.WithValues("baseline", true)
+		}
+		tlog.Info("Created new trial", "reportTrialURL", t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL], "assignments", t.Spec.Assignments)
+		created++
+
+		// No other suggestions are accepted until the baseline trial has been reported
+		if server.IsBaseline(t) {
+			break
+		}
+	}
+
+	// Nothing was requested from the server, wait for the rate limit
+	if created == 0 {
+		return result, nil
+	}
+
+	// Record that we have successfully communicated with the server
+	if server.SyncSucceeded(exp) {
+		if err := r.Update(ctx, exp); err != nil {
 			return controller.RequeueConflict(err)
 		}
-		return controller.RequeueIfUnavailable(err)
 	}
 
-	// Generate a new trial from the template on the experiment and apply the server response
-	t := &redskyv1beta1.Trial{}
-	experiment.PopulateTrialFromTemplate(exp, t)
-	t.Namespace = namespace
-	server.ToClusterTrial(t, &suggestion)
+	return result, nil
+}
 
-	// Since the trial originated from the server, we can delete it out of the cluster (require both TTLs to be unset)
-	if t.Spec.TTLSecondsAfterFinished == nil && t.Spec.TTLSecondsAfterFailure == nil {
-		t.Spec.TTLSecondsAfterFinished = &defaultServerTrialTTLSecondsAfterFinished
-		t.Spec.TTLSecondsAfterFailure = &defaultServerTrialTTLSecondsAfterFailure
+// repeatTrial will create the next trial repeating the assignments of a finished in cluster trial
+func (r *ServerReconciler) repeatTrial(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	rt := server.NewRepetition(exp, t, trialList)
+	if rt == nil {
+		return nil, nil
 	}
 
-	// Create the trial
-	if err := r.Create(ctx, t); err != nil {
-		// If creation fails, abandon the suggestion (ignoring those errors)
-		if url := t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL]; url != "" {
-			_ = r.ExperimentsAPI.AbandonRunningTrial(ctx, url)
-		}
+	// Determine the namespace (if any) to use for the repetition
+	namespace, err := experiment.NextTrialNamespace(ctx, r, exp, trialList)
+	if err != nil {
 		return &ctrl.Result{}, err
 	}
+	if namespace == "" {
+		return nil, nil
+	}
+	rt.Namespace = namespace
+
+	if err := r.Create(ctx, rt); err != nil {
+		return &ctrl.Result{}, controller.IgnoreAlreadyExists(err)
+	}
 
-	log.Info("Created new trial", "reportTrialURL", t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL], "assignments", t.Spec.Assignments)
+	log.Info("Created trial repetition", "repetition", rt.Name)
 	return nil, nil
 }
 
 // reportTrial will report the values from a finished in cluster trial back to the server
-func (r *ServerReconciler) reportTrial(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial) (*ctrl.Result, error) {
+func (r *ServerReconciler) reportTrial(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
 	if !meta.RemoveFinalizer(t, server.Finalizer) {
 		return nil, nil
 	}
 
+	// Report the aggregate values of all the repetitions
+	if err := server.AggregateRepetitions(exp, t, trialList); err != nil {
+		return &ctrl.Result{}, err
+	}
+
 	if reportTrialURL := t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL]; reportTrialURL != "" {
-		trialValues := server.FromClusterTrial(t)
-		err := r.ExperimentsAPI.ReportTrial(ctx, reportTrialURL, *trialValues)
+		trialValues, err := server.ReportTrial(ctx, r.ExperimentsAPI, reportTrialURL, t)
 		if controller.IgnoreReportError(err) != nil {
-			return &ctrl.Result{}, err
+			return r.syncFailed(ctx, exp, "ServerReportFailed", err)
 		}
 
 		// Shadow the logger reference with one that will produce more contextual details
@@ -410,12 +521,19 @@ func (r *ServerReconciler) reportTrial(ctx context.Context, log logr.Logger, t *
 		return controller.RequeueConflict(err)
 	}
 
+	// The repetitions are no longer needed once their values are reported with the trial
+	for _, rt := range server.ReleaseRepetitions(t, trialList) {
+		if err := r.Update(ctx, rt); err != nil {
+			return controller.RequeueConflict(err)
+		}
+	}
+
 	log.Info("Reported trial")
 	return nil, nil
 }
 
 // abandonTrial will remove the finalizer and try to notify the server that the trial will not be reported
-func (r *ServerReconciler) abandonTrial(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial) (*ctrl.Result, error) {
+func (r *ServerReconciler) abandonTrial(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial) (*ctrl.Result, error) {
 	if !meta.RemoveFinalizer(t, server.Finalizer) {
 		return nil, nil
 	}
@@ -423,7 +541,7 @@ func (r *ServerReconciler) abandonTrial(ctx context.Context, log logr.Logger, t
 	if reportTrialURL := t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL]; reportTrialURL != "" {
 		err := r.ExperimentsAPI.AbandonRunningTrial(ctx, reportTrialURL)
 		if controller.IgnoreNotFound(err) != nil {
-			return &ctrl.Result{}, err
+			return r.syncFailed(ctx, exp, "ServerAbandonFailed", err)
 		}
 
 		// Shadow the logger reference with one that will produce more contextual details
@@ -438,3 +556,85 @@ func (r *ServerReconciler) abandonTrial(ctx context.Context, log logr.Logger, t
 	log.Info("Abandoned trial")
 	return nil, nil
 }
+
+// expireFinalizers removes the server finalizer from deleted objects that have been waiting on the server for longer
+// than the configured timeout; the server will not receive any further updates for those objects
+func (r *ServerReconciler) expireFinalizers(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*ctrl.Result, error) {
+	now := time.Now()
+
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if server.FinalizerExpired(t, r.FinalizerTimeout, now) && meta.RemoveFinalizer(t, server.Finalizer) {
+			if err := r.Update(ctx, t); err != nil {
+				return controller.RequeueConflict(err)
+			}
+
+			r.Recorder.Eventf(t, corev1.EventTypeWarning, "FinalizerTimeout", "Removed server finalizer after waiting %s, the trial was not reported", r.FinalizerTimeout)
+			log.Info("Removed expired trial finalizer", "trial", t.Namespace+"/"+t.Name)
+		}
+	}
+
+	if server.FinalizerExpired(exp, r.FinalizerTimeout, now) && meta.RemoveFinalizer(exp, server.Finalizer) {
+		if err := r.Update(ctx, exp); err != nil {
+			return controller.RequeueConflict(err)
+		}
+
+		r.Recorder.Eventf(exp, corev1.EventTypeWarning, "FinalizerTimeout", "Removed server finalizer after waiting %s, the experiment was not unlinked", r.FinalizerTimeout)
+		log.Info("Removed expired experiment finalizer")
+		return &ctrl.Result{}, nil
+	}
+
+	return nil, nil
+}
+
+// checkDrift compares the cluster experiment with the server definition, differences are recorded on the experiment
+// status and prevent trials from being created or reported until the definitions match again
+func (r *ServerReconciler) checkDrift(ctx context.Context, log logr.Logger, exp *redskyv1beta1.Experiment) (*ctrl.Result, error) {
+	key := types.NamespacedName{Namespace: exp.Namespace, Name: exp.Name}
+	if !r.checksDrift(exp) {
+		r.driftChecks.Delete(key)
+		return nil, nil
+	}
+
+	// Only check once per interval
+	if checked, ok := r.driftChecks.Load(key); ok && time.Since(checked.(time.Time)) < requeueIntervals(r.Intervals).DriftCheck {
+		return nil, nil
+	}
+	r.driftChecks.Store(key, time.Now())
+
+	ee, err := r.ExperimentsAPI.GetExperimentByName(ctx, experimentsv1alpha1.NewExperimentName(exp.Name))
+	if err != nil {
+		// Drift detection is best effort, the next check will try again
+		log.Error(err, "Failed to fetch remote experiment for drift detection")
+		return nil, nil
+	}
+
+	if !server.CheckDrift(exp, &ee) {
+		return nil, nil
+	}
+
+	if experiment.IsDrifted(exp) {
+		log.Info("Experiment definition has drifted from the server")
+	}
+	if err := r.Update(ctx, exp); err != nil {
+		return controller.RequeueConflict(err)
+	}
+	return nil, nil
+}
+
+// checksDrift returns true if the experiment is eligible for drift detection
+func (r *ServerReconciler) checksDrift(exp *redskyv1beta1.Experiment) bool {
+	if exp.GetAnnotations()[redskyv1beta1.AnnotationExperimentURL] == "" {
+		return false
+	}
+	return exp.DeletionTimestamp.IsZero() && !experiment.IsFinished(exp)
+}
+
+// syncFailed records a failed server interaction on the experiment status before returning the error
+func (r *ServerReconciler) syncFailed(ctx context.Context, exp *redskyv1beta1.Experiment, reason string, err error) (*ctrl.Result, error) {
+	server.SyncFailed(exp, reason, err)
+	if err := r.Update(ctx, exp); err != nil {
+		return controller.RequeueConflict(err)
+	}
+	return &ctrl.Result{}, err
+}
@@ -18,7 +18,6 @@ package controllers
 
 import (
 	"context"
-	"time"
 
 	"github.com/go-logr/logr"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
@@ -41,8 +40,13 @@ type SetupReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Jobs is the cache of jobs created by the controller, the manager cache is used if nil
+	Jobs *controller.JobCache
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
 }
 
+// +kubebuilder:rbac:groups=redskyops.dev,resources=experiments,verbs=get;list;watch
 // +kubebuilder:rbac:groups=redskyops.dev,resources=trials;trials/finalizers,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
 // +kubebuilder:rbac:groups=batch;extensions,resources=jobs,verbs=list;watch;create
@@ -81,10 +85,10 @@ func (r *SetupReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 func (r *SetupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// TODO Have some type of setting to by-pass this
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named("setup").
-		For(&redskyv1beta1.Trial{}).
-		Owns(&batchv1.Job{}).
+		For(&redskyv1beta1.Trial{})
+	return controller.WatchJobs(b, r.Jobs).
 		Complete(r)
 }
 
@@ -198,7 +202,16 @@ func (r *SetupReconciler) createSetupJob(ctx context.Context, t *redskyv1beta1.T
 
 	// Create a setup job if necessary
 	if mode != "" {
-		job, err := setup.NewJob(t, mode)
+		// The experiment is needed to render Helm values, it may already be gone by the time the delete job runs
+		exp := &redskyv1beta1.Experiment{}
+		if err := r.Get(ctx, t.ExperimentNamespacedName(), exp); err != nil {
+			if !apierrs.IsNotFound(err) {
+				return &ctrl.Result{}, err
+			}
+			exp = nil
+		}
+
+		job, err := setup.NewJob(exp, t, mode)
 		if err != nil {
 			return &ctrl.Result{}, err
 		}
@@ -225,7 +238,7 @@ func (r *SetupReconciler) finish(ctx context.Context, t *redskyv1beta1.Trial) (*
 	// If the create job isn't finished, wait for it (unless the trial is already finished, i.e. failed)
 	if trial.CheckCondition(&t.Status, redskyv1beta1.TrialSetupCreated, corev1.ConditionFalse) {
 		if !trial.IsFinished(t) && t.DeletionTimestamp.IsZero() {
-			return &ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+			return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).SetupJob}, nil
 		}
 	}
 
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/controller"
+	"github.com/thestormforge/optimize-controller/internal/sink"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SinkReconciler publishes finished trials to an external system
+type SinkReconciler struct {
+	client.Client
+	Log logr.Logger
+	// Publisher receives an event for each finished trial
+	Publisher sink.Publisher
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
+}
+
+// +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=get;list;watch;update
+
+func (r *SinkReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	t := &redskyv1beta1.Trial{}
+	if err := r.Get(ctx, req.NamespacedName, t); err != nil {
+		return ctrl.Result{}, controller.IgnoreNotFound(err)
+	}
+
+	if result, err := r.publish(ctx, t); result != nil {
+		return *result, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *SinkReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sink").
+		For(&redskyv1beta1.Trial{}).
+		Complete(r)
+}
+
+// publish sends an event for the trial once it has finished and it's metrics have been collected
+func (r *SinkReconciler) publish(ctx context.Context, t *redskyv1beta1.Trial) (*ctrl.Result, error) {
+	if t.Annotations[redskyv1beta1.AnnotationPublishedTime] != "" || !t.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	// Completed trials are only published once they have been observed (i.e. all of the values are available)
+	if !trial.CheckCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue) &&
+		!trial.CheckCondition(&t.Status, redskyv1beta1.TrialObserved, corev1.ConditionTrue) {
+		return nil, nil
+	}
+
+	e := sink.NewEvent(t)
+	if e == nil {
+		return nil, nil
+	}
+
+	if err := r.Publisher.Publish(ctx, e); err != nil {
+		r.Log.Info("Failed to publish trial", "trial", fmt.Sprintf("%s/%s", t.Namespace, t.Name), "error", err.Error())
+		return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).SinkRetry}, nil
+	}
+
+	metav1.SetMetaDataAnnotation(&t.ObjectMeta, redskyv1beta1.AnnotationPublishedTime, metav1.Now().UTC().Format(time.RFC3339))
+	if err := r.Update(ctx, t); err != nil {
+		return controller.RequeueConflict(err)
+	}
+	return &ctrl.Result{}, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// trialExperimentIndex is the name of the cache index used to find trials using the experiment label.
+const trialExperimentIndex = "metadata.labels." + redskyv1beta1.LabelExperiment
+
+// SetupIndexes registers the cache indexes shared by the controllers, it must be called exactly once before
+// the controllers are added to the manager.
+func SetupIndexes(mgr ctrl.Manager) error {
+	if err := indexTrialsByExperiment(mgr); err != nil {
+		return err
+	}
+	return mgr.GetFieldIndexer().IndexField(&corev1.Namespace{}, "metadata.name", func(obj runtime.Object) []string {
+		return []string{obj.(*corev1.Namespace).Name}
+	})
+}
+
+// indexTrialsByExperiment adds a cache index for looking up trials by experiment name. Without the index,
+// every trial list must scan all of the trials in the cache, i.e. the cost of reconciling all of the trials
+// grows quadratically with the number of trials.
+func indexTrialsByExperiment(mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(&redskyv1beta1.Trial{}, trialExperimentIndex, func(obj runtime.Object) []string {
+		if name := obj.(*redskyv1beta1.Trial).Labels[redskyv1beta1.LabelExperiment]; name != "" {
+			return []string{name}
+		}
+		return nil
+	})
+}
+
+// listTrials retrieves the list of trial objects belonging to the supplied experiment.
+func listTrials(ctx context.Context, r client.Reader, trialList *redskyv1beta1.TrialList, exp *redskyv1beta1.Experiment) error {
+	// The default trial selector only matches the experiment label, use the index instead
+	if exp.Spec.Selector == nil {
+		return r.List(ctx, trialList, client.MatchingFields{trialExperimentIndex: exp.Name})
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(exp.Spec.Selector)
+	if err != nil {
+		return err
+	}
+	return r.List(ctx, trialList, client.MatchingLabelsSelector{Selector: s})
+}
@@ -36,15 +36,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// pendingPods restricts pod lists to the pods which may preempt a trial, pods are not cached so the field selector is
+// evaluated by the API server
+var pendingPods = client.MatchingFields{"status.phase": string(corev1.PodPending)}
+
 // TrialJobReconciler reconciles a Trial's job
 type TrialJobReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Jobs is the cache of jobs created by the controller, the manager cache is used if nil
+	Jobs *controller.JobCache
+	// Intervals are the delays used when polling for changes, the defaults are used if nil
+	Intervals *RequeueIntervals
 }
 
 // +kubebuilder:rbac:groups=redskyops.dev,resources=trials,verbs=get;list;watch;update
-// +kubebuilder:rbac:groups=batch;extensions,resources=jobs,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=batch;extensions,resources=jobs,verbs=get;list;watch;create;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
 
 func (r *TrialJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -62,6 +70,18 @@ func (r *TrialJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
+	// Stop the trial run job if the trial failed while it was running (e.g. a metric exceeded the fail fast limit)
+	if trial.CheckCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue) {
+		result, err := r.abort(ctx, t, jobList, &now)
+		return *result, err
+	}
+
+	// Wait for the preempting workloads to be scheduled before retrying a preempted trial
+	if trial.IsPreempted(t) {
+		result, err := r.resumePreempted(ctx, t, jobList, &now)
+		return *result, err
+	}
+
 	// Update trial status based on existing job state
 	if result, err := r.updateStatus(ctx, t, jobList, &now); result != nil {
 		return *result, err
@@ -69,6 +89,12 @@ func (r *TrialJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	// Create a new job if necessary
 	if len(jobList.Items) > 0 {
+		result, err := r.preempt(ctx, t, jobList, &now)
+		return *result, err
+	}
+
+	// Wait for the pre-trial hooks to finish
+	if trial.HooksPending(t, redskyv1beta1.TrialHookPreTrial) {
 		return ctrl.Result{}, nil
 	}
 
@@ -93,10 +119,10 @@ func (r *TrialJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 }
 
 func (r *TrialJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named("trial-job").
-		For(&redskyv1beta1.Trial{}).
-		Owns(&batchv1.Job{}).
+		For(&redskyv1beta1.Trial{})
+	return controller.WatchJobs(b, r.Jobs).
 		Complete(r)
 }
 
@@ -106,9 +132,9 @@ func (r *TrialJobReconciler) ignoreTrial(t *redskyv1beta1.Trial) bool {
 		return true
 	}
 
-	// Ignore failed trials
+	// Ignore failed trials, unless they failed while the trial run job was still running
 	if trial.CheckCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue) {
-		return true
+		return t.Status.StartTime == nil || t.Status.CompletionTime != nil
 	}
 
 	// Ignore trials that are not ready yet
@@ -140,8 +166,94 @@ func (r *TrialJobReconciler) updateStatus(ctx context.Context, t *redskyv1beta1.
 	return nil, nil
 }
 
+// preempt will abort the trial run job if a preempting workload cannot be scheduled and this trial is the
+// best candidate for preemption
+func (r *TrialJobReconciler) preempt(ctx context.Context, t *redskyv1beta1.Trial, jobList *batchv1.JobList, probeTime *metav1.Time) (*ctrl.Result, error) {
+	if t.Spec.Preemption == nil || t.Status.StartTime == nil {
+		return &ctrl.Result{}, nil
+	}
+
+	// We are not watching pods, poll for workloads that cannot be scheduled
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, pendingPods); err != nil {
+		return &ctrl.Result{}, err
+	}
+	pod := trial.PreemptingPod(t, podList)
+	if pod == nil {
+		return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).Preemption}, nil
+	}
+
+	// Only the best candidate across all of the running trials is preempted
+	trialList := &redskyv1beta1.TrialList{}
+	if err := r.List(ctx, trialList); err != nil {
+		return &ctrl.Result{}, err
+	}
+	if c := trial.PreemptionCandidate(trialList, pod, probeTime.Time); c == nil || c.UID != t.UID {
+		return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).Preemption}, nil
+	}
+
+	// Delete the trial run job to release the resources
+	for i := range jobList.Items {
+		if err := r.Delete(ctx, &jobList.Items[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); controller.IgnoreNotFound(err) != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	// The trial will run again from the beginning so reset the start time
+	msg := fmt.Sprintf("preempted by pod %s/%s", pod.Namespace, pod.Name)
+	trial.ApplyCondition(&t.Status, redskyv1beta1.TrialPreempted, corev1.ConditionTrue, "Preempted", msg, probeTime)
+	t.Status.StartTime = nil
+	err := r.Update(ctx, t)
+	return controller.RequeueConflict(err)
+}
+
+// abort will suspend the trial run job of a failed trial and record the completion time of the trial
+func (r *TrialJobReconciler) abort(ctx context.Context, t *redskyv1beta1.Trial, jobList *batchv1.JobList, probeTime *metav1.Time) (*ctrl.Result, error) {
+	// Patch the job and set parallelism to 0 to suspend the job and terminate any active pods
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if job.Spec.Parallelism != nil && *job.Spec.Parallelism == 0 {
+			continue
+		}
+		if err := r.Patch(ctx, job, client.RawPatch(types.StrategicMergePatchType, []byte(`{ "spec": { "parallelism": 0  } }`))); controller.IgnoreNotFound(err) != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	t.Status.CompletionTime = probeTime.DeepCopy()
+	err := r.Update(ctx, t)
+	return controller.RequeueConflict(err)
+}
+
+// resumePreempted will allow a preempted trial to run again once the preempting workloads have been scheduled
+func (r *TrialJobReconciler) resumePreempted(ctx context.Context, t *redskyv1beta1.Trial, jobList *batchv1.JobList, probeTime *metav1.Time) (*ctrl.Result, error) {
+	// Wait for the aborted trial run job to be removed
+	if len(jobList.Items) > 0 {
+		return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).Preemption}, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, pendingPods); err != nil {
+		return &ctrl.Result{}, err
+	}
+	if trial.PreemptingPod(t, podList) != nil {
+		return &ctrl.Result{RequeueAfter: requeueIntervals(r.Intervals).Preemption}, nil
+	}
+
+	trial.ApplyCondition(&t.Status, redskyv1beta1.TrialPreempted, corev1.ConditionFalse, "Resumed", "", probeTime)
+	err := r.Update(ctx, t)
+	return controller.RequeueConflict(err)
+}
+
 // createJob will create a new trial run job
 func (r *TrialJobReconciler) createJob(ctx context.Context, t *redskyv1beta1.Trial) (*ctrl.Result, error) {
+	// Record where the artifacts will be uploaded before the trial run starts
+	if u := trial.ArtifactsURL(t); u != "" && t.Annotations[redskyv1beta1.AnnotationArtifactsURL] != u {
+		metav1.SetMetaDataAnnotation(&t.ObjectMeta, redskyv1beta1.AnnotationArtifactsURL, u)
+		err := r.Update(ctx, t)
+		return controller.RequeueConflict(err)
+	}
+
 	job := trial.NewJob(t)
 	if err := controllerutil.SetControllerReference(t, job, r.Scheme); err != nil {
 		return &ctrl.Result{}, err
@@ -240,6 +352,11 @@ func (r *TrialJobReconciler) applyJobStatus(ctx context.Context, t *redskyv1beta
 func containerTime(pods *corev1.PodList) (startedAt *metav1.Time, finishedAt *metav1.Time) {
 	for i := range pods.Items {
 		for j := range pods.Items[i].Status.ContainerStatuses {
+			// The artifacts are uploaded after the trial run is over
+			if pods.Items[i].Status.ContainerStatuses[j].Name == trial.ArtifactsContainerName {
+				continue
+			}
+
 			s := &pods.Items[i].Status.ContainerStatuses[j].State
 			if s.Running != nil {
 				startedAt, _ = earliestTime(startedAt, &s.Running.StartedAt)
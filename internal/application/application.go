@@ -21,9 +21,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
-	"unicode"
 
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	"github.com/thestormforge/optimize-controller/internal/meta"
+	"github.com/thestormforge/optimize-controller/internal/scan"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 )
 
@@ -132,6 +133,26 @@ func GuessScenarioAndObjective(app *redskyappsv1alpha1.Application, experimentNa
 	return "", ""
 }
 
+// ExcludeFilter returns a filter that removes any resources matching the supplied exclusions.
+// Empty exclusions are ignored, otherwise they would match (and remove) every resource.
+func ExcludeFilter(excludes []redskyappsv1alpha1.ResourceExclusion) scan.ExcludeFilter {
+	result := make(scan.ExcludeFilter, 0, len(excludes))
+	for _, e := range excludes {
+		if e == (redskyappsv1alpha1.ResourceExclusion{}) {
+			continue
+		}
+
+		result = append(result, scan.GenericSelector{
+			Group:         e.Group,
+			Kind:          e.Kind,
+			Name:          e.Name,
+			Namespace:     e.Namespace,
+			LabelSelector: e.Selector,
+		})
+	}
+	return result
+}
+
 // WorkingDirectory returns the directory the application was loaded from. This
 // directory should be used as the effective working directory when resolving relative
 // paths found in the application definition.
@@ -143,23 +164,8 @@ func WorkingDirectory(app *redskyappsv1alpha1.Application) string {
 }
 
 func cleanName(n string) string {
-	n = strings.Map(func(r rune) rune {
-		r = unicode.ToLower(r)
-		if r >= 'a' && r <= 'z' {
-			return r
-		}
-		if r >= '0' && r <= '9' {
-			return r
-		}
-		if r == '-' {
-			return r
-		}
-		return -1
-	}, n)
-
-	if n == "" {
+	if n = meta.CleanName(n); n == "" {
 		n = "default"
 	}
-
 	return n
 }
@@ -20,8 +20,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	konjurev1beta2 "github.com/thestormforge/konjure/pkg/api/core/v1beta2"
+	"github.com/thestormforge/konjure/pkg/konjure"
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func TestExperimentName(t *testing.T) {
@@ -62,3 +65,205 @@ func TestExperimentName(t *testing.T) {
 		})
 	}
 }
+
+func TestIngressURL(t *testing.T) {
+	service := `apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  namespace: shop
+spec:
+  ports:
+  - name: http
+    port: 8080
+`
+	otherService := `apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+  namespace: shop
+spec:
+  ports:
+  - name: http
+    port: 9090
+`
+	ingress := `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: frontend
+  namespace: shop
+spec:
+  tls:
+  - hosts:
+    - shop.example.com
+  rules:
+  - host: shop.example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: frontend
+          servicePort: 8080
+`
+	ingressV1 := `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: frontend
+  namespace: shop
+spec:
+  rules:
+  - host: shop.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: frontend
+            port:
+              number: 8080
+`
+	route := `apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: frontend
+  namespace: shop
+spec:
+  host: shop.apps.example.com
+  to:
+    kind: Service
+    name: frontend
+`
+
+	cases := []struct {
+		desc           string
+		resources      []string
+		preferInternal bool
+		expected       string
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:      "service",
+			resources: []string{service},
+			expected:  "http://frontend.shop:8080",
+		},
+		{
+			desc:      "ingress",
+			resources: []string{service, ingress},
+			expected:  "https://shop.example.com/",
+		},
+		{
+			desc:           "ingress prefer internal",
+			resources:      []string{ingress, service},
+			preferInternal: true,
+			expected:       "http://frontend.shop:8080",
+		},
+		{
+			desc:      "ingress v1",
+			resources: []string{service, ingressV1},
+			expected:  "http://shop.example.com/",
+		},
+		{
+			desc:           "ingress v1 prefer internal",
+			resources:      []string{otherService, ingressV1, service},
+			preferInternal: true,
+			expected:       "http://frontend.shop:8080",
+		},
+		{
+			desc:      "route",
+			resources: []string{service, route},
+			expected:  "http://shop.apps.example.com",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var nodes []*yaml.RNode
+			for _, r := range c.resources {
+				nodes = append(nodes, yaml.MustParse(r))
+			}
+
+			actual, err := IngressURL(nodes, c.preferInternal)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExcludeFilter(t *testing.T) {
+	nodes := []*yaml.RNode{
+		yaml.MustParse("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: foo\n"),
+		yaml.MustParse("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: bar\n"),
+	}
+
+	cases := []struct {
+		desc     string
+		excludes []redskyappsv1alpha1.ResourceExclusion
+		expected []string
+	}{
+		{
+			desc:     "none",
+			expected: []string{"foo", "bar"},
+		},
+		{
+			desc:     "empty",
+			excludes: []redskyappsv1alpha1.ResourceExclusion{{}},
+			expected: []string{"foo", "bar"},
+		},
+		{
+			desc:     "kind",
+			excludes: []redskyappsv1alpha1.ResourceExclusion{{}, {Kind: "Job"}},
+			expected: []string{"foo"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := ExcludeFilter(c.excludes).Filter(nodes)
+			if assert.NoError(t, err) {
+				var names []string
+				for _, node := range actual {
+					names = append(names, node.GetName())
+				}
+				assert.Equal(t, c.expected, names)
+			}
+		})
+	}
+}
+
+func TestGenerator_Resources(t *testing.T) {
+	cases := []struct {
+		desc      string
+		generator Generator
+		expected  konjure.Resources
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:      "default selector",
+			generator: Generator{Name: "checkout"},
+			expected: konjure.Resources{
+				{Kubernetes: &konjurev1beta2.Kubernetes{Selector: "app.kubernetes.io/name=checkout"}},
+			},
+		},
+		{
+			desc:      "namespace and selector",
+			generator: Generator{Name: "checkout", Namespaces: []string{"prod"}, LabelSelector: "app=checkout"},
+			expected: konjure.Resources{
+				{Kubernetes: &konjurev1beta2.Kubernetes{Namespaces: []string{"prod"}, Selector: "app=checkout"}},
+			},
+		},
+		{
+			desc:      "explicit resources",
+			generator: Generator{Name: "checkout", Resources: konjure.Resources{konjure.NewResource("app.yaml")}},
+			expected:  konjure.Resources{konjure.NewResource("app.yaml")},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.generator.resources())
+		})
+	}
+}
@@ -35,6 +35,11 @@ can be URL-like values such as file paths, HTTP URLs, or Git repository URLs.
 They can also be more complex definitions such references to in-cluster objects
 or Helm charts.
 # Reference: https://docs.stormforge.io/reference/application/v1alpha1/#application
+`,
+
+		"excludes": `
+Excludes are patterns of resources (for example, Jobs or resources managed by an
+operator) which should be ignored when generating experiments.
 `,
 
 		"parameters": `
@@ -66,8 +71,15 @@ Reference: https://docs.stormforge.io/reference/application/v1alpha1/#stormforge
 
 		"ingress": `
 Ingress defines the destination of the load test. This is typically a public
-facing URL for your application.
+facing URL for your application. When omitted, the URL is discovered from the
+Ingress, Route or Service resources of the application.
 Reference: https://docs.stormforge.io/reference/application/v1alpha1/#ingress
+`,
+
+		"credentials": `
+Credentials reference the secrets needed to test or measure your application.
+Trial job credentials are exposed to the load test as environment variables,
+metric credentials are used to authenticate Prometheus, Datadog or InfluxDB.
 `,
 	}
 
@@ -85,6 +97,11 @@ Reference: https://docs.stormforge.io/reference/application/v1alpha1/#ingress
     chart: nginx
     version: 8.5.4`,
 
+		"excludes": `
+Excludes are patterns of resources (for example, Jobs or resources managed by an
+operator) which should be ignored when generating experiments.
+`,
+
 		"parameters": `- containerResources:
     selector: component=postgres # Filters to only discover container resources (memory and CPU) for the specified labels
 - replicas:
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"time"
 
+	konjurev1beta2 "github.com/thestormforge/konjure/pkg/api/core/v1beta2"
 	"github.com/thestormforge/konjure/pkg/konjure"
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	"github.com/thestormforge/optimize-controller/internal/scan"
@@ -37,6 +38,14 @@ type Generator struct {
 	Name string
 	// The collection of resources defining the application.
 	Resources konjure.Resources
+	// The namespaces to scan for resources in the live cluster.
+	Namespaces []string
+	// The label selector used to find namespaces to scan in the live cluster.
+	NamespaceSelector string
+	// The label selector used to find resources in the live cluster.
+	LabelSelector string
+	// Patterns of resources to ignore.
+	Excludes []redskyappsv1alpha1.ResourceExclusion
 	// File name containing a description of the load to generate.
 	ScenarioFile string
 	// The list of goal names to include in the application
@@ -45,15 +54,18 @@ type Generator struct {
 	Documentation DocumentationFilter
 	// An explicit working directory used to relativize file paths.
 	WorkingDirectory string
+	// Flag indicating the cluster internal service DNS name should be preferred for the ingress URL.
+	PreferInternalIngress bool
 	// Configure the filter options.
 	scan.FilterOptions
 }
 
 func (g *Generator) Execute(output kio.Writer) error {
 	return kio.Pipeline{
-		Inputs: []kio.Reader{g.Resources},
+		Inputs: []kio.Reader{g.resources()},
 		Filters: []kio.Filter{
 			g.FilterOptions.NewFilter(g.WorkingDirectory),
+			ExcludeFilter(g.Excludes),
 			&scan.Scanner{
 				Selectors:   []scan.Selector{g},
 				Transformer: g,
@@ -97,7 +109,7 @@ func (g *Generator) Map(node *yaml.RNode, meta yaml.ResourceMeta) ([]interface{}
 }
 
 // Transform converts the scan information into an application definition.
-func (g *Generator) Transform(_ []*yaml.RNode, selected []interface{}) ([]*yaml.RNode, error) {
+func (g *Generator) Transform(nodes []*yaml.RNode, selected []interface{}) ([]*yaml.RNode, error) {
 	result := sfio.ObjectSlice{}
 
 	app := &redskyappsv1alpha1.Application{}
@@ -114,6 +126,10 @@ func (g *Generator) Transform(_ []*yaml.RNode, selected []interface{}) ([]*yaml.
 		return nil, err
 	}
 
+	if _, err := DiscoverIngress(app, g.PreferInternalIngress).Filter(nodes); err != nil {
+		return nil, err
+	}
+
 	if err := g.clean(app); err != nil {
 		return nil, err
 	}
@@ -148,7 +164,13 @@ func (g *Generator) merge(src, dst *redskyappsv1alpha1.Application) {
 		dst.Annotations = src.Annotations
 	}
 
+	if src.Ingress != nil {
+		dst.Ingress = src.Ingress
+	}
+
 	dst.Resources = append(dst.Resources, src.Resources...)
+	dst.Excludes = append(dst.Excludes, src.Excludes...)
+	dst.Credentials = append(dst.Credentials, src.Credentials...)
 	dst.Scenarios = append(dst.Scenarios, src.Scenarios...)
 	dst.Objectives = append(dst.Objectives, src.Objectives...)
 }
@@ -162,7 +184,8 @@ func (g *Generator) apply(app *redskyappsv1alpha1.Application) error {
 		app.Name = g.Name
 	}
 
-	app.Resources = append(app.Resources, g.Resources...)
+	app.Resources = append(app.Resources, g.resources()...)
+	app.Excludes = append(app.Excludes, g.Excludes...)
 
 	if s, err := g.readScenario(); err != nil {
 		return err
@@ -179,6 +202,30 @@ func (g *Generator) apply(app *redskyappsv1alpha1.Application) error {
 	return nil
 }
 
+// resources returns the resources to scan, including any resources selected from
+// the live cluster.
+func (g *Generator) resources() konjure.Resources {
+	labelSelector := g.LabelSelector
+	if len(g.Resources) == 0 && labelSelector == "" && g.Name != "" {
+		// Use a default label selector based on the application name
+		labelSelector = "app.kubernetes.io/name=" + g.Name
+	}
+
+	// Only include the cluster resources if there is something to select on
+	if len(g.Namespaces) == 0 && g.NamespaceSelector == "" && labelSelector == "" {
+		return g.Resources
+	}
+
+	result := make(konjure.Resources, 0, len(g.Resources)+1)
+	result = append(result, g.Resources...)
+	result = append(result, konjure.Resource{Kubernetes: &konjurev1beta2.Kubernetes{
+		Namespaces:        g.Namespaces,
+		NamespaceSelector: g.NamespaceSelector,
+		Selector:          labelSelector,
+	}})
+	return result
+}
+
 // clean ensures that the application state is reasonable.
 func (g *Generator) clean(app *redskyappsv1alpha1.Application) error {
 	var resources []konjure.Resource
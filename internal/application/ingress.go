@@ -0,0 +1,315 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	"github.com/thestormforge/optimize-controller/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DiscoverIngress returns a filter that populates the ingress URL of the supplied
+// application (if it is not already set) using the resources in the stream. The
+// resources themselves are not modified.
+func DiscoverIngress(app *redskyappsv1alpha1.Application, preferInternal bool) kio.Filter {
+	return kio.FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+		if app.Ingress != nil && app.Ingress.URL != "" {
+			return nodes, nil
+		}
+
+		u, err := IngressURL(nodes, preferInternal)
+		if err != nil {
+			return nil, err
+		}
+		if u != "" {
+			app.Ingress = &redskyappsv1alpha1.Ingress{URL: u}
+		}
+
+		return nodes, nil
+	})
+}
+
+// IngressURL inspects the supplied Ingress, Route and Service resources to determine the URL
+// used to access the application. External URLs (e.g. from an Ingress) are preferred unless
+// the internal flag is set, in which case the cluster DNS name of a service is used. An empty
+// string is returned if no URL could be found.
+func IngressURL(nodes []*yaml.RNode, preferInternal bool) (string, error) {
+	var external, internal []ingressCandidate
+	for _, node := range nodes {
+		meta, err := node.GetMeta()
+		if err != nil {
+			return "", err
+		}
+
+		c, err := scanIngress(node, meta)
+		if err != nil {
+			return "", err
+		}
+		if c == nil {
+			continue
+		}
+
+		if c.internal {
+			internal = append(internal, *c)
+		} else {
+			external = append(external, *c)
+		}
+	}
+
+	if !preferInternal {
+		if len(external) > 0 {
+			return external[0].url, nil
+		}
+		if len(internal) > 0 {
+			return internal[0].url, nil
+		}
+		return "", nil
+	}
+
+	// Prefer the service used as the backend of an external ingress
+	for _, e := range external {
+		for _, i := range internal {
+			if e.namespace == i.namespace && e.service != "" && e.service == i.service {
+				return i.url, nil
+			}
+		}
+	}
+	if len(internal) > 0 {
+		return internal[0].url, nil
+	}
+	if len(external) > 0 {
+		return external[0].url, nil
+	}
+	return "", nil
+}
+
+// ingressCandidate is a possible ingress URL discovered while scanning.
+type ingressCandidate struct {
+	url       string
+	internal  bool
+	namespace string
+	service   string
+}
+
+// scanIngress returns the ingress candidate for a single resource, if any.
+func scanIngress(node *yaml.RNode, meta yaml.ResourceMeta) (*ingressCandidate, error) {
+	switch {
+	case meta.Kind == "Ingress" && (strings.HasPrefix(meta.APIVersion, "networking.k8s.io/") || strings.HasPrefix(meta.APIVersion, "extensions/")):
+		ing := &networkingv1beta1.Ingress{}
+		if err := sfio.DecodeYAMLToJSON(node, ing); err != nil {
+			return nil, err
+		}
+		if meta.APIVersion == "networking.k8s.io/v1" {
+			if err := decodeIngressV1Backends(node, ing); err != nil {
+				return nil, err
+			}
+		}
+		return ingressCandidateFromIngress(ing), nil
+
+	case meta.Kind == "Route" && strings.HasPrefix(meta.APIVersion, "route.openshift.io/"):
+		return ingressCandidateFromRoute(node, meta)
+
+	case meta.Kind == "Service" && meta.APIVersion == "v1":
+		svc := &corev1.Service{}
+		if err := sfio.DecodeYAMLToJSON(node, svc); err != nil {
+			return nil, err
+		}
+		return ingressCandidateFromService(svc), nil
+	}
+
+	return nil, nil
+}
+
+// decodeIngressV1Backends fills in the backends of an Ingress decoded from the "networking.k8s.io/v1" API, the
+// remainder of the schema is compatible with "v1beta1" but the default backend and service references were changed.
+func decodeIngressV1Backends(node *yaml.RNode, ing *networkingv1beta1.Ingress) error {
+	type ingressBackendV1 struct {
+		Service *struct {
+			Name string `json:"name"`
+			Port struct {
+				Name   string `json:"name"`
+				Number int32  `json:"number"`
+			} `json:"port"`
+		} `json:"service"`
+	}
+	v1 := struct {
+		Spec struct {
+			DefaultBackend *ingressBackendV1 `json:"defaultBackend"`
+			Rules          []struct {
+				HTTP *struct {
+					Paths []struct {
+						Backend ingressBackendV1 `json:"backend"`
+					} `json:"paths"`
+				} `json:"http"`
+			} `json:"rules"`
+		} `json:"spec"`
+	}{}
+	if err := sfio.DecodeYAMLToJSON(node, &v1); err != nil {
+		return err
+	}
+
+	convert := func(in *ingressBackendV1, out *networkingv1beta1.IngressBackend) {
+		if in.Service == nil {
+			return
+		}
+		out.ServiceName = in.Service.Name
+		if in.Service.Port.Name != "" {
+			out.ServicePort = intstr.FromString(in.Service.Port.Name)
+		} else {
+			out.ServicePort = intstr.FromInt(int(in.Service.Port.Number))
+		}
+	}
+
+	if v1.Spec.DefaultBackend != nil {
+		ing.Spec.Backend = &networkingv1beta1.IngressBackend{}
+		convert(v1.Spec.DefaultBackend, ing.Spec.Backend)
+	}
+	for i := range v1.Spec.Rules {
+		if v1.Spec.Rules[i].HTTP == nil || i >= len(ing.Spec.Rules) || ing.Spec.Rules[i].HTTP == nil {
+			continue
+		}
+		for j := range v1.Spec.Rules[i].HTTP.Paths {
+			if j < len(ing.Spec.Rules[i].HTTP.Paths) {
+				convert(&v1.Spec.Rules[i].HTTP.Paths[j].Backend, &ing.Spec.Rules[i].HTTP.Paths[j].Backend)
+			}
+		}
+	}
+	return nil
+}
+
+// ingressCandidateFromIngress uses the first rule with a host name.
+func ingressCandidateFromIngress(ing *networkingv1beta1.Ingress) *ingressCandidate {
+	u := url.URL{Scheme: "http"}
+	if len(ing.Spec.TLS) > 0 {
+		u.Scheme = "https"
+	}
+
+	var backend *networkingv1beta1.IngressBackend
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host == "" || strings.Contains(rule.Host, "*") {
+			continue
+		}
+
+		u.Host = rule.Host
+		if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+			if p := rule.HTTP.Paths[0].Path; !strings.ContainsAny(p, "()*") {
+				u.Path = p
+			}
+			backend = &rule.HTTP.Paths[0].Backend
+		}
+		break
+	}
+
+	// Fall back to the load balancer status if there are no host names
+	if u.Host == "" {
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			if u.Host = lb.Hostname; u.Host == "" {
+				u.Host = lb.IP
+			}
+			if u.Host != "" {
+				break
+			}
+		}
+	}
+	if u.Host == "" {
+		return nil
+	}
+
+	if backend == nil {
+		backend = ing.Spec.Backend
+	}
+
+	c := &ingressCandidate{url: u.String(), namespace: ing.Namespace}
+	if backend != nil {
+		c.service = backend.ServiceName
+	}
+	return c
+}
+
+// ingressCandidateFromRoute uses the host of an OpenShift route.
+func ingressCandidateFromRoute(node *yaml.RNode, meta yaml.ResourceMeta) (*ingressCandidate, error) {
+	route := struct {
+		Spec struct {
+			Host string `json:"host"`
+			Path string `json:"path"`
+			TLS  *struct {
+				Termination string `json:"termination"`
+			} `json:"tls"`
+			To struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"spec"`
+	}{}
+	if err := sfio.DecodeYAMLToJSON(node, &route); err != nil {
+		return nil, err
+	}
+	if route.Spec.Host == "" {
+		return nil, nil
+	}
+
+	u := url.URL{Scheme: "http", Host: route.Spec.Host, Path: route.Spec.Path}
+	if route.Spec.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	c := &ingressCandidate{url: u.String(), namespace: meta.Namespace}
+	if route.Spec.To.Kind == "Service" {
+		c.service = route.Spec.To.Name
+	}
+	return c, nil
+}
+
+// ingressCandidateFromService uses the load balancer status of a service, or the cluster DNS name.
+func ingressCandidateFromService(svc *corev1.Service) *ingressCandidate {
+	if len(svc.Spec.Ports) == 0 || svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return nil
+	}
+
+	port := svc.Spec.Ports[0]
+	u := url.URL{Scheme: "http"}
+	if port.Port == 443 || strings.Contains(strings.ToLower(port.Name), "https") {
+		u.Scheme = "https"
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			if u.Host = lb.Hostname; u.Host == "" {
+				u.Host = lb.IP
+			}
+			if u.Host != "" {
+				u.Host = fmt.Sprintf("%s:%d", u.Host, port.Port)
+				return &ingressCandidate{url: u.String(), namespace: svc.Namespace, service: svc.Name}
+			}
+		}
+	}
+
+	u.Host = svc.Name
+	if svc.Namespace != "" {
+		u.Host += "." + svc.Namespace
+	}
+	u.Host = fmt.Sprintf("%s:%d", u.Host, port.Port)
+	return &ingressCandidate{url: u.String(), internal: true, namespace: svc.Namespace, service: svc.Name}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thestormforge/optimize-controller/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// The default manager cache holds a full copy of every object of every kind that is read or watched, regardless of
+// namespace or labels. On a busy cluster, the jobs and pods that have nothing to do with the controller dominate its
+// memory footprint. Instead, only the jobs the controller creates (which always have a trial role label) are cached
+// and the kinds which are only ever read on demand (e.g. pods) bypass the cache entirely.
+
+// JobCache is a cache of the jobs created by the controller.
+type JobCache struct {
+	informer  toolscache.SharedIndexInformer
+	indexer   toolscache.Indexer
+	hasSynced toolscache.InformerSynced
+}
+
+// NewJobCache returns a new cache of jobs which have a trial role label. The cache must be added to the manager so it
+// is started and stopped with the other caches.
+func NewJobCache(config *rest.Config, resync time.Duration) (*JobCache, error) {
+	cs, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	f := informers.NewSharedInformerFactoryWithOptions(cs, resync, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+		opts.LabelSelector = v1beta1.LabelTrialRole
+	}))
+
+	informer := f.Batch().V1().Jobs().Informer()
+	return &JobCache{
+		informer:  informer,
+		indexer:   informer.GetIndexer(),
+		hasSynced: informer.HasSynced,
+	}, nil
+}
+
+// Informer returns the shared informer used to watch the cached jobs.
+func (c *JobCache) Informer() toolscache.SharedIndexInformer {
+	return c.informer
+}
+
+// Start runs the informer until the supplied channel is closed.
+func (c *JobCache) Start(stop <-chan struct{}) error {
+	c.informer.Run(stop)
+	return nil
+}
+
+// List returns the cached jobs matching the supplied options, waiting for the cache to sync if necessary.
+func (c *JobCache) List(ctx context.Context, list *batchv1.JobList, opts ...client.ListOption) error {
+	if !toolscache.WaitForCacheSync(ctx.Done(), c.hasSynced) {
+		return fmt.Errorf("job cache did not sync")
+	}
+
+	lo := (&client.ListOptions{}).ApplyOptions(opts)
+	sel := lo.LabelSelector
+	if sel == nil {
+		sel = labels.Everything()
+	}
+
+	var items []batchv1.Job
+	err := toolscache.ListAllByNamespace(c.indexer, lo.Namespace, sel, func(obj interface{}) {
+		if job, ok := obj.(*batchv1.Job); ok {
+			items = append(items, *job.DeepCopy())
+		}
+	})
+	list.Items = items
+	return err
+}
+
+// WatchJobs configures the supplied builder to reconcile trials when the jobs they own change. The jobs are watched
+// using the job cache when it is available, otherwise the manager cache is used.
+func WatchJobs(b *builder.Builder, jobs *JobCache) *builder.Builder {
+	if jobs == nil {
+		return b.Owns(&batchv1.Job{})
+	}
+	return b.Watches(&source.Informer{Informer: jobs.Informer()}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &v1beta1.Trial{},
+		IsController: true,
+	})
+}
+
+// NewClient returns a function for creating the manager client. Jobs are read from the supplied job cache (if
+// available) and kinds that are not watched by any controller are read directly from the API server.
+func NewClient(jobs *JobCache) func(cache.Cache, *rest.Config, client.Options) (client.Client, error) {
+	return func(cache cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+		c, err := client.New(config, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return &client.DelegatingClient{
+			Reader: &selectiveReader{
+				cacheReader:  cache,
+				clientReader: c,
+				jobs:         jobs,
+			},
+			Writer:       c,
+			StatusClient: c,
+		}, nil
+	}
+}
+
+// selectiveReader is a client reader that only uses the cache for the kinds the controllers watch.
+type selectiveReader struct {
+	cacheReader  client.Reader
+	clientReader client.Reader
+	jobs         *JobCache
+}
+
+// Get retrieves an object, uncached kinds are retrieved from the API server.
+func (r *selectiveReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if isUncached(obj) {
+		return r.clientReader.Get(ctx, key, obj)
+	}
+	return r.cacheReader.Get(ctx, key, obj)
+}
+
+// List retrieves a list of objects, jobs come from the job cache and uncached kinds are listed from the API server.
+func (r *selectiveReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if jobList, ok := list.(*batchv1.JobList); ok && r.jobs != nil {
+		return r.jobs.List(ctx, jobList, opts...)
+	}
+	if isUncached(list) {
+		return r.clientReader.List(ctx, list, opts...)
+	}
+	return r.cacheReader.List(ctx, list, opts...)
+}
+
+// isUncached checks to see if the supplied object (or list) should bypass the cache.
+func isUncached(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *unstructured.Unstructured, *unstructured.UnstructuredList,
+		*batchv1.Job,
+		*corev1.Pod, *corev1.PodList,
+		*corev1.Service, *corev1.ServiceList,
+		*corev1.Secret, *corev1.SecretList,
+		*corev1.ConfigMap, *corev1.ConfigMapList:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-controller/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordingReader records its name for each read
+type recordingReader struct {
+	name  string
+	reads *[]string
+}
+
+func (r *recordingReader) Get(context.Context, client.ObjectKey, runtime.Object) error {
+	*r.reads = append(*r.reads, r.name)
+	return nil
+}
+
+func (r *recordingReader) List(context.Context, runtime.Object, ...client.ListOption) error {
+	*r.reads = append(*r.reads, r.name)
+	return nil
+}
+
+func newTestJobCache(jobs ...*batchv1.Job) (*JobCache, error) {
+	indexer := toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, toolscache.Indexers{
+		toolscache.NamespaceIndex: toolscache.MetaNamespaceIndexFunc,
+	})
+	for _, job := range jobs {
+		if err := indexer.Add(job); err != nil {
+			return nil, err
+		}
+	}
+	return &JobCache{indexer: indexer, hasSynced: func() bool { return true }}, nil
+}
+
+func newTestJob(namespace, name, trialName string) *batchv1.Job {
+	return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Namespace: namespace,
+		Name:      name,
+		Labels:    map[string]string{v1beta1.LabelTrial: trialName, v1beta1.LabelTrialRole: "trialRun"},
+	}}
+}
+
+func TestJobCache_List(t *testing.T) {
+	jobs, err := newTestJobCache(
+		newTestJob("default", "one", "one"),
+		newTestJob("default", "two", "two"),
+		newTestJob("other", "one", "one"),
+	)
+	require.NoError(t, err)
+
+	cases := []struct {
+		desc     string
+		opts     []client.ListOption
+		expected []string
+	}{
+		{
+			desc:     "all",
+			expected: []string{"default/one", "default/two", "other/one"},
+		},
+		{
+			desc:     "namespace",
+			opts:     []client.ListOption{client.InNamespace("default")},
+			expected: []string{"default/one", "default/two"},
+		},
+		{
+			desc:     "labels",
+			opts:     []client.ListOption{client.MatchingLabels{v1beta1.LabelTrial: "one"}},
+			expected: []string{"default/one", "other/one"},
+		},
+		{
+			desc:     "namespace and labels",
+			opts:     []client.ListOption{client.InNamespace("other"), client.MatchingLabels{v1beta1.LabelTrial: "one"}},
+			expected: []string{"other/one"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			list := &batchv1.JobList{}
+			if assert.NoError(t, jobs.List(context.TODO(), list, c.opts...)) {
+				var actual []string
+				for i := range list.Items {
+					actual = append(actual, list.Items[i].Namespace+"/"+list.Items[i].Name)
+				}
+				assert.ElementsMatch(t, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSelectiveReader(t *testing.T) {
+	jobs, err := newTestJobCache(newTestJob("default", "one", "one"))
+	require.NoError(t, err)
+
+	var reads []string
+	r := &selectiveReader{
+		cacheReader:  &recordingReader{name: "cache", reads: &reads},
+		clientReader: &recordingReader{name: "client", reads: &reads},
+		jobs:         jobs,
+	}
+
+	jobList := &batchv1.JobList{}
+	assert.NoError(t, r.List(context.TODO(), jobList))
+	assert.NoError(t, r.List(context.TODO(), &corev1.PodList{}))
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{}, &corev1.Secret{}))
+	assert.NoError(t, r.List(context.TODO(), &v1beta1.TrialList{}))
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{}, &v1beta1.Experiment{}))
+
+	assert.Len(t, jobList.Items, 1)
+	assert.Equal(t, []string{"client", "client", "cache", "cache"}, reads)
+}
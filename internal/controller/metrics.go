@@ -44,6 +44,13 @@ var (
 		Name: "redsky_experiment_active_trials_total",
 		Help: "Total number of active trials present for an experiment",
 	}, []string{"experiment"})
+
+	// CustomResourceDefinitionDrift is a Prometheus gauge metric which is set to 1 when
+	// the installed custom resource definition does not match the controller
+	CustomResourceDefinitionDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "redsky_crd_schema_drift",
+		Help: "Indicates the installed custom resource definition does not match the controller",
+	}, []string{"crd"})
 )
 
 func init() {
@@ -51,5 +58,6 @@ func init() {
 		ReconcileConflictErrors,
 		ExperimentTrials,
 		ExperimentActiveTrials,
+		CustomResourceDefinitionDrift,
 	)
 }
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"reflect"
+	"sort"
+
+	"github.com/thestormforge/optimize-controller/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// basesDir is the directory of the embedded configuration containing the generated custom resource definitions
+const basesDir = "crd/bases"
+
+// GroupVersionKind is the type of the custom resource definitions embedded in the build
+var GroupVersionKind = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}
+
+// Expected returns the custom resource definitions this build of the controller was generated with, indexed by name
+func Expected() (map[string]*unstructured.Unstructured, error) {
+	entries, err := fs.ReadDir(config.Content, basesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*unstructured.Unstructured, len(entries))
+	for _, e := range entries {
+		data, err := fs.ReadFile(config.Content, path.Join(basesDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		u := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(data, &u.Object); err != nil {
+			return nil, err
+		}
+		result[u.GetName()] = u
+	}
+	return result, nil
+}
+
+// Drift returns the names of the versions whose definition (served, storage and schema) differs between the expected
+// and installed custom resource definitions. Versions missing from the installed definition are also included.
+func Drift(expected, installed *unstructured.Unstructured) []string {
+	expectedVersions := versions(expected)
+	installedVersions := versions(installed)
+
+	var drift []string
+	for name, ev := range expectedVersions {
+		if iv, ok := installedVersions[name]; !ok || !equivalent(ev, iv) {
+			drift = append(drift, name)
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// Patch replaces the versions of the installed custom resource definition with the expected versions
+func Patch(expected, installed *unstructured.Unstructured) error {
+	v, _, err := unstructured.NestedFieldCopy(expected.Object, "spec", "versions")
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(installed.Object, v, "spec", "versions")
+}
+
+// versions returns the relevant fields of each version of the custom resource definition, indexed by name
+func versions(crd *unstructured.Unstructured) map[string]interface{} {
+	result := make(map[string]interface{})
+	vs, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range vs {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		result[name] = map[string]interface{}{
+			"served":  m["served"],
+			"storage": m["storage"],
+			"schema":  m["schema"],
+		}
+	}
+	return result
+}
+
+// equivalent compares two values using their JSON representation, this eliminates differences in numeric types
+// between values parsed from YAML and values returned from the API server
+func equivalent(a, b interface{}) bool {
+	var na, nb interface{}
+	if data, err := json.Marshal(a); err != nil || json.Unmarshal(data, &na) != nil {
+		return false
+	}
+	if data, err := json.Marshal(b); err != nil || json.Unmarshal(data, &nb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDrift(t *testing.T) {
+	expected, err := Expected()
+	require.NoError(t, err)
+
+	trials, ok := expected["trials.redskyops.dev"]
+	require.True(t, ok)
+
+	cases := []struct {
+		desc   string
+		modify func(u *unstructured.Unstructured)
+		drift  []string
+	}{
+		{
+			desc:   "unchanged",
+			modify: func(u *unstructured.Unstructured) {},
+		},
+		{
+			desc: "missing property",
+			modify: func(u *unstructured.Unstructured) {
+				vs, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+				unstructured.RemoveNestedField(vs[1].(map[string]interface{}), "schema", "openAPIV3Schema", "properties", "spec")
+				_ = unstructured.SetNestedSlice(u.Object, vs, "spec", "versions")
+			},
+			drift: []string{"v1beta1"},
+		},
+		{
+			desc: "missing version",
+			modify: func(u *unstructured.Unstructured) {
+				vs, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+				_ = unstructured.SetNestedSlice(u.Object, vs[:1], "spec", "versions")
+			},
+			drift: []string{"v1beta1"},
+		},
+		{
+			desc: "storage version",
+			modify: func(u *unstructured.Unstructured) {
+				vs, _, _ := unstructured.NestedSlice(u.Object, "spec", "versions")
+				vs[0].(map[string]interface{})["storage"] = true
+				vs[1].(map[string]interface{})["storage"] = false
+				_ = unstructured.SetNestedSlice(u.Object, vs, "spec", "versions")
+			},
+			drift: []string{"v1alpha1", "v1beta1"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			installed := trials.DeepCopy()
+			c.modify(installed)
+			assert.Equal(t, c.drift, Drift(trials, installed))
+
+			if assert.NoError(t, Patch(trials, installed)) {
+				assert.Empty(t, Drift(trials, installed))
+			}
+		})
+	}
+}
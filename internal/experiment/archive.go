@@ -0,0 +1,115 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/version"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArchiveFinalizer is a finalizer that protects experiment archives from deletion, it is never removed by the
+// controller: once the retention policy allows it, the finalizer must be removed explicitly to delete the archive
+const ArchiveFinalizer = "archiveFinalizer.redskyops.dev"
+
+// ArchiveName returns the name of the archive for the supplied experiment. The name includes part of the experiment
+// UID so that re-creating an experiment with the same name does not collide with an earlier archive.
+func ArchiveName(exp *redskyv1beta1.Experiment) string {
+	if uid := string(exp.UID); len(uid) >= 8 {
+		return exp.Name + "-" + uid[:8]
+	}
+	return exp.Name
+}
+
+// NewArchive returns an immutable record of the supplied experiment and the outcomes of its trials.
+func NewArchive(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList, now metav1.Time) (*redskyv1beta1.ExperimentArchive, error) {
+	archive := &redskyv1beta1.ExperimentArchive{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       ArchiveName(exp),
+			Namespace:  exp.Namespace,
+			Labels:     map[string]string{redskyv1beta1.LabelExperiment: exp.Name},
+			Finalizers: []string{ArchiveFinalizer},
+		},
+		Spec: redskyv1beta1.ExperimentArchiveSpec{
+			Experiment:       *exp.Spec.DeepCopy(),
+			ExperimentStatus: *exp.Status.DeepCopy(),
+			Provenance: redskyv1beta1.ArchiveProvenance{
+				ExperimentName:              exp.Name,
+				ExperimentUID:               exp.UID,
+				ExperimentGeneration:        exp.Generation,
+				ExperimentCreationTimestamp: exp.CreationTimestamp,
+				ExperimentURL:               exp.Annotations[redskyv1beta1.AnnotationExperimentURL],
+				ArchiveTime:                 now,
+				ControllerVersion:           version.GetInfo().String(),
+			},
+		},
+	}
+
+	for i := range trialList.Items {
+		archive.Spec.Trials = append(archive.Spec.Trials, archivedTrial(&trialList.Items[i]))
+	}
+	sort.Slice(archive.Spec.Trials, func(i, j int) bool { return archive.Spec.Trials[i].Name < archive.Spec.Trials[j].Name })
+
+	digest, err := ArchiveDigest(&archive.Spec)
+	if err != nil {
+		return nil, err
+	}
+	archive.Spec.Provenance.Digest = digest
+
+	return archive, nil
+}
+
+// ArchiveDigest computes the digest of the archived experiment, status and trials; the provenance is not included.
+func ArchiveDigest(spec *redskyv1beta1.ExperimentArchiveSpec) (string, error) {
+	data, err := json.Marshal(&redskyv1beta1.ExperimentArchiveSpec{
+		Experiment:       spec.Experiment,
+		ExperimentStatus: spec.ExperimentStatus,
+		Trials:           spec.Trials,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func archivedTrial(t *redskyv1beta1.Trial) redskyv1beta1.ArchivedTrial {
+	at := redskyv1beta1.ArchivedTrial{
+		Name:           t.Name,
+		Phase:          t.Status.Phase,
+		Assignments:    t.Spec.Assignments,
+		Values:         t.Spec.Values,
+		StartTime:      t.Status.StartTime,
+		CompletionTime: t.Status.CompletionTime,
+	}
+
+	for _, c := range t.Status.Conditions {
+		if c.Type == redskyv1beta1.TrialFailed && c.Status == corev1.ConditionTrue {
+			at.FailureReason = c.Reason
+			at.FailureMessage = c.Message
+		}
+	}
+
+	return *at.DeepCopy()
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewArchive(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-exp",
+			Namespace: "default",
+			UID:       "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0",
+		},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Metrics: []redskyv1beta1.Metric{{Name: "cost", Minimize: true}},
+		},
+		Status: redskyv1beta1.ExperimentStatus{Phase: PhaseCompleted},
+	}
+	trialList := &redskyv1beta1.TrialList{
+		Items: []redskyv1beta1.Trial{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-exp-002"},
+				Status: redskyv1beta1.TrialStatus{
+					Phase: "Failed",
+					Conditions: []redskyv1beta1.TrialCondition{
+						{Type: redskyv1beta1.TrialFailed, Status: corev1.ConditionTrue, Reason: "FailFast", Message: "too slow"},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-exp-001"},
+				Spec:       redskyv1beta1.TrialSpec{Values: []redskyv1beta1.Value{{Name: "cost", Value: "1.5"}}},
+				Status:     redskyv1beta1.TrialStatus{Phase: "Completed"},
+			},
+		},
+	}
+
+	archive, err := NewArchive(exp, trialList, metav1.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-exp-0f1e2d3c", archive.Name)
+	assert.Equal(t, []string{ArchiveFinalizer}, archive.Finalizers)
+	assert.Equal(t, "my-exp", archive.Spec.Provenance.ExperimentName)
+	assert.Equal(t, PhaseCompleted, archive.Spec.ExperimentStatus.Phase)
+	if assert.Len(t, archive.Spec.Trials, 2) {
+		assert.Equal(t, "my-exp-001", archive.Spec.Trials[0].Name)
+		assert.Equal(t, "1.5", archive.Spec.Trials[0].Values[0].Value)
+		assert.Equal(t, "FailFast", archive.Spec.Trials[1].FailureReason)
+	}
+
+	// The digest must detect modifications to the archived results
+	digest, err := ArchiveDigest(&archive.Spec)
+	require.NoError(t, err)
+	assert.Equal(t, archive.Spec.Provenance.Digest, digest)
+
+	archive.Spec.Trials[0].Values[0].Value = "0.5"
+	digest, err = ArchiveDigest(&archive.Spec)
+	require.NoError(t, err)
+	assert.NotEqual(t, archive.Spec.Provenance.Digest, digest)
+}
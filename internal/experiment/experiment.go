@@ -17,6 +17,10 @@ limitations under the License.
 package experiment
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/controller"
 	"github.com/thestormforge/optimize-controller/internal/trial"
@@ -37,10 +41,14 @@ const (
 	PhasePaused = "Paused"
 	// PhaseEmpty indicates there is no record of trials being run in the cluster
 	PhaseEmpty = "Never run" // TODO This is misleading, it could be that we already deleted the trials that ran
+	// PhaseWaiting indicates that the experiment is waiting for the experiments it depends on to complete
+	PhaseWaiting = "Waiting"
 	// PhaseIdle indicates that the experiment is waiting for trials to be manually created
 	PhaseIdle = "Idle"
 	// PhaseRunning indicates that there are actively running trials for the experiment
 	PhaseRunning = "Running"
+	// PhaseDraining indicates that there are actively running trials but new trial suggestions are paused
+	PhaseDraining = "Draining"
 	// PhaseCompleted indicates that the experiment has exhausted it's trial budget and is no longer expecting new trials
 	PhaseCompleted = "Completed"
 	// PhaseFailed indicates that the experiment has failed
@@ -52,14 +60,20 @@ const (
 // UpdateStatus will ensure the experiment's status matches what is in the supplied trial list; returns true only if
 // changes were necessary
 func UpdateStatus(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) bool {
-	// Count the active trials
-	activeTrials := int32(0)
+	// Count the trials and collect the best values
+	activeTrials, completedTrials, failedTrials := int32(0), int32(0), int32(0)
 	for i := range trialList.Items {
 		t := &trialList.Items[i]
-		if trial.IsActive(t) && !trial.IsAbandoned(t) {
+		switch {
+		case trial.IsActive(t) && !trial.IsAbandoned(t):
 			activeTrials++
+		case trial.CheckCondition(&t.Status, redskyv1beta1.TrialFailed, corev1.ConditionTrue):
+			failedTrials++
+		case trial.CheckCondition(&t.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue):
+			completedTrials++
 		}
 	}
+	bestValues := bestValues(exp, trialList)
 
 	// Determine the phase
 	phase := summarize(exp, activeTrials, len(trialList.Items))
@@ -74,6 +88,18 @@ func UpdateStatus(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialL
 		exp.Status.ActiveTrials = activeTrials
 		dirty = true
 	}
+	if exp.Status.CompletedTrials != completedTrials {
+		exp.Status.CompletedTrials = completedTrials
+		dirty = true
+	}
+	if exp.Status.FailedTrials != failedTrials {
+		exp.Status.FailedTrials = failedTrials
+		dirty = true
+	}
+	if exp.Status.BestValues != bestValues {
+		exp.Status.BestValues = bestValues
+		dirty = true
+	}
 
 	// If we made a change, record this in the metric gauges
 	if dirty {
@@ -84,6 +110,44 @@ func UpdateStatus(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialL
 	return false
 }
 
+// bestValues returns a string representation of the best observed value for each optimized metric
+// across the successfully completed trials.
+func bestValues(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) string {
+	values := make([]string, 0, len(exp.Spec.Metrics))
+	for _, m := range exp.Spec.Metrics {
+		if m.Optimize != nil && !*m.Optimize {
+			continue
+		}
+
+		var best string
+		var bestValue float64
+		for i := range trialList.Items {
+			t := &trialList.Items[i]
+			if !trial.CheckCondition(&t.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue) {
+				continue
+			}
+
+			for _, v := range t.Spec.Values {
+				if v.Name != m.Name || v.AttemptsRemaining != 0 {
+					continue
+				}
+				fv, err := strconv.ParseFloat(v.Value, 64)
+				if err != nil {
+					continue
+				}
+				if best == "" || (m.Minimize && fv < bestValue) || (!m.Minimize && fv > bestValue) {
+					best, bestValue = v.Value, fv
+				}
+			}
+		}
+
+		if best != "" {
+			values = append(values, fmt.Sprintf("%s=%s", m.Name, best))
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
 func summarize(exp *redskyv1beta1.Experiment, activeTrials int32, totalTrials int) string {
 	if !exp.GetDeletionTimestamp().IsZero() {
 		return PhaseDeleted
@@ -103,10 +167,17 @@ func summarize(exp *redskyv1beta1.Experiment, activeTrials int32, totalTrials in
 	}
 
 	if activeTrials > 0 {
+		if SuggestionsPaused(exp) {
+			return PhaseDraining
+		}
 		return PhaseRunning
 	}
 
-	if exp.Replicas() == 0 {
+	if IsWaiting(exp) {
+		return PhaseWaiting
+	}
+
+	if exp.Replicas() == 0 || SuggestionsPaused(exp) {
 		return PhasePaused
 	}
 
@@ -120,6 +191,40 @@ func summarize(exp *redskyv1beta1.Experiment, activeTrials int32, totalTrials in
 	return PhaseIdle
 }
 
+// SuggestionsPaused checks to see if new trial suggestions should not be requested for the experiment.
+func SuggestionsPaused(exp *redskyv1beta1.Experiment) bool {
+	return strings.ToLower(exp.GetAnnotations()[redskyv1beta1.AnnotationPauseSuggestions]) == "true"
+}
+
+// IsWaiting checks to see if the experiment is still waiting for the experiments it depends on. An experiment with
+// dependencies is considered waiting until the condition is explicitly cleared.
+func IsWaiting(exp *redskyv1beta1.Experiment) bool {
+	if len(exp.Spec.DependsOn) == 0 {
+		return false
+	}
+	return !CheckCondition(&exp.Status, redskyv1beta1.ExperimentWaiting, corev1.ConditionFalse)
+}
+
+// IsDrifted checks to see if the experiment definition no longer matches the definition on the server.
+func IsDrifted(exp *redskyv1beta1.Experiment) bool {
+	return CheckCondition(&exp.Status, redskyv1beta1.ExperimentDrifted, corev1.ConditionTrue)
+}
+
+// WaitingFor returns the name of the first dependency that has not completed, or an empty string if all of the
+// dependencies have completed. The lookup function should return nil if the named experiment does not exist.
+func WaitingFor(exp *redskyv1beta1.Experiment, lookup func(name string) (*redskyv1beta1.Experiment, error)) (string, error) {
+	for _, name := range exp.Spec.DependsOn {
+		dep, err := lookup(name)
+		if err != nil {
+			return "", err
+		}
+		if dep == nil || !CheckCondition(&dep.Status, redskyv1beta1.ExperimentComplete, corev1.ConditionTrue) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
 func IsFinished(exp *redskyv1beta1.Experiment) bool {
 	for _, c := range exp.Status.Conditions {
 		if c.Status == corev1.ConditionTrue {
@@ -152,6 +257,11 @@ func ApplyCondition(status *redskyv1beta1.ExperimentStatus, conditionType redsky
 				status.Conditions[i] = newCondition
 			} else {
 				status.Conditions[i].LastProbeTime = *time
+				// Repeated server synchronization failures should reflect the most recent error
+				if conditionType == redskyv1beta1.ExperimentServerSynced && message != "" {
+					status.Conditions[i].Reason = reason
+					status.Conditions[i].Message = message
+				}
 			}
 			return
 		}
@@ -159,3 +269,15 @@ func ApplyCondition(status *redskyv1beta1.ExperimentStatus, conditionType redsky
 
 	status.Conditions = append(status.Conditions, newCondition)
 }
+
+// CheckCondition checks to see if a condition has a specific status
+func CheckCondition(status *redskyv1beta1.ExperimentStatus, conditionType redskyv1beta1.ExperimentConditionType, conditionStatus corev1.ConditionStatus) bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return status.Conditions[i].Status == conditionStatus
+		}
+	}
+
+	// If the condition we are looking for *is* unknown, then we did "find" it
+	return conditionStatus == corev1.ConditionUnknown
+}
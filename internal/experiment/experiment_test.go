@@ -110,6 +110,37 @@ func TestSummarize(t *testing.T) {
 			expectedPhase: PhaseRunning,
 			activeTrials:  1,
 		},
+		{
+			desc: "suggestions paused no active trials",
+			experiment: &redsky.Experiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						redsky.AnnotationPauseSuggestions: "true",
+					},
+				},
+				Spec: redsky.ExperimentSpec{
+					Replicas: &oneReplica,
+				},
+			},
+			expectedPhase: PhasePaused,
+			totalTrials:   1,
+		},
+		{
+			desc: "suggestions paused active trials",
+			experiment: &redsky.Experiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						redsky.AnnotationPauseSuggestions: "true",
+					},
+				},
+				Spec: redsky.ExperimentSpec{
+					Replicas: &oneReplica,
+				},
+			},
+			expectedPhase: PhaseDraining,
+			activeTrials:  1,
+			totalTrials:   1,
+		},
 		{
 			desc: "paused budget done",
 			experiment: &redsky.Experiment{
@@ -179,6 +210,32 @@ func TestSummarize(t *testing.T) {
 			},
 			expectedPhase: PhaseFailed,
 		},
+		{
+			desc: "waiting",
+			experiment: &redsky.Experiment{
+				Spec: redsky.ExperimentSpec{
+					DependsOn: []string{"database"},
+				},
+			},
+			expectedPhase: PhaseWaiting,
+		},
+		{
+			desc: "waiting cleared",
+			experiment: &redsky.Experiment{
+				Spec: redsky.ExperimentSpec{
+					DependsOn: []string{"database"},
+				},
+				Status: redsky.ExperimentStatus{
+					Conditions: []redsky.ExperimentCondition{
+						{
+							Type:   redsky.ExperimentWaiting,
+							Status: corev1.ConditionFalse,
+						},
+					},
+				},
+			},
+			expectedPhase: PhaseEmpty,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -277,6 +334,34 @@ func TestApplyCondition(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:            "update server synced message",
+			conditionType:   redsky.ExperimentServerSynced,
+			conditionStatus: corev1.ConditionFalse,
+			reason:          "Testing",
+			message:         "Test Test",
+			time:            &now,
+			initialConditions: []redsky.ExperimentCondition{
+				{
+					Type:               redsky.ExperimentServerSynced,
+					Status:             corev1.ConditionFalse,
+					LastProbeTime:      then,
+					LastTransitionTime: then,
+					Reason:             "Foo",
+					Message:            "Bar",
+				},
+			},
+			expectedConditions: []redsky.ExperimentCondition{
+				{
+					Type:               redsky.ExperimentServerSynced,
+					Status:             corev1.ConditionFalse,
+					LastProbeTime:      now,
+					LastTransitionTime: then,
+					Reason:             "Testing",
+					Message:            "Test Test",
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -287,3 +372,89 @@ func TestApplyCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestBestValues(t *testing.T) {
+	optimize := false
+	exp := &redsky.Experiment{
+		Spec: redsky.ExperimentSpec{
+			Metrics: []redsky.Metric{
+				{Name: "cost", Minimize: true},
+				{Name: "throughput"},
+				{Name: "ignored", Optimize: &optimize},
+			},
+		},
+	}
+
+	complete := redsky.TrialStatus{Conditions: []redsky.TrialCondition{{Type: redsky.TrialComplete, Status: corev1.ConditionTrue}}}
+	failed := redsky.TrialStatus{Conditions: []redsky.TrialCondition{{Type: redsky.TrialFailed, Status: corev1.ConditionTrue}}}
+	trialList := &redsky.TrialList{
+		Items: []redsky.Trial{
+			{
+				Spec:   redsky.TrialSpec{Values: []redsky.Value{{Name: "cost", Value: "10"}, {Name: "throughput", Value: "100"}, {Name: "ignored", Value: "1"}}},
+				Status: complete,
+			},
+			{
+				Spec:   redsky.TrialSpec{Values: []redsky.Value{{Name: "cost", Value: "5"}, {Name: "throughput", Value: "50"}}},
+				Status: complete,
+			},
+			{
+				Spec:   redsky.TrialSpec{Values: []redsky.Value{{Name: "cost", Value: "1"}, {Name: "throughput", Value: "1000"}}},
+				Status: failed,
+			},
+		},
+	}
+
+	assert.Equal(t, "cost=5, throughput=100", bestValues(exp, trialList))
+	assert.True(t, UpdateStatus(exp, trialList))
+	assert.Equal(t, int32(2), exp.Status.CompletedTrials)
+	assert.Equal(t, int32(1), exp.Status.FailedTrials)
+}
+
+func TestWaitingFor(t *testing.T) {
+	complete := redsky.ExperimentStatus{Conditions: []redsky.ExperimentCondition{{Type: redsky.ExperimentComplete, Status: corev1.ConditionTrue}}}
+	failed := redsky.ExperimentStatus{Conditions: []redsky.ExperimentCondition{{Type: redsky.ExperimentFailed, Status: corev1.ConditionTrue}}}
+	experiments := map[string]*redsky.Experiment{
+		"database": {Status: complete},
+		"cache":    {Status: failed},
+		"queue":    {},
+	}
+	lookup := func(name string) (*redsky.Experiment, error) { return experiments[name], nil }
+
+	cases := []struct {
+		desc      string
+		dependsOn []string
+		expected  string
+	}{
+		{
+			desc: "no dependencies",
+		},
+		{
+			desc:      "complete",
+			dependsOn: []string{"database"},
+		},
+		{
+			desc:      "running",
+			dependsOn: []string{"database", "queue"},
+			expected:  "queue",
+		},
+		{
+			desc:      "failed",
+			dependsOn: []string{"cache", "queue"},
+			expected:  "cache",
+		},
+		{
+			desc:      "missing",
+			dependsOn: []string{"search"},
+			expected:  "search",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			exp := &redsky.Experiment{Spec: redsky.ExperimentSpec{DependsOn: c.dependsOn}}
+			actual, err := WaitingFor(exp, lookup)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
@@ -17,8 +17,13 @@ limitations under the License.
 package generation
 
 import (
+	"sort"
+
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/scan"
+	"github.com/thestormforge/optimize-controller/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -27,12 +32,36 @@ type ApplicationSelector struct {
 	Application *redskyappsv1alpha1.Application
 	Scenario    *redskyappsv1alpha1.Scenario
 	Objective   *redskyappsv1alpha1.Objective
+
+	// The namespaces of the application resources
+	namespaces []string
+	// The existing constraints on the namespace the trials run in
+	resourceQuotas []corev1.ResourceQuota
+	limitRanges    []corev1.LimitRange
 }
 
 var _ scan.Selector = &ApplicationSelector{}
 
 // Select only returns an empty node to ensure that map will be called.
-func (s *ApplicationSelector) Select([]*yaml.RNode) ([]*yaml.RNode, error) {
+func (s *ApplicationSelector) Select(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	// Record the namespaces the application resources span
+	s.namespaces = nil
+	s.resourceQuotas, s.limitRanges = nil, nil
+	for _, node := range nodes {
+		ns := node.GetNamespace()
+		if ns == "" && s.Application != nil {
+			ns = s.Application.Namespace
+		}
+		if ns != "" {
+			s.namespaces = appendMissing(s.namespaces, ns)
+		}
+
+		if err := s.saveTrialNamespaceConstraint(ns, node); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(s.namespaces)
+
 	// In order to evaluate side effects on the application state, we CANNOT introduce the serialized
 	// application into the resource node stream and process it from there. We still need to
 	// return exactly one node here to make sure `Map` gets called.
@@ -43,6 +72,8 @@ func (s *ApplicationSelector) Select([]*yaml.RNode) ([]*yaml.RNode, error) {
 func (s *ApplicationSelector) Map(*yaml.RNode, yaml.ResourceMeta) ([]interface{}, error) {
 	var result []interface{}
 
+	result = append(result, &ApplicationMetadataSource{Application: s.Application})
+
 	// NOTE: We iterate by index and obtain pointers because some of these evaluate for side effects
 
 	if s.Scenario != nil {
@@ -54,13 +85,14 @@ func (s *ApplicationSelector) Map(*yaml.RNode, yaml.ResourceMeta) ([]interface{}
 		case s.Scenario.Custom != nil:
 			result = append(result, &CustomSource{Scenario: s.Scenario, Objective: s.Objective, Application: s.Application})
 		}
+		result = append(result, &PlacementSource{Scenario: s.Scenario})
 	}
 
 	if s.Objective != nil {
 		for i := range s.Objective.Goals {
 			switch {
 			case s.Objective.Goals[i].Requests != nil:
-				result = append(result, &RequestsMetricsSource{Goal: &s.Objective.Goals[i]})
+				result = append(result, &RequestsMetricsSource{Goal: &s.Objective.Goals[i], Namespaces: s.namespaces})
 			case s.Objective.Goals[i].Duration != nil:
 				result = append(result, &DurationMetricsSource{Goal: &s.Objective.Goals[i]})
 			case s.Objective.Goals[i].Prometheus != nil:
@@ -71,12 +103,78 @@ func (s *ApplicationSelector) Map(*yaml.RNode, yaml.ResourceMeta) ([]interface{}
 		}
 	}
 
-	result = append(result, &BuiltInPrometheus{
+	result = append(result, &CredentialsSource{Application: s.Application})
+
+	builtInPrometheus := &BuiltInPrometheus{
 		SetupTaskName:          "monitoring",
 		ClusterRoleName:        "redsky-prometheus",
 		ServiceAccountName:     "redsky-setup",
 		ClusterRoleBindingName: "redsky-setup-prometheus",
-	})
+	}
+	if s.Application != nil {
+		builtInPrometheus.Prometheus = s.Application.Prometheus
+		builtInPrometheus.ImagePullSecrets = s.Application.ImagePullSecrets
+	}
+	if s.Scenario != nil && s.Scenario.Custom != nil {
+		builtInPrometheus.PushGateway = s.Scenario.Custom.UsePushGateway
+	}
+	result = append(result, builtInPrometheus)
+
+	result = append(result, &ScenarioResourcesSource{Scenario: s.Scenario})
+
+	result = append(result, &TrialNamespaceSource{Application: s.Application, ResourceQuotas: s.resourceQuotas, LimitRanges: s.limitRanges})
+
+	result = append(result, &ImagesSource{Application: s.Application})
 
 	return result, nil
 }
+
+// saveTrialNamespaceConstraint records existing resource quotas and limit ranges in the namespace the trials run in.
+func (s *ApplicationSelector) saveTrialNamespaceConstraint(namespace string, node *yaml.RNode) error {
+	if s.Application == nil || namespace != s.Application.Namespace {
+		return nil
+	}
+
+	m, err := node.GetMeta()
+	if err != nil {
+		return err
+	}
+	if m.APIVersion != "v1" {
+		return nil
+	}
+
+	switch m.Kind {
+	case "ResourceQuota":
+		rq := corev1.ResourceQuota{}
+		if err := sfio.DecodeYAMLToJSON(node, &rq); err != nil {
+			return err
+		}
+		s.resourceQuotas = append(s.resourceQuotas, rq)
+	case "LimitRange":
+		lr := corev1.LimitRange{}
+		if err := sfio.DecodeYAMLToJSON(node, &lr); err != nil {
+			return err
+		}
+		s.limitRanges = append(s.limitRanges, lr)
+	}
+	return nil
+}
+
+// ApplicationMetadataSource copies the descriptive information from the application to the experiment.
+type ApplicationMetadataSource struct {
+	Application *redskyappsv1alpha1.Application
+}
+
+var _ ExperimentSource = &ApplicationMetadataSource{}
+
+// Update sets the description, owner and ticket URL of the experiment.
+func (s *ApplicationMetadataSource) Update(exp *redskyv1beta1.Experiment) error {
+	if s.Application == nil {
+		return nil
+	}
+
+	exp.Spec.Description = s.Application.Description
+	exp.Spec.Owner = s.Application.Owner
+	exp.Spec.TicketURL = s.Application.TicketURL
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/thestormforge/optimize-controller/internal/scan"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// LiveBaselineFilter overwrites the replica counts and container resources of workloads using the
+// values currently deployed to the cluster. This ensures the baselines of the generated parameters
+// reflect what is actually running, even when the manifests are out of date.
+type LiveBaselineFilter struct {
+	// Flag indicating the live cluster state should not be used.
+	Disabled bool
+	// The namespace to use for resources that do not specify one.
+	Namespace string
+	// The function used to execute kubectl commands.
+	Kubectl func(cmd *exec.Cmd) ([]byte, error)
+}
+
+var _ kio.Filter = &LiveBaselineFilter{}
+
+// Filter updates the supplied workloads using the live cluster state. Resources which cannot be
+// found in the cluster are left unchanged.
+func (f *LiveBaselineFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	if f.Disabled || f.Kubectl == nil {
+		return nodes, nil
+	}
+
+	workloads := &scan.GenericSelector{
+		Group: "apps|extensions",
+		Kind:  "Deployment|StatefulSet|DaemonSet|ReplicaSet",
+	}
+
+	selected, err := workloads.Select(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range selected {
+		meta, err := node.GetMeta()
+		if err != nil {
+			return nil, err
+		}
+
+		live := f.get(meta)
+		if live == nil {
+			continue
+		}
+
+		if err := updateBaseline(node, live, meta.Kind); err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+// get returns the live state of the described resource, or nil if it could not be fetched.
+func (f *LiveBaselineFilter) get(meta yaml.ResourceMeta) *yaml.RNode {
+	resourceType := strings.ToLower(meta.Kind)
+	if group := strings.Split(meta.APIVersion, "/"); len(group) > 1 {
+		resourceType += "." + group[0]
+	}
+
+	args := []string{"get", resourceType, meta.Name, "--output", "yaml"}
+	if ns := meta.Namespace; ns != "" {
+		args = append(args, "--namespace", ns)
+	} else if f.Namespace != "" {
+		args = append(args, "--namespace", f.Namespace)
+	}
+
+	// Failing to get the live state is not an error, we just fall back to the manifest
+	data, err := f.Kubectl(exec.Command("kubectl", args...))
+	if err != nil {
+		return nil
+	}
+
+	live, err := yaml.Parse(string(data))
+	if err != nil {
+		return nil
+	}
+	return live
+}
+
+// updateBaseline copies the replica count and container resources from a live resource.
+func updateBaseline(node, live *yaml.RNode, kind string) error {
+	if replicas, err := live.Pipe(yaml.Lookup("spec", "replicas")); err != nil {
+		return err
+	} else if replicas != nil {
+		if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec"), yaml.SetField("replicas", replicas)); err != nil {
+			return err
+		}
+	}
+
+	podSpec := strings.Split(podSpecPath(kind), "/")
+	for _, field := range []string{"initContainers", "containers"} {
+		path := make([]string, 0, len(podSpec)+2)
+		path = append(path, podSpec...)
+		path = append(path, field)
+
+		containers, err := live.Pipe(yaml.Lookup(path...))
+		if err != nil {
+			return err
+		}
+		if containers == nil {
+			continue
+		}
+
+		elements, err := containers.Elements()
+		if err != nil {
+			return err
+		}
+
+		for _, c := range elements {
+			name, resources := c.Field("name"), c.Field("resources")
+			if name == nil || resources == nil {
+				continue
+			}
+
+			if err := node.PipeE(
+				yaml.Lookup(append(path, "[name="+name.Value.YNode().Value+"]")...),
+				yaml.SetField("resources", resources.Value),
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thestormforge/konjure/pkg/filters"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/scan"
+	"github.com/thestormforge/optimize-controller/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ConfigMapSelector scans for keys of a ConfigMap and the workloads which use it.
+type ConfigMapSelector struct {
+	scan.GenericSelector
+	// Name of the ConfigMap.
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Keys of the ConfigMap to optimize.
+	Keys []redskyappsv1alpha1.ConfigMapKey `json:"keys,omitempty"`
+	// Regular expression matching the group of workloads to restart when the ConfigMap changes.
+	WorkloadGroup string `json:"workloadGroup,omitempty"`
+	// Regular expression matching the kind of workloads to restart when the ConfigMap changes.
+	WorkloadKind string `json:"workloadKind,omitempty"`
+
+	// The keys found in each matching ConfigMap, indexed by namespace.
+	found map[string]*configMapKeys
+}
+
+var _ scan.Selector = &ConfigMapSelector{}
+
+// Default applies default values to the selector.
+func (s *ConfigMapSelector) Default() {
+	if s.Kind == "" {
+		s.Version = "v1"
+		s.Kind = "ConfigMap"
+		s.Name = regexp.QuoteMeta(s.ConfigMapName)
+	}
+
+	if s.WorkloadKind == "" {
+		s.WorkloadGroup = "apps|extensions|batch"
+		s.WorkloadKind = "Deployment|StatefulSet|DaemonSet|ReplicaSet|CronJob"
+	}
+}
+
+// Select matches the ConfigMaps followed by any workloads that might be using them.
+func (s *ConfigMapSelector) Select(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	// The ConfigMaps must be first so we know which keys were found by the time we see the workloads
+	result, err := s.GenericSelector.Select(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	workloadSelector := &filters.ResourceMetaFilter{
+		Group:         s.WorkloadGroup,
+		Kind:          s.WorkloadKind,
+		LabelSelector: s.LabelSelector,
+	}
+	workloadNodes, err := workloadSelector.Filter(nodes)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, workloadNodes...)
+
+	return result, nil
+}
+
+// Map inspects the supplied ConfigMap for the configured keys or the supplied workload
+// for references to a ConfigMap that was already inspected.
+func (s *ConfigMapSelector) Map(node *yaml.RNode, meta yaml.ResourceMeta) ([]interface{}, error) {
+	if meta.APIVersion == "v1" && meta.Kind == "ConfigMap" {
+		return s.mapConfigMap(node, meta)
+	}
+
+	cm := s.found[meta.Namespace]
+	if cm == nil {
+		return nil, nil
+	}
+
+	podSpec := corev1.PodSpec{}
+	if podSpecNode, err := node.Pipe(yaml.Lookup(strings.Split(podSpecPath(meta.Kind), "/")...)); err != nil {
+		return nil, err
+	} else if podSpecNode == nil {
+		return nil, nil
+	} else if err := sfio.DecodeYAMLToJSON(podSpecNode, &podSpec); err != nil {
+		return nil, err
+	}
+
+	if !usesConfigMap(&podSpec, cm.meta.Name) {
+		return nil, nil
+	}
+
+	return []interface{}{&configMapRollout{
+		pnode: pnode{
+			meta:      meta,
+			fieldPath: strings.Split(strings.TrimSuffix(podSpecPath(meta.Kind), "/spec"), "/"),
+		},
+		configMap: cm,
+	}}, nil
+}
+
+// mapConfigMap returns a parameter for each of the configured keys present in the ConfigMap.
+func (s *ConfigMapSelector) mapConfigMap(node *yaml.RNode, meta yaml.ResourceMeta) ([]interface{}, error) {
+	var result []interface{}
+	cm := &configMapKeys{meta: meta}
+	for _, key := range s.Keys {
+		value, err := node.Pipe(yaml.Lookup("data", key.Key))
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+
+		cm.keys = append(cm.keys, key.Key)
+		result = append(result, &configMapParameter{
+			pnode: pnode{
+				meta:      meta,
+				fieldPath: []string{"data", key.Key},
+				value:     value.YNode(),
+			},
+			key: key,
+		})
+	}
+
+	if len(cm.keys) > 0 {
+		if s.found == nil {
+			s.found = make(map[string]*configMapKeys)
+		}
+		s.found[meta.Namespace] = cm
+	}
+
+	return result, nil
+}
+
+// usesConfigMap checks to see if the pod specification references the named ConfigMap.
+func usesConfigMap(spec *corev1.PodSpec, name string) bool {
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil && v.ConfigMap.Name == name {
+			return true
+		}
+		if v.Projected != nil {
+			for _, src := range v.Projected.Sources {
+				if src.ConfigMap != nil && src.ConfigMap.Name == name {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			for _, src := range c.EnvFrom {
+				if src.ConfigMapRef != nil && src.ConfigMapRef.Name == name {
+					return true
+				}
+			}
+			for _, env := range c.Env {
+				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// configMapKeys records the keys found in a ConfigMap.
+type configMapKeys struct {
+	meta yaml.ResourceMeta
+	keys []string
+}
+
+// parameterName returns the name of the parameter used for a key of the ConfigMap.
+func (cm *configMapKeys) parameterName(name ParameterNamer, key string) string {
+	return name(cm.meta, []string{"data", key}, key)
+}
+
+// configMapParameter is used to record the position of a ConfigMap key found by
+// the selector during scanning.
+type configMapParameter struct {
+	pnode
+	key redskyappsv1alpha1.ConfigMapKey
+}
+
+var _ PatchSource = &configMapParameter{}
+var _ ParameterSource = &configMapParameter{}
+
+// Patch produces a YAML filter for replacing the value of the ConfigMap key.
+func (p *configMapParameter) Patch(name ParameterNamer) (yaml.Filter, error) {
+	value := fmt.Sprintf("{{ .Values.%s }}", name(p.meta, p.fieldPath, p.key.Key))
+	if len(p.key.Values) == 0 {
+		value += p.key.Suffix
+	}
+
+	return yaml.Tee(
+		&yaml.PathGetter{Path: p.fieldPath[:len(p.fieldPath)-1], Create: yaml.MappingNode},
+		yaml.SetField(p.key.Key, yaml.NewStringRNode(value)),
+	), nil
+}
+
+// Parameters lists the categorical or numeric parameter used by the patch.
+func (p *configMapParameter) Parameters(name ParameterNamer) ([]redskyv1beta1.Parameter, error) {
+	// The workloads using the ConfigMap are annotated with the parameter values (see `configMapRollout`)
+	// so changing the value always triggers a rollout
+	param := redskyv1beta1.Parameter{
+		Name:          name(p.meta, p.fieldPath, p.key.Key),
+		RestartPolicy: redskyv1beta1.RestartPolicyAlways,
+	}
+
+	if len(p.key.Values) > 0 {
+		value := p.value.Value
+		if value == "" {
+			value = p.key.Values[0]
+		}
+		param.Baseline = &intstr.IntOrString{Type: intstr.String, StrVal: value}
+		param.Values = appendMissing(p.key.Values, value)
+		return []redskyv1beta1.Parameter{param}, nil
+	}
+
+	param.Min, param.Max = p.key.Min, p.key.Max
+	if baseline, err := strconv.Atoi(strings.TrimSuffix(p.value.Value, p.key.Suffix)); err == nil {
+		param.Baseline = &intstr.IntOrString{Type: intstr.Int, IntVal: int32(baseline)}
+		if param.Min == 0 && param.Max == 0 {
+			param.Min = int32(baseline / 2)
+			param.Max = int32(baseline * 2)
+		}
+
+		// Make sure the baseline is always in range
+		if param.Baseline.IntVal < param.Min {
+			param.Min = param.Baseline.IntVal
+		}
+		if param.Baseline.IntVal > param.Max {
+			param.Max = param.Baseline.IntVal
+		}
+	}
+
+	if param.Min >= param.Max {
+		return nil, fmt.Errorf("unable to determine range for key %q of ConfigMap %q, min and max are required", p.key.Key, p.meta.Name)
+	}
+
+	return []redskyv1beta1.Parameter{param}, nil
+}
+
+// configMapRollout is used to record the position of a pod template which
+// references a ConfigMap found by the selector during scanning.
+type configMapRollout struct {
+	pnode
+	configMap *configMapKeys
+}
+
+var _ PatchSource = &configMapRollout{}
+
+// Patch produces a YAML filter for annotating the pod template with the values
+// of the ConfigMap parameters, forcing a rollout whenever they change.
+func (p *configMapRollout) Patch(name ParameterNamer) (yaml.Filter, error) {
+	values := make([]string, 0, len(p.configMap.keys))
+	for _, key := range p.configMap.keys {
+		values = append(values, fmt.Sprintf("{{ .Values.%s }}", p.configMap.parameterName(name, key)))
+	}
+
+	path := make([]string, 0, len(p.fieldPath)+2)
+	path = append(path, p.fieldPath...)
+	path = append(path, "metadata", "annotations")
+
+	return yaml.Tee(
+		&yaml.PathGetter{Path: path, Create: yaml.MappingNode},
+		yaml.SetField(redskyappsv1alpha1.AnnotationConfigMapPrefix+p.configMap.meta.Name, yaml.NewStringRNode(strings.Join(values, ","))),
+	), nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestConfigMapParameter(t *testing.T) {
+	cases := []struct {
+		desc string
+		configMapParameter
+		expectedParameters []redskyv1beta1.Parameter
+		expectedPatch      string
+	}{
+		{
+			desc: "numeric suffix",
+			configMapParameter: configMapParameter{
+				pnode: pnode{
+					fieldPath: []string{"data", "shared_buffers"},
+					value:     yaml.NewScalarRNode("128MB").YNode(),
+				},
+				key: redskyappsv1alpha1.ConfigMapKey{Key: "shared_buffers", Suffix: "MB"},
+			},
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:          "shared_buffers",
+					Baseline:      newInt(128),
+					Min:           64,
+					Max:           256,
+					RestartPolicy: redskyv1beta1.RestartPolicyAlways,
+				},
+			},
+			expectedPatch: unindent(`
+              data:
+                shared_buffers: "{{ .Values.shared_buffers }}MB"`),
+		},
+		{
+			desc: "categorical",
+			configMapParameter: configMapParameter{
+				pnode: pnode{
+					fieldPath: []string{"data", "worker_processes"},
+					value:     yaml.NewScalarRNode("auto").YNode(),
+				},
+				key: redskyappsv1alpha1.ConfigMapKey{Key: "worker_processes", Values: []string{"1", "2", "4"}},
+			},
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:          "worker_processes",
+					Baseline:      &intstr.IntOrString{Type: intstr.String, StrVal: "auto"},
+					Values:        []string{"1", "2", "4", "auto"},
+					RestartPolicy: redskyv1beta1.RestartPolicyAlways,
+				},
+			},
+			expectedPatch: unindent(`
+              data:
+                worker_processes: "{{ .Values.worker_processes }}"`),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			t.Run("parameters", func(t *testing.T) {
+				parameters, err := c.configMapParameter.Parameters(ignoreMetaForName)
+				if assert.NoError(t, err) {
+					assert.Equal(t, c.expectedParameters, parameters)
+				}
+			})
+
+			t.Run("patch", func(t *testing.T) {
+				filter, err := c.configMapParameter.Patch(ignoreMetaForName)
+				if assert.NoError(t, err) {
+					patch, err := yaml.NewMapRNode(nil).Pipe(filter)
+					if assert.NoError(t, err) {
+						actual, err := yaml.String(patch.YNode())
+						require.NoError(t, err)
+						assert.YAMLEq(t, c.expectedPatch, actual)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestUsesConfigMap(t *testing.T) {
+	spec := &corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "test",
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-config"}},
+			}},
+		}},
+		Volumes: []corev1.Volume{{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "file-config"}},
+			},
+		}},
+	}
+
+	assert.True(t, usesConfigMap(spec, "env-config"))
+	assert.True(t, usesConfigMap(spec, "file-config"))
+	assert.False(t, usesConfigMap(spec, "other-config"))
+}
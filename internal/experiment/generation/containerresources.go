@@ -18,8 +18,12 @@ package generation
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/thestormforge/konjure/pkg/filters"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/scan"
 	"github.com/thestormforge/optimize-controller/internal/sfio"
@@ -36,12 +40,22 @@ type ContainerResourcesSelector struct {
 	ContainerName string `json:"containerName,omitempty"`
 	// Path to the resource requirements.
 	Path string `json:"path,omitempty"`
-	// Names of the resources to select, defaults to ["cpu", "memory"].
+	// Names of the resources to select, defaults to ["cpu", "memory"]. Extended resources (e.g. "nvidia.com/gpu")
+	// are optimized in whole units.
 	Resources []corev1.ResourceName `json:"resources,omitempty"`
+	// Resource requirements to patch, one of "requests", "limits" or "both", defaults to "both".
+	Mode redskyappsv1alpha1.ContainerResourcesMode `json:"mode,omitempty"`
+	// Per-resource ratio of limits to requests, only used when patching both.
+	LimitRequestRatio corev1.ResourceList `json:"limitRequestRatio,omitempty"`
 	// Create container resource requirements even if the original object does not contain them.
 	CreateIfNotPresent bool `json:"create,omitempty"`
 	// Per-namespace limit ranges for containers.
 	ContainerLimitRange map[string]corev1.LimitRangeItem `json:"containerLimitRange,omitempty"`
+	// Per-resource maximum of the sum across all of the selected containers, weighted by replica count.
+	MaxTotal corev1.ResourceList `json:"maxTotal,omitempty"`
+
+	// total is used to collect all of the parameters constrained by the maximum total.
+	total *containerResourcesTotal
 }
 
 var _ scan.Selector = &ContainerResourcesSelector{}
@@ -49,14 +63,18 @@ var _ scan.Selector = &ContainerResourcesSelector{}
 // Default applies default values to the selector.
 func (s *ContainerResourcesSelector) Default() {
 	if s.Kind == "" {
-		s.Group = "apps|extensions"
-		s.Kind = "Deployment|StatefulSet"
-		s.Path = "/spec/template/spec/containers/[name={ .ContainerName }]/resources"
+		s.Group = "apps|extensions|batch"
+		s.Kind = "Deployment|StatefulSet|DaemonSet|ReplicaSet|CronJob"
+		s.Path = "/{ .PodSpecPath }/containers/[name={ .ContainerName }]/resources"
 	}
 
 	if len(s.Resources) == 0 {
 		s.Resources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
 	}
+
+	if s.Mode == "" {
+		s.Mode = redskyappsv1alpha1.ContainerResourcesBoth
+	}
 }
 
 // Select matches all of the generically match nodes plus any `LimitRange` resources
@@ -64,6 +82,9 @@ func (s *ContainerResourcesSelector) Default() {
 func (s *ContainerResourcesSelector) Select(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	var result []*yaml.RNode
 
+	// Each scan starts a new total, otherwise parameters from a previous scan would be constrained
+	s.total = nil
+
 	// In addition to the actual nodes, we collect the limit ranges and put them
 	// at the front of the list so we can process them first
 	limitRangeSelector := &filters.ResourceMetaFilter{Version: "v1", Kind: "LimitRange"}
@@ -95,7 +116,10 @@ func (s *ContainerResourcesSelector) Map(node *yaml.RNode, meta yaml.ResourceMet
 	}
 
 	// Evaluate and validate the path
-	path, err := sfio.FieldPath(s.Path, map[string]string{"ContainerName": s.ContainerName})
+	path, err := sfio.FieldPath(s.Path, map[string]string{
+		"ContainerName": s.ContainerName,
+		"PodSpecPath":   podSpecPath(meta.Kind),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -114,24 +138,60 @@ func (s *ContainerResourcesSelector) Map(node *yaml.RNode, meta yaml.ResourceMet
 		resourcesMatcher.Create = yaml.NewMapRNode(nil)
 	}
 
+	// The first parameter found is preceded by the source of the total constraints
+	if len(s.MaxTotal) > 0 && s.total == nil {
+		s.total = &containerResourcesTotal{maxTotal: s.MaxTotal}
+		result = append(result, s.total)
+	}
+
+	// Capture the replica count to use as a constraint weight
+	replicas, err := workloadReplicas(node)
+	if err != nil {
+		return nil, err
+	}
+
 	return result, node.PipeE(sfio.TeeMatched(
 		containerMatcher,
 		sfio.PreserveFieldMatcherPath(resourcesMatcher),
 		yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
-			result = append(result, &containerResourcesParameter{
+			p := &containerResourcesParameter{
 				pnode: pnode{
 					meta:      meta,
 					fieldPath: node.FieldPath(),
 					value:     node.YNode(),
 				},
-				resources:  s.Resources,
-				limitRange: s.ContainerLimitRange[meta.Namespace],
-			})
+				resources:         s.Resources,
+				mode:              s.Mode,
+				limitRequestRatio: s.LimitRequestRatio,
+				limitRange:        s.ContainerLimitRange[meta.Namespace],
+				replicas:          replicas,
+			}
+			result = append(result, p)
+			if s.total != nil {
+				s.total.parameters = append(s.total.parameters, p)
+			}
 			return node, nil
 		}),
 	))
 }
 
+// workloadReplicas returns the current replica count of a workload, defaulting to 1.
+func workloadReplicas(node *yaml.RNode) (int64, error) {
+	replicasNode, err := node.Pipe(yaml.Lookup("spec", "replicas"))
+	if err != nil {
+		return 0, err
+	}
+	if replicasNode == nil {
+		return 1, nil
+	}
+
+	replicas, err := strconv.ParseInt(yaml.GetValue(replicasNode), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid replica count: %w", err)
+	}
+	return replicas, nil
+}
+
 // saveContainerLimitRange captures the container specific limit range item for
 // the specified namespace so that it can be used for defaults later.
 func (s *ContainerResourcesSelector) saveContainerLimitRange(namespace string, node *yaml.RNode) error {
@@ -160,8 +220,11 @@ func (s *ContainerResourcesSelector) saveContainerLimitRange(namespace string, n
 // found by the selector during scanning.
 type containerResourcesParameter struct {
 	pnode
-	resources  []corev1.ResourceName
-	limitRange corev1.LimitRangeItem
+	resources         []corev1.ResourceName
+	mode              redskyappsv1alpha1.ContainerResourcesMode
+	limitRequestRatio corev1.ResourceList
+	limitRange        corev1.LimitRangeItem
+	replicas          int64
 }
 
 var _ PatchSource = &containerResourcesParameter{}
@@ -183,20 +246,44 @@ func (p *containerResourcesParameter) Patch(name ParameterNamer) (yaml.Filter, e
 	for _, rn := range p.resources {
 		// Create patch filter for each ResourceName (e.g. "cpu: {{ .Values ...")
 		parameterName := name(p.meta, p.fieldPath, string(rn))
+		if err := checkParameterName(parameterName); err != nil {
+			return nil, err
+		}
 		patch := fmt.Sprintf("{{ .Values.%s }}%s", parameterName, ind[rn].Suffix())
 		patchFilter := yaml.SetField(string(rn), yaml.NewStringRNode(patch))
 
-		// Apply the same patch filter to both limits and requests
-		if err := limitsPatch.Value.PipeE(patchFilter); err != nil {
-			return nil, err
+		// Limits may be scaled relative to the requests
+		limitsPatchFilter := patchFilter
+		patchLimits, patchRequests := p.mode != redskyappsv1alpha1.ContainerResourcesRequests, p.mode != redskyappsv1alpha1.ContainerResourcesLimits
+		if !isOvercommitAllowed(rn) {
+			// The limits must always equal the requests, regardless of mode or ratio
+			patchLimits, patchRequests = true, true
+		} else if ratio, ok := p.limitRequestRatio[rn]; ok && p.mode != redskyappsv1alpha1.ContainerResourcesLimits && ratio.MilliValue() != 1000 {
+			patch := fmt.Sprintf("{{ percent .Values.%s %d }}%s", parameterName, ratio.MilliValue()/10, ind[rn].Suffix())
+			limitsPatchFilter = yaml.SetField(string(rn), yaml.NewStringRNode(patch))
 		}
-		if err := requestsPatch.Value.PipeE(patchFilter); err != nil {
-			return nil, err
+
+		if patchLimits {
+			if err := limitsPatch.Value.PipeE(limitsPatchFilter); err != nil {
+				return nil, err
+			}
+		}
+		if patchRequests {
+			if err := requestsPatch.Value.PipeE(patchFilter); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	// Combine the filters using Tee so resulting filter won't change the traversal depth
-	return yaml.Tee(path, yaml.Tee(limitsPatch), yaml.Tee(requestsPatch)), nil
+	patches := []yaml.Filter{path}
+	if len(limitsPatch.Value.YNode().Content) > 0 {
+		patches = append(patches, yaml.Tee(limitsPatch))
+	}
+	if len(requestsPatch.Value.YNode().Content) > 0 {
+		patches = append(patches, yaml.Tee(requestsPatch))
+	}
+	return yaml.Tee(patches...), nil
 }
 
 // Parameters lists the parameters used by the patch.
@@ -219,6 +306,77 @@ func (p *containerResourcesParameter) Parameters(name ParameterNamer) ([]redskyv
 	return result, nil
 }
 
+// containerResourcesTotal is used to constrain the sum of the container resources
+// parameters found by a single selector.
+type containerResourcesTotal struct {
+	maxTotal   corev1.ResourceList
+	parameters []*containerResourcesParameter
+}
+
+var _ ConstraintSource = &containerResourcesTotal{}
+
+// Constraints produces a sum constraint for each resource with a maximum total. The
+// weight of each parameter converts the parameter value back into a quantity of the
+// resource for all of the replicas, e.g. a CPU parameter measured in millicores for
+// a deployment with 3 replicas is weighted "3m".
+func (t *containerResourcesTotal) Constraints(name ParameterNamer) ([]redskyv1beta1.Constraint, error) {
+	var result []redskyv1beta1.Constraint
+	for _, rn := range sortedResourceNames(t.maxTotal) {
+		sc := &redskyv1beta1.SumConstraint{
+			Bound:        t.maxTotal[rn],
+			IsUpperBound: true,
+		}
+
+		for _, p := range t.parameters {
+			if !containsResourceName(p.resources, rn) {
+				continue
+			}
+
+			ind, err := p.indexContainerResources()
+			if err != nil {
+				return nil, err
+			}
+
+			unit := ind[rn].Unit()
+			sc.Parameters = append(sc.Parameters, redskyv1beta1.SumConstraintParameter{
+				Name:   name(p.meta, p.fieldPath, string(rn)),
+				Weight: *resource.NewMilliQuantity(unit.MilliValue()*p.replicas, resource.DecimalSI),
+			})
+		}
+
+		if len(sc.Parameters) == 0 {
+			continue
+		}
+
+		result = append(result, redskyv1beta1.Constraint{
+			Name: "total_" + string(rn),
+			Sum:  sc,
+		})
+	}
+
+	return result, nil
+}
+
+// sortedResourceNames returns the names from a resource list in a stable order.
+func sortedResourceNames(rl corev1.ResourceList) []corev1.ResourceName {
+	result := make([]corev1.ResourceName, 0, len(rl))
+	for rn := range rl {
+		result = append(result, rn)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// containsResourceName checks to see if the resource name is in the list.
+func containsResourceName(names []corev1.ResourceName, rn corev1.ResourceName) bool {
+	for i := range names {
+		if names[i] == rn {
+			return true
+		}
+	}
+	return false
+}
+
 // indexContainerResources collects the container resources for this parameter.
 func (p *containerResourcesParameter) indexContainerResources() (map[corev1.ResourceName]containerResources, error) {
 	// Decode the resource requirements we found during the scan
@@ -227,15 +385,28 @@ func (p *containerResourcesParameter) indexContainerResources() (map[corev1.Reso
 		return nil, err
 	}
 
+	// When only optimizing limits, the baseline comes from the limits
+	baselines := []corev1.ResourceList{scannedValue.Requests, p.limitRange.DefaultRequest, defaultLimitRange.DefaultRequest}
+	if p.mode == redskyappsv1alpha1.ContainerResourcesLimits {
+		baselines = []corev1.ResourceList{scannedValue.Limits, p.limitRange.Default, scannedValue.Requests, p.limitRange.DefaultRequest, defaultLimitRange.DefaultRequest}
+	}
+
 	// For each configured resource, capture the baseline and range
 	result := make(map[corev1.ResourceName]containerResources, len(p.resources))
 	for _, rn := range p.resources {
-		result[rn] = containerResources{
+		cr := containerResources{
 			max:          lookupQuantity(rn, p.limitRange.Max, defaultLimitRange.Max),
 			min:          lookupQuantity(rn, p.limitRange.Min, defaultLimitRange.Min),
-			baseline:     lookupQuantity(rn, scannedValue.Requests, p.limitRange.DefaultRequest, defaultLimitRange.DefaultRequest),
-			defaultScale: defaultScale[rn],
+			baseline:     lookupQuantity(rn, baselines...),
+			defaultScale: resourceScale(rn),
 		}
+
+		// Huge pages are always expressed in binary units, even when there is no baseline to get the format from
+		if cr.baseline.IsZero() && strings.HasPrefix(string(rn), corev1.ResourceHugePagesPrefix) {
+			cr.baseline.Format = resource.BinarySI
+		}
+
+		result[rn] = cr
 	}
 
 	return result, nil
@@ -283,6 +454,35 @@ var (
 	}
 )
 
+// resourceScale returns the default scale for the named resource.
+func resourceScale(rn corev1.ResourceName) resource.Scale {
+	if scale, ok := defaultScale[rn]; ok {
+		return scale
+	}
+
+	if strings.HasPrefix(string(rn), corev1.ResourceHugePagesPrefix) {
+		return resource.Mega // Mi
+	}
+
+	// Extended resources (e.g. GPUs) can only be allocated in whole units
+	return 0
+}
+
+// isOvercommitAllowed checks to see if the limits of the named resource can
+// differ from the requests. Huge pages and extended resources (i.e. resources
+// with a fully qualified name outside of the "kubernetes.io" domain) cannot be
+// overcommitted and must specify equal requests and limits.
+func isOvercommitAllowed(rn corev1.ResourceName) bool {
+	name := string(rn)
+	if strings.HasPrefix(name, corev1.ResourceHugePagesPrefix) {
+		return false
+	}
+	if strings.Contains(name, "/") && !strings.HasPrefix(name, corev1.ResourceDefaultNamespacePrefix) {
+		return false
+	}
+	return true
+}
+
 // containerResources contains the quantity range for a resource.
 type containerResources struct {
 	max          resource.Quantity
@@ -335,6 +535,20 @@ func (cr containerResources) Suffix() string {
 	return QuantitySuffix(cr.scale(), cr.baseline.Format)
 }
 
+// Unit returns the quantity of the resource represented by a parameter value of one.
+func (cr containerResources) Unit() resource.Quantity {
+	scale := cr.scale()
+	if cr.baseline.Format == resource.BinarySI && scale > 0 {
+		v := int64(1)
+		for e := int(scale) / 3; e > 0; e-- {
+			v *= 1024
+		}
+		return *resource.NewQuantity(v, resource.BinarySI)
+	}
+
+	return *resource.NewScaledQuantity(1, scale)
+}
+
 // scale is used to determine what scale all the values should be recorded using.
 // This is important because we do not want to have mismatched scales (e.g. a min
 // of 1000 and a baseline of 2).
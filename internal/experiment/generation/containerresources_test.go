@@ -17,17 +17,20 @@ limitations under the License.
 package generation
 
 import (
+	"os/exec"
 	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -35,8 +38,10 @@ func TestContainerResourcesParameter(t *testing.T) {
 	cases := []struct {
 		desc string
 		containerResourcesParameter
+		name               ParameterNamer
 		expectedParameters []redskyv1beta1.Parameter
 		expectedPatch      string
+		expectedPatchErr   string
 	}{
 		{
 			desc: "binary memory",
@@ -165,18 +170,228 @@ func TestContainerResourcesParameter(t *testing.T) {
                   requests:
                     memory: "{{ .Values.memory }}Ki"`),
 		},
+
+		{
+			desc: "requests only",
+
+			containerResourcesParameter: containerResourcesParameter{
+				pnode: pnode{
+					fieldPath: []string{"spec", "resources"},
+					value: encodeResourceRequirements(corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					}),
+				},
+				resources: []corev1.ResourceName{corev1.ResourceMemory},
+				mode:      redskyappsv1alpha1.ContainerResourcesRequests,
+			},
+
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:     "memory",
+					Baseline: newInt(2048),
+					Min:      1024,
+					Max:      4096,
+				},
+			},
+			expectedPatch: unindent(`
+              spec:
+                resources:
+                  requests:
+                    memory: "{{ .Values.memory }}Mi"`),
+		},
+
+		{
+			desc: "limits only",
+
+			containerResourcesParameter: containerResourcesParameter{
+				pnode: pnode{
+					fieldPath: []string{"spec", "resources"},
+					value: encodeResourceRequirements(corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					}),
+				},
+				resources: []corev1.ResourceName{corev1.ResourceMemory},
+				mode:      redskyappsv1alpha1.ContainerResourcesLimits,
+			},
+
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:     "memory",
+					Baseline: newInt(1024),
+					Min:      512,
+					Max:      2048,
+				},
+			},
+			expectedPatch: unindent(`
+              spec:
+                resources:
+                  limits:
+                    memory: "{{ .Values.memory }}Mi"`),
+		},
+
+		{
+			desc: "limit request ratio",
+
+			containerResourcesParameter: containerResourcesParameter{
+				pnode: pnode{
+					fieldPath: []string{"spec", "resources"},
+					value: encodeResourceRequirements(corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("500m"),
+						},
+					}),
+				},
+				resources: []corev1.ResourceName{corev1.ResourceCPU},
+				mode:      redskyappsv1alpha1.ContainerResourcesBoth,
+				limitRequestRatio: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1.5"),
+				},
+			},
+
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:     "cpu",
+					Baseline: newInt(500),
+					Min:      250,
+					Max:      1000,
+				},
+			},
+			expectedPatch: unindent(`
+              spec:
+                resources:
+                  limits:
+                    cpu: "{{ percent .Values.cpu 150 }}m"
+                  requests:
+                    cpu: "{{ .Values.cpu }}m"`),
+		},
+
+		{
+			desc: "extended resources",
+
+			containerResourcesParameter: containerResourcesParameter{
+				pnode: pnode{
+					fieldPath: []string{"spec", "resources"},
+					value: encodeResourceRequirements(corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("2"),
+						},
+						Limits: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("2"),
+						},
+					}),
+				},
+				resources: []corev1.ResourceName{"nvidia.com/gpu"},
+				mode:      redskyappsv1alpha1.ContainerResourcesRequests,
+				limitRequestRatio: corev1.ResourceList{
+					"nvidia.com/gpu": resource.MustParse("2"),
+				},
+			},
+			name: parameterNamer(nil),
+
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:     "nvidia_com_gpu",
+					Baseline: newInt(2),
+					Min:      1,
+					Max:      4,
+				},
+			},
+			expectedPatch: unindent(`
+              spec:
+                resources:
+                  limits:
+                    nvidia.com/gpu: "{{ .Values.nvidia_com_gpu }}"
+                  requests:
+                    nvidia.com/gpu: "{{ .Values.nvidia_com_gpu }}"`),
+		},
+
+		{
+			desc: "huge pages",
+
+			containerResourcesParameter: containerResourcesParameter{
+				pnode: pnode{
+					fieldPath: []string{"spec", "resources"},
+					value: encodeResourceRequirements(corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"hugepages-2Mi": resource.MustParse("1Gi"),
+						},
+					}),
+				},
+				resources: []corev1.ResourceName{"hugepages-2Mi"},
+				mode:      redskyappsv1alpha1.ContainerResourcesBoth,
+			},
+			name: parameterNamer(nil),
+
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:     "hugepages_2mi",
+					Baseline: newInt(1024),
+					Min:      512,
+					Max:      2048,
+				},
+			},
+			expectedPatch: unindent(`
+              spec:
+                resources:
+                  limits:
+                    hugepages-2Mi: "{{ .Values.hugepages_2mi }}Mi"
+                  requests:
+                    hugepages-2Mi: "{{ .Values.hugepages_2mi }}Mi"`),
+		},
+
+		{
+			desc: "invalid parameter name",
+
+			containerResourcesParameter: containerResourcesParameter{
+				pnode: pnode{
+					fieldPath: []string{"spec", "resources"},
+					value: encodeResourceRequirements(corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("2"),
+						},
+					}),
+				},
+				resources: []corev1.ResourceName{"nvidia.com/gpu"},
+			},
+
+			expectedParameters: []redskyv1beta1.Parameter{
+				{
+					Name:     "nvidia.com/gpu",
+					Baseline: newInt(2),
+					Min:      1,
+					Max:      4,
+				},
+			},
+			expectedPatchErr: `invalid parameter name "nvidia.com/gpu", the name cannot be used in a patch template`,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
+			name := c.name
+			if name == nil {
+				name = ignoreMetaForName
+			}
+
 			t.Run("parameters", func(t *testing.T) {
-				parameters, err := c.containerResourcesParameter.Parameters(ignoreMetaForName)
+				parameters, err := c.containerResourcesParameter.Parameters(name)
 				if assert.NoError(t, err) {
 					assert.Equal(t, c.expectedParameters, parameters)
 				}
 			})
 
 			t.Run("patch", func(t *testing.T) {
-				filter, err := c.containerResourcesParameter.Patch(ignoreMetaForName)
+				filter, err := c.containerResourcesParameter.Patch(name)
+				if c.expectedPatchErr != "" {
+					assert.EqualError(t, err, c.expectedPatchErr)
+					return
+				}
 				if assert.NoError(t, err) {
 					patch, err := yaml.NewMapRNode(nil).Pipe(filter)
 					if assert.NoError(t, err) {
@@ -190,6 +405,163 @@ func TestContainerResourcesParameter(t *testing.T) {
 	}
 }
 
+func TestContainerResourcesSelector(t *testing.T) {
+	cases := []struct {
+		desc              string
+		resource          string
+		expectedFieldPath []string
+	}{
+		{
+			desc: "deployment",
+			resource: unindent(`
+              apiVersion: apps/v1
+              kind: Deployment
+              metadata:
+                name: test
+              spec:
+                template:
+                  spec:
+                    containers:
+                    - name: app
+                      image: nginx`),
+			expectedFieldPath: []string{"spec", "template", "spec", "containers", "[name=app]", "resources"},
+		},
+		{
+			desc: "daemon set",
+			resource: unindent(`
+              apiVersion: apps/v1
+              kind: DaemonSet
+              metadata:
+                name: test
+              spec:
+                template:
+                  spec:
+                    containers:
+                    - name: agent
+                      image: nginx`),
+			expectedFieldPath: []string{"spec", "template", "spec", "containers", "[name=agent]", "resources"},
+		},
+		{
+			desc: "cron job",
+			resource: unindent(`
+              apiVersion: batch/v1beta1
+              kind: CronJob
+              metadata:
+                name: test
+              spec:
+                jobTemplate:
+                  spec:
+                    template:
+                      spec:
+                        containers:
+                        - name: job
+                          image: busybox`),
+			expectedFieldPath: []string{"spec", "jobTemplate", "spec", "template", "spec", "containers", "[name=job]", "resources"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			sel := &ContainerResourcesSelector{CreateIfNotPresent: true}
+			sel.Default()
+
+			node := yaml.MustParse(c.resource)
+			selected, err := sel.Select([]*yaml.RNode{node})
+			require.NoError(t, err)
+			require.Len(t, selected, 1)
+
+			meta, err := node.GetMeta()
+			require.NoError(t, err)
+			mapped, err := sel.Map(node, meta)
+			require.NoError(t, err)
+			if assert.Len(t, mapped, 1) {
+				assert.Equal(t, c.expectedFieldPath, mapped[0].(*containerResourcesParameter).fieldPath)
+				assert.Equal(t, meta.Kind, mapped[0].(*containerResourcesParameter).TargetRef().Kind)
+			}
+		})
+	}
+}
+
+func TestContainerResourcesTotal(t *testing.T) {
+	sel := &ContainerResourcesSelector{
+		CreateIfNotPresent: true,
+		Resources:          []corev1.ResourceName{corev1.ResourceCPU},
+		MaxTotal:           corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+	}
+	sel.Default()
+
+	var nodes []*yaml.RNode
+	for _, r := range []string{
+		unindent(`
+          apiVersion: apps/v1
+          kind: Deployment
+          metadata:
+            name: frontend
+          spec:
+            replicas: 3
+            template:
+              spec:
+                containers:
+                - name: app
+                  resources:
+                    requests:
+                      cpu: 500m`),
+		unindent(`
+          apiVersion: apps/v1
+          kind: Deployment
+          metadata:
+            name: backend
+          spec:
+            template:
+              spec:
+                containers:
+                - name: app
+                  resources:
+                    requests:
+                      cpu: 1`),
+	} {
+		nodes = append(nodes, yaml.MustParse(r))
+	}
+
+	// Scan the same resources twice, the total from the first scan must not be reused
+	var selected []interface{}
+	for i := 0; i < 2; i++ {
+		selectedNodes, err := sel.Select(nodes)
+		require.NoError(t, err)
+
+		selected = nil
+		for _, node := range selectedNodes {
+			meta, err := node.GetMeta()
+			require.NoError(t, err)
+			mapped, err := sel.Map(node, meta)
+			require.NoError(t, err)
+			selected = append(selected, mapped...)
+		}
+	}
+
+	// The total constraint source is only included once
+	require.Len(t, selected, 3)
+	total, ok := selected[0].(*containerResourcesTotal)
+	require.True(t, ok)
+
+	constraints, err := total.Constraints(func(meta yaml.ResourceMeta, _ []string, name string) string {
+		return meta.Name + "_" + name
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []redskyv1beta1.Constraint{
+		{
+			Name: "total_cpu",
+			Sum: &redskyv1beta1.SumConstraint{
+				Bound:        resource.MustParse("4"),
+				IsUpperBound: true,
+				Parameters: []redskyv1beta1.SumConstraintParameter{
+					{Name: "frontend_cpu", Weight: *resource.NewMilliQuantity(3, resource.DecimalSI)},
+					{Name: "backend_cpu", Weight: *resource.NewMilliQuantity(1, resource.DecimalSI)},
+				},
+			},
+		},
+	}, constraints)
+}
+
 // encodeResourceRequirements is a helper to generate the YAML content necessary
 // for the pnode value of the containerResourcesParameter.
 func encodeResourceRequirements(rr corev1.ResourceRequirements) *yaml.Node {
@@ -210,6 +582,89 @@ func newInt(val int) *intstr.IntOrString {
 func ignoreMetaForName(_ yaml.ResourceMeta, _ []string, name string) string { return name }
 
 // unindent removes a fixed indentation width from each line of a string.
+func TestLiveBaselineFilter(t *testing.T) {
+	input := unindent(`
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: app
+      spec:
+        template:
+          spec:
+            containers:
+            - name: app
+              image: app
+              resources:
+                requests:
+                  cpu: 100m
+      ---
+      apiVersion: v1
+      kind: Service
+      metadata:
+        name: app`)
+
+	live := unindent(`
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: app
+        namespace: prod
+      spec:
+        replicas: 3
+        template:
+          spec:
+            containers:
+            - name: app
+              image: app
+              resources:
+                requests:
+                  cpu: 500m
+                  memory: 1Gi`)
+
+	expected := unindent(`
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: app
+      spec:
+        template:
+          spec:
+            containers:
+            - name: app
+              image: app
+              resources:
+                requests:
+                  cpu: 500m
+                  memory: 1Gi
+        replicas: 3
+      ---
+      apiVersion: v1
+      kind: Service
+      metadata:
+        name: app`)
+
+	var args []string
+	f := &LiveBaselineFilter{
+		Namespace: "prod",
+		Kubectl: func(cmd *exec.Cmd) ([]byte, error) {
+			args = cmd.Args
+			return []byte(live), nil
+		},
+	}
+
+	nodes, err := kio.FromBytes([]byte(input))
+	require.NoError(t, err)
+
+	nodes, err = f.Filter(nodes)
+	require.NoError(t, err)
+
+	actual, err := kio.StringAll(nodes)
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(actual))
+	}
+	assert.Equal(t, []string{"kubectl", "get", "deployment.apps", "app", "--output", "yaml", "--namespace", "prod"}, args)
+}
+
 func unindent(s string) string {
 	p := 0
 	var result []string
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CredentialsSource wires the application credentials into the trial job and metrics. It must be
+// evaluated after the sources which produce the trial job and metrics.
+type CredentialsSource struct {
+	Application *redskyappsv1alpha1.Application
+}
+
+var _ ExperimentSource = &CredentialsSource{}
+
+// Update adds environment variables to the trial job containers and secret targets to the metrics.
+func (s *CredentialsSource) Update(exp *redskyv1beta1.Experiment) error {
+	if s.Application == nil {
+		return nil
+	}
+
+	for i := range s.Application.Credentials {
+		c := &s.Application.Credentials[i]
+		switch c.Usage {
+
+		case redskyappsv1alpha1.CredentialTrialJob, "":
+			if c.Name == "" {
+				return fmt.Errorf("trial job credential %q must have a name", c.SecretKeyRef.Name)
+			}
+
+			// Only generated trial jobs are updated, the default trial job does not use credentials
+			if exp.Spec.TrialTemplate.Spec.JobTemplate == nil {
+				continue
+			}
+
+			pod := ensureTrialJobPod(exp)
+			for j := range pod.Spec.Containers {
+				pod.Spec.Containers[j].Env = append(pod.Spec.Containers[j].Env, corev1.EnvVar{
+					Name:      c.Name,
+					ValueFrom: &corev1.EnvVarSource{SecretKeyRef: c.SecretKeyRef.DeepCopy()},
+				})
+			}
+
+		case redskyappsv1alpha1.CredentialMetrics:
+			for j := range exp.Spec.Metrics {
+				m := &exp.Spec.Metrics[j]
+				if m.Target != nil || !usesMetricCredentials(m) {
+					continue
+				}
+
+				m.Target = &redskyv1beta1.ResourceTarget{
+					APIVersion: "v1",
+					Kind:       "Secret",
+					Name:       c.SecretKeyRef.Name,
+				}
+			}
+
+		default:
+			return fmt.Errorf("unknown credential usage %q", c.Usage)
+		}
+	}
+
+	return nil
+}
+
+// usesMetricCredentials checks to see if a metric queries an external service that accepts credentials.
+func usesMetricCredentials(m *redskyv1beta1.Metric) bool {
+	switch m.Type {
+	case redskyv1beta1.MetricDatadog, redskyv1beta1.MetricInfluxDB, redskyv1beta1.MetricElasticsearch:
+		return true
+	case redskyv1beta1.MetricPrometheus:
+		// The built-in Prometheus (i.e. no URL) does not require credentials
+		return m.URL != ""
+	default:
+		return false
+	}
+}
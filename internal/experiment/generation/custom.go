@@ -94,6 +94,21 @@ func (s *CustomSource) Metrics() ([]redskyv1beta1.Metric, error) {
 		case goal.Implemented:
 			// Do nothing
 
+		case s.Scenario.Custom.UsePushGateway && goal.Latency != nil:
+			if l := latencySampleName(goal.Latency.LatencyType); l != "" {
+				result = append(result, newGoalMetric(goal, pushedSampleQuery(l)))
+			}
+
+		case s.Scenario.Custom.UsePushGateway && goal.ErrorRate != nil:
+			if goal.ErrorRate.ErrorRateType == redskyappsv1alpha1.ErrorRateRequests {
+				query := `scalar(failure_count{job="trialRun",instance="{{ .Trial.Name }}"} / request_count{job="trialRun",instance="{{ .Trial.Name }}"})`
+				result = append(result, newGoalMetric(goal, query))
+			}
+
+		case s.Scenario.Custom.UsePushGateway && isCustomGoal(goal):
+			// Custom measurements are pushed using a sample name matching the goal name
+			result = append(result, newGoalMetric(goal, pushedSampleQuery(pushedSampleName(goal.Name))))
+
 		case goal.Requests != nil:
 			if s.Scenario.Custom.UsePushGateway {
 				continue
@@ -129,3 +144,29 @@ func (s *CustomSource) Metrics() ([]redskyv1beta1.Metric, error) {
 
 	return result, nil
 }
+
+// isCustomGoal checks to see if the goal has a name but no configuration.
+func isCustomGoal(goal *redskyappsv1alpha1.Goal) bool {
+	return goal.Name != "" &&
+		goal.Requests == nil &&
+		goal.Latency == nil &&
+		goal.ErrorRate == nil &&
+		goal.Duration == nil &&
+		goal.Prometheus == nil &&
+		goal.Datadog == nil
+}
+
+// pushedSampleQuery returns a query for a sample pushed to the Prometheus Push Gateway by the trial job.
+func pushedSampleQuery(name string) string {
+	return `scalar(` + name + `{job="trialRun",instance="{{ .Trial.Name }}"})`
+}
+
+// pushedSampleName returns a valid Prometheus metric name for the supplied goal name.
+func pushedSampleName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
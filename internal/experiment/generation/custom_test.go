@@ -0,0 +1,50 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+)
+
+func TestCustomSourcePushGateway(t *testing.T) {
+	s := &CustomSource{
+		Scenario: &redskyappsv1alpha1.Scenario{
+			Custom: &redskyappsv1alpha1.CustomScenario{UsePushGateway: true},
+		},
+		Objective: &redskyappsv1alpha1.Objective{
+			Goals: []redskyappsv1alpha1.Goal{
+				{Name: "p95-latency", Latency: &redskyappsv1alpha1.LatencyGoal{LatencyType: redskyappsv1alpha1.LatencyPercentile95}},
+				{Name: "error-rate", ErrorRate: &redskyappsv1alpha1.ErrorRateGoal{ErrorRateType: redskyappsv1alpha1.ErrorRateRequests}},
+				{Name: "queue-depth"},
+				{Name: "cost", Requests: &redskyappsv1alpha1.RequestsGoal{}},
+			},
+		},
+	}
+
+	metrics, err := s.Metrics()
+	if assert.NoError(t, err) && assert.Len(t, metrics, 3) {
+		assert.Equal(t, `scalar(p95{job="trialRun",instance="{{ .Trial.Name }}"})`, metrics[0].Query)
+		assert.Equal(t, `scalar(failure_count{job="trialRun",instance="{{ .Trial.Name }}"} / request_count{job="trialRun",instance="{{ .Trial.Name }}"})`, metrics[1].Query)
+		assert.Equal(t, `scalar(queue_depth{job="trialRun",instance="{{ .Trial.Name }}"})`, metrics[2].Query)
+	}
+
+	// Requests are left for the built-in Prometheus
+	assert.False(t, s.Objective.Goals[3].Implemented)
+}
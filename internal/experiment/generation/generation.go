@@ -35,13 +35,16 @@ import (
 func newGoalMetric(obj *redskyappsv1alpha1.Goal, query string) redskyv1beta1.Metric {
 	defer func() { obj.Implemented = true }()
 	return redskyv1beta1.Metric{
-		Type:     redskyv1beta1.MetricPrometheus,
-		Query:    query,
-		Minimize: true,
-		Name:     obj.Name,
-		Min:      obj.Min,
-		Max:      obj.Max,
-		Optimize: obj.Optimize,
+		Type:           redskyv1beta1.MetricPrometheus,
+		Query:          query,
+		Minimize:       true,
+		Name:           obj.Name,
+		Min:            obj.Min,
+		Max:            obj.Max,
+		TargetValue:    obj.Target,
+		FailFast:       obj.FailFast,
+		SampleInterval: obj.SampleInterval,
+		Optimize:       obj.Optimize,
 	}
 }
 
@@ -53,13 +56,21 @@ func ensureTrialJobPod(exp *redskyv1beta1.Experiment) *corev1.PodTemplateSpec {
 	return &exp.Spec.TrialTemplate.Spec.JobTemplate.Spec.Template
 }
 
+// podSpecPath returns the path to the pod specification for a kind of workload.
+func podSpecPath(kind string) string {
+	switch kind {
+	case "CronJob":
+		return "spec/jobTemplate/spec/template/spec"
+	case "Pod":
+		return "spec"
+	default:
+		return "spec/template/spec"
+	}
+}
+
 // trialJobImage returns the image name for a type of job.
 func trialJobImage(job string) string {
-	// Allow the image name to be overridden using environment variables, primarily for development work
-	imageName := os.Getenv("OPTIMIZE_TRIALS_IMAGE_REPOSITORY")
-	if imageName == "" {
-		imageName = "thestormforge/optimize-trials"
-	}
+	imageName := trialJobRepository()
 	imageTag := os.Getenv("OPTIMIZE_TRIALS_IMAGE_TAG")
 	if imageTag == "" {
 		imageTagBase := os.Getenv("OPTIMIZE_TRIALS_IMAGE_TAG_BASE")
@@ -71,6 +82,15 @@ func trialJobImage(job string) string {
 	return imageName + ":" + imageTag
 }
 
+// trialJobRepository returns the repository of the trial job images.
+func trialJobRepository() string {
+	// Allow the image name to be overridden using environment variables, primarily for development work
+	if imageName := os.Getenv("OPTIMIZE_TRIALS_IMAGE_REPOSITORY"); imageName != "" {
+		return imageName
+	}
+	return "thestormforge/optimize-trials"
+}
+
 // loadApplicationData loads data (e.g. a supporting test file).
 func loadApplicationData(app *redskyappsv1alpha1.Application, src string) ([]byte, error) {
 	dst := filepath.Join(os.TempDir(), fmt.Sprintf("load-application-data-%x", md5.Sum([]byte(src))))
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewGoalMetric(t *testing.T) {
+	failFast := resource.MustParse("2")
+	goal := &redskyappsv1alpha1.Goal{
+		Name:           "p95-latency",
+		FailFast:       &failFast,
+		SampleInterval: &metav1.Duration{Duration: 30 * time.Second},
+	}
+
+	m := newGoalMetric(goal, "query")
+	assert.True(t, goal.Implemented)
+	assert.Equal(t, "p95-latency", m.Name)
+	assert.Equal(t, &failFast, m.FailFast)
+	assert.Equal(t, &metav1.Duration{Duration: 30 * time.Second}, m.SampleInterval)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"strings"
+
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/setup"
+)
+
+// ImagesSource applies the application image registry and pull secrets to the trial job and setup tasks. It must
+// be evaluated after the sources which produce the trial job and setup tasks.
+type ImagesSource struct {
+	Application *redskyappsv1alpha1.Application
+}
+
+var _ ExperimentSource = &ImagesSource{}
+
+// Update rewrites the trial and setup task images to use the configured registry and adds the image pull secrets
+// to the trial job pod. Images explicitly configured on the application are not changed.
+func (s *ImagesSource) Update(exp *redskyv1beta1.Experiment) error {
+	if s.Application == nil {
+		return nil
+	}
+
+	// Only generated trial jobs are updated, the default trial job does not use the trial images
+	if exp.Spec.TrialTemplate.Spec.JobTemplate != nil {
+		pod := ensureTrialJobPod(exp)
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, s.Application.ImagePullSecrets...)
+
+		if s.Application.ImageRegistry != "" {
+			repository := trialJobRepository() + ":"
+			for i := range pod.Spec.Containers {
+				c := &pod.Spec.Containers[i]
+				if strings.HasPrefix(c.Image, repository) {
+					c.Image = withRegistry(c.Image, s.Application.ImageRegistry)
+				}
+			}
+		}
+	}
+
+	// Setup tasks without an image would otherwise use the default image of the controller
+	if s.Application.ImageRegistry != "" {
+		for i := range exp.Spec.TrialTemplate.Spec.SetupTasks {
+			task := &exp.Spec.TrialTemplate.Spec.SetupTasks[i]
+			if task.Image == "" && len(task.Command) == 0 {
+				task.Image = withRegistry(setup.Image, s.Application.ImageRegistry)
+			}
+		}
+	}
+
+	return nil
+}
+
+// withRegistry replaces the registry of the supplied image name.
+func withRegistry(image, registry string) string {
+	// The first component is only a registry if it looks like a host name
+	if pos := strings.Index(image, "/"); pos > 0 {
+		if host := image[0:pos]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			image = image[pos+1:]
+		}
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
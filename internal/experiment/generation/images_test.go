@@ -0,0 +1,90 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestWithRegistry(t *testing.T) {
+	cases := []struct {
+		image    string
+		registry string
+		expected string
+	}{
+		{
+			image:    "thestormforge/optimize-trials:v0.0.1-locust",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/thestormforge/optimize-trials:v0.0.1-locust",
+		},
+		{
+			image:    "docker.io/thestormforge/setuptools:latest",
+			registry: "mirror.example.com/stormforge/",
+			expected: "mirror.example.com/stormforge/thestormforge/setuptools:latest",
+		},
+		{
+			image:    "localhost:5000/setuptools:latest",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/setuptools:latest",
+		},
+		{
+			image:    "busybox",
+			registry: "mirror.example.com",
+			expected: "mirror.example.com/busybox",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.image, func(t *testing.T) {
+			assert.Equal(t, c.expected, withRegistry(c.image, c.registry))
+		})
+	}
+}
+
+func TestImagesSource(t *testing.T) {
+	s := &ImagesSource{
+		Application: &redskyappsv1alpha1.Application{
+			ImageRegistry:    "mirror.example.com",
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "mirror"}},
+		},
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	ensureTrialJobPod(exp).Spec.Containers = []corev1.Container{
+		{Name: "locust", Image: trialJobImage("locust")},
+		{Name: "custom", Image: "example.com/custom:latest"},
+	}
+	exp.Spec.TrialTemplate.Spec.SetupTasks = []redskyv1beta1.SetupTask{
+		{Name: "monitoring"},
+		{Name: "custom", Image: "example.com/setup:latest"},
+	}
+
+	if assert.NoError(t, s.Update(exp)) {
+		pod := exp.Spec.TrialTemplate.Spec.JobTemplate.Spec.Template.Spec
+		assert.Equal(t, []corev1.LocalObjectReference{{Name: "mirror"}}, pod.ImagePullSecrets)
+		assert.Equal(t, "mirror.example.com/"+trialJobImage("locust"), pod.Containers[0].Image)
+		assert.Equal(t, "example.com/custom:latest", pod.Containers[1].Image)
+
+		tasks := exp.Spec.TrialTemplate.Spec.SetupTasks
+		assert.Equal(t, "mirror.example.com/setuptools:latest", tasks[0].Image)
+		assert.Equal(t, "example.com/setup:latest", tasks[1].Image)
+	}
+}
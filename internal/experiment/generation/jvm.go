@@ -0,0 +1,359 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/scan"
+	"github.com/thestormforge/optimize-controller/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// JVMSelector scans for containers running a Java virtual machine.
+type JVMSelector struct {
+	scan.GenericSelector
+	// Regular expression matching the container name.
+	ContainerName string `json:"containerName,omitempty"`
+	// Path to the containers.
+	Path string `json:"path,omitempty"`
+	// Name of the environment variable used to pass options to the JVM.
+	VariableName string `json:"variableName,omitempty"`
+	// Regular expression matching images known to run a JVM.
+	Image string `json:"image,omitempty"`
+	// Garbage collectors to consider, e.g. "G1GC" for "-XX:+UseG1GC".
+	GarbageCollectors []string `json:"garbageCollectors,omitempty"`
+}
+
+var _ scan.Selector = &JVMSelector{}
+
+// Default applies default values to the selector.
+func (s *JVMSelector) Default() {
+	if s.Kind == "" {
+		s.Group = "apps|extensions"
+		s.Kind = "Deployment|StatefulSet"
+		s.Path = "/spec/template/spec/containers/[name={ .ContainerName }]"
+	}
+
+	if s.Image == "" {
+		s.Image = `(^|/)(openjdk|adoptopenjdk|eclipse-temurin|amazoncorretto|azul/zulu-openjdk.*|ibmjava|sapmachine|tomcat|jetty)(:|$)`
+	}
+
+	if len(s.GarbageCollectors) == 0 {
+		s.GarbageCollectors = []string{"G1GC", "ParallelGC", "SerialGC"}
+	}
+}
+
+// Map inspects the supplied resource for containers running a JVM.
+func (s *JVMSelector) Map(node *yaml.RNode, meta yaml.ResourceMeta) ([]interface{}, error) {
+	var result []interface{}
+
+	path, err := sfio.FieldPath(s.Path, map[string]string{"ContainerName": s.ContainerName})
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := regexp.Compile(s.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, node.PipeE(sfio.TeeMatched(
+		yaml.PathMatcher{Path: path},
+		yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
+			container := corev1.Container{}
+			if err := sfio.DecodeYAMLToJSON(node, &container); err != nil {
+				return nil, err
+			}
+
+			// Only consider containers which are already passing JVM options or use a known image
+			variableName, options, ok := jvmOptions(container.Env, s.VariableName)
+			if !ok && !image.MatchString(container.Image) {
+				return node, nil
+			}
+
+			result = append(result, &jvmParameter{
+				pnode: pnode{
+					meta:      meta,
+					fieldPath: node.FieldPath(),
+					value:     node.YNode(),
+				},
+				variableName:      variableName,
+				options:           options,
+				memoryLimit:       lookupQuantity(corev1.ResourceMemory, container.Resources.Limits, container.Resources.Requests, defaultLimitRange.Max),
+				garbageCollectors: s.GarbageCollectors,
+			})
+			return node, nil
+		}),
+	))
+}
+
+// jvmOptions returns the name and value of the environment variable used to
+// pass options to the JVM. The final result indicates if the variable exists.
+func jvmOptions(env []corev1.EnvVar, variableName string) (string, string, bool) {
+	names := []string{"JAVA_OPTS", "JAVA_TOOL_OPTIONS"}
+	if variableName != "" {
+		names = []string{variableName}
+	}
+
+	for _, name := range names {
+		for i := range env {
+			if env[i].Name == name && env[i].ValueFrom == nil {
+				return name, env[i].Value, true
+			}
+		}
+	}
+
+	return names[len(names)-1], "", false
+}
+
+// jvmParameter is used to record the position of a container running a JVM
+// found by the selector during scanning.
+type jvmParameter struct {
+	pnode
+	variableName      string
+	options           string
+	memoryLimit       resource.Quantity
+	garbageCollectors []string
+	// memory is the container resources parameter optimizing the memory of the same container (if any).
+	memory *containerResourcesParameter
+}
+
+var _ PatchSource = &jvmParameter{}
+var _ ParameterSource = &jvmParameter{}
+var _ ConstraintSource = &jvmParameter{}
+
+// Patch produces a YAML filter for replacing the heap size and garbage collector
+// options while preserving any other options that were already specified.
+func (p *jvmParameter) Patch(name ParameterNamer) (yaml.Filter, error) {
+	opts := p.parseOptions()
+	opts.other = append(opts.other,
+		fmt.Sprintf("-Xms{{ .Values.%s }}m", name(p.meta, p.fieldPath, "min_heap")),
+		fmt.Sprintf("-Xmx{{ .Values.%s }}m", name(p.meta, p.fieldPath, "max_heap")),
+		fmt.Sprintf("-XX:+Use{{ .Values.%s }}", name(p.meta, p.fieldPath, "gc")),
+	)
+
+	path := make([]string, 0, len(p.fieldPath)+2)
+	path = append(path, p.fieldPath...)
+	path = append(path, "env", "[name="+p.variableName+"]")
+	value := yaml.NewScalarRNode(strings.Join(opts.other, " "))
+
+	return yaml.Tee(
+		&yaml.PathGetter{Path: path, Create: yaml.MappingNode},
+		yaml.FieldSetter{Name: "value", Value: value, OverrideStyle: true},
+	), nil
+}
+
+// Parameters lists the heap size (in MiB) and garbage collector parameters used by the patch.
+func (p *jvmParameter) Parameters(name ParameterNamer) ([]redskyv1beta1.Parameter, error) {
+	opts := p.parseOptions()
+	limit := p.memoryLimitMi()
+
+	// When not specified, use the JVM ergonomics for the baseline (1/4 and 1/64 of available memory)
+	maxHeap, minHeap, gc := opts.maxHeap, opts.minHeap, opts.gc
+	if maxHeap == 0 {
+		maxHeap = limit / 4
+	}
+	if minHeap == 0 {
+		minHeap = limit / 64
+	}
+	if gc == "" {
+		gc = p.garbageCollectors[0]
+	}
+
+	maxHeapParam := redskyv1beta1.Parameter{
+		Name:     name(p.meta, p.fieldPath, "max_heap"),
+		Min:      int32(limit / 8),
+		Max:      int32(limit),
+		Baseline: &intstr.IntOrString{Type: intstr.Int, IntVal: int32(maxHeap)},
+	}
+
+	minHeapParam := redskyv1beta1.Parameter{
+		Name:     name(p.meta, p.fieldPath, "min_heap"),
+		Min:      int32(limit / 64),
+		Max:      int32(limit),
+		Baseline: &intstr.IntOrString{Type: intstr.Int, IntVal: int32(minHeap)},
+	}
+
+	gcParam := redskyv1beta1.Parameter{
+		Name:     name(p.meta, p.fieldPath, "gc"),
+		Values:   appendMissing(p.garbageCollectors, gc),
+		Baseline: &intstr.IntOrString{Type: intstr.String, StrVal: gc},
+	}
+
+	// Make sure explicit baselines are always in range and the heap is never sized to zero
+	for _, param := range []*redskyv1beta1.Parameter{&maxHeapParam, &minHeapParam} {
+		if param.Baseline.IntVal < param.Min {
+			param.Min = param.Baseline.IntVal
+		}
+		if param.Baseline.IntVal > param.Max {
+			param.Max = param.Baseline.IntVal
+		}
+		if param.Min < 1 {
+			param.Min = 1
+		}
+		if param.Baseline.IntVal < param.Min {
+			param.Baseline.IntVal = param.Min
+		}
+		if param.Min >= param.Max {
+			return nil, fmt.Errorf("unable to determine heap size range for %q, the memory limit of %dMi is too small", p.meta.Name, limit)
+		}
+	}
+
+	return []redskyv1beta1.Parameter{maxHeapParam, minHeapParam, gcParam}, nil
+}
+
+// Constraints keeps the initial heap size below the maximum heap size and the
+// maximum heap size below the container memory limit (leaving room for the
+// memory the JVM uses outside of the heap). When the container memory is also
+// being optimized, the heap is constrained relative to the memory parameter,
+// e.g. "max_heap - 0.75*memory <= 0", otherwise the heap is constrained by
+// the current memory limit.
+func (p *jvmParameter) Constraints(name ParameterNamer) ([]redskyv1beta1.Constraint, error) {
+	maxHeap := name(p.meta, p.fieldPath, "max_heap")
+	minHeap := name(p.meta, p.fieldPath, "min_heap")
+
+	heapLimit := &redskyv1beta1.SumConstraint{
+		Bound:        *resource.NewQuantity(p.memoryLimitMi()*3/4, resource.DecimalSI),
+		IsUpperBound: true,
+		Parameters: []redskyv1beta1.SumConstraintParameter{
+			{Name: maxHeap, Weight: *resource.NewQuantity(1, resource.DecimalSI)},
+		},
+	}
+
+	if p.memory != nil {
+		ind, err := p.memory.indexContainerResources()
+		if err != nil {
+			return nil, err
+		}
+
+		// Convert the memory parameter into MiB (to match the heap size) and take 3/4 of it
+		unit := ind[corev1.ResourceMemory].Unit()
+		if weight := unit.Value() * 750 / (1 << 20); weight > 0 {
+			heapLimit.Bound = *resource.NewQuantity(0, resource.DecimalSI)
+			heapLimit.Parameters = append(heapLimit.Parameters, redskyv1beta1.SumConstraintParameter{
+				Name:   name(p.memory.meta, p.memory.fieldPath, string(corev1.ResourceMemory)),
+				Weight: *resource.NewMilliQuantity(-weight, resource.DecimalSI),
+			})
+		}
+	}
+
+	return []redskyv1beta1.Constraint{
+		{
+			Name: name(p.meta, p.fieldPath, "heap_order"),
+			Order: &redskyv1beta1.OrderConstraint{
+				LowerParameter: minHeap,
+				UpperParameter: maxHeap,
+			},
+		},
+		{
+			Name: name(p.meta, p.fieldPath, "heap_limit"),
+			Sum:  heapLimit,
+		},
+	}, nil
+}
+
+// linkContainerMemory associates each JVM parameter with the container resources
+// parameter optimizing the memory of the same container.
+func linkContainerMemory(selected []interface{}) {
+	for _, sel := range selected {
+		jp, ok := sel.(*jvmParameter)
+		if !ok {
+			continue
+		}
+
+		for _, other := range selected {
+			cp, ok := other.(*containerResourcesParameter)
+			if !ok || !containsResourceName(cp.resources, corev1.ResourceMemory) {
+				continue
+			}
+
+			// The container resources are found at the "resources" field of the same container
+			if *cp.TargetRef() == *jp.TargetRef() && len(cp.fieldPath) == len(jp.fieldPath)+1 &&
+				strings.Join(cp.fieldPath[:len(jp.fieldPath)], "/") == strings.Join(jp.fieldPath, "/") {
+				jp.memory = cp
+				break
+			}
+		}
+	}
+}
+
+// memoryLimitMi returns the container memory limit in MiB.
+func (p *jvmParameter) memoryLimitMi() int64 {
+	return p.memoryLimit.Value() / (1 << 20)
+}
+
+// jvmOptionValues contains the parsed values of the JVM options.
+type jvmOptionValues struct {
+	maxHeap int64
+	minHeap int64
+	gc      string
+	other   []string
+}
+
+// parseOptions extracts the heap sizes (in MiB) and garbage collector from the
+// current JVM options, all other options are preserved in order.
+func (p *jvmParameter) parseOptions() jvmOptionValues {
+	var result jvmOptionValues
+	for _, opt := range strings.Fields(p.options) {
+		switch {
+		case strings.HasPrefix(opt, "-Xmx"):
+			result.maxHeap = parseJVMSizeMi(strings.TrimPrefix(opt, "-Xmx"))
+		case strings.HasPrefix(opt, "-Xms"):
+			result.minHeap = parseJVMSizeMi(strings.TrimPrefix(opt, "-Xms"))
+		case strings.HasPrefix(opt, "-XX:+Use") && strings.HasSuffix(opt, "GC"):
+			result.gc = strings.TrimPrefix(opt, "-XX:+Use")
+		default:
+			result.other = append(result.other, opt)
+		}
+	}
+	return result
+}
+
+// parseJVMSizeMi parses a JVM memory size (e.g. "512m" or "2g") into MiB, returning
+// zero if the size cannot be parsed.
+func parseJVMSizeMi(size string) int64 {
+	var shift uint
+	if l := len(size) - 1; l > 0 {
+		switch size[l] {
+		case 'k', 'K':
+			shift = 10
+		case 'm', 'M':
+			shift = 20
+		case 'g', 'G':
+			shift = 30
+		case 't', 'T':
+			shift = 40
+		}
+		if shift > 0 {
+			size = size[:l]
+		}
+	}
+
+	v, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return (v << shift) / (1 << 20)
+}
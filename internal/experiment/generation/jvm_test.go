@@ -0,0 +1,183 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestJVMParameterParseOptions(t *testing.T) {
+	cases := []struct {
+		desc     string
+		options  string
+		expected jvmOptionValues
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:    "heap sizes",
+			options: "-Xms64m -Xmx2g -Dfoo=bar",
+			expected: jvmOptionValues{
+				minHeap: 64,
+				maxHeap: 2048,
+				other:   []string{"-Dfoo=bar"},
+			},
+		},
+		{
+			desc:    "garbage collector",
+			options: "-XX:+UseParallelGC -XX:+ExitOnOutOfMemoryError",
+			expected: jvmOptionValues{
+				gc:    "ParallelGC",
+				other: []string{"-XX:+ExitOnOutOfMemoryError"},
+			},
+		},
+		{
+			desc:    "bytes",
+			options: "-Xmx536870912",
+			expected: jvmOptionValues{
+				maxHeap: 512,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p := &jvmParameter{options: c.options, memoryLimit: resource.MustParse("1Gi")}
+			assert.Equal(t, c.expected, p.parseOptions())
+		})
+	}
+}
+
+func TestJVMParameterParameters(t *testing.T) {
+	name := func(m yaml.ResourceMeta, path []string, name string) string { return name }
+
+	cases := []struct {
+		desc        string
+		options     string
+		memoryLimit string
+		expectedMin []int32
+		expectedErr string
+	}{
+		{
+			desc:        "defaults",
+			memoryLimit: "1Gi",
+			expectedMin: []int32{128, 16},
+		},
+		{
+			desc:        "small limit",
+			memoryLimit: "32Mi",
+			expectedMin: []int32{4, 1},
+		},
+		{
+			desc:        "zero baseline",
+			options:     "-Xms512k",
+			memoryLimit: "32Mi",
+			expectedMin: []int32{4, 1},
+		},
+		{
+			desc:        "limit too small",
+			memoryLimit: "1Mi",
+			expectedErr: `unable to determine heap size range for "test", the memory limit of 1Mi is too small`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p := &jvmParameter{
+				pnode:             pnode{meta: resourceMeta("Deployment", "test")},
+				options:           c.options,
+				memoryLimit:       resource.MustParse(c.memoryLimit),
+				garbageCollectors: []string{"G1GC"},
+			}
+			params, err := p.Parameters(name)
+			if c.expectedErr != "" {
+				assert.EqualError(t, err, c.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, params, 3) {
+				for i, expected := range c.expectedMin {
+					assert.Equal(t, expected, params[i].Min)
+					assert.Less(t, params[i].Min, params[i].Max)
+					assert.GreaterOrEqual(t, params[i].Baseline.IntVal, params[i].Min)
+				}
+			}
+		})
+	}
+}
+
+func TestJVMParameterContainerMemory(t *testing.T) {
+	jvmSel := &JVMSelector{}
+	jvmSel.Default()
+	crSel := &ContainerResourcesSelector{Resources: []corev1.ResourceName{corev1.ResourceMemory}}
+	crSel.Default()
+
+	nodes := []*yaml.RNode{yaml.MustParse(unindent(`
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: app
+      spec:
+        template:
+          spec:
+            containers:
+            - name: app
+              image: openjdk:11
+              resources:
+                limits:
+                  memory: 2Gi
+                requests:
+                  memory: 2Gi`))}
+
+	var selected []interface{}
+	for _, sel := range []interface {
+		Select([]*yaml.RNode) ([]*yaml.RNode, error)
+		Map(*yaml.RNode, yaml.ResourceMeta) ([]interface{}, error)
+	}{jvmSel, crSel} {
+		selectedNodes, err := sel.Select(nodes)
+		require.NoError(t, err)
+		for _, node := range selectedNodes {
+			meta, err := node.GetMeta()
+			require.NoError(t, err)
+			mapped, err := sel.Map(node, meta)
+			require.NoError(t, err)
+			selected = append(selected, mapped...)
+		}
+	}
+	require.Len(t, selected, 2)
+	linkContainerMemory(selected)
+
+	p, ok := selected[0].(*jvmParameter)
+	require.True(t, ok)
+	constraints, err := p.Constraints(parameterNamer(selected))
+	require.NoError(t, err)
+	if assert.Len(t, constraints, 2) {
+		assert.Equal(t, &redskyv1beta1.SumConstraint{
+			Bound:        *resource.NewQuantity(0, resource.DecimalSI),
+			IsUpperBound: true,
+			Parameters: []redskyv1beta1.SumConstraintParameter{
+				{Name: "app_max_heap", Weight: *resource.NewQuantity(1, resource.DecimalSI)},
+				{Name: "app_memory", Weight: *resource.NewMilliQuantity(-750, resource.DecimalSI)},
+			},
+		}, constraints[1].Sum)
+	}
+}
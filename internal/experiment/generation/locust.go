@@ -21,6 +21,7 @@ import (
 
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/meta"
 	"github.com/thestormforge/optimize-controller/internal/sfio"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -71,13 +72,13 @@ func (s *LocustSource) Update(exp *redskyv1beta1.Experiment) error {
 		},
 	}
 
-	// TODO We need to rethink how ingress scanning works, this just preserves existing behavior
+	// The ingress is discovered from the application resources if it is not explicitly configured
 	var ingressURL string
 	if s.Application != nil && s.Application.Ingress != nil {
 		ingressURL = s.Application.Ingress.URL
 	}
 	if ingressURL == "" {
-		return fmt.Errorf("ingress must be configured when using Locust scenarios")
+		return fmt.Errorf("ingress must be configured when using Locust scenarios (no Ingress, Route or Service was found)")
 	}
 	pod.Containers[0].Env = append(pod.Containers[0].Env, corev1.EnvVar{Name: "HOST", Value: ingressURL})
 
@@ -118,9 +119,8 @@ func (s *LocustSource) Metrics() ([]redskyv1beta1.Metric, error) {
 			// Do nothing
 
 		case goal.Latency != nil:
-			if l := s.locustLatency(goal.Latency.LatencyType); l != "" {
-				query := `scalar(` + l + `{job="trialRun",instance="{{ .Trial.Name }}"})`
-				result = append(result, newGoalMetric(goal, query))
+			if l := latencySampleName(goal.Latency.LatencyType); l != "" {
+				result = append(result, newGoalMetric(goal, pushedSampleQuery(l)))
 			}
 
 		case goal.ErrorRate != nil:
@@ -136,7 +136,7 @@ func (s *LocustSource) Metrics() ([]redskyv1beta1.Metric, error) {
 }
 
 func (s *LocustSource) locustConfigMapName() string {
-	return fmt.Sprintf("%s-locustfile", s.Scenario.Name)
+	return meta.JoinName(s.Scenario.Name, "locustfile")
 }
 
 func (s *LocustSource) locustEnv() []corev1.EnvVar {
@@ -168,7 +168,8 @@ func (s *LocustSource) locustEnv() []corev1.EnvVar {
 	return env
 }
 
-func (s *LocustSource) locustLatency(lt redskyappsv1alpha1.LatencyType) string {
+// latencySampleName returns the name of the sample pushed by Locust (or a custom scenario) for a latency type.
+func latencySampleName(lt redskyappsv1alpha1.LatencyType) string {
 	switch redskyappsv1alpha1.FixLatency(lt) {
 	case redskyappsv1alpha1.LatencyMinimum:
 		return "min_response_time"
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// PlacementSource applies the scenario placement to the trial job pod. It must be evaluated after the sources
+// which produce the trial job.
+type PlacementSource struct {
+	Scenario *redskyappsv1alpha1.Scenario
+}
+
+var _ ExperimentSource = &PlacementSource{}
+
+// Update sets the node selector, tolerations, affinity and topology spread constraints of the trial job pod.
+func (s *PlacementSource) Update(exp *redskyv1beta1.Experiment) error {
+	if s.Scenario == nil || s.Scenario.Placement == nil {
+		return nil
+	}
+
+	p := s.Scenario.Placement.DeepCopy()
+	pod := &ensureTrialJobPod(exp).Spec
+	if p.NodeSelector != nil {
+		pod.NodeSelector = p.NodeSelector
+	}
+	if p.Tolerations != nil {
+		pod.Tolerations = p.Tolerations
+	}
+	if p.Affinity != nil {
+		pod.Affinity = p.Affinity
+	}
+	if p.TopologySpreadConstraints != nil {
+		pod.TopologySpreadConstraints = p.TopologySpreadConstraints
+	}
+
+	return nil
+}
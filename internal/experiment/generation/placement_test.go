@@ -0,0 +1,48 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPlacementSource(t *testing.T) {
+	s := &PlacementSource{
+		Scenario: &redskyappsv1alpha1.Scenario{
+			Placement: &redskyappsv1alpha1.Placement{
+				NodeSelector: map[string]string{"node-role": "load"},
+				Tolerations:  []corev1.Toleration{{Key: "load", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+			},
+		},
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	ensureTrialJobPod(exp).Spec.Containers = []corev1.Container{{Name: "locust"}}
+
+	if assert.NoError(t, s.Update(exp)) {
+		pod := exp.Spec.TrialTemplate.Spec.JobTemplate.Spec.Template.Spec
+		assert.Equal(t, map[string]string{"node-role": "load"}, pod.NodeSelector)
+		assert.Equal(t, s.Scenario.Placement.Tolerations, pod.Tolerations)
+		assert.Nil(t, pod.Affinity)
+		assert.Len(t, pod.Containers, 1)
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package generation
 
 import (
+	"fmt"
+
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/sfio"
@@ -51,6 +53,9 @@ type BuiltInPrometheus struct {
 	ClusterRoleName        string
 	ServiceAccountName     string
 	ClusterRoleBindingName string
+	Prometheus             *redskyappsv1alpha1.Prometheus
+	PushGateway            bool
+	ImagePullSecrets       []corev1.LocalObjectReference
 
 	sfio.ObjectSlice
 }
@@ -60,7 +65,7 @@ var _ kio.Reader = &BuiltInPrometheus{}       // RBAC
 
 func (p *BuiltInPrometheus) Update(exp *redskyv1beta1.Experiment) error {
 	// Detect if we need built-in Prometheus by checking the generated metrics
-	var needsPrometheus bool
+	needsPrometheus := p.PushGateway
 	for _, m := range exp.Spec.Metrics {
 		if m.Type == redskyv1beta1.MetricPrometheus && m.URL == "" {
 			needsPrometheus = true
@@ -72,11 +77,26 @@ func (p *BuiltInPrometheus) Update(exp *redskyv1beta1.Experiment) error {
 		return nil
 	}
 
+	// Use an existing Prometheus instead of the setup task when one is configured
+	if p.Prometheus != nil && p.Prometheus.URL != "" {
+		if p.PushGateway {
+			return fmt.Errorf("the push gateway requires the built-in Prometheus, remove the Prometheus URL")
+		}
+		for i := range exp.Spec.Metrics {
+			m := &exp.Spec.Metrics[i]
+			if m.Type == redskyv1beta1.MetricPrometheus && m.URL == "" {
+				m.URL = p.Prometheus.URL
+			}
+		}
+		return nil
+	}
+
 	exp.Spec.TrialTemplate.Spec.SetupServiceAccountName = p.ServiceAccountName
 	exp.Spec.TrialTemplate.Spec.SetupTasks = append(exp.Spec.TrialTemplate.Spec.SetupTasks,
 		redskyv1beta1.SetupTask{
-			Name: p.SetupTaskName,
-			Args: []string{"prometheus", "$(MODE)"},
+			Name:       p.SetupTaskName,
+			Args:       []string{"prometheus", "$(MODE)"},
+			Prometheus: p.setup(),
 		})
 
 	p.ObjectSlice = append(p.ObjectSlice,
@@ -84,6 +104,7 @@ func (p *BuiltInPrometheus) Update(exp *redskyv1beta1.Experiment) error {
 			ObjectMeta: metav1.ObjectMeta{
 				Name: p.ServiceAccountName,
 			},
+			ImagePullSecrets: p.ImagePullSecrets,
 		},
 
 		&rbacv1.ClusterRole{
@@ -142,3 +163,17 @@ func (p *BuiltInPrometheus) Update(exp *redskyv1beta1.Experiment) error {
 
 	return nil
 }
+
+// setup returns the configuration of the built-in Prometheus setup task.
+func (p *BuiltInPrometheus) setup() *redskyv1beta1.PrometheusSetup {
+	if p.Prometheus == nil {
+		return nil
+	}
+
+	return &redskyv1beta1.PrometheusSetup{
+		ScrapeInterval:     p.Prometheus.ScrapeInterval,
+		Retention:          p.Prometheus.Retention,
+		Resources:          p.Prometheus.Resources,
+		ExtraScrapeConfigs: p.Prometheus.ExtraScrapeConfigs,
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+func TestBuiltInPrometheus(t *testing.T) {
+	cases := []struct {
+		desc               string
+		prometheus         *redskyappsv1alpha1.Prometheus
+		pushGateway        bool
+		expectedURL        string
+		expectedSetupTasks int
+		expectedError      bool
+	}{
+		{
+			desc:               "built-in",
+			expectedSetupTasks: 1,
+		},
+		{
+			desc:        "existing",
+			prometheus:  &redskyappsv1alpha1.Prometheus{URL: "http://prometheus-operated.monitoring:9090"},
+			expectedURL: "http://prometheus-operated.monitoring:9090",
+		},
+		{
+			desc:          "existing with push gateway",
+			prometheus:    &redskyappsv1alpha1.Prometheus{URL: "http://prometheus-operated.monitoring:9090"},
+			pushGateway:   true,
+			expectedError: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			exp := &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					Metrics: []redskyv1beta1.Metric{
+						{Name: "cost", Type: redskyv1beta1.MetricPrometheus},
+						{Name: "latency", Type: redskyv1beta1.MetricPrometheus, URL: "http://example.com"},
+					},
+				},
+			}
+
+			p := &BuiltInPrometheus{SetupTaskName: "monitoring", Prometheus: c.prometheus, PushGateway: c.pushGateway}
+			err := p.Update(exp)
+			if c.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expectedURL, exp.Spec.Metrics[0].URL)
+				assert.Equal(t, "http://example.com", exp.Spec.Metrics[1].URL)
+				assert.Len(t, exp.Spec.TrialTemplate.Spec.SetupTasks, c.expectedSetupTasks)
+				assert.Equal(t, c.expectedSetupTasks > 0, len(p.ObjectSlice) > 0)
+			}
+		})
+	}
+}
@@ -31,6 +31,10 @@ type ReplicaSelector struct {
 	Path string `json:"path,omitempty"`
 	// Create container resource specifications even if the original object does not contain them.
 	CreateIfNotPresent bool `json:"create,omitempty"`
+	// The minimum number of replicas.
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// The maximum number of replicas.
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
 }
 
 var _ scan.Selector = &ReplicaSelector{}
@@ -43,6 +47,12 @@ func (s *ReplicaSelector) Default() {
 	if s.Path == "" {
 		s.Path = "/spec/replicas"
 	}
+	if s.MinReplicas <= 0 {
+		s.MinReplicas = 1
+	}
+	if s.MaxReplicas <= 0 {
+		s.MaxReplicas = 5
+	}
 }
 
 func (s *ReplicaSelector) Map(node *yaml.RNode, meta yaml.ResourceMeta) ([]interface{}, error) {
@@ -64,11 +74,15 @@ func (s *ReplicaSelector) Map(node *yaml.RNode, meta yaml.ResourceMeta) ([]inter
 				value = &yaml.Node{Kind: yaml.ScalarNode, Value: "1"}
 			}
 
-			result = append(result, &replicaParameter{pnode: pnode{
-				meta:      meta,
-				fieldPath: node.FieldPath(),
-				value:     value,
-			}})
+			result = append(result, &replicaParameter{
+				pnode: pnode{
+					meta:      meta,
+					fieldPath: node.FieldPath(),
+					value:     value,
+				},
+				min: s.MinReplicas,
+				max: s.MaxReplicas,
+			})
 
 			return node, nil
 		}))
@@ -76,6 +90,8 @@ func (s *ReplicaSelector) Map(node *yaml.RNode, meta yaml.ResourceMeta) ([]inter
 
 type replicaParameter struct {
 	pnode
+	min int32
+	max int32
 }
 
 var _ PatchSource = &replicaParameter{}
@@ -100,9 +116,18 @@ func (p *replicaParameter) Parameters(name ParameterNamer) ([]redskyv1beta1.Para
 	}
 
 	baselineReplicas := intstr.FromInt(v)
-	var minReplicas, maxReplicas int32 = 1, 5
+	minReplicas, maxReplicas := p.min, p.max
+	if minReplicas <= 0 {
+		minReplicas = 1
+	}
+	if maxReplicas <= minReplicas {
+		maxReplicas = minReplicas + 1
+	}
 
-	// Only adjust the max replica count if necessary
+	// Only adjust the replica range if necessary to include the baseline
+	if baselineReplicas.IntVal < minReplicas {
+		minReplicas = baselineReplicas.IntVal
+	}
 	if baselineReplicas.IntVal > maxReplicas {
 		maxReplicas = baselineReplicas.IntVal
 	}
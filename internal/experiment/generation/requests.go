@@ -31,6 +31,8 @@ const requestsQueryFormat = `({{ cpuRequests . %q }} * %d) + ({{ memoryRequests
 
 type RequestsMetricsSource struct {
 	Goal *redskyappsv1alpha1.Goal
+	// The namespaces of the application, requests are measured across all of them when there is more than one
+	Namespaces []string
 }
 
 var _ MetricSource = &RequestsMetricsSource{}
@@ -51,7 +53,8 @@ func (s *RequestsMetricsSource) Metrics() ([]redskyv1beta1.Metric, error) {
 		memoryWeight = &zero
 	}
 
-	query := fmt.Sprintf(requestsQueryFormat, s.Goal.Requests.Selector, cpuWeight.Value(), s.Goal.Requests.Selector, memoryWeight.Value())
+	selector := s.selector()
+	query := fmt.Sprintf(requestsQueryFormat, selector, cpuWeight.Value(), selector, memoryWeight.Value())
 	result = append(result, newGoalMetric(s.Goal, query))
 
 	// If the name contains "cost" and the weights are non-zero, add non-optimized metrics for each request
@@ -64,13 +67,27 @@ func (s *RequestsMetricsSource) Metrics() ([]redskyv1beta1.Metric, error) {
 			newGoalMetric(&redskyappsv1alpha1.Goal{
 				Name:     result[0].Name + "-cpu-requests",
 				Optimize: &nonOptimized,
-			}, fmt.Sprintf("{{ cpuRequests . %q }}", s.Goal.Requests.Selector)),
+			}, fmt.Sprintf("{{ cpuRequests . %q }}", selector)),
 			newGoalMetric(&redskyappsv1alpha1.Goal{
 				Name:     result[0].Name + "-memory-requests",
 				Optimize: &nonOptimized,
-			}, fmt.Sprintf("{{ memoryRequests . %q | GB }}", s.Goal.Requests.Selector)),
+			}, fmt.Sprintf("{{ memoryRequests . %q | GB }}", selector)),
 		)
 	}
 
 	return result, nil
 }
+
+// selector returns the label selector for the pods whose requests are measured.
+func (s *RequestsMetricsSource) selector() string {
+	if len(s.Namespaces) < 2 {
+		return s.Goal.Requests.Selector
+	}
+
+	// Measure the end-to-end requests across all of the application namespaces
+	sel := fmt.Sprintf("namespace in (%s)", strings.Join(s.Namespaces, ","))
+	if s.Goal.Requests.Selector != "" {
+		sel = s.Goal.Requests.Selector + "," + sel
+	}
+	return sel
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// ScenarioResourcesSource applies the scenario compute resources to the trial job and setup task containers. It
+// must be evaluated after the sources which produce the trial job and setup tasks.
+type ScenarioResourcesSource struct {
+	Scenario *redskyappsv1alpha1.Scenario
+}
+
+var _ ExperimentSource = &ScenarioResourcesSource{}
+
+// Update sets the resources of any trial job or setup task container which does not already specify resources.
+func (s *ScenarioResourcesSource) Update(exp *redskyv1beta1.Experiment) error {
+	if s.Scenario == nil {
+		return nil
+	}
+
+	// Only generated trial jobs are updated, the default trial job just sleeps
+	if r := s.Scenario.Resources; r != nil && exp.Spec.TrialTemplate.Spec.JobTemplate != nil {
+		pod := ensureTrialJobPod(exp)
+		for i := range pod.Spec.Containers {
+			c := &pod.Spec.Containers[i]
+			if len(c.Resources.Limits) == 0 && len(c.Resources.Requests) == 0 {
+				r.DeepCopyInto(&c.Resources)
+			}
+		}
+	}
+
+	if r := s.Scenario.SetupResources; r != nil {
+		for i := range exp.Spec.TrialTemplate.Spec.SetupTasks {
+			task := &exp.Spec.TrialTemplate.Spec.SetupTasks[i]
+			if task.Resources == nil {
+				task.Resources = r.DeepCopy()
+			}
+		}
+	}
+
+	return nil
+}
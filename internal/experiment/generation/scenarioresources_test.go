@@ -0,0 +1,67 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestScenarioResourcesSource(t *testing.T) {
+	trialResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	setupResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}
+	explicitResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+	}
+
+	s := &ScenarioResourcesSource{
+		Scenario: &redskyappsv1alpha1.Scenario{
+			Resources:      &trialResources,
+			SetupResources: &setupResources,
+		},
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	ensureTrialJobPod(exp).Spec.Containers = []corev1.Container{
+		{Name: "locust"},
+		{Name: "sidecar", Resources: explicitResources},
+	}
+	exp.Spec.TrialTemplate.Spec.SetupTasks = []redskyv1beta1.SetupTask{
+		{Name: "monitoring"},
+		{Name: "explicit", Resources: explicitResources.DeepCopy()},
+	}
+
+	if assert.NoError(t, s.Update(exp)) {
+		pod := exp.Spec.TrialTemplate.Spec.JobTemplate.Spec.Template.Spec
+		assert.Equal(t, trialResources, pod.Containers[0].Resources)
+		assert.Equal(t, explicitResources, pod.Containers[1].Resources)
+
+		tasks := exp.Spec.TrialTemplate.Spec.SetupTasks
+		assert.Equal(t, &setupResources, tasks[0].Resources)
+		assert.Equal(t, &explicitResources, tasks[1].Resources)
+	}
+}
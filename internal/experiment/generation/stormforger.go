@@ -25,6 +25,7 @@ import (
 	"github.com/pelletier/go-toml"
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/meta"
 	"github.com/thestormforge/optimize-controller/internal/sfio"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -215,7 +216,7 @@ func (s *StormForgerSource) stormForgerTestCase() (org, name string) {
 }
 
 func (s *StormForgerSource) stormForgerConfigMapName() string {
-	return fmt.Sprintf("%s-test-case-file", s.Scenario.Name)
+	return meta.JoinName(s.Scenario.Name, "test-case-file")
 }
 
 // stormForgerAccessToken returns the effective access token information.
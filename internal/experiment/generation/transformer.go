@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/scan"
@@ -56,6 +57,12 @@ type PatchSource interface {
 	Patch(name ParameterNamer) (yaml.Filter, error)
 }
 
+// ConstraintSource allows selectors to restrict the domain of the parameters
+// they contribute to an experiment.
+type ConstraintSource interface {
+	Constraints(name ParameterNamer) ([]redskyv1beta1.Constraint, error)
+}
+
 // MetricSource allows selectors to contribute metrics to an experiment.
 type MetricSource interface {
 	Metrics() ([]redskyv1beta1.Metric, error)
@@ -77,6 +84,9 @@ func (t *Transformer) Transform(nodes []*yaml.RNode, selected []interface{}) ([]
 	// Parameter names need to be computed based on what resources were selected by the scan
 	name := parameterNamer(selected)
 
+	// Some constraints span the output of multiple selectors
+	linkContainerMemory(selected)
+
 	// Start with a new experiment and collect the scan results into it
 	exp := redskyv1beta1.Experiment{}
 	patches := make(map[corev1.ObjectReference][]yaml.Filter)
@@ -97,6 +107,14 @@ func (t *Transformer) Transform(nodes []*yaml.RNode, selected []interface{}) ([]
 			exp.Spec.Parameters = append(exp.Spec.Parameters, params...)
 		}
 
+		if cs, ok := sel.(ConstraintSource); ok {
+			constraints, err := cs.Constraints(name)
+			if err != nil {
+				return nil, err
+			}
+			exp.Spec.Constraints = append(exp.Spec.Constraints, constraints...)
+		}
+
 		if ps, ok := sel.(PatchSource); ok {
 			ref := ps.TargetRef()
 			f, err := ps.Patch(name)
@@ -234,13 +252,25 @@ func (p *pnode) TargetRef() *corev1.ObjectReference {
 	}
 }
 
+// templateIdentifier matches parameter names which can be referenced from a patch template.
+var templateIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// checkParameterName returns an error if the parameter name cannot be referenced from a patch template.
+func checkParameterName(name string) error {
+	if !templateIdentifier.MatchString(name) {
+		return fmt.Errorf("invalid parameter name %q, the name cannot be used in a patch template", name)
+	}
+	return nil
+}
+
 // parameterNamer returns a name generation function for parameters based on scan results.
 func parameterNamer(selected []interface{}) ParameterNamer {
-	// Index the object references by kind and name
+	// Index the object references by kind and namespace qualified name
 	type targeted interface {
 		TargetRef() *corev1.ObjectReference
 	}
 	needsPath := make(map[string]map[string]int)
+	namespaces := make(map[string]bool)
 	for _, sel := range selected {
 		t, ok := sel.(targeted)
 		if !ok {
@@ -250,10 +280,12 @@ func parameterNamer(selected []interface{}) ParameterNamer {
 		if ns := needsPath[targetRef.Kind]; ns == nil {
 			needsPath[targetRef.Kind] = make(map[string]int)
 		}
-		needsPath[targetRef.Kind][targetRef.Name]++
+		needsPath[targetRef.Kind][targetRef.Namespace+"/"+targetRef.Name]++
+		namespaces[targetRef.Namespace] = true
 	}
 
 	// Determine which prefixes we need
+	needsNamespace := len(namespaces) > 1
 	needsKind := len(needsPath) > 1
 	needsName := false
 	for _, v := range needsPath {
@@ -263,6 +295,10 @@ func parameterNamer(selected []interface{}) ParameterNamer {
 	return func(meta yaml.ResourceMeta, path []string, name string) string {
 		var parts []string
 
+		if needsNamespace && meta.Namespace != "" {
+			parts = append(parts, meta.Namespace)
+		}
+
 		if needsKind {
 			parts = append(parts, meta.Kind)
 		}
@@ -271,7 +307,7 @@ func parameterNamer(selected []interface{}) ParameterNamer {
 			parts = append(parts, meta.Name)
 		}
 
-		if needsPath[meta.Kind][meta.Name] > 1 {
+		if needsPath[meta.Kind][meta.Namespace+"/"+meta.Name] > 1 {
 			for _, p := range path {
 				if yaml.IsListIndex(p) {
 					if _, value, _ := yaml.SplitIndexNameValue(p); value != "" {
@@ -288,9 +324,15 @@ func parameterNamer(selected []interface{}) ParameterNamer {
 		// Explainer: Parameter names are used in Go Templates which are executed
 		// against Go structs, if the template parser encounters a token that is
 		// not a valid Go field name, parsing fails (e.g. "bad character U+002D '-'").
+		// This includes extended resource names like "nvidia.com/gpu".
 
 		parameterName := strings.Join(parts, "_")
-		parameterName = strings.ReplaceAll(parameterName, "-", "_")
+		parameterName = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, parameterName)
 		parameterName = strings.ToLower(parameterName)
 		return parameterName
 	}
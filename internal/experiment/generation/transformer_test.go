@@ -20,6 +20,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -37,7 +40,7 @@ func TestParameterNames(t *testing.T) {
 			desc: "one deployment one container",
 			selected: []pnode{
 				{
-					meta:      meta("Deployment", "test"),
+					meta:      resourceMeta("Deployment", "test"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test]", "resources"},
 				},
 			},
@@ -51,11 +54,11 @@ func TestParameterNames(t *testing.T) {
 			desc: "one deployment two containers",
 			selected: []pnode{
 				{
-					meta:      meta("Deployment", "test"),
+					meta:      resourceMeta("Deployment", "test"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test1]", "resources"},
 				},
 				{
-					meta:      meta("Deployment", "test"),
+					meta:      resourceMeta("Deployment", "test"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test2]", "resources"},
 				},
 			},
@@ -71,11 +74,11 @@ func TestParameterNames(t *testing.T) {
 			desc: "two deployments one container",
 			selected: []pnode{
 				{
-					meta:      meta("Deployment", "test1"),
+					meta:      resourceMeta("Deployment", "test1"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test]", "resources"},
 				},
 				{
-					meta:      meta("Deployment", "test2"),
+					meta:      resourceMeta("Deployment", "test2"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test]", "resources"},
 				},
 			},
@@ -91,15 +94,15 @@ func TestParameterNames(t *testing.T) {
 			desc: "two deployments two containers",
 			selected: []pnode{
 				{
-					meta:      meta("Deployment", "test1"),
+					meta:      resourceMeta("Deployment", "test1"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test1]", "resources"},
 				},
 				{
-					meta:      meta("Deployment", "test1"),
+					meta:      resourceMeta("Deployment", "test1"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test2]", "resources"},
 				},
 				{
-					meta:      meta("Deployment", "test2"),
+					meta:      resourceMeta("Deployment", "test2"),
 					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test]", "resources"},
 				},
 			},
@@ -112,6 +115,26 @@ func TestParameterNames(t *testing.T) {
 				"test2_memory",
 			},
 		},
+
+		{
+			desc: "two namespaces",
+			selected: []pnode{
+				{
+					meta:      namespacedMeta("Deployment", "frontend", "test"),
+					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test]", "resources"},
+				},
+				{
+					meta:      namespacedMeta("Deployment", "backend", "test"),
+					fieldPath: []string{"spec", "template", "spec", "containers", "[name=test]", "resources"},
+				},
+			},
+			expected: []string{
+				"frontend_test_cpu",
+				"frontend_test_memory",
+				"backend_test_cpu",
+				"backend_test_memory",
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
@@ -131,7 +154,7 @@ func TestParameterNames(t *testing.T) {
 	}
 }
 
-func meta(kind, name string) yaml.ResourceMeta {
+func resourceMeta(kind, name string) yaml.ResourceMeta {
 	return yaml.ResourceMeta{
 		TypeMeta: yaml.TypeMeta{
 			Kind: kind,
@@ -143,3 +166,47 @@ func meta(kind, name string) yaml.ResourceMeta {
 		},
 	}
 }
+
+func namespacedMeta(kind, namespace, name string) yaml.ResourceMeta {
+	m := resourceMeta(kind, name)
+	m.Namespace = namespace
+	return m
+}
+
+func TestCredentialsSource(t *testing.T) {
+	app := &redskyappsv1alpha1.Application{
+		Credentials: []redskyappsv1alpha1.Credential{
+			{
+				Name:         "API_TOKEN",
+				SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "load-test"}, Key: "token"},
+			},
+			{
+				Usage:        redskyappsv1alpha1.CredentialMetrics,
+				SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "metrics"}},
+			},
+		},
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	ensureTrialJobPod(exp).Spec.Containers = []corev1.Container{{Name: "test"}}
+	exp.Spec.Metrics = []redskyv1beta1.Metric{
+		{Name: "built-in", Type: redskyv1beta1.MetricPrometheus},
+		{Name: "external", Type: redskyv1beta1.MetricPrometheus, URL: "http://prometheus:9090"},
+		{Name: "datadog", Type: redskyv1beta1.MetricDatadog},
+		{Name: "kubernetes"},
+	}
+
+	err := (&CredentialsSource{Application: app}).Update(exp)
+	if assert.NoError(t, err) {
+		env := ensureTrialJobPod(exp).Spec.Containers[0].Env
+		if assert.Len(t, env, 1) {
+			assert.Equal(t, "API_TOKEN", env[0].Name)
+			assert.Equal(t, "load-test", env[0].ValueFrom.SecretKeyRef.Name)
+		}
+
+		assert.Nil(t, exp.Spec.Metrics[0].Target)
+		assert.Equal(t, &redskyv1beta1.ResourceTarget{APIVersion: "v1", Kind: "Secret", Name: "metrics"}, exp.Spec.Metrics[1].Target)
+		assert.Equal(t, &redskyv1beta1.ResourceTarget{APIVersion: "v1", Kind: "Secret", Name: "metrics"}, exp.Spec.Metrics[2].Target)
+		assert.Nil(t, exp.Spec.Metrics[3].Target)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TrialNamespaceSource stamps the resource quota and limit range the application declares into each namespace the
+// trials run in using the experiment namespace template. When the application resources already include a resource
+// quota or limit range, the existing objects are validated against the declared constraints instead.
+type TrialNamespaceSource struct {
+	Application    *redskyappsv1alpha1.Application
+	ResourceQuotas []corev1.ResourceQuota
+	LimitRanges    []corev1.LimitRange
+}
+
+var _ ExperimentSource = &TrialNamespaceSource{}
+
+// Update adds the declared constraints to the namespace template of the experiment.
+func (s *TrialNamespaceSource) Update(exp *redskyv1beta1.Experiment) error {
+	if s.Application == nil || s.Application.TrialNamespace == nil {
+		return nil
+	}
+	tn := s.Application.TrialNamespace
+
+	existing := true
+	if tn.ResourceQuota != nil {
+		if len(s.ResourceQuotas) == 0 {
+			existing = false
+		} else if err := checkResourceQuota(tn.ResourceQuota, s.ResourceQuotas); err != nil {
+			return err
+		}
+	}
+	if tn.LimitRange != nil {
+		if len(s.LimitRanges) == 0 {
+			existing = false
+		} else if err := checkLimitRange(tn.LimitRange, s.LimitRanges); err != nil {
+			return err
+		}
+	}
+	if existing {
+		return nil
+	}
+
+	// The created namespaces are not matched by a selector, so each trial gets a new namespace which is removed once
+	// the trial is finished
+	if exp.Spec.NamespaceTemplate == nil {
+		exp.Spec.NamespaceTemplate = &redskyv1beta1.NamespaceTemplateSpec{DeleteAfterTrial: true}
+	}
+	exp.Spec.NamespaceTemplate.ResourceQuota = tn.ResourceQuota.DeepCopy()
+	exp.Spec.NamespaceTemplate.LimitRange = tn.LimitRange.DeepCopy()
+	return nil
+}
+
+// checkResourceQuota ensures the existing resource quotas are at least as restrictive as the declared quota.
+func checkResourceQuota(declared *corev1.ResourceQuotaSpec, existing []corev1.ResourceQuota) error {
+	for name, limit := range declared.Hard {
+		var hard []corev1.ResourceList
+		for i := range existing {
+			hard = append(hard, existing[i].Spec.Hard)
+		}
+
+		if err := checkMaximum(name, limit, hard); err != nil {
+			return fmt.Errorf("existing resource quota does not satisfy the trial namespace: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkLimitRange ensures the existing limit ranges are at least as restrictive as the declared limit range.
+func checkLimitRange(declared *corev1.LimitRangeSpec, existing []corev1.LimitRange) error {
+	for _, item := range declared.Limits {
+		var max []corev1.ResourceList
+		for i := range existing {
+			for _, e := range existing[i].Spec.Limits {
+				if e.Type == item.Type {
+					max = append(max, e.Max)
+				}
+			}
+		}
+
+		for name, limit := range item.Max {
+			if err := checkMaximum(name, limit, max); err != nil {
+				return fmt.Errorf("existing limit range does not satisfy the trial namespace %s limits: %w", item.Type, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkMaximum ensures at least one of the supplied resource lists limits the named resource to the specified value.
+func checkMaximum(name corev1.ResourceName, limit resource.Quantity, lists []corev1.ResourceList) error {
+	var found *resource.Quantity
+	for _, l := range lists {
+		if q, ok := l[name]; ok && (found == nil || q.Cmp(*found) < 0) {
+			found = &q
+		}
+	}
+
+	if found == nil {
+		return fmt.Errorf("%s is not limited (expected at most %s)", name, limit.String())
+	}
+	if found.Cmp(limit) > 0 {
+		return fmt.Errorf("%s is limited to %s (expected at most %s)", name, found.String(), limit.String())
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestTrialNamespaceSource(t *testing.T) {
+	app := &redskyappsv1alpha1.Application{
+		TrialNamespace: &redskyappsv1alpha1.TrialNamespace{
+			ResourceQuota: &corev1.ResourceQuotaSpec{
+				Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("8")},
+			},
+			LimitRange: &corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{
+					{Type: corev1.LimitTypeContainer, Max: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		desc           string
+		resourceQuotas []corev1.ResourceQuota
+		limitRanges    []corev1.LimitRange
+		expected       *redskyv1beta1.NamespaceTemplateSpec
+		expectedErr    string
+	}{
+		{
+			desc: "create",
+			expected: &redskyv1beta1.NamespaceTemplateSpec{
+				ResourceQuota:    app.TrialNamespace.ResourceQuota,
+				LimitRange:       app.TrialNamespace.LimitRange,
+				DeleteAfterTrial: true,
+			},
+		},
+		{
+			desc: "existing",
+			resourceQuotas: []corev1.ResourceQuota{
+				{Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("4")}}},
+			},
+			limitRanges: []corev1.LimitRange{
+				{Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+					{Type: corev1.LimitTypeContainer, Max: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}},
+				}}},
+			},
+		},
+		{
+			desc: "existing quota too large",
+			resourceQuotas: []corev1.ResourceQuota{
+				{Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("16")}}},
+			},
+			expectedErr: "existing resource quota does not satisfy the trial namespace: limits.cpu is limited to 16 (expected at most 8)",
+		},
+		{
+			desc: "existing limit range missing resource",
+			limitRanges: []corev1.LimitRange{
+				{Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+					{Type: corev1.LimitTypePod, Max: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}},
+				}}},
+			},
+			expectedErr: "existing limit range does not satisfy the trial namespace Container limits: memory is not limited (expected at most 4Gi)",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			s := &TrialNamespaceSource{Application: app, ResourceQuotas: c.resourceQuotas, LimitRanges: c.limitRanges}
+			exp := &redskyv1beta1.Experiment{}
+			err := s.Update(exp)
+			if c.expectedErr != "" {
+				assert.EqualError(t, err, c.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, exp.Spec.NamespaceTemplate)
+			}
+		})
+	}
+}
@@ -20,8 +20,10 @@ import (
 	"fmt"
 
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/application"
 	"github.com/thestormforge/optimize-controller/internal/experiment/generation"
+	"github.com/thestormforge/optimize-controller/internal/meta"
 	"github.com/thestormforge/optimize-controller/internal/scan"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
@@ -40,6 +42,8 @@ type Generator struct {
 	Objective string
 	// IncludeApplicationResources is a flag indicating that the application resources should be included in the output.
 	IncludeApplicationResources bool
+	// LiveBaseline is a flag indicating that parameter baselines should come from the live cluster state.
+	LiveBaseline bool
 	// Configure the filter options.
 	scan.FilterOptions
 }
@@ -78,6 +82,19 @@ func (g *Generator) Execute(output kio.Writer) error {
 			// Expand resource references using Konjure
 			g.FilterOptions.NewFilter(application.WorkingDirectory(&g.Application)),
 
+			// Remove any resources the application excludes
+			application.ExcludeFilter(g.Application.Excludes),
+
+			// Use the live cluster state for the baseline values
+			&generation.LiveBaselineFilter{
+				Disabled:  !g.LiveBaseline,
+				Namespace: g.Application.Namespace,
+				Kubectl:   g.FilterOptions.Kubectl,
+			},
+
+			// Discover the ingress if it was not explicitly configured
+			application.DiscoverIngress(&g.Application, false),
+
 			// Scan the resources and transform them into an experiment (and it's supporting resources)
 			&scan.Scanner{
 				Transformer: &generation.Transformer{
@@ -125,6 +142,9 @@ func (g *Generator) selectors() []scan.Selector {
 					LabelSelector: g.Application.Parameters[i].ContainerResources.Selector,
 				},
 				Resources:          g.Application.Parameters[i].ContainerResources.Resources,
+				Mode:               g.Application.Parameters[i].ContainerResources.Mode,
+				LimitRequestRatio:  g.Application.Parameters[i].ContainerResources.LimitRequestRatio,
+				MaxTotal:           g.Application.Parameters[i].ContainerResources.MaxTotal,
 				CreateIfNotPresent: true,
 			})
 
@@ -134,6 +154,8 @@ func (g *Generator) selectors() []scan.Selector {
 					LabelSelector: g.Application.Parameters[i].Replicas.Selector,
 				},
 				CreateIfNotPresent: true,
+				MinReplicas:        g.Application.Parameters[i].Replicas.Min,
+				MaxReplicas:        g.Application.Parameters[i].Replicas.Max,
 			})
 
 		case g.Application.Parameters[i].EnvironmentVariable != nil:
@@ -146,6 +168,24 @@ func (g *Generator) selectors() []scan.Selector {
 				ValueSuffix:  g.Application.Parameters[i].EnvironmentVariable.Suffix,
 				Values:       g.Application.Parameters[i].EnvironmentVariable.Values,
 			})
+
+		case g.Application.Parameters[i].JVM != nil:
+			result = append(result, &generation.JVMSelector{
+				GenericSelector: scan.GenericSelector{
+					LabelSelector: g.Application.Parameters[i].JVM.Selector,
+				},
+				VariableName:      g.Application.Parameters[i].JVM.VariableName,
+				GarbageCollectors: g.Application.Parameters[i].JVM.GarbageCollectors,
+			})
+
+		case g.Application.Parameters[i].ConfigMap != nil:
+			result = append(result, &generation.ConfigMapSelector{
+				GenericSelector: scan.GenericSelector{
+					LabelSelector: g.Application.Parameters[i].ConfigMap.Selector,
+				},
+				ConfigMapName: g.Application.Parameters[i].ConfigMap.Name,
+				Keys:          g.Application.Parameters[i].ConfigMap.Keys,
+			})
 		}
 
 	}
@@ -166,7 +206,19 @@ func (g *Generator) selectors() []scan.Selector {
 }
 
 // validate is basically just a hook to perform final verifications before actually emitting anything.
-func (g *Generator) validate([]*yaml.RNode) error {
+func (g *Generator) validate(nodes []*yaml.RNode) error {
+	// Experiment names are also used as label values so they must be valid DNS labels
+	for _, node := range nodes {
+		m, err := node.GetMeta()
+		if err != nil {
+			return err
+		}
+		if m.Kind == "Experiment" && m.APIVersion == redskyv1beta1.GroupVersion.String() {
+			if err := meta.ValidateName(m.Name); err != nil {
+				return fmt.Errorf("generated experiment name is invalid: %w", err)
+			}
+		}
+	}
 
 	objective, err := application.GetObjective(&g.Application, g.Objective)
 	if err != nil {
@@ -22,6 +22,7 @@ import (
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/trial"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,20 +31,37 @@ import (
 
 // NextTrialNamespace searches for or creates a new namespace to run a new trial in, returning an empty string if no such namespace can be found
 func NextTrialNamespace(ctx context.Context, c client.Client, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (string, error) {
+	namespaces, err := NextTrialNamespaces(ctx, c, exp, trialList, 1)
+	if err != nil || len(namespaces) == 0 {
+		return "", err
+	}
+	return namespaces[0], nil
+}
+
+// NextTrialNamespaces searches for or creates up to `limit` distinct namespaces to run new trials in, the number of
+// namespaces returned is also limited by the number of desired replicas that are not currently running a trial
+func NextTrialNamespaces(ctx context.Context, c client.Client, exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList, limit int32) ([]string, error) {
 	// Determine which namespaces have an active trial
 	activeNamespaces := make(map[string]bool, len(trialList.Items))
 	activeTrials := int32(0)
+	deleteAfterTrial := exp.Spec.NamespaceTemplate != nil && exp.Spec.NamespaceTemplate.DeleteAfterTrial
 	for i := range trialList.Items {
 		t := &trialList.Items[i]
 		if trial.IsActive(t) {
 			activeNamespaces[t.Namespace] = true
 			activeTrials++
+		} else if deleteAfterTrial {
+			// Namespaces are never reused if they are going to be deleted
+			activeNamespaces[t.Namespace] = true
 		}
 	}
 
 	// Check the number of desired replicas
 	if activeTrials >= exp.Replicas() || exp.Status.ActiveTrials != activeTrials {
-		return "", nil
+		return nil, nil
+	}
+	if free := exp.Replicas() - activeTrials; limit > free {
+		limit = free
 	}
 
 	// Match the potential namespaces
@@ -58,29 +76,40 @@ func NextTrialNamespace(ctx context.Context, c client.Client, exp *redskyv1beta1
 		// Match the (possibly nil) namespace selector
 		s, err := metav1.LabelSelectorAsSelector(exp.Spec.NamespaceSelector)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		selector = client.MatchingLabelsSelector{Selector: s}
 	}
 
-	// Find the first available namespace from the list
+	// Find the first available namespaces from the list
+	var namespaces []string
 	namespaceList := &corev1.NamespaceList{}
 	if err := c.List(ctx, namespaceList, selector); err != nil {
-		return "", err
+		return nil, err
 	}
 	for i := range namespaceList.Items {
-		if n := namespaceList.Items[i].Name; !activeNamespaces[n] {
-			return n, nil
+		n := &namespaceList.Items[i]
+		if int32(len(namespaces)) >= limit {
+			break
+		}
+		if !activeNamespaces[n.Name] && n.Status.Phase != corev1.NamespaceTerminating {
+			namespaces = append(namespaces, n.Name)
 		}
 	}
 
-	// If we could not find a namespace, we may be able to create it
-	if exp.Spec.NamespaceTemplate != nil {
-		return createNamespaceFromTemplate(ctx, c, exp)
+	// If we could not find enough namespaces, we may be able to create them
+	for exp.Spec.NamespaceTemplate != nil && int32(len(namespaces)) < limit {
+		name, err := createNamespaceFromTemplate(ctx, c, exp)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			break
+		}
+		namespaces = append(namespaces, name)
 	}
 
-	// No namespace is available
-	return "", nil
+	return namespaces, nil
 }
 
 func ignorePermissions(err error) error {
@@ -107,7 +136,7 @@ func createNamespaceFromTemplate(ctx context.Context, c client.Client, exp *reds
 	n.Labels[redskyv1beta1.LabelExperiment] = exp.Name
 	n.Labels[redskyv1beta1.LabelTrialRole] = "trialSetup"
 
-	// TODO We should also record the fact that we created the namespace for possible clean up later
+	// NOTE: The labels also record the fact that we created the namespace so it can be deleted later
 
 	// NOTE: The ignorePermission call is in different places for the namespace and supporting objects because
 	// if the namespace creation fails we cannot continue creating the supporting objects
@@ -137,6 +166,21 @@ func createNamespaceFromTemplate(ctx context.Context, c client.Client, exp *reds
 			return "", err
 		}
 	}
+	if ts.ResourceQuota != nil {
+		if err := c.Create(ctx, ts.ResourceQuota); ignorePermissions(err) != nil {
+			return "", err
+		}
+	}
+	if ts.LimitRange != nil {
+		if err := c.Create(ctx, ts.LimitRange); ignorePermissions(err) != nil {
+			return "", err
+		}
+	}
+	if ts.NetworkPolicy != nil {
+		if err := c.Create(ctx, ts.NetworkPolicy); ignorePermissions(err) != nil {
+			return "", err
+		}
+	}
 
 	return n.Name, nil
 }
@@ -146,6 +190,9 @@ type trialNamespace struct {
 	ServiceAccount *corev1.ServiceAccount
 	Role           *rbacv1.Role
 	RoleBindings   []rbacv1.RoleBinding
+	ResourceQuota  *corev1.ResourceQuota
+	LimitRange     *corev1.LimitRange
+	NetworkPolicy  *networkingv1.NetworkPolicy
 }
 
 func createTrialNamespace(exp *redskyv1beta1.Experiment, namespace string) *trialNamespace {
@@ -210,6 +257,35 @@ func createTrialNamespace(exp *redskyv1beta1.Experiment, namespace string) *tria
 		})
 	}
 
+	// Add the resource quota, limit range and network policy from the namespace template
+	if nt := exp.Spec.NamespaceTemplate; nt != nil && nt.ResourceQuota != nil {
+		ts.ResourceQuota = &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "redsky-trial-quota",
+				Namespace: namespace,
+			},
+		}
+		nt.ResourceQuota.DeepCopyInto(&ts.ResourceQuota.Spec)
+	}
+	if nt := exp.Spec.NamespaceTemplate; nt != nil && nt.LimitRange != nil {
+		ts.LimitRange = &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "redsky-trial-limits",
+				Namespace: namespace,
+			},
+		}
+		nt.LimitRange.DeepCopyInto(&ts.LimitRange.Spec)
+	}
+	if nt := exp.Spec.NamespaceTemplate; nt != nil && nt.NetworkPolicy != nil {
+		ts.NetworkPolicy = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "redsky-trial-network-policy",
+				Namespace: namespace,
+			},
+		}
+		nt.NetworkPolicy.DeepCopyInto(&ts.NetworkPolicy.Spec)
+	}
+
 	// Don't actually return the default service account for creation
 	if ts.ServiceAccount.Name == "default" {
 		ts.ServiceAccount = nil
@@ -217,3 +293,41 @@ func createTrialNamespace(exp *redskyv1beta1.Experiment, namespace string) *tria
 
 	return ts
 }
+
+// ExpiredTrialNamespaces returns the namespaces created from the experiment's namespace template that should be deleted
+// because the trials which ran in them have finished (or the experiment itself is being deleted).
+func ExpiredTrialNamespaces(exp *redskyv1beta1.Experiment, namespaceList *corev1.NamespaceList, trialList *redskyv1beta1.TrialList) []*corev1.Namespace {
+	if exp.Spec.NamespaceTemplate == nil || !exp.Spec.NamespaceTemplate.DeleteAfterTrial {
+		return nil
+	}
+
+	// Trials with finalizers may still need to report or run clean up tasks in the namespace
+	finished := make(map[string]bool, len(trialList.Items))
+	busy := make(map[string]bool, len(trialList.Items))
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if !trial.IsActive(t) && len(t.Finalizers) == 0 {
+			finished[t.Namespace] = true
+		} else {
+			busy[t.Namespace] = true
+		}
+	}
+
+	var result []*corev1.Namespace
+	for i := range namespaceList.Items {
+		n := &namespaceList.Items[i]
+		if !n.DeletionTimestamp.IsZero() || n.Name == exp.Namespace || busy[n.Name] {
+			continue
+		}
+
+		// Only delete namespaces that were created from the template
+		if n.Labels[redskyv1beta1.LabelExperiment] != exp.Name || n.Labels[redskyv1beta1.LabelTrialRole] != "trialSetup" {
+			continue
+		}
+
+		if finished[n.Name] || !exp.DeletionTimestamp.IsZero() {
+			result = append(result, n)
+		}
+	}
+	return result
+}
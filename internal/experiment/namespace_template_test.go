@@ -0,0 +1,104 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateTrialNamespace(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{}
+	exp.Spec.NamespaceTemplate = &redskyv1beta1.NamespaceTemplateSpec{
+		ResourceQuota: &corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("4")},
+		},
+		LimitRange: &corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{{Type: corev1.LimitTypeContainer, Max: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}},
+		},
+		NetworkPolicy: &networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	ts := createTrialNamespace(exp, "trial-ns")
+	if assert.NotNil(t, ts.ResourceQuota) {
+		assert.Equal(t, "trial-ns", ts.ResourceQuota.Namespace)
+		assert.Equal(t, *exp.Spec.NamespaceTemplate.ResourceQuota, ts.ResourceQuota.Spec)
+	}
+	if assert.NotNil(t, ts.LimitRange) {
+		assert.Equal(t, "trial-ns", ts.LimitRange.Namespace)
+		assert.Equal(t, *exp.Spec.NamespaceTemplate.LimitRange, ts.LimitRange.Spec)
+	}
+	if assert.NotNil(t, ts.NetworkPolicy) {
+		assert.Equal(t, "trial-ns", ts.NetworkPolicy.Namespace)
+		assert.Equal(t, *exp.Spec.NamespaceTemplate.NetworkPolicy, ts.NetworkPolicy.Spec)
+	}
+}
+
+func TestExpiredTrialNamespaces(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{}
+	exp.Name = "example"
+	exp.Namespace = "default"
+	exp.Spec.NamespaceTemplate = &redskyv1beta1.NamespaceTemplateSpec{DeleteAfterTrial: true}
+
+	created := func(name string) corev1.Namespace {
+		n := corev1.Namespace{}
+		n.Name = name
+		n.Labels = map[string]string{
+			redskyv1beta1.LabelExperiment: "example",
+			redskyv1beta1.LabelTrialRole:  "trialSetup",
+		}
+		return n
+	}
+	finished := []redskyv1beta1.TrialCondition{{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue}}
+
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{
+		created("finished"),
+		created("reporting"),
+		created("running"),
+		created("new"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "existing"}},
+	}}
+	trialList := &redskyv1beta1.TrialList{Items: []redskyv1beta1.Trial{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "finished"}, Status: redskyv1beta1.TrialStatus{Conditions: finished}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "reporting", Finalizers: []string{"example"}}, Status: redskyv1beta1.TrialStatus{Conditions: finished}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "running"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "existing"}, Status: redskyv1beta1.TrialStatus{Conditions: finished}},
+	}}
+
+	var names []string
+	for _, n := range ExpiredTrialNamespaces(exp, namespaceList, trialList) {
+		names = append(names, n.Name)
+	}
+	assert.Equal(t, []string{"finished"}, names)
+
+	// Everything without an active trial is deleted with the experiment
+	now := metav1.Now()
+	exp.DeletionTimestamp = &now
+	names = nil
+	for _, n := range ExpiredTrialNamespaces(exp, namespaceList, trialList) {
+		names = append(names, n.Name)
+	}
+	assert.Equal(t, []string{"finished", "new"}, names)
+}
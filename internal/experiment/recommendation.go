@@ -0,0 +1,228 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RecommendationName returns the name of the config map holding the current recommended configuration for the
+// application the supplied experiment belongs to. Experiments that are not associated with an application get
+// their own recommendation.
+func RecommendationName(exp *redskyv1beta1.Experiment) string {
+	if app := exp.Labels[redskyappsv1alpha1.LabelApplication]; app != "" {
+		return app + "-recommendation"
+	}
+	return exp.Name + "-recommendation"
+}
+
+// NewRecommendation returns a config map containing the assignments of the best trial of a finished experiment
+// along with their provenance. Returns nil if there is nothing to recommend.
+func NewRecommendation(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) *corev1.ConfigMap {
+	t, finishTime := recommendedTrial(exp, trialList)
+	if t == nil {
+		return nil
+	}
+
+	values := make([]string, 0, len(t.Spec.Values))
+	for _, v := range t.Spec.Values {
+		values = append(values, fmt.Sprintf("%s=%s", v.Name, v.Value))
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: recommendationMeta(exp, t, finishTime),
+		Data:       make(map[string]string, len(t.Spec.Assignments)),
+	}
+	cm.Name = RecommendationName(exp)
+	cm.Namespace = exp.Namespace
+	cm.Annotations[redskyv1beta1.AnnotationRecommendedValues] = strings.Join(values, ", ")
+	if u := exp.Annotations[redskyv1beta1.AnnotationExperimentURL]; u != "" {
+		cm.Annotations[redskyv1beta1.AnnotationExperimentURL] = u
+	}
+
+	for _, a := range t.Spec.Assignments {
+		cm.Data[a.Name] = a.Value.String()
+	}
+
+	return cm
+}
+
+// NewResourceRecommendations returns the container resources recommended for each workload patched by the best
+// trial of a finished experiment. The recommendations are extracted from the evaluated patches of the trial.
+func NewResourceRecommendations(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) ([]*redskyv1beta1.OptimizeRecommendation, error) {
+	t, finishTime := recommendedTrial(exp, trialList)
+	if t == nil {
+		return nil, nil
+	}
+
+	var result []*redskyv1beta1.OptimizeRecommendation
+	recommendations := make(map[string]*redskyv1beta1.OptimizeRecommendation)
+	for i := range t.Status.PatchOperations {
+		po := &t.Status.PatchOperations[i]
+		if po.PatchType != types.StrategicMergePatchType && po.PatchType != types.MergePatchType {
+			continue
+		}
+
+		// Only pod template resources are recommended
+		p := &struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(po.Data, p); err != nil {
+			return nil, err
+		}
+
+		// Recommendations live with the workload, unless it was in the trial namespace
+		namespace := exp.Namespace
+		if po.TargetRef.Namespace != "" && po.TargetRef.Namespace != t.Namespace {
+			namespace = po.TargetRef.Namespace
+		}
+
+		name := strings.ToLower(po.TargetRef.Kind) + "-" + po.TargetRef.Name
+		for _, c := range p.Spec.Template.Spec.Containers {
+			if len(c.Resources.Requests) == 0 && len(c.Resources.Limits) == 0 {
+				continue
+			}
+
+			r := recommendations[namespace+"/"+name]
+			if r == nil {
+				r = &redskyv1beta1.OptimizeRecommendation{
+					ObjectMeta: recommendationMeta(exp, t, finishTime),
+					Spec: redskyv1beta1.OptimizeRecommendationSpec{
+						TargetRef: corev1.ObjectReference{
+							APIVersion: po.TargetRef.APIVersion,
+							Kind:       po.TargetRef.Kind,
+							Name:       po.TargetRef.Name,
+						},
+					},
+					Status: redskyv1beta1.OptimizeRecommendationStatus{
+						Recommendation: &redskyv1beta1.RecommendedPodResources{},
+						Experiment:     exp.Name,
+						Trial:          t.Name,
+					},
+				}
+				r.Name = name
+				r.Namespace = namespace
+				recommendations[namespace+"/"+name] = r
+				result = append(result, r)
+			}
+
+			r.Status.Recommendation.ContainerRecommendations = mergeContainerRecommendation(r.Status.Recommendation.ContainerRecommendations, &c)
+		}
+	}
+
+	return result, nil
+}
+
+// ReplacesRecommendation checks to see if the supplied recommendation should replace the current recommendation.
+// A recommendation is only replaced by one from an experiment that finished at the same time or later; existing
+// objects which are not labeled as recommendations are never replaced.
+func ReplacesRecommendation(current, recommendation metav1.Object) bool {
+	if current.GetLabels()[redskyv1beta1.LabelRecommendation] != "true" {
+		return false
+	}
+
+	if current.GetAnnotations()[redskyv1beta1.AnnotationRecommendedTrial] == recommendation.GetAnnotations()[redskyv1beta1.AnnotationRecommendedTrial] &&
+		current.GetLabels()[redskyv1beta1.LabelExperiment] == recommendation.GetLabels()[redskyv1beta1.LabelExperiment] {
+		return false
+	}
+
+	currentTime, err := time.Parse(time.RFC3339, current.GetAnnotations()[redskyv1beta1.AnnotationRecommendationTime])
+	if err != nil {
+		return true
+	}
+	recommendationTime, err := time.Parse(time.RFC3339, recommendation.GetAnnotations()[redskyv1beta1.AnnotationRecommendationTime])
+	if err != nil {
+		return false
+	}
+	return !recommendationTime.Before(currentTime)
+}
+
+// recommendedTrial returns the best trial of a finished experiment and the time the experiment finished.
+func recommendedTrial(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) (*redskyv1beta1.Trial, time.Time) {
+	finishTime := experimentFinishTime(exp)
+	best := bestTrials(exp, trialList, 1)
+	if finishTime.IsZero() || len(best) == 0 {
+		return nil, finishTime
+	}
+	return best[0], finishTime
+}
+
+// recommendationMeta returns the labels and annotations common to all recommendations.
+func recommendationMeta(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, finishTime time.Time) metav1.ObjectMeta {
+	m := metav1.ObjectMeta{
+		Labels: map[string]string{
+			redskyv1beta1.LabelRecommendation: "true",
+			redskyv1beta1.LabelExperiment:     exp.Name,
+		},
+		Annotations: map[string]string{
+			redskyv1beta1.AnnotationRecommendedTrial:   t.Name,
+			redskyv1beta1.AnnotationRecommendationTime: finishTime.UTC().Format(time.RFC3339),
+		},
+	}
+
+	if app := exp.Labels[redskyappsv1alpha1.LabelApplication]; app != "" {
+		m.Labels[redskyappsv1alpha1.LabelApplication] = app
+	}
+
+	return m
+}
+
+// mergeContainerRecommendation adds the resources of the supplied container to the list of recommendations.
+func mergeContainerRecommendation(recommendations []redskyv1beta1.RecommendedContainerResources, c *corev1.Container) []redskyv1beta1.RecommendedContainerResources {
+	var rc *redskyv1beta1.RecommendedContainerResources
+	for i := range recommendations {
+		if recommendations[i].ContainerName == c.Name {
+			rc = &recommendations[i]
+		}
+	}
+	if rc == nil {
+		recommendations = append(recommendations, redskyv1beta1.RecommendedContainerResources{ContainerName: c.Name})
+		rc = &recommendations[len(recommendations)-1]
+	}
+
+	// The target is the requests, falling back to the limits when requests are not patched (Kubernetes defaults
+	// the requests to the limits)
+	target := c.Resources.Requests
+	if len(target) == 0 {
+		target = c.Resources.Limits
+	}
+	for name, q := range target {
+		if rc.Target == nil {
+			rc.Target = make(corev1.ResourceList)
+		}
+		rc.Target[name] = q.DeepCopy()
+	}
+	for name, q := range c.Resources.Limits {
+		if rc.UpperBound == nil {
+			rc.UpperBound = make(corev1.ResourceList)
+		}
+		rc.UpperBound[name] = q.DeepCopy()
+	}
+
+	return recommendations
+}
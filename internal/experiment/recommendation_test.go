@@ -0,0 +1,147 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNewRecommendation(t *testing.T) {
+	finished := metav1.NewTime(time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC))
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-exp",
+			Namespace: "default",
+			Labels:    map[string]string{redskyappsv1alpha1.LabelApplication: "my-app"},
+		},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Metrics: []redskyv1beta1.Metric{{Name: "throughput"}},
+		},
+	}
+
+	trialList := &redskyv1beta1.TrialList{}
+	for i, v := range []string{"10", "30", "20"} {
+		trialList.Items = append(trialList.Items, redskyv1beta1.Trial{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-exp-00" + v[:1]},
+			Spec: redskyv1beta1.TrialSpec{
+				Assignments: []redskyv1beta1.Assignment{{Name: "replicas", Value: intstr.FromInt(i + 1)}},
+				Values:      []redskyv1beta1.Value{{Name: "throughput", Value: v}},
+			},
+			Status: redskyv1beta1.TrialStatus{
+				Conditions: []redskyv1beta1.TrialCondition{{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue}},
+			},
+		})
+	}
+
+	// Nothing to recommend until the experiment finishes
+	assert.Nil(t, NewRecommendation(exp, trialList))
+
+	exp.Status.Conditions = []redskyv1beta1.ExperimentCondition{
+		{Type: redskyv1beta1.ExperimentComplete, Status: corev1.ConditionTrue, LastTransitionTime: finished},
+	}
+	cm := NewRecommendation(exp, trialList)
+	require.NotNil(t, cm)
+
+	assert.Equal(t, "my-app-recommendation", cm.Name)
+	assert.Equal(t, map[string]string{"replicas": "2"}, cm.Data)
+	assert.Equal(t, "my-exp-003", cm.Annotations[redskyv1beta1.AnnotationRecommendedTrial])
+	assert.Equal(t, "throughput=30", cm.Annotations[redskyv1beta1.AnnotationRecommendedValues])
+	assert.Equal(t, "2021-03-01T12:00:00Z", cm.Annotations[redskyv1beta1.AnnotationRecommendationTime])
+
+	// Only newer experiments replace the current recommendation
+	assert.False(t, ReplacesRecommendation(cm, cm))
+	older := cm.DeepCopy()
+	older.Labels[redskyv1beta1.LabelExperiment] = "old-exp"
+	older.Annotations[redskyv1beta1.AnnotationRecommendationTime] = "2021-01-01T00:00:00Z"
+	assert.True(t, ReplacesRecommendation(older, cm))
+	assert.False(t, ReplacesRecommendation(cm, older))
+
+	// Objects which are not recommendations are never replaced
+	unrelated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: cm.Name, Namespace: cm.Namespace}}
+	assert.False(t, ReplacesRecommendation(unrelated, cm))
+}
+
+func TestNewResourceRecommendations(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-exp", Namespace: "default"},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Metrics: []redskyv1beta1.Metric{{Name: "cost", Minimize: true}},
+		},
+		Status: redskyv1beta1.ExperimentStatus{
+			Conditions: []redskyv1beta1.ExperimentCondition{
+				{Type: redskyv1beta1.ExperimentComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	trialList := &redskyv1beta1.TrialList{
+		Items: []redskyv1beta1.Trial{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-exp-001", Namespace: "default"},
+				Spec: redskyv1beta1.TrialSpec{
+					Values: []redskyv1beta1.Value{{Name: "cost", Value: "1.0"}},
+				},
+				Status: redskyv1beta1.TrialStatus{
+					Conditions: []redskyv1beta1.TrialCondition{{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue}},
+					PatchOperations: []redskyv1beta1.PatchOperation{
+						{
+							TargetRef: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", Namespace: "default"},
+							PatchType: types.StrategicMergePatchType,
+							Data:      []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"requests":{"cpu":"500m"}}}]}}}}`),
+						},
+						{
+							TargetRef: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", Namespace: "default"},
+							PatchType: types.StrategicMergePatchType,
+							Data:      []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"app","resources":{"limits":{"memory":"1Gi"}}}]}}}}`),
+						},
+						{
+							TargetRef: corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: "app-config", Namespace: "default"},
+							PatchType: types.MergePatchType,
+							Data:      []byte(`{"data":{"threads":"4"}}`),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	recommendations, err := NewResourceRecommendations(exp, trialList)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+
+	r := recommendations[0]
+	assert.Equal(t, "deployment-app", r.Name)
+	assert.Equal(t, "default", r.Namespace)
+	assert.Equal(t, "app", r.Spec.TargetRef.Name)
+	assert.Equal(t, "my-exp-001", r.Status.Trial)
+	if assert.Len(t, r.Status.Recommendation.ContainerRecommendations, 1) {
+		cr := r.Status.Recommendation.ContainerRecommendations[0]
+		assert.Equal(t, "app", cr.ContainerName)
+		assert.Equal(t, "500m", cr.Target.Cpu().String())
+		assert.Equal(t, "1Gi", cr.Target.Memory().String())
+		assert.Equal(t, "1Gi", cr.UpperBound.Memory().String())
+	}
+}
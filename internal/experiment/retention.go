@@ -0,0 +1,222 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RetentionPolicy describes how long the results of finished experiments are kept
+type RetentionPolicy struct {
+	// KeepBest is the number of best trials (by the first optimized metric) that are never pruned; when zero, the
+	// entire experiment is pruned once it is old enough
+	KeepBest int
+	// OlderThan is the minimum amount of time since something finished before it can be pruned
+	OlderThan time.Duration
+}
+
+// Enabled checks to see if the retention policy will prune anything
+func (p *RetentionPolicy) Enabled() bool {
+	return p != nil && p.OlderThan > 0
+}
+
+// PruneExperiment checks to see if the entire experiment should be deleted
+func (p *RetentionPolicy) PruneExperiment(exp *redskyv1beta1.Experiment, now time.Time) bool {
+	if !p.Enabled() || p.KeepBest > 0 || !exp.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+
+	finishTime := experimentFinishTime(exp)
+	return !finishTime.IsZero() && finishTime.Add(p.OlderThan).Before(now)
+}
+
+// PruneTrials returns the finished trials of a finished experiment that should be deleted
+func (p *RetentionPolicy) PruneTrials(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList, now time.Time) []*redskyv1beta1.Trial {
+	if !p.Enabled() || !IsFinished(exp) {
+		return nil
+	}
+
+	keep := make(map[string]bool, p.KeepBest)
+	for _, t := range bestTrials(exp, trialList, p.KeepBest) {
+		keep[t.Name] = true
+	}
+
+	var prune []*redskyv1beta1.Trial
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if keep[t.Name] || !t.GetDeletionTimestamp().IsZero() || trial.IsActive(t) {
+			continue
+		}
+
+		finishTime := trialFinishTime(t)
+		if !finishTime.IsZero() && finishTime.Add(p.OlderThan).Before(now) {
+			prune = append(prune, t)
+		}
+	}
+	return prune
+}
+
+// ExcessTrials returns the oldest finished trials beyond the number of finished trials the experiment keeps in the
+// cluster. Trials with finalizers are not included since something (e.g. reporting to the server) is still waiting on
+// them.
+func ExcessTrials(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) []*redskyv1beta1.Trial {
+	r := exp.Spec.TrialRetention
+	if r == nil || r.KeepCompletedTrials == nil || *r.KeepCompletedTrials < 0 {
+		return nil
+	}
+
+	var finished []*redskyv1beta1.Trial
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if t.GetDeletionTimestamp().IsZero() && !trial.IsActive(t) && !trialFinishTime(t).IsZero() {
+			finished = append(finished, t)
+		}
+	}
+
+	keep := int(*r.KeepCompletedTrials)
+	if len(finished) <= keep {
+		return nil
+	}
+
+	sort.SliceStable(finished, func(i, j int) bool {
+		return trialFinishTime(finished[i]).Before(trialFinishTime(finished[j]))
+	})
+
+	var excess []*redskyv1beta1.Trial
+	for _, t := range finished[:len(finished)-keep] {
+		if len(t.GetFinalizers()) == 0 {
+			excess = append(excess, t)
+		}
+	}
+	return excess
+}
+
+// TrialObjectsExpiration returns the time after which the jobs and config maps of a finished trial can be deleted,
+// the zero time is returned if they should be kept.
+func TrialObjectsExpiration(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial) time.Time {
+	r := exp.Spec.TrialRetention
+	if r == nil || r.TTLSecondsAfterFinished == nil || *r.TTLSecondsAfterFinished < 0 ||
+		!t.GetDeletionTimestamp().IsZero() || trial.IsActive(t) {
+		return time.Time{}
+	}
+
+	finishTime := trialFinishTime(t)
+	if finishTime.IsZero() {
+		return finishTime
+	}
+	return finishTime.Add(time.Duration(*r.TTLSecondsAfterFinished) * time.Second)
+}
+
+// ParseAge parses a retention age, in addition to the usual duration units, whole days may be expressed using "d"
+func ParseAge(s string) (time.Duration, error) {
+	if d := strings.TrimSuffix(s, "d"); d != s {
+		days, err := strconv.Atoi(d)
+		if err == nil {
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// bestTrials returns up to n successfully completed trials ordered by the value of the first optimized metric
+func bestTrials(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList, n int) []*redskyv1beta1.Trial {
+	if n <= 0 {
+		return nil
+	}
+
+	var m *redskyv1beta1.Metric
+	for i := range exp.Spec.Metrics {
+		if exp.Spec.Metrics[i].Optimize == nil || *exp.Spec.Metrics[i].Optimize {
+			m = &exp.Spec.Metrics[i]
+			break
+		}
+	}
+	if m == nil {
+		return nil
+	}
+
+	var best []*redskyv1beta1.Trial
+	values := make(map[string]float64, len(trialList.Items))
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if !trial.CheckCondition(&t.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue) {
+			continue
+		}
+
+		for _, v := range t.Spec.Values {
+			if v.Name != m.Name || v.AttemptsRemaining != 0 {
+				continue
+			}
+			if fv, err := strconv.ParseFloat(v.Value, 64); err == nil {
+				best = append(best, t)
+				values[t.Name] = fv
+			}
+		}
+	}
+
+	sort.SliceStable(best, func(i, j int) bool {
+		if m.Minimize {
+			return values[best[i].Name] < values[best[j].Name]
+		}
+		return values[best[i].Name] > values[best[j].Name]
+	})
+
+	if len(best) > n {
+		best = best[:n]
+	}
+	return best
+}
+
+// experimentFinishTime returns the time the experiment completed or failed
+func experimentFinishTime(exp *redskyv1beta1.Experiment) time.Time {
+	finishTime := metav1.Time{}
+	for _, c := range exp.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		if c.Type == redskyv1beta1.ExperimentComplete || c.Type == redskyv1beta1.ExperimentFailed {
+			if finishTime.Before(&c.LastTransitionTime) {
+				finishTime = c.LastTransitionTime
+			}
+		}
+	}
+	return finishTime.Time
+}
+
+// trialFinishTime returns the time the trial completed or failed
+func trialFinishTime(t *redskyv1beta1.Trial) time.Time {
+	finishTime := metav1.Time{}
+	for _, c := range t.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		if c.Type == redskyv1beta1.TrialComplete || c.Type == redskyv1beta1.TrialFailed {
+			if finishTime.Before(&c.LastTransitionTime) {
+				finishTime = c.LastTransitionTime
+			}
+		}
+	}
+	return finishTime.Time
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Now()
+	old := metav1.NewTime(now.Add(-100 * 24 * time.Hour))
+	recent := metav1.NewTime(now.Add(-time.Hour))
+
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			Metrics: []redskyv1beta1.Metric{{Name: "cost", Minimize: true}},
+		},
+		Status: redskyv1beta1.ExperimentStatus{
+			Conditions: []redskyv1beta1.ExperimentCondition{
+				{Type: redskyv1beta1.ExperimentComplete, Status: corev1.ConditionTrue, LastTransitionTime: old},
+			},
+		},
+	}
+
+	completed := func(name, value string, finished metav1.Time) redskyv1beta1.Trial {
+		return redskyv1beta1.Trial{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       redskyv1beta1.TrialSpec{Values: []redskyv1beta1.Value{{Name: "cost", Value: value}}},
+			Status: redskyv1beta1.TrialStatus{
+				Conditions: []redskyv1beta1.TrialCondition{
+					{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue, LastTransitionTime: finished},
+				},
+			},
+		}
+	}
+	trialList := &redskyv1beta1.TrialList{
+		Items: []redskyv1beta1.Trial{
+			completed("t1", "3.0", old),
+			completed("t2", "1.0", old),
+			completed("t3", "2.0", old),
+			completed("t4", "5.0", recent),
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "t5"},
+				Status: redskyv1beta1.TrialStatus{
+					Conditions: []redskyv1beta1.TrialCondition{
+						{Type: redskyv1beta1.TrialFailed, Status: corev1.ConditionTrue, LastTransitionTime: old},
+					},
+				},
+			},
+		},
+	}
+
+	names := func(trials []*redskyv1beta1.Trial) []string {
+		var result []string
+		for _, t := range trials {
+			result = append(result, t.Name)
+		}
+		return result
+	}
+
+	cases := []struct {
+		desc            string
+		policy          RetentionPolicy
+		pruneExperiment bool
+		pruneTrials     []string
+	}{
+		{
+			desc: "disabled",
+		},
+		{
+			desc:        "keep best",
+			policy:      RetentionPolicy{KeepBest: 2, OlderThan: 90 * 24 * time.Hour},
+			pruneTrials: []string{"t1", "t5"},
+		},
+		{
+			desc:            "keep nothing",
+			policy:          RetentionPolicy{OlderThan: 90 * 24 * time.Hour},
+			pruneExperiment: true,
+			pruneTrials:     []string{"t1", "t2", "t3", "t5"},
+		},
+		{
+			desc:        "too recent",
+			policy:      RetentionPolicy{KeepBest: 1, OlderThan: 200 * 24 * time.Hour},
+			pruneTrials: nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.pruneExperiment, c.policy.PruneExperiment(exp, now))
+			assert.Equal(t, c.pruneTrials, names(c.policy.PruneTrials(exp, trialList, now)))
+		})
+	}
+}
+
+func TestTrialRetention(t *testing.T) {
+	now := time.Now()
+	finished := func(name string, age time.Duration, finalizers ...string) redskyv1beta1.Trial {
+		return redskyv1beta1.Trial{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Finalizers: finalizers},
+			Status: redskyv1beta1.TrialStatus{
+				Conditions: []redskyv1beta1.TrialCondition{
+					{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-age))},
+				},
+			},
+		}
+	}
+	trialList := &redskyv1beta1.TrialList{
+		Items: []redskyv1beta1.Trial{
+			finished("t1", 4*time.Hour),
+			finished("t2", 3*time.Hour, "serverFinalizer"),
+			finished("t3", 2*time.Hour),
+			finished("t4", time.Hour),
+			{ObjectMeta: metav1.ObjectMeta{Name: "t5"}},
+		},
+	}
+
+	names := func(trials []*redskyv1beta1.Trial) []string {
+		var result []string
+		for _, t := range trials {
+			result = append(result, t.Name)
+		}
+		return result
+	}
+	keep := func(n int32) *int32 { return &n }
+	ttl := int32((90 * time.Minute) / time.Second)
+
+	cases := []struct {
+		desc      string
+		retention *redskyv1beta1.TrialRetention
+		excess    []string
+		expired   []string
+	}{
+		{
+			desc: "disabled",
+		},
+		{
+			desc:      "keep two",
+			retention: &redskyv1beta1.TrialRetention{KeepCompletedTrials: keep(2)},
+			excess:    []string{"t1"},
+		},
+		{
+			desc:      "keep none",
+			retention: &redskyv1beta1.TrialRetention{KeepCompletedTrials: keep(0)},
+			excess:    []string{"t1", "t3", "t4"},
+		},
+		{
+			desc:      "keep all",
+			retention: &redskyv1beta1.TrialRetention{KeepCompletedTrials: keep(10)},
+		},
+		{
+			desc:      "ttl",
+			retention: &redskyv1beta1.TrialRetention{TTLSecondsAfterFinished: &ttl},
+			expired:   []string{"t1", "t2", "t3"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			exp := &redskyv1beta1.Experiment{Spec: redskyv1beta1.ExperimentSpec{TrialRetention: c.retention}}
+			assert.Equal(t, c.excess, names(ExcessTrials(exp, trialList)))
+
+			var expired []string
+			for i := range trialList.Items {
+				if expiration := TrialObjectsExpiration(exp, &trialList.Items[i]); !expiration.IsZero() && expiration.Before(now) {
+					expired = append(expired, trialList.Items[i].Name)
+				}
+			}
+			assert.Equal(t, c.expired, expired)
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	d, err := ParseAge("90d")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 90*24*time.Hour, d)
+	}
+
+	d, err = ParseAge("36h")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 36*time.Hour, d)
+	}
+
+	_, err = ParseAge("xd")
+	assert.Error(t, err)
+}
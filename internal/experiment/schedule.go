@@ -0,0 +1,183 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// ScheduleDelay returns the amount of time until new trials can be started according to the experiment schedule. A
+// zero delay indicates new trials can be started now.
+func ScheduleDelay(exp *redskyv1beta1.Experiment, now time.Time) (time.Duration, error) {
+	s := exp.Spec.Schedule
+	if s == nil || len(s.Windows) == 0 {
+		return 0, nil
+	}
+
+	loc := time.UTC
+	if s.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.TimeZone); err != nil {
+			return 0, fmt.Errorf("invalid schedule time zone: %w", err)
+		}
+	}
+	now = now.In(loc)
+
+	delay := time.Duration(-1)
+	for i := range s.Windows {
+		w, err := parseWindow(&s.Windows[i])
+		if err != nil {
+			return 0, err
+		}
+
+		// Start with the previous day in case the window continues past midnight, then look ahead a full week
+		for d := -1; d <= 7; d++ {
+			day := now.AddDate(0, 0, d)
+			if !w.days[day.Weekday()] {
+				continue
+			}
+
+			start := time.Date(day.Year(), day.Month(), day.Day(), w.startHour, w.startMinute, 0, 0, loc)
+			end := time.Date(day.Year(), day.Month(), day.Day(), w.endHour, w.endMinute, 0, 0, loc)
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+
+			if !now.Before(start) && now.Before(end) {
+				return 0, nil
+			}
+
+			if start.After(now) && (delay < 0 || start.Sub(now) < delay) {
+				delay = start.Sub(now)
+			}
+		}
+	}
+
+	return delay, nil
+}
+
+// ValidateSchedule checks the time zone and windows of a trial schedule.
+func ValidateSchedule(s *redskyv1beta1.TrialSchedule) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return fmt.Errorf("invalid schedule time zone: %w", err)
+		}
+	}
+
+	for i := range s.Windows {
+		if _, err := parseWindow(&s.Windows[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type scheduleWindow struct {
+	days        map[time.Weekday]bool
+	startHour   int
+	startMinute int
+	endHour     int
+	endMinute   int
+}
+
+func parseWindow(w *redskyv1beta1.TrialScheduleWindow) (*scheduleWindow, error) {
+	sw := &scheduleWindow{}
+
+	var err error
+	if sw.days, err = parseDays(w.Days); err != nil {
+		return nil, err
+	}
+	if sw.startHour, sw.startMinute, err = parseTimeOfDay(w.Start); err != nil {
+		return nil, err
+	}
+	if sw.endHour, sw.endMinute, err = parseTimeOfDay(w.End); err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+// parseTimeOfDay parses a 24-hour "HH:MM" time
+func parseTimeOfDay(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid schedule time %q, expected HH:MM", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// parseDays parses a cron style day of the week field, e.g. "*", "1-5" or "MON,WED,FRI"
+func parseDays(value string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool, 7)
+	if value == "" || value == "*" {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			days[d] = true
+		}
+		return days, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		lo, hi := item, item
+		if pos := strings.Index(item, "-"); pos > 0 {
+			lo, hi = item[:pos], item[pos+1:]
+		}
+
+		first, err := parseDay(lo)
+		if err != nil {
+			return nil, err
+		}
+		last, err := parseDay(hi)
+		if err != nil {
+			return nil, err
+		}
+		if last < first {
+			return nil, fmt.Errorf("invalid schedule day range %q", item)
+		}
+
+		for d := first; d <= last; d++ {
+			days[time.Weekday(d%7)] = true
+		}
+	}
+	return days, nil
+}
+
+var dayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// parseDay parses a single day of the week, Sunday may be either 0 or 7
+func parseDay(value string) (int, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	for i, name := range dayNames {
+		if value == name {
+			return i, nil
+		}
+	}
+
+	if d, err := strconv.Atoi(value); err == nil && d >= 0 && d <= 7 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid schedule day %q", value)
+}
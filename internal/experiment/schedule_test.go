@@ -0,0 +1,126 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+func TestScheduleDelay(t *testing.T) {
+	// 2021-06-02 is a Wednesday
+	wednesday := func(hour, minute int) time.Time {
+		return time.Date(2021, time.June, 2, hour, minute, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		desc     string
+		schedule *redskyv1beta1.TrialSchedule
+		now      time.Time
+		expected time.Duration
+		err      bool
+	}{
+		{
+			desc:     "no schedule",
+			now:      wednesday(12, 0),
+			expected: 0,
+		},
+		{
+			desc: "inside window",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Days: "MON-FRI", Start: "09:00", End: "17:00"}},
+			},
+			now:      wednesday(12, 0),
+			expected: 0,
+		},
+		{
+			desc: "before window",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Days: "1-5", Start: "09:00", End: "17:00"}},
+			},
+			now:      wednesday(8, 30),
+			expected: 30 * time.Minute,
+		},
+		{
+			desc: "after window",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Days: "1-5", Start: "09:00", End: "17:00"}},
+			},
+			now:      wednesday(17, 0),
+			expected: 16 * time.Hour,
+		},
+		{
+			desc: "overnight window",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Days: "TUE", Start: "22:00", End: "06:00"}},
+			},
+			now:      wednesday(2, 0),
+			expected: 0,
+		},
+		{
+			desc: "next week",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Days: "WED", Start: "01:00", End: "02:00"}},
+			},
+			now:      wednesday(3, 0),
+			expected: 7*24*time.Hour - 2*time.Hour,
+		},
+		{
+			desc: "time zone",
+			schedule: &redskyv1beta1.TrialSchedule{
+				TimeZone: "America/New_York",
+				Windows:  []redskyv1beta1.TrialScheduleWindow{{Start: "09:00", End: "17:00"}},
+			},
+			now:      wednesday(12, 0),
+			expected: time.Hour,
+		},
+		{
+			desc: "invalid time",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Start: "9am", End: "17:00"}},
+			},
+			now: wednesday(12, 0),
+			err: true,
+		},
+		{
+			desc: "invalid day",
+			schedule: &redskyv1beta1.TrialSchedule{
+				Windows: []redskyv1beta1.TrialScheduleWindow{{Days: "FUNDAY", Start: "09:00", End: "17:00"}},
+			},
+			now: wednesday(12, 0),
+			err: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			exp := &redskyv1beta1.Experiment{}
+			exp.Spec.Schedule = c.schedule
+
+			assert.Equal(t, c.err, ValidateSchedule(c.schedule) != nil)
+
+			delay, err := ScheduleDelay(exp, c.now)
+			if c.err {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, delay)
+			}
+		})
+	}
+}
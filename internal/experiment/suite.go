@@ -0,0 +1,144 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"fmt"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/application"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// SuiteGenerator is used to create experiment definitions for every combination of scenario and objective
+// defined on an application.
+type SuiteGenerator struct {
+	// The generator used for each experiment in the suite. If the scenario or objective is set, the suite is
+	// restricted to that scenario or objective.
+	Generator
+	// Parallel is a flag indicating the experiments in the suite can run at the same time. By default, each
+	// experiment depends on the one before it so they run sequentially.
+	Parallel bool
+}
+
+// Execute the experiment generation pipeline for each experiment in the suite, sending the combined results to the
+// supplied writer.
+func (g *SuiteGenerator) Execute(output kio.Writer) error {
+	if g.ExperimentName != "" {
+		return fmt.Errorf("experiment name cannot be used with a suite")
+	}
+
+	combinations, err := g.combinations()
+	if err != nil {
+		return err
+	}
+
+	var result []*yaml.RNode
+	generated := make(map[string]string)
+	var previous string
+	for _, c := range combinations {
+		gen := g.Generator
+		gen.Scenario, gen.Objective = c[0], c[1]
+
+		err := gen.Execute(kio.WriterFunc(func(nodes []*yaml.RNode) error {
+			for _, node := range nodes {
+				m, err := node.GetMeta()
+				if err != nil {
+					return err
+				}
+
+				if m.Kind == "Experiment" && m.APIVersion == redskyv1beta1.GroupVersion.String() {
+					if previous != "" && !g.Parallel {
+						if err := node.PipeE(
+							yaml.LookupCreate(yaml.SequenceNode, "spec", "dependsOn"),
+							yaml.Append(yaml.NewScalarRNode(previous).YNode()),
+						); err != nil {
+							return err
+						}
+					}
+					previous = m.Name
+				}
+
+				// Supporting resources may be generated more then once, they must be identical
+				key := fmt.Sprintf("%s/%s/%s/%s", m.APIVersion, m.Kind, m.Namespace, m.Name)
+				str, err := node.String()
+				if err != nil {
+					return err
+				}
+				if s, ok := generated[key]; ok {
+					if s != str {
+						return fmt.Errorf("suite generated conflicting definitions of %s %q", m.Kind, m.Name)
+					}
+					continue
+				}
+				generated[key] = str
+
+				result = append(result, node)
+			}
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+
+	return output.Write(result)
+}
+
+// combinations returns the scenario and objective name pairs for each experiment in the suite.
+func (g *SuiteGenerator) combinations() ([][2]string, error) {
+	scenarios := []string{""}
+	if g.Scenario != "" {
+		s, err := application.GetScenario(&g.Application, g.Scenario)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			scenarios = []string{s.Name}
+		}
+	} else if len(g.Application.Scenarios) > 0 {
+		scenarios = scenarios[:0]
+		for i := range g.Application.Scenarios {
+			scenarios = append(scenarios, g.Application.Scenarios[i].Name)
+		}
+	}
+
+	objectives := []string{""}
+	if g.Objective != "" {
+		o, err := application.GetObjective(&g.Application, g.Objective)
+		if err != nil {
+			return nil, err
+		}
+		if o != nil {
+			objectives = []string{o.Name}
+		}
+	} else if len(g.Application.Objectives) > 0 {
+		objectives = objectives[:0]
+		for i := range g.Application.Objectives {
+			objectives = append(objectives, g.Application.Objectives[i].Name)
+		}
+	}
+
+	result := make([][2]string, 0, len(scenarios)*len(objectives))
+	for _, s := range scenarios {
+		for _, o := range objectives {
+			result = append(result, [2]string{s, o})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+)
+
+func TestSuiteGenerator_Combinations(t *testing.T) {
+	app := redskyappsv1alpha1.Application{
+		Scenarios: []redskyappsv1alpha1.Scenario{
+			{Name: "browse"},
+			{Name: "checkout"},
+		},
+		Objectives: []redskyappsv1alpha1.Objective{
+			{Name: "cost"},
+			{Name: "latency"},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		generator Generator
+		expected  [][2]string
+	}{
+		{
+			desc:      "empty",
+			generator: Generator{},
+			expected:  [][2]string{{"", ""}},
+		},
+		{
+			desc:      "all",
+			generator: Generator{Application: app},
+			expected:  [][2]string{{"browse", "cost"}, {"browse", "latency"}, {"checkout", "cost"}, {"checkout", "latency"}},
+		},
+		{
+			desc:      "scenario",
+			generator: Generator{Application: app, Scenario: "checkout"},
+			expected:  [][2]string{{"checkout", "cost"}, {"checkout", "latency"}},
+		},
+		{
+			desc:      "objective",
+			generator: Generator{Application: app, Objective: "latency"},
+			expected:  [][2]string{{"browse", "latency"}, {"checkout", "latency"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			g := &SuiteGenerator{Generator: c.generator}
+			actual, err := g.combinations()
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
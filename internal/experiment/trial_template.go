@@ -51,6 +51,9 @@ func PopulateTrialFromTemplate(exp *redskyv1beta1.Experiment, t *redskyv1beta1.T
 	}
 
 	// Default trial name is the experiment name with a random suffix
+	if t.Name == "" && exp.Spec.TrialNaming != nil && exp.Spec.TrialNaming.Prefix != "" {
+		t.GenerateName = exp.Spec.TrialNaming.Prefix
+	}
 	if t.Name == "" && t.GenerateName == "" {
 		t.GenerateName = exp.Name + "-"
 	}
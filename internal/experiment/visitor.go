@@ -42,10 +42,12 @@ func Walk(ctx context.Context, v Visitor, obj interface{}) {
 
 	case *redskyv1beta1.Experiment:
 		Walk(withPath(ctx, "spec"), v, &o.Spec)
+		Walk(withPath(ctx, "status"), v, &o.Status)
 
 	case *redskyv1beta1.ExperimentSpec:
 		Walk(withPath(ctx, "optimization"), v, o.Optimization)
 		Walk(withPath(ctx, "parameters"), v, o.Parameters)
+		Walk(withPath(ctx, "constraints"), v, o.Constraints)
 		Walk(withPath(ctx, "metrics"), v, o.Metrics)
 		Walk(withPath(ctx, "patches"), v, o.Patches)
 		Walk(withPath(ctx, "trialTemplate"), v, &o.TrialTemplate)
@@ -66,6 +68,14 @@ func Walk(ctx context.Context, v Visitor, obj interface{}) {
 	case *redskyv1beta1.Parameter:
 		// Do nothing
 
+	case []redskyv1beta1.Constraint:
+		for i := range o {
+			Walk(withPath(ctx, i), v, &o[i])
+		}
+
+	case *redskyv1beta1.Constraint:
+		// Do nothing
+
 	case []redskyv1beta1.Metric:
 		for i := range o {
 			Walk(withPath(ctx, map[string]string{"name": o[i].Name}), v, &o[i])
@@ -90,6 +100,9 @@ func Walk(ctx context.Context, v Visitor, obj interface{}) {
 	case *batchv1beta1.JobTemplateSpec:
 		// Do nothing
 
+	case *redskyv1beta1.ExperimentStatus:
+		// Do nothing
+
 	default:
 		panic(fmt.Sprintf("experiment.Walk: unexpected type %T", obj))
 	}
@@ -192,3 +192,34 @@ func TestAddLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestJoinName(t *testing.T) {
+	cases := []struct {
+		desc     string
+		parts    []string
+		expected string
+	}{
+		{
+			desc:     "simple",
+			parts:    []string{"my-app", "locustfile"},
+			expected: "my-app-locustfile",
+		},
+		{
+			desc:     "invalid characters",
+			parts:    []string{"My_App!", "", "Scenario 1"},
+			expected: "myapp-scenario1",
+		},
+		{
+			desc:     "truncated",
+			parts:    []string{"this-is-a-really-really-long-name-that-is-exactly-63-characters", "locustfile"},
+			expected: "this-is-a-really-really-long-name-that-is-exactly-63-c-8b498b8e",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual := JoinName(c.parts...)
+			assert.Equal(t, c.expected, actual)
+			assert.NoError(t, ValidateName(actual))
+		})
+	}
+}
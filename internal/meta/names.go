@@ -0,0 +1,82 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MaxNameLength is the maximum length of a name that can also be used as a label value.
+const MaxNameLength = validation.DNS1123LabelMaxLength
+
+// JoinName combines the supplied parts into a single DNS-1123 label (e.g. suitable for
+// use as a resource name or label value). Invalid characters are removed and names which
+// would exceed the maximum length are truncated and suffixed with a hash of the full name
+// so they remain unique.
+func JoinName(parts ...string) string {
+	var cleaned []string
+	for _, p := range parts {
+		if p = strings.Trim(CleanName(p), "-"); p != "" {
+			cleaned = append(cleaned, p)
+		}
+	}
+	return TruncateName(strings.Join(cleaned, "-"), MaxNameLength)
+}
+
+// TruncateName ensures the supplied name does not exceed the specified length by replacing
+// the end of the name with a short hash of the full name.
+func TruncateName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+
+	// Use 8 characters of hash plus a separator
+	hash := fmt.Sprintf("%x", sha1.Sum([]byte(name)))[0:8]
+	prefix := strings.TrimRight(name[0:maxLength-len(hash)-1], "-.")
+	return prefix + "-" + hash
+}
+
+// ValidateName returns an error if the supplied name is not a valid DNS-1123 label.
+func ValidateName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// CleanName lower cases the supplied value and removes any characters which are not
+// allowed in a DNS-1123 label.
+func CleanName(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if r >= 'a' && r <= 'z' {
+			return r
+		}
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if r == '-' {
+			return r
+		}
+		return -1
+	}, s)
+}
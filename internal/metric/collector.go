@@ -0,0 +1,141 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Collector captures the value of a metric over the measurement window (the start and completion time) of a trial.
+type Collector interface {
+	// Collect returns the metric value and the error of that value (NaN if it is unknown). The metric queries have
+	// already been rendered. Failures that should be retried without counting against the remaining attempts must
+	// be returned as a `CaptureError` with a retry delay.
+	Collect(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error)
+}
+
+// CollectorFunc is an adapter to allow the use of ordinary functions as metric collectors.
+type CollectorFunc func(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error)
+
+// Collect calls f(ctx, log, t, m, target).
+func (f CollectorFunc) Collect(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	return f(ctx, log, t, m, target)
+}
+
+// CaptureError describes problems that arise while capturing metric values.
+type CaptureError struct {
+	// A description of what went wrong
+	Message string
+	// The URL that was used to capture the metric
+	Address string
+	// The metric query that failed
+	Query string
+	// The minimum amount of time until the metric is expected to be available
+	RetryAfter time.Duration
+}
+
+func (e *CaptureError) Error() string {
+	return e.Message
+}
+
+// RetryAfter returns the amount of time to wait before retrying a collection that failed with the supplied error. A
+// zero duration indicates the failure should count against the remaining attempts for the metric.
+func RetryAfter(err error) time.Duration {
+	if merr, ok := err.(*CaptureError); ok && merr.RetryAfter > 0 {
+		return merr.RetryAfter
+	}
+	return 0
+}
+
+var (
+	collectorsMu sync.RWMutex
+	collectors   = map[redskyv1beta1.MetricType]Collector{
+		redskyv1beta1.MetricKubernetes:    CollectorFunc(collectQuery),
+		redskyv1beta1.MetricDerived:       CollectorFunc(collectQuery),
+		redskyv1beta1.MetricPrometheus:    CollectorFunc(collectPrometheus),
+		redskyv1beta1.MetricDatadog:       CollectorFunc(collectDatadog),
+		redskyv1beta1.MetricJSONPath:      CollectorFunc(collectJSONPath),
+		redskyv1beta1.MetricNewRelic:      CollectorFunc(collectNewRelic),
+		redskyv1beta1.MetricInfluxDB:      CollectorFunc(collectInfluxDB),
+		redskyv1beta1.MetricElasticsearch: CollectorFunc(collectElasticsearch),
+		redskyv1beta1.MetricMetricsServer: CollectorFunc(collectMetricsServer),
+	}
+)
+
+// Register makes a collector available for the specified metric type, replacing any existing collector.
+func Register(metricType redskyv1beta1.MetricType, c Collector) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	collectors[metricType] = c
+}
+
+// CollectorFor returns the collector for the specified metric type, the empty type is a Kubernetes metric.
+func CollectorFor(metricType redskyv1beta1.MetricType) (Collector, error) {
+	if metricType == "" {
+		metricType = redskyv1beta1.MetricKubernetes
+	}
+
+	collectorsMu.RLock()
+	defer collectorsMu.RUnlock()
+	if c, ok := collectors[metricType]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown metric type: %s", metricType)
+}
+
+// collectQuery uses the rendered query itself as the metric value.
+func collectQuery(_ context.Context, _ logr.Logger, _ *redskyv1beta1.Trial, m *redskyv1beta1.Metric, _ runtime.Object) (float64, float64, error) {
+	value, err := strconv.ParseFloat(m.Query, 64)
+	return value, math.NaN(), err
+}
+
+func collectPrometheus(ctx context.Context, log logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	return capturePrometheusMetric(ctx, log, m, target, t.Status.StartTime.Time, t.Status.CompletionTime.Time)
+}
+
+func collectDatadog(_ context.Context, _ logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	return captureDatadogMetric(m, target, t.Status.StartTime.Time, t.Status.CompletionTime.Time)
+}
+
+func collectJSONPath(ctx context.Context, _ logr.Logger, _ *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	return captureJSONPathMetric(ctx, m, target)
+}
+
+func collectNewRelic(_ context.Context, _ logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, _ runtime.Object) (float64, float64, error) {
+	return captureNewRelicMetric(m, t.Status.StartTime.Time, t.Status.CompletionTime.Time)
+}
+
+func collectInfluxDB(ctx context.Context, _ logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	return captureInfluxDBMetric(ctx, m, target, t.Status.StartTime.Time, t.Status.CompletionTime.Time)
+}
+
+func collectElasticsearch(ctx context.Context, _ logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	return captureElasticsearchMetric(ctx, m, target, t.Status.StartTime.Time, t.Status.CompletionTime.Time)
+}
+
+func collectMetricsServer(_ context.Context, _ logr.Logger, t *redskyv1beta1.Trial, m *redskyv1beta1.Metric, _ runtime.Object) (float64, float64, error) {
+	return captureMetricsServerMetric(m, t)
+}
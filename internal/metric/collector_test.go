@@ -0,0 +1,147 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// collectorTestCase is a test case that can be evaluated against any collector
+type collectorTestCase struct {
+	desc       string
+	metric     redskyv1beta1.Metric
+	target     runtime.Object
+	expected   float64
+	retryAfter time.Duration
+	hasError   bool
+}
+
+// testCollector is a shared harness for evaluating test cases against the collector registered for a metric type;
+// the trial runs for five seconds, starting ten minutes ago.
+func testCollector(t *testing.T, metricType redskyv1beta1.MetricType, cases []collectorTestCase) {
+	log := zap.New(zap.UseDevMode(true))
+	startTime := metav1.NewTime(time.Now().Add(-10 * time.Minute).Truncate(time.Second))
+	completionTime := metav1.NewTime(startTime.Add(5 * time.Second))
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tt := &redskyv1beta1.Trial{}
+			tt.Status.StartTime = &startTime
+			tt.Status.CompletionTime = &completionTime
+
+			m := c.metric.DeepCopy()
+			m.Type = metricType
+
+			value, _, err := captureValue(context.Background(), log, tt, m, c.target)
+			switch {
+			case c.retryAfter > 0:
+				assert.Equal(t, c.retryAfter, RetryAfter(err))
+			case c.hasError:
+				assert.Error(t, err)
+				assert.Zero(t, RetryAfter(err))
+			default:
+				if assert.NoError(t, err) {
+					assert.Equal(t, c.expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestKubernetesCollector(t *testing.T) {
+	testCollector(t, redskyv1beta1.MetricKubernetes, []collectorTestCase{
+		{
+			desc:     "duration",
+			metric:   redskyv1beta1.Metric{Name: "duration", Query: "{{duration .StartTime .CompletionTime}}"},
+			expected: 5,
+		},
+		{
+			desc:     "constant",
+			metric:   redskyv1beta1.Metric{Name: "constant", Query: "1.5"},
+			expected: 1.5,
+		},
+		{
+			desc:     "not a number",
+			metric:   redskyv1beta1.Metric{Name: "text", Query: "five"},
+			hasError: true,
+		},
+	})
+}
+
+func TestJSONPathCollector(t *testing.T) {
+	srv := jsonPathHttpTestServer()
+	defer srv.Close()
+
+	testCollector(t, redskyv1beta1.MetricJSONPath, []collectorTestCase{
+		{
+			desc:     "value",
+			metric:   redskyv1beta1.Metric{Name: "p95", URL: srv.URL, Query: "{.current_response_time_percentile_95}"},
+			expected: 5,
+		},
+	})
+}
+
+func TestRegister(t *testing.T) {
+	const metricType redskyv1beta1.MetricType = "test"
+	_, err := CollectorFor(metricType)
+	assert.Error(t, err)
+
+	Register(metricType, CollectorFunc(func(_ context.Context, _ logr.Logger, _ *redskyv1beta1.Trial, m *redskyv1beta1.Metric, _ runtime.Object) (float64, float64, error) {
+		if m.Query == "wait" {
+			return 0, 0, &CaptureError{Message: "metric data not available", RetryAfter: time.Minute}
+		}
+		return 42, math.NaN(), nil
+	}))
+	defer func() {
+		collectorsMu.Lock()
+		delete(collectors, metricType)
+		collectorsMu.Unlock()
+	}()
+
+	testCollector(t, metricType, []collectorTestCase{
+		{
+			desc:     "collected",
+			metric:   redskyv1beta1.Metric{Name: "answer", Query: "answer"},
+			expected: 42,
+		},
+		{
+			desc:       "retry",
+			metric:     redskyv1beta1.Metric{Name: "answer", Query: "wait"},
+			retryAfter: time.Minute,
+		},
+	})
+}
+
+func TestCollectorFor(t *testing.T) {
+	c, err := CollectorFor("")
+	if assert.NoError(t, err) {
+		assert.NotNil(t, c)
+	}
+
+	_, err = CollectorFor("unknown")
+	assert.Error(t, err)
+}
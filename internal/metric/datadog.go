@@ -25,17 +25,13 @@ import (
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	datadog "github.com/zorkian/go-datadog-api"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func captureDatadogMetric(m *redskyv1beta1.Metric, startTime, completionTime time.Time) (float64, float64, error) {
-	apiKey := os.Getenv("DATADOG_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("DD_API_KEY")
-	}
-
-	applicationKey := os.Getenv("DATADOG_APP_KEY")
-	if applicationKey == "" {
-		applicationKey = os.Getenv("DD_APP_KEY")
+func captureDatadogMetric(m *redskyv1beta1.Metric, target runtime.Object, startTime, completionTime time.Time) (float64, float64, error) {
+	apiKey, applicationKey, err := datadogCredentials(target)
+	if err != nil {
+		return 0, 0, err
 	}
 
 	client := datadog.NewClient(apiKey, applicationKey)
@@ -84,3 +80,32 @@ func captureDatadogMetric(m *redskyv1beta1.Metric, startTime, completionTime tim
 
 	return value, math.NaN(), nil
 }
+
+// datadogCredentials returns the API and application keys used to access Datadog. If the metric
+// target is a secret, the "api-key" and "app-key" values are used, otherwise the keys come from
+// the environment.
+func datadogCredentials(target runtime.Object) (string, string, error) {
+	apiKey := os.Getenv("DATADOG_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("DD_API_KEY")
+	}
+
+	applicationKey := os.Getenv("DATADOG_APP_KEY")
+	if applicationKey == "" {
+		applicationKey = os.Getenv("DD_APP_KEY")
+	}
+
+	data, err := secretData(target)
+	if err != nil {
+		return "", "", err
+	}
+
+	if value, ok := data["api-key"]; ok {
+		apiKey = value
+	}
+	if value, ok := data["app-key"]; ok {
+		applicationKey = value
+	}
+
+	return apiKey, applicationKey, nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// defaultTimestampField is the document field used to limit searches to the trial window.
+const defaultTimestampField = "@timestamp"
+
+func captureElasticsearchMetric(ctx context.Context, m *redskyv1beta1.Metric, target runtime.Object, startTime, completionTime time.Time) (float64, float64, error) {
+	data, err := secretData(target)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	client, err := metricHTTPClient(m)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value, err := queryElasticsearch(ctx, client, m.URL, data, m.Query, startTime, completionTime)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	valueError := math.NaN()
+	if m.ErrorQuery != "" {
+		valueError, err = queryElasticsearch(ctx, client, m.URL, data, m.ErrorQuery, startTime, completionTime)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return value, valueError, nil
+}
+
+// queryElasticsearch executes a search request limited to the trial window and returns the numeric result.
+func queryElasticsearch(ctx context.Context, client *http.Client, address string, credentials map[string]string, query string, startTime, completionTime time.Time) (float64, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return 0, err
+	}
+	q := u.Query()
+	timestampField := q.Get("timestampField")
+	if timestampField == "" {
+		timestampField = defaultTimestampField
+	}
+	q.Del("timestampField")
+	u.Path = path.Join(u.Path, "_search")
+	u.RawQuery = q.Encode()
+
+	body, err := elasticsearchRequestBody(query, timestampField, startTime, completionTime)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey := credentials["apiKey"]; apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+apiKey)
+	} else if credentials["username"] != "" {
+		req.SetBasicAuth(credentials["username"], credentials["password"])
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, &CaptureError{Message: fmt.Sprintf("Elasticsearch query failed: %s", resp.Status), Address: address, Query: query}
+	}
+
+	result := &elasticsearchResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return 0, err
+	}
+
+	value, ok, err := result.value()
+	if err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, &CaptureError{Message: "metric data not available", Address: address, Query: query}
+	}
+	return value, nil
+}
+
+// elasticsearchRequestBody adds a range filter on the timestamp field to the supplied search request body, the
+// original query (if any) must also match.
+func elasticsearchRequestBody(query, timestampField string, startTime, completionTime time.Time) ([]byte, error) {
+	body := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(query), &body); err != nil {
+		return nil, fmt.Errorf("invalid Elasticsearch query: %w", err)
+	}
+
+	filter := map[string]interface{}{
+		"range": map[string]interface{}{
+			timestampField: map[string]interface{}{
+				"gte":    startTime.UTC().Format(time.RFC3339Nano),
+				"lte":    completionTime.UTC().Format(time.RFC3339Nano),
+				"format": "strict_date_optional_time",
+			},
+		},
+	}
+
+	boolQuery := map[string]interface{}{"filter": []interface{}{filter}}
+	if q, ok := body["query"]; ok {
+		boolQuery["must"] = []interface{}{q}
+	}
+	body["query"] = map[string]interface{}{"bool": boolQuery}
+
+	// Only the aggregations are needed, not the matching documents
+	if _, ok := body["size"]; !ok {
+		body["size"] = 0
+	}
+
+	return json.Marshal(body)
+}
+
+// elasticsearchResponse is the subset of the search response used to extract metric values.
+type elasticsearchResponse struct {
+	Hits struct {
+		// Total is an object in 7.x (and OpenSearch) and a number in earlier versions
+		Total json.RawMessage `json:"total"`
+	} `json:"hits"`
+	Aggregations map[string]elasticsearchAggregation `json:"aggregations"`
+}
+
+// elasticsearchAggregation is the subset of an aggregation result used to extract metric values.
+type elasticsearchAggregation struct {
+	Value    *float64           `json:"value"`
+	DocCount *float64           `json:"doc_count"`
+	Values   map[string]float64 `json:"values"`
+}
+
+// value returns the single aggregation value from the response, or the total number of hits if the
+// search did not include any aggregations.
+func (r *elasticsearchResponse) value() (float64, bool, error) {
+	if len(r.Aggregations) > 1 {
+		return 0, false, fmt.Errorf("expected one aggregation, got %d", len(r.Aggregations))
+	}
+
+	for _, agg := range r.Aggregations {
+		switch {
+		case agg.Value != nil:
+			return *agg.Value, true, nil
+		case len(agg.Values) == 1:
+			for _, v := range agg.Values {
+				return v, true, nil
+			}
+		case agg.DocCount != nil:
+			return *agg.DocCount, true, nil
+		}
+		return 0, false, nil
+	}
+
+	var count float64
+	if err := json.Unmarshal(r.Hits.Total, &count); err == nil {
+		return count, true, nil
+	}
+	var total struct {
+		Value *float64 `json:"value"`
+	}
+	if err := json.Unmarshal(r.Hits.Total, &total); err == nil && total.Value != nil {
+		return *total.Value, true, nil
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func captureInfluxDBMetric(ctx context.Context, m *redskyv1beta1.Metric, target runtime.Object, startTime, completionTime time.Time) (float64, float64, error) {
+	token, err := influxDBToken(target)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	client, err := metricHTTPClient(m)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value, err := queryInfluxDB(ctx, client, m.URL, token, m.Query, startTime, completionTime)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(value) == 0 {
+		return 0, 0, &CaptureError{Message: "metric data not available", Address: m.URL, Query: m.Query}
+	} else if len(value) > 1 {
+		return 0, 0, fmt.Errorf("expected one value, got %d", len(value))
+	}
+
+	valueError := math.NaN()
+	if m.ErrorQuery != "" {
+		errorValue, err := queryInfluxDB(ctx, client, m.URL, token, m.ErrorQuery, startTime, completionTime)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(errorValue) != 1 {
+			return 0, 0, fmt.Errorf("expected one error value, got %d", len(errorValue))
+		}
+		valueError = errorValue[0]
+	}
+
+	return value[0], valueError, nil
+}
+
+// queryInfluxDB executes a Flux query using the InfluxDB v2 query API and returns all of the
+// `_value` column values from the result. The trial window is available to the query using the
+// same `v.timeRangeStart` and `v.timeRangeStop` variables as the InfluxDB user interface.
+func queryInfluxDB(ctx context.Context, client *http.Client, address, token, query string, startTime, completionTime time.Time) ([]float64, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	u = u.ResolveReference(&url.URL{Path: "api/v2/query"})
+	u.RawQuery = q.Encode()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"type": "flux",
+		"query": fmt.Sprintf("option v = {timeRangeStart: %s, timeRangeStop: %s}\n%s",
+			startTime.UTC().Format(time.RFC3339), completionTime.UTC().Format(time.RFC3339), query),
+		"dialect": map[string]interface{}{
+			"header":      true,
+			"annotations": []string{},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/csv")
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &CaptureError{Message: fmt.Sprintf("InfluxDB query failed: %s", resp.Status), Address: address, Query: query}
+	}
+
+	return influxDBValues(resp.Body)
+}
+
+// influxDBValues extracts the `_value` column from a CSV encoded query response. Each table in
+// the response has it's own header row.
+func influxDBValues(r io.Reader) ([]float64, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var values []float64
+	col := -1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		// Look for the header row at the start of each table
+		if col < 0 || (col < len(record) && record[col] == "_value") {
+			col = -1
+			for i := range record {
+				if record[i] == "_value" {
+					col = i
+				}
+			}
+			continue
+		}
+
+		if col >= len(record) || record[col] == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(record[col], 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// influxDBToken returns the token used to authenticate with InfluxDB. If the metric target is a
+// secret, the "token" value is used, otherwise the token comes from the environment.
+func influxDBToken(target runtime.Object) (string, error) {
+	data, err := secretData(target)
+	if err != nil {
+		return "", err
+	}
+
+	if token, ok := data["token"]; ok {
+		return token, nil
+	}
+
+	return os.Getenv("INFLUXDB_TOKEN"), nil
+}
@@ -24,23 +24,26 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/util/jsonpath"
 )
 
 // TODO We need some type of client util to encapsulate this
 var httpClient = &http.Client{Timeout: 10 * time.Second}
 
-func captureJSONPathMetric(m *redskyv1beta1.Metric) (value float64, valueError float64, err error) {
-	// Fetch the URL
-	req, err := http.NewRequest(http.MethodGet, m.URL, nil)
+func captureJSONPathMetric(ctx context.Context, m *redskyv1beta1.Metric, target runtime.Object) (value float64, valueError float64, err error) {
+	// Check for credentials
+	credentials, err := secretData(target)
 	if err != nil {
 		return 0, 0, err
 	}
-	req.Header.Set("Accept", "application/json")
-	resp, err := httpClient.Do(req.WithContext(context.TODO()))
+
+	// Fetch the URL
+	resp, err := doJSONPathRequest(ctx, m, credentials)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -48,7 +51,10 @@ func captureJSONPathMetric(m *redskyv1beta1.Metric) (value float64, valueError f
 		_ = resp.Body.Close()
 	}()
 
-	// Check the response status
+	// Check the response status, server errors are retried using the metric's retry configuration
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return 0, 0, fmt.Errorf("metric request failed: %s", resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
 		// TODO Should we not ignore this?
 		return 0, math.NaN(), nil
@@ -90,3 +96,41 @@ func captureJSONPathMetric(m *redskyv1beta1.Metric) (value float64, valueError f
 	// If we made it this far we weren't able to extract the value
 	return 0, 0, fmt.Errorf("query '%s' did not match", m.Query)
 }
+
+// doJSONPathRequest fetches the JSON resource for a metric. The supplied credentials may contain a bearer "token"
+// or a "username" and "password" for basic authentication.
+func doJSONPathRequest(ctx context.Context, m *redskyv1beta1.Metric, credentials map[string]string) (*http.Response, error) {
+	method, body := http.MethodGet, ""
+	if m.HTTP != nil {
+		if m.HTTP.Method != "" {
+			method = m.HTTP.Method
+		}
+		body = m.HTTP.Body
+	}
+
+	client, err := metricHTTPClient(m)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, m.URL, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if credentials["token"] != "" {
+		req.Header.Set("Authorization", "Bearer "+credentials["token"])
+	} else if credentials["username"] != "" {
+		req.SetBasicAuth(credentials["username"], credentials["password"])
+	}
+	if m.HTTP != nil {
+		for _, h := range m.HTTP.Headers {
+			req.Header.Set(h.Name, h.Value)
+		}
+	}
+
+	return client.Do(req.WithContext(ctx))
+}
@@ -18,38 +18,196 @@ package metric
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/template"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // CaptureMetric captures a point-in-time metric value and it's error rate.
 func CaptureMetric(ctx context.Context, log logr.Logger, trial *redskyv1beta1.Trial, metric *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
-	// Execute the queries as Go templates
+	// Use the measurement window reported by the application instead of the observed trial run
 	var err error
+	if metric.Timestamps != nil {
+		if trial, err = reportedWindow(ctx, log, trial, metric, target); err != nil {
+			return 0, 0, err
+		}
+	} else if trial, err = warmedUpWindow(trial); err != nil {
+		return 0, 0, err
+	}
+
+	return captureValue(ctx, log, trial, metric, target)
+}
+
+// captureValue captures the metric value over the exact start and completion time of the supplied trial.
+func captureValue(ctx context.Context, log logr.Logger, trial *redskyv1beta1.Trial, metric *redskyv1beta1.Metric, target runtime.Object) (float64, float64, error) {
+	c, err := CollectorFor(metric.Type)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Execute the queries as Go templates
 	if metric.Query, metric.ErrorQuery, err = template.New().RenderMetricQueries(metric, trial, target); err != nil {
 		return 0, 0, err
 	}
 
-	// Capture the value based on the metric type
-	switch metric.Type {
-	case redskyv1beta1.MetricKubernetes, "":
-		value, err := strconv.ParseFloat(metric.Query, 64)
-		return value, math.NaN(), err
-	case redskyv1beta1.MetricPrometheus:
-		return capturePrometheusMetric(ctx, log, metric, trial.Status.CompletionTime.Time)
-	case redskyv1beta1.MetricDatadog:
-		return captureDatadogMetric(metric, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
-	case redskyv1beta1.MetricJSONPath:
-		return captureJSONPathMetric(metric)
-	case redskyv1beta1.MetricNewRelic:
-		return captureNewRelicMetric(metric, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
+	// Capture the value using the collector for the metric type
+	return c.Collect(ctx, log, trial, metric, target)
+}
+
+// warmedUpWindow returns a copy of the trial whose start time excludes the warm-up period of the trial run.
+func warmedUpWindow(trial *redskyv1beta1.Trial) (*redskyv1beta1.Trial, error) {
+	if trial.Spec.WarmupDuration == nil || trial.Spec.WarmupDuration.Duration <= 0 || trial.Status.StartTime == nil {
+		return trial, nil
+	}
+
+	t := trial.DeepCopy()
+	startTime := metav1.NewTime(t.Status.StartTime.Add(t.Spec.WarmupDuration.Duration))
+	t.Status.StartTime = &startTime
+
+	if t.Status.CompletionTime != nil && !t.Status.StartTime.Before(t.Status.CompletionTime) {
+		return nil, fmt.Errorf("warm-up duration %s exceeds the trial run", t.Spec.WarmupDuration.Duration)
+	}
+
+	return t, nil
+}
+
+// CaptureSample captures an intermediate metric value while the trial is still running. The metric is
+// evaluated as if the trial had completed shortly before the sample time to allow for collection latency.
+func CaptureSample(ctx context.Context, log logr.Logger, trial *redskyv1beta1.Trial, metric *redskyv1beta1.Metric, target runtime.Object, sampleTime time.Time) (float64, error) {
+	t := trial.DeepCopy()
+	completionTime := metav1.NewTime(sampleTime.Add(-2 * scrapeInterval))
+	t.Status.CompletionTime = &completionTime
+
+	value, _, err := CaptureMetric(ctx, log, t, metric, target)
+	return value, err
+}
+
+// Aggregate reduces a range of values to a single value using the named aggregation function. An empty
+// range of values produces NaN.
+func Aggregate(fn redskyv1beta1.MetricAggregationFunction, values []float64) (float64, error) {
+	var pct float64
+	switch fn {
+	case redskyv1beta1.MetricAggregationAverage, redskyv1beta1.MetricAggregationMinimum,
+		redskyv1beta1.MetricAggregationMaximum, redskyv1beta1.MetricAggregationSum, "":
 	default:
-		return 0, 0, fmt.Errorf("unknown metric type: %s", metric.Type)
+		p, err := strconv.ParseFloat(strings.TrimPrefix(string(fn), "p"), 64)
+		if !strings.HasPrefix(string(fn), "p") || err != nil || p < 0 || p > 100 {
+			return 0, fmt.Errorf("unknown aggregation function: %s", fn)
+		}
+		pct = p
+	}
+
+	if len(values) == 0 {
+		return math.NaN(), nil
+	}
+
+	switch fn {
+	case redskyv1beta1.MetricAggregationMinimum:
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Min(result, v)
+		}
+		return result, nil
+	case redskyv1beta1.MetricAggregationMaximum:
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Max(result, v)
+		}
+		return result, nil
+	case redskyv1beta1.MetricAggregationSum, redskyv1beta1.MetricAggregationAverage, "":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		if fn == redskyv1beta1.MetricAggregationSum {
+			return sum, nil
+		}
+		return sum / float64(len(values)), nil
+	}
+
+	// Interpolate between the closest ranks of the sorted values
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := pct / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1], nil
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo]), nil
+}
+
+// DefaultRetries is the number of times metric collection is retried when the metric does not specify a value.
+const DefaultRetries = 2
+
+// Attempts returns the total number of attempts allowed when collecting a metric.
+func Attempts(metric *redskyv1beta1.Metric) int {
+	if metric.Retries == nil {
+		return DefaultRetries + 1
 	}
+	if *metric.Retries < 0 {
+		return 1
+	}
+	return int(*metric.Retries) + 1
+}
+
+// RetryDelay returns the amount of time to wait before attempting to collect a metric after the specified
+// number of failed attempts. The configured delay is doubled after each failure.
+func RetryDelay(metric *redskyv1beta1.Metric, failures int) time.Duration {
+	if metric.RetryDelay == nil || failures <= 0 {
+		return 0
+	}
+	if failures > 10 {
+		failures = 10
+	}
+	return metric.RetryDelay.Duration << (failures - 1)
+}
+
+// ApplyTarget returns the value to record for a metric with a target value: values that are better
+// than the target (e.g. below the target of a minimized metric) are recorded as the target itself so
+// overshooting the target is not considered an improvement.
+func ApplyTarget(metric *redskyv1beta1.Metric, value float64) float64 {
+	if metric.TargetValue == nil {
+		return value
+	}
+
+	target, _ := strconv.ParseFloat(metric.TargetValue.AsDec().String(), 64)
+	if metric.Minimize {
+		return math.Max(value, target)
+	}
+	return math.Min(value, target)
+}
+
+// secretData returns the decoded data of the supplied target, the result is nil if the target is not a secret.
+func secretData(target runtime.Object) (map[string]string, error) {
+	u, ok := target.(*unstructured.Unstructured)
+	if !ok || u.GetKind() != "Secret" || u.GetAPIVersion() != "v1" {
+		return nil, nil
+	}
+
+	data, _, err := unstructured.NestedStringMap(u.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(data))
+	for key, encoded := range data {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret data %q: %w", key, err)
+		}
+		result[key] = string(decoded)
+	}
+
+	return result, nil
 }
@@ -19,17 +19,23 @@ package metric
 import (
 	"context"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -50,6 +56,15 @@ func TestCaptureMetric(t *testing.T) {
 	promHttpTest := promHttpTestServer()
 	defer promHttpTest.Close()
 
+	influxDBHttpTest := influxDBHttpTestServer()
+	defer influxDBHttpTest.Close()
+
+	elasticsearchHttpTest := elasticsearchHttpTestServer()
+	defer elasticsearchHttpTest.Close()
+
+	jsonPathPostHttpTest := jsonPathPostHttpTestServer()
+	defer jsonPathPostHttpTest.Close()
+
 	testCases := []struct {
 		desc     string
 		metric   *redskyv1beta1.Metric
@@ -117,6 +132,97 @@ func TestCaptureMetric(t *testing.T) {
 			},
 			expected: 5,
 		},
+
+		{
+			desc: "jsonpath http options",
+			metric: &redskyv1beta1.Metric{
+				Name:  "testMetric",
+				Query: "{.current_response_time_percentile_95}",
+				Type:  redskyv1beta1.MetricJSONPath,
+				URL:   jsonPathPostHttpTest.URL,
+				HTTP: &redskyv1beta1.MetricHTTP{
+					Method:  http.MethodPost,
+					Body:    `{"percentile":95}`,
+					Headers: []redskyv1beta1.MetricHTTPHeader{{Name: "Authorization", Value: "Bearer token"}},
+				},
+			},
+			expected: 7,
+		},
+
+		{
+			desc: "jsonpath secret credentials",
+			metric: &redskyv1beta1.Metric{
+				Name:  "testMetric",
+				Query: "{.current_response_time_percentile_95}",
+				Type:  redskyv1beta1.MetricJSONPath,
+				URL:   jsonPathPostHttpTest.URL,
+				HTTP: &redskyv1beta1.MetricHTTP{
+					Method: http.MethodPost,
+					Body:   `{"percentile":95}`,
+				},
+			},
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"data": map[string]interface{}{
+					"token": "dG9rZW4=", // token
+				},
+			}},
+			expected: 7,
+		},
+
+		{
+			desc: "influxdb url",
+			metric: &redskyv1beta1.Metric{
+				Name:  "testMetric",
+				Query: `from(bucket: "telegraf") |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> mean()`,
+				Type:  redskyv1beta1.MetricInfluxDB,
+				URL:   influxDBHttpTest.URL + "?org=test",
+			},
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"data": map[string]interface{}{
+					"token": "dG9rZW4=", // token
+				},
+			}},
+			expected: 42.5,
+		},
+
+		{
+			desc: "elasticsearch aggregation",
+			metric: &redskyv1beta1.Metric{
+				Name:  "testMetric",
+				Query: `{"query":{"match":{"level":"error"}},"aggs":{"errors":{"value_count":{"field":"level"}}}}`,
+				Type:  redskyv1beta1.MetricElasticsearch,
+				URL:   elasticsearchHttpTest.URL + "/logs-*?timestampField=time",
+			},
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"data": map[string]interface{}{
+					"apiKey": "a2V5", // key
+				},
+			}},
+			expected: 12,
+		},
+		{
+			desc: "elasticsearch hits",
+			metric: &redskyv1beta1.Metric{
+				Name:  "testMetric",
+				Query: `{}`,
+				Type:  redskyv1beta1.MetricElasticsearch,
+				URL:   elasticsearchHttpTest.URL + "/logs-*?timestampField=time",
+			},
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"data": map[string]interface{}{
+					"apiKey": "a2V5", // key
+				},
+			}},
+			expected: 34,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -136,6 +242,251 @@ func TestCaptureMetric(t *testing.T) {
 	}
 }
 
+func TestCaptureMetricServerError(t *testing.T) {
+	// Server errors must fail the collection attempt so it is retried by the controller
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	m := &redskyv1beta1.Metric{
+		Name:  "testMetric",
+		Query: "{.current_response_time_percentile_95}",
+		Type:  redskyv1beta1.MetricJSONPath,
+		URL:   ts.URL,
+	}
+	_, _, err := CaptureMetric(context.TODO(), zap.New(zap.UseDevMode(true)), &redskyv1beta1.Trial{}, m, nil)
+	assert.EqualError(t, err, "metric request failed: 503 Service Unavailable")
+}
+
+func TestDatadogCredentials(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"api-key": "YXBpa2V5", // apikey
+			"app-key": "YXBwa2V5", // appkey
+		},
+	}}
+
+	apiKey, appKey, err := datadogCredentials(secret)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "apikey", apiKey)
+		assert.Equal(t, "appkey", appKey)
+	}
+}
+
+func TestMetricsServer(t *testing.T) {
+	m := &redskyv1beta1.Metric{Name: "cpu-usage", Type: redskyv1beta1.MetricMetricsServer, Query: "cpu"}
+	trial := &redskyv1beta1.Trial{}
+
+	// Record two samples of pod metrics
+	for _, cpu := range []string{"250m", "750m"} {
+		podMetrics := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "usage": map[string]interface{}{"cpu": cpu, "memory": "128Mi"}},
+					map[string]interface{}{"name": "sidecar", "usage": map[string]interface{}{"cpu": "100m", "memory": "32Mi"}},
+				},
+			}},
+		}}
+
+		usage, err := MetricsServerUsage(m, podMetrics)
+		require.NoError(t, err)
+
+		samples, err := GetSamples(trial)
+		require.NoError(t, err)
+		samples.Add(m.Name, usage)
+		require.NoError(t, SetSamples(trial, samples))
+	}
+
+	value, valueError, err := captureMetricsServerMetric(m, trial)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 0.6, value, 0.0001)
+		assert.InDelta(t, 0.25, valueError, 0.0001)
+	}
+
+	_, _, err = captureMetricsServerMetric(&redskyv1beta1.Metric{Name: "memory-usage", Query: "memory"}, trial)
+	assert.Error(t, err)
+}
+
+func TestApplyTarget(t *testing.T) {
+	target := resource.MustParse("200m")
+	largeTarget := resource.MustParse("20G")
+	cases := []struct {
+		desc     string
+		metric   *redskyv1beta1.Metric
+		value    float64
+		expected float64
+	}{
+		{
+			desc:     "no target",
+			metric:   &redskyv1beta1.Metric{Minimize: true},
+			value:    0.1,
+			expected: 0.1,
+		},
+		{
+			desc:     "minimize above target",
+			metric:   &redskyv1beta1.Metric{Minimize: true, TargetValue: &target},
+			value:    0.3,
+			expected: 0.3,
+		},
+		{
+			desc:     "minimize below target",
+			metric:   &redskyv1beta1.Metric{Minimize: true, TargetValue: &target},
+			value:    0.1,
+			expected: 0.2,
+		},
+		{
+			desc:     "maximize above target",
+			metric:   &redskyv1beta1.Metric{TargetValue: &target},
+			value:    0.3,
+			expected: 0.2,
+		},
+		{
+			desc:     "maximize below target",
+			metric:   &redskyv1beta1.Metric{TargetValue: &target},
+			value:    0.1,
+			expected: 0.1,
+		},
+		{
+			desc:     "large target",
+			metric:   &redskyv1beta1.Metric{TargetValue: &largeTarget},
+			value:    3e10,
+			expected: 2e10,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ApplyTarget(tc.metric, tc.value))
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	values := []float64{4, 1, 3, 2, 5}
+	cases := []struct {
+		fn       redskyv1beta1.MetricAggregationFunction
+		values   []float64
+		expected float64
+		hasError bool
+	}{
+		{fn: "", values: values, expected: 3},
+		{fn: redskyv1beta1.MetricAggregationAverage, values: values, expected: 3},
+		{fn: redskyv1beta1.MetricAggregationMinimum, values: values, expected: 1},
+		{fn: redskyv1beta1.MetricAggregationMaximum, values: values, expected: 5},
+		{fn: redskyv1beta1.MetricAggregationSum, values: values, expected: 15},
+		{fn: "p50", values: values, expected: 3},
+		{fn: "p100", values: values, expected: 5},
+		{fn: "p90", values: values, expected: 4.6},
+		{fn: "p99", values: []float64{7}, expected: 7},
+		{fn: "p101", values: values, hasError: true},
+		{fn: "median", values: values, hasError: true},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.fn), func(t *testing.T) {
+			actual, err := Aggregate(tc.fn, tc.values)
+			if tc.hasError {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.InDelta(t, tc.expected, actual, 0.0001)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	var none int32
+	cases := []struct {
+		desc             string
+		metric           *redskyv1beta1.Metric
+		failures         int
+		expectedAttempts int
+		expectedDelay    time.Duration
+	}{
+		{
+			desc:             "default",
+			metric:           &redskyv1beta1.Metric{},
+			failures:         1,
+			expectedAttempts: 3,
+		},
+		{
+			desc:             "no retries",
+			metric:           &redskyv1beta1.Metric{Retries: &none},
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "first attempt",
+			metric:           &redskyv1beta1.Metric{RetryDelay: &metav1.Duration{Duration: 10 * time.Second}},
+			expectedAttempts: 3,
+		},
+		{
+			desc:             "first retry",
+			metric:           &redskyv1beta1.Metric{RetryDelay: &metav1.Duration{Duration: 10 * time.Second}},
+			failures:         1,
+			expectedAttempts: 3,
+			expectedDelay:    10 * time.Second,
+		},
+		{
+			desc:             "backoff",
+			metric:           &redskyv1beta1.Metric{RetryDelay: &metav1.Duration{Duration: 10 * time.Second}},
+			failures:         3,
+			expectedAttempts: 3,
+			expectedDelay:    40 * time.Second,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAttempts, Attempts(tc.metric))
+			assert.Equal(t, tc.expectedDelay, RetryDelay(tc.metric, tc.failures))
+		})
+	}
+}
+
+func TestMetricTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"value":3}`)
+	}))
+	defer srv.Close()
+
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	cases := []struct {
+		desc     string
+		tls      *redskyv1beta1.MetricTLS
+		hasError bool
+	}{
+		{
+			desc:     "unknown authority",
+			hasError: true,
+		},
+		{
+			desc: "certificate authority",
+			tls:  &redskyv1beta1.MetricTLS{CA: &redskyv1beta1.MetricTLSData{Value: ca}},
+		},
+		{
+			desc:     "invalid certificate authority",
+			tls:      &redskyv1beta1.MetricTLS{CA: &redskyv1beta1.MetricTLSData{Value: "invalid"}},
+			hasError: true,
+		},
+		{
+			desc: "insecure skip verify",
+			tls:  &redskyv1beta1.MetricTLS{InsecureSkipVerify: true},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			m := &redskyv1beta1.Metric{Name: "value", Type: redskyv1beta1.MetricJSONPath, URL: srv.URL, Query: "{.value}", TLS: tc.tls}
+			value, _, err := captureJSONPathMetric(context.Background(), m, nil)
+			if tc.hasError {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, 3.0, value)
+			}
+		})
+	}
+}
+
 func jsonPathHttpTestServer() *httptest.Server {
 	response := map[string]int{"current_response_time_percentile_95": 5}
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -143,9 +494,263 @@ func jsonPathHttpTestServer() *httptest.Server {
 	}))
 }
 
+func jsonPathPostHttpTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer token" || string(body) != `{"percentile":95}`:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			fmt.Fprint(w, `{"current_response_time_percentile_95":7}`)
+		}
+	}))
+}
+
 func promHttpTestServer() *httptest.Server {
 	resp := `{"status":"success","data":{"resultType":"scalar","result":[1595471900.283,"1"]}}`
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, resp)
 	}))
 }
+
+func influxDBHttpTestServer() *httptest.Server {
+	resp := ",result,table,_start,_stop,_value\r\n,_result,0,2021-01-01T00:00:00Z,2021-01-01T00:05:00Z,42.5\r\n\r\n"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/query" || r.URL.Query().Get("org") != "test" || r.Header.Get("Authorization") != "Token token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, resp)
+	}))
+}
+
+func elasticsearchHttpTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make(map[string]interface{})
+		if r.URL.Path != "/logs-*/_search" || r.URL.RawQuery != "" || r.Header.Get("Authorization") != "ApiKey key" ||
+			json.NewDecoder(r.Body).Decode(&body) != nil || !strings.Contains(fmt.Sprint(body["query"]), "range:map[time:") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := body["aggs"]; ok {
+			fmt.Fprint(w, `{"hits":{"total":{"value":34,"relation":"eq"}},"aggregations":{"errors":{"value":12}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"hits":{"total":{"value":34,"relation":"eq"}}}`)
+	}))
+}
+
+func TestSamplePoints(t *testing.T) {
+	start := metav1.NewTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := &redskyv1beta1.Metric{Name: "latency", SampleInterval: &metav1.Duration{Duration: time.Minute}}
+
+	s := &Samples{}
+	assert.Equal(t, start.Add(time.Minute), s.NextPointTime(m, start))
+	assert.True(t, s.NextPointTime(&redskyv1beta1.Metric{Name: "latency"}, start).IsZero())
+
+	for i := 0; i < MaxSamplePoints+5; i++ {
+		s.AddPoint(m.Name, metav1.NewTime(start.Add(time.Duration(i+1)*time.Minute)), float64(i))
+	}
+	if assert.Len(t, s.Series[m.Name], MaxSamplePoints) {
+		assert.Equal(t, float64(5), s.Series[m.Name][0].Value)
+	}
+	assert.Equal(t, start.Add(time.Duration(MaxSamplePoints+6)*time.Minute), s.NextPointTime(m, start))
+}
+
+func TestReportedWindow(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	startTime, completionTime := metav1.NewTime(start), metav1.NewTime(start.Add(10*time.Minute))
+	tt := &redskyv1beta1.Trial{
+		Status: redskyv1beta1.TrialStatus{StartTime: &startTime, CompletionTime: &completionTime},
+	}
+
+	cases := []struct {
+		desc          string
+		timestamps    *redskyv1beta1.MetricTimestamps
+		expected      float64
+		expectedError bool
+	}{
+		{
+			desc:     "observed",
+			expected: 600,
+		},
+		{
+			desc:       "reported start",
+			timestamps: &redskyv1beta1.MetricTimestamps{StartQuery: strconv.FormatInt(start.Add(time.Minute).Unix(), 10)},
+			expected:   540,
+		},
+		{
+			desc: "reported window",
+			timestamps: &redskyv1beta1.MetricTimestamps{
+				StartQuery: strconv.FormatInt(start.Add(2*time.Minute).Unix(), 10),
+				EndQuery:   strconv.FormatInt(start.Add(12*time.Minute).Unix(), 10),
+			},
+			expected: 600,
+		},
+		{
+			desc:          "inverted window",
+			timestamps:    &redskyv1beta1.MetricTimestamps{EndQuery: strconv.FormatInt(start.Add(-time.Minute).Unix(), 10)},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid timestamp",
+			timestamps:    &redskyv1beta1.MetricTimestamps{StartQuery: "0"},
+			expectedError: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			m := &redskyv1beta1.Metric{
+				Name:       "duration",
+				Type:       redskyv1beta1.MetricKubernetes,
+				Query:      "{{ duration .StartTime .CompletionTime }}",
+				Timestamps: c.timestamps,
+			}
+			value, _, err := CaptureMetric(context.TODO(), zap.New(zap.UseDevMode(true)), tt, m, nil)
+			if c.expectedError {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, value)
+			}
+		})
+	}
+}
+
+func TestWarmupDuration(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	startTime, completionTime := metav1.NewTime(start), metav1.NewTime(start.Add(10*time.Minute))
+
+	cases := []struct {
+		desc          string
+		warmup        *metav1.Duration
+		expected      float64
+		expectedError bool
+	}{
+		{
+			desc:     "no warm-up",
+			expected: 600,
+		},
+		{
+			desc:     "warm-up",
+			warmup:   &metav1.Duration{Duration: 2 * time.Minute},
+			expected: 480,
+		},
+		{
+			desc:          "warm-up exceeds run",
+			warmup:        &metav1.Duration{Duration: 10 * time.Minute},
+			expectedError: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tt := &redskyv1beta1.Trial{
+				Spec:   redskyv1beta1.TrialSpec{WarmupDuration: c.warmup},
+				Status: redskyv1beta1.TrialStatus{StartTime: &startTime, CompletionTime: &completionTime},
+			}
+			m := &redskyv1beta1.Metric{
+				Name:  "duration",
+				Type:  redskyv1beta1.MetricKubernetes,
+				Query: "{{ duration .StartTime .CompletionTime }}",
+			}
+			value, _, err := CaptureMetric(context.TODO(), zap.New(zap.UseDevMode(true)), tt, m, nil)
+			if c.expectedError {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, value)
+				assert.Equal(t, start, tt.Status.StartTime.Time)
+			}
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	now := time.Now()
+	observedStart, observedEnd := metav1.NewTime(now.Add(-time.Hour)), metav1.NewTime(now)
+	observed := &redskyv1beta1.Trial{Status: redskyv1beta1.TrialStatus{StartTime: &observedStart, CompletionTime: &observedEnd}}
+
+	cases := []struct {
+		desc     string
+		start    time.Time
+		end      time.Time
+		expected time.Duration
+	}{
+		{desc: "synchronized", start: observedStart.Add(time.Minute), end: observedEnd.Add(-time.Minute)},
+		{desc: "within tolerance", start: observedStart.Add(-time.Second), end: observedEnd.Add(time.Second)},
+		{desc: "behind", start: observedStart.Add(-time.Minute), end: observedEnd.Add(-time.Minute), expected: -time.Minute},
+		{desc: "ahead", start: observedStart.Add(time.Minute), end: observedEnd.Add(time.Minute), expected: time.Minute},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			reportedStart, reportedEnd := metav1.NewTime(c.start), metav1.NewTime(c.end)
+			reported := &redskyv1beta1.Trial{Status: redskyv1beta1.TrialStatus{StartTime: &reportedStart, CompletionTime: &reportedEnd}}
+			assert.Equal(t, c.expected, ClockSkew(observed, reported))
+		})
+	}
+}
+
+func TestInvokeWebhook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &WebhookRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch req.Trial {
+		case "default/convert":
+			fmt.Fprint(w, `{"values":[{"name":"cost","value":"0.85"}]}`)
+		case "default/unknown":
+			fmt.Fprint(w, `{"values":[{"name":"score","value":"1"}]}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       redskyv1beta1.ExperimentSpec{ValueWebhook: &redskyv1beta1.ValueWebhook{URL: ts.URL}},
+	}
+
+	cases := []struct {
+		desc          string
+		trial         string
+		expected      []redskyv1beta1.Value
+		expectedError bool
+	}{
+		{
+			desc:     "adjusted",
+			trial:    "convert",
+			expected: []redskyv1beta1.Value{{Name: "cost", Value: "0.85"}, {Name: "latency", Value: "120"}},
+		},
+		{
+			desc:          "unknown metric",
+			trial:         "unknown",
+			expected:      []redskyv1beta1.Value{{Name: "cost", Value: "1"}, {Name: "latency", Value: "120"}},
+			expectedError: true,
+		},
+		{
+			desc:          "server error",
+			trial:         "error",
+			expected:      []redskyv1beta1.Value{{Name: "cost", Value: "1"}, {Name: "latency", Value: "120"}},
+			expectedError: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tt := &redskyv1beta1.Trial{
+				ObjectMeta: metav1.ObjectMeta{Name: c.trial, Namespace: "default"},
+				Spec: redskyv1beta1.TrialSpec{Values: []redskyv1beta1.Value{
+					{Name: "cost", Value: "1"},
+					{Name: "latency", Value: "120"},
+				}},
+			}
+			err := InvokeWebhook(context.TODO(), exp, tt)
+			if c.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.expected, tt.Spec.Values)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MetricsServerSampleInterval is the minimum amount of time between samples of the metrics server.
+const MetricsServerSampleInterval = 15 * time.Second
+
+// MaxSamplePoints is the maximum number of intermediate values retained for each metric.
+const MaxSamplePoints = 100
+
+// Samples is the usage recorded from the metrics server while a trial is running.
+type Samples struct {
+	// The time of the most recent sample.
+	LastSampleTime metav1.Time `json:"lastSampleTime"`
+	// The summary of the sampled values, indexed by metric name.
+	Metrics map[string]SampleSummary `json:"metrics,omitempty"`
+	// The intermediate values of metrics with a sample interval, indexed by metric name.
+	Series map[string][]SamplePoint `json:"series,omitempty"`
+}
+
+// SamplePoint is an intermediate metric value captured while a trial is running.
+type SamplePoint struct {
+	Time  metav1.Time `json:"time"`
+	Value float64     `json:"value"`
+}
+
+// SampleSummary contains enough information to compute the mean and standard deviation of the samples.
+type SampleSummary struct {
+	Count      int     `json:"count"`
+	Sum        float64 `json:"sum"`
+	SumSquares float64 `json:"sumSquares"`
+}
+
+// GetSamples returns the samples recorded on the trial.
+func GetSamples(t *redskyv1beta1.Trial) (*Samples, error) {
+	s := &Samples{}
+	if data, ok := t.GetAnnotations()[redskyv1beta1.AnnotationMetricSamples]; ok {
+		if err := json.Unmarshal([]byte(data), s); err != nil {
+			return nil, fmt.Errorf("invalid metric samples: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// SetSamples records the samples on the trial.
+func SetSamples(t *redskyv1beta1.Trial, s *Samples) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if t.GetAnnotations() == nil {
+		t.SetAnnotations(make(map[string]string))
+	}
+	t.GetAnnotations()[redskyv1beta1.AnnotationMetricSamples] = string(data)
+	return nil
+}
+
+// Add records a new sample for the named metric.
+func (s *Samples) Add(name string, value float64) {
+	if s.Metrics == nil {
+		s.Metrics = make(map[string]SampleSummary)
+	}
+
+	ss := s.Metrics[name]
+	ss.Count++
+	ss.Sum += value
+	ss.SumSquares += value * value
+	s.Metrics[name] = ss
+}
+
+// AddPoint records a new intermediate value for the named metric, discarding the oldest values
+// once the maximum number of points is reached.
+func (s *Samples) AddPoint(name string, t metav1.Time, value float64) {
+	if s.Series == nil {
+		s.Series = make(map[string][]SamplePoint)
+	}
+
+	points := append(s.Series[name], SamplePoint{Time: t, Value: value})
+	if len(points) > MaxSamplePoints {
+		points = points[len(points)-MaxSamplePoints:]
+	}
+	s.Series[name] = points
+}
+
+// NextPointTime returns the time the next intermediate value of the metric should be captured.
+func (s *Samples) NextPointTime(m *redskyv1beta1.Metric, startTime metav1.Time) time.Time {
+	if m.SampleInterval == nil {
+		return time.Time{}
+	}
+
+	last := startTime
+	if points := s.Series[m.Name]; len(points) > 0 {
+		last = points[len(points)-1].Time
+	}
+	return last.Add(m.SampleInterval.Duration)
+}
+
+// Mean returns the average of the samples.
+func (ss SampleSummary) Mean() float64 {
+	return ss.Sum / float64(ss.Count)
+}
+
+// StdDev returns the standard deviation of the samples.
+func (ss SampleSummary) StdDev() float64 {
+	mean := ss.Mean()
+	return math.Sqrt(math.Max(ss.SumSquares/float64(ss.Count)-mean*mean, 0))
+}
+
+// MetricsServerUsage returns the total usage of the resource named by the metric query from the
+// supplied pod or node metrics. CPU usage is measured in cores and memory usage in bytes.
+func MetricsServerUsage(m *redskyv1beta1.Metric, target runtime.Object) (float64, error) {
+	var items []unstructured.Unstructured
+	switch t := target.(type) {
+	case *unstructured.Unstructured:
+		items = append(items, *t)
+	case *unstructured.UnstructuredList:
+		items = t.Items
+	default:
+		return 0, fmt.Errorf("unexpected metrics server target: %T", target)
+	}
+
+	var total float64
+	for i := range items {
+		// Pod metrics report usage per container, node metrics report usage directly
+		usages := []interface{}{items[i].Object["usage"]}
+		if containers, ok, _ := unstructured.NestedSlice(items[i].Object, "containers"); ok {
+			usages = usages[:0]
+			for _, c := range containers {
+				if c, ok := c.(map[string]interface{}); ok {
+					usages = append(usages, c["usage"])
+				}
+			}
+		}
+
+		for _, u := range usages {
+			usage, ok := u.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			value, ok := usage[m.Query].(string)
+			if !ok {
+				continue
+			}
+
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				return 0, err
+			}
+			total += float64(q.MilliValue()) / 1000
+		}
+	}
+
+	return total, nil
+}
+
+func captureMetricsServerMetric(m *redskyv1beta1.Metric, trial *redskyv1beta1.Trial) (float64, float64, error) {
+	s, err := GetSamples(trial)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ss, ok := s.Metrics[m.Name]
+	if !ok || ss.Count == 0 {
+		return 0, 0, &CaptureError{Message: "metric data not available", Query: m.Query}
+	}
+
+	return ss.Mean(), ss.StdDev(), nil
+}
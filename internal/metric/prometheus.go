@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/http"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,27 +28,28 @@ import (
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-// CaptureError describes problems that arise while capturing Prometheus metric values.
-type CaptureError struct {
-	// A description of what went wrong
-	Message string
-	// The URL that was used to capture the metric
-	Address string
-	// The metric query that failed
-	Query string
-	// The minimum amount of time until the metric is expected to be available
-	RetryAfter time.Duration
-}
+func capturePrometheusMetric(ctx context.Context, log logr.Logger, m *redskyv1beta1.Metric, target runtime.Object, startTime, completionTime time.Time) (value float64, valueError float64, err error) {
+	// Check for credentials
+	data, err := secretData(target)
+	if err != nil {
+		return 0, 0, err
+	}
 
-func (e *CaptureError) Error() string {
-	return e.Message
-}
+	var headers []redskyv1beta1.MetricHTTPHeader
+	if m.HTTP != nil {
+		headers = m.HTTP.Headers
+	}
+
+	next, err := metricTransport(m, prom.DefaultRoundTripper)
+	if err != nil {
+		return 0, 0, err
+	}
 
-func capturePrometheusMetric(ctx context.Context, log logr.Logger, m *redskyv1beta1.Metric, completionTime time.Time) (value float64, valueError float64, err error) {
 	// Get the Prometheus API
-	c, err := prom.NewClient(prom.Config{Address: m.URL})
+	c, err := prom.NewClient(prom.Config{Address: m.URL, RoundTripper: prometheusRoundTripper(next, data, headers)})
 	if err != nil {
 		return 0, 0, err
 	}
@@ -59,14 +61,23 @@ func capturePrometheusMetric(ctx context.Context, log logr.Logger, m *redskyv1be
 		return 0, 0, err
 	}
 
+	// Scrapes from the future indicate the Prometheus clock is ahead of ours
+	if skew := lastScrapeEndTime.Sub(time.Now()); skew > MaxClockSkew {
+		log.Info("Possible clock skew detected, Prometheus reported a scrape in the future", "lastScrapeEndTime", lastScrapeEndTime, "skew", skew.String())
+	}
+
 	// Execute the query
-	value, err = queryScalar(ctx, promAPI, m.Query, completionTime)
+	if m.Aggregation != nil {
+		value, err = queryAggregate(ctx, promAPI, m.Query, m.Aggregation, startTime, completionTime)
+	} else {
+		value, err = queryScalar(ctx, promAPI, m.Query, completionTime)
+	}
 	if err != nil {
 		return 0, 0, err
 	}
 
 	// If we got NaN, it might be that the final scrape hadn't finished
-	if math.IsNaN(value) && lastScrapeEndTime.After(completionTime) {
+	if math.IsNaN(value) && m.Aggregation == nil && lastScrapeEndTime.After(completionTime) {
 		log.Info("Retrying Prometheus query to include final scrape", "lastScrapeEndTime", lastScrapeEndTime)
 
 		value, err = queryScalar(ctx, promAPI, m.Query, lastScrapeEndTime)
@@ -152,3 +163,79 @@ func queryScalar(ctx context.Context, api promv1.API, q string, t time.Time) (fl
 		return 0, fmt.Errorf("expected scalar query result, got %s", v.Type())
 	}
 }
+
+// prometheusRoundTripper returns the round tripper used to make Prometheus API requests. The supplied secret
+// data may contain a bearer "token" or a "username" and "password" for basic authentication; any additional
+// headers (e.g. from the metric HTTP options) are also included on each request.
+func prometheusRoundTripper(next http.RoundTripper, data map[string]string, headers []redskyv1beta1.MetricHTTPHeader) http.RoundTripper {
+	rt := &prometheusAuthRoundTripper{next: next, headers: headers}
+	rt.token, rt.username, rt.password = data["token"], data["username"], data["password"]
+	if rt.token == "" && rt.username == "" && len(rt.headers) == 0 {
+		return rt.next
+	}
+	return rt
+}
+
+// prometheusAuthRoundTripper adds authorization to Prometheus API requests.
+type prometheusAuthRoundTripper struct {
+	token    string
+	username string
+	password string
+	headers  []redskyv1beta1.MetricHTTPHeader
+	next     http.RoundTripper
+}
+
+func (rt *prometheusAuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if rt.token != "" {
+		req.Header.Set("Authorization", "Bearer "+rt.token)
+	} else if rt.username != "" {
+		req.SetBasicAuth(rt.username, rt.password)
+	}
+	for _, h := range rt.headers {
+		req.Header.Set(h.Name, h.Value)
+	}
+	return rt.next.RoundTrip(req)
+}
+
+// queryAggregate evaluates a range query over the aggregation window and reduces the values to a single value.
+// Similar to `queryScalar`, NaN is returned if the result isn't a single series.
+func queryAggregate(ctx context.Context, api promv1.API, q string, a *redskyv1beta1.MetricAggregation, startTime, completionTime time.Time) (float64, error) {
+	v, _, err := api.QueryRange(ctx, q, aggregationRange(a, startTime, completionTime))
+	if err != nil {
+		return 0, err
+	}
+
+	vt, ok := v.(model.Matrix)
+	if !ok {
+		return 0, fmt.Errorf("expected range query result, got %s", v.Type())
+	}
+	if len(vt) != 1 {
+		return math.NaN(), nil
+	}
+
+	values := make([]float64, 0, len(vt[0].Values))
+	for _, p := range vt[0].Values {
+		values = append(values, float64(p.Value))
+	}
+	return Aggregate(a.Function, values)
+}
+
+// aggregationRange returns the range of the aggregation window relative to the trial start or completion time.
+func aggregationRange(a *redskyv1beta1.MetricAggregation, startTime, completionTime time.Time) promv1.Range {
+	window := completionTime.Sub(startTime)
+	if a.Window != nil {
+		window = a.Window.Duration
+	}
+
+	r := promv1.Range{Start: completionTime.Add(-window), End: completionTime, Step: scrapeInterval}
+	if a.RelativeTo == "start" {
+		r.Start, r.End = startTime, startTime.Add(window)
+	}
+
+	// Prometheus limits the number of points per series
+	if step := window / 1000; step > r.Step {
+		r.Step = step
+	}
+	return r
+}
@@ -12,6 +12,8 @@ import (
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestPrometheusCheckReady(t *testing.T) {
@@ -72,6 +74,96 @@ func TestPrometheusCheckReady(t *testing.T) {
 	}
 }
 
+func TestPrometheusRoundTripper(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		data     map[string]string
+		headers  []redskyv1beta1.MetricHTTPHeader
+		expected http.Header
+	}{
+		{
+			desc:     "no credentials",
+			expected: http.Header{},
+		},
+		{
+			desc:     "token",
+			data:     map[string]string{"token": "abc"},
+			expected: http.Header{"Authorization": []string{"Bearer abc"}},
+		},
+		{
+			desc:     "basic",
+			data:     map[string]string{"username": "user", "password": "pass"},
+			expected: http.Header{"Authorization": []string{"Basic dXNlcjpwYXNz"}},
+		},
+		{
+			desc:     "headers",
+			headers:  []redskyv1beta1.MetricHTTPHeader{{Name: "X-Api-Key", Value: "key"}},
+			expected: http.Header{"X-Api-Key": []string{"key"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%q", tc.desc), func(t *testing.T) {
+			var actual http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actual = http.Header{}
+				for _, k := range []string{"Authorization", "X-Api-Key"} {
+					if v, ok := r.Header[k]; ok {
+						actual[k] = v
+					}
+				}
+			}))
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			resp, err := prometheusRoundTripper(http.DefaultTransport, tc.data, tc.headers).RoundTrip(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAggregationRange(t *testing.T) {
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	completionTime := startTime.Add(time.Hour)
+	window := &metav1.Duration{Duration: 10 * time.Minute}
+
+	testCases := []struct {
+		desc        string
+		aggregation *redskyv1beta1.MetricAggregation
+		expected    promv1.Range
+	}{
+		{
+			desc:        "entire trial",
+			aggregation: &redskyv1beta1.MetricAggregation{},
+			expected:    promv1.Range{Start: startTime, End: completionTime, Step: 5 * time.Second},
+		},
+		{
+			desc:        "relative to completion",
+			aggregation: &redskyv1beta1.MetricAggregation{Window: window},
+			expected:    promv1.Range{Start: completionTime.Add(-10 * time.Minute), End: completionTime, Step: 5 * time.Second},
+		},
+		{
+			desc:        "relative to start",
+			aggregation: &redskyv1beta1.MetricAggregation{Window: window, RelativeTo: "start"},
+			expected:    promv1.Range{Start: startTime, End: startTime.Add(10 * time.Minute), Step: 5 * time.Second},
+		},
+		{
+			desc:        "long window",
+			aggregation: &redskyv1beta1.MetricAggregation{Window: &metav1.Duration{Duration: 4 * time.Hour}},
+			expected:    promv1.Range{Start: completionTime.Add(-4 * time.Hour), End: completionTime, Step: 14400 * time.Millisecond},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, aggregationRange(tc.aggregation, startTime, completionTime))
+		})
+	}
+}
+
 func promTargetsHttpTestServer(scrapeTime time.Time) *httptest.Server {
 	respStr := `{"status":"success","data":{"activeTargets":[{"discoveredLabels":{"job":"kube-state-metrics"},"labels":{"instance":"localhost:8080","job":"kube-state-metrics"},"scrapePool":"kube-state-metrics","scrapeUrl":"http://localhost:8080/metrics","globalUrl":"http://redsky-default-prometheus-server-94df65748-bljzg:8080/metrics","lastError":"","lastScrape":%q,"lastScrapeDuration":0.0030478,"health":"up"},{"discoveredLabels":{"instance":"kind-control-plane","job":"kubernetes-cadvisor"},"labels":{"beta_kubernetes_io_arch":"amd64","beta_kubernetes_io_os":"linux","instance":"kind-control-plane","job":"kubernetes-cadvisor","kubernetes_io_arch":"amd64","kubernetes_io_hostname":"kind-control-plane","kubernetes_io_os":"linux"},"scrapePool":"kubernetes-cadvisor","scrapeUrl":"https://172.18.0.2:10250/metrics/cadvisor","globalUrl":"https://172.18.0.2:10250/metrics/cadvisor","lastError":"","lastScrape":%q,"lastScrapeDuration":0.0626849,"health":"up"},{"discoveredLabels":{"job":"prometheus-pushgateway"},"labels":{"instance":"localhost:9091","job":"prometheus-pushgateway"},"scrapePool":"prometheus-pushgateway","scrapeUrl":"http://localhost:9091/metrics","globalUrl":"http://redsky-default-prometheus-server-94df65748-bljzg:9091/metrics","lastError":"","lastScrape":%q,"lastScrapeDuration":0.0016028,"health":"up"}],"droppedTargets":[]}}`
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-logr/logr"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MaxClockSkew is the largest difference between the clocks of the controller and a metric source which is
+// tolerated before the difference is reported.
+const MaxClockSkew = 5 * time.Second
+
+// reportedWindow returns a copy of the trial whose start and completion times are replaced by the timestamps
+// reported by the application for the measurement window of the metric.
+func reportedWindow(ctx context.Context, log logr.Logger, trial *redskyv1beta1.Trial, metric *redskyv1beta1.Metric, target runtime.Object) (*redskyv1beta1.Trial, error) {
+	t := trial.DeepCopy()
+	if q := metric.Timestamps.StartQuery; q != "" {
+		startTime, err := captureTimestamp(ctx, log, trial, metric, target, q)
+		if err != nil {
+			return nil, err
+		}
+		t.Status.StartTime = &startTime
+	}
+	if q := metric.Timestamps.EndQuery; q != "" {
+		completionTime, err := captureTimestamp(ctx, log, trial, metric, target, q)
+		if err != nil {
+			return nil, err
+		}
+		t.Status.CompletionTime = &completionTime
+	}
+
+	if !t.Status.StartTime.Before(t.Status.CompletionTime) {
+		return nil, fmt.Errorf("invalid measurement window, start time %s is not before end time %s", t.Status.StartTime, t.Status.CompletionTime)
+	}
+
+	// The application cannot legitimately report activity outside of the trial run
+	if skew := ClockSkew(trial, t); skew != 0 {
+		log.Info("Possible clock skew detected, using the reported measurement window", "metric", metric.Name, "skew", skew.String(),
+			"startTime", t.Status.StartTime.Time, "completionTime", t.Status.CompletionTime.Time)
+	}
+
+	return t, nil
+}
+
+// captureTimestamp evaluates a timestamp query the same way as the metric query.
+func captureTimestamp(ctx context.Context, log logr.Logger, trial *redskyv1beta1.Trial, metric *redskyv1beta1.Metric, target runtime.Object, query string) (metav1.Time, error) {
+	m := metric.DeepCopy()
+	m.Query, m.ErrorQuery = query, ""
+	m.Timestamps, m.Aggregation = nil, nil
+
+	value, _, err := captureValue(ctx, log, trial, m, target)
+	if err != nil {
+		return metav1.Time{}, err
+	}
+	if math.IsNaN(value) || value <= 0 {
+		return metav1.Time{}, &CaptureError{Message: fmt.Sprintf("invalid timestamp: %v", value), Address: m.URL, Query: query}
+	}
+
+	sec, frac := math.Modf(value)
+	return metav1.NewTime(time.Unix(int64(sec), int64(frac*float64(time.Second)))), nil
+}
+
+// ClockSkew returns the amount by which the reported measurement window extends beyond the observed trial run,
+// the result is zero unless the difference exceeds the maximum tolerated clock skew. A positive value indicates
+// the reported clock is ahead of the observed clock.
+func ClockSkew(observed, reported *redskyv1beta1.Trial) time.Duration {
+	if observed.Status.StartTime != nil && reported.Status.StartTime != nil {
+		if d := reported.Status.StartTime.Sub(observed.Status.StartTime.Time); d < -MaxClockSkew {
+			return d
+		}
+	}
+	if observed.Status.CompletionTime != nil && reported.Status.CompletionTime != nil {
+		if d := reported.Status.CompletionTime.Sub(observed.Status.CompletionTime.Time); d > MaxClockSkew {
+			return d
+		}
+	}
+	return 0
+}
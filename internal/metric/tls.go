@@ -0,0 +1,92 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// metricHTTPClient returns the HTTP client used to fetch the supplied metric.
+func metricHTTPClient(m *redskyv1beta1.Metric) (*http.Client, error) {
+	if m.TLS == nil && m.Timeout == nil {
+		return httpClient, nil
+	}
+
+	rt, err := metricTransport(m, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &http.Client{Timeout: httpClient.Timeout, Transport: rt}
+	if m.Timeout != nil {
+		c.Timeout = m.Timeout.Duration
+	}
+	return c, nil
+}
+
+// metricTransport returns the round tripper used to fetch the supplied metric. If the metric does not
+// have a TLS configuration, the default round tripper is returned.
+func metricTransport(m *redskyv1beta1.Metric, defaultRoundTripper http.RoundTripper) (http.RoundTripper, error) {
+	if m.TLS == nil {
+		return defaultRoundTripper, nil
+	}
+
+	cfg, err := tlsConfig(m.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg
+	return t, nil
+}
+
+// tlsConfig returns the TLS configuration for a metric; references must already be resolved to values.
+func tlsConfig(mt *redskyv1beta1.MetricTLS) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: mt.InsecureSkipVerify,
+	}
+
+	if ca := tlsValue(mt.CA); ca != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(ca)) {
+			return nil, fmt.Errorf("invalid metric TLS certificate authority")
+		}
+	}
+
+	if cert, key := tlsValue(mt.Cert), tlsValue(mt.Key); cert != "" || key != "" {
+		c, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric TLS client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{c}
+	}
+
+	return cfg, nil
+}
+
+func tlsValue(d *redskyv1beta1.MetricTLSData) string {
+	if d == nil {
+		return ""
+	}
+	return d.Value
+}
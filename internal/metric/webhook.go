@@ -0,0 +1,129 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// DefaultWebhookTimeout is the amount of time to wait for a value webhook response when the webhook does not specify a value.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookRequest is the body of the request sent to a value webhook.
+type WebhookRequest struct {
+	// The namespace and name of the experiment.
+	Experiment string `json:"experiment"`
+	// The namespace and name of the trial.
+	Trial string `json:"trial"`
+	// The parameter assignments of the trial.
+	Assignments []redskyv1beta1.Assignment `json:"assignments,omitempty"`
+	// The collected metric values.
+	Values []redskyv1beta1.Value `json:"values"`
+}
+
+// WebhookResponse is the body of the response returned from a value webhook.
+type WebhookResponse struct {
+	// The adjusted metric values, values which are not included are left unchanged.
+	Values []redskyv1beta1.Value `json:"values"`
+}
+
+// InvokeWebhook sends the collected values of the trial to the value webhook of the experiment and applies the
+// adjusted values from the response to the trial.
+func InvokeWebhook(ctx context.Context, exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial) error {
+	wh := exp.Spec.ValueWebhook
+	if wh == nil {
+		return nil
+	}
+
+	timeout := DefaultWebhookTimeout
+	if wh.Timeout != nil {
+		timeout = wh.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(&WebhookRequest{
+		Experiment:  exp.Namespace + "/" + exp.Name,
+		Trial:       t.Namespace + "/" + t.Name,
+		Assignments: t.Spec.Assignments,
+		Values:      t.Spec.Values,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("value webhook returned unexpected status: %s", resp.Status)
+	}
+
+	data := &WebhookResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+		return fmt.Errorf("invalid value webhook response: %w", err)
+	}
+
+	return applyWebhookValues(t, data.Values)
+}
+
+// applyWebhookValues replaces the trial values with the adjusted values of the same name.
+func applyWebhookValues(t *redskyv1beta1.Trial, values []redskyv1beta1.Value) error {
+	index := make(map[string]*redskyv1beta1.Value, len(t.Spec.Values))
+	for i := range t.Spec.Values {
+		index[t.Spec.Values[i].Name] = &t.Spec.Values[i]
+	}
+
+	// Validate everything before changing anything
+	for _, v := range values {
+		if _, ok := index[v.Name]; !ok {
+			return fmt.Errorf("value webhook returned unknown metric %q", v.Name)
+		}
+		if _, err := strconv.ParseFloat(v.Value, 64); err != nil {
+			return fmt.Errorf("value webhook returned invalid value for metric %q: %w", v.Name, err)
+		}
+	}
+
+	for _, v := range values {
+		tv := index[v.Name]
+		tv.Value = v.Value
+		if v.Error != "" {
+			tv.Error = v.Error
+		}
+	}
+	return nil
+}
@@ -107,3 +107,25 @@ func CreatePatchOperation(t *redsky.Trial, p *redsky.PatchTemplate, ref *corev1.
 
 	return po, nil
 }
+
+// IsLivePatch checks to see if the supplied patch template can be applied without restarting the application, i.e.
+// every parameter referenced by the template has a restart policy of "Never". Patches that do not reference any
+// parameters are not considered live since they still require a restart when they are first applied.
+func IsLivePatch(te *template.Engine, params []redsky.Parameter, p *redsky.PatchTemplate) (bool, error) {
+	names, err := te.PatchParameters(p)
+	if err != nil || len(names) == 0 {
+		return false, err
+	}
+
+	restartPolicies := make(map[string]redsky.ParameterRestartPolicy, len(params))
+	for i := range params {
+		restartPolicies[params[i].Name] = params[i].RestartPolicy
+	}
+
+	for _, name := range names {
+		if restartPolicies[name] != redsky.RestartPolicyNever {
+			return false, nil
+		}
+	}
+	return true, nil
+}
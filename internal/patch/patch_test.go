@@ -228,3 +228,66 @@ metadata:
 		})
 	}
 }
+
+func TestIsLivePatch(t *testing.T) {
+	params := []redsky.Parameter{
+		{Name: "replicas", RestartPolicy: redsky.RestartPolicyAlways},
+		{Name: "min_replicas", RestartPolicy: redsky.RestartPolicyNever},
+		{Name: "max_replicas", RestartPolicy: redsky.RestartPolicyNever},
+		{Name: "cache_size"},
+	}
+	te := template.New().WithParameters(params).WithDerived([]redsky.DerivedValue{
+		{Name: "max_surge", Value: "{{ sub .Values.max_replicas .Values.min_replicas }}"},
+		{Name: "cache_mb", Value: "{{ .Values.cache_size }}Mi"},
+	})
+
+	testCases := []struct {
+		desc     string
+		patch    string
+		expected bool
+	}{
+		{
+			desc:     "no parameters",
+			patch:    `{"spec":{"replicas":1}}`,
+			expected: false,
+		},
+		{
+			desc:     "live",
+			patch:    `{"spec":{"minReplicas":{{ .Values.min_replicas }},"maxReplicas":{{ index .Values "max_replicas" }}}}`,
+			expected: true,
+		},
+		{
+			desc:     "restart",
+			patch:    `{"spec":{"minReplicas":{{ .Values.min_replicas }},"replicas":{{ .Values.replicas }}}}`,
+			expected: false,
+		},
+		{
+			desc:     "default restart policy",
+			patch:    `{"data":{"cacheSize":"{{ $.Values.cache_size }}"}}`,
+			expected: false,
+		},
+		{
+			desc:     "live derived",
+			patch:    `{"spec":{"maxSurge":{{ .Values.max_surge }}}}`,
+			expected: true,
+		},
+		{
+			desc:     "restart derived",
+			patch:    `{"data":{"cache":"{{ .Values.cache_mb }}"}}`,
+			expected: false,
+		},
+		{
+			desc:     "unnamed values",
+			patch:    `{"data":{ {{ range $k, $v := .Values }}"{{ $k }}":"{{ $v }}",{{ end }} }}`,
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			live, err := IsLivePatch(te, params, &redsky.PatchTemplate{Patch: tc.patch})
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, live)
+			}
+		})
+	}
+}
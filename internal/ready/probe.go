@@ -0,0 +1,229 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ready
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// probeTransport is shared by all HTTP probes; like the kubelet, certificates are not verified
+var probeTransport = &http.Transport{
+	TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+	DisableKeepAlives: true,
+}
+
+// CheckProbe performs the readiness probe against the specified (possibly nil) object. If the object is a pod or a
+// service, the probe is made directly against it, otherwise the probe is made against each of the pods associated
+// with the object. When the object is nil, the probe must explicitly specify a host.
+func (r *ReadinessChecker) CheckProbe(ctx context.Context, obj *unstructured.Unstructured, probe *redskyv1beta1.ReadinessProbe) (string, bool, error) {
+	if probe == nil {
+		return "", true, nil
+	}
+
+	timeout := time.Second
+	if probe.TimeoutSeconds > 0 {
+		timeout = time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+
+	var host string
+	var port intstr.IntOrString
+	var do func(string) (string, bool)
+	switch {
+	case probe.HTTPGet != nil:
+		host, port = probe.HTTPGet.Host, probe.HTTPGet.Port
+		do = func(hostPort string) (string, bool) { return httpProbe(ctx, probe.HTTPGet, hostPort, timeout) }
+	case probe.TCPSocket != nil:
+		host, port = probe.TCPSocket.Host, probe.TCPSocket.Port
+		do = func(hostPort string) (string, bool) { return tcpProbe(ctx, hostPort, timeout) }
+	default:
+		return "", false, &ReadinessError{Reason: "InvalidReadinessProbe", Message: "readiness probe must specify an HTTP or TCP action"}
+	}
+
+	endpoints, err := r.probeEndpoints(ctx, obj, host, port)
+	if err != nil {
+		return "", false, err
+	}
+	if len(endpoints) == 0 {
+		return "No readiness probe endpoints found", false, nil
+	}
+
+	// Stop probing as soon as an endpoint is not ready
+	for _, ep := range endpoints {
+		if msg, ok := do(ep); !ok {
+			return msg, false, nil
+		}
+	}
+	return "", true, nil
+}
+
+// probeEndpoints returns the "host:port" addresses that should be probed
+func (r *ReadinessChecker) probeEndpoints(ctx context.Context, obj *unstructured.Unstructured, host string, port intstr.IntOrString) ([]string, error) {
+	// An explicit host takes precedence over the object
+	if host != "" {
+		if port.Type != intstr.Int {
+			return nil, &ReadinessError{Reason: "InvalidReadinessProbe", Message: fmt.Sprintf("readiness probe for host %q requires a numeric port", host)}
+		}
+		return []string{net.JoinHostPort(host, strconv.Itoa(port.IntValue()))}, nil
+	}
+
+	if obj == nil {
+		return nil, &ReadinessError{Reason: "InvalidReadinessProbe", Message: "readiness probe without a target requires a host"}
+	}
+
+	switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+
+	case corev1.SchemeGroupVersion.WithKind("Pod").GroupKind():
+		pod := &corev1.Pod{}
+		if err := scheme.Scheme.Convert(obj, pod, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert %T to %T: %v", obj, pod, err)
+		}
+		return podEndpoints(pod, port)
+
+	case corev1.SchemeGroupVersion.WithKind("Service").GroupKind():
+		svc := &corev1.Service{}
+		if err := scheme.Scheme.Convert(obj, svc, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert %T to %T: %v", obj, svc, err)
+		}
+		return serviceEndpoints(svc, port)
+
+	}
+
+	list, err := r.listPods(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []string
+	for i := range list.Items {
+		eps, err := podEndpoints(&list.Items[i], port)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, eps...)
+	}
+	return endpoints, nil
+}
+
+// podEndpoints returns the pod address, named ports are resolved against the container ports
+func podEndpoints(pod *corev1.Pod, port intstr.IntOrString) ([]string, error) {
+	// The pod has not been assigned an address yet
+	if pod.Status.PodIP == "" {
+		return nil, nil
+	}
+
+	p := port.IntValue()
+	if port.Type == intstr.String {
+		for _, c := range pod.Spec.Containers {
+			for _, cp := range c.Ports {
+				if cp.Name == port.StrVal {
+					p = int(cp.ContainerPort)
+				}
+			}
+		}
+	}
+	if p <= 0 {
+		return nil, fmt.Errorf("unable to find port %q on pod %s", port.String(), pod.Name)
+	}
+
+	return []string{net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(p))}, nil
+}
+
+// serviceEndpoints returns the service DNS name, named ports are resolved against the service ports
+func serviceEndpoints(svc *corev1.Service, port intstr.IntOrString) ([]string, error) {
+	p := port.IntValue()
+	if port.Type == intstr.String {
+		for _, sp := range svc.Spec.Ports {
+			if sp.Name == port.StrVal {
+				p = int(sp.Port)
+			}
+		}
+	}
+	if p <= 0 {
+		return nil, fmt.Errorf("unable to find port %q on service %s", port.String(), svc.Name)
+	}
+
+	host := fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+	return []string{net.JoinHostPort(host, strconv.Itoa(p))}, nil
+}
+
+// httpProbe performs an HTTP GET, any status code in the 2xx or 3xx range is considered ready
+func httpProbe(ctx context.Context, action *corev1.HTTPGetAction, hostPort string, timeout time.Duration) (string, bool) {
+	u, err := url.Parse(action.Path)
+	if err != nil {
+		return err.Error(), false
+	}
+	u.Scheme = strings.ToLower(string(action.Scheme))
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	u.Host = hostPort
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err.Error(), false
+	}
+	for _, h := range action.HTTPHeaders {
+		if strings.EqualFold(h.Name, "Host") {
+			req.Host = h.Value
+			continue
+		}
+		req.Header.Add(h.Name, h.Value)
+	}
+
+	// Do not follow redirects, a 3xx response is considered ready
+	c := &http.Client{
+		Transport:     probeTransport,
+		Timeout:       timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Sprintf("HTTP readiness probe failed: %v", err), false
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Sprintf("HTTP readiness probe failed with status code %d: %s", resp.StatusCode, u.String()), false
+	}
+	return "", true
+}
+
+// tcpProbe opens (and immediately closes) a TCP connection
+func tcpProbe(ctx context.Context, hostPort string, timeout time.Duration) (string, bool) {
+	d := &net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		return fmt.Sprintf("TCP readiness probe failed: %v", err), false
+	}
+	_ = conn.Close()
+	return "", true
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ready
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestReadinessChecker_CheckProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	cases := []struct {
+		desc  string
+		probe *redskyv1beta1.ReadinessProbe
+		ready bool
+		err   bool
+	}{
+		{
+			desc:  "no probe",
+			ready: true,
+		},
+		{
+			desc: "http ready",
+			probe: &redskyv1beta1.ReadinessProbe{
+				HTTPGet: &corev1.HTTPGetAction{Host: host, Port: intstr.FromInt(port), Path: "/ready"},
+			},
+			ready: true,
+		},
+		{
+			desc: "http not ready",
+			probe: &redskyv1beta1.ReadinessProbe{
+				HTTPGet: &corev1.HTTPGetAction{Host: host, Port: intstr.FromInt(port), Path: "/other"},
+			},
+		},
+		{
+			desc: "tcp ready",
+			probe: &redskyv1beta1.ReadinessProbe{
+				TCPSocket: &corev1.TCPSocketAction{Host: host, Port: intstr.FromInt(port)},
+			},
+			ready: true,
+		},
+		{
+			desc: "named port with host",
+			probe: &redskyv1beta1.ReadinessProbe{
+				TCPSocket: &corev1.TCPSocketAction{Host: host, Port: intstr.FromString("http")},
+			},
+			err: true,
+		},
+		{
+			desc:  "missing action",
+			probe: &redskyv1beta1.ReadinessProbe{},
+			err:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rc := &ReadinessChecker{}
+			_, ok, err := rc.CheckProbe(context.TODO(), nil, c.probe)
+			if c.err {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, c.ready, ok)
+			}
+		})
+	}
+}
+
+func TestPodEndpoints(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+		},
+		Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+
+	eps, err := podEndpoints(pod, intstr.FromString("http"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"10.0.0.1:8080"}, eps)
+	}
+
+	eps, err = podEndpoints(pod, intstr.FromInt(9090))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"10.0.0.1:9090"}, eps)
+	}
+
+	_, err = podEndpoints(pod, intstr.FromString("metrics"))
+	assert.Error(t, err)
+}
@@ -56,6 +56,14 @@ func (o *FilterOptions) NewFilter(workingDirectory string) *konjure.Filter {
 	return f
 }
 
+// Kubectl executes the supplied kubectl command.
+func (o *FilterOptions) Kubectl(cmd *exec.Cmd) ([]byte, error) {
+	if o.KubectlExecutor != nil {
+		return o.KubectlExecutor(cmd)
+	}
+	return kubectl(cmd)
+}
+
 func kubectl(cmd *exec.Cmd) ([]byte, error) {
 	// If LookPath found the kubectl binary, it is safer to just use it. That
 	// way the cluster version doesn't need to be in the compatibility range of
@@ -106,7 +114,7 @@ func kustomize(cmd *exec.Cmd) ([]byte, error) {
 	}
 
 	// Run the Kustomization in process
-	rm, err := krusty.MakeKustomizer(opts).Run(fs, cmd.Args[1])
+	rm, err := krusty.MakeKustomizer(opts).Run(fs, cmd.Args[2])
 	if err != nil {
 		return nil, err
 	}
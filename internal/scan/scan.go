@@ -40,6 +40,39 @@ func (g *GenericSelector) Select(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	return (*filters.ResourceMetaFilter)(g).Filter(nodes)
 }
 
+// ExcludeFilter removes any resource nodes which match at least one of the
+// selectors.
+type ExcludeFilter []GenericSelector
+
+var _ kio.Filter = ExcludeFilter{}
+
+// Filter returns only the resource nodes that do not match an exclusion.
+func (f ExcludeFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	excluded := make(map[*yaml.RNode]bool)
+	for i := range f {
+		matched, err := f[i].Select(nodes)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, node := range matched {
+			excluded[node] = true
+		}
+	}
+
+	if len(excluded) == 0 {
+		return nodes, nil
+	}
+
+	result := make([]*yaml.RNode, 0, len(nodes)-len(excluded))
+	for _, node := range nodes {
+		if !excluded[node] {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
 // Transformer consumes the aggregated outputs from the selectors and
 // turns them back into resource nodes. The original resource nodes used
 // to generate the inputs are also made available.
@@ -142,3 +142,98 @@ kind: ConfigMap
 		})
 	}
 }
+
+func TestExcludeFilter_Filter(t *testing.T) {
+	cases := []struct {
+		desc     string
+		excludes ExcludeFilter
+		input    string
+		expected string
+	}{
+		{
+			desc: "no excludes",
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: foo
+`,
+			expected: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: foo
+`,
+		},
+
+		{
+			desc: "kind and name",
+			excludes: ExcludeFilter{
+				{Kind: "Job"},
+				{Kind: "Deployment", Name: "operator-.*"},
+			},
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: foo
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator-controller
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+		},
+
+		{
+			desc: "label selector",
+			excludes: ExcludeFilter{
+				{LabelSelector: "app.kubernetes.io/managed-by=operator"},
+			},
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  labels:
+    app.kubernetes.io/managed-by: operator
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bar
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bar
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			nodes, err := kio.FromBytes([]byte(c.input))
+			require.NoError(t, err)
+
+			nodes, err = c.excludes.Filter(nodes)
+			require.NoError(t, err)
+
+			actual, err := kio.StringAll(nodes)
+			if assert.NoError(t, err) {
+				assert.Equal(t, strings.TrimSpace(c.expected), strings.TrimSpace(actual))
+			}
+		})
+	}
+}
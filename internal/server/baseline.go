@@ -0,0 +1,43 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/meta"
+)
+
+// labelBaseline is the suggestion label (copied to the cluster trial) which identifies the baseline trial
+const labelBaseline = "baseline"
+
+// IsBaseline checks to see if the supplied trial was created from the baseline suggestion
+func IsBaseline(t *redskyv1beta1.Trial) bool {
+	return t.GetLabels()[labelBaseline] == "true"
+}
+
+// BaselinePending checks to see if the baseline trial has been created but has not been reported yet. Additional
+// suggestions should not be requested while the baseline is pending so every experiment has a measured reference point
+// before the optimizer suggestions are evaluated.
+func BaselinePending(trialList *redskyv1beta1.TrialList) bool {
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if IsBaseline(t) && t.DeletionTimestamp.IsZero() && meta.HasFinalizer(t, Finalizer) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBaselinePending(t *testing.T) {
+	now := metav1.Now()
+	baseline := func(finalizers []string, deleted *metav1.Time) redskyv1beta1.Trial {
+		return redskyv1beta1.Trial{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:            map[string]string{"baseline": "true"},
+				Finalizers:        finalizers,
+				DeletionTimestamp: deleted,
+			},
+		}
+	}
+
+	cases := []struct {
+		desc     string
+		trials   []redskyv1beta1.Trial
+		expected bool
+	}{
+		{
+			desc: "no trials",
+		},
+		{
+			desc:   "suggested trial",
+			trials: []redskyv1beta1.Trial{{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{Finalizer}}}},
+		},
+		{
+			desc:     "baseline not reported",
+			trials:   []redskyv1beta1.Trial{baseline([]string{Finalizer}, nil)},
+			expected: true,
+		},
+		{
+			desc:   "baseline reported",
+			trials: []redskyv1beta1.Trial{baseline(nil, nil)},
+		},
+		{
+			desc:   "baseline abandoned",
+			trials: []redskyv1beta1.Trial{baseline([]string{Finalizer}, &now)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, BaselinePending(&redskyv1beta1.TrialList{Items: c.trials}))
+		})
+	}
+}
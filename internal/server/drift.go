@@ -0,0 +1,165 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	redskyapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefinitionDrift returns a description of each difference between the parameters and metrics of the cluster
+// experiment and the server definition of the same experiment.
+func DefinitionDrift(exp *redskyv1beta1.Experiment, ee *redskyapi.Experiment) ([]string, error) {
+	_, local, _, err := FromCluster(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []string
+
+	params := make(map[string]*redskyapi.Parameter, len(ee.Parameters))
+	for i := range ee.Parameters {
+		params[ee.Parameters[i].Name] = &ee.Parameters[i]
+	}
+	for i := range local.Parameters {
+		lp := &local.Parameters[i]
+		rp, ok := params[lp.Name]
+		delete(params, lp.Name)
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("parameter '%s' is missing from the server", lp.Name))
+		case lp.Type != rp.Type:
+			drift = append(drift, fmt.Sprintf("parameter '%s' has type '%s' on the server", lp.Name, rp.Type))
+		case !sameBounds(lp.Bounds, rp.Bounds):
+			drift = append(drift, fmt.Sprintf("parameter '%s' has bounds [%s, %s] on the server", lp.Name, rp.Bounds.Min, rp.Bounds.Max))
+		case !sameValues(lp.Values, rp.Values):
+			drift = append(drift, fmt.Sprintf("parameter '%s' has values [%s] on the server", lp.Name, strings.Join(rp.Values, ", ")))
+		}
+	}
+	for i := range ee.Parameters {
+		if _, ok := params[ee.Parameters[i].Name]; ok {
+			drift = append(drift, fmt.Sprintf("parameter '%s' is missing from the cluster", ee.Parameters[i].Name))
+		}
+	}
+
+	metrics := make(map[string]*redskyapi.Metric, len(ee.Metrics))
+	for i := range ee.Metrics {
+		metrics[ee.Metrics[i].Name] = &ee.Metrics[i]
+	}
+	for i := range local.Metrics {
+		lm := &local.Metrics[i]
+		rm, ok := metrics[lm.Name]
+		delete(metrics, lm.Name)
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("metric '%s' is missing from the server", lm.Name))
+		case lm.Minimize != rm.Minimize:
+			drift = append(drift, fmt.Sprintf("metric '%s' has minimize=%t on the server", lm.Name, rm.Minimize))
+		case isOptimized(lm) != isOptimized(rm):
+			drift = append(drift, fmt.Sprintf("metric '%s' has optimize=%t on the server", lm.Name, isOptimized(rm)))
+		}
+	}
+	for i := range ee.Metrics {
+		if _, ok := metrics[ee.Metrics[i].Name]; ok {
+			drift = append(drift, fmt.Sprintf("metric '%s' is missing from the cluster", ee.Metrics[i].Name))
+		}
+	}
+
+	return drift, nil
+}
+
+// CheckDrift compares the cluster experiment to the server definition and records the result using the drifted
+// condition. Returns true only if the status needed to be changed.
+func CheckDrift(exp *redskyv1beta1.Experiment, ee *redskyapi.Experiment) bool {
+	drift, err := DefinitionDrift(exp, ee)
+	if err != nil {
+		drift = append(drift, err.Error())
+	}
+
+	if len(drift) == 0 {
+		if !experiment.IsDrifted(exp) {
+			return false
+		}
+		experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentDrifted, corev1.ConditionFalse, "DefinitionMatched", "", nil)
+		return true
+	}
+
+	msg := strings.Join(drift, "; ")
+	for _, c := range exp.Status.Conditions {
+		if c.Type == redskyv1beta1.ExperimentDrifted && c.Status == corev1.ConditionTrue && c.Message == msg {
+			return false
+		}
+	}
+	experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentDrifted, corev1.ConditionTrue, "DefinitionDrifted", msg, nil)
+	return true
+}
+
+// sameBounds checks to see if two sets of numeric bounds are equivalent.
+func sameBounds(a, b *redskyapi.Bounds) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return sameNumber(a.Min, b.Min) && sameNumber(a.Max, b.Max)
+}
+
+// sameNumber checks to see if two numbers are equal, regardless of how they are formatted.
+func sameNumber(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+
+	x, err := a.Float64()
+	if err != nil {
+		return false
+	}
+	y, err := b.Float64()
+	if err != nil {
+		return false
+	}
+	return x == y
+}
+
+// sameValues checks to see if two lists of categorical values contain the same values, ignoring order.
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	as := append([]string(nil), a...)
+	bs := append([]string(nil), b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isOptimized checks to see if an API metric is optimized, metrics are optimized unless explicitly disabled.
+func isOptimized(m *redskyapi.Metric) bool {
+	return m.Optimize == nil || *m.Optimize
+}
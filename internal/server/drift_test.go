@@ -0,0 +1,100 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	redskyapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestDefinitionDrift(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "cpu", Min: 100, Max: 4000},
+				{Name: "gc", Values: []string{"serial", "parallel"}},
+			},
+			Metrics: []redskyv1beta1.Metric{
+				{Name: "cost", Minimize: true},
+			},
+		},
+	}
+
+	cases := []struct {
+		desc     string
+		ee       *redskyapi.Experiment
+		expected []string
+	}{
+		{
+			desc: "matched",
+			ee: &redskyapi.Experiment{
+				Parameters: []redskyapi.Parameter{
+					{Type: redskyapi.ParameterTypeInteger, Name: "cpu", Bounds: &redskyapi.Bounds{Min: "100", Max: "4000.0"}},
+					{Type: redskyapi.ParameterTypeCategorical, Name: "gc", Values: []string{"parallel", "serial"}},
+				},
+				Metrics: []redskyapi.Metric{
+					{Name: "cost", Minimize: true},
+				},
+			},
+		},
+		{
+			desc: "drifted",
+			ee: &redskyapi.Experiment{
+				Parameters: []redskyapi.Parameter{
+					{Type: redskyapi.ParameterTypeInteger, Name: "cpu", Bounds: &redskyapi.Bounds{Min: "100", Max: "2000"}},
+					{Type: redskyapi.ParameterTypeInteger, Name: "memory", Bounds: &redskyapi.Bounds{Min: "128", Max: "4096"}},
+				},
+				Metrics: []redskyapi.Metric{
+					{Name: "cost", Minimize: false},
+				},
+			},
+			expected: []string{
+				"parameter 'cpu' has bounds [100, 2000] on the server",
+				"parameter 'gc' is missing from the server",
+				"parameter 'memory' is missing from the cluster",
+				"metric 'cost' has minimize=false on the server",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			drift, err := DefinitionDrift(exp, c.ee)
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, drift)
+			}
+		})
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			Metrics: []redskyv1beta1.Metric{{Name: "cost"}},
+		},
+	}
+
+	assert.True(t, CheckDrift(exp, &redskyapi.Experiment{}))
+	assert.True(t, experiment.IsDrifted(exp))
+	assert.False(t, CheckDrift(exp, &redskyapi.Experiment{}))
+
+	assert.True(t, CheckDrift(exp, &redskyapi.Experiment{Metrics: []redskyapi.Metric{{Name: "cost"}}}))
+	assert.False(t, experiment.IsDrifted(exp))
+}
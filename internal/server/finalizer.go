@@ -0,0 +1,33 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"time"
+
+	"github.com/thestormforge/optimize-controller/internal/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FinalizerExpired checks to see if the server finalizer has been blocking the deletion of the supplied object for
+// longer than the timeout, e.g. because the server is unreachable. A timeout of zero never expires.
+func FinalizerExpired(obj metav1.Object, timeout time.Duration, now time.Time) bool {
+	if timeout <= 0 || obj.GetDeletionTimestamp() == nil || !meta.HasFinalizer(obj, Finalizer) {
+		return false
+	}
+	return obj.GetDeletionTimestamp().Add(timeout).Before(now)
+}
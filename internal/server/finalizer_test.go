@@ -0,0 +1,75 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFinalizerExpired(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	deleted := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	cases := []struct {
+		desc       string
+		deleted    *metav1.Time
+		finalizers []string
+		timeout    time.Duration
+		expected   bool
+	}{
+		{
+			desc:       "expired",
+			deleted:    &deleted,
+			finalizers: []string{Finalizer},
+			timeout:    5 * time.Minute,
+			expected:   true,
+		},
+		{
+			desc:       "waiting",
+			deleted:    &deleted,
+			finalizers: []string{Finalizer},
+			timeout:    15 * time.Minute,
+		},
+		{
+			desc:       "disabled",
+			deleted:    &deleted,
+			finalizers: []string{Finalizer},
+		},
+		{
+			desc:       "not deleted",
+			finalizers: []string{Finalizer},
+			timeout:    5 * time.Minute,
+		},
+		{
+			desc:       "other finalizer",
+			deleted:    &deleted,
+			finalizers: []string{"example.com/finalizer"},
+			timeout:    5 * time.Minute,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tt := &redskyv1beta1.Trial{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: c.deleted, Finalizers: c.finalizers}}
+			assert.Equal(t, c.expected, FinalizerExpired(tt, c.timeout, now))
+		})
+	}
+}
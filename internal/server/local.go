@@ -0,0 +1,335 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	"github.com/thestormforge/optimize-controller/internal/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The local optimizer produces suggestions as a pure function of the experiment definition and the suggestion index,
+// no state other than the index (recorded on the experiment and each trial) is needed to resume an experiment. Every parameter is
+// treated as a discrete domain of `n` values: categorical values, each integer of a numeric range, or each step index
+// of a scaled range.
+
+// defaultLocalBudget is the number of trials created by the local optimizer when no budget is configured.
+const defaultLocalBudget = 20
+
+// defaultGridPoints is the number of values of each numeric parameter used for a grid search.
+const defaultGridPoints = 5
+
+// localDomain is the discrete domain of a single parameter.
+type localDomain struct {
+	param *redskyv1beta1.Parameter
+	size  int64
+}
+
+// value returns the assignment for the k-th value of the domain.
+func (d *localDomain) value(k int64) intstr.IntOrString {
+	if values := d.param.GetValues(); len(values) > 0 {
+		return intstr.FromString(values[k])
+	}
+	if isScaled(d.param) {
+		return intstr.FromInt(int(scaledValue(d.param, k)))
+	}
+	return intstr.FromInt(int(int64(d.param.Min) + k))
+}
+
+// localDomains returns the domains of all the experiment parameters.
+func localDomains(exp *redskyv1beta1.Experiment) ([]localDomain, error) {
+	domains := make([]localDomain, 0, len(exp.Spec.Parameters))
+	for i := range exp.Spec.Parameters {
+		p := &exp.Spec.Parameters[i]
+		d := localDomain{param: p}
+		switch {
+		case len(p.GetValues()) > 0:
+			d.size = int64(len(p.GetValues()))
+		case isScaled(p):
+			n, err := scaledMax(p)
+			if err != nil {
+				return nil, err
+			}
+			d.size = n + 1
+		case p.Max < p.Min:
+			return nil, fmt.Errorf("invalid range for parameter '%s': [%d, %d]", p.Name, p.Min, p.Max)
+		default:
+			d.size = int64(p.Max) - int64(p.Min) + 1
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+// hasLocalBaseline checks to see if every parameter has a baseline, in which case the baseline is the first suggestion.
+func hasLocalBaseline(exp *redskyv1beta1.Experiment) bool {
+	for i := range exp.Spec.Parameters {
+		if exp.Spec.Parameters[i].GetBaseline() == nil {
+			return false
+		}
+	}
+	return len(exp.Spec.Parameters) > 0
+}
+
+// gridPoints returns the step indices of the domain that are evaluated by a grid search.
+func gridPoints(lo *redskyv1beta1.LocalOptimizer, d *localDomain) []int64 {
+	if len(d.param.GetValues()) > 0 {
+		points := make([]int64, d.size)
+		for k := range points {
+			points[k] = int64(k)
+		}
+		return points
+	}
+
+	g := int64(lo.GridPoints)
+	if g <= 0 {
+		g = defaultGridPoints
+	}
+	if g > d.size {
+		g = d.size
+	}
+	if g == 1 {
+		return []int64{0}
+	}
+
+	points := make([]int64, g)
+	for k := range points {
+		points[k] = int64(k) * (d.size - 1) / (g - 1)
+	}
+	return points
+}
+
+// gridSize returns the total number of grid points, saturating at the largest positive int.
+func gridSize(lo *redskyv1beta1.LocalOptimizer, domains []localDomain) int {
+	size := 1
+	for i := range domains {
+		n := len(gridPoints(lo, &domains[i]))
+		if n > 0 && size > math.MaxInt32/n {
+			return math.MaxInt32
+		}
+		size *= n
+	}
+	return size
+}
+
+// LocalBudget returns the total number of suggestions the local optimizer will produce for the experiment.
+func LocalBudget(exp *redskyv1beta1.Experiment) int {
+	lo := exp.Spec.LocalOptimizer
+	if lo == nil {
+		return 0
+	}
+
+	budget := -1
+	if lo.Budget != nil {
+		budget = int(*lo.Budget)
+	} else {
+		for _, o := range exp.Spec.Optimization {
+			if o.Name == "experimentBudget" {
+				if b, err := strconv.Atoi(o.Value); err == nil {
+					budget = b
+				}
+			}
+		}
+	}
+
+	if lo.Strategy == redskyv1beta1.LocalOptimizerGrid {
+		domains, err := localDomains(exp)
+		if err != nil {
+			return 0
+		}
+		size := gridSize(lo, domains)
+		if hasLocalBaseline(exp) {
+			size++
+		}
+		if budget < 0 || budget > size {
+			budget = size
+		}
+	}
+
+	if budget < 0 {
+		budget = defaultLocalBudget
+	}
+	return budget
+}
+
+// NextLocalSuggestion returns the index of the next suggestion of the local optimizer given the existing trials. The
+// index recorded on the experiment ensures the suggestions do not rewind once finished trials are deleted; suggestions
+// which do not satisfy the experiment constraints are skipped.
+func NextLocalSuggestion(exp *redskyv1beta1.Experiment, trialList *redskyv1beta1.TrialList) int {
+	next, _ := strconv.Atoi(exp.GetAnnotations()[redskyv1beta1.AnnotationNextLocalSuggestion])
+	for i := range trialList.Items {
+		if idx, err := strconv.Atoi(trialList.Items[i].GetAnnotations()[redskyv1beta1.AnnotationLocalSuggestion]); err == nil && idx >= next {
+			next = idx + 1
+		}
+	}
+
+	for budget := LocalBudget(exp); next < budget; next++ {
+		assignments, _, err := LocalAssignments(exp, next)
+		if err != nil {
+			break // Let the error surface when the trial is created
+		}
+		t := &redskyv1beta1.Trial{Spec: redskyv1beta1.TrialSpec{Assignments: assignments}}
+		if validation.CheckConstraints(t, exp) == nil {
+			break
+		}
+	}
+	return next
+}
+
+// RecordLocalSuggestion records the index of the next suggestion of the local optimizer on the experiment, returning
+// true if the experiment was changed.
+func RecordLocalSuggestion(exp *redskyv1beta1.Experiment, next int) bool {
+	value := strconv.Itoa(next)
+	if exp.GetAnnotations()[redskyv1beta1.AnnotationNextLocalSuggestion] == value {
+		return false
+	}
+	if exp.Annotations == nil {
+		exp.Annotations = make(map[string]string, 1)
+	}
+	exp.Annotations[redskyv1beta1.AnnotationNextLocalSuggestion] = value
+	return true
+}
+
+// LocalAssignments returns the parameter assignments of the local optimizer suggestion with the specified index. The
+// returned boolean indicates the suggestion is the experiment baseline.
+func LocalAssignments(exp *redskyv1beta1.Experiment, index int) ([]redskyv1beta1.Assignment, bool, error) {
+	lo := exp.Spec.LocalOptimizer
+	if lo == nil {
+		return nil, false, fmt.Errorf("experiment does not use a local optimizer")
+	}
+
+	budget := LocalBudget(exp)
+	if hasLocalBaseline(exp) {
+		if index == 0 {
+			assignments := make([]redskyv1beta1.Assignment, 0, len(exp.Spec.Parameters))
+			for i := range exp.Spec.Parameters {
+				p := &exp.Spec.Parameters[i]
+				assignments = append(assignments, redskyv1beta1.Assignment{Name: p.Name, Value: *p.GetBaseline()})
+			}
+			return assignments, true, nil
+		}
+		index--
+		budget--
+	}
+
+	domains, err := localDomains(exp)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ks []int64
+	switch lo.Strategy {
+	case "", redskyv1beta1.LocalOptimizerRandom:
+		ks = randomIndices(lo, domains, index)
+	case redskyv1beta1.LocalOptimizerLatinHypercube:
+		ks = latinHypercubeIndices(lo, domains, index, budget)
+	case redskyv1beta1.LocalOptimizerGrid:
+		if ks, err = gridIndices(lo, domains, index); err != nil {
+			return nil, false, err
+		}
+	default:
+		return nil, false, fmt.Errorf("unknown local optimizer strategy: %s", lo.Strategy)
+	}
+
+	assignments := make([]redskyv1beta1.Assignment, 0, len(domains))
+	for i := range domains {
+		assignments = append(assignments, redskyv1beta1.Assignment{Name: domains[i].param.Name, Value: domains[i].value(ks[i])})
+	}
+	return assignments, false, nil
+}
+
+// randomIndices chooses each value independently and uniformly.
+func randomIndices(lo *redskyv1beta1.LocalOptimizer, domains []localDomain, index int) []int64 {
+	rng := rand.New(rand.NewSource(lo.Seed + int64(index)))
+	ks := make([]int64, len(domains))
+	for i := range domains {
+		ks[i] = rng.Int63n(domains[i].size)
+	}
+	return ks
+}
+
+// latinHypercubeIndices divides each domain into `budget` equally sized strata and uses a (per-parameter) random
+// permutation of the strata so each stratum of each parameter is sampled exactly once over the course of the budget.
+func latinHypercubeIndices(lo *redskyv1beta1.LocalOptimizer, domains []localDomain, index, budget int) []int64 {
+	if budget < 1 {
+		budget = 1
+	}
+
+	rng := rand.New(rand.NewSource(lo.Seed + int64(index)))
+	ks := make([]int64, len(domains))
+	for i := range domains {
+		strata := rand.New(rand.NewSource(lo.Seed ^ int64(i+1)<<32)).Perm(budget)
+		s := float64(strata[index%budget])
+		k := int64((s + rng.Float64()) / float64(budget) * float64(domains[i].size))
+		if k >= domains[i].size {
+			k = domains[i].size - 1
+		}
+		ks[i] = k
+	}
+	return ks
+}
+
+// gridIndices decodes the index into a point on the grid, the first parameter varies the fastest.
+func gridIndices(lo *redskyv1beta1.LocalOptimizer, domains []localDomain, index int) ([]int64, error) {
+	ks := make([]int64, len(domains))
+	for i := range domains {
+		points := gridPoints(lo, &domains[i])
+		ks[i] = points[index%len(points)]
+		index /= len(points)
+	}
+	if index > 0 {
+		return nil, fmt.Errorf("local optimizer grid is exhausted")
+	}
+	return ks, nil
+}
+
+// ToClusterLocalTrial applies the local optimizer suggestion with the specified index to a new trial.
+func ToClusterLocalTrial(t *redskyv1beta1.Trial, exp *redskyv1beta1.Experiment, index int) error {
+	assignments, baseline, err := LocalAssignments(exp, index)
+	if err != nil {
+		return err
+	}
+
+	if t.Annotations == nil {
+		t.Annotations = make(map[string]string, 1)
+	}
+	t.Annotations[redskyv1beta1.AnnotationLocalSuggestion] = strconv.Itoa(index)
+
+	// Use the same names the server would have used, suggestion numbers start at one
+	if t.Name == "" && t.GenerateName != "" {
+		t.Name = trialName(t.GenerateName, strconv.Itoa(index+1), exp.Spec.TrialNaming)
+	}
+
+	if baseline {
+		if t.Labels == nil {
+			t.Labels = make(map[string]string, 1)
+		}
+		t.Labels[labelBaseline] = "true"
+	}
+
+	t.Spec.Assignments = append(t.Spec.Assignments, assignments...)
+
+	trial.UpdateStatus(t)
+
+	return nil
+}
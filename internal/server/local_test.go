@@ -0,0 +1,218 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestLocalBudget(t *testing.T) {
+	budget := int32(50)
+	params := []redskyv1beta1.Parameter{
+		{Name: "cpu", Min: 100, Max: 4000},
+		{Name: "gc", Values: []string{"serial", "parallel"}},
+	}
+
+	cases := []struct {
+		desc     string
+		spec     redskyv1beta1.ExperimentSpec
+		expected int
+	}{
+		{
+			desc: "none",
+		},
+		{
+			desc:     "default",
+			spec:     redskyv1beta1.ExperimentSpec{LocalOptimizer: &redskyv1beta1.LocalOptimizer{}},
+			expected: 20,
+		},
+		{
+			desc: "experiment budget",
+			spec: redskyv1beta1.ExperimentSpec{
+				LocalOptimizer: &redskyv1beta1.LocalOptimizer{},
+				Optimization:   []redskyv1beta1.Optimization{{Name: "experimentBudget", Value: "30"}},
+			},
+			expected: 30,
+		},
+		{
+			desc: "explicit budget",
+			spec: redskyv1beta1.ExperimentSpec{
+				LocalOptimizer: &redskyv1beta1.LocalOptimizer{Budget: &budget},
+				Optimization:   []redskyv1beta1.Optimization{{Name: "experimentBudget", Value: "30"}},
+			},
+			expected: 50,
+		},
+		{
+			desc: "grid",
+			spec: redskyv1beta1.ExperimentSpec{
+				LocalOptimizer: &redskyv1beta1.LocalOptimizer{Strategy: redskyv1beta1.LocalOptimizerGrid, GridPoints: 3},
+				Parameters:     params,
+			},
+			expected: 6,
+		},
+		{
+			desc: "grid budget",
+			spec: redskyv1beta1.ExperimentSpec{
+				LocalOptimizer: &redskyv1beta1.LocalOptimizer{Strategy: redskyv1beta1.LocalOptimizerGrid, Budget: &budget},
+				Parameters:     params,
+			},
+			expected: 10,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, LocalBudget(&redskyv1beta1.Experiment{Spec: c.spec}))
+		})
+	}
+}
+
+func TestLocalAssignments(t *testing.T) {
+	params := []redskyv1beta1.Parameter{
+		{Name: "replicas", Min: 1, Max: 10},
+		{Name: "memory", Min: 128, Max: 4096, Scale: redskyv1beta1.ScaleLog},
+		{Name: "gc", Values: []string{"serial", "parallel", "g1"}},
+	}
+
+	strategies := []redskyv1beta1.LocalOptimizerStrategy{
+		redskyv1beta1.LocalOptimizerRandom,
+		redskyv1beta1.LocalOptimizerLatinHypercube,
+		redskyv1beta1.LocalOptimizerGrid,
+	}
+	for _, s := range strategies {
+		t.Run(string(s), func(t *testing.T) {
+			exp := &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					LocalOptimizer: &redskyv1beta1.LocalOptimizer{Strategy: s, Seed: 42},
+					Parameters:     params,
+				},
+			}
+
+			seen := make(map[string]bool)
+			for i := 0; i < LocalBudget(exp); i++ {
+				assignments, baseline, err := LocalAssignments(exp, i)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.False(t, baseline)
+				if assert.Len(t, assignments, len(params)) {
+					assert.GreaterOrEqual(t, assignments[0].Value.IntVal, int32(1))
+					assert.LessOrEqual(t, assignments[0].Value.IntVal, int32(10))
+					assert.Contains(t, []int32{128, 256, 512, 1024, 2048, 4096}, assignments[1].Value.IntVal)
+					assert.Contains(t, []string{"serial", "parallel", "g1"}, assignments[2].Value.StrVal)
+				}
+
+				// The same index must always produce the same suggestion
+				again, _, _ := LocalAssignments(exp, i)
+				assert.Equal(t, assignments, again)
+
+				if s == redskyv1beta1.LocalOptimizerGrid {
+					key := assignments[0].Value.String() + "/" + assignments[1].Value.String() + "/" + assignments[2].Value.String()
+					assert.False(t, seen[key], "duplicate grid point %s", key)
+					seen[key] = true
+				}
+			}
+		})
+	}
+}
+
+func TestLocalAssignmentsBaseline(t *testing.T) {
+	cpu, gc := intstr.FromInt(500), intstr.FromString("g1")
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			LocalOptimizer: &redskyv1beta1.LocalOptimizer{Strategy: redskyv1beta1.LocalOptimizerGrid, GridPoints: 2},
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "cpu", Min: 100, Max: 4000, Baseline: &cpu},
+				{Name: "gc", Values: []string{"serial", "g1"}, Baseline: &gc},
+			},
+		},
+	}
+
+	assert.Equal(t, 5, LocalBudget(exp))
+
+	assignments, baseline, err := LocalAssignments(exp, 0)
+	if assert.NoError(t, err) {
+		assert.True(t, baseline)
+		assert.Equal(t, []redskyv1beta1.Assignment{{Name: "cpu", Value: cpu}, {Name: "gc", Value: gc}}, assignments)
+	}
+
+	assignments, baseline, err = LocalAssignments(exp, 1)
+	if assert.NoError(t, err) {
+		assert.False(t, baseline)
+		assert.Equal(t, []redskyv1beta1.Assignment{{Name: "cpu", Value: intstr.FromInt(100)}, {Name: "gc", Value: intstr.FromString("serial")}}, assignments)
+	}
+
+	_, _, err = LocalAssignments(exp, 5)
+	assert.EqualError(t, err, "local optimizer grid is exhausted")
+}
+
+func TestNextLocalSuggestion(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			LocalOptimizer: &redskyv1beta1.LocalOptimizer{Strategy: redskyv1beta1.LocalOptimizerGrid, GridPoints: 2},
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "requests", Min: 1, Max: 4},
+				{Name: "limits", Min: 1, Max: 4},
+			},
+			Constraints: []redskyv1beta1.Constraint{
+				{Name: "requests-limits", Order: &redskyv1beta1.OrderConstraint{LowerParameter: "requests", UpperParameter: "limits"}},
+			},
+		},
+	}
+	trialList := &redskyv1beta1.TrialList{}
+
+	// The grid is (1, 1), (4, 1), (1, 4), (4, 4) and the second point violates the order constraint
+	assert.Equal(t, 0, NextLocalSuggestion(exp, trialList))
+	assert.True(t, RecordLocalSuggestion(exp, 1))
+	assert.False(t, RecordLocalSuggestion(exp, 1))
+	assert.Equal(t, 2, NextLocalSuggestion(exp, trialList))
+
+	// The recorded index is used even if the trials have been deleted
+	assert.True(t, RecordLocalSuggestion(exp, 4))
+	assert.Equal(t, 4, NextLocalSuggestion(exp, trialList))
+}
+
+func TestToClusterLocalTrial(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			LocalOptimizer: &redskyv1beta1.LocalOptimizer{},
+			Parameters:     []redskyv1beta1.Parameter{{Name: "cpu", Min: 100, Max: 4000}},
+		},
+	}
+
+	trialList := &redskyv1beta1.TrialList{
+		Items: []redskyv1beta1.Trial{
+			{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{redskyv1beta1.AnnotationLocalSuggestion: "0"}}},
+			{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{redskyv1beta1.AnnotationLocalSuggestion: "2"}}},
+			{},
+		},
+	}
+	index := NextLocalSuggestion(exp, trialList)
+	assert.Equal(t, 3, index)
+
+	tr := &redskyv1beta1.Trial{ObjectMeta: metav1.ObjectMeta{GenerateName: "my-exp-"}}
+	if assert.NoError(t, ToClusterLocalTrial(tr, exp, index)) {
+		assert.Equal(t, "my-exp-004", tr.Name)
+		assert.Equal(t, "3", tr.Annotations[redskyv1beta1.AnnotationLocalSuggestion])
+		assert.Len(t, tr.Spec.Assignments, 1)
+		assert.Empty(t, tr.Finalizers)
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// LastModified returns the most recent modification time of the experiment. In addition to the creation timestamp,
+// the update times of the managed fields (which include status updates) and the condition transition times are
+// considered. The result is truncated to whole seconds to match the precision of HTTP dates.
+func LastModified(exp *redskyv1beta1.Experiment) time.Time {
+	lastModified := exp.CreationTimestamp.Time
+
+	for i := range exp.ManagedFields {
+		if t := exp.ManagedFields[i].Time; t != nil && t.After(lastModified) {
+			lastModified = t.Time
+		}
+	}
+
+	for i := range exp.Status.Conditions {
+		if t := exp.Status.Conditions[i].LastTransitionTime; t.After(lastModified) {
+			lastModified = t.Time
+		}
+	}
+
+	return lastModified.Truncate(time.Second)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLastModified(t *testing.T) {
+	created := time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC)
+	updated := metav1.NewTime(created.Add(time.Hour))
+	transitioned := metav1.NewTime(created.Add(2*time.Hour + 500*time.Millisecond))
+
+	exp := &redskyv1beta1.Experiment{}
+	exp.CreationTimestamp = metav1.NewTime(created)
+	assert.Equal(t, created, LastModified(exp))
+
+	exp.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl", Time: &updated}, {Manager: "other"}}
+	assert.Equal(t, updated.Time, LastModified(exp))
+
+	exp.Status.Conditions = []redskyv1beta1.ExperimentCondition{{LastTransitionTime: transitioned}}
+	assert.Equal(t, created.Add(2*time.Hour), LastModified(exp))
+}
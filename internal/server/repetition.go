@@ -0,0 +1,168 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/internal/meta"
+	"github.com/thestormforge/optimize-controller/internal/metric"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// defaultRepetitionAggregation is used to combine the values of repeated trials when the metric does not specify a function
+const defaultRepetitionAggregation redskyv1beta1.MetricAggregationFunction = "p50"
+
+// Repetitions returns the trials repeating the assignments of the supplied trial.
+func Repetitions(t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) []*redskyv1beta1.Trial {
+	var result []*redskyv1beta1.Trial
+	for i := range trialList.Items {
+		if trialList.Items[i].Labels[redskyv1beta1.LabelRepetitionOf] == t.Name {
+			result = append(result, &trialList.Items[i])
+		}
+	}
+	return result
+}
+
+// IsRepetition checks to see if the supplied trial is a repetition of another trial.
+func IsRepetition(t *redskyv1beta1.Trial) bool {
+	return t.Labels[redskyv1beta1.LabelRepetitionOf] != ""
+}
+
+// RepetitionsFinished checks to see if the supplied finished trial can be reported, i.e. all of the repetitions of
+// the trial have finished. Failed trials and repetitions are never repeated.
+func RepetitionsFinished(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) bool {
+	if exp.Repetitions() <= 1 || IsRepetition(t) || !exp.DeletionTimestamp.IsZero() || !t.DeletionTimestamp.IsZero() ||
+		!trial.CheckCondition(&t.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue) {
+		return true
+	}
+
+	repetitions := Repetitions(t, trialList)
+	for _, r := range repetitions {
+		if !trial.IsFinished(r) && !trial.IsAbandoned(r) {
+			return false
+		}
+	}
+	return int32(len(repetitions))+1 >= exp.Repetitions()
+}
+
+// IsHeldRepetition checks to see if the supplied trial is a repetition whose values have not been aggregated yet.
+func IsHeldRepetition(t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) bool {
+	name := t.Labels[redskyv1beta1.LabelRepetitionOf]
+	if name == "" {
+		return false
+	}
+
+	for i := range trialList.Items {
+		if trialList.Items[i].Name == name && meta.HasFinalizer(&trialList.Items[i], Finalizer) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRepetition returns a new trial that repeats the assignments of the supplied trial. Returns nil if a repetition
+// is already running or if the supplied trial is itself a repetition.
+func NewRepetition(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) *redskyv1beta1.Trial {
+	if IsRepetition(t) {
+		return nil
+	}
+
+	repetitions := Repetitions(t, trialList)
+	for _, r := range repetitions {
+		if !trial.IsFinished(r) && !trial.IsAbandoned(r) {
+			return nil
+		}
+	}
+
+	r := &redskyv1beta1.Trial{}
+	experiment.PopulateTrialFromTemplate(exp, r)
+	r.Name = fmt.Sprintf("%s-r%d", t.Name, len(repetitions)+1)
+	r.GenerateName = ""
+	r.Labels[redskyv1beta1.LabelRepetitionOf] = t.Name
+	r.Spec.Assignments = append(r.Spec.Assignments, t.Spec.Assignments...)
+	r.Spec.TTLSecondsAfterFinished = t.Spec.TTLSecondsAfterFinished
+	r.Spec.TTLSecondsAfterFailure = t.Spec.TTLSecondsAfterFailure
+	trial.UpdateStatus(r)
+
+	// The finalizer keeps the repetition around until it's values are aggregated
+	controllerutil.AddFinalizer(r, Finalizer)
+	return r
+}
+
+// ReleaseRepetitions removes the finalizer from the repetitions of the supplied trial once their values have been
+// aggregated, returning only the repetitions which were modified.
+func ReleaseRepetitions(t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) []*redskyv1beta1.Trial {
+	var result []*redskyv1beta1.Trial
+	for _, r := range Repetitions(t, trialList) {
+		if meta.RemoveFinalizer(r, Finalizer) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// AggregateRepetitions replaces the values of the supplied trial with the aggregate values of the trial and
+// all of it's successfully completed repetitions.
+func AggregateRepetitions(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, trialList *redskyv1beta1.TrialList) error {
+	repetitions := Repetitions(t, trialList)
+	if len(repetitions) == 0 || !trial.CheckCondition(&t.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue) {
+		return nil
+	}
+
+	for i := range t.Spec.Values {
+		v := &t.Spec.Values[i]
+
+		var values []float64
+		for _, r := range append([]*redskyv1beta1.Trial{t}, repetitions...) {
+			if !trial.CheckCondition(&r.Status, redskyv1beta1.TrialComplete, corev1.ConditionTrue) {
+				continue
+			}
+			for _, rv := range r.Spec.Values {
+				if rv.Name != v.Name || rv.AttemptsRemaining != 0 {
+					continue
+				}
+				if fv, err := strconv.ParseFloat(rv.Value, 64); err == nil {
+					values = append(values, fv)
+				}
+			}
+		}
+
+		fn := defaultRepetitionAggregation
+		for j := range exp.Spec.Metrics {
+			if exp.Spec.Metrics[j].Name == v.Name && exp.Spec.Metrics[j].RepetitionAggregation != "" {
+				fn = exp.Spec.Metrics[j].RepetitionAggregation
+			}
+		}
+
+		value, err := metric.Aggregate(fn, values)
+		if err != nil {
+			return err
+		}
+		if !math.IsNaN(value) {
+			v.Value = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestRepetitions(t *testing.T) {
+	three := int32(3)
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Repetitions: &three,
+			Metrics: []redskyv1beta1.Metric{
+				{Name: "duration"},
+				{Name: "cost", RepetitionAggregation: redskyv1beta1.MetricAggregationMaximum},
+			},
+		},
+	}
+
+	complete := redskyv1beta1.TrialStatus{Conditions: []redskyv1beta1.TrialCondition{{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue}}}
+	values := func(duration, cost string) []redskyv1beta1.Value {
+		return []redskyv1beta1.Value{{Name: "duration", Value: duration}, {Name: "cost", Value: cost}}
+	}
+	repetitionOf := map[string]string{redskyv1beta1.LabelRepetitionOf: "example-001"}
+
+	trialList := &redskyv1beta1.TrialList{
+		Items: []redskyv1beta1.Trial{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "example-001", Finalizers: []string{Finalizer}},
+				Spec: redskyv1beta1.TrialSpec{
+					Assignments: []redskyv1beta1.Assignment{{Name: "cpu", Value: intstr.FromInt(100)}},
+					Values:      values("10", "3"),
+				},
+				Status: complete,
+			},
+		},
+	}
+	primary := &trialList.Items[0]
+
+	// The first repetition is needed
+	assert.False(t, RepetitionsFinished(exp, primary, trialList))
+	r := NewRepetition(exp, primary, trialList)
+	if assert.NotNil(t, r) {
+		assert.Equal(t, "example-001-r1", r.Name)
+		assert.Equal(t, "example-001", r.Labels[redskyv1beta1.LabelRepetitionOf])
+		assert.Equal(t, primary.Spec.Assignments, r.Spec.Assignments)
+		assert.Contains(t, r.Finalizers, Finalizer)
+	}
+
+	// Do not start another repetition while one is running
+	trialList.Items = append(trialList.Items, redskyv1beta1.Trial{ObjectMeta: metav1.ObjectMeta{Name: "example-001-r1", Labels: repetitionOf, Finalizers: []string{Finalizer}}})
+	primary = &trialList.Items[0]
+	assert.False(t, RepetitionsFinished(exp, primary, trialList))
+	assert.Nil(t, NewRepetition(exp, primary, trialList))
+	assert.True(t, IsHeldRepetition(&trialList.Items[1], trialList))
+
+	// Repetitions are never repeated
+	assert.True(t, RepetitionsFinished(exp, &trialList.Items[1], trialList))
+	assert.Nil(t, NewRepetition(exp, &trialList.Items[1], trialList))
+
+	// Finish the repetitions
+	trialList.Items[1].Spec.Values = values("14", "1")
+	trialList.Items[1].Status = complete
+	trialList.Items = append(trialList.Items, redskyv1beta1.Trial{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-001-r2", Labels: repetitionOf, Finalizers: []string{Finalizer}},
+		Spec:       redskyv1beta1.TrialSpec{Values: values("11", "2")},
+		Status:     complete,
+	})
+	primary = &trialList.Items[0]
+	assert.True(t, RepetitionsFinished(exp, primary, trialList))
+
+	if assert.NoError(t, AggregateRepetitions(exp, primary, trialList)) {
+		assert.Equal(t, values("11", "3"), primary.Spec.Values)
+	}
+
+	// Release the repetitions once the values are aggregated
+	assert.Len(t, ReleaseRepetitions(primary, trialList), 2)
+	assert.Empty(t, trialList.Items[1].Finalizers)
+	assert.Empty(t, trialList.Items[2].Finalizers)
+	assert.Empty(t, ReleaseRepetitions(primary, trialList))
+}
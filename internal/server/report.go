@@ -0,0 +1,85 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	redskyapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// IdempotencyKeyHeader is the request header used to send idempotency keys to the server
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context that will send the supplied idempotency key with API requests
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKey returns the idempotency key from the supplied context, if any
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// ReportIdempotencyKey returns the idempotency key for reporting the supplied trial. The key is derived from the trial
+// UID so it remains stable across controller restarts.
+func ReportIdempotencyKey(t *redskyv1beta1.Trial) string {
+	if t.UID == "" {
+		return ""
+	}
+	return "report-" + string(t.UID)
+}
+
+// ReportTrial reports the values of the supplied trial back to the server. Every attempt carries the same idempotency
+// key so a failed report can be safely retried by requeuing the trial; a trial which was already reported (e.g. the
+// controller restarted after reporting, but before the trial was updated) produces an error that should be ignored
+// using `controller.IgnoreReportError`.
+func ReportTrial(ctx context.Context, api redskyapi.API, reportTrialURL string, t *redskyv1beta1.Trial) (*redskyapi.TrialValues, error) {
+	ctx = WithIdempotencyKey(ctx, ReportIdempotencyKey(t))
+	trialValues := FromClusterTrial(t)
+	return trialValues, api.ReportTrial(ctx, reportTrialURL, *trialValues)
+}
+
+// IdempotencyTransport returns a round tripper that adds the idempotency key from the request context as a header
+func IdempotencyTransport(transport http.RoundTripper) http.RoundTripper {
+	return &idempotencyTransport{base: transport}
+}
+
+type idempotencyTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip adds the idempotency key header before delegating to the base transport
+func (t *idempotencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if key := IdempotencyKey(req.Context()); key != "" && req.Header.Get(IdempotencyKeyHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	if t.base != nil {
+		return t.base.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
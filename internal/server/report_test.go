@@ -0,0 +1,121 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/controller"
+	redskyapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// reportingAPI mimics the server behavior of rejecting duplicate reports
+type reportingAPI struct {
+	redskyapi.API
+
+	// failures is the number of transient failures to produce before accepting reports
+	failures int
+	// calls is the total number of report attempts
+	calls int
+	// reported is the set of idempotency keys that have been reported
+	reported map[string]redskyapi.TrialValues
+}
+
+func (api *reportingAPI) ReportTrial(ctx context.Context, u string, vs redskyapi.TrialValues) error {
+	api.calls++
+	if api.failures > 0 {
+		api.failures--
+		return fmt.Errorf("connection reset by peer")
+	}
+
+	key := IdempotencyKey(ctx)
+	if _, ok := api.reported[key]; ok {
+		return &redskyapi.Error{Type: redskyapi.ErrTrialAlreadyReported}
+	}
+	if api.reported == nil {
+		api.reported = make(map[string]redskyapi.TrialValues)
+	}
+	api.reported[key] = vs
+	return nil
+}
+
+func TestReportTrial(t *testing.T) {
+	newTrial := func() *redskyv1beta1.Trial {
+		tr := &redskyv1beta1.Trial{}
+		tr.UID = "8f7c2d3e-0000-0000-0000-000000000000"
+		tr.Spec.Values = []redskyv1beta1.Value{{Name: "cost", Value: "1.5"}}
+		return tr
+	}
+
+	t.Run("transient failure", func(t *testing.T) {
+		api := &reportingAPI{failures: 1}
+
+		// The failure is returned so the trial can be requeued
+		_, err := ReportTrial(context.TODO(), api, "", newTrial())
+		assert.Error(t, controller.IgnoreReportError(err))
+		assert.Empty(t, api.reported)
+
+		_, err = ReportTrial(context.TODO(), api, "", newTrial())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, api.calls)
+		assert.Len(t, api.reported, 1)
+	})
+
+	t.Run("report then crash", func(t *testing.T) {
+		api := &reportingAPI{}
+
+		// The first report succeeds, but the trial update is lost (e.g. the controller restarted)
+		_, err := ReportTrial(context.TODO(), api, "", newTrial())
+		assert.NoError(t, err)
+
+		// The trial is reported again when the controller restarts
+		_, err = ReportTrial(context.TODO(), api, "", newTrial())
+		assert.NoError(t, controller.IgnoreReportError(err))
+
+		assert.Equal(t, 2, api.calls)
+		if assert.Len(t, api.reported, 1) {
+			assert.Contains(t, api.reported, "report-8f7c2d3e-0000-0000-0000-000000000000")
+		}
+	})
+}
+
+func TestIdempotencyTransport(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: IdempotencyTransport(nil)}
+	for _, ctx := range []context.Context{context.TODO(), WithIdempotencyKey(context.TODO(), "test")} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+		if assert.NoError(t, err) {
+			resp, err := c.Do(req)
+			if assert.NoError(t, err) {
+				_ = resp.Body.Close()
+			}
+		}
+	}
+
+	assert.Equal(t, []string{"", "test"}, keys)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+// Scaled parameters are sent to the server as an integer range of step indices, [0, n], the server is then free to
+// explore the indices uniformly and the cluster maps suggested indices back into the actual parameter values. For a
+// linear scale, the value at index k is `min + k*step`; for a log scale it is `min * step^k`.
+
+// isScaled checks to see if the numeric range of the parameter must be explored using step indices.
+func isScaled(p *redskyv1beta1.Parameter) bool {
+	if len(p.GetValues()) > 0 {
+		return false
+	}
+	return p.Scale == redskyv1beta1.ScaleLog || p.Step > 1
+}
+
+// scaleStep returns the effective step of a numeric parameter.
+func scaleStep(p *redskyv1beta1.Parameter) (int64, error) {
+	step := int64(p.Step)
+	switch p.Scale {
+	case "", redskyv1beta1.ScaleLinear:
+		if step == 0 {
+			step = 1
+		}
+		if step < 1 {
+			return 0, fmt.Errorf("invalid step for parameter '%s': %d", p.Name, step)
+		}
+	case redskyv1beta1.ScaleLog:
+		if step == 0 {
+			step = 2
+		}
+		if step < 2 {
+			return 0, fmt.Errorf("invalid step for log scale parameter '%s': %d", p.Name, step)
+		}
+		if p.Min < 1 {
+			return 0, fmt.Errorf("invalid minimum for log scale parameter '%s': %d", p.Name, p.Min)
+		}
+	default:
+		return 0, fmt.Errorf("unknown scale for parameter '%s': %s", p.Name, p.Scale)
+	}
+	return step, nil
+}
+
+// scaledMax returns the largest step index of a scaled parameter.
+func scaledMax(p *redskyv1beta1.Parameter) (int64, error) {
+	step, err := scaleStep(p)
+	if err != nil {
+		return 0, err
+	}
+
+	min, max := int64(p.Min), int64(p.Max)
+	if max < min {
+		return 0, fmt.Errorf("invalid range for parameter '%s': [%d, %d]", p.Name, min, max)
+	}
+
+	if p.Scale != redskyv1beta1.ScaleLog {
+		return (max - min) / step, nil
+	}
+
+	var n int64
+	for v := min * step; v <= max; v *= step {
+		n++
+	}
+	return n, nil
+}
+
+// scaledIndex returns the step index of the supplied value, values which do not fall on a step are rejected.
+func scaledIndex(p *redskyv1beta1.Parameter, value int32) (int64, error) {
+	step, err := scaleStep(p)
+	if err != nil {
+		return 0, err
+	}
+
+	min, max, v := int64(p.Min), int64(p.Max), int64(value)
+	if v < min || v > max {
+		return 0, fmt.Errorf("value out of range for parameter '%s': %d", p.Name, v)
+	}
+
+	if p.Scale != redskyv1beta1.ScaleLog {
+		if (v-min)%step != 0 {
+			return 0, fmt.Errorf("value is not a multiple of the step for parameter '%s': %d", p.Name, v)
+		}
+		return (v - min) / step, nil
+	}
+
+	var k int64
+	for s := min; s <= v; s *= step {
+		if s == v {
+			return k, nil
+		}
+		k++
+	}
+	return 0, fmt.Errorf("value is not a power of the step for parameter '%s': %d", p.Name, v)
+}
+
+// scaledValue returns the value of the supplied step index, the index is clamped to the range of the parameter.
+func scaledValue(p *redskyv1beta1.Parameter, k int64) int64 {
+	step, err := scaleStep(p)
+	if err != nil {
+		return k
+	}
+
+	n, _ := scaledMax(p)
+	switch {
+	case k < 0:
+		k = 0
+	case k > n:
+		k = n
+	}
+
+	if p.Scale != redskyv1beta1.ScaleLog {
+		return int64(p.Min) + k*step
+	}
+
+	v := int64(p.Min)
+	for ; k > 0; k-- {
+		v *= step
+	}
+	return v
+}
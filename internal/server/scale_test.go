@@ -0,0 +1,204 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	redskyapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1/numstr"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestScaledParameter(t *testing.T) {
+	cases := []struct {
+		desc      string
+		parameter redskyv1beta1.Parameter
+		values    []int64
+		invalid   []int32
+	}{
+		{
+			desc:      "linear step",
+			parameter: redskyv1beta1.Parameter{Name: "replicas", Min: 10, Max: 55, Step: 10},
+			values:    []int64{10, 20, 30, 40, 50},
+			invalid:   []int32{15, 60},
+		},
+		{
+			desc:      "log default step",
+			parameter: redskyv1beta1.Parameter{Name: "heap", Min: 64, Max: 1024, Scale: redskyv1beta1.ScaleLog},
+			values:    []int64{64, 128, 256, 512, 1024},
+			invalid:   []int32{100, 2048},
+		},
+		{
+			desc:      "log step",
+			parameter: redskyv1beta1.Parameter{Name: "connections", Min: 1, Max: 500, Scale: redskyv1beta1.ScaleLog, Step: 10},
+			values:    []int64{1, 10, 100},
+			invalid:   []int32{50, 500},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p := &c.parameter
+			if !assert.True(t, isScaled(p)) {
+				return
+			}
+
+			n, err := scaledMax(p)
+			if assert.NoError(t, err) {
+				assert.Equal(t, int64(len(c.values)-1), n)
+			}
+
+			for k, v := range c.values {
+				assert.Equal(t, v, scaledValue(p, int64(k)))
+				i, err := scaledIndex(p, int32(v))
+				if assert.NoError(t, err) {
+					assert.Equal(t, int64(k), i)
+				}
+			}
+
+			for _, v := range c.invalid {
+				_, err := scaledIndex(p, v)
+				assert.Error(t, err)
+			}
+
+			// Out of range indices are clamped
+			assert.Equal(t, c.values[0], scaledValue(p, -1))
+			assert.Equal(t, c.values[len(c.values)-1], scaledValue(p, n+1))
+		})
+	}
+}
+
+func TestScaledParameter_Invalid(t *testing.T) {
+	for _, p := range []redskyv1beta1.Parameter{
+		{Name: "zero", Min: 0, Max: 8, Scale: redskyv1beta1.ScaleLog},
+		{Name: "one", Min: 1, Max: 8, Scale: redskyv1beta1.ScaleLog, Step: 1},
+		{Name: "negative", Min: 1, Max: 8, Step: -2},
+		{Name: "unknown", Min: 1, Max: 8, Scale: "exponential"},
+	} {
+		_, err := scaledMax(&p)
+		assert.Error(t, err, p.Name)
+	}
+}
+
+func TestScaledParameter_RoundTrip(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{}
+	exp.Name = "scaled"
+	exp.Spec.Parameters = []redskyv1beta1.Parameter{
+		{Name: "heap", Min: 64, Max: 1024, Scale: redskyv1beta1.ScaleLog, Baseline: &intstr.IntOrString{IntVal: 256}},
+		{Name: "replicas", Min: 1, Max: 9, Step: 2, Baseline: &intstr.IntOrString{IntVal: 3}},
+		{Name: "cpu", Min: 100, Max: 4000, Baseline: &intstr.IntOrString{IntVal: 500}},
+	}
+
+	_, out, baseline, err := FromCluster(exp)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []redskyapi.Parameter{
+		{Type: redskyapi.ParameterTypeInteger, Name: "heap", Bounds: &redskyapi.Bounds{Min: json.Number("0"), Max: json.Number("4")}},
+		{Type: redskyapi.ParameterTypeInteger, Name: "replicas", Bounds: &redskyapi.Bounds{Min: json.Number("0"), Max: json.Number("4")}},
+		{Type: redskyapi.ParameterTypeInteger, Name: "cpu", Bounds: &redskyapi.Bounds{Min: json.Number("100"), Max: json.Number("4000")}},
+	}, out.Parameters)
+	assert.Equal(t, []redskyapi.Assignment{
+		{ParameterName: "heap", Value: numstr.FromInt64(2)},
+		{ParameterName: "replicas", Value: numstr.FromInt64(1)},
+		{ParameterName: "cpu", Value: numstr.FromInt64(500)},
+	}, baseline.Assignments)
+
+	tr := &redskyv1beta1.Trial{}
+	tr.Annotations = map[string]string{}
+	ToClusterTrial(tr, &redskyapi.TrialAssignments{Assignments: []redskyapi.Assignment{
+		{ParameterName: "heap", Value: numstr.FromInt64(4)},
+		{ParameterName: "replicas", Value: numstr.FromInt64(3)},
+		{ParameterName: "cpu", Value: numstr.FromInt64(1000)},
+	}}, exp)
+	assert.Equal(t, []redskyv1beta1.Assignment{
+		{Name: "heap", Value: intstr.FromInt(1024)},
+		{Name: "replicas", Value: intstr.FromInt(7)},
+		{Name: "cpu", Value: intstr.FromInt(1000)},
+	}, tr.Spec.Assignments)
+
+	// Baselines must fall on a step
+	exp.Spec.Parameters[0].Baseline = &intstr.IntOrString{IntVal: 300}
+	_, _, _, err = FromCluster(exp)
+	assert.Error(t, err)
+}
+
+func TestScaledParameter_Constraints(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{}
+	exp.Name = "scaled"
+	exp.Spec.Parameters = []redskyv1beta1.Parameter{
+		{Name: "heap", Min: 64, Max: 1024, Scale: redskyv1beta1.ScaleLog},
+		{Name: "cpu", Min: 100, Max: 4000},
+		{Name: "memory", Min: 100, Max: 4000},
+	}
+
+	cases := []struct {
+		desc       string
+		constraint redskyv1beta1.Constraint
+		err        string
+	}{
+		{
+			desc: "order",
+			constraint: redskyv1beta1.Constraint{
+				Name:  "heap-memory",
+				Order: &redskyv1beta1.OrderConstraint{LowerParameter: "heap", UpperParameter: "memory"},
+			},
+			err: "constraint 'heap-memory' references scaled parameter 'heap', constraints cannot be used with scaled parameters",
+		},
+		{
+			desc: "sum",
+			constraint: redskyv1beta1.Constraint{
+				Name: "total",
+				Sum: &redskyv1beta1.SumConstraint{
+					Bound:      resource.MustParse("2000"),
+					Parameters: []redskyv1beta1.SumConstraintParameter{{Name: "memory", Weight: resource.MustParse("1")}, {Name: "heap", Weight: resource.MustParse("1")}},
+				},
+			},
+			err: "constraint 'total' references scaled parameter 'heap', constraints cannot be used with scaled parameters",
+		},
+		{
+			desc: "expression",
+			constraint: redskyv1beta1.Constraint{
+				Name:       "heap-limit",
+				Expression: "heap + memory <= 2000",
+			},
+			err: "constraint 'heap-limit' references scaled parameter 'heap', constraints cannot be used with scaled parameters",
+		},
+		{
+			desc: "unscaled",
+			constraint: redskyv1beta1.Constraint{
+				Name:  "cpu-memory",
+				Order: &redskyv1beta1.OrderConstraint{LowerParameter: "cpu", UpperParameter: "memory"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			exp.Spec.Constraints = []redskyv1beta1.Constraint{c.constraint}
+			_, _, _, err := FromCluster(exp)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
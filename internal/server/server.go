@@ -17,6 +17,7 @@ limitations under the License.
 package server
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -26,10 +27,13 @@ import (
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/internal/meta"
 	"github.com/thestormforge/optimize-controller/internal/trial"
+	"github.com/thestormforge/optimize-controller/internal/validation"
 	redskyapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1/numstr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -44,11 +48,11 @@ const (
 // FromCluster converts cluster state to API state
 func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redskyapi.Experiment, *redskyapi.TrialAssignments, error) {
 	out := &redskyapi.Experiment{}
-	out.ExperimentMeta.LastModified = in.CreationTimestamp.Time
+	out.ExperimentMeta.LastModified = LastModified(in)
 	out.ExperimentMeta.SelfURL = in.Annotations[redskyv1beta1.AnnotationExperimentURL]
 	out.ExperimentMeta.NextTrialURL = in.Annotations[redskyv1beta1.AnnotationNextTrialURL]
 
-	baseline := &redskyapi.TrialAssignments{Labels: map[string]string{"baseline": "true"}}
+	baseline := &redskyapi.TrialAssignments{Labels: map[string]string{labelBaseline: "true"}}
 
 	if l := len(in.ObjectMeta.Labels); l > 0 {
 		out.Labels = make(map[string]string, l)
@@ -58,6 +62,21 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 		}
 	}
 
+	// Descriptive information is stored using labels so it is available to the results UI
+	for k, v := range map[string]string{
+		"description": in.Spec.Description,
+		"owner":       in.Spec.Owner,
+		"ticket":      in.Spec.TicketURL,
+	} {
+		if v == "" {
+			continue
+		}
+		if out.Labels == nil {
+			out.Labels = make(map[string]string)
+		}
+		out.Labels[k] = v
+	}
+
 	out.Optimization = nil
 	for _, o := range in.Spec.Optimization {
 		out.Optimization = append(out.Optimization, redskyapi.Optimization{
@@ -68,42 +87,67 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 
 	out.Parameters = nil
 	for _, p := range in.Spec.Parameters {
+		// The server does not know about parameter dependencies, it will make suggestions for inactive parameters
+		// which are subsequently ignored when the patches are rendered
+		if err := checkDependency(in.Spec.Parameters, &p); err != nil {
+			return nil, nil, nil, err
+		}
+
+		// Boolean parameters are represented as categorical parameters
+		values := p.GetValues()
+
 		// This is a special case to omit parameters client side
-		if p.Min == p.Max && len(p.Values) == 0 {
+		if p.Min == p.Max && len(values) == 0 {
 			continue
 		}
 
-		if len(p.Values) > 0 {
+		if len(values) > 0 {
 			out.Parameters = append(out.Parameters, redskyapi.Parameter{
 				Type:   redskyapi.ParameterTypeCategorical,
 				Name:   p.Name,
-				Values: p.Values,
+				Values: values,
 			})
 		} else {
+			min, max := int64(p.Min), int64(p.Max)
+			if isScaled(&p) {
+				n, err := scaledMax(&p)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				min, max = 0, n
+			}
+
 			out.Parameters = append(out.Parameters, redskyapi.Parameter{
 				Type: redskyapi.ParameterTypeInteger,
 				Name: p.Name,
 				Bounds: &redskyapi.Bounds{
-					Min: json.Number(strconv.FormatInt(int64(p.Min), 10)),
-					Max: json.Number(strconv.FormatInt(int64(p.Max), 10)),
+					Min: json.Number(strconv.FormatInt(min, 10)),
+					Max: json.Number(strconv.FormatInt(max, 10)),
 				},
 			})
 		}
 
-		if p.Baseline != nil {
+		if baselineValue := p.GetBaseline(); baselineValue != nil {
 			var v numstr.NumberOrString
-			if p.Baseline.Type == intstr.String {
-				vs := p.Baseline.StrVal
-				if !stringSliceContains(p.Values, vs) {
+			if baselineValue.Type == intstr.String {
+				vs := baselineValue.StrVal
+				if !stringSliceContains(values, vs) {
 					return nil, nil, nil, fmt.Errorf("baseline out of range for parameter '%s'", p.Name)
 				}
 				v = numstr.FromString(vs)
 			} else {
-				vi := p.Baseline.IntVal
+				vi := baselineValue.IntVal
 				if vi < p.Min || vi > p.Max {
 					return nil, nil, nil, fmt.Errorf("baseline out of range for parameter '%s'", p.Name)
 				}
 				v = numstr.FromInt64(int64(vi))
+				if isScaled(&p) {
+					k, err := scaledIndex(&p, vi)
+					if err != nil {
+						return nil, nil, nil, fmt.Errorf("invalid baseline: %w", err)
+					}
+					v = numstr.FromInt64(k)
+				}
 			}
 			baseline.Assignments = append(baseline.Assignments, redskyapi.Assignment{
 				ParameterName: p.Name,
@@ -116,6 +160,9 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 	for _, c := range in.Spec.Constraints {
 		switch {
 		case c.Order != nil:
+			if err := checkScaledConstraint(in.Spec.Parameters, c.Name, c.Order.LowerParameter, c.Order.UpperParameter); err != nil {
+				return nil, nil, nil, err
+			}
 			out.Constraints = append(out.Constraints, redskyapi.Constraint{
 				Name:           c.Name,
 				ConstraintType: redskyapi.ConstraintOrder,
@@ -135,6 +182,10 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 					continue
 				}
 
+				if err := checkScaledConstraint(in.Spec.Parameters, c.Name, p.Name); err != nil {
+					return nil, nil, nil, err
+				}
+
 				sc.Parameters = append(sc.Parameters, redskyapi.SumConstraintParameter{
 					Name:   p.Name,
 					Weight: float64(p.Weight.MilliValue()) / 1000,
@@ -146,6 +197,26 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 				ConstraintType: redskyapi.ConstraintSum,
 				SumConstraint:  sc,
 			})
+		case c.Expression != "":
+			ce, err := checkConstraintExpression(in.Spec.Parameters, c.Expression)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			if err := checkScaledConstraint(in.Spec.Parameters, c.Name, ce.Parameters()...); err != nil {
+				return nil, nil, nil, err
+			}
+
+			// Only linear expressions can be sent to the server (as sum constraints)
+			sc, ok := linearConstraint(ce)
+			if !ok {
+				return nil, nil, nil, fmt.Errorf("constraint '%s' expression '%s' is not linear, only linear expressions can be used with the server", c.Name, c.Expression)
+			}
+			out.Constraints = append(out.Constraints, redskyapi.Constraint{
+				Name:           c.Name,
+				ConstraintType: redskyapi.ConstraintSum,
+				SumConstraint:  *sc,
+			})
 		}
 	}
 
@@ -158,6 +229,9 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 		})
 	}
 
+	// NOTE: The Experiments API has no representation of a metric target value, targets are only applied by the
+	// controller to the values it reports (see `metric.ApplyTarget`) and are never sent to the server
+
 	// Check that we have the correct number of assignments on the baseline
 	if len(baseline.Assignments) == 0 {
 		baseline = nil
@@ -169,6 +243,95 @@ func FromCluster(in *redskyv1beta1.Experiment) (redskyapi.ExperimentName, *redsk
 	return n, out, baseline, nil
 }
 
+// checkConstraintExpression verifies that the constraint expression is valid and only references numeric parameters.
+func checkConstraintExpression(params []redskyv1beta1.Parameter, expr string) (*validation.ConstraintExpression, error) {
+	ce, err := validation.ParseConstraintExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ce.Parameters()) == 0 {
+		return nil, fmt.Errorf("constraint expression '%s' does not reference any parameters", expr)
+	}
+	for _, name := range ce.Parameters() {
+		p := findParameter(params, name)
+		if p == nil {
+			return nil, fmt.Errorf("constraint expression '%s' references unknown parameter '%s'", expr, name)
+		}
+		if len(p.GetValues()) > 0 {
+			return nil, fmt.Errorf("constraint expression '%s' references non-numeric parameter '%s'", expr, name)
+		}
+	}
+
+	return ce, nil
+}
+
+// checkScaledConstraint verifies that a constraint does not reference scaled parameters: the server only sees the step
+// index of a scaled parameter so the constraint would not apply to the actual values.
+func checkScaledConstraint(params []redskyv1beta1.Parameter, constraint string, names ...string) error {
+	for _, name := range names {
+		if p := findParameter(params, name); p != nil && isScaled(p) {
+			return fmt.Errorf("constraint '%s' references scaled parameter '%s', constraints cannot be used with scaled parameters", constraint, name)
+		}
+	}
+	return nil
+}
+
+// linearConstraint returns the sum constraint equivalent to the supplied constraint expression. Expressions that are
+// not linear cannot be converted.
+func linearConstraint(ce *validation.ConstraintExpression) (*redskyapi.SumConstraint, bool) {
+	weights, bound, isUpperBound, ok := ce.Linear()
+	if !ok || len(weights) == 0 {
+		return nil, false
+	}
+
+	sc := &redskyapi.SumConstraint{
+		IsUpperBound: isUpperBound,
+		Bound:        bound,
+	}
+	for _, name := range ce.Parameters() {
+		if w, ok := weights[name]; ok {
+			sc.Parameters = append(sc.Parameters, redskyapi.SumConstraintParameter{
+				Name:   name,
+				Weight: w,
+			})
+		}
+	}
+	return sc, true
+}
+
+// checkDependency verifies that the parameter depends on valid values of another categorical parameter.
+func checkDependency(params []redskyv1beta1.Parameter, p *redskyv1beta1.Parameter) error {
+	visited := map[string]bool{p.Name: true}
+	for dep := p.DependsOn; dep != nil; {
+		if visited[dep.Name] {
+			return fmt.Errorf("circular dependency for parameter '%s'", p.Name)
+		}
+		visited[dep.Name] = true
+
+		dp := findParameter(params, dep.Name)
+		if dp == nil {
+			return fmt.Errorf("unknown dependency '%s' for parameter '%s'", dep.Name, p.Name)
+		}
+
+		values := dp.GetValues()
+		if len(values) == 0 {
+			return fmt.Errorf("dependency '%s' for parameter '%s' must be categorical", dep.Name, p.Name)
+		}
+		if len(dep.Values) == 0 {
+			return fmt.Errorf("dependency '%s' for parameter '%s' must specify values", dep.Name, p.Name)
+		}
+		for _, v := range dep.Values {
+			if !stringSliceContains(values, v) {
+				return fmt.Errorf("invalid value '%s' of dependency '%s' for parameter '%s'", v, dep.Name, p.Name)
+			}
+		}
+
+		dep = dp.DependsOn
+	}
+	return nil
+}
+
 // ToCluster converts API state to cluster state
 func ToCluster(exp *redskyv1beta1.Experiment, ee *redskyapi.Experiment) {
 	if exp.GetAnnotations() == nil {
@@ -189,41 +352,177 @@ func ToCluster(exp *redskyv1beta1.Experiment, ee *redskyapi.Experiment) {
 	controllerutil.AddFinalizer(exp, Finalizer)
 }
 
+// ToClusterExperiment converts an API experiment definition into cluster state, it is the inverse of `FromCluster`.
+// The API does not have any information about how to run trials (e.g. patches or metric queries), that information
+// must already be present on the supplied experiment. Parameters and metrics already on the experiment are matched
+// by name to preserve their cluster specific configuration. The parameter baselines are stored on the baseline trial
+// instead of the experiment, use `ToClusterBaseline` to restore them.
+func ToClusterExperiment(exp *redskyv1beta1.Experiment, ee *redskyapi.Experiment) error {
+	for k, v := range ee.Labels {
+		switch k {
+		case "description":
+			exp.Spec.Description = v
+		case "owner":
+			exp.Spec.Owner = v
+		case "ticket":
+			exp.Spec.TicketURL = v
+		default:
+			if k == "application" || k == "scenario" {
+				k = "redskyops.dev/" + k
+			}
+			if exp.Labels == nil {
+				exp.Labels = make(map[string]string)
+			}
+			exp.Labels[k] = v
+		}
+	}
+
+	params := make([]redskyv1beta1.Parameter, 0, len(ee.Parameters))
+	for _, p := range ee.Parameters {
+		param := redskyv1beta1.Parameter{Name: p.Name}
+		if existing := findParameter(exp.Spec.Parameters, p.Name); existing != nil {
+			param = *existing
+		}
+
+		switch p.Type {
+		case redskyapi.ParameterTypeCategorical:
+			if len(p.Values) == 2 && p.Values[0] == "false" && p.Values[1] == "true" {
+				param.Type = redskyv1beta1.ParameterTypeBoolean
+				param.Values = nil
+			} else if param.Type != redskyv1beta1.ParameterTypeBoolean {
+				param.Values = p.Values
+			}
+		case redskyapi.ParameterTypeInteger:
+			if p.Bounds == nil {
+				return fmt.Errorf("missing bounds for parameter '%s'", p.Name)
+			}
+
+			// Scaled parameters are represented by index on the server, keep the existing range
+			if isScaled(&param) {
+				break
+			}
+
+			min, err := p.Bounds.Min.Int64()
+			if err != nil {
+				return fmt.Errorf("invalid minimum for parameter '%s': %w", p.Name, err)
+			}
+			max, err := p.Bounds.Max.Int64()
+			if err != nil {
+				return fmt.Errorf("invalid maximum for parameter '%s': %w", p.Name, err)
+			}
+			param.Min, param.Max = int32(min), int32(max)
+		default:
+			return fmt.Errorf("unsupported type '%s' for parameter '%s'", p.Type, p.Name)
+		}
+
+		params = append(params, param)
+	}
+	exp.Spec.Parameters = params
+
+	var constraints []redskyv1beta1.Constraint
+	for _, c := range ee.Constraints {
+		switch c.ConstraintType {
+		case redskyapi.ConstraintOrder:
+			constraints = append(constraints, redskyv1beta1.Constraint{
+				Name: c.Name,
+				Order: &redskyv1beta1.OrderConstraint{
+					LowerParameter: c.OrderConstraint.LowerParameter,
+					UpperParameter: c.OrderConstraint.UpperParameter,
+				},
+			})
+		case redskyapi.ConstraintSum:
+			sc := &redskyv1beta1.SumConstraint{
+				IsUpperBound: c.SumConstraint.IsUpperBound,
+				Bound:        *milliQuantity(c.SumConstraint.Bound),
+			}
+			for _, p := range c.SumConstraint.Parameters {
+				sc.Parameters = append(sc.Parameters, redskyv1beta1.SumConstraintParameter{
+					Name:   p.Name,
+					Weight: *milliQuantity(p.Weight),
+				})
+			}
+			constraints = append(constraints, redskyv1beta1.Constraint{
+				Name: c.Name,
+				Sum:  sc,
+			})
+		default:
+			return fmt.Errorf("unsupported type '%s' for constraint '%s'", c.ConstraintType, c.Name)
+		}
+	}
+
+	metrics := make([]redskyv1beta1.Metric, 0, len(ee.Metrics))
+	for _, m := range ee.Metrics {
+		metric := redskyv1beta1.Metric{Name: m.Name}
+		for i := range exp.Spec.Metrics {
+			if exp.Spec.Metrics[i].Name == m.Name {
+				metric = exp.Spec.Metrics[i]
+				break
+			}
+		}
+
+		metric.Minimize = m.Minimize
+		metric.Optimize = m.Optimize
+		metrics = append(metrics, metric)
+	}
+
+	exp.Spec.Constraints = constraints
+	exp.Spec.Metrics = metrics
+
+	ToCluster(exp, ee)
+	return nil
+}
+
+// ToClusterBaseline restores the parameter baselines from the assignments of the baseline trial, it is the inverse of
+// the baseline returned by `FromCluster`.
+func ToClusterBaseline(exp *redskyv1beta1.Experiment, baseline *redskyapi.TrialAssignments) {
+	for _, a := range baseline.Assignments {
+		if p := findParameter(exp.Spec.Parameters, a.ParameterName); p != nil {
+			v := toClusterValue(p, a.Value)
+			p.Baseline = &v
+		}
+	}
+}
+
+// toClusterValue converts an assignment value from the server for the supplied (possibly nil) parameter.
+func toClusterValue(p *redskyv1beta1.Parameter, value numstr.NumberOrString) intstr.IntOrString {
+	if value.IsString {
+		return intstr.FromString(value.StrVal)
+	}
+
+	// While the server supports 64-bit integers, any parameters used for Kubernetes
+	// experiments will have been defined with 32-bit integer bounds.
+	val := value.Int64Value()
+	if p != nil && isScaled(p) {
+		val = scaledValue(p, val)
+	}
+	switch {
+	case val > math.MaxInt32:
+		return intstr.FromInt(math.MaxInt32)
+	case val < math.MinInt32:
+		return intstr.FromInt(math.MinInt32)
+	default:
+		return intstr.FromInt(int(val))
+	}
+}
+
+// milliQuantity returns the quantity for a floating point value using milli-unit precision.
+func milliQuantity(v float64) *resource.Quantity {
+	return resource.NewMilliQuantity(int64(math.Round(v*1000)), resource.DecimalSI)
+}
+
 // ToClusterTrial converts API state to cluster state
-func ToClusterTrial(t *redskyv1beta1.Trial, suggestion *redskyapi.TrialAssignments) {
+func ToClusterTrial(t *redskyv1beta1.Trial, suggestion *redskyapi.TrialAssignments, exp *redskyv1beta1.Experiment) {
 	t.GetAnnotations()[redskyv1beta1.AnnotationReportTrialURL] = suggestion.SelfURL
 
 	// Try to make the cluster trial names match what is on the server
 	if t.Name == "" && t.GenerateName != "" && suggestion.SelfURL != "" {
-		name := path.Base(suggestion.SelfURL)
-		if num, err := strconv.ParseInt(name, 10, 64); err == nil {
-			t.Name = fmt.Sprintf("%s%03d", t.GenerateName, num)
-		} else {
-			t.Name = t.GenerateName + name
-		}
+		t.Name = trialName(t.GenerateName, path.Base(suggestion.SelfURL), exp.Spec.TrialNaming)
 	}
 
 	for _, a := range suggestion.Assignments {
-		var v intstr.IntOrString
-		if a.Value.IsString {
-			v = intstr.FromString(a.Value.StrVal)
-		} else {
-			// While the server supports 64-bit integers, any parameters used for Kubernetes
-			// experiments will have been defined with 32-bit integer bounds.
-			val := a.Value.Int64Value()
-			switch {
-			case val > math.MaxInt32:
-				v = intstr.FromInt(math.MaxInt32)
-			case val < math.MinInt32:
-				v = intstr.FromInt(math.MinInt32)
-			default:
-				v = intstr.FromInt(int(val))
-			}
-		}
-
 		t.Spec.Assignments = append(t.Spec.Assignments, redskyv1beta1.Assignment{
 			Name:  a.ParameterName,
-			Value: v,
+			Value: toClusterValue(findParameter(exp.Spec.Parameters, a.ParameterName), a.Value),
 		})
 	}
 
@@ -245,6 +544,39 @@ func ToClusterTrial(t *redskyv1beta1.Trial, suggestion *redskyapi.TrialAssignmen
 	controllerutil.AddFinalizer(t, Finalizer)
 }
 
+// findParameter returns the named parameter from the supplied list.
+func findParameter(params []redskyv1beta1.Parameter, name string) *redskyv1beta1.Parameter {
+	for i := range params {
+		if params[i].Name == name {
+			return &params[i]
+		}
+	}
+	return nil
+}
+
+// trialName returns the name of a trial given the generate name prefix and the server identifier of the trial.
+func trialName(prefix, id string, naming *redskyv1beta1.TrialNaming) string {
+	suffix := redskyv1beta1.TrialNameNumber
+	if naming != nil && naming.Suffix != "" {
+		suffix = naming.Suffix
+	}
+
+	var name string
+	switch suffix {
+	case redskyv1beta1.TrialNameHash:
+		name = prefix + fmt.Sprintf("%x", sha1.Sum([]byte(prefix+id)))[0:5]
+	default:
+		if num, err := strconv.ParseInt(id, 10, 64); err == nil {
+			name = fmt.Sprintf("%s%03d", prefix, num)
+		} else {
+			name = prefix + id
+		}
+	}
+
+	// The trial name is also used for the job name and as a label value
+	return meta.TruncateName(name, meta.MaxNameLength)
+}
+
 // FromClusterTrial converts cluster state to API state
 func FromClusterTrial(t *redskyv1beta1.Trial) *redskyapi.TrialValues {
 	out := &redskyapi.TrialValues{}
@@ -304,8 +636,31 @@ func FailExperiment(exp *redskyv1beta1.Experiment, reason string, err error) boo
 	return true
 }
 
+// SyncSucceeded records a successful interaction with the server on the experiment status. Returns true
+// only if the status needed to be changed.
+func SyncSucceeded(exp *redskyv1beta1.Experiment) bool {
+	if exp.Status.ServerSyncFailures == 0 && experiment.CheckCondition(&exp.Status, redskyv1beta1.ExperimentServerSynced, corev1.ConditionTrue) {
+		return false
+	}
+
+	exp.Status.ServerSyncFailures = 0
+	experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentServerSynced, corev1.ConditionTrue, "", "", nil)
+	return true
+}
+
+// SyncFailed records a failed interaction with the server on the experiment status.
+func SyncFailed(exp *redskyv1beta1.Experiment, reason string, err error) {
+	exp.Status.ServerSyncFailures++
+	experiment.ApplyCondition(&exp.Status, redskyv1beta1.ExperimentServerSynced, corev1.ConditionFalse, reason, err.Error(), nil)
+}
+
 // IsServerSyncEnabled checks to see if server synchronization is enabled.
 func IsServerSyncEnabled(exp *redskyv1beta1.Experiment) bool {
+	// Experiments using a local optimizer never exist on the server
+	if exp.Spec.LocalOptimizer != nil {
+		return false
+	}
+
 	switch strings.ToLower(exp.GetAnnotations()[redskyv1beta1.AnnotationServerSync]) {
 	case "disabled", "false":
 		return false
@@ -38,7 +38,9 @@ func TestFromCluster(t *testing.T) {
 	one := intstr.FromInt(1)
 	two := intstr.FromInt(2)
 	three := intstr.FromString("three")
-	now := time.Now()
+	smallOrdinal, mediumOrdinal, largeOrdinal := int32(1), int32(2), int32(3)
+	latencyTarget, throughputTarget := resource.MustParse("200m"), resource.MustParse("5")
+	now := time.Now().Truncate(time.Second)
 	cases := []struct {
 		desc     string
 		in       *redskyv1beta1.Experiment
@@ -68,6 +70,27 @@ func TestFromCluster(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "description",
+			in: &redskyv1beta1.Experiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"redskyops.dev/application": "test"},
+				},
+				Spec: redskyv1beta1.ExperimentSpec{
+					Description: "Testing the description",
+					Owner:       "test-team",
+					TicketURL:   "https://example.com/issues/1",
+				},
+			},
+			out: &redskyapi.Experiment{
+				Labels: map[string]string{
+					"application": "test",
+					"description": "Testing the description",
+					"owner":       "test-team",
+					"ticket":      "https://example.com/issues/1",
+				},
+			},
+		},
 		{
 			desc: "optimization",
 			in: &redskyv1beta1.Experiment{
@@ -203,6 +226,48 @@ func TestFromCluster(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "expressionConstraints",
+			in: &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					Parameters: []redskyv1beta1.Parameter{
+						{Name: "cpu", Min: 1, Max: 8},
+						{Name: "replicas", Min: 1, Max: 8},
+					},
+					Constraints: []redskyv1beta1.Constraint{
+						{Name: "linear", Expression: "2 * cpu <= 10 - replicas"},
+					},
+				},
+			},
+			out: &redskyapi.Experiment{
+				Parameters: []redskyapi.Parameter{
+					{
+						Type:   redskyapi.ParameterTypeInteger,
+						Name:   "cpu",
+						Bounds: &redskyapi.Bounds{Min: "1", Max: "8"},
+					},
+					{
+						Type:   redskyapi.ParameterTypeInteger,
+						Name:   "replicas",
+						Bounds: &redskyapi.Bounds{Min: "1", Max: "8"},
+					},
+				},
+				Constraints: []redskyapi.Constraint{
+					{
+						Name:           "linear",
+						ConstraintType: redskyapi.ConstraintSum,
+						SumConstraint: redskyapi.SumConstraint{
+							IsUpperBound: true,
+							Bound:        10,
+							Parameters: []redskyapi.SumConstraintParameter{
+								{Name: "cpu", Weight: 2.0},
+								{Name: "replicas", Weight: 1.0},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "metrics",
 			in: &redskyv1beta1.Experiment{
@@ -222,6 +287,23 @@ func TestFromCluster(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "metric targets",
+			in: &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					Metrics: []redskyv1beta1.Metric{
+						{Name: "one", Minimize: true, TargetValue: &latencyTarget},
+						{Name: "two", Minimize: false, TargetValue: &throughputTarget},
+					},
+				},
+			},
+			out: &redskyapi.Experiment{
+				Metrics: []redskyapi.Metric{
+					{Name: "one", Minimize: true},
+					{Name: "two", Minimize: false},
+				},
+			},
+		},
 		{
 			desc: "baseline",
 			in: &redskyv1beta1.Experiment{
@@ -261,6 +343,58 @@ func TestFromCluster(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "categorical order",
+			in: &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					Parameters: []redskyv1beta1.Parameter{
+						{
+							Name:   "one",
+							Values: []string{"large", "small", "medium", "custom"},
+							ValueInfo: []redskyv1beta1.CategoricalValue{
+								{Value: "small", Ordinal: &smallOrdinal},
+								{Value: "medium", Ordinal: &mediumOrdinal},
+								{Value: "large", Ordinal: &largeOrdinal},
+							},
+						},
+					},
+				},
+			},
+			out: &redskyapi.Experiment{
+				Parameters: []redskyapi.Parameter{
+					{
+						Type:   redskyapi.ParameterTypeCategorical,
+						Name:   "one",
+						Values: []string{"small", "medium", "large", "custom"},
+					},
+				},
+			},
+		},
+		{
+			desc: "boolean",
+			in: &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					Parameters: []redskyv1beta1.Parameter{
+						{Name: "one", Type: redskyv1beta1.ParameterTypeBoolean, Baseline: &one},
+					},
+				},
+			},
+			out: &redskyapi.Experiment{
+				Parameters: []redskyapi.Parameter{
+					{
+						Type:   redskyapi.ParameterTypeCategorical,
+						Name:   "one",
+						Values: []string{"false", "true"},
+					},
+				},
+			},
+			baseline: &redskyapi.TrialAssignments{
+				Labels: map[string]string{"baseline": "true"},
+				Assignments: []redskyapi.Assignment{
+					{ParameterName: "one", Value: numstr.FromString("true")},
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
@@ -274,6 +408,75 @@ func TestFromCluster(t *testing.T) {
 	}
 }
 
+func TestFromClusterNonlinearConstraint(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "cpu", Min: 1, Max: 8},
+				{Name: "replicas", Min: 1, Max: 8},
+			},
+			Constraints: []redskyv1beta1.Constraint{
+				{Name: "capacity", Expression: "cpu * replicas <= 32"},
+			},
+		},
+	}
+
+	_, _, _, err := FromCluster(exp)
+	assert.EqualError(t, err, "constraint 'capacity' expression 'cpu * replicas <= 32' is not linear, only linear expressions can be used with the server")
+}
+
+func TestCheckDependency(t *testing.T) {
+	gc := redskyv1beta1.Parameter{Name: "gc", Values: []string{"G1", "Parallel"}}
+	dependsOn := func(name string, values ...string) *redskyv1beta1.ParameterDependency {
+		return &redskyv1beta1.ParameterDependency{Name: name, Values: values}
+	}
+
+	cases := []struct {
+		desc      string
+		parameter redskyv1beta1.Parameter
+		others    []redskyv1beta1.Parameter
+		err       string
+	}{
+		{
+			desc:      "valid",
+			parameter: redskyv1beta1.Parameter{Name: "region_size", DependsOn: dependsOn("gc", "G1")},
+		},
+		{
+			desc:      "unknown",
+			parameter: redskyv1beta1.Parameter{Name: "region_size", DependsOn: dependsOn("collector", "G1")},
+			err:       "unknown dependency 'collector' for parameter 'region_size'",
+		},
+		{
+			desc:      "not categorical",
+			parameter: redskyv1beta1.Parameter{Name: "region_size", DependsOn: dependsOn("heap", "1")},
+			others:    []redskyv1beta1.Parameter{{Name: "heap", Min: 1, Max: 8}},
+			err:       "dependency 'heap' for parameter 'region_size' must be categorical",
+		},
+		{
+			desc:      "invalid value",
+			parameter: redskyv1beta1.Parameter{Name: "region_size", DependsOn: dependsOn("gc", "CMS")},
+			err:       "invalid value 'CMS' of dependency 'gc' for parameter 'region_size'",
+		},
+		{
+			desc:      "circular",
+			parameter: redskyv1beta1.Parameter{Name: "a", Values: []string{"x"}, DependsOn: dependsOn("b", "x")},
+			others:    []redskyv1beta1.Parameter{{Name: "b", Values: []string{"x"}, DependsOn: dependsOn("a", "x")}},
+			err:       "circular dependency for parameter 'a'",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			params := append([]redskyv1beta1.Parameter{gc, c.parameter}, c.others...)
+			err := checkDependency(params, &c.parameter)
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+		})
+	}
+}
+
 func TestToCluster(t *testing.T) {
 	cases := []struct {
 		desc   string
@@ -327,11 +530,210 @@ func TestToCluster(t *testing.T) {
 	}
 }
 
+func TestToClusterExperiment(t *testing.T) {
+	targetValue := resource.MustParse("100m")
+
+	cases := []struct {
+		desc   string
+		exp    *redskyv1beta1.Experiment
+		ee     *redskyapi.Experiment
+		expOut *redskyv1beta1.Experiment
+	}{
+		{
+			desc: "basic",
+			exp:  &redskyv1beta1.Experiment{},
+			ee: &redskyapi.Experiment{
+				ExperimentMeta: redskyapi.ExperimentMeta{
+					SelfURL:      "self_111",
+					NextTrialURL: "next_trial_111",
+				},
+				Labels: map[string]string{
+					"application": "my-app",
+					"owner":       "jdoe",
+					"env":         "dev",
+				},
+				Optimization: []redskyapi.Optimization{
+					{Name: "experimentBudget", Value: "20"},
+				},
+				Parameters: []redskyapi.Parameter{
+					{
+						Type:   redskyapi.ParameterTypeInteger,
+						Name:   "cpu",
+						Bounds: &redskyapi.Bounds{Min: "1", Max: "8"},
+					},
+					{
+						Type:   redskyapi.ParameterTypeInteger,
+						Name:   "replicas",
+						Bounds: &redskyapi.Bounds{Min: "1", Max: "4"},
+					},
+					{
+						Type:   redskyapi.ParameterTypeCategorical,
+						Name:   "gc",
+						Values: []string{"serial", "parallel"},
+					},
+					{
+						Type:   redskyapi.ParameterTypeCategorical,
+						Name:   "cache",
+						Values: []string{"false", "true"},
+					},
+				},
+				Constraints: []redskyapi.Constraint{
+					{
+						Name:           "budget",
+						ConstraintType: redskyapi.ConstraintSum,
+						SumConstraint: redskyapi.SumConstraint{
+							IsUpperBound: true,
+							Bound:        10,
+							Parameters: []redskyapi.SumConstraintParameter{
+								{Name: "cpu", Weight: 0.5},
+								{Name: "replicas", Weight: 1},
+							},
+						},
+					},
+				},
+				Metrics: []redskyapi.Metric{
+					{Name: "latency", Minimize: true},
+					{Name: "cost", Minimize: true},
+				},
+			},
+			expOut: &redskyv1beta1.Experiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"redskyops.dev/application": "my-app",
+						"env":                       "dev",
+					},
+					Annotations: map[string]string{
+						redskyv1beta1.AnnotationExperimentURL: "self_111",
+						redskyv1beta1.AnnotationNextTrialURL:  "next_trial_111",
+					},
+					Finalizers: []string{
+						Finalizer,
+					},
+				},
+				Spec: redskyv1beta1.ExperimentSpec{
+					Owner: "jdoe",
+					Optimization: []redskyv1beta1.Optimization{
+						{Name: "experimentBudget", Value: "20"},
+					},
+					Parameters: []redskyv1beta1.Parameter{
+						{Name: "cpu", Min: 1, Max: 8},
+						{Name: "replicas", Min: 1, Max: 4},
+						{Name: "gc", Values: []string{"serial", "parallel"}},
+						{Name: "cache", Type: redskyv1beta1.ParameterTypeBoolean},
+					},
+					Constraints: []redskyv1beta1.Constraint{
+						{
+							Name: "budget",
+							Sum: &redskyv1beta1.SumConstraint{
+								IsUpperBound: true,
+								Bound:        *resource.NewMilliQuantity(10000, resource.DecimalSI),
+								Parameters: []redskyv1beta1.SumConstraintParameter{
+									{Name: "cpu", Weight: *resource.NewMilliQuantity(500, resource.DecimalSI)},
+									{Name: "replicas", Weight: *resource.NewMilliQuantity(1000, resource.DecimalSI)},
+								},
+							},
+						},
+					},
+					Metrics: []redskyv1beta1.Metric{
+						{Name: "latency", Minimize: true},
+						{Name: "cost", Minimize: true},
+					},
+				},
+			},
+		},
+		{
+			desc: "existing configuration",
+			exp: &redskyv1beta1.Experiment{
+				Spec: redskyv1beta1.ExperimentSpec{
+					Parameters: []redskyv1beta1.Parameter{
+						{Name: "memory", Min: 128, Max: 4096, Scale: redskyv1beta1.ScaleLog},
+						{Name: "unused", Min: 1, Max: 2},
+					},
+					Metrics: []redskyv1beta1.Metric{
+						{Name: "cost", Query: "{{ cpuRequests . \"\" }}", Type: redskyv1beta1.MetricKubernetes, TargetValue: &targetValue},
+					},
+				},
+			},
+			ee: &redskyapi.Experiment{
+				Parameters: []redskyapi.Parameter{
+					{
+						Type:   redskyapi.ParameterTypeInteger,
+						Name:   "memory",
+						Bounds: &redskyapi.Bounds{Min: "0", Max: "5"},
+					},
+				},
+				Metrics: []redskyapi.Metric{
+					{Name: "cost", Minimize: true},
+				},
+			},
+			expOut: &redskyv1beta1.Experiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						redskyv1beta1.AnnotationExperimentURL: "",
+						redskyv1beta1.AnnotationNextTrialURL:  "",
+					},
+					Finalizers: []string{
+						Finalizer,
+					},
+				},
+				Spec: redskyv1beta1.ExperimentSpec{
+					Parameters: []redskyv1beta1.Parameter{
+						{Name: "memory", Min: 128, Max: 4096, Scale: redskyv1beta1.ScaleLog},
+					},
+					Metrics: []redskyv1beta1.Metric{
+						{Name: "cost", Query: "{{ cpuRequests . \"\" }}", Type: redskyv1beta1.MetricKubernetes, Minimize: true, TargetValue: &targetValue},
+					},
+				},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if assert.NoError(t, ToClusterExperiment(c.exp, c.ee)) {
+				assert.Equal(t, c.expOut, c.exp)
+			}
+		})
+	}
+}
+
+func TestToClusterBaseline(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "cpu", Min: 100, Max: 4000},
+				{Name: "memory", Min: 128, Max: 4096, Scale: redskyv1beta1.ScaleLog},
+				{Name: "gc", Values: []string{"serial", "parallel"}},
+			},
+		},
+	}
+
+	ToClusterBaseline(exp, &redskyapi.TrialAssignments{
+		Labels: map[string]string{"baseline": "true"},
+		Assignments: []redskyapi.Assignment{
+			{ParameterName: "cpu", Value: numstr.FromInt64(500)},
+			{ParameterName: "memory", Value: numstr.FromInt64(2)},
+			{ParameterName: "gc", Value: numstr.FromString("parallel")},
+		},
+	})
+
+	cpu, memory, gc := intstr.FromInt(500), intstr.FromInt(512), intstr.FromString("parallel")
+	assert.Equal(t, &cpu, exp.Spec.Parameters[0].Baseline)
+	assert.Equal(t, &memory, exp.Spec.Parameters[1].Baseline)
+	assert.Equal(t, &gc, exp.Spec.Parameters[2].Baseline)
+
+	// The baseline must survive the round trip back to the server
+	_, _, baseline, err := FromCluster(exp)
+	if assert.NoError(t, err) && assert.NotNil(t, baseline) {
+		assert.Equal(t, numstr.FromInt64(2), baseline.Assignments[1].Value)
+	}
+}
+
 func TestToClusterTrial(t *testing.T) {
 	cases := []struct {
 		desc       string
 		trial      *redskyv1beta1.Trial
 		suggestion *redskyapi.TrialAssignments
+		naming     *redskyv1beta1.TrialNaming
 		trialOut   *redskyv1beta1.Trial
 	}{
 		{
@@ -418,6 +820,36 @@ func TestToClusterTrial(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "hash naming",
+			trial: &redskyv1beta1.Trial{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "generate_name",
+					Annotations:  map[string]string{},
+				},
+			},
+			suggestion: &redskyapi.TrialAssignments{
+				TrialMeta: redskyapi.TrialMeta{
+					SelfURL: "some/path/1",
+				},
+			},
+			naming: &redskyv1beta1.TrialNaming{Suffix: redskyv1beta1.TrialNameHash},
+			trialOut: &redskyv1beta1.Trial{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:         "generate_nameec87a",
+					GenerateName: "generate_name",
+					Annotations: map[string]string{
+						redskyv1beta1.AnnotationReportTrialURL: "some/path/1",
+					},
+					Finalizers: []string{
+						Finalizer,
+					},
+				},
+				Status: redskyv1beta1.TrialStatus{
+					Phase: "Created",
+				},
+			},
+		},
 		{
 			desc: "32bit overflow",
 			trial: &redskyv1beta1.Trial{
@@ -451,7 +883,9 @@ func TestToClusterTrial(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
-			ToClusterTrial(c.trial, c.suggestion)
+			exp := &redskyv1beta1.Experiment{}
+			exp.Spec.TrialNaming = c.naming
+			ToClusterTrial(c.trial, c.suggestion, exp)
 			assert.Equal(t, c.trialOut, c.trial)
 		})
 	}
@@ -597,3 +1031,27 @@ func TestStopExperiment(t *testing.T) {
 		})
 	}
 }
+
+func TestServerSync(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{}
+
+	// The first success always records the condition
+	assert.True(t, SyncSucceeded(exp))
+	assert.False(t, SyncSucceeded(exp))
+	assert.Equal(t, int32(0), exp.Status.ServerSyncFailures)
+
+	// Failures are counted until the next success
+	SyncFailed(exp, "ServerNextTrialFailed", fmt.Errorf("first"))
+	SyncFailed(exp, "ServerNextTrialFailed", fmt.Errorf("second"))
+	assert.Equal(t, int32(2), exp.Status.ServerSyncFailures)
+	if assert.Len(t, exp.Status.Conditions, 1) {
+		assert.Equal(t, redskyv1beta1.ExperimentServerSynced, exp.Status.Conditions[0].Type)
+		assert.Equal(t, corev1.ConditionFalse, exp.Status.Conditions[0].Status)
+		assert.Equal(t, "ServerNextTrialFailed", exp.Status.Conditions[0].Reason)
+		assert.Equal(t, "second", exp.Status.Conditions[0].Message)
+	}
+
+	assert.True(t, SyncSucceeded(exp))
+	assert.Equal(t, int32(0), exp.Status.ServerSyncFailures)
+	assert.Equal(t, corev1.ConditionTrue, exp.Status.Conditions[0].Status)
+}
@@ -18,11 +18,14 @@ package setup
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/meta"
 	"github.com/thestormforge/optimize-controller/internal/template"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -47,11 +50,12 @@ var (
 // ":latest". To address this we always explicitly specify the pull policy corresponding to the image.
 // Finally, when using digests, the default of "IfNotPresent" is acceptable as it is unambiguous.
 
-// NewJob returns a new setup job for either create or delete
-func NewJob(t *redskyv1beta1.Trial, mode string) (*batchv1.Job, error) {
+// NewJob returns a new setup job for either create or delete; the experiment is used to encode the assignments
+// rendered into Helm values and may be nil if it is no longer available
+func NewJob(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, mode string) (*batchv1.Job, error) {
 	job := &batchv1.Job{}
 	job.Namespace = t.Namespace
-	job.Name = fmt.Sprintf("%s-%s", t.Name, mode)
+	job.Name = meta.TruncateName(fmt.Sprintf("%s-%s", t.Name, mode), meta.MaxNameLength)
 	job.Labels = map[string]string{
 		redskyv1beta1.LabelExperiment: t.ExperimentNamespacedName().Name,
 		redskyv1beta1.LabelTrial:      t.Name,
@@ -86,7 +90,7 @@ func NewJob(t *redskyv1beta1.Trial, mode string) (*batchv1.Job, error) {
 			continue
 		}
 		c := corev1.Container{
-			Name:  fmt.Sprintf("%s-%s", job.Name, task.Name),
+			Name:  meta.TruncateName(fmt.Sprintf("%s-%s", job.Name, task.Name), meta.MaxNameLength),
 			Image: task.Image,
 			Args:  task.Args,
 			Env: []corev1.EnvVar{
@@ -128,10 +132,18 @@ func NewJob(t *redskyv1beta1.Trial, mode string) (*batchv1.Job, error) {
 		// Add the configured volume mounts
 		c.VolumeMounts = append(c.VolumeMounts, task.VolumeMounts...)
 
+		// Add the configured compute resources
+		if task.Resources != nil {
+			task.Resources.DeepCopyInto(&c.Resources)
+		}
+
 		// For Helm installs, serialize a Konjure configuration
 		helmConfig := newHelmGeneratorConfig(&task)
 		if helmConfig != nil {
 			te := template.New()
+			if exp != nil {
+				te = te.WithParameters(exp.Spec.Parameters).WithDerived(exp.Spec.Derived)
+			}
 
 			// Helm Values
 			for _, hv := range task.HelmValues {
@@ -207,6 +219,15 @@ func NewJob(t *redskyv1beta1.Trial, mode string) (*batchv1.Job, error) {
 			c.Env = append(c.Env, corev1.EnvVar{Name: "HELM_CONFIG", Value: base64.StdEncoding.EncodeToString(b)})
 		}
 
+		// For the built-in Prometheus, pass the configuration through the environment
+		if task.Prometheus != nil && IsPrometheusSetupTask(&task) {
+			env, err := prometheusEnv(task.Prometheus)
+			if err != nil {
+				return nil, err
+			}
+			c.Env = append(c.Env, env...)
+		}
+
 		job.Spec.Template.Spec.Containers = append(job.Spec.Template.Spec.Containers, c)
 	}
 
@@ -252,3 +273,54 @@ func newHelmGeneratorConfig(task *redskyv1beta1.SetupTask) *helmGeneratorConfig
 
 	return cfg
 }
+
+// defaultPrometheusScrapeTimeout is the scrape timeout used by the built-in Prometheus configuration
+const defaultPrometheusScrapeTimeout = 3 * time.Second
+
+// prometheusEnv returns the environment variables used by the setup tools to configure the built-in Prometheus
+func prometheusEnv(p *redskyv1beta1.PrometheusSetup) ([]corev1.EnvVar, error) {
+	var env []corev1.EnvVar
+
+	if p.ScrapeInterval != nil && p.ScrapeInterval.Duration > 0 {
+		env = append(env, corev1.EnvVar{Name: "PROMETHEUS_SCRAPE_INTERVAL", Value: prometheusDuration(p.ScrapeInterval.Duration)})
+
+		// The scrape timeout cannot exceed the scrape interval
+		if p.ScrapeInterval.Duration < defaultPrometheusScrapeTimeout {
+			env = append(env, corev1.EnvVar{Name: "PROMETHEUS_SCRAPE_TIMEOUT", Value: prometheusDuration(p.ScrapeInterval.Duration)})
+		}
+	}
+
+	if p.Retention != nil && p.Retention.Duration > 0 {
+		env = append(env, corev1.EnvVar{Name: "PROMETHEUS_RETENTION", Value: prometheusDuration(p.Retention.Duration)})
+	}
+
+	if p.Resources != nil {
+		b, err := json.Marshal(p.Resources)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, corev1.EnvVar{Name: "PROMETHEUS_RESOURCES", Value: string(b)})
+	}
+
+	if p.ExtraScrapeConfigs != "" {
+		scrapeConfigs := make([]interface{}, 0)
+		if err := yaml.Unmarshal([]byte(p.ExtraScrapeConfigs), &scrapeConfigs); err != nil {
+			return nil, fmt.Errorf("invalid Prometheus scrape configurations: %w", err)
+		}
+		b, err := yaml.Marshal(scrapeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, corev1.EnvVar{Name: "PROMETHEUS_SCRAPE_CONFIGS", Value: base64.StdEncoding.EncodeToString(b)})
+	}
+
+	return env, nil
+}
+
+// prometheusDuration formats a duration using the units understood by Prometheus
+func prometheusDuration(d time.Duration) string {
+	if d%time.Second != 0 {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%ds", int64(d.Seconds()))
+}
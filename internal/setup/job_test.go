@@ -17,12 +17,16 @@ limitations under the License.
 package setup_test
 
 import (
+	"encoding/base64"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	redsky "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/setup"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -78,11 +82,29 @@ func TestNewJob(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "default with resources",
+			trial: &redsky.Trial{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+				},
+				Spec: redsky.TrialSpec{
+					SetupTasks: []redsky.SetupTask{
+						{
+							Resources: &corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(fmt.Sprintf("%q", tc.desc), func(t *testing.T) {
-			j, err := setup.NewJob(tc.trial, "create")
+			j, err := setup.NewJob(nil, tc.trial, "create")
 			assert.NoError(t, err)
 
 			if len(tc.trial.Spec.SetupTasks) == 0 {
@@ -97,6 +119,112 @@ func TestNewJob(t *testing.T) {
 				assert.Len(t, j.Spec.Template.Spec.Containers[0].Args, len(tc.trial.Spec.SetupTasks[0].Args))
 			}
 
+			if tc.trial.Spec.SetupTasks[0].Resources != nil {
+				assert.Equal(t, *tc.trial.Spec.SetupTasks[0].Resources, j.Spec.Template.Spec.Containers[0].Resources)
+			}
+
 		})
 	}
 }
+
+func TestNewJobPrometheus(t *testing.T) {
+	trial := &redsky.Trial{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: redsky.TrialSpec{
+			SetupTasks: []redsky.SetupTask{
+				{
+					Name: "monitoring",
+					Args: []string{"prometheus", "$(MODE)"},
+					Prometheus: &redsky.PrometheusSetup{
+						ScrapeInterval: &metav1.Duration{Duration: 2 * time.Second},
+						Retention:      &metav1.Duration{Duration: 6 * time.Hour},
+						Resources: &corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+						},
+						ExtraScrapeConfigs: "- job_name: app\n  static_configs:\n  - targets: [\"app:8080\"]\n",
+					},
+				},
+			},
+		},
+	}
+
+	j, err := setup.NewJob(nil, trial, "create")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	env := make(map[string]string)
+	for _, e := range j.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "2s", env["PROMETHEUS_SCRAPE_INTERVAL"])
+	assert.Equal(t, "2s", env["PROMETHEUS_SCRAPE_TIMEOUT"])
+	assert.Equal(t, "21600s", env["PROMETHEUS_RETENTION"])
+	assert.JSONEq(t, `{"limits":{"memory":"2Gi"}}`, env["PROMETHEUS_RESOURCES"])
+	assert.NotEmpty(t, env["PROMETHEUS_SCRAPE_CONFIGS"])
+
+	// Invalid scrape configurations are rejected
+	trial.Spec.SetupTasks[0].Prometheus.ExtraScrapeConfigs = "job_name: app"
+	_, err = setup.NewJob(nil, trial, "create")
+	assert.Error(t, err)
+}
+
+func TestNewJobHelmValues(t *testing.T) {
+	exp := &redsky.Experiment{
+		Spec: redsky.ExperimentSpec{
+			Parameters: []redsky.Parameter{
+				{Name: "timeout", Min: 100, Max: 5000, Encoding: &redsky.ParameterEncoding{Type: redsky.EncodingDuration, Unit: "ms"}},
+			},
+		},
+	}
+	trial := &redsky.Trial{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: redsky.TrialSpec{
+			Assignments: []redsky.Assignment{
+				{Name: "timeout", Value: intstr.FromInt(1500)},
+			},
+			SetupTasks: []redsky.SetupTask{
+				{
+					Name:      "app",
+					HelmChart: "app",
+					HelmValues: []redsky.HelmValue{
+						{Name: "server.timeout", Value: intstr.FromString("{{ .Values.timeout }}")},
+					},
+				},
+			},
+		},
+	}
+
+	j, err := setup.NewJob(exp, trial, "create")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var helmConfig string
+	for _, e := range j.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == "HELM_CONFIG" {
+			helmConfig = e.Value
+		}
+	}
+	b, err := base64.StdEncoding.DecodeString(helmConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cfg := struct {
+		Values []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"values"`
+	}{}
+	if assert.NoError(t, yaml.Unmarshal(b, &cfg)) && assert.Len(t, cfg.Values, 1) {
+		assert.Equal(t, "server.timeout", cfg.Values[0].Name)
+		assert.Equal(t, "1500ms", cfg.Values[0].Value)
+	}
+}
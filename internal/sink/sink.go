@@ -0,0 +1,184 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/trial"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultTimeout is the amount of time to wait for an event to be accepted.
+const DefaultTimeout = 10 * time.Second
+
+// kafkaScheme is the URL scheme prefix used to identify a Kafka REST proxy.
+const kafkaScheme = "kafka+"
+
+// Event is published once for each finished trial.
+type Event struct {
+	// The namespace and name of the experiment.
+	Experiment string `json:"experiment"`
+	// The namespace and name of the trial.
+	Trial string `json:"trial"`
+	// The status of the trial, one of: completed|failed.
+	Status string `json:"status"`
+	// The reason the trial failed.
+	FailureReason string `json:"failureReason,omitempty"`
+	// The message describing why the trial failed.
+	FailureMessage string `json:"failureMessage,omitempty"`
+	// The time the trial started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// The time the trial completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// The parameter assignments of the trial.
+	Assignments []redskyv1beta1.Assignment `json:"assignments,omitempty"`
+	// The collected metric values.
+	Values []redskyv1beta1.Value `json:"values,omitempty"`
+	// The labels of the trial.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NewEvent returns the event for a finished trial. Returns nil if the trial is not finished.
+func NewEvent(t *redskyv1beta1.Trial) *Event {
+	if !trial.IsFinished(t) {
+		return nil
+	}
+
+	e := &Event{
+		Experiment:     t.ExperimentNamespacedName().String(),
+		Trial:          t.Namespace + "/" + t.Name,
+		Status:         "completed",
+		StartTime:      t.Status.StartTime,
+		CompletionTime: t.Status.CompletionTime,
+		Assignments:    t.Spec.Assignments,
+		Values:         t.Spec.Values,
+		Labels:         t.Labels,
+	}
+
+	for _, c := range t.Status.Conditions {
+		if c.Type == redskyv1beta1.TrialFailed && c.Status == corev1.ConditionTrue {
+			e.Status = "failed"
+			e.FailureReason = c.Reason
+			e.FailureMessage = c.Message
+			e.Values = nil
+		}
+	}
+
+	return e
+}
+
+// Publisher sends trial events to an external system.
+type Publisher interface {
+	// Publish sends a single event.
+	Publish(ctx context.Context, e *Event) error
+}
+
+// NewPublisher returns a publisher for the supplied URL. URLs using a "kafka+http" or "kafka+https" scheme are
+// sent to the topic endpoint of a Kafka REST proxy (e.g. "kafka+https://rest-proxy:8082/topics/trials"), all other
+// URLs are treated as a generic webhook that receives each event as a JSON document.
+func NewPublisher(rawURL string) (Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	kafka := strings.HasPrefix(u.Scheme, kafkaScheme)
+	u.Scheme = strings.TrimPrefix(u.Scheme, kafkaScheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported trial sink URL: %s", rawURL)
+	}
+
+	if kafka {
+		return &kafkaPublisher{url: u.String(), client: http.DefaultClient}, nil
+	}
+	return &webhookPublisher{url: u.String(), client: http.DefaultClient}, nil
+}
+
+// webhookPublisher sends each event in the body of a POST request.
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// Publish sends the event to the webhook.
+func (p *webhookPublisher) Publish(ctx context.Context, e *Event) error {
+	return post(ctx, p.client, p.url, "application/json", e)
+}
+
+// kafkaPublisher sends each event as a record to a Kafka REST proxy topic, keyed by the trial.
+type kafkaPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// kafkaRecords is the body of a Kafka REST proxy produce request.
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// kafkaRecord is a single record of a Kafka REST proxy produce request.
+type kafkaRecord struct {
+	Key   string `json:"key,omitempty"`
+	Value *Event `json:"value"`
+}
+
+// Publish sends the event to the Kafka topic.
+func (p *kafkaPublisher) Publish(ctx context.Context, e *Event) error {
+	return post(ctx, p.client, p.url, "application/vnd.kafka.json.v2+json", &kafkaRecords{
+		Records: []kafkaRecord{{Key: e.Trial, Value: e}},
+	})
+}
+
+// post sends the JSON representation of the body to the supplied URL.
+func post(ctx context.Context, client *http.Client, u, contentType string, body interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("trial sink returned unexpected status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestNewEvent(t *testing.T) {
+	tr := &redskyv1beta1.Trial{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example-001"},
+		Spec: redskyv1beta1.TrialSpec{
+			ExperimentRef: &corev1.ObjectReference{Namespace: "default", Name: "example"},
+			Assignments:   []redskyv1beta1.Assignment{{Name: "cpu", Value: intstr.FromInt(100)}},
+			Values:        []redskyv1beta1.Value{{Name: "cost", Value: "1.5"}},
+		},
+	}
+	assert.Nil(t, NewEvent(tr))
+
+	tr.Status.Conditions = []redskyv1beta1.TrialCondition{{Type: redskyv1beta1.TrialComplete, Status: corev1.ConditionTrue}}
+	e := NewEvent(tr)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "default/example", e.Experiment)
+		assert.Equal(t, "default/example-001", e.Trial)
+		assert.Equal(t, "completed", e.Status)
+		assert.Equal(t, tr.Spec.Values, e.Values)
+	}
+
+	tr.Status.Conditions = []redskyv1beta1.TrialCondition{{Type: redskyv1beta1.TrialFailed, Status: corev1.ConditionTrue, Reason: "JobFailed", Message: "boom"}}
+	e = NewEvent(tr)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "failed", e.Status)
+		assert.Equal(t, "JobFailed", e.FailureReason)
+		assert.Empty(t, e.Values)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	var contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		contentType, body = r.Header.Get("Content-Type"), string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	e := &Event{Experiment: "default/example", Trial: "default/example-001", Status: "completed"}
+
+	p, err := NewPublisher(srv.URL + "/hook")
+	require.NoError(t, err)
+	if assert.NoError(t, p.Publish(context.Background(), e)) {
+		assert.Equal(t, "application/json", contentType)
+		assert.JSONEq(t, `{"experiment":"default/example","trial":"default/example-001","status":"completed"}`, body)
+	}
+
+	p, err = NewPublisher(strings.Replace(srv.URL, "http://", "kafka+http://", 1) + "/topics/trials")
+	require.NoError(t, err)
+	if assert.NoError(t, p.Publish(context.Background(), e)) {
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", contentType)
+		assert.JSONEq(t, `{"records":[{"key":"default/example-001","value":{"experiment":"default/example","trial":"default/example-001","status":"completed"}}]}`, body)
+	}
+
+	_, err = NewPublisher("kafka://broker:9092/trials")
+	assert.Error(t, err)
+}
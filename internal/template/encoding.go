@@ -0,0 +1,74 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Encoder converts an assignment value into the representation used by templates
+type Encoder func(value intstr.IntOrString, encoding *redskyv1beta1.ParameterEncoding) (interface{}, error)
+
+// Encoders returns the built-in parameter encoders
+func Encoders() map[redskyv1beta1.ParameterEncodingType]Encoder {
+	return map[redskyv1beta1.ParameterEncodingType]Encoder{
+		redskyv1beta1.EncodingDuration: encodeDuration,
+		redskyv1beta1.EncodingBoolean:  encodeBoolean,
+		redskyv1beta1.EncodingBase64:   encodeBase64,
+	}
+}
+
+// encodeDuration renders a numeric value as a duration, e.g. "1500ms"
+func encodeDuration(value intstr.IntOrString, encoding *redskyv1beta1.ParameterEncoding) (interface{}, error) {
+	if value.Type != intstr.Int {
+		return nil, fmt.Errorf("duration encoding requires a numeric value, got %q", value.StrVal)
+	}
+
+	unit := encoding.Unit
+	if unit == "" {
+		unit = "s"
+	}
+	if _, err := time.ParseDuration("1" + unit); err != nil {
+		return nil, fmt.Errorf("invalid duration unit %q", unit)
+	}
+
+	return strconv.FormatInt(int64(value.IntVal), 10) + unit, nil
+}
+
+// encodeBoolean renders a numeric value as "false" (for zero) or "true", string values must already be a boolean
+func encodeBoolean(value intstr.IntOrString, _ *redskyv1beta1.ParameterEncoding) (interface{}, error) {
+	if value.Type == intstr.Int {
+		return strconv.FormatBool(value.IntVal != 0), nil
+	}
+
+	b, err := strconv.ParseBool(value.StrVal)
+	if err != nil {
+		return nil, fmt.Errorf("boolean encoding requires a boolean value, got %q", value.StrVal)
+	}
+	return strconv.FormatBool(b), nil
+}
+
+// encodeBase64 renders the string representation of a value using standard base64 encoding
+func encodeBase64(value intstr.IntOrString, _ *redskyv1beta1.ParameterEncoding) (interface{}, error) {
+	return base64.StdEncoding.EncodeToString([]byte(value.String())), nil
+}
@@ -23,7 +23,6 @@ import (
 	"text/template"
 	"time"
 
-	"github.com/Masterminds/sprig"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,9 +30,7 @@ import (
 
 // FuncMap returns the functions used for template evaluation
 func FuncMap() template.FuncMap {
-	f := sprig.TxtFuncMap()
-	delete(f, "env")
-	delete(f, "expandenv")
+	f := sprigFuncMap()
 
 	extra := template.FuncMap{
 		"duration":          duration,
@@ -44,6 +41,10 @@ func FuncMap() template.FuncMap {
 		"memoryUtilization": memoryUtilization,
 		"cpuRequests":       cpuRequests,
 		"memoryRequests":    memoryRequests,
+		"addf":              addf,
+		"subf":              subf,
+		"mulf":              mulf,
+		"divf":              divf,
 		"GB":                gb,
 		"MB":                mb,
 		"KB":                kb,
@@ -67,6 +68,26 @@ func duration(start, completion time.Time) float64 {
 	return 0
 }
 
+// addf returns the sum of two floating point numbers
+func addf(a, b float64) float64 {
+	return a + b
+}
+
+// subf returns the difference of two floating point numbers
+func subf(a, b float64) float64 {
+	return a - b
+}
+
+// mulf returns the product of two floating point numbers
+func mulf(a, b float64) float64 {
+	return a * b
+}
+
+// divf returns the quotient of two floating point numbers
+func divf(a, b float64) float64 {
+	return a / b
+}
+
 // percent returns a percentage of an integer value using an integer (0-100) percentage
 func percent(value int32, percent int32) string {
 	return fmt.Sprintf("%d", int64(float64(value)*(float64(percent)/100.0)))
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+)
+
+// sprigFunctions is the audited subset of Sprig functions that can be allowed in templates, indexed by category. Sprig
+// functions which access the environment, the network, or random or cryptographic sources are intentionally excluded.
+var sprigFunctions = map[string][]string{
+	"strings": {
+		"abbrev", "abbrevboth", "camelcase", "cat", "coalesce", "contains", "default", "empty", "hasPrefix",
+		"hasSuffix", "indent", "initials", "join", "kebabcase", "lower", "nindent", "nospace", "plural", "quote",
+		"repeat", "replace", "snakecase", "splitList", "squote", "substr", "swapcase", "title", "toString", "trim",
+		"trimAll", "trimPrefix", "trimSuffix", "trunc", "untitle", "upper", "wrap", "wrapWith",
+	},
+	"math": {
+		"add", "add1", "atoi", "biggest", "ceil", "div", "float64", "floor", "int", "int64", "max", "min", "mod",
+		"mul", "round", "sub",
+	},
+	"dates": {
+		"ago", "date", "dateInZone", "dateModify", "durationRound", "htmlDate", "htmlDateInZone", "now", "toDate",
+		"unixEpoch",
+	},
+}
+
+// allowedSprigFunctions are the names of the Sprig functions currently available to templates, if nil all of the
+// Sprig functions (except those reading the environment) are available
+var allowedSprigFunctions map[string]bool
+
+// AllowSprigFunctions restricts the Sprig functions available to templates. Each entry in the allowlist is either
+// the name of an individual function or a category ("strings", "math" or "dates"); only the audited functions can be
+// allowed. An empty allowlist removes the restriction.
+func AllowSprigFunctions(allowlist []string) error {
+	if len(allowlist) == 0 {
+		allowedSprigFunctions = nil
+		return nil
+	}
+
+	audited := auditedSprigFunctions()
+	allowed := make(map[string]bool, len(allowlist))
+	for _, name := range allowlist {
+		name = strings.TrimSpace(name)
+		if fns, ok := sprigFunctions[name]; ok {
+			for _, fn := range fns {
+				allowed[fn] = true
+			}
+			continue
+		}
+		if !audited[name] {
+			return fmt.Errorf("%q is not an audited template function or category (%s)", name, strings.Join(sprigCategories(), ", "))
+		}
+		allowed[name] = true
+	}
+
+	allowedSprigFunctions = allowed
+	return nil
+}
+
+// sprigFuncMap returns the allowed Sprig functions
+func sprigFuncMap() template.FuncMap {
+	all := sprig.TxtFuncMap()
+	if allowedSprigFunctions == nil {
+		delete(all, "env")
+		delete(all, "expandenv")
+		return all
+	}
+
+	f := make(template.FuncMap, len(allowedSprigFunctions))
+	for name := range allowedSprigFunctions {
+		if fn, ok := all[name]; ok {
+			f[name] = fn
+		}
+	}
+	return f
+}
+
+// auditedSprigFunctions returns the names of all the audited Sprig functions
+func auditedSprigFunctions() map[string]bool {
+	result := make(map[string]bool)
+	for _, fns := range sprigFunctions {
+		for _, fn := range fns {
+			result[fn] = true
+		}
+	}
+	return result
+}
+
+// sprigCategories returns the sorted list of Sprig function categories
+func sprigCategories() []string {
+	result := make([]string, 0, len(sprigFunctions))
+	for c := range sprigFunctions {
+		result = append(result, c)
+	}
+	sort.Strings(result)
+	return result
+}
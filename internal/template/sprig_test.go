@@ -0,0 +1,52 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowSprigFunctions(t *testing.T) {
+	defer func() { _ = AllowSprigFunctions(nil) }()
+
+	// By default all of the Sprig functions except the environment functions are available
+	f := FuncMap()
+	assert.Contains(t, f, "upper")
+	assert.Contains(t, f, "add")
+	assert.Contains(t, f, "now")
+	assert.Contains(t, f, "genPrivateKey")
+	assert.NotContains(t, f, "env")
+	assert.NotContains(t, f, "expandenv")
+	assert.Contains(t, f, "duration")
+
+	// Categories and individual functions can be combined
+	if assert.NoError(t, AllowSprigFunctions([]string{"math", " quote"})) {
+		f = FuncMap()
+		assert.Contains(t, f, "add")
+		assert.Contains(t, f, "quote")
+		assert.NotContains(t, f, "upper")
+		assert.NotContains(t, f, "now")
+		assert.NotContains(t, f, "genPrivateKey")
+		assert.Contains(t, f, "duration")
+	}
+
+	// Functions which have not been audited cannot be allowed
+	assert.Error(t, AllowSprigFunctions([]string{"env"}))
+	assert.Error(t, AllowSprigFunctions([]string{"randAlphaNum"}))
+}
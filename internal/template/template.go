@@ -20,7 +20,10 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"text/template"
+	"text/template/parse"
 	"time"
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
@@ -53,6 +56,8 @@ type MetricData struct {
 	Range string
 	// Trial assignments
 	Values map[string]interface{}
+	// Collected metric values, indexed by metric name
+	Metrics map[string]float64
 }
 
 // Pods returns the metric target if available.
@@ -61,21 +66,17 @@ func (m *MetricData) Pods() runtime.Object {
 	return m.Target
 }
 
-func newPatchData(t *redskyv1beta1.Trial) *PatchData {
+func newPatchData(t *redskyv1beta1.Trial, values func([]redskyv1beta1.Assignment) (map[string]interface{}, error)) (*PatchData, error) {
 	d := &PatchData{}
 
 	t.ObjectMeta.DeepCopyInto(&d.Trial)
 
-	d.Values = make(map[string]interface{}, len(t.Spec.Assignments))
-	for _, a := range t.Spec.Assignments {
-		if a.Value.Type == intstr.String {
-			d.Values[a.Name] = a.Value.StrVal
-		} else {
-			d.Values[a.Name] = a.Value.IntVal
-		}
+	var err error
+	if d.Values, err = values(t.Spec.Assignments); err != nil {
+		return nil, err
 	}
 
-	return d
+	return d, nil
 }
 
 func newMetricData(t *redskyv1beta1.Trial, target runtime.Object) *MetricData {
@@ -101,6 +102,13 @@ func newMetricData(t *redskyv1beta1.Trial, target runtime.Object) *MetricData {
 		d.CompletionTime = t.Status.CompletionTime.Time
 	}
 
+	d.Metrics = make(map[string]float64, len(t.Spec.Values))
+	for _, v := range t.Spec.Values {
+		if value, err := strconv.ParseFloat(v.Value, 64); err == nil && v.Value != "" {
+			d.Metrics[v.Name] = value
+		}
+	}
+
 	d.Range = fmt.Sprintf("%.0fs", math.Max(d.CompletionTime.Sub(d.StartTime).Seconds(), 0))
 
 	return d
@@ -109,13 +117,125 @@ func newMetricData(t *redskyv1beta1.Trial, target runtime.Object) *MetricData {
 // Engine is used to render Go text templates
 type Engine struct {
 	FuncMap template.FuncMap
+	// Encoders are used to convert assignment values for parameters with an encoding
+	Encoders map[redskyv1beta1.ParameterEncodingType]Encoder
+
+	// The names of the known parameters
+	parameters []string
+	// The encodings of the known parameters, indexed by name
+	encodings map[string]*redskyv1beta1.ParameterEncoding
+	// The dependencies of the known parameters, indexed by name
+	dependencies map[string]*redskyv1beta1.ParameterDependency
+	// The values derived from the assignments, in the order they are computed
+	derived []redskyv1beta1.DerivedValue
 }
 
 // New creates a new template engine
 func New() *Engine {
 	return &Engine{
-		FuncMap: FuncMap(),
+		FuncMap:  FuncMap(),
+		Encoders: Encoders(),
+	}
+}
+
+// WithParameters configures the template engine to encode assignment values using the encodings of the supplied
+// parameters and to omit the assignments of inactive parameters; without parameters, assignment values are rendered
+// as is.
+func (e *Engine) WithParameters(params []redskyv1beta1.Parameter) *Engine {
+	e.parameters = make([]string, 0, len(params))
+	e.encodings = make(map[string]*redskyv1beta1.ParameterEncoding, len(params))
+	e.dependencies = make(map[string]*redskyv1beta1.ParameterDependency, len(params))
+	for i := range params {
+		e.parameters = append(e.parameters, params[i].Name)
+		if params[i].Encoding != nil {
+			e.encodings[params[i].Name] = params[i].Encoding
+		}
+		if params[i].DependsOn != nil {
+			e.dependencies[params[i].Name] = params[i].DependsOn
+		}
+	}
+	return e
+}
+
+// WithDerived configures the template engine to compute the supplied derived values from the assignments; the
+// derived values are available to the templates alongside the assignments.
+func (e *Engine) WithDerived(derived []redskyv1beta1.DerivedValue) *Engine {
+	e.derived = derived
+	return e
+}
+
+// values returns the template representation of the supplied assignments
+func (e *Engine) values(assignments []redskyv1beta1.Assignment) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(assignments))
+	for _, a := range assignments {
+		if !e.active(a.Name, assignments) {
+			continue
+		}
+
+		if enc := e.encodings[a.Name]; enc != nil {
+			encoder, ok := e.Encoders[enc.Type]
+			if !ok {
+				return nil, fmt.Errorf("unknown encoding %q for parameter %q", enc.Type, a.Name)
+			}
+			v, err := encoder(a.Value, enc)
+			if err != nil {
+				return nil, fmt.Errorf("unable to encode parameter %q: %w", a.Name, err)
+			}
+			values[a.Name] = v
+		} else if a.Value.Type == intstr.String {
+			values[a.Name] = a.Value.StrVal
+		} else {
+			values[a.Name] = a.Value.IntVal
+		}
+	}
+
+	for _, d := range e.derived {
+		if stringSliceContains(e.parameters, d.Name) {
+			return nil, fmt.Errorf("derived value %q conflicts with a parameter of the same name", d.Name)
+		}
+		b, err := e.render(d.Name, d.Value, &PatchData{Values: values})
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute derived value %q: %w", d.Name, err)
+		}
+		values[d.Name] = derivedValue(strings.TrimSpace(b.String()))
+	}
+	return values, nil
+}
+
+// derivedValue returns the template representation of a rendered derived value, numbers are converted so they can
+// be used in arithmetic without additional conversions.
+func derivedValue(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// active checks to see if the named parameter is active given the supplied assignments. A parameter is active if it
+// has no dependency, or if the parameter it depends on is both active and assigned one of the required values.
+func (e *Engine) active(name string, assignments []redskyv1beta1.Assignment) bool {
+	visited := make(map[string]bool)
+	for dep := e.dependencies[name]; dep != nil; dep = e.dependencies[dep.Name] {
+		if visited[dep.Name] {
+			return false
+		}
+		visited[dep.Name] = true
+
+		matched := false
+		for _, a := range assignments {
+			if a.Name == dep.Name {
+				matched = stringSliceContains(dep.Values, a.Value.String())
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
 	}
+	return true
 }
 
 // TODO Investigate better use of template names
@@ -124,7 +244,10 @@ func New() *Engine {
 
 // RenderPatch returns the JSON representation of the supplied patch template (input can be a Go template that produces YAML)
 func (e *Engine) RenderPatch(patch *redskyv1beta1.PatchTemplate, trial *redskyv1beta1.Trial) ([]byte, error) {
-	data := newPatchData(trial)
+	data, err := newPatchData(trial, e.values)
+	if err != nil {
+		return nil, err
+	}
 	b, err := e.render("patch", patch.Patch, data) // TODO What should we use for patch template names? Something from the targetRef?
 	if err != nil {
 		return nil, err
@@ -132,9 +255,49 @@ func (e *Engine) RenderPatch(patch *redskyv1beta1.PatchTemplate, trial *redskyv1
 	return yaml.ToJSON(b.Bytes())
 }
 
+// PatchParameters returns the names of the parameters referenced by the supplied patch template, including the
+// parameters referenced indirectly through derived values. If the template uses the assignments in a way that does not
+// identify individual parameters (e.g. `{{ range .Values }}`), all of the known parameters are returned.
+func (e *Engine) PatchParameters(patch *redskyv1beta1.PatchTemplate) ([]string, error) {
+	tmpl, err := template.New("patch").Funcs(e.FuncMap).Parse(patch.Patch)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	if tmpl.Tree != nil && !referencedValues(tmpl.Tree.Root, names) {
+		return e.parameters, nil
+	}
+
+	// Derived values can only reference the values before them, expand them in reverse order
+	for i := len(e.derived) - 1; i >= 0; i-- {
+		if !names[e.derived[i].Name] {
+			continue
+		}
+		dt, err := template.New(e.derived[i].Name).Funcs(e.FuncMap).Parse(e.derived[i].Value)
+		if err != nil {
+			return nil, err
+		}
+		if dt.Tree != nil && !referencedValues(dt.Tree.Root, names) {
+			return e.parameters, nil
+		}
+	}
+
+	var result []string
+	for _, name := range e.parameters {
+		if names[name] {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
 // RenderHelmValue returns a rendered string of the supplied Helm value
 func (e *Engine) RenderHelmValue(helmValue *redskyv1beta1.HelmValue, trial *redskyv1beta1.Trial) (string, error) {
-	data := newPatchData(trial)
+	data, err := newPatchData(trial, e.values)
+	if err != nil {
+		return "", err
+	}
 	b, err := e.render(helmValue.Name, helmValue.Value.String(), data)
 	if err != nil {
 		return "", err
@@ -145,19 +308,26 @@ func (e *Engine) RenderHelmValue(helmValue *redskyv1beta1.HelmValue, trial *reds
 // RenderMetricQueries returns the metric query and the metric error query
 func (e *Engine) RenderMetricQueries(metric *redskyv1beta1.Metric, trial *redskyv1beta1.Trial, target runtime.Object) (string, string, error) {
 	data := newMetricData(trial, target)
-	b1, err := e.render(metric.Name, metric.Query, data)
+
+	// Derived metrics must not silently use a zero value in place of a metric value which was not collected
+	var options []string
+	if metric.Type == redskyv1beta1.MetricDerived {
+		options = append(options, "missingkey=error")
+	}
+
+	b1, err := e.render(metric.Name, metric.Query, data, options...)
 	if err != nil {
 		return "", "", err
 	}
-	b2, err := e.render(metric.Name, metric.ErrorQuery, data)
+	b2, err := e.render(metric.Name, metric.ErrorQuery, data, options...)
 	if err != nil {
 		return "", "", err
 	}
 	return b1.String(), b2.String(), nil
 }
 
-func (e *Engine) render(name, text string, data interface{}) (*bytes.Buffer, error) {
-	tmpl, err := template.New(name).Funcs(e.FuncMap).Parse(text)
+func (e *Engine) render(name, text string, data interface{}, options ...string) (*bytes.Buffer, error) {
+	tmpl, err := template.New(name).Funcs(e.FuncMap).Option(options...).Parse(text)
 	if err != nil {
 		return nil, err
 	}
@@ -168,3 +338,85 @@ func (e *Engine) render(name, text string, data interface{}) (*bytes.Buffer, err
 	}
 	return b, nil
 }
+
+// referencedValues collects the names of the assignments referenced from the supplied template node, returns false
+// if the assignments are referenced without a name
+func referencedValues(node parse.Node, names map[string]bool) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, nn := range n.Nodes {
+			if !referencedValues(nn, names) {
+				return false
+			}
+		}
+	case *parse.ActionNode:
+		return referencedValues(n.Pipe, names)
+	case *parse.IfNode:
+		return referencedValues(n.Pipe, names) && referencedValues(n.List, names) && referencedValues(n.ElseList, names)
+	case *parse.RangeNode:
+		return referencedValues(n.Pipe, names) && referencedValues(n.List, names) && referencedValues(n.ElseList, names)
+	case *parse.WithNode:
+		return referencedValues(n.Pipe, names) && referencedValues(n.List, names) && referencedValues(n.ElseList, names)
+	case *parse.TemplateNode:
+		return referencedValues(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return true
+		}
+		for _, cmd := range n.Cmds {
+			if !referencedValues(cmd, names) {
+				return false
+			}
+		}
+	case *parse.CommandNode:
+		// Allow `{{ index .Values "name" }}`
+		if len(n.Args) == 3 {
+			fn, ok1 := n.Args[0].(*parse.IdentifierNode)
+			field, ok2 := n.Args[1].(*parse.FieldNode)
+			name, ok3 := n.Args[2].(*parse.StringNode)
+			if ok1 && ok2 && ok3 && fn.Ident == "index" && len(field.Ident) == 1 && field.Ident[0] == "Values" {
+				names[name.Text] = true
+				return true
+			}
+		}
+		for _, arg := range n.Args {
+			if !referencedValues(arg, names) {
+				return false
+			}
+		}
+	case *parse.ChainNode:
+		return referencedValues(n.Node, names)
+	case *parse.FieldNode:
+		return referencedValue(n.Ident, names)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return referencedValue(n.Ident[1:], names)
+		}
+	}
+	return true
+}
+
+// referencedValue records the name of an assignment from a field reference like `.Values.name`
+func referencedValue(ident []string, names map[string]bool) bool {
+	if len(ident) == 0 || ident[0] != "Values" {
+		return true
+	}
+	if len(ident) == 1 {
+		return false
+	}
+	names[ident[1]] = true
+	return true
+}
+
+// stringSliceContains checks to see if the supplied slice contains the supplied string
+func stringSliceContains(a []string, x string) bool {
+	for _, s := range a {
+		if s == x {
+			return true
+		}
+	}
+	return false
+}
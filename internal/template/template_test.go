@@ -30,10 +30,10 @@ import (
 )
 
 func TestEngine_RenderPatch(t *testing.T) {
-	eng := New()
-
 	cases := []struct {
 		desc          string
+		parameters    []redskyv1beta1.Parameter
+		derived       []redskyv1beta1.DerivedValue
 		patchTemplate redskyv1beta1.PatchTemplate
 		trial         redskyv1beta1.Trial
 		expected      []byte
@@ -69,9 +69,97 @@ func TestEngine_RenderPatch(t *testing.T) {
 			},
 			expected: []byte(`{"spec":{"replicas":2}}`),
 		},
+
+		{
+			desc: "encoded assignments",
+			parameters: []redskyv1beta1.Parameter{
+				{Name: "timeout", Encoding: &redskyv1beta1.ParameterEncoding{Type: redskyv1beta1.EncodingDuration, Unit: "ms"}},
+				{Name: "enabled", Encoding: &redskyv1beta1.ParameterEncoding{Type: redskyv1beta1.EncodingBoolean}},
+				{Name: "secret", Encoding: &redskyv1beta1.ParameterEncoding{Type: redskyv1beta1.EncodingBase64}},
+			},
+			patchTemplate: redskyv1beta1.PatchTemplate{
+				Patch: "data:\n  timeout: {{ .Values.timeout }}\n  enabled: {{ .Values.enabled | quote }}\n  secret: {{ .Values.secret }}\n",
+			},
+			trial: redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{
+					Assignments: []redskyv1beta1.Assignment{
+						{
+							Name:  "timeout",
+							Value: intstr.FromInt(1500),
+						},
+						{
+							Name:  "enabled",
+							Value: intstr.FromInt(1),
+						},
+						{
+							Name:  "secret",
+							Value: intstr.FromInt(42),
+						},
+					},
+				},
+			},
+			expected: []byte(`{"data":{"enabled":"true","secret":"NDI=","timeout":"1500ms"}}`),
+		},
+
+		{
+			desc: "inactive assignments",
+			parameters: []redskyv1beta1.Parameter{
+				{Name: "gc", Values: []string{"G1", "Parallel"}},
+				{Name: "region_size", DependsOn: &redskyv1beta1.ParameterDependency{Name: "gc", Values: []string{"G1"}}},
+				{Name: "threads", DependsOn: &redskyv1beta1.ParameterDependency{Name: "gc", Values: []string{"Parallel"}}},
+			},
+			patchTemplate: redskyv1beta1.PatchTemplate{
+				Patch: "data:\n  gc: {{ .Values.gc }}\n{{- with .Values.region_size }}\n  regionSize: {{ . }}{{ end }}\n{{- with .Values.threads }}\n  threads: {{ . }}{{ end }}\n",
+			},
+			trial: redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{
+					Assignments: []redskyv1beta1.Assignment{
+						{
+							Name:  "gc",
+							Value: intstr.FromString("G1"),
+						},
+						{
+							Name:  "region_size",
+							Value: intstr.FromInt(4),
+						},
+						{
+							Name:  "threads",
+							Value: intstr.FromInt(8),
+						},
+					},
+				},
+			},
+			expected: []byte(`{"data":{"gc":"G1","regionSize":4}}`),
+		},
+
+		{
+			desc: "derived values",
+			parameters: []redskyv1beta1.Parameter{
+				{Name: "memory"},
+			},
+			derived: []redskyv1beta1.DerivedValue{
+				{Name: "maxHeap", Value: "{{ mulf (float64 .Values.memory) 0.8 | floor }}"},
+				{Name: "heapOpts", Value: "-Xmx{{ .Values.maxHeap }}m"},
+			},
+			patchTemplate: redskyv1beta1.PatchTemplate{
+				Patch: "data:\n  maxHeap: {{ .Values.maxHeap }}\n  opts: {{ .Values.heapOpts }}\n",
+			},
+			trial: redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{
+					Assignments: []redskyv1beta1.Assignment{
+						{
+							Name:  "memory",
+							Value: intstr.FromInt(2000),
+						},
+					},
+				},
+			},
+			expected: []byte(`{"data":{"maxHeap":1600,"opts":"-Xmx1600m"}}`),
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
+			eng := New().WithParameters(c.parameters).WithDerived(c.derived)
 			actual, err := eng.RenderPatch(&c.patchTemplate, &c.trial)
 			if assert.NoError(t, err) {
 				assert.Equal(t, string(c.expected), string(actual))
@@ -277,6 +365,24 @@ func TestEngine_RenderMetricQueries(t *testing.T) {
 			expectedQuery: expectedCPURequestsQueryWithParams,
 		},
 
+		{
+			desc: "function cpuRequests with namespaces",
+			metric: redskyv1beta1.Metric{
+				Name:  "testMetric",
+				Query: `{{cpuRequests . "namespace in (backend,frontend),component=bob"}}`,
+			},
+			trial: redskyv1beta1.Trial{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+				},
+				Status: redskyv1beta1.TrialStatus{
+					StartTime:      &metav1.Time{Time: now.Add(-5 * time.Second)},
+					CompletionTime: &now,
+				},
+			},
+			expectedQuery: expectedCPURequestsQueryWithNamespaces,
+		},
+
 		{
 			desc: "function memoryRequests with parameters",
 			metric: redskyv1beta1.Metric{
@@ -313,6 +419,25 @@ func TestEngine_RenderMetricQueries(t *testing.T) {
 			expectedQuery: "1234/1000000000",
 		},
 
+		{
+			desc: "derived metric",
+			metric: redskyv1beta1.Metric{
+				Name:  "cost_per_request",
+				Query: `{{ divf .Metrics.cost .Metrics.requests }}`,
+				Type:  redskyv1beta1.MetricDerived,
+			},
+			trial: redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{
+					Values: []redskyv1beta1.Value{
+						{Name: "cost", Value: "1.5"},
+						{Name: "requests", Value: "300"},
+						{Name: "cost_per_request", AttemptsRemaining: 3},
+					},
+				},
+			},
+			expectedQuery: "0.005",
+		},
+
 		{
 			desc: "function gib",
 			metric: redskyv1beta1.Metric{
@@ -368,6 +493,23 @@ func TestEngine_RenderMetricQueriesFailures(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "derived metric missing value",
+			metric: redskyv1beta1.Metric{
+				Name:  "cost_per_request",
+				Query: `{{ divf .Metrics.cost .Metrics.requests }}`,
+				Type:  redskyv1beta1.MetricDerived,
+			},
+			trial: redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{
+					Values: []redskyv1beta1.Value{
+						{Name: "cost", Value: "1.5"},
+						{Name: "requests", AttemptsRemaining: 0},
+						{Name: "cost_per_request", AttemptsRemaining: 3},
+					},
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
@@ -484,6 +626,16 @@ scalar(
   )
 )`
 
+	expectedCPURequestsQueryWithNamespaces = `
+scalar(
+  sum(
+    avg_over_time(kube_pod_container_resource_requests_cpu_cores[5s])
+    *
+    on (pod) group_left
+    max_over_time(kube_pod_labels{namespace=~"backend|frontend",label_component="bob"}[5s])
+  )
+)`
+
 	expectedMemoryRequestsQuerySanitized = `
 scalar(
   sum(
@@ -112,13 +112,16 @@ func renderUtilization(metricData MetricData, labelSelectors []string, query str
 		return "", err
 	}
 
-	// Always include the trial namespace first
+	// Always include the namespace first, defaulting to the trial namespace
 	requirements, _ := sel.Requirements()
 	labelMatchers := make([]string, 0, 1+len(requirements))
 	labelMatchers = append(labelMatchers, fmt.Sprintf("namespace=%q", metricData.Trial.Namespace))
 	for _, req := range requirements {
-		// Force a "label_" prefix
-		key := strings.TrimPrefix(req.Key(), "label_")
+		// Force a "label_" prefix, except for the namespace which may be used to span multiple namespaces
+		key := "label_" + strings.TrimPrefix(req.Key(), "label_")
+		if req.Key() == "namespace" {
+			key = "namespace"
+		}
 
 		// If we got this far the cardinality will be correct (e.g. only one element for =)
 		value := strings.Join(req.Values().List(), "|")
@@ -144,7 +147,12 @@ func renderUtilization(metricData MetricData, labelSelectors []string, query str
 			return "", fmt.Errorf("unsupported label selector: %s", req.String())
 		}
 
-		labelMatchers = append(labelMatchers, fmt.Sprintf("label_%s%s%q", key, op, value))
+		matcher := fmt.Sprintf("%s%s%q", key, op, value)
+		if key == "namespace" {
+			labelMatchers[0] = matcher
+		} else {
+			labelMatchers = append(labelMatchers, matcher)
+		}
 	}
 
 	// Add the metric selector start and end markers (we know it will be non-empty because of the namespace)
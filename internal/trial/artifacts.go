@@ -0,0 +1,132 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ArtifactsContainerName is the name of the trial run container which uploads the artifacts
+	ArtifactsContainerName = "redsky-artifacts"
+	// artifactsDir is the directory shared between the trial run containers for writing artifacts
+	artifactsDir = "/var/run/redsky/artifacts"
+	// podLogsDir is the directory on the node containing the container logs
+	podLogsDir = "/var/log/pods"
+	// defaultArtifactsImage is the default uploader image
+	defaultArtifactsImage = "minio/mc"
+)
+
+// ArtifactsURL returns the location of the artifacts for the supplied trial, or an empty string if the trial
+// does not upload artifacts.
+func ArtifactsURL(t *redskyv1beta1.Trial) string {
+	if t.Spec.Artifacts == nil || t.Spec.Artifacts.URL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(t.Spec.Artifacts.URL, "/") + "/" + t.Name + "/"
+}
+
+// addArtifactsUploader adds a container to the trial run job that uploads the trial artifacts once the other
+// containers have exited. The containers share a process namespace so the uploader can tell when they are done.
+func addArtifactsUploader(t *redskyv1beta1.Trial, job *batchv1.Job) {
+	// The MinIO client addresses the bucket using the "artifacts" alias instead of the URL scheme
+	a := t.Spec.Artifacts
+	dest := a.URL
+	if pos := strings.Index(dest, "://"); pos >= 0 {
+		dest = dest[pos+3:]
+	}
+	dest = path.Join("artifacts", dest, t.Name)
+
+	pod := &job.Spec.Template.Spec
+	shareProcessNamespace := true
+	pod.ShareProcessNamespace = &shareProcessNamespace
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name:         ArtifactsContainerName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+
+	// Expose the artifacts directory to the trial run containers
+	mount := corev1.VolumeMount{Name: ArtifactsContainerName, MountPath: artifactsDir}
+	for i := range pod.Containers {
+		c := &pod.Containers[i]
+		c.VolumeMounts = append(c.VolumeMounts, mount)
+		c.Env = append(c.Env, corev1.EnvVar{Name: "ARTIFACTS_DIR", Value: artifactsDir})
+	}
+
+	// Wait for the other containers to start and exit, top-level processes of other containers have a parent of 0
+	script := []string{
+		`others() { for s in /proc/[0-9]*/stat; do set -- $(cat "$s" 2>/dev/null); [ "$4" = 0 ] && [ "$1" != 1 ] && [ "$1" != "$$" ] && echo "$1"; done; }`,
+		`n=0; while [ -z "$(others)" ] && [ $n -lt 30 ]; do sleep 1; n=$((n+1)); done`,
+		`while [ -n "$(others)" ]; do sleep 1; done`,
+	}
+
+	paths := a.Paths
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	for _, p := range paths {
+		script = append(script, fmt.Sprintf("mc cp --recursive %s %s", shellQuote(path.Join(artifactsDir, p)), shellQuote(dest+"/")))
+	}
+
+	c := corev1.Container{
+		Name:         ArtifactsContainerName,
+		Image:        a.Image,
+		Command:      []string{"/bin/sh", "-c"},
+		VolumeMounts: []corev1.VolumeMount{mount},
+		EnvFrom: []corev1.EnvFromSource{
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: a.SecretName}}},
+		},
+	}
+	if c.Image == "" {
+		c.Image = defaultArtifactsImage
+	}
+
+	// Container logs are read from the node
+	if len(a.ContainerLogs) > 0 {
+		pod.Volumes = append(pod.Volumes, corev1.Volume{
+			Name:         ArtifactsContainerName + "-logs",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: podLogsDir}},
+		})
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: ArtifactsContainerName + "-logs", MountPath: podLogsDir, ReadOnly: true})
+		c.Env = append(c.Env,
+			corev1.EnvVar{Name: "POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
+			corev1.EnvVar{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+			corev1.EnvVar{Name: "POD_UID", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.uid"}}},
+		)
+		for _, name := range a.ContainerLogs {
+			src := podLogsDir + `/${POD_NAMESPACE}_${POD_NAME}_${POD_UID}/` + name + "/"
+			script = append(script, fmt.Sprintf("mc cp --recursive \"%s\" %s", src, shellQuote(dest+"/logs/"+name+"/")))
+		}
+	}
+
+	// Failing to upload the artifacts should not fail the trial
+	script = append(script, "exit 0")
+	c.Args = []string{strings.Join(script, "\n")}
+
+	pod.Containers = append(pod.Containers, c)
+}
+
+// shellQuote returns a single quoted shell string
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
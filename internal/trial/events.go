@@ -0,0 +1,116 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"sort"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EventCollectionPeriod is the amount of time after a trial fails that related events are collected
+	EventCollectionPeriod = 5 * time.Minute
+	// MaxEvents is the maximum number of events recorded on a trial
+	MaxEvents = 20
+)
+
+// relevantEventReasons are the reasons of normal (i.e. non-warning) events that may explain a trial failure
+var relevantEventReasons = map[string]bool{
+	"ScalingReplicaSet":         true,
+	"SuccessfulRescale":         true,
+	"TriggeredScaleUp":          true,
+	"ScaleDown":                 true,
+	"Preempted":                 true,
+	"Killing":                   true,
+	"NodeNotReady":              true,
+	"NodeHasDiskPressure":       true,
+	"NodeHasInsufficientMemory": true,
+	"NodeHasInsufficientPID":    true,
+	"RemovingNode":              true,
+}
+
+// NeedsEvents checks to see if cluster events should be collected for the specified trial
+func NeedsEvents(t *redskyv1beta1.Trial, now time.Time) bool {
+	if len(t.Status.Events) > 0 {
+		return false
+	}
+
+	for _, c := range t.Status.Conditions {
+		if c.Type == redskyv1beta1.TrialFailed && c.Status == corev1.ConditionTrue {
+			return now.Before(c.LastTransitionTime.Add(EventCollectionPeriod))
+		}
+	}
+
+	return false
+}
+
+// FailureEvents returns a summary of the supplied events which may be related to the failure of the trial: warnings
+// or events describing environmental changes (such as node pressure, evictions or scaling activity) that occurred
+// between the creation of the trial and shortly after it failed.
+func FailureEvents(t *redskyv1beta1.Trial, events []corev1.Event) []redskyv1beta1.TrialEvent {
+	start, end := t.CreationTimestamp.Time, time.Time{}
+	for _, c := range t.Status.Conditions {
+		if c.Type == redskyv1beta1.TrialFailed && c.Status == corev1.ConditionTrue {
+			end = c.LastTransitionTime.Add(time.Minute)
+		}
+	}
+
+	var result []redskyv1beta1.TrialEvent
+	for i := range events {
+		e := &events[i]
+		if e.Type != corev1.EventTypeWarning && !relevantEventReasons[e.Reason] {
+			continue
+		}
+
+		ts := eventTime(e)
+		if ts.Before(start) || (!end.IsZero() && ts.After(end)) {
+			continue
+		}
+
+		result = append(result, redskyv1beta1.TrialEvent{
+			Type:           e.Type,
+			Reason:         e.Reason,
+			Message:        e.Message,
+			InvolvedObject: e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name,
+			Count:          e.Count,
+			LastTimestamp:  metav1.NewTime(ts),
+		})
+	}
+
+	// Keep only the most recent events
+	sort.SliceStable(result, func(i, j int) bool { return result[i].LastTimestamp.Before(&result[j].LastTimestamp) })
+	if len(result) > MaxEvents {
+		result = result[len(result)-MaxEvents:]
+	}
+	return result
+}
+
+// eventTime returns the most recent time an event occurred
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.FirstTimestamp.Time
+	}
+}
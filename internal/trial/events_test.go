@@ -0,0 +1,74 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFailureEvents(t *testing.T) {
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	failed := created.Add(10 * time.Minute)
+
+	tt := &redskyv1beta1.Trial{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Status: redskyv1beta1.TrialStatus{
+			Conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:               redskyv1beta1.TrialFailed,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(failed),
+				},
+			},
+		},
+	}
+
+	newEvent := func(eventType, reason string, ts time.Time) corev1.Event {
+		return corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "app"},
+			Type:           eventType,
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(ts),
+		}
+	}
+
+	events := []corev1.Event{
+		newEvent(corev1.EventTypeWarning, "Evicted", created.Add(5*time.Minute)),
+		newEvent(corev1.EventTypeNormal, "Pulled", created.Add(2*time.Minute)),
+		newEvent(corev1.EventTypeNormal, "ScalingReplicaSet", created.Add(3*time.Minute)),
+		newEvent(corev1.EventTypeWarning, "BackOff", created.Add(-time.Minute)),
+		newEvent(corev1.EventTypeWarning, "FailedScheduling", failed.Add(time.Hour)),
+	}
+
+	actual := FailureEvents(tt, events)
+	if assert.Len(t, actual, 2) {
+		assert.Equal(t, "ScalingReplicaSet", actual[0].Reason)
+		assert.Equal(t, "Evicted", actual[1].Reason)
+		assert.Equal(t, "Pod/app", actual[1].InvolvedObject)
+	}
+
+	assert.True(t, NeedsEvents(tt, failed.Add(time.Minute)))
+	assert.False(t, NeedsEvents(tt, failed.Add(time.Hour)))
+	tt.Status.Events = actual
+	assert.False(t, NeedsEvents(tt, failed.Add(time.Minute)))
+}
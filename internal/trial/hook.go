@@ -0,0 +1,184 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"fmt"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/meta"
+	"github.com/thestormforge/optimize-controller/internal/setup"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultHookRetries is the number of additional hook jobs to run when using the retry failure policy
+const defaultHookRetries = 3
+
+// HookConditionType returns the trial condition type used to report the status of the hooks for the specified phase
+func HookConditionType(phase redskyv1beta1.TrialHookPhase) redskyv1beta1.TrialConditionType {
+	if phase == redskyv1beta1.TrialHookPostTrial {
+		return redskyv1beta1.TrialPostHooksComplete
+	}
+	return redskyv1beta1.TrialPreHooksComplete
+}
+
+// HooksPending checks to see if the supplied trial has hooks for the specified phase that have not finished
+func HooksPending(t *redskyv1beta1.Trial, phase redskyv1beta1.TrialHookPhase) bool {
+	for i := range t.Spec.Hooks {
+		if t.Spec.Hooks[i].Phase == phase {
+			return !CheckCondition(&t.Status, HookConditionType(phase), corev1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// HookProgress describes the state of the hooks for a single phase of a trial
+type HookProgress struct {
+	// Next is the hook job that needs to be created
+	Next *batchv1.Job
+	// Active indicates that a hook job is still running
+	Active bool
+	// Failure is a message describing the hook job that failed
+	Failure string
+}
+
+// Finished checks to see if all of the hooks have finished successfully
+func (p *HookProgress) Finished() bool {
+	return p.Next == nil && !p.Active && p.Failure == ""
+}
+
+// CheckHooks inspects the supplied hook jobs to determine the progress of the hooks for the specified phase. Hooks
+// are run one at a time, a hook does not start until the hook before it has completed successfully.
+func CheckHooks(t *redskyv1beta1.Trial, phase redskyv1beta1.TrialHookPhase, jobList *batchv1.JobList) *HookProgress {
+	p := &HookProgress{}
+	for i := range t.Spec.Hooks {
+		h := &t.Spec.Hooks[i]
+		if h.Phase != phase {
+			continue
+		}
+
+		// Find the most recent attempt at running the hook
+		var attempts int
+		var job *batchv1.Job
+		for j := range jobList.Items {
+			if jobList.Items[j].Labels[redskyv1beta1.LabelTrialHook] == h.Name {
+				attempts++
+			}
+		}
+		for j := range jobList.Items {
+			if jobList.Items[j].Name == hookJobName(t, h, attempts-1) {
+				job = &jobList.Items[j]
+			}
+		}
+
+		if job == nil {
+			p.Next = NewHookJob(t, h, attempts)
+			return p
+		}
+
+		complete, failed := hookJobStatus(job)
+		switch {
+		case complete:
+			continue
+		case failed && h.FailurePolicy == redskyv1beta1.TrialHookRetry && attempts <= hookRetries(h):
+			p.Next = NewHookJob(t, h, attempts)
+		case failed:
+			p.Failure = fmt.Sprintf("Hook %q did not complete successfully after %d attempt(s)", h.Name, attempts)
+		default:
+			p.Active = true
+		}
+		return p
+	}
+	return p
+}
+
+// NewHookJob returns a new job for an attempt at running the supplied hook
+func NewHookJob(t *redskyv1beta1.Trial, h *redskyv1beta1.TrialHook, attempt int) *batchv1.Job {
+	job := &batchv1.Job{}
+
+	// Start with the job template
+	h.JobTemplate.ObjectMeta.DeepCopyInto(&job.ObjectMeta)
+	h.JobTemplate.Spec.DeepCopyInto(&job.Spec)
+
+	// Apply labels to the job itself
+	meta.AddLabel(job, redskyv1beta1.LabelExperiment, t.ExperimentNamespacedName().Name)
+	meta.AddLabel(job, redskyv1beta1.LabelTrial, t.Name)
+	meta.AddLabel(job, redskyv1beta1.LabelTrialRole, "trialHook")
+	meta.AddLabel(job, redskyv1beta1.LabelTrialHook, h.Name)
+
+	// Apply labels to the pod template
+	meta.AddLabel(&job.Spec.Template, redskyv1beta1.LabelExperiment, t.ExperimentNamespacedName().Name)
+	meta.AddLabel(&job.Spec.Template, redskyv1beta1.LabelTrial, t.Name)
+	meta.AddLabel(&job.Spec.Template, redskyv1beta1.LabelTrialRole, "trialHook")
+	meta.AddLabel(&job.Spec.Template, redskyv1beta1.LabelTrialHook, h.Name)
+
+	// The name is used to identify the attempt so it cannot come from the template
+	job.Namespace = t.Namespace
+	job.Name = hookJobName(t, h, attempt)
+	job.GenerateName = ""
+
+	// The default restart policy for a pod is not acceptable in the context of a job
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	// Retries are handled by running a new job so each attempt is only run once
+	if job.Spec.BackoffLimit == nil {
+		job.Spec.BackoffLimit = new(int32)
+	}
+
+	// Expose the current assignments as environment variables to every container
+	for i := range job.Spec.Template.Spec.Containers {
+		c := &job.Spec.Template.Spec.Containers[i]
+		c.Env = setup.AppendAssignmentEnv(t, c.Env)
+	}
+
+	return job
+}
+
+// hookJobName returns the name of the job for an attempt at running the supplied hook
+func hookJobName(t *redskyv1beta1.Trial, h *redskyv1beta1.TrialHook, attempt int) string {
+	if attempt > 0 {
+		return meta.TruncateName(fmt.Sprintf("%s-%s-%d", t.Name, h.Name, attempt), meta.MaxNameLength)
+	}
+	return meta.TruncateName(fmt.Sprintf("%s-%s", t.Name, h.Name), meta.MaxNameLength)
+}
+
+// hookRetries returns the number of additional attempts allowed for the supplied hook
+func hookRetries(h *redskyv1beta1.TrialHook) int {
+	if h.Retries != nil {
+		return int(*h.Retries)
+	}
+	return defaultHookRetries
+}
+
+// hookJobStatus checks to see if the supplied hook job is complete or failed
+func hookJobStatus(job *batchv1.Job) (bool, bool) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, false
+		case batchv1.JobFailed:
+			return false, true
+		}
+	}
+	return false, false
+}
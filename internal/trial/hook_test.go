@@ -0,0 +1,121 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckHooks(t *testing.T) {
+	retries := int32(1)
+	tt := &redskyv1beta1.Trial{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: redskyv1beta1.TrialSpec{
+			Hooks: []redskyv1beta1.TrialHook{
+				{Name: "reload", Phase: redskyv1beta1.TrialHookPreTrial, FailurePolicy: redskyv1beta1.TrialHookRetry, Retries: &retries},
+				{Name: "warm", Phase: redskyv1beta1.TrialHookPreTrial},
+				{Name: "cleanup", Phase: redskyv1beta1.TrialHookPostTrial},
+			},
+		},
+	}
+
+	job := func(name, hook string, conditionType batchv1.JobConditionType) batchv1.Job {
+		j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{redskyv1beta1.LabelTrialHook: hook}}}
+		if conditionType != "" {
+			j.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+		}
+		return j
+	}
+
+	cases := []struct {
+		desc     string
+		phase    redskyv1beta1.TrialHookPhase
+		jobs     []batchv1.Job
+		next     string
+		active   bool
+		failure  bool
+		finished bool
+	}{
+		{
+			desc:  "first hook",
+			phase: redskyv1beta1.TrialHookPreTrial,
+			next:  "test-reload",
+		},
+		{
+			desc:   "first hook running",
+			phase:  redskyv1beta1.TrialHookPreTrial,
+			jobs:   []batchv1.Job{job("test-reload", "reload", "")},
+			active: true,
+		},
+		{
+			desc:  "retry",
+			phase: redskyv1beta1.TrialHookPreTrial,
+			jobs:  []batchv1.Job{job("test-reload", "reload", batchv1.JobFailed)},
+			next:  "test-reload-1",
+		},
+		{
+			desc:  "second hook",
+			phase: redskyv1beta1.TrialHookPreTrial,
+			jobs: []batchv1.Job{
+				job("test-reload", "reload", batchv1.JobFailed),
+				job("test-reload-1", "reload", batchv1.JobComplete),
+			},
+			next: "test-warm",
+		},
+		{
+			desc:  "retries exhausted",
+			phase: redskyv1beta1.TrialHookPreTrial,
+			jobs: []batchv1.Job{
+				job("test-reload", "reload", batchv1.JobFailed),
+				job("test-reload-1", "reload", batchv1.JobFailed),
+			},
+			failure: true,
+		},
+		{
+			desc:    "no retry",
+			phase:   redskyv1beta1.TrialHookPostTrial,
+			jobs:    []batchv1.Job{job("test-cleanup", "cleanup", batchv1.JobFailed)},
+			failure: true,
+		},
+		{
+			desc:     "finished",
+			phase:    redskyv1beta1.TrialHookPostTrial,
+			jobs:     []batchv1.Job{job("test-cleanup", "cleanup", batchv1.JobComplete)},
+			finished: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p := CheckHooks(tt, c.phase, &batchv1.JobList{Items: c.jobs})
+			if c.next != "" && assert.NotNil(t, p.Next) {
+				assert.Equal(t, c.next, p.Next.Name)
+				assert.Equal(t, "trialHook", p.Next.Labels[redskyv1beta1.LabelTrialRole])
+			} else {
+				assert.Nil(t, p.Next)
+			}
+			assert.Equal(t, c.active, p.Active)
+			assert.Equal(t, c.failure, p.Failure != "")
+			assert.Equal(t, c.finished, p.Finished())
+		})
+	}
+}
@@ -79,6 +79,11 @@ func NewJob(t *redskyv1beta1.Trial) *batchv1.Job {
 		addDefaultContainer(t, job)
 	}
 
+	// Upload artifacts once the trial run is done
+	if t.Spec.Artifacts != nil {
+		addArtifactsUploader(t, job)
+	}
+
 	// Check to see if there is patch for the (as of yet, non-existent) trial job
 	job = patchSelf(t, job)
 
@@ -94,6 +99,9 @@ func addDefaultContainer(t *redskyv1beta1.Trial, job *batchv1.Job) {
 	if t.Spec.StartTimeOffset != nil {
 		s = &metav1.Duration{Duration: s.Duration + t.Spec.StartTimeOffset.Duration}
 	}
+	if t.Spec.WarmupDuration != nil {
+		s = &metav1.Duration{Duration: s.Duration + t.Spec.WarmupDuration.Duration}
+	}
 
 	// Add a busybox container that just runs sleep
 	job.Spec.Template.Spec.Containers = []corev1.Container{
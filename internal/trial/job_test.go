@@ -99,3 +99,38 @@ func TestNewJob(t *testing.T) {
 		})
 	}
 }
+
+func TestNewJobArtifacts(t *testing.T) {
+	trial := &redskyv1beta1.Trial{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-trial",
+			Namespace: "default",
+		},
+		Spec: redskyv1beta1.TrialSpec{
+			Artifacts: &redskyv1beta1.TrialArtifacts{
+				URL:           "s3://my-bucket/load-tests/",
+				SecretName:    "artifacts-credentials",
+				Paths:         []string{"report.html"},
+				ContainerLogs: []string{"default-trial-run"},
+			},
+		},
+	}
+
+	assert.Equal(t, "s3://my-bucket/load-tests/my-trial/", ArtifactsURL(trial))
+
+	job := NewJob(trial)
+	pod := &job.Spec.Template.Spec
+	if assert.Len(t, pod.Containers, 2) {
+		assert.Contains(t, pod.Containers[0].Env, corev1.EnvVar{Name: "ARTIFACTS_DIR", Value: artifactsDir})
+
+		uploader := &pod.Containers[1]
+		assert.Equal(t, ArtifactsContainerName, uploader.Name)
+		assert.Equal(t, defaultArtifactsImage, uploader.Image)
+		assert.Contains(t, uploader.Args[0], `mc cp --recursive '/var/run/redsky/artifacts/report.html' 'artifacts/my-bucket/load-tests/my-trial/'`)
+		assert.Contains(t, uploader.Args[0], `mc cp --recursive "/var/log/pods/${POD_NAMESPACE}_${POD_NAME}_${POD_UID}/default-trial-run/" 'artifacts/my-bucket/load-tests/my-trial/logs/default-trial-run/'`)
+	}
+	if assert.NotNil(t, pod.ShareProcessNamespace) {
+		assert.True(t, *pod.ShareProcessNamespace)
+	}
+	assert.Len(t, pod.Volumes, 2)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"strings"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PreemptionGracePeriod is the minimum amount of time between preempting trials, this gives the
+// resources of a preempted trial a chance to be released before another trial is preempted.
+const PreemptionGracePeriod = time.Minute
+
+// IsPreempted checks to see if the specified trial run has been preempted
+func IsPreempted(t *redskyv1beta1.Trial) bool {
+	return CheckCondition(&t.Status, redskyv1beta1.TrialPreempted, corev1.ConditionTrue)
+}
+
+// PreemptingPod returns the first pod which can preempt the specified trial: the pod must use one of
+// the priority classes from the trial preemption policy and be unschedulable due to insufficient resources.
+func PreemptingPod(t *redskyv1beta1.Trial, podList *corev1.PodList) *corev1.Pod {
+	if t.Spec.Preemption == nil {
+		return nil
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodPending || !hasPriorityClass(t, pod.Spec.PriorityClassName) {
+			continue
+		}
+
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse &&
+				c.Reason == corev1.PodReasonUnschedulable && strings.Contains(c.Message, "Insufficient") {
+				return pod
+			}
+		}
+	}
+
+	return nil
+}
+
+// PreemptionCandidate returns the running trial which should be preempted to make room for the supplied pod,
+// the result is nil if no trial can be preempted or if a trial was preempted within the grace period.
+func PreemptionCandidate(trialList *redskyv1beta1.TrialList, pod *corev1.Pod, now time.Time) *redskyv1beta1.Trial {
+	var candidate *redskyv1beta1.Trial
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if !hasPriorityClass(t, pod.Spec.PriorityClassName) {
+			continue
+		}
+
+		// Do not preempt anything else while a recently preempted trial releases its resources
+		for _, c := range t.Status.Conditions {
+			if c.Type == redskyv1beta1.TrialPreempted && c.Status == corev1.ConditionTrue &&
+				c.LastTransitionTime.Add(PreemptionGracePeriod).After(now) {
+				return nil
+			}
+		}
+
+		// Only running trials can be preempted
+		if t.Status.StartTime == nil || t.Status.CompletionTime != nil || IsFinished(t) || IsPreempted(t) || !t.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if candidate == nil || preemptBefore(t, candidate) {
+			candidate = t
+		}
+	}
+	return candidate
+}
+
+// preemptBefore checks to see if trial a should be preempted before trial b: lower priority trials are
+// preempted first, followed by the trial that has been running for the least amount of time.
+func preemptBefore(a, b *redskyv1beta1.Trial) bool {
+	if a.Spec.Preemption.Priority != b.Spec.Preemption.Priority {
+		return a.Spec.Preemption.Priority < b.Spec.Preemption.Priority
+	}
+	if !a.Status.StartTime.Equal(b.Status.StartTime) {
+		return b.Status.StartTime.Before(a.Status.StartTime)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
+
+func hasPriorityClass(t *redskyv1beta1.Trial, priorityClassName string) bool {
+	if t.Spec.Preemption == nil || priorityClassName == "" {
+		return false
+	}
+	for _, name := range t.Spec.Preemption.PriorityClassNames {
+		if name == priorityClassName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreemptingPod(t *testing.T) {
+	preemption := &redskyv1beta1.TrialPreemption{PriorityClassNames: []string{"critical"}}
+	unschedulable := corev1.PodCondition{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+	}
+
+	cases := []struct {
+		desc       string
+		preemption *redskyv1beta1.TrialPreemption
+		pod        corev1.Pod
+		expected   bool
+	}{
+		{
+			desc:       "insufficient resources",
+			preemption: preemption,
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{PriorityClassName: "critical"},
+				Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{unschedulable}},
+			},
+			expected: true,
+		},
+		{
+			desc: "no preemption",
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{PriorityClassName: "critical"},
+				Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{unschedulable}},
+			},
+		},
+		{
+			desc:       "other priority class",
+			preemption: preemption,
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{PriorityClassName: "batch"},
+				Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{unschedulable}},
+			},
+		},
+		{
+			desc:       "running",
+			preemption: preemption,
+			pod: corev1.Pod{
+				Spec:   corev1.PodSpec{PriorityClassName: "critical"},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+		},
+		{
+			desc:       "node selector",
+			preemption: preemption,
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{PriorityClassName: "critical"},
+				Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available: 3 node(s) didn't match node selector.",
+				}}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tt := &redskyv1beta1.Trial{Spec: redskyv1beta1.TrialSpec{Preemption: c.preemption}}
+			pod := PreemptingPod(tt, &corev1.PodList{Items: []corev1.Pod{c.pod}})
+			assert.Equal(t, c.expected, pod != nil)
+		})
+	}
+}
+
+func TestPreemptionCandidate(t *testing.T) {
+	now := time.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	later := metav1.NewTime(now.Add(-time.Minute))
+	pod := &corev1.Pod{Spec: corev1.PodSpec{PriorityClassName: "critical"}}
+
+	newTrial := func(name string, priority int32, startTime *metav1.Time, conditions ...redskyv1beta1.TrialCondition) redskyv1beta1.Trial {
+		return redskyv1beta1.Trial{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: redskyv1beta1.TrialSpec{Preemption: &redskyv1beta1.TrialPreemption{
+				PriorityClassNames: []string{"critical"},
+				Priority:           priority,
+			}},
+			Status: redskyv1beta1.TrialStatus{StartTime: startTime, Conditions: conditions},
+		}
+	}
+
+	cases := []struct {
+		desc     string
+		trials   []redskyv1beta1.Trial
+		expected string
+	}{
+		{
+			desc:   "no running trials",
+			trials: []redskyv1beta1.Trial{newTrial("a", 0, nil)},
+		},
+		{
+			desc:     "lowest priority",
+			trials:   []redskyv1beta1.Trial{newTrial("a", 1, &later), newTrial("b", 0, &earlier)},
+			expected: "b",
+		},
+		{
+			desc:     "most recently started",
+			trials:   []redskyv1beta1.Trial{newTrial("a", 0, &earlier), newTrial("b", 0, &later)},
+			expected: "b",
+		},
+		{
+			desc: "recently preempted",
+			trials: []redskyv1beta1.Trial{
+				newTrial("a", 0, &earlier),
+				newTrial("b", 0, nil, redskyv1beta1.TrialCondition{
+					Type:               redskyv1beta1.TrialPreempted,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: later,
+				}),
+			},
+		},
+		{
+			desc: "previously preempted",
+			trials: []redskyv1beta1.Trial{
+				newTrial("a", 0, &earlier),
+				newTrial("b", 0, nil, redskyv1beta1.TrialCondition{
+					Type:               redskyv1beta1.TrialPreempted,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: earlier,
+				}),
+			},
+			expected: "a",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual := PreemptionCandidate(&redskyv1beta1.TrialList{Items: c.trials}, pod, now)
+			if c.expected == "" {
+				assert.Nil(t, actual)
+			} else if assert.NotNil(t, actual) {
+				assert.Equal(t, c.expected, actual.Name)
+			}
+		})
+	}
+}
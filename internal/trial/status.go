@@ -17,8 +17,10 @@ limitations under the License.
 package trial
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
@@ -26,32 +28,42 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// TODO Make the constant names better reflect the code, not the text
-// TODO Use a prefix, like "phase"?
 const (
-	created      = "Created"
-	setupCreated = "Setup Created"
-	settingUp    = "Setting up"
-	setupDeleted = "Setup Deleted"
-	tearingDown  = "Tearing Down"
-	patched      = "Patched"
-	patching     = "Patching"
-	running      = "Running"
-	stabilized   = "Stabilized"
-	waiting      = "Waiting"
-	captured     = "Captured"
-	capturing    = "Capturing"
-	completed    = "Completed"
-	failed       = "Failed"
+	// PhaseCreated indicates that the trial has been created but no work has started
+	PhaseCreated = "Created"
+	// PhaseSettingUp indicates that the trial setup tasks are being created
+	PhaseSettingUp = "SettingUp"
+	// PhasePatching indicates that the trial patches are being applied
+	PhasePatching = "Patching"
+	// PhaseStabilizing indicates that the trial is waiting for the patched resources to become ready
+	PhaseStabilizing = "Stabilizing"
+	// PhaseRunningHooks indicates that the trial hook jobs are running
+	PhaseRunningHooks = "RunningHooks"
+	// PhaseRunning indicates that the trial run job has started
+	PhaseRunning = "Running"
+	// PhasePreempted indicates that the trial run job was aborted and will be retried later
+	PhasePreempted = "Preempted"
+	// PhaseMeasuring indicates that the trial run job has finished and metric values are being collected
+	PhaseMeasuring = "Measuring"
+	// PhaseTearingDown indicates that the trial has been measured and is being cleaned up, e.g. setup tasks are being deleted
+	PhaseTearingDown = "TearingDown"
+	// PhaseFinished indicates that the trial has completed successfully
+	PhaseFinished = "Finished"
+	// PhaseFailed indicates that the trial has failed
+	PhaseFailed = "Failed"
 )
 
 var (
+	// trialConditionTypeOrder is the order in which conditions are expected to be added over the life of a trial
 	trialConditionTypeOrder = []redskyv1beta1.TrialConditionType{
 		redskyv1beta1.TrialSetupCreated,
-		redskyv1beta1.TrialSetupDeleted,
 		redskyv1beta1.TrialPatched,
 		redskyv1beta1.TrialReady,
+		redskyv1beta1.TrialPreHooksComplete,
+		redskyv1beta1.TrialPreempted,
 		redskyv1beta1.TrialObserved,
+		redskyv1beta1.TrialPostHooksComplete,
+		redskyv1beta1.TrialSetupDeleted,
 		redskyv1beta1.TrialComplete,
 		redskyv1beta1.TrialFailed,
 	}
@@ -82,7 +94,7 @@ func UpdateStatus(t *redskyv1beta1.Trial) bool {
 func summarize(t *redskyv1beta1.Trial) string {
 	// If there is an initializer we are in the "setting up" phase
 	if t.HasInitializer() {
-		return settingUp
+		return PhaseSettingUp
 	}
 
 	// TODO Re-implement this so it doesn't use conditions, otherwise the conditions need to be ordered
@@ -99,7 +111,7 @@ func summarize(t *redskyv1beta1.Trial) string {
 		return false
 	})
 
-	phase := created
+	phase := PhaseCreated
 	for i := range t.Status.Conditions {
 		c := t.Status.Conditions[i]
 		switch c.Type {
@@ -107,65 +119,69 @@ func summarize(t *redskyv1beta1.Trial) string {
 		case redskyv1beta1.TrialSetupCreated:
 			switch c.Status {
 			case corev1.ConditionTrue:
-				phase = setupCreated
-			case corev1.ConditionFalse:
-				phase = settingUp
-			case corev1.ConditionUnknown:
-				phase = settingUp
-			}
-
-		case redskyv1beta1.TrialSetupDeleted:
-			switch c.Status {
-			case corev1.ConditionTrue:
-				phase = setupDeleted
-			case corev1.ConditionFalse:
-				phase = tearingDown
+				phase = PhasePatching
+			case corev1.ConditionFalse, corev1.ConditionUnknown:
+				phase = PhaseSettingUp
 			}
 
 		case redskyv1beta1.TrialPatched:
 			switch c.Status {
 			case corev1.ConditionTrue:
-				phase = patched
-			case corev1.ConditionFalse:
-				phase = patching
-			case corev1.ConditionUnknown:
-				phase = patching
+				phase = PhaseStabilizing
+			case corev1.ConditionFalse, corev1.ConditionUnknown:
+				phase = PhasePatching
 			}
 
 		case redskyv1beta1.TrialReady:
 			switch c.Status {
 			case corev1.ConditionTrue:
 				if t.Status.StartTime != nil {
-					phase = running
-				} else {
-					phase = stabilized
+					phase = PhaseRunning
 				}
+			case corev1.ConditionFalse, corev1.ConditionUnknown:
+				phase = PhaseStabilizing
+			}
+
+		case redskyv1beta1.TrialPreHooksComplete, redskyv1beta1.TrialPostHooksComplete:
+			switch c.Status {
 			case corev1.ConditionFalse:
-				phase = waiting
-			case corev1.ConditionUnknown:
-				phase = waiting
+				phase = PhaseRunningHooks
+			}
+
+		case redskyv1beta1.TrialPreempted:
+			switch c.Status {
+			case corev1.ConditionTrue:
+				phase = PhasePreempted
 			}
 
 		case redskyv1beta1.TrialObserved:
 			switch c.Status {
 			case corev1.ConditionTrue:
-				phase = captured
+				// The trial is not finished until it is marked complete
+				phase = PhaseTearingDown
+			case corev1.ConditionFalse, corev1.ConditionUnknown:
+				// The run job may still be going, only report measuring once it is done
+				if t.Status.CompletionTime != nil {
+					phase = PhaseMeasuring
+				}
+			}
+
+		case redskyv1beta1.TrialSetupDeleted:
+			switch c.Status {
 			case corev1.ConditionFalse:
-				phase = capturing
-			case corev1.ConditionUnknown:
-				phase = capturing
+				phase = PhaseTearingDown
 			}
 
 		case redskyv1beta1.TrialComplete:
 			switch c.Status {
 			case corev1.ConditionTrue:
-				return completed
+				return PhaseFinished
 			}
 
 		case redskyv1beta1.TrialFailed:
 			switch c.Status {
 			case corev1.ConditionTrue:
-				return failed
+				return PhaseFailed
 			}
 		}
 	}
@@ -173,13 +189,39 @@ func summarize(t *redskyv1beta1.Trial) string {
 }
 
 func assignments(t *redskyv1beta1.Trial) string {
+	if t.Spec.AssignmentsFormat == redskyv1beta1.TrialAssignmentsJSON {
+		return assignmentsJSON(t)
+	}
+
 	assignments := make([]string, len(t.Spec.Assignments))
 	for i := range t.Spec.Assignments {
-		assignments[i] = fmt.Sprintf("%s=%s", t.Spec.Assignments[i].Name, t.Spec.Assignments[i].Value.String())
+		value := t.Spec.Assignments[i].Value.String()
+		if value == "" || strings.ContainsAny(value, ",= \"") {
+			value = strconv.Quote(value)
+		}
+		assignments[i] = fmt.Sprintf("%s=%s", t.Spec.Assignments[i].Name, value)
 	}
 	return strings.Join(assignments, ", ")
 }
 
+// assignmentsJSON renders the assignments as a JSON object, preserving the assignment order
+func assignmentsJSON(t *redskyv1beta1.Trial) string {
+	var buf strings.Builder
+	buf.WriteByte('{')
+	for i := range t.Spec.Assignments {
+		name, _ := json.Marshal(t.Spec.Assignments[i].Name)
+		value, _ := json.Marshal(t.Spec.Assignments[i].Value)
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
 func values(t *redskyv1beta1.Trial) string {
 	for i := range t.Status.Conditions {
 		c := &t.Status.Conditions[i]
@@ -22,17 +22,21 @@ import (
 	"github.com/stretchr/testify/assert"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestUpdateStatus_Summarize(t *testing.T) {
 	cases := []struct {
-		desc       string
-		conditions []redskyv1beta1.TrialCondition
-		phase      string
+		desc           string
+		conditions     []redskyv1beta1.TrialCondition
+		startTime      *metav1.Time
+		completionTime *metav1.Time
+		phase          string
 	}{
 		{
 			desc:  "Created",
-			phase: created,
+			phase: PhaseCreated,
 		},
 		{
 			desc: "HasSetupTasks",
@@ -46,7 +50,7 @@ func TestUpdateStatus_Summarize(t *testing.T) {
 					Status: corev1.ConditionUnknown,
 				},
 			},
-			phase: settingUp,
+			phase: PhaseSettingUp,
 		},
 		{
 			desc: "SettingUp",
@@ -60,7 +64,7 @@ func TestUpdateStatus_Summarize(t *testing.T) {
 					Status: corev1.ConditionUnknown,
 				},
 			},
-			phase: settingUp,
+			phase: PhaseSettingUp,
 		},
 		{
 			desc: "SetupCreated",
@@ -74,7 +78,7 @@ func TestUpdateStatus_Summarize(t *testing.T) {
 					Status: corev1.ConditionUnknown,
 				},
 			},
-			phase: setupCreated,
+			phase: PhasePatching,
 		},
 		{
 			desc: "SetupCreateFailure",
@@ -92,7 +96,7 @@ func TestUpdateStatus_Summarize(t *testing.T) {
 					Status: corev1.ConditionTrue,
 				},
 			},
-			phase: failed,
+			phase: PhaseFailed,
 		},
 		{
 			desc: "SetupCreateUnexpectedFailure",
@@ -110,12 +114,117 @@ func TestUpdateStatus_Summarize(t *testing.T) {
 					Status: corev1.ConditionTrue,
 				},
 			},
-			phase: failed,
+			phase: PhaseFailed,
+		},
+		{
+			desc: "Running",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialPatched,
+					Status: corev1.ConditionTrue,
+				},
+				{
+					Type:   redskyv1beta1.TrialReady,
+					Status: corev1.ConditionTrue,
+				},
+				{
+					Type:   redskyv1beta1.TrialObserved,
+					Status: corev1.ConditionUnknown,
+				},
+			},
+			startTime: &metav1.Time{},
+			phase:     PhaseRunning,
+		},
+		{
+			desc: "Preempted",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialPreempted,
+					Status: corev1.ConditionTrue,
+				},
+				{
+					Type:   redskyv1beta1.TrialReady,
+					Status: corev1.ConditionTrue,
+				},
+			},
+			phase: PhasePreempted,
+		},
+		{
+			desc: "Measuring",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialReady,
+					Status: corev1.ConditionTrue,
+				},
+				{
+					Type:   redskyv1beta1.TrialObserved,
+					Status: corev1.ConditionFalse,
+				},
+			},
+			startTime:      &metav1.Time{},
+			completionTime: &metav1.Time{},
+			phase:          PhaseMeasuring,
+		},
+		{
+			desc: "TearingDown",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialSetupDeleted,
+					Status: corev1.ConditionFalse,
+				},
+				{
+					Type:   redskyv1beta1.TrialObserved,
+					Status: corev1.ConditionTrue,
+				},
+			},
+			phase: PhaseTearingDown,
+		},
+		{
+			desc: "RunningHooks",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialPostHooksComplete,
+					Status: corev1.ConditionFalse,
+				},
+				{
+					Type:   redskyv1beta1.TrialObserved,
+					Status: corev1.ConditionTrue,
+				},
+			},
+			phase: PhaseRunningHooks,
+		},
+		{
+			desc: "Observed",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialObserved,
+					Status: corev1.ConditionTrue,
+				},
+			},
+			phase: PhaseTearingDown,
+		},
+		{
+			desc: "Finished",
+			conditions: []redskyv1beta1.TrialCondition{
+				{
+					Type:   redskyv1beta1.TrialObserved,
+					Status: corev1.ConditionTrue,
+				},
+				{
+					Type:   redskyv1beta1.TrialComplete,
+					Status: corev1.ConditionTrue,
+				},
+			},
+			phase: PhaseFinished,
 		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
-			tt := &redskyv1beta1.Trial{Status: redskyv1beta1.TrialStatus{Conditions: c.conditions}}
+			tt := &redskyv1beta1.Trial{Status: redskyv1beta1.TrialStatus{
+				Conditions:     c.conditions,
+				StartTime:      c.startTime,
+				CompletionTime: c.completionTime,
+			}}
 			UpdateStatus(tt)
 			assert.Equal(t, c.phase, tt.Status.Phase)
 		})
@@ -207,3 +316,41 @@ func TestUpdateStatus_Values(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateStatus_Assignments(t *testing.T) {
+	assignments := []redskyv1beta1.Assignment{
+		{Name: "replicas", Value: intstr.FromInt(3)},
+		{Name: "gc", Value: intstr.FromString("G1")},
+		{Name: "opts", Value: intstr.FromString("-Xmx1g, -Xss1m")},
+	}
+
+	cases := []struct {
+		desc   string
+		format redskyv1beta1.TrialAssignmentsFormat
+		value  string
+	}{
+		{
+			desc:  "Default",
+			value: `replicas=3, gc=G1, opts="-Xmx1g, -Xss1m"`,
+		},
+		{
+			desc:   "Text",
+			format: redskyv1beta1.TrialAssignmentsText,
+			value:  `replicas=3, gc=G1, opts="-Xmx1g, -Xss1m"`,
+		},
+		{
+			desc:   "JSON",
+			format: redskyv1beta1.TrialAssignmentsJSON,
+			value:  `{"replicas":3,"gc":"G1","opts":"-Xmx1g, -Xss1m"}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tt := &redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{Assignments: assignments, AssignmentsFormat: c.format},
+			}
+			UpdateStatus(tt)
+			assert.Equal(t, c.value, tt.Status.Assignments)
+		})
+	}
+}
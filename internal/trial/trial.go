@@ -101,7 +101,7 @@ func IsBaseline(t *redskyv1beta1.Trial, exp *redskyv1beta1.Experiment) bool {
 	// Index the parameter definitions
 	baseline := make(map[string]intstr.IntOrString, len(exp.Spec.Parameters))
 	for i := range exp.Spec.Parameters {
-		if b := exp.Spec.Parameters[i].Baseline; b != nil {
+		if b := exp.Spec.Parameters[i].GetBaseline(); b != nil {
 			baseline[exp.Spec.Parameters[i].Name] = *b
 		}
 	}
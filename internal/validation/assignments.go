@@ -78,7 +78,7 @@ func CheckAssignments(t *redskyv1beta1.Trial, exp *redskyv1beta1.Experiment) err
 // CheckParameterValue ensures the supplied value in range for the parameter.
 func CheckParameterValue(p *redskyv1beta1.Parameter, v intstr.IntOrString) bool {
 	if v.Type == intstr.String {
-		return contains(p.Values, v.StrVal)
+		return contains(p.GetValues(), v.StrVal)
 	}
 	return v.IntVal >= p.Min && v.IntVal <= p.Max
 }
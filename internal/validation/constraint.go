@@ -0,0 +1,331 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ConstraintExpression is a parsed expression constraint, e.g. `cpu * replicas <= 32`.
+type ConstraintExpression struct {
+	op          token.Token
+	left, right ast.Expr
+}
+
+// ParseConstraintExpression parses an expression constraint. The expression must compare two arithmetic expressions
+// consisting of parameter names, numeric constants and the +, -, * and / operators.
+func ParseConstraintExpression(expr string) (*ConstraintExpression, error) {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint expression %q: %w", expr, err)
+	}
+
+	for pe, ok := e.(*ast.ParenExpr); ok; pe, ok = e.(*ast.ParenExpr) {
+		e = pe.X
+	}
+
+	be, ok := e.(*ast.BinaryExpr)
+	if !ok {
+		return nil, fmt.Errorf("invalid constraint expression %q: must be a comparison", expr)
+	}
+	switch be.Op {
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+	default:
+		return nil, fmt.Errorf("invalid constraint expression %q: unsupported comparison %q", expr, be.Op)
+	}
+
+	for _, side := range []ast.Expr{be.X, be.Y} {
+		if err := checkArithmetic(side); err != nil {
+			return nil, fmt.Errorf("invalid constraint expression %q: %w", expr, err)
+		}
+	}
+
+	return &ConstraintExpression{op: be.Op, left: be.X, right: be.Y}, nil
+}
+
+// Parameters returns the names of the parameters referenced by the expression, in the order they first appear.
+func (c *ConstraintExpression) Parameters() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, side := range []ast.Expr{c.left, c.right} {
+		ast.Inspect(side, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && !seen[id.Name] {
+				seen[id.Name] = true
+				names = append(names, id.Name)
+			}
+			return true
+		})
+	}
+	return names
+}
+
+// Eval evaluates the expression using the supplied parameter values.
+func (c *ConstraintExpression) Eval(values map[string]float64) (bool, error) {
+	l, err := evalArithmetic(c.left, values)
+	if err != nil {
+		return false, err
+	}
+	r, err := evalArithmetic(c.right, values)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.op {
+	case token.LSS:
+		return l < r, nil
+	case token.LEQ:
+		return l <= r, nil
+	case token.GTR:
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+// Linear returns the weights and bound of an equivalent sum constraint. The last return value is false if the
+// expression is not linear, i.e. it cannot be represented as a sum constraint. Strict comparisons are treated as
+// inclusive bounds.
+func (c *ConstraintExpression) Linear() (map[string]float64, float64, bool, bool) {
+	lw, lc, ok := linearTerms(c.left)
+	if !ok {
+		return nil, 0, false, false
+	}
+	rw, rc, ok := linearTerms(c.right)
+	if !ok {
+		return nil, 0, false, false
+	}
+
+	// Move all of the parameters to the left and all of the constants to the right
+	weights := lw
+	for name, w := range rw {
+		weights[name] -= w
+	}
+	for name, w := range weights {
+		if w == 0 {
+			delete(weights, name)
+		}
+	}
+
+	return weights, rc - lc, c.op == token.LSS || c.op == token.LEQ, true
+}
+
+// CheckConstraints ensures the numeric trial assignments satisfy the constraints on the experiment. Constraints which
+// reference parameters without a numeric assignment are ignored.
+func CheckConstraints(t *redskyv1beta1.Trial, exp *redskyv1beta1.Experiment) error {
+	values := make(map[string]float64, len(t.Spec.Assignments))
+	for _, a := range t.Spec.Assignments {
+		if a.Value.Type == intstr.Int {
+			values[a.Name] = float64(a.Value.IntVal)
+		}
+	}
+
+	for i := range exp.Spec.Constraints {
+		c := &exp.Spec.Constraints[i]
+		switch {
+		case c.Order != nil:
+			lower, lok := values[c.Order.LowerParameter]
+			upper, uok := values[c.Order.UpperParameter]
+			if lok && uok && lower > upper {
+				return fmt.Errorf("assignments do not satisfy order constraint %q", c.Name)
+			}
+
+		case c.Sum != nil:
+			sum, ok := 0.0, true
+			for _, p := range c.Sum.Parameters {
+				v, found := values[p.Name]
+				ok = ok && found
+				sum += v * float64(p.Weight.MilliValue()) / 1000
+			}
+			bound := float64(c.Sum.Bound.MilliValue()) / 1000
+			if ok && ((c.Sum.IsUpperBound && sum > bound) || (!c.Sum.IsUpperBound && sum < bound)) {
+				return fmt.Errorf("assignments do not satisfy sum constraint %q", c.Name)
+			}
+
+		case c.Expression != "":
+			ce, err := ParseConstraintExpression(c.Expression)
+			if err != nil {
+				return err
+			}
+
+			ok, err := ce.Eval(values)
+			if err != nil {
+				return fmt.Errorf("unable to evaluate constraint expression %q: %w", c.Expression, err)
+			}
+			if !ok {
+				return fmt.Errorf("assignments do not satisfy constraint expression %q", c.Expression)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkArithmetic ensures the supplied expression only uses the supported arithmetic.
+func checkArithmetic(e ast.Expr) error {
+	switch n := e.(type) {
+	case *ast.Ident:
+		return nil
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return fmt.Errorf("unsupported constant %s", n.Value)
+		}
+		return nil
+	case *ast.ParenExpr:
+		return checkArithmetic(n.X)
+	case *ast.UnaryExpr:
+		if n.Op != token.ADD && n.Op != token.SUB {
+			return fmt.Errorf("unsupported operator %q", n.Op)
+		}
+		return checkArithmetic(n.X)
+	case *ast.BinaryExpr:
+		switch n.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO:
+		default:
+			return fmt.Errorf("unsupported operator %q", n.Op)
+		}
+		if err := checkArithmetic(n.X); err != nil {
+			return err
+		}
+		return checkArithmetic(n.Y)
+	default:
+		return fmt.Errorf("unsupported expression")
+	}
+}
+
+// evalArithmetic evaluates an arithmetic expression previously verified by `checkArithmetic`.
+func evalArithmetic(e ast.Expr, values map[string]float64) (float64, error) {
+	switch n := e.(type) {
+	case *ast.Ident:
+		v, ok := values[n.Name]
+		if !ok {
+			return 0, fmt.Errorf("missing numeric value for parameter %q", n.Name)
+		}
+		return v, nil
+	case *ast.BasicLit:
+		return parseConstant(n)
+	case *ast.ParenExpr:
+		return evalArithmetic(n.X, values)
+	case *ast.UnaryExpr:
+		x, err := evalArithmetic(n.X, values)
+		if n.Op == token.SUB {
+			x = -x
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := evalArithmetic(n.X, values)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evalArithmetic(n.Y, values)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		default:
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return x / y, nil
+		}
+	default:
+		return 0, fmt.Errorf("unsupported expression")
+	}
+}
+
+// linearTerms returns the parameter weights and constant term of an arithmetic expression, the last return value is
+// false if the expression is not linear.
+func linearTerms(e ast.Expr) (map[string]float64, float64, bool) {
+	switch n := e.(type) {
+	case *ast.Ident:
+		return map[string]float64{n.Name: 1}, 0, true
+	case *ast.BasicLit:
+		v, err := parseConstant(n)
+		return map[string]float64{}, v, err == nil
+	case *ast.ParenExpr:
+		return linearTerms(n.X)
+	case *ast.UnaryExpr:
+		w, c, ok := linearTerms(n.X)
+		if n.Op == token.SUB {
+			return scaleTerms(w, -1), -c, ok
+		}
+		return w, c, ok
+	case *ast.BinaryExpr:
+		xw, xc, ok := linearTerms(n.X)
+		if !ok {
+			return nil, 0, false
+		}
+		yw, yc, ok := linearTerms(n.Y)
+		if !ok {
+			return nil, 0, false
+		}
+		switch n.Op {
+		case token.ADD, token.SUB:
+			sign := 1.0
+			if n.Op == token.SUB {
+				sign = -1
+			}
+			for name, w := range yw {
+				xw[name] += sign * w
+			}
+			return xw, xc + sign*yc, true
+		case token.MUL:
+			// At least one side must be constant
+			if len(xw) == 0 {
+				return scaleTerms(yw, xc), xc * yc, true
+			}
+			if len(yw) == 0 {
+				return scaleTerms(xw, yc), xc * yc, true
+			}
+		case token.QUO:
+			// Only division by a non-zero constant is linear
+			if len(yw) == 0 && yc != 0 {
+				return scaleTerms(xw, 1/yc), xc / yc, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// scaleTerms multiplies the parameter weights by a constant factor.
+func scaleTerms(w map[string]float64, f float64) map[string]float64 {
+	for name := range w {
+		w[name] *= f
+	}
+	return w
+}
+
+// parseConstant returns the value of a numeric constant.
+func parseConstant(lit *ast.BasicLit) (float64, error) {
+	if lit.Kind == token.INT {
+		i, err := strconv.ParseInt(lit.Value, 0, 64)
+		return float64(i), err
+	}
+	return strconv.ParseFloat(lit.Value, 64)
+}
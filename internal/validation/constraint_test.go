@@ -0,0 +1,164 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestParseConstraintExpression(t *testing.T) {
+	cases := []struct {
+		desc        string
+		expr        string
+		parameters  []string
+		weights     map[string]float64
+		bound       float64
+		upper       bool
+		linear      bool
+		expectedErr string
+	}{
+		{
+			desc:       "product",
+			expr:       "cpu * replicas <= 32",
+			parameters: []string{"cpu", "replicas"},
+		},
+		{
+			desc:       "weighted sum",
+			expr:       "(cpu + 2*memory) / 2 >= replicas - 1",
+			parameters: []string{"cpu", "memory", "replicas"},
+			weights:    map[string]float64{"cpu": 0.5, "memory": 1, "replicas": -1},
+			bound:      -1,
+			linear:     true,
+		},
+		{
+			desc:       "order",
+			expr:       "min < max",
+			parameters: []string{"min", "max"},
+			weights:    map[string]float64{"min": 1, "max": -1},
+			upper:      true,
+			linear:     true,
+		},
+		{
+			desc:        "arithmetic only",
+			expr:        "cpu * replicas",
+			expectedErr: `invalid constraint expression "cpu * replicas": unsupported comparison "*"`,
+		},
+		{
+			desc:        "equality",
+			expr:        "cpu == 1",
+			expectedErr: `invalid constraint expression "cpu == 1": unsupported comparison "=="`,
+		},
+		{
+			desc:        "function call",
+			expr:        "max(cpu, 1) <= 2",
+			expectedErr: `invalid constraint expression "max(cpu, 1) <= 2": unsupported expression`,
+		},
+		{
+			desc:        "string constant",
+			expr:        `cpu <= "2"`,
+			expectedErr: `invalid constraint expression "cpu <= \"2\"": unsupported constant "2"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ce, err := ParseConstraintExpression(c.expr)
+			if c.expectedErr != "" {
+				assert.EqualError(t, err, c.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.parameters, ce.Parameters())
+				weights, bound, upper, linear := ce.Linear()
+				assert.Equal(t, c.linear, linear)
+				if c.linear {
+					assert.Equal(t, c.weights, weights)
+					assert.Equal(t, c.bound, bound)
+					assert.Equal(t, c.upper, upper)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckConstraints(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		Spec: redskyv1beta1.ExperimentSpec{
+			Constraints: []redskyv1beta1.Constraint{
+				{Name: "capacity", Expression: "cpu * replicas <= 32"},
+				{Name: "order", Order: &redskyv1beta1.OrderConstraint{LowerParameter: "cpu", UpperParameter: "replicas"}},
+				{Name: "sum", Sum: &redskyv1beta1.SumConstraint{
+					Bound:      resource.MustParse("3"),
+					Parameters: []redskyv1beta1.SumConstraintParameter{{Name: "cpu", Weight: resource.MustParse("1")}, {Name: "replicas", Weight: resource.MustParse("1")}},
+				}},
+			},
+		},
+	}
+
+	cases := []struct {
+		desc        string
+		cpu         int
+		replicas    int
+		expectedErr string
+	}{
+		{
+			desc:     "satisfied",
+			cpu:      4,
+			replicas: 8,
+		},
+		{
+			desc:        "violated",
+			cpu:         8,
+			replicas:    5,
+			expectedErr: `assignments do not satisfy constraint expression "cpu * replicas <= 32"`,
+		},
+		{
+			desc:        "order violated",
+			cpu:         4,
+			replicas:    2,
+			expectedErr: `assignments do not satisfy order constraint "order"`,
+		},
+		{
+			desc:        "sum violated",
+			cpu:         1,
+			replicas:    1,
+			expectedErr: `assignments do not satisfy sum constraint "sum"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tr := &redskyv1beta1.Trial{
+				Spec: redskyv1beta1.TrialSpec{
+					Assignments: []redskyv1beta1.Assignment{
+						{Name: "cpu", Value: intstr.FromInt(c.cpu)},
+						{Name: "replicas", Value: intstr.FromInt(c.replicas)},
+					},
+				},
+			}
+			err := CheckConstraints(tr, exp)
+			if c.expectedErr != "" {
+				assert.EqualError(t, err, c.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
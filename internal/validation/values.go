@@ -48,3 +48,19 @@ func CheckMetricBounds(m *redskyv1beta1.Metric, v *redskyv1beta1.Value) error {
 
 	return nil
 }
+
+// CheckFailFast returns an error if an intermediate sample of the metric is worse than the fail fast limit.
+func CheckFailFast(m *redskyv1beta1.Metric, value float64) error {
+	if m.FailFast == nil {
+		return nil
+	}
+
+	limit := float64(m.FailFast.ScaledValue(resource.Nano)) / 1000000000
+	if m.Minimize && value > limit {
+		return fmt.Errorf("metric value %f for %s is above the fail fast limit of %s", value, m.Name, m.FailFast.String())
+	}
+	if !m.Minimize && value < limit {
+		return fmt.Errorf("metric value %f for %s is below the fail fast limit of %s", value, m.Name, m.FailFast.String())
+	}
+	return nil
+}
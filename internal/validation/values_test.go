@@ -92,3 +92,49 @@ func TestCheckMetricBounds(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckFailFast(t *testing.T) {
+	cases := []struct {
+		desc     string
+		metric   redskyv1beta1.Metric
+		value    float64
+		hasError bool
+	}{
+		{
+			desc:  "no limit",
+			value: 1.0,
+		},
+		{
+			desc:   "minimized within limit",
+			metric: redskyv1beta1.Metric{Minimize: true, FailFast: mustQuantity("50m")},
+			value:  0.01,
+		},
+		{
+			desc:     "minimized over limit",
+			metric:   redskyv1beta1.Metric{Minimize: true, FailFast: mustQuantity("50m")},
+			value:    0.07,
+			hasError: true,
+		},
+		{
+			desc:   "maximized within limit",
+			metric: redskyv1beta1.Metric{FailFast: mustQuantity("100")},
+			value:  150,
+		},
+		{
+			desc:     "maximized under limit",
+			metric:   redskyv1beta1.Metric{FailFast: mustQuantity("100")},
+			value:    90,
+			hasError: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := CheckFailFast(&c.metric, c.value)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -21,11 +21,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	redskyv1alpha1 "github.com/thestormforge/optimize-controller/api/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/controllers"
 	"github.com/thestormforge/optimize-controller/internal/controller"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/internal/sink"
+	"github.com/thestormforge/optimize-controller/internal/template"
 	"github.com/thestormforge/optimize-controller/internal/version"
 	"github.com/thestormforge/optimize-go/pkg/config"
 	zap2 "go.uber.org/zap"
@@ -55,9 +60,28 @@ func main() {
 
 	var metricsAddr string
 	var enableLeaderElection bool
+	var retentionKeepBest int
+	var retentionOlderThan string
+	var trialSink string
+	var serverFinalizerTimeout time.Duration
+	var crdAutoPatch bool
+	var templateFunctions string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.IntVar(&retentionKeepBest, "retention-keep-best", 0, "The number of best trials to keep when pruning finished experiments.")
+	flag.StringVar(&retentionOlderThan, "retention-older-than", "",
+		"Prune the results of experiments that finished longer ago than this (e.g. \"90d\"). Pruning is disabled by default.")
+	flag.StringVar(&trialSink, "trial-sink", "",
+		"Publish an event for each finished trial to this webhook URL, use a \"kafka+https\" URL for a Kafka REST proxy topic.")
+	flag.DurationVar(&serverFinalizerTimeout, "server-finalizer-timeout", 0,
+		"Remove the server finalizer from deleted experiments and trials that have been waiting on the server for longer than this. Disabled by default.")
+	flag.BoolVar(&crdAutoPatch, "crd-auto-patch", false,
+		"Update installed custom resource definitions that do not match the controller. Requires permission to update custom resource definitions.")
+	flag.StringVar(&templateFunctions, "template-functions", "",
+		"Comma separated allowlist of Sprig template functions (or categories: \"strings\", \"math\", \"dates\") available to patches and metric queries. All Sprig functions except \"env\" and \"expandenv\" are allowed by default.")
+	intervals := controllers.DefaultRequeueIntervals
+	intervals.AddFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(func(o *zap.Options) {
@@ -68,38 +92,93 @@ func main() {
 		o.StacktraceLevel = &stl
 	}))
 
+	retention := &experiment.RetentionPolicy{KeepBest: retentionKeepBest}
+	if retentionOlderThan != "" {
+		olderThan, err := experiment.ParseAge(retentionOlderThan)
+		if err != nil {
+			setupLog.Error(err, "invalid retention age")
+			os.Exit(1)
+		}
+		retention.OlderThan = olderThan
+	}
+
+	if err := intervals.Validate(); err != nil {
+		setupLog.Error(err, "invalid requeue interval")
+		os.Exit(1)
+	}
+
+	if templateFunctions != "" {
+		if err := template.AllowSprigFunctions(strings.Split(templateFunctions, ",")); err != nil {
+			setupLog.Error(err, "invalid template function allowlist")
+			os.Exit(1)
+		}
+	}
+
 	v := version.GetInfo()
 	setupLog.Info("Red Sky Ops Controller", "version", v.String(), "gitCommit", v.GitCommit)
 
-	mgr, err := ctrl.NewManager(controller.WithConversion(ctrl.GetConfigOrDie(), scheme), ctrl.Options{
+	config := controller.WithConversion(ctrl.GetConfigOrDie(), scheme)
+	jobs, err := controller.NewJobCache(config, 0)
+	if err != nil {
+		setupLog.Error(err, "unable to create job cache")
+		os.Exit(1)
+	}
+
+	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
 		LeaderElection:     enableLeaderElection,
+		NewClient:          controller.NewClient(jobs),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if err := mgr.Add(jobs); err != nil {
+		setupLog.Error(err, "unable to add job cache")
+		os.Exit(1)
+	}
+
+	if err := controllers.SetupIndexes(mgr); err != nil {
+		setupLog.Error(err, "unable to create cache indexes")
+		os.Exit(1)
+	}
 
 	if err = (&controllers.ExperimentReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Experiment"),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Experiment"),
+		Retention: retention,
+		Intervals: &intervals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Experiment")
 		os.Exit(1)
 	}
 	if err = (&controllers.ServerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Server"),
-		Scheme: mgr.GetScheme(),
+		Client:           mgr.GetClient(),
+		Log:              ctrl.Log.WithName("controllers").WithName("Server"),
+		Scheme:           mgr.GetScheme(),
+		Recorder:         mgr.GetEventRecorderFor("server"),
+		FinalizerTimeout: serverFinalizerTimeout,
+		Intervals:        &intervals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Server")
 		os.Exit(1)
 	}
+	if err = (&controllers.LocalReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Local"),
+		Scheme:    mgr.GetScheme(),
+		Intervals: &intervals,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Local")
+		os.Exit(1)
+	}
 	if err = (&controllers.SetupReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Setup"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Setup"),
+		Scheme:    mgr.GetScheme(),
+		Jobs:      jobs,
+		Intervals: &intervals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Setup")
 		os.Exit(1)
@@ -113,17 +192,29 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.ReadyReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Ready"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Ready"),
+		Scheme:    mgr.GetScheme(),
+		Intervals: &intervals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Ready")
 		os.Exit(1)
 	}
-	if err = (&controllers.TrialJobReconciler{
+	if err = (&controllers.HookReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Trial"),
+		Log:    ctrl.Log.WithName("controllers").WithName("Hook"),
 		Scheme: mgr.GetScheme(),
+		Jobs:   jobs,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Hook")
+		os.Exit(1)
+	}
+	if err = (&controllers.TrialJobReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Trial"),
+		Scheme:    mgr.GetScheme(),
+		Jobs:      jobs,
+		Intervals: &intervals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Trial")
 		os.Exit(1)
@@ -136,6 +227,31 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Metric")
 		os.Exit(1)
 	}
+	if err = (&controllers.CRDReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("CRD"),
+		Recorder:  mgr.GetEventRecorderFor("crd"),
+		AutoPatch: crdAutoPatch,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CRD")
+		os.Exit(1)
+	}
+	if trialSink != "" {
+		publisher, err := sink.NewPublisher(trialSink)
+		if err != nil {
+			setupLog.Error(err, "invalid trial sink")
+			os.Exit(1)
+		}
+		if err = (&controllers.SinkReconciler{
+			Client:    mgr.GetClient(),
+			Log:       ctrl.Log.WithName("controllers").WithName("Sink"),
+			Publisher: publisher,
+			Intervals: &intervals,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Sink")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
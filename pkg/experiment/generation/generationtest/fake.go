@@ -0,0 +1,106 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generationtest provides fake implementations of the experiment generation source interfaces for use in
+// tests. The fakes can be returned from the `Map` function of a scan selector to contribute fixed values to a
+// generated experiment.
+//
+// The fakes live outside of the internal packages so code in other modules can use them to test custom sources.
+package generationtest
+
+import (
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment/generation"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ExperimentSource is a fake experiment source that records the experiments it updates.
+type ExperimentSource struct {
+	// UpdateFunc is invoked for each update, if nil the experiment is not modified.
+	UpdateFunc func(exp *redskyv1beta1.Experiment) error
+	// Err is returned from every update, UpdateFunc is not invoked when it is set.
+	Err error
+
+	// Updated is the list of experiments that were passed to Update.
+	Updated []*redskyv1beta1.Experiment
+}
+
+var _ generation.ExperimentSource = &ExperimentSource{}
+
+// Update records the experiment and invokes the update function.
+func (s *ExperimentSource) Update(exp *redskyv1beta1.Experiment) error {
+	s.Updated = append(s.Updated, exp)
+	if s.Err != nil {
+		return s.Err
+	}
+	if s.UpdateFunc != nil {
+		return s.UpdateFunc(exp)
+	}
+	return nil
+}
+
+// ParameterSource is a fake parameter source that returns a fixed list of parameters. The parameter names are
+// passed through the supplied parameter namer using the configured metadata and path.
+type ParameterSource struct {
+	// Meta is the metadata of the resource the parameters belong to.
+	Meta yaml.ResourceMeta
+	// Path is the field path of the parameters.
+	Path []string
+	// Values is the list of parameters to return.
+	Values []redskyv1beta1.Parameter
+	// Err is returned instead of the parameters when it is set.
+	Err error
+}
+
+var _ generation.ParameterSource = &ParameterSource{}
+
+// Parameters returns copies of the configured parameters using the computed names.
+func (s *ParameterSource) Parameters(name generation.ParameterNamer) ([]redskyv1beta1.Parameter, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	var params []redskyv1beta1.Parameter
+	for i := range s.Values {
+		p := *s.Values[i].DeepCopy()
+		p.Name = name(s.Meta, s.Path, p.Name)
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// MetricSource is a fake metric source that returns a fixed list of metrics.
+type MetricSource struct {
+	// Values is the list of metrics to return.
+	Values []redskyv1beta1.Metric
+	// Err is returned instead of the metrics when it is set.
+	Err error
+}
+
+var _ generation.MetricSource = &MetricSource{}
+
+// Metrics returns copies of the configured metrics.
+func (s *MetricSource) Metrics() ([]redskyv1beta1.Metric, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	var metrics []redskyv1beta1.Metric
+	for i := range s.Values {
+		metrics = append(metrics, *s.Values[i].DeepCopy())
+	}
+	return metrics, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generationtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment/generation"
+	"github.com/thestormforge/optimize-controller/internal/scan"
+	"github.com/thestormforge/optimize-controller/internal/sfio"
+	"github.com/thestormforge/optimize-controller/pkg/scan/scantest"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+func TestFakes(t *testing.T) {
+	nodes, err := kio.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+`))
+	require.NoError(t, err)
+
+	es := &ExperimentSource{UpdateFunc: func(exp *redskyv1beta1.Experiment) error {
+		exp.Name = "fake"
+		return nil
+	}}
+	ps := &ParameterSource{Values: []redskyv1beta1.Parameter{{Name: "replicas", Min: 1, Max: 5}}}
+	ms := &MetricSource{Values: []redskyv1beta1.Metric{{Name: "cost", Minimize: true}}}
+
+	sel := &scantest.Selector{Values: []interface{}{es, ps, ms}}
+	s := &scan.Scanner{Selectors: []scan.Selector{sel}, Transformer: &generation.Transformer{}}
+
+	result, err := s.Filter(nodes)
+	require.NoError(t, err)
+	assert.Len(t, es.Updated, 1)
+	assert.Len(t, sel.Mapped, 1)
+
+	exp := &redskyv1beta1.Experiment{}
+	if assert.Len(t, result, 1) && assert.NoError(t, sfio.DecodeYAMLToJSON(result[0], exp)) {
+		assert.Equal(t, "fake", exp.Name)
+		assert.Equal(t, ps.Values, exp.Spec.Parameters)
+		assert.Equal(t, ms.Values, exp.Spec.Metrics)
+	}
+
+	ms.Err = fmt.Errorf("test")
+	_, err = s.Filter(nodes)
+	assert.EqualError(t, err, "test")
+}
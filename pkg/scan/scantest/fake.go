@@ -0,0 +1,85 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scantest provides fake implementations of the scan interfaces for use in tests. The fakes live outside of
+// the internal packages so code in other modules can use them.
+package scantest
+
+import (
+	"github.com/thestormforge/optimize-controller/internal/scan"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Selector is a fake selector that matches resource nodes using a generic selector and maps every matched node to
+// a fixed list of values.
+type Selector struct {
+	// GenericSelector is used to select resource nodes, the zero value matches everything.
+	scan.GenericSelector
+	// Values is the list of values produced for each node that is mapped.
+	Values []interface{}
+	// Err is returned from both select and map when it is set.
+	Err error
+
+	// Mapped is the metadata of each resource node that was mapped.
+	Mapped []yaml.ResourceMeta
+}
+
+var _ scan.Selector = &Selector{}
+
+// Select returns the nodes matched by the generic selector.
+func (s *Selector) Select(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return s.GenericSelector.Select(nodes)
+}
+
+// Map records the metadata of the node and returns the configured values.
+func (s *Selector) Map(_ *yaml.RNode, meta yaml.ResourceMeta) ([]interface{}, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	s.Mapped = append(s.Mapped, meta)
+	return s.Values, nil
+}
+
+// Transformer is a fake transformer that records its inputs.
+type Transformer struct {
+	// TransformFunc is invoked to produce the result, if nil the original resource nodes are returned.
+	TransformFunc func(nodes []*yaml.RNode, selected []interface{}) ([]*yaml.RNode, error)
+	// Err is returned from the transform when it is set, TransformFunc is not invoked.
+	Err error
+
+	// Nodes is the list of resource nodes passed to the last transform.
+	Nodes []*yaml.RNode
+	// Selected is the list of selected values passed to the last transform.
+	Selected []interface{}
+}
+
+var _ scan.Transformer = &Transformer{}
+
+// Transform records the inputs and invokes the transform function.
+func (t *Transformer) Transform(nodes []*yaml.RNode, selected []interface{}) ([]*yaml.RNode, error) {
+	t.Nodes = nodes
+	t.Selected = selected
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	if t.TransformFunc != nil {
+		return t.TransformFunc(nodes, selected)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scantest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-controller/internal/scan"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+func TestFakes(t *testing.T) {
+	nodes, err := kio.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+`))
+	require.NoError(t, err)
+
+	sel := &Selector{GenericSelector: scan.GenericSelector{Kind: "Deployment"}, Values: []interface{}{"a", "b"}}
+	tr := &Transformer{}
+	s := &scan.Scanner{Selectors: []scan.Selector{sel}, Transformer: tr}
+
+	result, err := s.Filter(nodes)
+	require.NoError(t, err)
+	assert.Equal(t, nodes, result)
+	assert.Equal(t, nodes, tr.Nodes)
+	assert.Equal(t, []interface{}{"a", "b"}, tr.Selected)
+	if assert.Len(t, sel.Mapped, 1) {
+		assert.Equal(t, "Deployment", sel.Mapped[0].Kind)
+	}
+}
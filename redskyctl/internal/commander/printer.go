@@ -269,6 +269,24 @@ func (f *printFlags) toPrinter(printer *ResourcePrinter) error {
 	return NoPrinterError{OutputFormat: f.outputFormat, AllowedFormats: f.allowedFormats}
 }
 
+// ContinuationPrinter returns a printer for the remaining pages of a list whose first page was printed using the
+// supplied printer, the header row is not repeated. Returns false if the output format cannot be continued, for
+// example JSON or YAML output where each page would be a separate document.
+func ContinuationPrinter(printer ResourcePrinter) (ResourcePrinter, bool) {
+	switch p := printer.(type) {
+	case *tablePrinter:
+		pp := *p
+		pp.headers = false
+		return &pp, true
+	case *csvPrinter:
+		pp := *p
+		pp.headers = false
+		return &pp, true
+	default:
+		return nil, false
+	}
+}
+
 // marshalPrinter is a printer that generates output using some type of generic encoding (e.g. JSON)
 type marshalPrinter struct {
 	// outputFormat is the name of the marshaller to use, JSON will be used if it is unrecognized
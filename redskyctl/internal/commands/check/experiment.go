@@ -18,10 +18,12 @@ package check
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -29,12 +31,18 @@ import (
 	redskyv1alpha1 "github.com/thestormforge/optimize-controller/api/v1alpha1"
 	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
 	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/internal/meta"
+	"github.com/thestormforge/optimize-controller/internal/metric"
+	"github.com/thestormforge/optimize-controller/internal/server"
 	"github.com/thestormforge/optimize-controller/internal/template"
 	"github.com/thestormforge/optimize-controller/internal/validation"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -49,10 +57,15 @@ const (
 
 // ExperimentOptions are the options for checking an experiment manifest
 type ExperimentOptions struct {
+	// Config is the Red Sky Configuration
+	Config *config.RedSkyConfig
+	// ExperimentsAPI is used to compare the experiment with the server definition
+	ExperimentsAPI experimentsv1alpha1.API
 	// IOStreams are used to access the standard process streams
 	commander.IOStreams
 
 	Filename string
+	Remote   bool
 }
 
 // NewExperimentCommand creates a new command for checking an experiment manifest
@@ -62,11 +75,18 @@ func NewExperimentCommand(o *ExperimentOptions) *cobra.Command {
 		Short: "Check an experiment",
 		Long:  "Check an experiment manifest",
 
-		PreRun: commander.StreamsPreRun(&o.IOStreams),
-		RunE:   commander.WithContextE(o.checkExperiment),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			commander.SetStreams(&o.IOStreams, cmd)
+			if o.Remote {
+				return commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+			}
+			return nil
+		},
+		RunE: commander.WithContextE(o.checkExperiment),
 	}
 
 	cmd.Flags().StringVarP(&o.Filename, "filename", "f", "", "`file` that contains the experiment to check")
+	cmd.Flags().BoolVar(&o.Remote, "remote", false, "compare the experiment with the definition on the server")
 
 	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
 	_ = cmd.MarkFlagRequired("filename")
@@ -117,6 +137,22 @@ func (o *ExperimentOptions) checkExperiment(ctx context.Context) error {
 	// Use the linter to inspect the experiment
 	experiment.Walk(ctx, l, exp)
 
+	// Compare the experiment with the server definition
+	if o.Remote {
+		ee, err := o.ExperimentsAPI.GetExperimentByName(ctx, experimentsv1alpha1.NewExperimentName(exp.Name))
+		if err != nil {
+			return err
+		}
+
+		drift, err := server.DefinitionDrift(exp, &ee)
+		if err != nil {
+			return err
+		}
+		for _, d := range drift {
+			l.logger.V(vError).Info("Experiment definition has drifted from the server", "difference", d)
+		}
+	}
+
 	// TODO Ideally we would just return an error here, but it would look strange alongside the other output
 	if hasError {
 		os.Exit(1)
@@ -138,6 +174,41 @@ func (l *linter) Visit(ctx context.Context, obj interface{}) experiment.Visitor
 
 	switch o := obj.(type) {
 
+	case *redskyv1beta1.Experiment:
+		// The experiment name is used as a label value on trials
+		if err := meta.ValidateName(o.Name); err != nil {
+			lint.Error(err, "Experiment name is invalid")
+		}
+
+		if n := o.Spec.TrialNaming; n != nil {
+			switch n.Suffix {
+			case
+				redskyv1beta1.TrialNameNumber,
+				redskyv1beta1.TrialNameHash,
+				"": // Suffix is valid
+			default:
+				lint.V(vError).Info("Trial naming suffix is invalid", "suffix", n.Suffix)
+			}
+		}
+
+		if wh := o.Spec.ValueWebhook; wh != nil {
+			if u, err := url.Parse(wh.URL); err != nil || !u.IsAbs() {
+				lint.V(vError).Info("Value webhook URL must be absolute", "url", wh.URL)
+			}
+			switch wh.FailurePolicy {
+			case
+				redskyv1beta1.WebhookFailurePolicyFail,
+				redskyv1beta1.WebhookFailurePolicyIgnore,
+				"": // Failure policy is valid
+			default:
+				lint.V(vError).Info("Value webhook failure policy is invalid", "failurePolicy", wh.FailurePolicy)
+			}
+		}
+
+		if err := experiment.ValidateSchedule(o.Spec.Schedule); err != nil {
+			lint.Error(err, "Trial schedule is invalid")
+		}
+
 	case *redskyv1beta1.Optimization:
 		switch o.Name {
 		case "experimentBudget":
@@ -161,13 +232,48 @@ func (l *linter) Visit(ctx context.Context, obj interface{}) experiment.Visitor
 		}
 
 	case *redskyv1beta1.Parameter:
-		if len(o.Values) > 0 && (o.Min != 0 || o.Max != 0) {
-			// NOTE: This won't hit on v1alpha1 converted experiments because min/max get reset
-			lint.V(vWarn).Info("Parameter has both a numeric and string range defined")
-		} else if o.Max <= o.Min && (o.Max != 0 || o.Min != 0) {
-			lint.V(vError).Info("Parameter minimum must be strictly less then maximum", "min", o.Min, "max", o.Max)
-		} else if o.Baseline != nil {
-			checkBaseline(lint, o)
+		switch o.Type {
+		case redskyv1beta1.ParameterTypeBoolean:
+			if len(o.Values) > 0 || o.Min != 0 || o.Max != 0 {
+				lint.V(vWarn).Info("Boolean parameter should not define a range")
+			}
+			if b := o.GetBaseline(); b != nil && !validation.CheckParameterValue(o, *b) {
+				lint.V(vError).Info("Parameter baseline is not a boolean", "baseline", o.Baseline.String())
+			}
+		case "":
+			// Type is inferred from the range
+			if len(o.Values) > 0 && (o.Min != 0 || o.Max != 0) {
+				// NOTE: This won't hit on v1alpha1 converted experiments because min/max get reset
+				lint.V(vWarn).Info("Parameter has both a numeric and string range defined")
+			} else if o.Max <= o.Min && (o.Max != 0 || o.Min != 0) {
+				lint.V(vError).Info("Parameter minimum must be strictly less then maximum", "min", o.Min, "max", o.Max)
+			} else if o.Baseline != nil {
+				checkBaseline(lint, o)
+			}
+		default:
+			lint.V(vError).Info("Parameter type is invalid", "type", o.Type)
+		}
+
+		for _, v := range o.ValueInfo {
+			if !validation.CheckParameterValue(o, intstr.FromString(v.Value)) {
+				lint.V(vError).Info("Parameter value information does not match an allowed value", "value", v.Value)
+			}
+		}
+
+		if o.Encoding != nil {
+			if _, ok := template.Encoders()[o.Encoding.Type]; !ok {
+				lint.V(vError).Info("Parameter encoding type is invalid", "type", o.Encoding.Type)
+			}
+		}
+
+	case *redskyv1beta1.Constraint:
+		if o.Expression != "" {
+			if o.Order != nil || o.Sum != nil {
+				lint.V(vError).Info("Constraint must only define one of order, sum or expression")
+			}
+			if _, err := validation.ParseConstraintExpression(o.Expression); err != nil {
+				lint.Error(err, "Constraint expression is invalid", "expression", o.Expression)
+			}
 		}
 
 	case *redskyv1beta1.Metric:
@@ -177,6 +283,10 @@ func (l *linter) Visit(ctx context.Context, obj interface{}) experiment.Visitor
 			redskyv1beta1.MetricPrometheus,
 			redskyv1beta1.MetricJSONPath,
 			redskyv1beta1.MetricDatadog,
+			redskyv1beta1.MetricInfluxDB,
+			redskyv1beta1.MetricElasticsearch,
+			redskyv1beta1.MetricMetricsServer,
+			redskyv1beta1.MetricDerived,
 			"": // Type is valid
 		default:
 			lint.V(vError).Info("Metric type is invalid", "type", o.Type)
@@ -199,6 +309,14 @@ func (l *linter) Visit(ctx context.Context, obj interface{}) experiment.Visitor
 				if !strings.Contains(q, "scalar") {
 					lint.V(vWarn).Info("Prometheus query may require explicit scalar conversion", "query", o.Query)
 				}
+			case redskyv1beta1.MetricMetricsServer:
+				if q != string(corev1.ResourceCPU) && q != string(corev1.ResourceMemory) {
+					lint.V(vWarn).Info("Metrics server query should be a resource name, one of: cpu|memory", "query", o.Query)
+				}
+			case redskyv1beta1.MetricElasticsearch:
+				if !json.Valid([]byte(q)) {
+					lint.V(vError).Info("Elasticsearch query must be a JSON search request body", "query", o.Query)
+				}
 			}
 		}
 
@@ -212,6 +330,71 @@ func (l *linter) Visit(ctx context.Context, obj interface{}) experiment.Visitor
 			lint.V(vWarn).Info("Metric requires manual conversion to latest version for URL")
 		}
 
+		if o.HTTP != nil {
+			if o.Type != redskyv1beta1.MetricJSONPath && o.Type != redskyv1beta1.MetricPrometheus {
+				lint.V(vWarn).Info("Metric HTTP options are only used by JSON path and Prometheus metrics", "type", o.Type)
+			}
+			for _, h := range o.HTTP.Headers {
+				if h.Value != "" && h.SecretKeyRef != nil {
+					lint.V(vError).Info("Metric HTTP header must not have both a value and a secret reference", "name", h.Name)
+				}
+			}
+		}
+
+		if o.Aggregation != nil {
+			if o.Type != redskyv1beta1.MetricPrometheus {
+				lint.V(vWarn).Info("Metric aggregation is only used by Prometheus metrics", "type", o.Type)
+			}
+			if _, err := metric.Aggregate(o.Aggregation.Function, nil); err != nil {
+				lint.Error(err, "Metric aggregation function is not valid")
+			}
+			if o.Aggregation.RelativeTo != "" && o.Aggregation.RelativeTo != "start" && o.Aggregation.RelativeTo != "completion" {
+				lint.V(vError).Info("Metric aggregation must be relative to the trial start or completion", "relativeTo", o.Aggregation.RelativeTo)
+			}
+		}
+
+		if o.TLS != nil {
+			switch o.Type {
+			case redskyv1beta1.MetricPrometheus, redskyv1beta1.MetricJSONPath, redskyv1beta1.MetricInfluxDB, redskyv1beta1.MetricElasticsearch:
+			default:
+				lint.V(vWarn).Info("Metric TLS configuration is only used by Prometheus, JSON path, InfluxDB and Elasticsearch metrics", "type", o.Type)
+			}
+			for _, d := range []*redskyv1beta1.MetricTLSData{o.TLS.CA, o.TLS.Cert, o.TLS.Key} {
+				if d != nil && ((d.Value != "" && (d.ConfigMapKeyRef != nil || d.SecretKeyRef != nil)) || (d.ConfigMapKeyRef != nil && d.SecretKeyRef != nil)) {
+					lint.V(vError).Info("Metric TLS data must have exactly one of a value, config map key or secret key")
+				}
+			}
+			if (o.TLS.Cert == nil) != (o.TLS.Key == nil) {
+				lint.V(vError).Info("Metric TLS client certificate and key must both be specified")
+			}
+			if o.TLS.InsecureSkipVerify {
+				lint.V(vWarn).Info("Metric TLS certificate verification is disabled")
+			}
+		}
+
+		if o.FailFast != nil && o.SampleInterval == nil {
+			lint.V(vWarn).Info("Metric fail fast limit is only used with a sample interval")
+		}
+
+		if o.SampleInterval != nil {
+			switch o.Type {
+			case redskyv1beta1.MetricMetricsServer, redskyv1beta1.MetricDerived:
+				lint.V(vWarn).Info("Metric sample interval is not used by metrics server or derived metrics", "type", o.Type)
+			}
+			if o.SampleInterval.Duration < time.Second {
+				lint.V(vError).Info("Metric sample interval must be at least one second", "sampleInterval", o.SampleInterval.Duration)
+			}
+		}
+
+		if o.Timestamps != nil {
+			if o.Timestamps.StartQuery == "" && o.Timestamps.EndQuery == "" {
+				lint.V(vWarn).Info("Metric timestamps should have a start or end query")
+			}
+			if o.Type == redskyv1beta1.MetricMetricsServer {
+				lint.V(vWarn).Info("Metric timestamps are not used by metrics server metrics")
+			}
+		}
+
 	case *redskyv1beta1.PatchTemplate:
 		if o.TargetRef != nil {
 			if o.TargetRef.Kind == "" {
@@ -236,6 +419,18 @@ func (l *linter) Visit(ctx context.Context, obj interface{}) experiment.Visitor
 			lint.V(vWarn).Info("Job backoffLimit should be 0", "backoffLimit", *o.Spec.BackoffLimit)
 		}
 
+	case *redskyv1beta1.ExperimentStatus:
+		for _, c := range o.Conditions {
+			if c.Type == redskyv1beta1.ExperimentServerSynced && c.Status == corev1.ConditionFalse {
+				lint.V(vWarn).Info("Experiment is not synchronized with the server, trials will not be created",
+					"reason", c.Reason, "message", c.Message, "consecutiveFailures", o.ServerSyncFailures, "lastSyncAttempt", c.LastProbeTime.String())
+			}
+			if c.Type == redskyv1beta1.ExperimentDrifted && c.Status == corev1.ConditionTrue {
+				lint.V(vWarn).Info("Experiment definition has drifted from the server, trials will not be created or reported",
+					"message", c.Message, "lastCheck", c.LastProbeTime.String())
+			}
+		}
+
 	}
 
 	// Return the linter to continue walking through the experiment
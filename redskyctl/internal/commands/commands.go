@@ -45,6 +45,7 @@ import (
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/results"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/revoke"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/run"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/test"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/version"
 	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/config"
@@ -73,14 +74,24 @@ func NewRedskyctlCommand() *cobra.Command {
 	rootCmd.AddCommand(authorize_cluster.NewCommand(&authorize_cluster.Options{GeneratorOptions: authorize_cluster.GeneratorOptions{Config: cfg}}))
 	rootCmd.AddCommand(generate.NewCommand(&generate.Options{Config: cfg}))
 	rootCmd.AddCommand(fix.NewCommand(&fix.Options{}))
+	rootCmd.AddCommand(test.NewCommand(&test.Options{}))
 	rootCmd.AddCommand(export.NewCommand(&export.Options{Config: cfg}))
+	rootCmd.AddCommand(export.NewHelmPostRendererCommand(&export.Options{Config: cfg}))
 	rootCmd.AddCommand(run.NewCommand(&run.Options{Config: cfg}))
+	rootCmd.AddCommand(experiments.NewPruneCommand(&experiments.PruneOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewReportCommand(&experiments.ReportOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewLogsCommand(&experiments.LogsOptions{Options: experiments.Options{Config: cfg}, Tail: -1}))
+	rootCmd.AddCommand(experiments.NewUnstickCommand(&experiments.UnstickOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewPauseCommand(&experiments.PauseOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewResumeCommand(&experiments.PauseOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewImportCommand(&experiments.ImportOptions{Options: experiments.Options{Config: cfg}}))
 
 	// Remote Server Commands
 	rootCmd.AddCommand(experiments.NewDeleteCommand(&experiments.DeleteOptions{Options: experiments.Options{Config: cfg}}))
 	rootCmd.AddCommand(experiments.NewGetCommand(&experiments.GetOptions{Options: experiments.Options{Config: cfg}, ChunkSize: 500}))
-	rootCmd.AddCommand(experiments.NewLabelCommand(&experiments.LabelOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewLabelCommand(&experiments.LabelOptions{Options: experiments.Options{Config: cfg}, ChunkSize: 500}))
 	rootCmd.AddCommand(experiments.NewSuggestCommand(&experiments.SuggestOptions{Options: experiments.Options{Config: cfg}}))
+	rootCmd.AddCommand(experiments.NewAnalyzeCommand(&experiments.AnalyzeOptions{Options: experiments.Options{Config: cfg}}))
 	rootCmd.AddCommand(results.NewCommand(&results.Options{Config: cfg}))
 
 	// Administrative Commands
@@ -17,12 +17,17 @@ limitations under the License.
 package docs
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // TODO Add support for fetching Red Sky OpenAPI specification
@@ -55,7 +60,7 @@ func NewCommand(o *Options) *cobra.Command {
 	_ = cmd.MarkFlagDirname("directory")
 	_ = cmd.MarkFlagDirname("source")
 
-	commander.SetFlagValues(cmd, "doc-type", "markdown", "man", "api")
+	commander.SetFlagValues(cmd, "doc-type", "markdown", "man", "api", "schema")
 
 	return cmd
 }
@@ -79,9 +84,35 @@ func (o *Options) docs(cmd *cobra.Command) error {
 			return err
 		}
 
+	case "schema":
+		if err := o.genApplicationSchema(); err != nil {
+			return err
+		}
+
 	default:
 		return fmt.Errorf("unknown documentation type: %s", o.DocType)
 	}
 
 	return nil
 }
+
+// genApplicationSchema writes a JSON schema for application definitions, the descriptions are taken from the
+// doc comments of the API types so editors (e.g. using the YAML language server) can offer documentation
+func (o *Options) genApplicationSchema() error {
+	g, err := newSchemaGenerator(filepath.Join(o.SourcePath, "api", "apps", "v1alpha1"))
+	if err != nil {
+		return err
+	}
+
+	gvk := metav1.GroupVersionKind{
+		Group:   redskyappsv1alpha1.GroupVersion.Group,
+		Version: redskyappsv1alpha1.GroupVersion.Version,
+		Kind:    "Application",
+	}
+	data, err := json.MarshalIndent(g.rootSchema(gvk, &redskyappsv1alpha1.Application{}), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(o.Directory, "application.json"), data, 0644)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/thestormforge/konjure/pkg/konjure"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// jsonSchema is the subset of JSON Schema (plus the Kubernetes and editor extensions) used to describe API types.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	MarkdownDescription  string                 `json:"markdownDescription,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+
+	GroupVersionKind []metav1.GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+	IntOrString      bool                      `json:"x-kubernetes-int-or-string,omitempty"`
+}
+
+// describe sets the description of the schema, the same text is used as Markdown for editors which support it.
+func (s *jsonSchema) describe(text string) {
+	s.Description = text
+	s.MarkdownDescription = text
+}
+
+// schemaGenerator produces JSON schemas for Go types, using the doc comments from the Go source as descriptions.
+type schemaGenerator struct {
+	// pkgPath is the import path of the Go package the doc comments were parsed from
+	pkgPath string
+	// docs are the doc comments for each type, indexed by type name and field name (empty for the type itself)
+	docs map[string]map[string]string
+	// enums are the string constants declared for each type, indexed by type name
+	enums map[string][]string
+	// visiting are the types currently being generated, used to stop recursive types
+	visiting map[reflect.Type]bool
+}
+
+// newSchemaGenerator returns a new schema generator using the Go source in the supplied directory.
+func newSchemaGenerator(dir string) (*schemaGenerator, error) {
+	g := &schemaGenerator{
+		docs:     make(map[string]map[string]string),
+		enums:    make(map[string][]string),
+		visiting: make(map[reflect.Type]bool),
+	}
+
+	fset := token.NewFileSet()
+	notTest := func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fset, dir, notTest, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		for _, t := range doc.New(pkg, "", doc.AllDecls).Types {
+			g.docs[t.Name] = map[string]string{"": commentText(t.Doc)}
+			for _, spec := range t.Decl.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, f := range st.Fields.List {
+					for _, n := range f.Names {
+						g.docs[t.Name][n.Name] = commentText(f.Doc.Text())
+					}
+					if id, ok := f.Type.(*ast.Ident); ok && len(f.Names) == 0 {
+						g.docs[t.Name][id.Name] = commentText(f.Doc.Text())
+					}
+				}
+			}
+
+			for _, c := range t.Consts {
+				for _, spec := range c.Decl.Specs {
+					vs, ok := spec.(*ast.ValueSpec)
+					if !ok {
+						continue
+					}
+					for _, v := range vs.Values {
+						if lit, ok := v.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							if s, err := strconv.Unquote(lit.Value); err == nil {
+								g.enums[t.Name] = append(g.enums[t.Name], s)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// commentText strips code generation markers from a doc comment.
+func commentText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, "+") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+}
+
+var (
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+	durationType    = reflect.TypeOf(metav1.Duration{})
+	timeType        = reflect.TypeOf(metav1.Time{})
+	objectMetaType  = reflect.TypeOf(metav1.ObjectMeta{})
+	typeMetaType    = reflect.TypeOf(metav1.TypeMeta{})
+	resourceType    = reflect.TypeOf(konjure.Resource{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// rootSchema returns the schema of a top-level API object.
+func (g *schemaGenerator) rootSchema(gvk metav1.GroupVersionKind, obj interface{}) *jsonSchema {
+	t := reflect.Indirect(reflect.ValueOf(obj)).Type()
+	g.pkgPath = t.PkgPath()
+	s := g.schema(t)
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.Title = gvk.Kind
+	s.GroupVersionKind = []metav1.GroupVersionKind{gvk}
+	s.Properties["apiVersion"] = &jsonSchema{Type: "string", Enum: []string{gvk.Group + "/" + gvk.Version}}
+	s.Properties["kind"] = &jsonSchema{Type: "string", Enum: []string{gvk.Kind}}
+	s.Required = append([]string{"apiVersion", "kind"}, s.Required...)
+	return s
+}
+
+// schema returns the schema of the supplied Go type.
+func (g *schemaGenerator) schema(t reflect.Type) *jsonSchema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	s := &jsonSchema{}
+	if d := g.docs[t.Name()][""]; d != "" && t.PkgPath() == g.pkgPath {
+		s.describe(d)
+	}
+
+	switch t {
+	case quantityType, intOrStringType:
+		s.AnyOf = []*jsonSchema{{Type: "integer"}, {Type: "string"}}
+		s.IntOrString = true
+		return s
+	case durationType:
+		s.Type = "string"
+		return s
+	case timeType:
+		s.Type = "string"
+		s.Format = "date-time"
+		return s
+	case objectMetaType:
+		s.Type = "object"
+		return s
+	case resourceType:
+		s.AnyOf = []*jsonSchema{{Type: "string"}, {Type: "object"}}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = "string"
+		if t.PkgPath() == g.pkgPath {
+			s.Enum = g.enums[t.Name()]
+		}
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.Type = "string"
+			s.Format = "byte"
+			break
+		}
+		s.Type = "array"
+		s.Items = g.schema(t.Elem())
+	case reflect.Map:
+		s.Type = "object"
+		s.AdditionalProperties = g.schema(t.Elem())
+	case reflect.Struct:
+		// Types like `LatencyGoal` can also be specified using the value of their only (embedded) field
+		if t.NumField() == 1 && t.Field(0).Anonymous && reflect.PtrTo(t).Implements(unmarshalerType) {
+			return g.schema(t.Field(0).Type)
+		}
+
+		s.Type = "object"
+		if g.visiting[t] {
+			return s
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+		g.addProperties(s, t)
+	case reflect.Interface:
+		// Anything is allowed
+	}
+
+	return s
+}
+
+// addProperties adds the exported fields of the supplied struct type to the schema.
+func (g *schemaGenerator) addProperties(s *jsonSchema, t reflect.Type) {
+	if s.Properties == nil {
+		s.Properties = make(map[string]*jsonSchema)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type == typeMetaType {
+			continue
+		}
+
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = strings.Split(tag, ",")[0]
+			opts = strings.TrimPrefix(tag, name)
+		}
+
+		// Inline embedded structs
+		if f.Anonymous && (name == "" || strings.Contains(opts, "inline")) && f.Type.Kind() == reflect.Struct {
+			g.addProperties(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		p := g.schema(f.Type)
+		if d := g.docs[t.Name()][f.Name]; d != "" && t.PkgPath() == g.pkgPath {
+			p.describe(d)
+		}
+		s.Properties[name] = p
+
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplicationSchema(t *testing.T) {
+	g, err := newSchemaGenerator(filepath.Join("..", "..", "..", "..", "api", "apps", "v1alpha1"))
+	require.NoError(t, err)
+
+	s := g.rootSchema(metav1.GroupVersionKind{Group: "apps.redskyops.dev", Version: "v1alpha1", Kind: "Application"}, &redskyappsv1alpha1.Application{})
+	assert.Equal(t, []string{"apps.redskyops.dev/v1alpha1"}, s.Properties["apiVersion"].Enum)
+	assert.Contains(t, s.Required, "kind")
+
+	scenarios := s.Properties["scenarios"]
+	if assert.NotNil(t, scenarios) {
+		assert.Equal(t, "array", scenarios.Type)
+		assert.Equal(t, "The list of scenarios to optimize the application for.", scenarios.Description)
+		assert.Equal(t, "Scenario describes a specific pattern of load to optimize the application for.", scenarios.Items.Description)
+		assert.Contains(t, scenarios.Items.Properties, "stormforger")
+	}
+
+	goal := s.Properties["objectives"].Items.Properties["goals"].Items
+	assert.Equal(t, "object", goal.Type)
+	assert.True(t, goal.Properties["max"].IntOrString)
+	assert.NotContains(t, goal.Properties, "Implemented")
+	if latency := goal.Properties["latency"]; assert.NotNil(t, latency) {
+		assert.Equal(t, "string", latency.Type)
+		assert.Contains(t, latency.Enum, "percentile_95")
+		assert.Equal(t, "Latency is used to optimize the responsiveness of an application.", latency.Description)
+	}
+
+	credential := s.Properties["credentials"].Items
+	assert.Equal(t, []string{"secretKeyRef"}, credential.Required)
+	assert.Equal(t, []string{"trialJob", "metrics"}, credential.Properties["usage"].Enum)
+}
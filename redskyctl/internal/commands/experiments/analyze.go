@@ -0,0 +1,430 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// AnalyzeOptions includes the configuration for analyzing the parameter space of an experiment
+type AnalyzeOptions struct {
+	Options
+
+	Metric  string
+	Buckets int
+	Output  string
+}
+
+// NewAnalyzeCommand creates a new analyze command
+func NewAnalyzeCommand(o *AnalyzeOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze NAME",
+		Short: "Analyze the parameter space",
+		Long:  "Rank the parameters by importance and summarize the effect of each parameter on the metrics using the completed trials of an experiment",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = []name{{Type: typeExperiment, Name: args[0]}}
+			commander.SetStreams(&o.IOStreams, cmd)
+			return commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+		},
+		RunE: commander.WithContextE(o.analyze),
+	}
+
+	cmd.Flags().StringVar(&o.Metric, "metric", "", "only analyze the named `metric`")
+	cmd.Flags().IntVar(&o.Buckets, "buckets", 4, "the `number` of ranges used to summarize numeric parameters")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text", "output `format`. One of: text|json")
+
+	commander.SetFlagValues(cmd, "output", "text", "json")
+
+	return cmd
+}
+
+func (o *AnalyzeOptions) analyze(ctx context.Context) error {
+	exp, err := o.ExperimentsAPI.GetExperimentByName(ctx, o.Names[0].experimentName())
+	if err != nil {
+		return err
+	}
+
+	var l experimentsv1alpha1.TrialList
+	if exp.TrialsURL != "" {
+		q := &experimentsv1alpha1.TrialListQuery{Status: []experimentsv1alpha1.TrialStatus{experimentsv1alpha1.TrialCompleted}}
+		if l, err = o.ExperimentsAPI.GetAllTrials(ctx, exp.TrialsURL, q); err != nil {
+			return err
+		}
+	}
+
+	var metrics []string
+	for i := range exp.Metrics {
+		if o.Metric == "" || o.Metric == exp.Metrics[i].Name {
+			metrics = append(metrics, exp.Metrics[i].Name)
+		}
+	}
+	if len(metrics) == 0 {
+		return fmt.Errorf("unknown metric %q", o.Metric)
+	}
+
+	var result []parameterAnalysis
+	for _, m := range metrics {
+		var pas []parameterAnalysis
+		for i := range exp.Parameters {
+			pas = append(pas, analyzeParameter(exp.Parameters[i].Name, m, l.Trials, o.Buckets))
+		}
+
+		// Present the most important parameters first
+		sort.SliceStable(pas, func(i, j int) bool { return pas[i].Importance > pas[j].Importance })
+		result = append(result, pas...)
+	}
+
+	report := &analysisReport{
+		Ranking:    rankParameters(result),
+		Parameters: result,
+	}
+
+	switch strings.ToLower(o.Output) {
+	case "json":
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "text", "":
+		if err := renderRanking(o.Out, report.Ranking); err != nil {
+			return err
+		}
+		return renderAnalysis(o.Out, report.Parameters)
+	default:
+		return fmt.Errorf("unknown output format %q", o.Output)
+	}
+}
+
+// analysisReport is the complete analysis of an experiment.
+type analysisReport struct {
+	// The parameters ordered from most to least important.
+	Ranking []parameterRank `json:"ranking"`
+	// The analysis of each parameter and metric combination.
+	Parameters []parameterAnalysis `json:"parameters"`
+}
+
+// parameterRank is the overall importance of a parameter.
+type parameterRank struct {
+	// The parameter name.
+	Parameter string `json:"parameter"`
+	// The importance score of the parameter across all metrics, from 0 to 1.
+	Score float64 `json:"score"`
+}
+
+// parameterAnalysis describes the relationship between a single parameter and a single metric.
+type parameterAnalysis struct {
+	// The parameter name.
+	Parameter string `json:"parameter"`
+	// The metric name.
+	Metric string `json:"metric"`
+	// The scatter data of parameter assignments and metric values.
+	Points []analysisPoint `json:"points"`
+	// The partial dependence of the metric on the parameter, i.e. the average metric value for ranges of the parameter.
+	PartialDependence []analysisBucket `json:"partialDependence"`
+	// The spread of the partial dependence as a fraction of the observed metric range, from 0 to 1.
+	Importance float64 `json:"importance"`
+	// The correlation between the parameter and the metric: the Pearson correlation coefficient for numeric
+	// parameters or the (non-negative) correlation ratio for categorical parameters.
+	Correlation float64 `json:"correlation"`
+}
+
+// analysisPoint is a single observation of a metric value for a parameter assignment.
+type analysisPoint struct {
+	X        float64 `json:"x"`
+	Category string  `json:"category,omitempty"`
+	Y        float64 `json:"y"`
+}
+
+// analysisBucket is the average metric value for a range (or category) of parameter assignments.
+type analysisBucket struct {
+	Label string  `json:"label"`
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+}
+
+// analyzeParameter computes the scatter data and partial dependence of a metric on a parameter.
+func analyzeParameter(parameter, metric string, trials []experimentsv1alpha1.TrialItem, buckets int) parameterAnalysis {
+	pa := parameterAnalysis{Parameter: parameter, Metric: metric}
+
+	// Collect the scatter data
+	categorical := false
+	for i := range trials {
+		y, ok := trialValue(&trials[i], metric)
+		if !ok {
+			continue
+		}
+		for _, a := range trials[i].Assignments {
+			if a.ParameterName != parameter {
+				continue
+			}
+			p := analysisPoint{Y: y}
+			if a.Value.IsString {
+				p.Category, categorical = a.Value.String(), true
+			} else if x, err := strconv.ParseFloat(a.Value.String(), 64); err == nil {
+				p.X = x
+			}
+			pa.Points = append(pa.Points, p)
+		}
+	}
+	if len(pa.Points) == 0 {
+		return pa
+	}
+
+	// Group the points into buckets, numeric buckets are ordered by range and categories by name
+	minX, maxX, minY, maxY := pointRange(pa.Points)
+	if buckets < 1 {
+		buckets = 1
+	}
+	var keys []string
+	index := make(map[string]*analysisBucket)
+	for _, p := range pa.Points {
+		key, label := p.Category, p.Category
+		if !categorical {
+			b := 0
+			if maxX > minX {
+				b = int(math.Min(float64(buckets-1), math.Floor((p.X-minX)/(maxX-minX)*float64(buckets))))
+			}
+			lo := minX + (maxX-minX)*float64(b)/float64(buckets)
+			hi := minX + (maxX-minX)*float64(b+1)/float64(buckets)
+			key = fmt.Sprintf("%08d", b)
+			label = strconv.FormatFloat(lo, 'g', 4, 64) + " to " + strconv.FormatFloat(hi, 'g', 4, 64)
+		}
+
+		ab, ok := index[key]
+		if !ok {
+			ab = &analysisBucket{Label: label}
+			index[key] = ab
+			keys = append(keys, key)
+		}
+		ab.Mean = (ab.Mean*float64(ab.Count) + p.Y) / float64(ab.Count+1)
+		ab.Count++
+	}
+	sort.Strings(keys)
+
+	minMean, maxMean := math.Inf(1), math.Inf(-1)
+	for _, key := range keys {
+		ab := index[key]
+		pa.PartialDependence = append(pa.PartialDependence, *ab)
+		minMean, maxMean = math.Min(minMean, ab.Mean), math.Max(maxMean, ab.Mean)
+	}
+	if maxY > minY {
+		pa.Importance = (maxMean - minMean) / (maxY - minY)
+	}
+
+	if categorical {
+		pa.Correlation = correlationRatio(pa.Points, pa.PartialDependence)
+	} else {
+		pa.Correlation = pearsonCorrelation(pa.Points)
+	}
+
+	return pa
+}
+
+// rankParameters orders the parameters by their average importance and correlation across all metrics.
+func rankParameters(analysis []parameterAnalysis) []parameterRank {
+	var result []parameterRank
+	index := make(map[string]int)
+	counts := make(map[string]int)
+	for _, pa := range analysis {
+		i, ok := index[pa.Parameter]
+		if !ok {
+			i = len(result)
+			index[pa.Parameter] = i
+			result = append(result, parameterRank{Parameter: pa.Parameter})
+		}
+		result[i].Score += (pa.Importance + math.Abs(pa.Correlation)) / 2
+		counts[pa.Parameter]++
+	}
+
+	for i := range result {
+		result[i].Score /= float64(counts[result[i].Parameter])
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	return result
+}
+
+// pearsonCorrelation returns the correlation coefficient of the numeric scatter data.
+func pearsonCorrelation(points []analysisPoint) float64 {
+	n := float64(len(points))
+	var sx, sy, sxx, syy, sxy float64
+	for _, p := range points {
+		sx, sy = sx+p.X, sy+p.Y
+		sxx, syy, sxy = sxx+p.X*p.X, syy+p.Y*p.Y, sxy+p.X*p.Y
+	}
+
+	d := math.Sqrt(n*sxx-sx*sx) * math.Sqrt(n*syy-sy*sy)
+	if d == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / d
+}
+
+// correlationRatio returns the fraction of the metric variance explained by the parameter categories.
+func correlationRatio(points []analysisPoint, categories []analysisBucket) float64 {
+	var mean float64
+	for _, p := range points {
+		mean += p.Y / float64(len(points))
+	}
+
+	var total, between float64
+	for _, p := range points {
+		total += (p.Y - mean) * (p.Y - mean)
+	}
+	for _, c := range categories {
+		between += float64(c.Count) * (c.Mean - mean) * (c.Mean - mean)
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return math.Sqrt(between / total)
+}
+
+// renderRanking produces an ASCII rendering of the parameter importance ranking.
+func renderRanking(w io.Writer, ranking []parameterRank) error {
+	if _, err := fmt.Fprintln(w, "Parameter importance:"); err != nil {
+		return err
+	}
+
+	width := 0
+	for _, r := range ranking {
+		if len(r.Parameter) > width {
+			width = len(r.Parameter)
+		}
+	}
+
+	for i, r := range ranking {
+		bar := strings.Repeat("#", int(math.Round(r.Score*20)))
+		if _, err := fmt.Fprintf(w, "  %2d. %-*s | %-20s %.2f\n", i+1, width, r.Parameter, bar, r.Score); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// renderAnalysis produces an ASCII rendering of the parameter analysis.
+func renderAnalysis(w io.Writer, analysis []parameterAnalysis) error {
+	const barWidth = 40
+	metric := ""
+	for _, pa := range analysis {
+		if pa.Metric != metric {
+			metric = pa.Metric
+			if _, err := fmt.Fprintf(w, "Metric: %s\n", metric); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "\n  %s (importance %.2f, %d trials)\n", pa.Parameter, pa.Importance, len(pa.Points)); err != nil {
+			return err
+		}
+
+		width := 0
+		maxMean := 0.0
+		for _, ab := range pa.PartialDependence {
+			if len(ab.Label) > width {
+				width = len(ab.Label)
+			}
+			maxMean = math.Max(maxMean, math.Abs(ab.Mean))
+		}
+
+		for _, ab := range pa.PartialDependence {
+			bar := 0
+			if maxMean > 0 {
+				bar = int(math.Round(math.Abs(ab.Mean) / maxMean * barWidth))
+			}
+			if _, err := fmt.Fprintf(w, "    %-*s | %-*s %g (n=%d)\n", width, ab.Label, barWidth, strings.Repeat("#", bar), ab.Mean, ab.Count); err != nil {
+				return err
+			}
+		}
+
+		if err := renderScatter(w, pa.Points, barWidth, 8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderScatter produces an ASCII scatter plot of numeric parameter assignments (horizontal) against metric values (vertical).
+func renderScatter(w io.Writer, points []analysisPoint, cols, rows int) error {
+	if len(points) == 0 || points[0].Category != "" {
+		return nil
+	}
+
+	grid := make([][]byte, rows)
+	for i := range grid {
+		grid[i] = []byte(strings.Repeat(" ", cols))
+	}
+
+	minX, maxX, minY, maxY := pointRange(points)
+	for _, p := range points {
+		c, r := 0, rows-1
+		if maxX > minX {
+			c = int(math.Round((p.X - minX) / (maxX - minX) * float64(cols-1)))
+		}
+		if maxY > minY {
+			r = rows - 1 - int(math.Round((p.Y-minY)/(maxY-minY)*float64(rows-1)))
+		}
+		grid[r][c] = '*'
+	}
+
+	if _, err := fmt.Fprintf(w, "\n    %g\n", maxY); err != nil {
+		return err
+	}
+	for _, row := range grid {
+		if _, err := fmt.Fprintf(w, "    |%s\n", row); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "    +%s\n    %g (%g to %g)\n", strings.Repeat("-", cols), minY, minX, maxX)
+	return err
+}
+
+// trialValue returns the value of the named metric from a trial.
+func trialValue(t *experimentsv1alpha1.TrialItem, metric string) (float64, bool) {
+	for _, v := range t.Values {
+		if v.MetricName == metric {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// pointRange returns the bounds of the scatter data.
+func pointRange(points []analysisPoint) (minX, maxX, minY, maxY float64) {
+	minX, maxX, minY, maxY = math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	return minX, maxX, minY, maxY
+}
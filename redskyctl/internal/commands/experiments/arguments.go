@@ -65,32 +65,14 @@ func Completion(ctx context.Context, api experimentsv1alpha1.API, args []string,
 
 func experimentNames(ctx context.Context, api experimentsv1alpha1.API, ns names) (completions []string, directive cobra.ShellCompDirective) {
 	directive = cobra.ShellCompDirectiveNoFileComp
-	addPage := func(list *experimentsv1alpha1.ExperimentList) {
-		for i := range list.Experiments {
-			n := list.Experiments[i].Name()
-			if ns.suggest(n) {
-				completions = append(completions, n)
-			}
-		}
-	}
 
-	// Get the first page of experiments
-	l, err := api.GetAllExperiments(ctx, nil)
-	if err != nil {
-		return
-	}
-	addPage(&l)
-	next := l.Next
-
-	// Get the rest of the experiments
-	for next != "" {
-		n, err := api.GetAllExperimentsByPage(ctx, next)
-		if err != nil {
-			break
+	// Errors are ignored, any completions from pages that were fetched are still returned
+	_ = forEachExperiment(ctx, api, nil, func(item *experimentsv1alpha1.ExperimentItem) error {
+		if n := item.Name(); ns.suggest(n) {
+			completions = append(completions, n)
 		}
-		addPage(&n)
-		next = n.Next
-	}
+		return nil
+	})
 
 	return
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package experiments
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"regexp"
@@ -96,6 +97,92 @@ func hasTrialNumber(t *experimentsv1alpha1.TrialItem, nums []int64) bool {
 	return false
 }
 
+// forEachExperimentPage streams the experiment list one page at a time. The next page is not requested until the
+// callback has returned for the current page, the query limit controls the page size.
+func forEachExperimentPage(ctx context.Context, api experimentsv1alpha1.API, q *experimentsv1alpha1.ExperimentListQuery, f func(*experimentsv1alpha1.ExperimentList) error) error {
+	l, err := api.GetAllExperiments(ctx, q)
+	for {
+		if err != nil {
+			return err
+		}
+
+		next := l.Next
+		if err := f(&l); err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		l, err = api.GetAllExperimentsByPage(ctx, next)
+	}
+}
+
+// forEachExperiment invokes the callback for each experiment, one page at a time.
+func forEachExperiment(ctx context.Context, api experimentsv1alpha1.API, q *experimentsv1alpha1.ExperimentListQuery, f func(*experimentsv1alpha1.ExperimentItem) error) error {
+	return forEachExperimentPage(ctx, api, q, func(l *experimentsv1alpha1.ExperimentList) error {
+		for i := range l.Experiments {
+			if err := f(&l.Experiments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEachTrialPage invokes the callback with the trials of the experiment, at most `chunkSize` trials at a time (all
+// of the trials at once if the chunk size is not positive). The next chunk is not processed until the callback has
+// returned for the current chunk. The Experiments API does not page trial lists, so while the full list is fetched
+// with a single request, the trials of each chunk are released as soon as the callback returns.
+func ForEachTrialPage(ctx context.Context, api experimentsv1alpha1.API, exp *experimentsv1alpha1.Experiment, q *experimentsv1alpha1.TrialListQuery, chunkSize int, f func(*experimentsv1alpha1.TrialList) error) error {
+	if exp.TrialsURL == "" {
+		return nil
+	}
+
+	l, err := api.GetAllTrials(ctx, exp.TrialsURL, q)
+	if err != nil {
+		return err
+	}
+
+	if chunkSize <= 0 || chunkSize > len(l.Trials) {
+		chunkSize = len(l.Trials)
+	}
+
+	for start := 0; start < len(l.Trials); start += chunkSize {
+		end := start + chunkSize
+		if end > len(l.Trials) {
+			end = len(l.Trials)
+		}
+
+		page := experimentsv1alpha1.TrialList{Experiment: exp, Trials: l.Trials[start:end:end]}
+		for i := range page.Trials {
+			page.Trials[i].Experiment = exp
+		}
+
+		if err := f(&page); err != nil {
+			return err
+		}
+
+		for i := start; i < end; i++ {
+			l.Trials[i] = experimentsv1alpha1.TrialItem{}
+		}
+	}
+	return nil
+}
+
+// ForEachTrial invokes the callback for each trial of the experiment, at most `chunkSize` trials are retained at a time.
+func ForEachTrial(ctx context.Context, api experimentsv1alpha1.API, exp *experimentsv1alpha1.Experiment, q *experimentsv1alpha1.TrialListQuery, chunkSize int, f func(*experimentsv1alpha1.TrialItem) error) error {
+	return ForEachTrialPage(ctx, api, exp, q, chunkSize, func(l *experimentsv1alpha1.TrialList) error {
+		for i := range l.Trials {
+			if err := f(&l.Trials[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // name is construct for identifying an object in the Experiments API
 type name struct {
 	// Type is the normalized type name being named
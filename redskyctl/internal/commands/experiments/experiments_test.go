@@ -17,10 +17,14 @@ limitations under the License.
 package experiments
 
 import (
+	"bytes"
+	"context"
 	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1/numstr"
 )
 
 func TestParseNames(t *testing.T) {
@@ -145,6 +149,16 @@ func TestParseNames(t *testing.T) {
 				{Type: typeTrial, Name: "foo-2", Number: -1},
 			},
 		},
+		{
+			// An explicit slash separator disambiguates experiment names ending in a number
+			desc: "ExperimentHasNumber",
+			args: []string{"trial", "foo-2/3", "foo-2-3", "trial/foo-2/004"},
+			names: []name{
+				{Type: typeTrial, Name: "foo-2", Number: 3},
+				{Type: typeTrial, Name: "foo-2", Number: 3},
+				{Type: typeTrial, Name: "foo-2", Number: 4},
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
@@ -201,3 +215,168 @@ func TestSortByField(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeParameter(t *testing.T) {
+	var trials []experimentsv1alpha1.TrialItem
+	for _, d := range []struct {
+		cpu     int64
+		cache   string
+		latency float64
+	}{
+		{cpu: 100, cache: "off", latency: 400},
+		{cpu: 200, cache: "on", latency: 300},
+		{cpu: 300, cache: "off", latency: 200},
+		{cpu: 400, cache: "on", latency: 100},
+	} {
+		var ti experimentsv1alpha1.TrialItem
+		ti.Assignments = []experimentsv1alpha1.Assignment{
+			{ParameterName: "cpu", Value: numstr.FromInt64(d.cpu)},
+			{ParameterName: "cache", Value: numstr.FromString(d.cache)},
+		}
+		ti.Values = []experimentsv1alpha1.Value{{MetricName: "latency", Value: d.latency}}
+		trials = append(trials, ti)
+	}
+
+	cpu := analyzeParameter("cpu", "latency", trials, 2)
+	assert.Len(t, cpu.Points, 4)
+	assert.Equal(t, []analysisBucket{
+		{Label: "100 to 250", Count: 2, Mean: 350},
+		{Label: "250 to 400", Count: 2, Mean: 150},
+	}, cpu.PartialDependence)
+	assert.InDelta(t, 0.6667, cpu.Importance, 0.0001)
+
+	cache := analyzeParameter("cache", "latency", trials, 2)
+	assert.Equal(t, []analysisBucket{
+		{Label: "off", Count: 2, Mean: 300},
+		{Label: "on", Count: 2, Mean: 200},
+	}, cache.PartialDependence)
+	assert.InDelta(t, 0.3333, cache.Importance, 0.0001)
+	assert.InDelta(t, -1, cpu.Correlation, 0.0001)
+	assert.InDelta(t, 0.4472, cache.Correlation, 0.0001)
+
+	ranking := rankParameters([]parameterAnalysis{cache, cpu})
+	if assert.Len(t, ranking, 2) {
+		assert.Equal(t, "cpu", ranking[0].Parameter)
+		assert.InDelta(t, 0.8333, ranking[0].Score, 0.0001)
+		assert.Equal(t, "cache", ranking[1].Parameter)
+	}
+
+	var buf bytes.Buffer
+	if assert.NoError(t, renderAnalysis(&buf, []parameterAnalysis{cpu, cache})) {
+		assert.Contains(t, buf.String(), "cpu (importance 0.67, 4 trials)")
+		assert.Contains(t, buf.String(), "off | ")
+	}
+}
+
+// pagedExperimentsAPI serves experiment list pages indexed by URL, the first page has an empty URL
+type pagedExperimentsAPI struct {
+	experimentsv1alpha1.API
+	pages    map[string]experimentsv1alpha1.ExperimentList
+	requests []string
+}
+
+func (api *pagedExperimentsAPI) GetAllExperiments(ctx context.Context, q *experimentsv1alpha1.ExperimentListQuery) (experimentsv1alpha1.ExperimentList, error) {
+	return api.GetAllExperimentsByPage(ctx, "")
+}
+
+func (api *pagedExperimentsAPI) GetAllExperimentsByPage(_ context.Context, u string) (experimentsv1alpha1.ExperimentList, error) {
+	api.requests = append(api.requests, u)
+	return api.pages[u], nil
+}
+
+func TestForEachExperiment(t *testing.T) {
+	page := func(next string, names ...string) experimentsv1alpha1.ExperimentList {
+		l := experimentsv1alpha1.ExperimentList{}
+		l.Next = next
+		for _, n := range names {
+			l.Experiments = append(l.Experiments, experimentsv1alpha1.ExperimentItem{Experiment: experimentsv1alpha1.Experiment{DisplayName: n}})
+		}
+		return l
+	}
+
+	api := &pagedExperimentsAPI{pages: map[string]experimentsv1alpha1.ExperimentList{
+		"":       page("page-2", "a", "b"),
+		"page-2": page("page-3", "c"),
+		"page-3": page("", "d"),
+	}}
+
+	// Stop part way through the second page, the last page should never be requested
+	var names []string
+	err := forEachExperiment(context.Background(), api, nil, func(item *experimentsv1alpha1.ExperimentItem) error {
+		names = append(names, item.DisplayName)
+		if item.DisplayName == "c" {
+			return context.Canceled
+		}
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, []string{"", "page-2"}, api.requests)
+}
+
+// trialsAPI serves a single trial list
+type trialsAPI struct {
+	experimentsv1alpha1.API
+	trials experimentsv1alpha1.TrialList
+}
+
+func (api *trialsAPI) GetAllTrials(context.Context, string, *experimentsv1alpha1.TrialListQuery) (experimentsv1alpha1.TrialList, error) {
+	l := api.trials
+	l.Trials = append([]experimentsv1alpha1.TrialItem(nil), api.trials.Trials...)
+	return l, nil
+}
+
+func TestForEachTrialPage(t *testing.T) {
+	api := &trialsAPI{}
+	for i := int64(1); i <= 5; i++ {
+		api.trials.Trials = append(api.trials.Trials, experimentsv1alpha1.TrialItem{Number: i})
+	}
+	exp := &experimentsv1alpha1.Experiment{TrialsURL: "trials"}
+
+	cases := []struct {
+		desc      string
+		chunkSize int
+		expected  [][]int64
+	}{
+		{
+			desc:     "all",
+			expected: [][]int64{{1, 2, 3, 4, 5}},
+		},
+		{
+			desc:      "chunked",
+			chunkSize: 2,
+			expected:  [][]int64{{1, 2}, {3, 4}, {5}},
+		},
+		{
+			desc:      "large chunk",
+			chunkSize: 500,
+			expected:  [][]int64{{1, 2, 3, 4, 5}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var pages [][]int64
+			var previous *experimentsv1alpha1.TrialList
+			err := ForEachTrialPage(context.Background(), api, exp, nil, c.chunkSize, func(l *experimentsv1alpha1.TrialList) error {
+				// The trials of the previous page are released before the next page
+				if previous != nil {
+					for i := range previous.Trials {
+						assert.Zero(t, previous.Trials[i].Number)
+					}
+				}
+				previous = l
+
+				var numbers []int64
+				for i := range l.Trials {
+					assert.Equal(t, exp, l.Trials[i].Experiment)
+					numbers = append(numbers, l.Trials[i].Number)
+				}
+				pages = append(pages, numbers)
+				return nil
+			})
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, pages)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
@@ -139,19 +140,32 @@ func (o *GetOptions) getExperiments(ctx context.Context, names []experimentsv1al
 }
 
 func (o *GetOptions) getExperimentList(ctx context.Context, q *experimentsv1alpha1.ExperimentListQuery) error {
-	// Get all the experiments one page at a time
-	l, err := o.ExperimentsAPI.GetAllExperiments(ctx, q)
-	if err != nil {
-		return err
-	}
+	// Print each page as it arrives unless sorting or the output format requires the full list
+	next, streaming := commander.ContinuationPrinter(o.Printer)
+	streaming = streaming && o.SortBy == ""
+
+	printer, printed := o.Printer, false
+	l := experimentsv1alpha1.ExperimentList{}
+	if err := forEachExperimentPage(ctx, o.ExperimentsAPI, q, func(page *experimentsv1alpha1.ExperimentList) error {
+		if !streaming {
+			l.Experiments = append(l.Experiments, page.Experiments...)
+			return nil
+		}
 
-	for l.Next != "" {
-		n, err := o.ExperimentsAPI.GetAllExperimentsByPage(ctx, l.Next)
-		if err != nil {
+		if err := o.filterAndSortExperiments(page); err != nil || len(page.Experiments) == 0 {
+			return err
+		}
+		if err := printer.PrintObj(page, o.Out); err != nil {
 			return err
 		}
-		l.Next = n.Next
-		l.Experiments = append(l.Experiments, n.Experiments...)
+		printer, printed = next, true
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if printed {
+		return nil
 	}
 
 	if err := o.filterAndSortExperiments(&l); err != nil {
@@ -171,18 +185,14 @@ func (o *GetOptions) getTrials(ctx context.Context, numbers map[experimentsv1alp
 			return err
 		}
 
-		// Get the trials
-		tl, err := o.ExperimentsAPI.GetAllTrials(ctx, exp.TrialsURL, o.trialListQuery())
-		if err != nil {
-			return err
-		}
-
-		for i := range tl.Trials {
-			if hasTrialNumber(&tl.Trials[i], nums) {
-				t := tl.Trials[i]
-				t.Experiment = &exp
-				l.Trials = append(l.Trials, t)
+		// Only keep the requested trials
+		if err := ForEachTrial(ctx, o.ExperimentsAPI, &exp, o.trialListQuery(), o.ChunkSize, func(t *experimentsv1alpha1.TrialItem) error {
+			if hasTrialNumber(t, nums) {
+				l.Trials = append(l.Trials, *t)
 			}
+			return nil
+		}); err != nil {
+			return err
 		}
 	}
 
@@ -205,19 +215,32 @@ func (o *GetOptions) getTrialList(ctx context.Context, name experimentsv1alpha1.
 		return err
 	}
 
-	// Fetch the trial data
-	var l experimentsv1alpha1.TrialList
-	if exp.TrialsURL != "" {
-		l, err = o.ExperimentsAPI.GetAllTrials(ctx, exp.TrialsURL, q)
-		if err != nil {
-			return err
+	// Print each chunk of trials as it is processed unless sorting or the output format requires the full list
+	next, streaming := commander.ContinuationPrinter(o.Printer)
+	streaming = streaming && o.SortBy == ""
+
+	printer, printed := o.Printer, false
+	l := experimentsv1alpha1.TrialList{Experiment: &exp}
+	if err := ForEachTrialPage(ctx, o.ExperimentsAPI, &exp, q, o.ChunkSize, func(page *experimentsv1alpha1.TrialList) error {
+		if !streaming {
+			l.Trials = append(l.Trials, page.Trials...)
+			return nil
 		}
 
-		// Store a back reference to the experiment on the list and every item in it
-		l.Experiment = &exp
-		for i := range l.Trials {
-			l.Trials[i].Experiment = &exp
+		if err := o.filterAndSortTrials(page); err != nil || len(page.Trials) == 0 {
+			return err
+		}
+		if err := printer.PrintObj(page, o.Out); err != nil {
+			return err
 		}
+		printer, printed = next, true
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if printed {
+		return nil
 	}
 
 	if err := o.filterAndSortTrials(&l); err != nil {
@@ -268,12 +291,23 @@ func (o *GetOptions) filterAndSortTrials(l *experimentsv1alpha1.TrialList) error
 
 	// If sorting was requested, sort using maps with all the sortable keys
 	if o.SortBy != "" {
-		sort.Slice(l.Trials, sortByField(o.SortBy, func(i int) interface{} { return sortableTrialData(&l.Trials[i]) }))
+		SortTrials(l.Trials, o.SortBy)
 	}
 
 	return nil
 }
 
+// SortTrials sorts the trials using a JSONPath expression evaluated against the same schema used for `--sort-by`,
+// e.g. "values.latency.value"; a leading "-" reverses the order.
+func SortTrials(trials []experimentsv1alpha1.TrialItem, sortBy string) {
+	less := sortByField(strings.TrimPrefix(sortBy, "-"), func(i int) interface{} { return sortableTrialData(&trials[i]) })
+	if strings.HasPrefix(sortBy, "-") {
+		sort.SliceStable(trials, func(i, j int) bool { return less(j, i) })
+		return
+	}
+	sort.SliceStable(trials, less)
+}
+
 // sortableTrialData slightly modifies the schema of the trial item to make it easier to specify sort orders
 func sortableTrialData(item *experimentsv1alpha1.TrialItem) map[string]interface{} {
 	assignments := make(map[string]interface{}, len(item.Assignments))
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/server"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// ImportOptions includes the configuration for importing an experiment from the server into the cluster
+type ImportOptions struct {
+	Options
+
+	// Filename is an optional experiment manifest used to supply the configuration which is not stored on the server
+	Filename string
+	// Namespace is the namespace to import the experiment into, the current namespace is used if empty
+	Namespace string
+	// DryRun prints the experiment instead of applying it to the cluster
+	DryRun bool
+}
+
+// NewImportCommand creates a new import command
+func NewImportCommand(o *ImportOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import EXPERIMENT_NAME",
+		Short: "Import an experiment from the server",
+		Long: "Import an experiment definition from the remote server and apply it to the cluster. The server does not " +
+			"store patches, metric queries or the trial template, an existing experiment manifest is required to supply them " +
+			"unless the experiment is only printed.",
+
+		Annotations: map[string]string{
+			commander.PrinterAllowedFormats: "json,yaml",
+			commander.PrinterOutputFormat:   "yaml",
+			commander.PrinterHideStatus:     "true",
+		},
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = []name{{Type: typeExperiment, Name: args[0], Number: -1}}
+			commander.SetStreams(&o.IOStreams, cmd)
+			return commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+		},
+		RunE: commander.WithContextE(o.importExperiment),
+	}
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "`file` containing the experiment manifest to import into")
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "import the experiment into the specified `namespace`")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "print the experiment instead of applying it")
+
+	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+
+	commander.SetKubePrinter(&o.Printer, cmd, nil)
+
+	return cmd
+}
+
+func (o *ImportOptions) importExperiment(ctx context.Context) error {
+	// Without a manifest there is nothing to patch or measure, the experiment could not run any trials
+	if o.Filename == "" && !o.DryRun {
+		return fmt.Errorf("an experiment manifest is required to apply an imported experiment, specify a file or use --dry-run")
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	if o.Filename != "" {
+		r, err := o.IOStreams.OpenFile(o.Filename)
+		if err != nil {
+			return err
+		}
+		if err := commander.NewResourceReader().ReadInto(r, exp); err != nil {
+			return err
+		}
+	}
+
+	ee, err := o.ExperimentsAPI.GetExperimentByName(ctx, experimentsv1alpha1.NewExperimentName(o.Names[0].Name))
+	if err != nil {
+		return err
+	}
+
+	exp.Name = o.Names[0].Name
+	if o.Namespace != "" {
+		exp.Namespace = o.Namespace
+	}
+	if err := server.ToClusterExperiment(exp, &ee); err != nil {
+		return err
+	}
+
+	// The baseline is only recorded on the server as a labeled trial
+	q := &experimentsv1alpha1.TrialListQuery{
+		Status: []experimentsv1alpha1.TrialStatus{experimentsv1alpha1.TrialStaged, experimentsv1alpha1.TrialActive, experimentsv1alpha1.TrialCompleted, experimentsv1alpha1.TrialFailed},
+	}
+	if err := ForEachTrial(ctx, o.ExperimentsAPI, &ee, q, 0, func(t *experimentsv1alpha1.TrialItem) error {
+		if t.Labels["baseline"] == "true" {
+			server.ToClusterBaseline(exp, &t.TrialAssignments)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		return o.Printer.PrintObj(exp, o.Out)
+	}
+
+	// The experiment must be serialized with type information for kubectl
+	exp.APIVersion = redskyv1beta1.GroupVersion.String()
+	exp.Kind = "Experiment"
+	data, err := json.Marshal(exp)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := o.Config.Kubectl(ctx, "apply", "-f", "-")
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = o.Out
+	cmd.Stderr = o.ErrOut
+	return cmd.Run()
+}
@@ -32,6 +32,8 @@ type LabelOptions struct {
 
 	// Labels to apply
 	Labels map[string]string
+	// ChunkSize is the number of trials processed at a time
+	ChunkSize int
 }
 
 // NewLabelCommand creates a new label command
@@ -53,6 +55,8 @@ func NewLabelCommand(o *LabelOptions) *cobra.Command {
 		RunE: commander.WithContextE(o.label),
 	}
 
+	cmd.Flags().IntVar(&o.ChunkSize, "chunk-size", o.ChunkSize, "fetch large lists in chunks rather then all at once")
+
 	o.Printer = &verbPrinter{verb: "labeled"}
 
 	return cmd
@@ -130,24 +134,18 @@ func (o *LabelOptions) labelTrials(ctx context.Context, numbers map[experimentsv
 
 		// Note that you can only label completed trials
 		q := &experimentsv1alpha1.TrialListQuery{Status: []experimentsv1alpha1.TrialStatus{experimentsv1alpha1.TrialCompleted}}
-		tl, err := o.ExperimentsAPI.GetAllTrials(ctx, exp.TrialsURL, q)
-		if err != nil {
-			return err
-		}
-
 		var labeled int
-		for i := range tl.Trials {
-			if hasTrialNumber(&tl.Trials[i], nums) {
-				t := tl.Trials[i]
-				t.Experiment = &exp
-				if err := o.ExperimentsAPI.LabelTrial(ctx, t.LabelsURL, experimentsv1alpha1.TrialLabels{Labels: o.Labels}); err != nil {
-					return err
-				}
-				if err := o.Printer.PrintObj(&t, o.Out); err != nil {
-					return err
-				}
-				labeled++
+		if err := ForEachTrial(ctx, o.ExperimentsAPI, &exp, q, o.ChunkSize, func(t *experimentsv1alpha1.TrialItem) error {
+			if !hasTrialNumber(t, nums) {
+				return nil
+			}
+			if err := o.ExperimentsAPI.LabelTrial(ctx, t.LabelsURL, experimentsv1alpha1.TrialLabels{Labels: o.Labels}); err != nil {
+				return err
 			}
+			labeled++
+			return o.Printer.PrintObj(t, o.Out)
+		}); err != nil {
+			return err
 		}
 
 		if len(nums) != labeled {
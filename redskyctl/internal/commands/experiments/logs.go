@@ -0,0 +1,168 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LogsOptions includes the configuration for displaying the logs of a trial
+type LogsOptions struct {
+	Options
+
+	// Namespace is the namespace of the trial, the current namespace is used if empty
+	Namespace string
+	// Follow streams the logs of every container as they are written
+	Follow bool
+	// Tail is the number of recent lines to display from each container, negative values display everything
+	Tail int
+}
+
+// NewLogsCommand creates a new logs command
+func NewLogsCommand(o *LogsOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs TRIAL_NAME",
+		Short: "Display trial logs",
+		Long:  "Display the logs of the trial job and setup task containers of a trial in the cluster",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = []name{{Type: typeTrial, Name: args[0], Number: -1}}
+			commander.SetStreams(&o.IOStreams, cmd)
+			return nil
+		},
+		RunE: commander.WithContextE(o.logs),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "display logs of the trial in the specified `namespace`")
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", o.Follow, "stream the logs as they are written")
+	cmd.Flags().IntVar(&o.Tail, "tail", o.Tail, "`lines` of recent logs to display from each container, -1 displays everything")
+
+	return cmd
+}
+
+func (o *LogsOptions) logs(ctx context.Context) error {
+	trialName := o.Names[0].Name
+	selector := redskyv1beta1.LabelTrial + "=" + trialName
+
+	// Following multiple pods at once is only possible using the selector
+	if o.Follow {
+		return o.kubectl(ctx, "logs", "--selector", selector, "--all-containers", "--prefix", "--follow", "--tail", strconv.Itoa(o.Tail))
+	}
+
+	pods, err := o.listPods(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	sources := logSources(pods)
+	if len(sources) == 0 {
+		return fmt.Errorf("no pods found for trial %q", trialName)
+	}
+
+	for i, src := range sources {
+		if i > 0 {
+			_, _ = fmt.Fprintln(o.Out)
+		}
+		_, _ = fmt.Fprintf(o.Out, "==> %s/%s (%s) <==\n", src.pod, src.container, src.role)
+		if err := o.kubectl(ctx, "logs", src.pod, "--container", src.container, "--tail", strconv.Itoa(o.Tail)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listPods returns the pods matching the supplied selector
+func (o *LogsOptions) listPods(ctx context.Context, selector string) (*corev1.PodList, error) {
+	get, err := o.Config.Kubectl(ctx, o.namespaceArgs("get", "pods", "--selector", selector, "--output", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	get.Stderr = o.ErrOut
+
+	data, err := get.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := json.Unmarshal(data, pods); err != nil {
+		return nil, err
+	}
+	return pods, nil
+}
+
+// kubectl runs a kubectl command in the trial namespace, sending the output directly to the output stream
+func (o *LogsOptions) kubectl(ctx context.Context, args ...string) error {
+	cmd, err := o.Config.Kubectl(ctx, o.namespaceArgs(args...)...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = o.Out
+	cmd.Stderr = o.ErrOut
+	return cmd.Run()
+}
+
+// namespaceArgs appends the namespace argument to the supplied kubectl arguments
+func (o *LogsOptions) namespaceArgs(args ...string) []string {
+	if o.Namespace != "" {
+		return append(args, "--namespace", o.Namespace)
+	}
+	return args
+}
+
+// logSource identifies a single container whose logs should be displayed
+type logSource struct {
+	pod       string
+	container string
+	role      string
+}
+
+// logSources returns the containers of the supplied pods in the order they were run
+func logSources(pods *corev1.PodList) []logSource {
+	items := make([]corev1.Pod, len(pods.Items))
+	copy(items, pods.Items)
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].CreationTimestamp.Equal(&items[j].CreationTimestamp) {
+			return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	var sources []logSource
+	for _, pod := range items {
+		role := pod.Labels[redskyv1beta1.LabelTrialRole]
+		for _, c := range pod.Spec.InitContainers {
+			sources = append(sources, logSource{pod: pod.Name, container: c.Name, role: role})
+		}
+		for _, c := range pod.Spec.Containers {
+			sources = append(sources, logSource{pod: pod.Name, container: c.Name, role: role})
+		}
+	}
+	return sources
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLogSources(t *testing.T) {
+	now := time.Now()
+	pod := func(name, role string, created time.Time, containers ...string) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Labels:            map[string]string{redskyv1beta1.LabelTrialRole: role},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+		}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+
+	run := pod("example-001-abcde", "trialRun", now.Add(-time.Minute), "main")
+	run.Spec.InitContainers = []corev1.Container{{Name: "wait"}}
+	pods := &corev1.PodList{
+		Items: []corev1.Pod{
+			pod("example-001-delete-fghij", "trialSetup", now, "postgres"),
+			run,
+			pod("example-001-create-klmno", "trialSetup", now.Add(-2*time.Minute), "postgres"),
+		},
+	}
+
+	assert.Equal(t, []logSource{
+		{pod: "example-001-create-klmno", container: "postgres", role: "trialSetup"},
+		{pod: "example-001-abcde", container: "wait", role: "trialRun"},
+		{pod: "example-001-abcde", container: "main", role: "trialRun"},
+		{pod: "example-001-delete-fghij", container: "postgres", role: "trialSetup"},
+	}, logSources(pods))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/controller"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// PauseOptions includes the configuration for pausing or resuming an experiment in the cluster
+type PauseOptions struct {
+	Options
+
+	// Namespace is the namespace of the experiment, the current namespace is used if empty
+	Namespace string
+	// Resume indicates a paused experiment should be resumed
+	Resume bool
+}
+
+// NewPauseCommand creates a new pause command
+func NewPauseCommand(o *PauseOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause EXPERIMENT_NAME",
+		Short: "Pause an experiment",
+		Long: "Pause an experiment by scaling it down to zero replicas and clearing the next trial URL, " +
+			"the controller will stop requesting new trial suggestions until the experiment is resumed.",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = []name{{Type: typeExperiment, Name: args[0], Number: -1}}
+			commander.SetStreams(&o.IOStreams, cmd)
+			return nil
+		},
+		RunE: commander.WithContextE(o.pause),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "pause the experiment in the specified `namespace`")
+
+	return cmd
+}
+
+// NewResumeCommand creates a new resume command
+func NewResumeCommand(o *PauseOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume EXPERIMENT_NAME",
+		Short: "Resume a paused experiment",
+		Long: "Resume an experiment by restoring the replica count it had when it was paused " +
+			"and the next trial URL from the server.",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = []name{{Type: typeExperiment, Name: args[0], Number: -1}}
+			o.Resume = true
+			commander.SetStreams(&o.IOStreams, cmd)
+			return commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+		},
+		RunE: commander.WithContextE(o.pause),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "resume the experiment in the specified `namespace`")
+
+	return cmd
+}
+
+func (o *PauseOptions) pause(ctx context.Context) error {
+	experimentName := o.Names[0].Name
+
+	args := []string{"get", "experiments.v1beta1.redskyops.dev", experimentName, "--output", "json"}
+	if o.Namespace != "" {
+		args = append(args, "--namespace", o.Namespace)
+	}
+	exp := &redskyv1beta1.Experiment{}
+	if err := o.getJSON(ctx, args, exp); err != nil {
+		return err
+	}
+
+	var patch map[string]interface{}
+	verb := "paused"
+	if o.Resume {
+		nextTrialURL, err := o.nextTrialURL(ctx, exp)
+		if err != nil {
+			return err
+		}
+		patch = resumePatch(exp, nextTrialURL)
+		verb = "resumed"
+	} else {
+		patch = pausePatch(exp)
+	}
+
+	if patch == nil {
+		_, _ = fmt.Fprintf(o.Out, "experiment \"%s\" is already %s\n", exp.Name, verb)
+		return nil
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := o.Config.Kubectl(ctx, "patch", "experiments.v1beta1.redskyops.dev", exp.Name, "--namespace", exp.Namespace, "--type", "merge", "--patch", string(data))
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = o.ErrOut
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "experiment \"%s\" %s\n", exp.Name, verb)
+	return nil
+}
+
+// nextTrialURL returns the current next trial URL from the server, experiments which are not synchronized with the
+// server (or which have been stopped on the server) do not have a next trial URL
+func (o *PauseOptions) nextTrialURL(ctx context.Context, exp *redskyv1beta1.Experiment) (string, error) {
+	if exp.GetAnnotations()[redskyv1beta1.AnnotationExperimentURL] == "" {
+		return "", nil
+	}
+
+	ee, err := o.ExperimentsAPI.GetExperimentByName(ctx, experimentsv1alpha1.NewExperimentName(exp.Name))
+	if err != nil {
+		return "", controller.IgnoreNotFound(err)
+	}
+	return ee.NextTrialURL, nil
+}
+
+// pausePatch returns a merge patch which scales the experiment down and clears the next trial URL, the current replica
+// count is recorded so it can be restored; returns nil if the experiment is already paused
+func pausePatch(exp *redskyv1beta1.Experiment) map[string]interface{} {
+	if _, ok := exp.GetAnnotations()[redskyv1beta1.AnnotationPausedReplicas]; ok {
+		return nil
+	}
+
+	replicas := int32(1)
+	if exp.Spec.Replicas != nil {
+		replicas = *exp.Spec.Replicas
+	}
+
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				redskyv1beta1.AnnotationPausedReplicas: strconv.FormatInt(int64(replicas), 10),
+				redskyv1beta1.AnnotationNextTrialURL:   nil,
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 0,
+		},
+	}
+}
+
+// resumePatch returns a merge patch which restores the replica count and next trial URL of a paused experiment;
+// returns nil if the experiment is not paused
+func resumePatch(exp *redskyv1beta1.Experiment, nextTrialURL string) map[string]interface{} {
+	value, ok := exp.GetAnnotations()[redskyv1beta1.AnnotationPausedReplicas]
+	if !ok {
+		return nil
+	}
+
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || replicas < 1 {
+		replicas = 1
+	}
+
+	annotations := map[string]interface{}{
+		redskyv1beta1.AnnotationPausedReplicas: nil,
+	}
+	if nextTrialURL != "" {
+		annotations[redskyv1beta1.AnnotationNextTrialURL] = nextTrialURL
+	}
+
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+)
+
+func TestPauseResumePatch(t *testing.T) {
+	replicas := int32(3)
+	exp := &redskyv1beta1.Experiment{}
+	exp.Spec.Replicas = &replicas
+	exp.Annotations = map[string]string{redskyv1beta1.AnnotationNextTrialURL: "http://example.com/next"}
+
+	assert.Nil(t, resumePatch(exp, "http://example.com/next"))
+	assert.Equal(t, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				redskyv1beta1.AnnotationPausedReplicas: "3",
+				redskyv1beta1.AnnotationNextTrialURL:   nil,
+			},
+		},
+		"spec": map[string]interface{}{"replicas": 0},
+	}, pausePatch(exp))
+
+	// Simulate the paused state
+	exp.Annotations = map[string]string{redskyv1beta1.AnnotationPausedReplicas: "3"}
+	replicas = 0
+
+	assert.Nil(t, pausePatch(exp))
+	assert.Equal(t, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				redskyv1beta1.AnnotationPausedReplicas: nil,
+				redskyv1beta1.AnnotationNextTrialURL:   "http://example.com/next",
+			},
+		},
+		"spec": map[string]interface{}{"replicas": int64(3)},
+	}, resumePatch(exp, "http://example.com/next"))
+}
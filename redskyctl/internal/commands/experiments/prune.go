@@ -0,0 +1,168 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PruneOptions includes the configuration for pruning the results of finished experiments
+type PruneOptions struct {
+	Options
+
+	// Namespace is the namespace to prune experiments from, the current namespace is used if empty
+	Namespace string
+	// AllNamespaces prunes experiments from every namespace
+	AllNamespaces bool
+	// KeepBest is the number of best trials to keep for each experiment
+	KeepBest int
+	// OlderThan is the minimum age of the results to prune
+	OlderThan string
+	// DryRun only prints what would be deleted
+	DryRun bool
+}
+
+// NewPruneCommand creates a new prune command
+func NewPruneCommand(o *PruneOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Prune finished experiments",
+		Long:  "Delete the trials and experiments of finished experiments in the cluster according to a retention policy",
+
+		PreRun: commander.StreamsPreRun(&o.IOStreams),
+		RunE:   commander.WithContextE(o.prune),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "prune experiments in the specified `namespace`")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "prune experiments in all namespaces")
+	cmd.Flags().IntVar(&o.KeepBest, "keep-best", o.KeepBest, "keep the `count` best trials of each experiment; when zero, entire experiments are deleted")
+	cmd.Flags().StringVar(&o.OlderThan, "older-than", o.OlderThan, "only prune results that finished longer ago than `age` (e.g. 90d)")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "only print the objects that would be deleted")
+
+	_ = cmd.MarkFlagRequired("older-than")
+
+	return cmd
+}
+
+func (o *PruneOptions) prune(ctx context.Context) error {
+	olderThan, err := experiment.ParseAge(o.OlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid age %q: %w", o.OlderThan, err)
+	}
+	if olderThan <= 0 {
+		return fmt.Errorf("age must be positive")
+	}
+	policy := &experiment.RetentionPolicy{KeepBest: o.KeepBest, OlderThan: olderThan}
+
+	experimentList := &redskyv1beta1.ExperimentList{}
+	if err := o.getList(ctx, "experiments.v1beta1.redskyops.dev", o.namespaceArgs(), experimentList); err != nil {
+		return err
+	}
+
+	// Trials may be in a different namespace from their experiment
+	trialList := &redskyv1beta1.TrialList{}
+	if err := o.getList(ctx, "trials.v1beta1.redskyops.dev", []string{"--all-namespaces"}, trialList); err != nil {
+		return err
+	}
+
+	trials := make(map[types.NamespacedName]*redskyv1beta1.TrialList)
+	for i := range trialList.Items {
+		nn := trialList.Items[i].ExperimentNamespacedName()
+		if trials[nn] == nil {
+			trials[nn] = &redskyv1beta1.TrialList{}
+		}
+		trials[nn].Items = append(trials[nn].Items, trialList.Items[i])
+	}
+
+	now := time.Now()
+	for i := range experimentList.Items {
+		exp := &experimentList.Items[i]
+		if policy.PruneExperiment(exp, now) {
+			if err := o.delete(ctx, "experiment", exp.Namespace, exp.Name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tl := trials[types.NamespacedName{Namespace: exp.Namespace, Name: exp.Name}]
+		if tl == nil {
+			continue
+		}
+		for _, t := range policy.PruneTrials(exp, tl, now) {
+			if err := o.delete(ctx, "trial", t.Namespace, t.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// namespaceArgs returns the kubectl arguments used to select the namespaces to prune
+func (o *PruneOptions) namespaceArgs() []string {
+	if o.AllNamespaces {
+		return []string{"--all-namespaces"}
+	}
+	if o.Namespace != "" {
+		return []string{"--namespace", o.Namespace}
+	}
+	return nil
+}
+
+// getList retrieves a list of resources from the cluster
+func (o *PruneOptions) getList(ctx context.Context, resource string, args []string, list interface{}) error {
+	get, err := o.Config.Kubectl(ctx, append(append([]string{"get", resource}, args...), "--output", "json")...)
+	if err != nil {
+		return err
+	}
+	get.Stderr = o.ErrOut
+
+	data, err := get.Output()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, list)
+}
+
+// delete removes a single resource from the cluster, honoring the dry run setting
+func (o *PruneOptions) delete(ctx context.Context, kind, namespace, name string) error {
+	if o.DryRun {
+		_, _ = fmt.Fprintf(o.Out, "%s \"%s\" deleted (dry run)\n", kind, name)
+		return nil
+	}
+
+	del, err := o.Config.Kubectl(ctx, "delete", "--namespace", namespace, "--ignore-not-found", kind+".v1beta1.redskyops.dev", name)
+	if err != nil {
+		return err
+	}
+	del.Stderr = o.ErrOut
+	if err := del.Run(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "%s \"%s\" deleted\n", kind, name)
+	return nil
+}
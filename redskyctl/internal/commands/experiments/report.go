@@ -0,0 +1,129 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+)
+
+// ReportOptions includes the configuration for reporting on the experiments of an application
+type ReportOptions struct {
+	Options
+
+	// Application is the name of the application to report on
+	Application string
+	// Namespace is the namespace of the experiments, the current namespace is used if empty
+	Namespace string
+}
+
+// NewReportCommand creates a new report command
+func NewReportCommand(o *ReportOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report APPLICATION",
+		Short: "Report on an application suite",
+		Long:  "Summarize the progress and results of every experiment generated for an application in the cluster",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Application = args[0]
+			commander.SetStreams(&o.IOStreams, cmd)
+			return nil
+		},
+		RunE: commander.WithContextE(o.report),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "report on experiments in the specified `namespace`")
+
+	return cmd
+}
+
+func (o *ReportOptions) report(ctx context.Context) error {
+	args := []string{"get", "experiments.v1beta1.redskyops.dev", "--selector", redskyappsv1alpha1.LabelApplication + "=" + o.Application, "--output", "json"}
+	if o.Namespace != "" {
+		args = append(args, "--namespace", o.Namespace)
+	}
+
+	get, err := o.Config.Kubectl(ctx, args...)
+	if err != nil {
+		return err
+	}
+	get.Stderr = o.ErrOut
+
+	data, err := get.Output()
+	if err != nil {
+		return err
+	}
+
+	experimentList := &redskyv1beta1.ExperimentList{}
+	if err := json.Unmarshal(data, experimentList); err != nil {
+		return err
+	}
+
+	if len(experimentList.Items) == 0 {
+		return fmt.Errorf("no experiments found for application %q", o.Application)
+	}
+
+	return report(o.Out, experimentList)
+}
+
+// report writes a summary table of the supplied experiments
+func report(w io.Writer, experimentList *redskyv1beta1.ExperimentList) error {
+	items := make([]*redskyv1beta1.Experiment, 0, len(experimentList.Items))
+	for i := range experimentList.Items {
+		items = append(items, &experimentList.Items[i])
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		si, sj := items[i].Labels[redskyappsv1alpha1.LabelScenario], items[j].Labels[redskyappsv1alpha1.LabelScenario]
+		if si != sj {
+			return si < sj
+		}
+		return items[i].Labels[redskyappsv1alpha1.LabelObjective] < items[j].Labels[redskyappsv1alpha1.LabelObjective]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SCENARIO\tOBJECTIVE\tEXPERIMENT\tSTATUS\tCOMPLETED\tFAILED\tBEST")
+	for _, exp := range items {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			orNone(exp.Labels[redskyappsv1alpha1.LabelScenario]),
+			orNone(exp.Labels[redskyappsv1alpha1.LabelObjective]),
+			exp.Name,
+			orNone(exp.Status.Phase),
+			exp.Status.CompletedTrials,
+			exp.Status.FailedTrials,
+			orNone(exp.Status.BestValues))
+	}
+	return tw.Flush()
+}
+
+// orNone returns a placeholder for empty values
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/server"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UnstickOptions includes the configuration for removing the server finalizers of a deleted experiment
+type UnstickOptions struct {
+	Options
+
+	// Namespace is the namespace of the experiment, the current namespace is used if empty
+	Namespace string
+}
+
+// NewUnstickCommand creates a new unstick command
+func NewUnstickCommand(o *UnstickOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unstick EXPERIMENT_NAME",
+		Short: "Finish deleting an experiment",
+		Long: "Remove the server finalizers from a deleted experiment and its deleted trials in the cluster. " +
+			"Use this when the server is unreachable and deletion is blocked; any unreported results are lost.",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Names = []name{{Type: typeExperiment, Name: args[0], Number: -1}}
+			commander.SetStreams(&o.IOStreams, cmd)
+			return nil
+		},
+		RunE: commander.WithContextE(o.unstick),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "unstick the experiment in the specified `namespace`")
+
+	return cmd
+}
+
+func (o *UnstickOptions) unstick(ctx context.Context) error {
+	experimentName := o.Names[0].Name
+
+	args := []string{"get", "experiments.v1beta1.redskyops.dev", experimentName, "--output", "json"}
+	if o.Namespace != "" {
+		args = append(args, "--namespace", o.Namespace)
+	}
+	exp := &redskyv1beta1.Experiment{}
+	if err := o.getJSON(ctx, args, exp); err != nil {
+		return err
+	}
+
+	// Trials may be in a different namespace from their experiment
+	trialList := &redskyv1beta1.TrialList{}
+	args = []string{"get", "trials.v1beta1.redskyops.dev", "--all-namespaces", "--selector", redskyv1beta1.LabelExperiment + "=" + exp.Name, "--output", "json"}
+	if err := o.getJSON(ctx, args, trialList); err != nil {
+		return err
+	}
+
+	var removed int
+	for i := range trialList.Items {
+		t := &trialList.Items[i]
+		if t.ExperimentNamespacedName().Namespace != exp.Namespace || t.DeletionTimestamp == nil {
+			continue
+		}
+		if ok, err := o.removeFinalizer(ctx, "trial", t); err != nil {
+			return err
+		} else if ok {
+			removed++
+		}
+	}
+
+	if exp.DeletionTimestamp != nil {
+		if ok, err := o.removeFinalizer(ctx, "experiment", exp); err != nil {
+			return err
+		} else if ok {
+			removed++
+		}
+	}
+
+	if removed == 0 {
+		_, _ = fmt.Fprintf(o.Out, "experiment \"%s\" is not stuck waiting on the server\n", exp.Name)
+	}
+	return nil
+}
+
+// getJSON runs a kubectl command and parses the JSON output
+func (o *Options) getJSON(ctx context.Context, args []string, obj interface{}) error {
+	get, err := o.Config.Kubectl(ctx, args...)
+	if err != nil {
+		return err
+	}
+	get.Stderr = o.ErrOut
+
+	data, err := get.Output()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, obj)
+}
+
+// removeFinalizer removes the server finalizer from the supplied object, returns false if there was no finalizer
+func (o *UnstickOptions) removeFinalizer(ctx context.Context, kind string, obj metav1.Object) (bool, error) {
+	patch := finalizerPatch(obj.GetFinalizers(), server.Finalizer)
+	if patch == nil {
+		return false, nil
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return false, err
+	}
+
+	cmd, err := o.Config.Kubectl(ctx, "patch", kind+".v1beta1.redskyops.dev", obj.GetName(), "--namespace", obj.GetNamespace(), "--type", "json", "--patch", string(data))
+	if err != nil {
+		return false, err
+	}
+	cmd.Stderr = o.ErrOut
+	if err := cmd.Run(); err != nil {
+		return false, err
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "%s \"%s\" unstuck\n", kind, obj.GetName())
+	return true, nil
+}
+
+// finalizerPatch returns a JSON patch that removes the supplied finalizer, the patch fails if the finalizers changed
+func finalizerPatch(finalizers []string, finalizer string) []map[string]interface{} {
+	for i := range finalizers {
+		if finalizers[i] == finalizer {
+			path := fmt.Sprintf("/metadata/finalizers/%d", i)
+			return []map[string]interface{}{
+				{"op": "test", "path": path, "value": finalizer},
+				{"op": "remove", "path": path},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalizerPatch(t *testing.T) {
+	assert.Nil(t, finalizerPatch([]string{"example.com/finalizer"}, "serverFinalizer.redskyops.dev"))
+	assert.Equal(t, []map[string]interface{}{
+		{"op": "test", "path": "/metadata/finalizers/1", "value": "serverFinalizer.redskyops.dev"},
+		{"op": "remove", "path": "/metadata/finalizers/1"},
+	}, finalizerPatch([]string{"example.com/finalizer", "serverFinalizer.redskyops.dev"}, "serverFinalizer.redskyops.dev"))
+}
@@ -19,14 +19,20 @@ package export
 import (
 	"bytes"
 	"context"
+	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	konjurev1beta2 "github.com/thestormforge/konjure/pkg/api/core/v1beta2"
 	"github.com/thestormforge/konjure/pkg/filters"
+	"github.com/thestormforge/konjure/pkg/konjure"
 	app "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	redsky "github.com/thestormforge/optimize-controller/api/v1beta1"
 	apppkg "github.com/thestormforge/optimize-controller/internal/application"
@@ -37,6 +43,7 @@ import (
 	"github.com/thestormforge/optimize-controller/internal/sfio"
 	"github.com/thestormforge/optimize-controller/internal/template"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/experiments"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/kustomize"
 	experimentsapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/config"
@@ -50,6 +57,9 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+// stdinFilename is the name used for manifests read from stdin.
+const stdinFilename = "stdin.yaml"
+
 // Options are the configuration options for creating a patched experiment
 type Options struct {
 	// Config is the Red Sky Configuration used to generate the controller installation
@@ -60,12 +70,16 @@ type Options struct {
 	commander.IOStreams
 
 	inputFiles    []string
-	trialName     string
+	kustomizeDir  string
+	trialNames    []string
 	patchOnly     bool
 	patchedTarget bool
+	postRender    bool
+	chunkSize     int
 
 	// This is used for testing
 	Fs          filesys.FileSystem
+	fsBase      string
 	inputData   []byte
 	experiment  *redsky.Experiment
 	application *app.Application
@@ -84,9 +98,13 @@ type trialDetails struct {
 // NewCommand creates a command for performing an export
 func NewCommand(o *Options) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "export TRIAL_NAME",
+		Use:   "export TRIAL_NAME...",
 		Short: "Export trial parameters to an application or experiment",
-		Long:  "Export trial parameters to an application or experiment from the specified trial",
+		Long: "Export trial parameters to an application or experiment from the specified trial.\n\n" +
+			"The trial name is the experiment name followed by the trial number, separated by either a \"-\" or a \"/\". " +
+			"Use the \"/\" separator (e.g. \"my-app-v2/5\") when the experiment name itself ends with a dash and digits.\n\n" +
+			"When multiple trials are specified, the patches from each trial are combined into a single output; " +
+			"patches from later trials are applied after (and therefore take precedence over) patches from earlier trials.",
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			commander.SetStreams(&o.IOStreams, cmd)
@@ -96,33 +114,48 @@ func NewCommand(o *Options) *cobra.Command {
 				err = commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
 			}
 
-			if len(args) != 1 {
+			if len(args) == 0 {
 				return fmt.Errorf("a trial name must be specified")
 			}
 
-			o.trialName = args[0]
+			if len(o.inputFiles) == 0 && o.kustomizeDir == "" {
+				return fmt.Errorf("either a filename or a kustomization directory must be specified")
+			}
+
+			o.trialNames = args
 
 			return err
 		},
 		RunE: commander.WithContextE(o.runner),
 	}
 
-	cmd.Flags().StringSliceVarP(&o.inputFiles, "filename", "f", []string{""}, "experiment and related manifest `files` to export, - for stdin")
+	cmd.Flags().StringSliceVarP(&o.inputFiles, "filename", "f", nil, "experiment and related manifest `files` to export, - for stdin")
+	cmd.Flags().StringVarP(&o.kustomizeDir, "kustomize", "k", "", "kustomization `dir`ectory to build and use as input")
 	cmd.Flags().BoolVarP(&o.patchOnly, "patch", "p", false, "export only the patch")
 	cmd.Flags().BoolVarP(&o.patchedTarget, "patched-target", "t", false, "export only the patched resource")
+	cmd.Flags().IntVar(&o.chunkSize, "chunk-size", 500, "fetch large lists in chunks rather then all at once")
 
-	_ = cmd.MarkFlagRequired("filename")
 	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+	_ = cmd.MarkFlagDirname("kustomize")
 
 	return cmd
 }
 
 func (o *Options) readInput() error {
 	// Do an in memory filesystem so we can properly handle stdin
-	if o.Fs == nil {
+	if o.Fs == nil && o.kustomizeDir == "" {
 		o.Fs = filesys.MakeFsInMemory()
 	}
 
+	// A kustomization can reference anything on disk, use a temporary directory instead
+	if o.Fs == nil {
+		dir, err := ioutil.TempDir("", "redskyctl-export-")
+		if err != nil {
+			return err
+		}
+		o.Fs, o.fsBase = filesys.MakeFsOnDisk(), dir
+	}
+
 	if o.resources == nil {
 		o.resources = make(map[string]struct{})
 	}
@@ -142,27 +175,33 @@ func (o *Options) readInput() error {
 		}
 
 		if filename == "-" {
-			filename = "stdin.yaml"
+			filename = stdinFilename
 		}
 
-		if err := o.Fs.WriteFile(filepath.Base(filename), data); err != nil {
+		kr, err := o.addInput(filename, data)
+		if err != nil {
 			return err
 		}
 
-		path, err := filepath.Abs(filename)
+		kioInputs = append(kioInputs, kr)
+	}
+
+	if o.kustomizeDir != "" {
+		data, err := o.buildKustomization()
 		if err != nil {
 			return err
 		}
 
-		kioInputs = append(kioInputs, &kio.ByteReader{
-			Reader: bytes.NewReader(data),
-			SetAnnotations: map[string]string{
-				kioutil.PathAnnotation: path,
-			},
-		})
+		path, err := filepath.Abs(o.kustomizeDir)
+		if err != nil {
+			return err
+		}
 
-		// Track all of the input files so we can use them as kustomize resources later on
-		o.resources[filepath.Base(filename)] = struct{}{}
+		kioInputs = append(kioInputs, &kio.ByteReader{Reader: bytes.NewReader(data)})
+
+		// Reference the kustomization itself (instead of the build output) so the final build includes
+		// components and generators exactly as they would be if the user built the kustomization
+		o.resources[path] = struct{}{}
 	}
 
 	var inputsBuf bytes.Buffer
@@ -181,6 +220,49 @@ func (o *Options) readInput() error {
 	return nil
 }
 
+// addInput records the supplied input data in the in memory filesystem so it can be used as a kustomize
+// resource later on, the returned reader can be used to include the data in the aggregate input.
+func (o *Options) addInput(filename string, data []byte) (kio.Reader, error) {
+	if err := o.Fs.WriteFile(filepath.Join(o.fsBase, filepath.Base(filename)), data); err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Track all of the input files so we can use them as kustomize resources later on
+	o.resources[filepath.Base(filename)] = struct{}{}
+
+	return &kio.ByteReader{
+		Reader: bytes.NewReader(data),
+		SetAnnotations: map[string]string{
+			kioutil.PathAnnotation: path,
+		},
+	}, nil
+}
+
+// buildKustomization runs a kustomize build of the kustomization directory.
+func (o *Options) buildKustomization() ([]byte, error) {
+	var buf bytes.Buffer
+
+	opts := scan.FilterOptions{
+		DefaultReader: o.In,
+	}
+
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{konjure.Resources{{Kustomize: &konjurev1beta2.Kustomize{Root: o.kustomizeDir}}}},
+		Filters: []kio.Filter{opts.NewFilter(".")},
+		Outputs: []kio.Writer{&kio.ByteWriter{Writer: &buf}},
+	}.Execute()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build kustomization %q: %w", o.kustomizeDir, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (o *Options) extractApplication(trial *trialDetails) error {
 	var appBuf bytes.Buffer
 
@@ -268,49 +350,42 @@ func filterPatch(patches []types.Patch) kio.FilterFunc {
 }
 
 func (o *Options) runner(ctx context.Context) error {
-	// look up trial from api
-	trialDetails, err := o.getTrialDetails(ctx)
-	if err != nil {
-		return err
-	}
+	defer func() {
+		if o.fsBase != "" {
+			_ = os.RemoveAll(o.fsBase)
+		}
+	}()
 
 	if err := o.readInput(); err != nil {
 		return err
 	}
 
-	// See if we have been given an experiment
-	if err := o.extractExperiment(trialDetails); err != nil {
-		return fmt.Errorf("got an error when looking for experiment: %w", err)
-	}
-
-	// See if we have been given an application
-	if o.experiment == nil {
-		if err := o.extractApplication(trialDetails); err != nil {
-			return fmt.Errorf("got an error when looking for application: %w", err)
-		}
-
-		if o.application == nil {
-			return fmt.Errorf("unable to find an application %q", trialDetails.Application)
+	// Collect the patches from each trial, later patches are applied after earlier patches
+	inputResources := o.resources
+	resources := make(map[string]struct{})
+	var patches []types.Patch
+	for _, trialName := range o.trialNames {
+		o.experiment, o.application = nil, nil
+		o.resources = make(map[string]struct{}, len(inputResources))
+		for name := range inputResources {
+			o.resources[name] = struct{}{}
 		}
 
-		if err := o.generateExperiment(trialDetails); err != nil {
+		trialPatches, err := o.trialPatches(ctx, trialName)
+		if err != nil {
 			return err
 		}
-	}
 
-	// At this point we must have an experiment
-	if o.experiment == nil {
-		return fmt.Errorf("unable to find an experiment %q", trialDetails.Experiment)
+		patches = append(patches, trialPatches...)
+		for name := range o.resources {
+			resources[name] = struct{}{}
+		}
 	}
+	o.resources = resources
 
-	trial := &redsky.Trial{}
-	experiment.PopulateTrialFromTemplate(o.experiment, trial)
-	server.ToClusterTrial(trial, trialDetails.Assignments)
-
-	// render patches
-	patches, err := createKustomizePatches(o.experiment.Spec.Patches, trial)
-	if err != nil {
-		return err
+	// When post-rendering, only the manifests read from stdin are included in the output
+	if o.postRender {
+		o.resources = map[string]struct{}{stdinFilename: {}}
 	}
 
 	if o.patchOnly {
@@ -325,9 +400,11 @@ func (o *Options) runner(ctx context.Context) error {
 	for name := range o.resources {
 		resourceNames = append(resourceNames, name)
 	}
+	sort.Strings(resourceNames)
 
 	yamls, err := kustomize.Yamls(
 		kustomize.WithFS(o.Fs),
+		kustomize.WithBase(o.fsBase),
 		kustomize.WithResourceNames(resourceNames),
 		kustomize.WithPatches(patches),
 	)
@@ -345,12 +422,48 @@ func (o *Options) runner(ctx context.Context) error {
 		Filters: []kio.Filter{kio.FilterFunc(filterPatch(patches))},
 		Outputs: []kio.Writer{o.YAMLWriter()},
 	}
-	if err := output.Execute(); err != nil {
-		return err
+	return output.Execute()
+}
+
+// trialPatches returns the patches for a single trial.
+func (o *Options) trialPatches(ctx context.Context, trialName string) ([]types.Patch, error) {
+	// look up trial from api
+	trialDetails, err := o.getTrialDetails(ctx, trialName)
+	if err != nil {
+		return nil, err
 	}
 
-	// We don't want to bail if we cant find an application since we'll handle this later
-	return nil
+	// See if we have been given an experiment
+	if err := o.extractExperiment(trialDetails); err != nil {
+		return nil, fmt.Errorf("got an error when looking for experiment: %w", err)
+	}
+
+	// See if we have been given an application
+	if o.experiment == nil {
+		if err := o.extractApplication(trialDetails); err != nil {
+			return nil, fmt.Errorf("got an error when looking for application: %w", err)
+		}
+
+		if o.application == nil {
+			return nil, fmt.Errorf("unable to find an application %q", trialDetails.Application)
+		}
+
+		if err := o.generateExperiment(trialDetails); err != nil {
+			return nil, err
+		}
+	}
+
+	// At this point we must have an experiment
+	if o.experiment == nil {
+		return nil, fmt.Errorf("unable to find an experiment %q", trialDetails.Experiment)
+	}
+
+	trial := &redsky.Trial{}
+	experiment.PopulateTrialFromTemplate(o.experiment, trial)
+	server.ToClusterTrial(trial, trialDetails.Assignments, o.experiment)
+
+	// render patches
+	return createKustomizePatches(o.experiment, trial)
 }
 
 func (o *Options) generateExperiment(trial *trialDetails) error {
@@ -386,8 +499,9 @@ func (o *Options) generateExperiment(trial *trialDetails) error {
 			return err
 		}
 
-		assetName := fmt.Sprintf("%s%d%s", "application-assets", idx, ".yaml")
-		if err := o.Fs.WriteFile(assetName, listBytes); err != nil {
+		// Use the content to name the asset so identical assets from multiple trials are only included once
+		assetName := fmt.Sprintf("application-assets-%x.yaml", sha1.Sum(listBytes))
+		if err := o.Fs.WriteFile(filepath.Join(o.fsBase, assetName), listBytes); err != nil {
 			return err
 		}
 
@@ -412,27 +526,42 @@ func (o *Options) generateExperiment(trial *trialDetails) error {
 		return err
 	}
 
-	if err := o.Fs.WriteFile("resources.yaml", buf.Bytes()); err != nil {
+	resourcesName := fmt.Sprintf("resources-%x.yaml", sha1.Sum(buf.Bytes()))
+	if err := o.Fs.WriteFile(filepath.Join(o.fsBase, resourcesName), buf.Bytes()); err != nil {
 		return err
 	}
 
-	o.resources["resources.yaml"] = struct{}{}
+	o.resources[resourcesName] = struct{}{}
 
 	return nil
 }
 
+// splitTrialName splits a trial name into the experiment name and trial number. In addition to the
+// "<experiment>-<number>" form, the unambiguous "<experiment>/<number>" form is also accepted.
+func splitTrialName(name string) (experimentsapi.ExperimentName, int64) {
+	if pos := strings.LastIndex(name, "/"); pos >= 0 {
+		num, err := strconv.ParseInt(name[pos+1:], 10, 64)
+		if err != nil || pos == 0 {
+			return experimentsapi.NewExperimentName(name), -1
+		}
+		return experimentsapi.NewExperimentName(name[0:pos]), num
+	}
+
+	return experimentsapi.SplitTrialName(name)
+}
+
 // getTrialDetails returns information about the requested trial.
-func (o *Options) getTrialDetails(ctx context.Context) (*trialDetails, error) {
-	if o.trialName == "" {
+func (o *Options) getTrialDetails(ctx context.Context, trialName string) (*trialDetails, error) {
+	if trialName == "" {
 		return nil, fmt.Errorf("a trial name must be specified")
 	}
 	if o.ExperimentsAPI == nil {
 		return nil, fmt.Errorf("unable to connect to api server")
 	}
 
-	experimentName, trialNumber := experimentsapi.SplitTrialName(o.trialName)
+	experimentName, trialNumber := splitTrialName(trialName)
 	if trialNumber < 0 {
-		return nil, fmt.Errorf("invalid trial name %q", o.trialName)
+		return nil, fmt.Errorf("invalid trial name %q", trialName)
 	}
 
 	exp, err := o.ExperimentsAPI.GetExperimentByName(ctx, experimentName)
@@ -454,16 +583,14 @@ func (o *Options) getTrialDetails(ctx context.Context) (*trialDetails, error) {
 	query := &experimentsapi.TrialListQuery{
 		Status: []experimentsapi.TrialStatus{experimentsapi.TrialCompleted},
 	}
-	trialList, err := o.ExperimentsAPI.GetAllTrials(ctx, exp.TrialsURL, query)
-	if err != nil {
-		return nil, err
-	}
-
-	for i := range trialList.Trials {
-		if trialList.Trials[i].Number == trialNumber {
-			result.Assignments = &trialList.Trials[i].TrialAssignments
-			break
+	if err := experiments.ForEachTrial(ctx, o.ExperimentsAPI, &exp, query, o.chunkSize, func(t *experimentsapi.TrialItem) error {
+		if t.Number == trialNumber {
+			assignments := t.TrialAssignments
+			result.Assignments = &assignments
 		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
 	if result.Assignments == nil {
@@ -473,8 +600,9 @@ func (o *Options) getTrialDetails(ctx context.Context) (*trialDetails, error) {
 }
 
 // createKustomizePatches translates a patchTemplate into a kustomize (json) patch
-func createKustomizePatches(patchSpec []redsky.PatchTemplate, trial *redsky.Trial) ([]types.Patch, error) {
-	te := template.New()
+func createKustomizePatches(exp *redsky.Experiment, trial *redsky.Trial) ([]types.Patch, error) {
+	patchSpec := exp.Spec.Patches
+	te := template.New().WithParameters(exp.Spec.Parameters).WithDerived(exp.Spec.Derived)
 	patches := make([]types.Patch, len(patchSpec))
 
 	for idx, expPatch := range patchSpec {
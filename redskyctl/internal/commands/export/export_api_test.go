@@ -41,6 +41,24 @@ var wannabeTrial = experimentsapi.TrialItem{
 	Status: experimentsapi.TrialCompleted,
 }
 
+// This is a second target trial, used when exporting multiple trials
+var otherTrial = experimentsapi.TrialItem{
+	TrialAssignments: experimentsapi.TrialAssignments{
+		Assignments: []experimentsapi.Assignment{
+			{
+				ParameterName: "cpu",
+				Value:         numstr.FromInt64(300),
+			},
+			{
+				ParameterName: "memory",
+				Value:         numstr.FromInt64(400),
+			},
+		},
+	},
+	Number: 1235,
+	Status: experimentsapi.TrialCompleted,
+}
+
 // Implement the api interface
 type fakeRedSkyServer struct{}
 
@@ -120,6 +138,7 @@ func (f *fakeRedSkyServer) GetAllTrials(ctx context.Context, name string, query
 	tl := experimentsapi.TrialList{
 		Trials: []experimentsapi.TrialItem{
 			wannabeTrial,
+			otherTrial,
 			// This one should not be used because number doesnt match up
 			{
 				TrialAssignments: experimentsapi.TrialAssignments{
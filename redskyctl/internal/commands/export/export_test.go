@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/export"
+	experimentsapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/config"
 	"sigs.k8s.io/kustomize/api/filesys"
 )
@@ -39,6 +40,9 @@ func TestPatchExperiment(t *testing.T) {
 	manifestFile := createTempManifests(t)
 	defer os.Remove(manifestFile.Name())
 
+	kustomizeDir := createTempKustomization(t)
+	defer os.RemoveAll(kustomizeDir)
+
 	testCases := []struct {
 		desc  string
 		args  []string
@@ -70,6 +74,14 @@ func TestPatchExperiment(t *testing.T) {
 			},
 			stdin: bytes.NewReader(pgDeployment),
 		},
+		{
+			desc: "exp file manifest kustomization",
+			args: []string{
+				"--filename", expFile.Name(),
+				"--kustomize", kustomizeDir,
+				"sampleExperiment-1234",
+			},
+		},
 		{
 			desc: "exp stdin manifest stdin",
 			args: []string{
@@ -114,6 +126,101 @@ func TestPatchExperiment(t *testing.T) {
 	}
 }
 
+func TestPatchExperimentMultipleTrials(t *testing.T) {
+	_, _, expFile := createTempExperimentFile(t)
+	defer os.Remove(expFile.Name())
+
+	manifestFile := createTempManifests(t)
+	defer os.Remove(manifestFile.Name())
+
+	cfg := &config.RedSkyConfig{}
+
+	opts := &export.Options{Config: cfg}
+	opts.ExperimentsAPI = &fakeRedSkyServer{}
+	cmd := export.NewCommand(opts)
+	commander.ConfigGlobals(cfg, cmd)
+
+	var b bytes.Buffer
+	cmd.SetOut(&b)
+	cmd.SetArgs([]string{
+		"--filename", expFile.Name(),
+		"--filename", manifestFile.Name(),
+		"--patch",
+		"sampleExperiment-1234",
+		"sampleExperiment/1235",
+	})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	// Both trials patch the same object, so only the patches include the values from each trial
+	for _, trial := range []experimentsapi.TrialItem{wannabeTrial, otherTrial} {
+		cpu := trial.TrialAssignments.Assignments[0]
+		mem := trial.TrialAssignments.Assignments[1]
+		assert.Contains(t, b.String(), fmt.Sprintf("%sm", cpu.Value.String()))
+		assert.Contains(t, b.String(), fmt.Sprintf("%sMi", mem.Value.String()))
+	}
+}
+
+func TestPatchKustomization(t *testing.T) {
+	_, _, expFile := createTempExperimentFile(t)
+	defer os.Remove(expFile.Name())
+
+	kustomizeDir := createTempKustomization(t)
+	defer os.RemoveAll(kustomizeDir)
+
+	cfg := &config.RedSkyConfig{}
+
+	opts := &export.Options{Config: cfg}
+	opts.ExperimentsAPI = &fakeRedSkyServer{}
+	cmd := export.NewCommand(opts)
+	commander.ConfigGlobals(cfg, cmd)
+
+	var b bytes.Buffer
+	cmd.SetOut(&b)
+	cmd.SetArgs([]string{
+		"--filename", expFile.Name(),
+		"--kustomize", kustomizeDir,
+		"sampleExperiment-1234",
+	})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	// Generators from the kustomization must be included (with the hash suffix) alongside the patched resources
+	cpu := wannabeTrial.TrialAssignments.Assignments[0]
+	assert.Contains(t, b.String(), fmt.Sprintf("%s: %sm", cpu.ParameterName, cpu.Value.String()))
+	assert.Regexp(t, `name: postgres-config-[a-z0-9]{10}`, b.String())
+}
+
+func TestHelmPostRenderer(t *testing.T) {
+	_, _, expFile := createTempExperimentFile(t)
+	defer os.Remove(expFile.Name())
+
+	cfg := &config.RedSkyConfig{}
+
+	opts := &export.Options{Config: cfg}
+	opts.ExperimentsAPI = &fakeRedSkyServer{}
+	cmd := export.NewHelmPostRendererCommand(opts)
+	commander.ConfigGlobals(cfg, cmd)
+
+	var b bytes.Buffer
+	cmd.SetOut(&b)
+	cmd.SetIn(bytes.NewReader(pgDeployment))
+	cmd.SetArgs([]string{
+		"--filename", expFile.Name(),
+		"sampleExperiment-1234",
+	})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	// Only the rendered manifests should be included in the output
+	cpu := wannabeTrial.TrialAssignments.Assignments[0]
+	assert.Contains(t, b.String(), fmt.Sprintf("%s: %sm", cpu.ParameterName, cpu.Value.String()))
+	assert.NotContains(t, b.String(), "kind: Experiment")
+}
+
 func TestPatchApplication(t *testing.T) {
 	// All of these files get created in the same tempdir ( neat-o )
 	// so we can 'cheat' kustomize/krusty by passing in basename(manifests)
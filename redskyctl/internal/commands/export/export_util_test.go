@@ -19,6 +19,7 @@ package export_test
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -99,6 +100,25 @@ func createTempManifests(t *testing.T) *os.File {
 	return tmpfile
 }
 
+func createTempKustomization(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kustomization-")
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "manifest.yaml"), pgDeployment, 0644)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(`resources:
+- manifest.yaml
+configMapGenerator:
+- name: postgres-config
+  literals:
+  - POSTGRES_DB=sample
+`), 0644)
+	require.NoError(t, err)
+
+	return dir
+}
+
 func createTempApplication(t *testing.T, filename string) (*app.Application, []byte, *os.File) {
 	tm := &metav1.TypeMeta{}
 	tm.SetGroupVersionKind(app.GroupVersion.WithKind("Application"))
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+)
+
+// NewHelmPostRendererCommand creates a command for patching rendered Helm chart output
+func NewHelmPostRendererCommand(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "helm-post-renderer TRIAL_NAME...",
+		Short: "Apply trial parameters to rendered Helm chart output",
+		Long: "Apply trial parameters to the rendered Helm chart manifests read from stdin.\n\n" +
+			"The experiment (or application) must be supplied using the filename option; only the patched chart " +
+			"manifests are written to stdout. Helm does not pass arguments to post-renderers, so this command is " +
+			"normally invoked from a small wrapper script, e.g. `exec redskyctl helm-post-renderer -f experiment.yaml my-app-5`, " +
+			"which is then used as the `--post-renderer` for `helm install` or `helm upgrade`.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			commander.SetStreams(&o.IOStreams, cmd)
+
+			var err error
+			if o.ExperimentsAPI == nil {
+				err = commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("a trial name must be specified")
+			}
+
+			if len(o.inputFiles) == 0 {
+				return fmt.Errorf("an experiment or application filename must be specified")
+			}
+
+			// The rendered chart is always read from stdin
+			o.inputFiles = append(o.inputFiles, "-")
+			o.trialNames = args
+			o.postRender = true
+
+			return err
+		},
+		RunE: commander.WithContextE(o.runner),
+	}
+
+	cmd.Flags().StringSliceVarP(&o.inputFiles, "filename", "f", nil, "experiment or application `files` used to produce the patches")
+
+	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	experimentsapi "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestSplitTrialName(t *testing.T) {
+	cases := []struct {
+		desc           string
+		name           string
+		experimentName string
+		number         int64
+	}{
+		{
+			desc:           "dash",
+			name:           "my-app-5",
+			experimentName: "my-app",
+			number:         5,
+		},
+		{
+			desc:           "slash",
+			name:           "my-app/5",
+			experimentName: "my-app",
+			number:         5,
+		},
+		{
+			desc:           "slash experiment ending in a number",
+			name:           "my-app-v2/005",
+			experimentName: "my-app-v2",
+			number:         5,
+		},
+		{
+			desc:           "slash without number",
+			name:           "my-app/latest",
+			experimentName: "my-app/latest",
+			number:         -1,
+		},
+		{
+			desc:           "slash without experiment",
+			name:           "/5",
+			experimentName: "/5",
+			number:         -1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			experimentName, number := splitTrialName(c.name)
+			assert.Equal(t, experimentsapi.NewExperimentName(c.experimentName), experimentName)
+			assert.Equal(t, c.number, number)
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 	konjurev1beta2 "github.com/thestormforge/konjure/pkg/api/core/v1beta2"
 	"github.com/thestormforge/konjure/pkg/konjure"
+	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	"github.com/thestormforge/optimize-controller/internal/application"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
 	"github.com/thestormforge/optimize-go/pkg/config"
@@ -34,9 +35,10 @@ type ApplicationOptions struct {
 	// IOStreams are used to access the standard process streams
 	commander.IOStreams
 
-	Generator       application.Generator
-	Resources       []string
-	DefaultResource konjurev1beta2.Kubernetes
+	Generator application.Generator
+	Resources []string
+	Kustomize string
+	Excludes  []string
 }
 
 func NewApplicationCommand(o *ApplicationOptions) *cobra.Command {
@@ -58,13 +60,17 @@ func NewApplicationCommand(o *ApplicationOptions) *cobra.Command {
 	cmd.Flags().StringVar(&o.Generator.Name, "name", "", "set the application `name`")
 	cmd.Flags().StringSliceVar(&o.Generator.Goals, "goals", nil, "specify the application optimization objective")
 	cmd.Flags().BoolVar(&o.Generator.Documentation.Disabled, "no-comments", false, "suppress documentation comments on output")
+	cmd.Flags().BoolVar(&o.Generator.PreferInternalIngress, "internal-ingress", false, "prefer the cluster internal service DNS name for the ingress URL")
 	cmd.Flags().StringVar(&o.Generator.ScenarioFile, "test-case-file", "", "specify either a StormForger (.js) or Locust (.py) test case `file`")
 	cmd.Flags().StringArrayVarP(&o.Resources, "resources", "r", nil, "additional resources to consider")
-	cmd.Flags().StringArrayVar(&o.DefaultResource.Namespaces, "namespace", nil, "select resources from a specific namespace")
-	cmd.Flags().StringVar(&o.DefaultResource.NamespaceSelector, "ns-selector", "", "`sel`ect resources from labeled namespaces")
-	cmd.Flags().StringVarP(&o.DefaultResource.Selector, "selector", "l", "", "`sel`ect only labeled resources")
+	cmd.Flags().StringVarP(&o.Kustomize, "kustomize", "k", "", "kustomization `dir`ectory to build and consider")
+	cmd.Flags().StringArrayVar(&o.Excludes, "exclude", nil, "ignore resources of the specified `kind`")
+	cmd.Flags().StringArrayVar(&o.Generator.Namespaces, "namespace", nil, "select resources from a specific namespace")
+	cmd.Flags().StringVar(&o.Generator.NamespaceSelector, "ns-selector", "", "`sel`ect resources from labeled namespaces")
+	cmd.Flags().StringVarP(&o.Generator.LabelSelector, "selector", "l", "", "`sel`ect only labeled resources")
 
 	_ = cmd.MarkFlagFilename("test-case-file", "js", "py")
+	_ = cmd.MarkFlagDirname("kustomize")
 
 	return cmd
 }
@@ -73,24 +79,17 @@ func (o *ApplicationOptions) generate() error {
 	if len(o.Resources) > 0 {
 		// Add explicitly requested resources
 		o.Generator.Resources = append(o.Generator.Resources, konjure.NewResource(o.Resources...))
-	} else if o.DefaultResource.Selector == "" && o.Generator.Name != "" {
-		// Add a default the label selector based on the name
-		o.DefaultResource.Selector = "app.kubernetes.io/name=" + o.Generator.Name
 	}
 
-	// Only include the default resource if it has values
-	if !o.isDefaultResourceEmpty() {
-		o.Generator.Resources = append(o.Generator.Resources, konjure.Resource{Kubernetes: &o.DefaultResource})
+	if o.Kustomize != "" {
+		// Add the explicitly requested kustomization
+		o.Generator.Resources = append(o.Generator.Resources, konjure.Resource{Kustomize: &konjurev1beta2.Kustomize{Root: o.Kustomize}})
+	}
+
+	for _, kind := range o.Excludes {
+		o.Generator.Excludes = append(o.Generator.Excludes, redskyappsv1alpha1.ResourceExclusion{Kind: kind})
 	}
 
 	// Generate the application
 	return o.Generator.Execute(&kio.ByteWriter{Writer: o.Out})
 }
-
-func (o *ApplicationOptions) isDefaultResourceEmpty() bool {
-	// TODO Should we check that `kubectl` is available and can return something meaningful?
-	return len(o.DefaultResource.Namespaces) == 0 &&
-		o.DefaultResource.NamespaceSelector == "" &&
-		len(o.DefaultResource.Types) == 0 &&
-		o.DefaultResource.Selector == ""
-}
@@ -23,6 +23,7 @@ import (
 	"unicode"
 
 	"github.com/spf13/cobra"
+	konjurev1beta2 "github.com/thestormforge/konjure/pkg/api/core/v1beta2"
 	"github.com/thestormforge/konjure/pkg/konjure"
 	redskyappsv1alpha1 "github.com/thestormforge/optimize-controller/api/apps/v1alpha1"
 	"github.com/thestormforge/optimize-controller/internal/experiment"
@@ -42,6 +43,12 @@ type ExperimentOptions struct {
 
 	Filename  string
 	Resources []string
+	Kustomize string
+	Excludes  []string
+	Explain   bool
+	Impact    bool
+	Suite     bool
+	Parallel  bool
 }
 
 // Other possible options:
@@ -71,12 +78,20 @@ func NewExperimentCommand(o *ExperimentOptions) *cobra.Command {
 
 	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "file that contains the application definition")
 	cmd.Flags().StringArrayVarP(&o.Resources, "resources", "r", nil, "additional resources to consider")
+	cmd.Flags().StringVarP(&o.Kustomize, "kustomize", "k", "", "kustomization `dir`ectory to build and consider")
+	cmd.Flags().StringArrayVar(&o.Excludes, "exclude", nil, "ignore resources of the specified `kind`")
 	cmd.Flags().StringVar(&o.Generator.ExperimentName, "name", o.Generator.ExperimentName, "override the experiment `name`")
 	cmd.Flags().StringVarP(&o.Generator.Scenario, "scenario", "s", o.Generator.Scenario, "the application scenario to generate an experiment for")
 	cmd.Flags().StringVar(&o.Generator.Objective, "objective", o.Generator.Objective, "the application objective to generate an experiment for")
 	cmd.Flags().BoolVar(&o.Generator.IncludeApplicationResources, "include-resources", false, "include the application resources in the output")
+	cmd.Flags().BoolVar(&o.Generator.LiveBaseline, "live-baseline", false, "use the replicas and resources currently deployed to the cluster as the baseline")
+	cmd.Flags().BoolVar(&o.Explain, "explain", false, "describe the restart behavior of the generated parameters on standard error")
+	cmd.Flags().BoolVar(&o.Impact, "impact", false, "report the objects, namespaces and estimated duration of each trial on standard error")
+	cmd.Flags().BoolVar(&o.Suite, "suite", false, "generate an experiment for every combination of scenario and objective")
+	cmd.Flags().BoolVar(&o.Parallel, "parallel", false, "allow the experiments of a suite to run at the same time instead of sequentially")
 
 	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+	_ = cmd.MarkFlagDirname("kustomize")
 
 	return cmd
 }
@@ -112,7 +127,18 @@ func (o *ExperimentOptions) generate() error {
 	}
 
 	// Generate the experiment
-	return o.Generator.Execute(o.YAMLWriter())
+	output := o.YAMLWriter()
+	if o.Explain {
+		output = explainWriter(o.ErrOut, output)
+	}
+	if o.Impact {
+		output = impactWriter(o.ErrOut, output)
+	}
+	if o.Suite {
+		suite := &experiment.SuiteGenerator{Generator: o.Generator, Parallel: o.Parallel}
+		return suite.Execute(output)
+	}
+	return o.Generator.Execute(output)
 }
 
 func (o *ExperimentOptions) filterResources(app *redskyappsv1alpha1.Application) error {
@@ -121,6 +147,16 @@ func (o *ExperimentOptions) filterResources(app *redskyappsv1alpha1.Application)
 		app.Resources = append(app.Resources, konjure.NewResource(o.Resources...))
 	}
 
+	// Add a kustomization to build (this allows overlays to be used directly when invoking the CLI)
+	if o.Kustomize != "" {
+		app.Resources = append(app.Resources, konjure.Resource{Kustomize: &konjurev1beta2.Kustomize{Root: o.Kustomize}})
+	}
+
+	// Add additional exclusions
+	for _, kind := range o.Excludes {
+		app.Excludes = append(app.Excludes, redskyappsv1alpha1.ResourceExclusion{Kind: kind})
+	}
+
 	// If there are no resources, assume the directory of the input file (or "." if no file is specified)
 	if len(app.Resources) == 0 {
 		app.Resources = append(app.Resources, konjure.NewResource(filepath.Dir(o.Filename)))
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/patch"
+	"github.com/thestormforge/optimize-controller/internal/template"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// rolloutTimeout is the maximum amount of time the controller waits for a patched object to become ready
+const rolloutTimeout = 3 * time.Minute
+
+// explainWriter returns a writer that describes the generated experiments before passing them to the supplied writer
+func explainWriter(w io.Writer, output kio.Writer) kio.Writer {
+	return kio.WriterFunc(func(nodes []*yaml.RNode) error {
+		for _, node := range nodes {
+			exp, err := decodeExperiment(node)
+			if err != nil {
+				return err
+			}
+			if exp == nil {
+				continue
+			}
+			if err := explain(w, exp); err != nil {
+				return err
+			}
+		}
+
+		return output.Write(nodes)
+	})
+}
+
+// decodeExperiment returns the experiment represented by the supplied node, or nil if the node is not an experiment
+func decodeExperiment(node *yaml.RNode) (*redskyv1beta1.Experiment, error) {
+	m, err := node.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	if m.Kind != "Experiment" || m.APIVersion != redskyv1beta1.GroupVersion.String() {
+		return nil, nil
+	}
+
+	data, err := node.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	exp := &redskyv1beta1.Experiment{}
+	if err := json.Unmarshal(data, exp); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// explain writes a description of the restart behavior of the experiment's parameters
+func explain(w io.Writer, exp *redskyv1beta1.Experiment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Experiment %q:\n\n", exp.Name)
+
+	_, _ = fmt.Fprintln(tw, "PARAMETER\tRESTART POLICY")
+	for _, p := range exp.Spec.Parameters {
+		rp := p.RestartPolicy
+		if rp == "" {
+			rp = redskyv1beta1.RestartPolicyAlways
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", p.Name, rp)
+	}
+	_, _ = fmt.Fprintln(tw)
+
+	rollouts := 0
+	te := template.New().WithParameters(exp.Spec.Parameters).WithDerived(exp.Spec.Derived)
+	_, _ = fmt.Fprintln(tw, "PATCH TARGET\tAPPLIED")
+	for i := range exp.Spec.Patches {
+		p := &exp.Spec.Patches[i]
+		live, err := patch.IsLivePatch(te, exp.Spec.Parameters, p)
+		if err != nil {
+			return err
+		}
+
+		target := fmt.Sprintf("patch %d", i+1)
+		if p.TargetRef != nil {
+			target = p.TargetRef.Kind + "/" + p.TargetRef.Name
+		}
+
+		if live {
+			_, _ = fmt.Fprintf(tw, "%s\tlive\n", target)
+		} else {
+			_, _ = fmt.Fprintf(tw, "%s\trollout\n", target)
+			rollouts++
+		}
+	}
+	_, _ = fmt.Fprintln(tw)
+
+	// Readiness checks are evaluated concurrently so the overhead does not grow with the number of rollouts
+	if rollouts > 0 {
+		_, _ = fmt.Fprintf(tw, "Estimated per-trial overhead: up to %s waiting for %d patched object(s) to roll out\n\n", rolloutTimeout, rollouts)
+	} else {
+		_, _ = fmt.Fprintf(tw, "Estimated per-trial overhead: none, all patches are applied live\n\n")
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExplain(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres"},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "cpu"},
+				{Name: "shared_buffers", RestartPolicy: redskyv1beta1.RestartPolicyNever},
+			},
+			Patches: []redskyv1beta1.PatchTemplate{
+				{
+					TargetRef: &corev1.ObjectReference{Kind: "Deployment", Name: "postgres"},
+					Patch:     `{"spec":{"template":{"spec":{"containers":[{"name":"postgres","resources":{"limits":{"cpu":"{{ .Values.cpu }}m"}}}]}}}}`,
+				},
+				{
+					TargetRef: &corev1.ObjectReference{Kind: "ConfigMap", Name: "postgres-config"},
+					Patch:     `{"data":{"shared_buffers":"{{ .Values.shared_buffers }}MB"}}`,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if assert.NoError(t, explain(&buf, exp)) {
+		assert.Equal(t, `Experiment "postgres":
+
+PARAMETER        RESTART POLICY
+cpu              Always
+shared_buffers   Never
+
+PATCH TARGET                APPLIED
+Deployment/postgres         rollout
+ConfigMap/postgres-config   live
+
+Estimated per-trial overhead: up to 3m0s waiting for 1 patched object(s) to roll out
+
+`, buf.String())
+	}
+}
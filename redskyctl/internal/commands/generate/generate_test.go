@@ -68,6 +68,7 @@ func TestGenerate(t *testing.T) {
 				"kind: Deployment",
 				"kind: ClusterRole",
 				"kind: ClusterRoleBinding",
+				"rbac.authorization.k8s.io/aggregate-to-edit",
 			},
 		},
 		{
@@ -0,0 +1,171 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/patch"
+	"github.com/thestormforge/optimize-controller/internal/template"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// trialNamespace is used in place of the namespace when trials do not run in the experiment namespace
+const trialNamespace = "(trial namespace)"
+
+// impactWriter returns a writer that reports the impact of running the generated experiments before passing them to
+// the supplied writer
+func impactWriter(w io.Writer, output kio.Writer) kio.Writer {
+	return kio.WriterFunc(func(nodes []*yaml.RNode) error {
+		var size int
+		for _, node := range nodes {
+			s, err := node.String()
+			if err != nil {
+				return err
+			}
+			size += len(s)
+		}
+		_, _ = fmt.Fprintf(w, "Generated %d object(s), %d bytes\n\n", len(nodes), size)
+
+		for _, node := range nodes {
+			exp, err := decodeExperiment(node)
+			if err != nil {
+				return err
+			}
+			if exp == nil {
+				continue
+			}
+			if err := impact(w, exp); err != nil {
+				return err
+			}
+		}
+
+		return output.Write(nodes)
+	})
+}
+
+// impact writes a description of the objects and namespaces each trial of the experiment changes
+func impact(w io.Writer, exp *redskyv1beta1.Experiment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Experiment %q:\n\n", exp.Name)
+
+	// Trials only run in the experiment namespace if there are no other namespaces to choose from
+	defaultNamespace := exp.Namespace
+	if exp.Spec.NamespaceSelector != nil || exp.Spec.NamespaceTemplate != nil {
+		defaultNamespace = trialNamespace
+	}
+	namespaces := map[string]bool{defaultNamespace: true}
+
+	rollouts := 0
+	te := template.New().WithParameters(exp.Spec.Parameters).WithDerived(exp.Spec.Derived)
+	_, _ = fmt.Fprintln(tw, "PATCH TARGET\tNAMESPACE\tROLLOUT")
+	for i := range exp.Spec.Patches {
+		p := &exp.Spec.Patches[i]
+		live, err := patch.IsLivePatch(te, exp.Spec.Parameters, p)
+		if err != nil {
+			return err
+		}
+
+		target, namespace, rollout := fmt.Sprintf("patch %d", i+1), defaultNamespace, "unknown"
+		if p.TargetRef != nil {
+			target = p.TargetRef.Kind + "/" + p.TargetRef.Name
+			if p.TargetRef.Namespace != "" {
+				namespace = p.TargetRef.Namespace
+			}
+			rollout = "no"
+			if !live && isWorkload(p.TargetRef.Kind) {
+				rollout = "yes"
+				rollouts++
+			}
+		}
+		namespaces[namespace] = true
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", target, namespace, rollout)
+	}
+	_, _ = fmt.Fprintln(tw)
+
+	names := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	_, _ = fmt.Fprintf(tw, "Objects patched per trial:\t%d\n", len(exp.Spec.Patches))
+	_, _ = fmt.Fprintf(tw, "Rollouts per trial:\t%d\n", rollouts)
+	_, _ = fmt.Fprintf(tw, "Namespaces touched:\t%s\n", strings.Join(names, ", "))
+	_, _ = fmt.Fprintf(tw, "Estimated per-trial duration:\t%s\n\n", trialDuration(&exp.Spec.TrialTemplate.Spec, rollouts))
+
+	return tw.Flush()
+}
+
+// trialDuration returns a description of how long a single trial is expected to take
+func trialDuration(spec *redskyv1beta1.TrialSpec, rollouts int) string {
+	var total time.Duration
+	var parts []string
+
+	if rollouts > 0 {
+		total += rolloutTimeout
+		parts = append(parts, fmt.Sprintf("up to %s rollout", rolloutTimeout))
+	}
+
+	if spec.InitialDelaySeconds > 0 {
+		d := time.Duration(spec.InitialDelaySeconds) * time.Second
+		total += d
+		parts = append(parts, fmt.Sprintf("%s initial delay", d))
+	}
+
+	known := true
+	switch {
+	case spec.ApproximateRuntime != nil:
+		total += spec.ApproximateRuntime.Duration
+		parts = append(parts, fmt.Sprintf("%s trial run", spec.ApproximateRuntime.Duration))
+	case spec.JobTemplate != nil && spec.JobTemplate.Spec.ActiveDeadlineSeconds != nil:
+		d := time.Duration(*spec.JobTemplate.Spec.ActiveDeadlineSeconds) * time.Second
+		total += d
+		parts = append(parts, fmt.Sprintf("up to %s trial run", d))
+	default:
+		known = false
+		parts = append(parts, "unknown trial run")
+	}
+
+	if len(spec.SetupTasks) > 0 {
+		known = false
+		parts = append(parts, fmt.Sprintf("%d setup task(s)", len(spec.SetupTasks)))
+	}
+
+	if known {
+		return fmt.Sprintf("%s (%s)", total, strings.Join(parts, ", "))
+	}
+	return fmt.Sprintf("at least %s (%s)", total, strings.Join(parts, ", "))
+}
+
+// isWorkload checks to see if changes to the kind of object trigger a rollout of new pods
+func isWorkload(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return true
+	default:
+		return false
+	}
+}
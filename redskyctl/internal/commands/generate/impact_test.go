@@ -0,0 +1,113 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImpact(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Parameters: []redskyv1beta1.Parameter{
+				{Name: "cpu"},
+				{Name: "shared_buffers", RestartPolicy: redskyv1beta1.RestartPolicyNever},
+			},
+			Patches: []redskyv1beta1.PatchTemplate{
+				{
+					TargetRef: &corev1.ObjectReference{Kind: "Deployment", Name: "postgres"},
+					Patch:     `{"spec":{"template":{"spec":{"containers":[{"name":"postgres","resources":{"limits":{"cpu":"{{ .Values.cpu }}m"}}}]}}}}`,
+				},
+				{
+					TargetRef: &corev1.ObjectReference{Kind: "ConfigMap", Name: "postgres-config"},
+					Patch:     `{"data":{"shared_buffers":"{{ .Values.shared_buffers }}MB"}}`,
+				},
+				{
+					TargetRef: &corev1.ObjectReference{Kind: "Deployment", Name: "exporter", Namespace: "monitoring"},
+					Patch:     `{"spec":{"template":{"spec":{"containers":[{"name":"exporter","resources":{"limits":{"cpu":"{{ .Values.cpu }}m"}}}]}}}}`,
+				},
+			},
+			TrialTemplate: redskyv1beta1.TrialTemplateSpec{
+				Spec: redskyv1beta1.TrialSpec{
+					InitialDelaySeconds: 30,
+					ApproximateRuntime:  &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if assert.NoError(t, impact(&buf, exp)) {
+		assert.Equal(t, `Experiment "postgres":
+
+PATCH TARGET                NAMESPACE    ROLLOUT
+Deployment/postgres         default      yes
+ConfigMap/postgres-config   default      no
+Deployment/exporter         monitoring   yes
+
+Objects patched per trial:      3
+Rollouts per trial:             2
+Namespaces touched:             default, monitoring
+Estimated per-trial duration:   8m30s (up to 3m0s rollout, 30s initial delay, 5m0s trial run)
+
+`, buf.String())
+	}
+}
+
+func TestTrialDuration(t *testing.T) {
+	deadline := int64(600)
+	cases := []struct {
+		desc     string
+		spec     redskyv1beta1.TrialSpec
+		rollouts int
+		expected string
+	}{
+		{
+			desc:     "unknown",
+			expected: "at least 0s (unknown trial run)",
+		},
+		{
+			desc:     "deadline",
+			spec:     redskyv1beta1.TrialSpec{JobTemplate: &batchv1beta1.JobTemplateSpec{Spec: batchv1.JobSpec{ActiveDeadlineSeconds: &deadline}}},
+			rollouts: 1,
+			expected: "13m0s (up to 3m0s rollout, up to 10m0s trial run)",
+		},
+		{
+			desc: "setup tasks",
+			spec: redskyv1beta1.TrialSpec{
+				ApproximateRuntime: &metav1.Duration{Duration: time.Minute},
+				SetupTasks:         []redskyv1beta1.SetupTask{{Name: "monitoring"}},
+			},
+			expected: "at least 1m0s (1m0s trial run, 1 setup task(s))",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, trialDuration(&c.spec, c.rollouts))
+		})
+	}
+}
@@ -54,8 +54,10 @@ type RBACOptions struct {
 	IncludeNames       bool
 	ClusterRole        bool
 	ClusterRoleBinding bool
+	Scoped             bool
 
-	mapper meta.RESTMapper
+	mapper           meta.RESTMapper
+	defaultNamespace string
 }
 
 func NewRBACCommand(o *RBACOptions) *cobra.Command {
@@ -81,6 +83,7 @@ func NewRBACCommand(o *RBACOptions) *cobra.Command {
 	cmd.Flags().BoolVar(&o.IncludeNames, "include-names", o.IncludeNames, "include resource names in the generated role")
 	cmd.Flags().BoolVar(&o.ClusterRole, "cluster-role", o.ClusterRole, "generate a cluster role")
 	cmd.Flags().BoolVar(&o.ClusterRoleBinding, "cluster-role-binding", o.ClusterRoleBinding, "when generating a cluster role, also generate a cluster role binding")
+	cmd.Flags().BoolVar(&o.Scoped, "scoped", o.Scoped, "generate the minimal roles required in each namespace, implies --include-names")
 
 	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
 	_ = cmd.MarkFlagRequired("filename")
@@ -144,14 +147,25 @@ func (o *RBACOptions) generate() error {
 		return err
 	}
 
-	// Discover the policy rules from the experiments and collapse them
-	var experimentRules []*rbacv1.PolicyRule
+	// Discover the policy rules from the experiments
+	var experimentRules []scopedRule
 	for i := range experimentList.Items {
 		experimentRules = o.appendRules(experimentRules, &experimentList.Items[i])
 	}
+
+	// Scoped roles only include the rules for the namespace they are bound in
+	if o.Scoped {
+		rbac := buildScopedRBAC(roleRef, subject, experimentRules)
+		if len(rbac.Items) == 0 {
+			return nil
+		}
+		return o.Printer.PrintObj(rbac, o.Out)
+	}
+
+	// Collapse the rules
 	rules := make([]rbacv1.PolicyRule, 0, len(experimentRules))
 	for _, r := range experimentRules {
-		rules = mergeRule(rules, r)
+		rules = mergeRule(rules, r.rule)
 	}
 	if len(rules) == 0 {
 		return nil
@@ -174,9 +188,12 @@ func (o *RBACOptions) bindingTargets(experimentList *redskyv1beta1.ExperimentLis
 		return nil, nil, nil, err
 	}
 
+	// Experiments without a namespace are created in the default namespace of the cluster
+	o.defaultNamespace = cstr.Namespace
+
 	// Create the role reference
 	roleRef := &rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: o.Name}
-	if o.ClusterRole {
+	if o.ClusterRole && !o.Scoped {
 		roleRef.Kind = "ClusterRole"
 	}
 	if roleRef.Name == "" {
@@ -192,8 +209,8 @@ func (o *RBACOptions) bindingTargets(experimentList *redskyv1beta1.ExperimentLis
 
 	// Namespaces
 	var namespaces []string
-	if !o.ClusterRole || !o.ClusterRoleBinding {
-		// Get the distinct list of namespaces from the experiments
+	if (!o.ClusterRole || !o.ClusterRoleBinding) && !o.Scoped {
+		// Get the distinct list of namespaces from the experiments and the namespace qualified patch targets
 		distinct := make(map[string]struct{}, len(experimentList.Items))
 		for i := range experimentList.Items {
 			ns := experimentList.Items[i].Namespace
@@ -201,12 +218,19 @@ func (o *RBACOptions) bindingTargets(experimentList *redskyv1beta1.ExperimentLis
 				ns = cstr.Namespace
 			}
 			distinct[ns] = struct{}{}
+
+			for _, p := range experimentList.Items[i].Spec.Patches {
+				if p.TargetRef != nil && p.TargetRef.Namespace != "" {
+					distinct[p.TargetRef.Namespace] = struct{}{}
+				}
+			}
 		}
 
 		namespaces = make([]string, 0, len(distinct))
 		for k := range distinct {
 			namespaces = append(namespaces, k)
 		}
+		sort.Strings(namespaces)
 	}
 
 	// The manager runs as the default service account
@@ -271,36 +295,127 @@ func buildRBAC(roleRef *rbacv1.RoleRef, subject *rbacv1.Subject, rules []rbacv1.
 	return result
 }
 
-// appendRules finds the patch and readiness targets from an experiment
-func (o *RBACOptions) appendRules(rules []*rbacv1.PolicyRule, exp *redskyv1beta1.Experiment) []*rbacv1.PolicyRule {
+// scopedRule is a policy rule along with the namespace it is required in, an empty namespace means the rule is
+// required in every namespace
+type scopedRule struct {
+	namespace string
+	rule      *rbacv1.PolicyRule
+}
+
+// buildScopedRBAC returns a role and role binding for each namespace containing only the rules required in that
+// namespace, rules required in every namespace are included in a cluster role with a cluster role binding
+func buildScopedRBAC(roleRef *rbacv1.RoleRef, subject *rbacv1.Subject, experimentRules []scopedRule) *corev1.List {
+	rules := make(map[string][]rbacv1.PolicyRule)
+	for _, r := range experimentRules {
+		rules[r.namespace] = mergeRule(rules[r.namespace], r.rule)
+	}
+
+	namespaces := make([]string, 0, len(rules))
+	for ns := range rules {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	result := &corev1.List{}
+	for _, ns := range namespaces {
+		if ns == "" {
+			clusterRoleRef := *roleRef
+			clusterRoleRef.Kind = "ClusterRole"
+			result.Items = append(result.Items, buildRBAC(&clusterRoleRef, subject, rules[ns], nil).Items...)
+			continue
+		}
+		result.Items = append(result.Items, buildRBAC(roleRef, subject, rules[ns], []string{ns}).Items...)
+	}
+	return result
+}
+
+// appendRules finds the patch, readiness, metric and trial namespace targets from an experiment
+func (o *RBACOptions) appendRules(rules []scopedRule, exp *redskyv1beta1.Experiment) []scopedRule {
+	// Unqualified targets are in the trial namespace, which is only known if trials run in the experiment namespace
+	trialNamespace := exp.Namespace
+	if trialNamespace == "" {
+		trialNamespace = o.defaultNamespace
+	}
+	if exp.Spec.NamespaceSelector != nil || exp.Spec.NamespaceTemplate != nil {
+		trialNamespace = ""
+	}
+	add := func(namespace string, rule *rbacv1.PolicyRule) {
+		if namespace == "" {
+			namespace = trialNamespace
+		}
+		rules = append(rules, scopedRule{namespace: namespace, rule: rule})
+	}
+
 	// Patches require "get" and "patch" permissions
 	for i := range exp.Spec.Patches {
 		// TODO This needs to use patch_controller.go `renderTemplate` to get the correct reference (e.g. SMP may have the ref in the payload)
 		// NOTE: Technically we can not get the target reference without an actual trial; in most cases a dummy trial should work
 		ref := exp.Spec.Patches[i].TargetRef
 		if ref != nil {
-			rules = append(rules, o.newPolicyRule(ref, "get", "patch"))
+			add(ref.Namespace, o.newPolicyRule(ref, "get", "patch"))
 		}
 	}
 
-	// Readiness gates with no name require "list" permissions
+	// Readiness gates with no name require "list" permissions, readiness gates will be converted to readiness checks
+	// so named gates require "get" permissions
 	for i := range exp.Spec.TrialTemplate.Spec.ReadinessGates {
 		rg := &exp.Spec.TrialTemplate.Spec.ReadinessGates[i]
+		ref := &corev1.ObjectReference{Kind: rg.Kind, APIVersion: rg.APIVersion, Name: rg.Name}
 		if rg.Name == "" {
-			ref := &corev1.ObjectReference{
-				Kind:       rg.Kind,
-				APIVersion: rg.APIVersion,
+			add("", o.newPolicyRule(ref, "list"))
+		} else {
+			add("", o.newPolicyRule(ref, "get"))
+		}
+	}
+
+	// Metric targets require "get" or "list" permissions depending on how they are referenced
+	for i := range exp.Spec.Metrics {
+		m := &exp.Spec.Metrics[i]
+		if m.Target == nil || m.Target.Kind == "Trial" {
+			continue
+		}
+		switch m.Type {
+		case redskyv1beta1.MetricKubernetes, "",
+			redskyv1beta1.MetricDatadog, redskyv1beta1.MetricInfluxDB, redskyv1beta1.MetricElasticsearch:
+		case redskyv1beta1.MetricPrometheus:
+			if m.Target.Kind != "Secret" {
+				continue
 			}
-			rules = append(rules, o.newPolicyRule(ref, "list"))
+		default:
+			continue
+		}
+
+		ref := &corev1.ObjectReference{Kind: m.Target.Kind, APIVersion: m.Target.APIVersion, Name: m.Target.Name}
+		if ref.Name == "" {
+			add(m.Target.Namespace, o.newPolicyRule(ref, "list"))
+		} else {
+			add(m.Target.Namespace, o.newPolicyRule(ref, "get"))
 		}
 	}
 
-	// Readiness gates will be converted to readiness checks; therefore we need the same check on non-empty names
-	for i := range exp.Spec.TrialTemplate.Spec.ReadinessGates {
-		r := &exp.Spec.TrialTemplate.Spec.ReadinessGates[i]
-		if r.Name == "" {
-			ref := &corev1.ObjectReference{Kind: r.Kind, APIVersion: r.APIVersion}
-			rules = append(rules, o.newPolicyRule(ref, "list"))
+	// Creating trial namespaces requires creating the supporting objects for the setup tasks; the rules granted to the
+	// setup service account must also be held by the controller
+	if nt := exp.Spec.NamespaceTemplate; nt != nil {
+		spec := &exp.Spec.TrialTemplate.Spec
+		add("", &rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"namespaces", "serviceaccounts"}})
+		if nt.ResourceQuota != nil {
+			add("", &rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"resourcequotas"}})
+		}
+		if nt.LimitRange != nil {
+			add("", &rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"limitranges"}})
+		}
+		if nt.NetworkPolicy != nil {
+			add("", &rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies"}})
+		}
+		if len(spec.SetupDefaultRules) > 0 {
+			add("", &rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"rolebindings", "roles"}})
+			for j := range spec.SetupDefaultRules {
+				add("", spec.SetupDefaultRules[j].DeepCopy())
+			}
+		}
+		if spec.SetupDefaultClusterRole != "" {
+			add("", &rbacv1.PolicyRule{Verbs: []string{"create"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"rolebindings"}})
+			add("", &rbacv1.PolicyRule{Verbs: []string{"bind"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, ResourceNames: []string{spec.SetupDefaultClusterRole}})
 		}
 	}
 
@@ -326,7 +441,7 @@ func (o *RBACOptions) newPolicyRule(ref *corev1.ObjectReference, verbs ...string
 	r.Resources = []string{m.Resource.Resource}
 
 	// Include the resource name if requested and available
-	if o.IncludeNames && ref.Name != "" {
+	if (o.IncludeNames || o.Scoped) && ref.Name != "" {
 		r.ResourceNames = []string{ref.Name}
 	}
 
@@ -375,6 +490,9 @@ func mergeRule(rules []rbacv1.PolicyRule, rule *rbacv1.PolicyRule) []rbacv1.Poli
 		if len(r.ResourceNames) > 0 && doesNotMatch(r.Resources, rule.Resources) {
 			continue
 		}
+		if (len(r.ResourceNames) > 0) != (len(rule.ResourceNames) > 0) {
+			continue
+		}
 
 		for _, rr := range rule.Resources {
 			r.Resources = appendMissing(r.Resources, rr)
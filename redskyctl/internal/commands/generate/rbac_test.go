@@ -0,0 +1,110 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBuildScopedRBAC(t *testing.T) {
+	rm := meta.NewDefaultRESTMapper(nil)
+	rm.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeRoot)
+	rm.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeRoot)
+	rm.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeRoot)
+	o := &RBACOptions{Scoped: true, mapper: rm, defaultNamespace: "default"}
+
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "app"},
+		Spec: redskyv1beta1.ExperimentSpec{
+			Patches: []redskyv1beta1.PatchTemplate{
+				{TargetRef: &corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+				{TargetRef: &corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: "settings", Namespace: "config"}},
+			},
+			Metrics: []redskyv1beta1.Metric{
+				{Name: "latency", Type: redskyv1beta1.MetricPrometheus},
+				{Name: "cost", Target: &redskyv1beta1.ResourceTarget{APIVersion: "v1", Kind: "Service", LabelSelector: &metav1.LabelSelector{}}},
+			},
+		},
+	}
+
+	roleRef := &rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "test-role"}
+	subject := &rbacv1.Subject{Kind: "ServiceAccount", Name: "default", Namespace: "redsky-system"}
+	rbac := buildScopedRBAC(roleRef, subject, o.appendRules(nil, exp))
+
+	roles := make(map[string][]rbacv1.PolicyRule)
+	var bindings []string
+	for _, item := range rbac.Items {
+		switch obj := item.Object.(type) {
+		case *rbacv1.Role:
+			roles[obj.Namespace] = obj.Rules
+		case *rbacv1.RoleBinding:
+			bindings = append(bindings, obj.Namespace)
+		default:
+			t.Errorf("unexpected object %T", item.Object)
+		}
+	}
+
+	assert.Equal(t, []string{"app", "config"}, bindings)
+	assert.Equal(t, map[string][]rbacv1.PolicyRule{
+		"app": {
+			{Verbs: []string{"get", "patch"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}},
+			{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"services"}},
+		},
+		"config": {
+			{Verbs: []string{"get", "patch"}, APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"settings"}},
+		},
+	}, roles)
+}
+
+func TestBuildScopedRBAC_NamespaceTemplate(t *testing.T) {
+	o := &RBACOptions{Scoped: true, mapper: meta.NewDefaultRESTMapper(nil), defaultNamespace: "default"}
+
+	exp := &redskyv1beta1.Experiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: redskyv1beta1.ExperimentSpec{
+			NamespaceTemplate: &redskyv1beta1.NamespaceTemplateSpec{},
+			TrialTemplate: redskyv1beta1.TrialTemplateSpec{
+				Spec: redskyv1beta1.TrialSpec{
+					SetupDefaultClusterRole: "cluster-admin",
+				},
+			},
+		},
+	}
+
+	roleRef := &rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "test-role"}
+	subject := &rbacv1.Subject{Kind: "ServiceAccount", Name: "default", Namespace: "redsky-system"}
+	rbac := buildScopedRBAC(roleRef, subject, o.appendRules(nil, exp))
+
+	if assert.Len(t, rbac.Items, 2) {
+		if cr, ok := rbac.Items[0].Object.(*rbacv1.ClusterRole); assert.True(t, ok) {
+			assert.Equal(t, []rbacv1.PolicyRule{
+				{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"namespaces", "serviceaccounts"}},
+				{Verbs: []string{"create"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"rolebindings"}},
+				{Verbs: []string{"bind"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, ResourceNames: []string{"cluster-admin"}},
+			}, cr.Rules)
+		}
+		assert.IsType(t, &rbacv1.ClusterRoleBinding{}, rbac.Items[1].Object)
+	}
+}
@@ -121,7 +121,7 @@ func (o *TrialOptions) generate() error {
 	// Build the trial
 	t := &redskyv1beta1.Trial{}
 	experiment.PopulateTrialFromTemplate(exp, t)
-	server.ToClusterTrial(t, &ta)
+	server.ToClusterTrial(t, &ta, exp)
 
 	// NOTE: Leaving the trial name empty and generateName non-empty means that you MUST use `kubectl create` and not `apply`
 
@@ -135,7 +135,7 @@ func (o *TrialOptions) generate() error {
 	}
 
 	// Convert the trial into a job
-	job, err := newJob(t, o.Job, o.JobTrialNumber)
+	job, err := newJob(exp, t, o.Job, o.JobTrialNumber)
 	if err != nil {
 		return err
 	}
@@ -143,7 +143,7 @@ func (o *TrialOptions) generate() error {
 	return o.Printer.PrintObj(job, o.Out)
 }
 
-func newJob(t *redskyv1beta1.Trial, mode string, trialNumber int) (*batchv1.Job, error) {
+func newJob(exp *redskyv1beta1.Experiment, t *redskyv1beta1.Trial, mode string, trialNumber int) (*batchv1.Job, error) {
 	// Make sure the trial has a name when generating the jobs or we produce invalid output
 	if t.Name == "" {
 		t.Name = fmt.Sprintf("%s%d", t.GenerateName, trialNumber)
@@ -155,7 +155,7 @@ func newJob(t *redskyv1beta1.Trial, mode string, trialNumber int) (*batchv1.Job,
 	}
 
 	// Create the setup job
-	job, err := setup.NewJob(t, mode)
+	job, err := setup.NewJob(exp, t, mode)
 	if err != nil {
 		return nil, err
 	}
@@ -17,13 +17,16 @@ limitations under the License.
 package results
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os/user"
 	"time"
 
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/config"
 )
 
@@ -31,12 +34,15 @@ import (
 type Options struct {
 	// Config is the Red Sky Configuration to get redirect URLs from
 	Config *config.RedSkyConfig
+	// ExperimentsAPI is used to serve the trials of an experiment
+	ExperimentsAPI experimentsv1alpha1.API
 	// IOStreams are used to access the standard process streams
 	commander.IOStreams
 
 	ServerAddress string
 	DisplayURL    bool
 	IdleTimeout   time.Duration
+	ChunkSize     int
 }
 
 // NewCommand creates a new command for displaying the results UI
@@ -46,22 +52,33 @@ func NewCommand(o *Options) *cobra.Command {
 		Short:      "View a visualization of the results",
 		Deprecated: "you can now access your results anytime using the web interface",
 
-		PreRun: commander.StreamsPreRun(&o.IOStreams),
-		RunE:   commander.WithoutArgsE(o.results),
+		Args: cobra.NoArgs,
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			commander.SetStreams(&o.IOStreams, cmd)
+			if o.ServerAddress == "" {
+				return nil
+			}
+			return commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+		},
+		RunE: commander.WithContextE(o.results),
 	}
 
-	// Keep the flags so we don't fail, but mark the all as hidden
-	cmd.Flags().StringVar(&o.ServerAddress, "address", "", "ignored for compatibility")
+	// The address serves the paged trials endpoint used by the results interface
+	cmd.Flags().StringVar(&o.ServerAddress, "address", "", "serve the trials API on the specified `address` instead of opening a browser")
 	cmd.Flags().BoolVar(&o.DisplayURL, "url", false, "display the URL instead of opening a browser")
-	cmd.Flags().DurationVar(&o.IdleTimeout, "idle-timeout", 5*time.Second, "ignored for compatibility")
-	_ = cmd.Flags().MarkHidden("address")
+	cmd.Flags().DurationVar(&o.IdleTimeout, "idle-timeout", 5*time.Second, "`duration` to wait for requests before shutting the trials API down")
+	cmd.Flags().IntVar(&o.ChunkSize, "chunk-size", 500, "maximum number of trials to process at once while serving the trials API")
 	_ = cmd.Flags().MarkHidden("url")
-	_ = cmd.Flags().MarkHidden("idle-timeout")
 
 	return cmd
 }
 
-func (o *Options) results() error {
+func (o *Options) results(ctx context.Context) error {
+	if o.ServerAddress != "" {
+		return o.serve(ctx)
+	}
+
 	s, err := config.CurrentServer(o.Config.Reader())
 	if err != nil {
 		return err
@@ -87,3 +104,20 @@ func (o *Options) results() error {
 
 	return nil
 }
+
+// serve runs the trials API until it has been idle for the configured timeout
+func (o *Options) serve(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/api/experiments/", &TrialsHandler{ExperimentsAPI: o.ExperimentsAPI, ChunkSize: o.ChunkSize})
+
+	server := commander.NewContextServer(ctx, mux,
+		commander.WithServerOptions(func(srv *http.Server) { srv.Addr = o.ServerAddress }),
+		commander.ShutdownOnInterrupt(func() { _, _ = fmt.Fprintln(o.Out) }),
+		commander.ShutdownOnIdle(o.IdleTimeout, func() { _, _ = fmt.Fprintln(o.Out, "Shutting down idle trials API") }),
+		commander.HandleStart(func(loc string) error {
+			_, _ = fmt.Fprintf(o.Out, "Serving trials from %sapi/experiments/{name}/trials\n", loc)
+			return nil
+		}))
+
+	return server.ListenAndServe()
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commands/experiments"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// defaultPageSize is the number of trials on a page when no page size is requested
+	defaultPageSize = 50
+	// maxPageSize is the largest number of trials returned on a single page
+	maxPageSize = 500
+)
+
+// TrialsHandler serves filtered, sorted pages of the trials of an experiment from `/api/experiments/{name}/trials`
+// so the results interface never needs to load the full trial list. The supported query parameters are:
+//
+//	page           the page number, starting at 1
+//	pageSize       the number of trials per page (at most 500)
+//	status         a comma separated list of trial statuses, e.g. "completed,failed"
+//	labelSelector  a Kubernetes label selector matched against the trial labels
+//	metric         a metric range as `name:min:max`, either bound may be empty; may be repeated
+//	sort           a JSONPath expression, e.g. "values.latency.value"; a leading "-" reverses the order
+type TrialsHandler struct {
+	// ExperimentsAPI is used to fetch the trials
+	ExperimentsAPI experimentsv1alpha1.API
+	// ChunkSize is the maximum number of trials processed at once
+	ChunkSize int
+}
+
+// TrialPage is a single page of trials
+type TrialPage struct {
+	// Total is the number of trials matching the filters
+	Total int `json:"total"`
+	// Page is the page number, starting at 1
+	Page int `json:"page"`
+	// PageSize is the maximum number of trials on the page
+	PageSize int `json:"pageSize"`
+	// Trials are the trials on the page
+	Trials []experimentsv1alpha1.TrialItem `json:"trials"`
+}
+
+// trialsQuery is the parsed query of a trial page request
+type trialsQuery struct {
+	page     int
+	pageSize int
+	status   []experimentsv1alpha1.TrialStatus
+	selector labels.Selector
+	metrics  []metricRange
+	sortBy   string
+}
+
+// metricRange is an inclusive range of metric values, nil bounds are unbounded
+type metricRange struct {
+	name     string
+	min, max *float64
+}
+
+var _ http.Handler = &TrialsHandler{}
+
+// ServeHTTP responds with a single page of trials.
+func (h *TrialsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/experiments/"), "/trials")
+	if name == "" || strings.Contains(name, "/") || !strings.HasSuffix(r.URL.Path, "/trials") {
+		http.NotFound(w, r)
+		return
+	}
+
+	q, err := parseTrialsQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.trialPage(r.Context(), experimentsv1alpha1.NewExperimentName(name), q)
+	if err != nil {
+		var eerr *experimentsv1alpha1.Error
+		if errors.As(err, &eerr) && eerr.Type == experimentsv1alpha1.ErrExperimentNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// trialPage fetches the trials of the experiment in chunks, only retaining the trials which match the query.
+func (h *TrialsHandler) trialPage(ctx context.Context, name experimentsv1alpha1.ExperimentName, q *trialsQuery) (*TrialPage, error) {
+	exp, err := h.ExperimentsAPI.GetExperimentByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []experimentsv1alpha1.TrialItem
+	lq := &experimentsv1alpha1.TrialListQuery{Status: q.status}
+	if err := experiments.ForEachTrial(ctx, h.ExperimentsAPI, &exp, lq, h.ChunkSize, func(t *experimentsv1alpha1.TrialItem) error {
+		if q.matches(t) {
+			item := *t
+			item.Experiment = nil
+			matched = append(matched, item)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if q.sortBy != "" {
+		experiments.SortTrials(matched, q.sortBy)
+	}
+
+	start := (q.page - 1) * q.pageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + q.pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return &TrialPage{
+		Total:    len(matched),
+		Page:     q.page,
+		PageSize: q.pageSize,
+		Trials:   append([]experimentsv1alpha1.TrialItem{}, matched[start:end]...),
+	}, nil
+}
+
+// parseTrialsQuery parses the query parameters of a trial page request.
+func parseTrialsQuery(values url.Values) (*trialsQuery, error) {
+	q := &trialsQuery{page: 1, pageSize: defaultPageSize, sortBy: values.Get("sort")}
+
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return nil, fmt.Errorf("invalid page: %s", v)
+		}
+		q.page = page
+	}
+
+	if v := values.Get("pageSize"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return nil, fmt.Errorf("invalid page size: %s", v)
+		}
+		q.pageSize = pageSize
+	}
+
+	if v := values.Get("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			q.status = append(q.status, experimentsv1alpha1.TrialStatus(strings.TrimSpace(s)))
+		}
+	} else {
+		q.status = []experimentsv1alpha1.TrialStatus{experimentsv1alpha1.TrialActive, experimentsv1alpha1.TrialCompleted, experimentsv1alpha1.TrialFailed}
+	}
+
+	sel, err := labels.Parse(values.Get("labelSelector"))
+	if err != nil {
+		return nil, err
+	}
+	q.selector = sel
+
+	for _, v := range values["metric"] {
+		mr, err := parseMetricRange(v)
+		if err != nil {
+			return nil, err
+		}
+		q.metrics = append(q.metrics, *mr)
+	}
+
+	return q, nil
+}
+
+// parseMetricRange parses a metric range of the form `name:min:max`.
+func parseMetricRange(v string) (*metricRange, error) {
+	parts := strings.Split(v, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid metric range, expected name:min:max: %s", v)
+	}
+
+	mr := &metricRange{name: parts[0]}
+	for i, bound := range []**float64{&mr.min, &mr.max} {
+		if parts[i+1] == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(parts[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric range bound for %s: %w", mr.name, err)
+		}
+		*bound = &f
+	}
+	return mr, nil
+}
+
+// matches checks to see if the trial satisfies the label selector and metric ranges of the query.
+func (q *trialsQuery) matches(t *experimentsv1alpha1.TrialItem) bool {
+	if !q.selector.Matches(labels.Set(t.Labels)) {
+		return false
+	}
+	for i := range q.metrics {
+		if !q.metrics[i].matches(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches checks to see if the trial has a value for the metric within the range.
+func (r *metricRange) matches(t *experimentsv1alpha1.TrialItem) bool {
+	for i := range t.Values {
+		if t.Values[i].MetricName != r.name {
+			continue
+		}
+		v := t.Values[i].Value
+		return (r.min == nil || v >= *r.min) && (r.max == nil || v <= *r.max)
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// trialsAPI serves a single experiment with a fixed list of trials
+type trialsAPI struct {
+	experimentsv1alpha1.API
+	trials []experimentsv1alpha1.TrialItem
+	query  *experimentsv1alpha1.TrialListQuery
+}
+
+func (api *trialsAPI) GetExperimentByName(_ context.Context, n experimentsv1alpha1.ExperimentName) (experimentsv1alpha1.Experiment, error) {
+	if n.Name() != "my-exp" {
+		return experimentsv1alpha1.Experiment{}, &experimentsv1alpha1.Error{Type: experimentsv1alpha1.ErrExperimentNotFound}
+	}
+	return experimentsv1alpha1.Experiment{TrialsURL: "trials"}, nil
+}
+
+func (api *trialsAPI) GetAllTrials(_ context.Context, _ string, q *experimentsv1alpha1.TrialListQuery) (experimentsv1alpha1.TrialList, error) {
+	api.query = q
+	return experimentsv1alpha1.TrialList{Trials: append([]experimentsv1alpha1.TrialItem(nil), api.trials...)}, nil
+}
+
+func TestTrialsHandler(t *testing.T) {
+	api := &trialsAPI{}
+	for i := int64(1); i <= 10; i++ {
+		item := experimentsv1alpha1.TrialItem{Number: i}
+		item.Values = []experimentsv1alpha1.Value{{MetricName: "latency", Value: float64(100 - i*10)}}
+		item.Labels = map[string]string{"best": "false"}
+		if i%2 == 0 {
+			item.Labels["best"] = "true"
+		}
+		api.trials = append(api.trials, item)
+	}
+	h := &TrialsHandler{ExperimentsAPI: api, ChunkSize: 3}
+
+	cases := []struct {
+		desc     string
+		target   string
+		status   int
+		total    int
+		expected []int64
+	}{
+		{
+			desc:     "first page",
+			target:   "/api/experiments/my-exp/trials?pageSize=4",
+			status:   http.StatusOK,
+			total:    10,
+			expected: []int64{1, 2, 3, 4},
+		},
+		{
+			desc:     "last page",
+			target:   "/api/experiments/my-exp/trials?pageSize=4&page=3",
+			status:   http.StatusOK,
+			total:    10,
+			expected: []int64{9, 10},
+		},
+		{
+			desc:     "sorted",
+			target:   "/api/experiments/my-exp/trials?pageSize=3&sort=values.latency.value",
+			status:   http.StatusOK,
+			total:    10,
+			expected: []int64{10, 9, 8},
+		},
+		{
+			desc:     "filtered",
+			target:   "/api/experiments/my-exp/trials?labelSelector=best%3Dtrue&metric=latency:20:60&sort=-number",
+			status:   http.StatusOK,
+			total:    3,
+			expected: []int64{8, 6, 4},
+		},
+		{
+			desc:   "invalid metric range",
+			target: "/api/experiments/my-exp/trials?metric=latency",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "unknown experiment",
+			target: "/api/experiments/other/trials",
+			status: http.StatusNotFound,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, c.target, nil))
+			if !assert.Equal(t, c.status, w.Code) || c.status != http.StatusOK {
+				return
+			}
+
+			page := &TrialPage{}
+			if assert.NoError(t, json.NewDecoder(w.Body).Decode(page)) {
+				var numbers []int64
+				for _, item := range page.Trials {
+					numbers = append(numbers, item.Number)
+				}
+				assert.Equal(t, c.total, page.Total)
+				assert.Equal(t, c.expected, numbers)
+			}
+		})
+	}
+}
+
+func TestTrialsHandler_Status(t *testing.T) {
+	api := &trialsAPI{}
+	h := &TrialsHandler{ExperimentsAPI: api}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/experiments/my-exp/trials?status=completed,failed", nil))
+	if assert.Equal(t, http.StatusOK, w.Code) && assert.NotNil(t, api.query) {
+		assert.Equal(t, []experimentsv1alpha1.TrialStatus{experimentsv1alpha1.TrialCompleted, experimentsv1alpha1.TrialFailed}, api.query.Status)
+	}
+}
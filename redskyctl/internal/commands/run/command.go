@@ -291,12 +291,18 @@ func (o *Options) refreshTrials() tea.Msg {
 	if err != nil {
 		return fmt.Errorf("could not get experiment for status, %w", err)
 	}
+	var notSynced *internal.ExperimentNotSyncedMsg
 	for _, node := range expNodes {
 		switch {
 		case conditionStatus(node, redskyv1beta1.ExperimentComplete) == corev1.ConditionTrue:
 			return internal.ExperimentFinishedMsg{}
 		case conditionStatus(node, redskyv1beta1.ExperimentFailed) == corev1.ConditionTrue:
 			return internal.ExperimentFinishedMsg{Failed: true}
+		case conditionStatus(node, redskyv1beta1.ExperimentServerSynced) == corev1.ConditionFalse:
+			notSynced = &internal.ExperimentNotSyncedMsg{
+				Reason:  conditionField(node, redskyv1beta1.ExperimentServerSynced, "reason"),
+				Message: conditionField(node, redskyv1beta1.ExperimentServerSynced, "message"),
+			}
 		}
 	}
 
@@ -313,6 +319,12 @@ func (o *Options) refreshTrials() tea.Msg {
 	if err != nil {
 		return fmt.Errorf("could not get trials for status, %w", err)
 	}
+
+	// Keep reporting trial progress while the controller is unable to reach the server
+	if notSynced != nil {
+		notSynced.Trials = trialNodes
+		return *notSynced
+	}
 	return internal.TrialsMsg(trialNodes)
 }
 
@@ -400,3 +412,12 @@ func conditionStatus(n *yaml.RNode, t redskyv1beta1.ExperimentConditionType) cor
 	}
 	return corev1.ConditionUnknown
 }
+
+// conditionField returns a field from an experiment condition given a YAML representation of the experiment.
+func conditionField(n *yaml.RNode, t redskyv1beta1.ExperimentConditionType, field string) string {
+	v, err := n.Pipe(yaml.Lookup("status", "conditions", fmt.Sprintf("[type=%s]", t), field))
+	if err == nil && v != nil {
+		return v.YNode().Value
+	}
+	return ""
+}
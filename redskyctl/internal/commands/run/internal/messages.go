@@ -86,6 +86,14 @@ type ExperimentFinishedMsg struct {
 	Failed bool
 }
 
+// ExperimentNotSyncedMsg indicates that the controller is failing to synchronize
+// the experiment with the server, the current trial list is included.
+type ExperimentNotSyncedMsg struct {
+	Reason  string
+	Message string
+	Trials  TrialsMsg
+}
+
 // TrialsMsg represents the current trial list of the experiment fetched as part
 // of a status update.
 type TrialsMsg []*yaml.RNode
@@ -353,6 +353,7 @@ type runModel struct {
 	completed         bool
 	failed            bool
 	trialFailureCount int
+	notSynced         *internal.ExperimentNotSyncedMsg
 }
 
 func (m runModel) Update(msg tea.Msg) (runModel, tea.Cmd) {
@@ -363,6 +364,11 @@ func (m runModel) Update(msg tea.Msg) (runModel, tea.Cmd) {
 
 	case internal.TrialsMsg:
 		m.trials = kio.ResourceNodeSlice(msg)
+		m.notSynced = nil
+
+	case internal.ExperimentNotSyncedMsg:
+		m.trials = kio.ResourceNodeSlice(msg.Trials)
+		m.notSynced = &msg
 
 	case internal.ExperimentFinishedMsg:
 		m.completed = !msg.Failed
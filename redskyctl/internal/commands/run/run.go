@@ -202,7 +202,7 @@ func (o *Options) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, o.refreshTrialsTick())
 		}
 
-	case internal.TrialsMsg:
+	case internal.TrialsMsg, internal.ExperimentNotSyncedMsg:
 		// If we got a status refresh, initiate another
 		cmds = append(cmds, o.refreshTrialsTick())
 
@@ -391,6 +391,11 @@ func (m previewModel) View() string {
 // View returns the rendering of the run model.
 func (m runModel) View() string {
 	var view out.View
+	if m.notSynced != nil {
+		view.Step(out.NotGood, "The controller is having trouble talking to the server (%s): %s", m.notSynced.Reason, m.notSynced.Message)
+		view.Newline()
+	}
+
 	if m.trials == nil {
 		return view.String()
 	}
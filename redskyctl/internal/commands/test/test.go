@@ -0,0 +1,315 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/internal/experiment"
+	"github.com/thestormforge/optimize-controller/internal/template"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// Options are the configuration options for testing experiment templates
+type Options struct {
+	commander.IOStreams
+
+	Filename     string
+	CaseFilename string
+	Update       bool
+}
+
+// CaseList is the contents of a test case file
+type CaseList struct {
+	// Cases are the individual test cases
+	Cases []Case `json:"cases"`
+}
+
+// Case describes a single trial used to render the experiment templates
+type Case struct {
+	// Name of the test case, used to name the golden file
+	Name string `json:"name"`
+	// Golden is the file containing the expected output, defaults to "testdata/<name>.golden" relative to the case file
+	Golden string `json:"golden,omitempty"`
+	// Trial name used when rendering, defaults to the first trial name of the experiment
+	TrialName string `json:"trialName,omitempty"`
+	// Assignments are the parameter values of the trial
+	Assignments map[string]intstr.IntOrString `json:"assignments,omitempty"`
+	// Values are the metric values of the trial
+	Values map[string]float64 `json:"values,omitempty"`
+	// StartTime of the trial, defaults to the Unix epoch so the output is reproducible
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Duration of the trial, defaults to the approximate runtime of the experiment
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// Result is the rendered output of a test case, it is the contents of the golden file
+type Result struct {
+	// Patches are the rendered patches, in experiment order
+	Patches []PatchResult `json:"patches,omitempty"`
+	// Metrics are the rendered metric queries, in experiment order
+	Metrics []MetricResult `json:"metrics,omitempty"`
+}
+
+// PatchResult is a single rendered patch
+type PatchResult struct {
+	Target string      `json:"target"`
+	Patch  interface{} `json:"patch,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// MetricResult is a single rendered metric query
+type MetricResult struct {
+	Name       string `json:"name"`
+	Query      string `json:"query,omitempty"`
+	ErrorQuery string `json:"errorQuery,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NewCommand creates a command for testing experiment templates
+func NewCommand(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Test experiment templates",
+		Long: "Render experiment patches and metric queries for each test case and compare the output to golden files" +
+			"\n\nRun with --update to (re-)create the golden files from the current output.",
+
+		PreRun: commander.StreamsPreRun(&o.IOStreams),
+		RunE:   commander.WithoutArgsE(o.Test),
+	}
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", "", "`file` containing the experiment definition")
+	cmd.Flags().StringVar(&o.CaseFilename, "case", "", "`file` containing the test cases")
+	cmd.Flags().BoolVar(&o.Update, "update", false, "overwrite the golden files with the rendered output")
+
+	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+	_ = cmd.MarkFlagFilename("case", "yml", "yaml")
+	_ = cmd.MarkFlagRequired("filename")
+	_ = cmd.MarkFlagRequired("case")
+
+	return cmd
+}
+
+// Test renders each test case and compares it to the golden output
+func (o *Options) Test() error {
+	exp, err := o.readExperiment()
+	if err != nil {
+		return err
+	}
+
+	cases, err := o.readCases()
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for i := range cases.Cases {
+		c := &cases.Cases[i]
+		if c.Name == "" {
+			return fmt.Errorf("test case %d is missing a name", i)
+		}
+
+		actual, err := yaml.Marshal(Render(exp, c))
+		if err != nil {
+			return err
+		}
+
+		golden := o.goldenFilename(c)
+		if o.Update {
+			if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(golden, actual, 0644); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(o.Out, "updated %s (%s)\n", c.Name, golden)
+			continue
+		}
+
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			return fmt.Errorf("unable to read golden file for %s, run with --update to create it: %w", c.Name, err)
+		}
+
+		if bytes.Equal(expected, actual) {
+			_, _ = fmt.Fprintf(o.Out, "ok   %s\n", c.Name)
+			continue
+		}
+
+		failed++
+		_, _ = fmt.Fprintf(o.Out, "FAIL %s (%s)\n--- expected\n%s--- actual\n%s", c.Name, golden, expected, actual)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test cases failed", failed, len(cases.Cases))
+	}
+	return nil
+}
+
+// Render produces the patches and metric queries of the experiment for the supplied test case. Rendering errors are
+// recorded in the result so they can be asserted by the golden file.
+func Render(exp *redskyv1beta1.Experiment, c *Case) *Result {
+	t := newTrial(exp, c)
+	te := template.New().WithParameters(exp.Spec.Parameters).WithDerived(exp.Spec.Derived)
+	result := &Result{}
+
+	for i := range exp.Spec.Patches {
+		p := &exp.Spec.Patches[i]
+		pr := PatchResult{Target: fmt.Sprintf("patches[%d]", i)}
+		if p.TargetRef != nil {
+			pr.Target = fmt.Sprintf("%s/%s", p.TargetRef.Kind, p.TargetRef.Name)
+		}
+
+		if data, err := te.RenderPatch(p, t); err != nil {
+			pr.Error = err.Error()
+		} else if err := json.Unmarshal(data, &pr.Patch); err != nil {
+			pr.Error = err.Error()
+		}
+
+		result.Patches = append(result.Patches, pr)
+	}
+
+	for i := range exp.Spec.Metrics {
+		m := &exp.Spec.Metrics[i]
+		mr := MetricResult{Name: m.Name}
+
+		// Dummy out the target object
+		target := &unstructured.Unstructured{}
+		if m.Target != nil {
+			target.SetGroupVersionKind(m.Target.GroupVersionKind())
+		}
+
+		if q, eq, err := te.RenderMetricQueries(m, t, target); err != nil {
+			mr.Error = err.Error()
+		} else {
+			mr.Query, mr.ErrorQuery = q, eq
+		}
+
+		result.Metrics = append(result.Metrics, mr)
+	}
+
+	return result
+}
+
+// newTrial returns a trial for the test case; the trial times are fixed so the rendered output is reproducible
+func newTrial(exp *redskyv1beta1.Experiment, c *Case) *redskyv1beta1.Trial {
+	t := &redskyv1beta1.Trial{}
+	t.Name = c.TrialName
+
+	experiment.PopulateTrialFromTemplate(exp, t)
+	if t.Namespace == "" {
+		t.Namespace = "default"
+	}
+	if t.Name == "" {
+		t.Name = t.GenerateName + "0"
+	}
+
+	for i := range exp.Spec.Parameters {
+		name := exp.Spec.Parameters[i].Name
+		if v, ok := c.Assignments[name]; ok {
+			t.Spec.Assignments = append(t.Spec.Assignments, redskyv1beta1.Assignment{Name: name, Value: v})
+		}
+	}
+
+	for i := range exp.Spec.Metrics {
+		name := exp.Spec.Metrics[i].Name
+		if v, ok := c.Values[name]; ok {
+			t.Spec.Values = append(t.Spec.Values, redskyv1beta1.Value{Name: name, Value: strconv.FormatFloat(v, 'f', -1, 64)})
+		}
+	}
+
+	startTime := metav1.NewTime(time.Unix(0, 0).UTC())
+	if c.StartTime != nil {
+		startTime = *c.StartTime
+	}
+
+	duration := 5 * time.Second
+	if c.Duration != nil {
+		duration = c.Duration.Duration
+	} else if d := exp.Spec.TrialTemplate.Spec.ApproximateRuntime; d != nil {
+		duration = d.Duration
+	}
+
+	completionTime := metav1.NewTime(startTime.Add(duration))
+	t.Status.StartTime = &startTime
+	t.Status.CompletionTime = &completionTime
+
+	return t
+}
+
+func (o *Options) readExperiment() (*redskyv1beta1.Experiment, error) {
+	r, err := o.IOStreams.OpenFile(o.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := &redskyv1beta1.Experiment{}
+	rr := commander.NewResourceReader()
+	if err := rr.ReadInto(r, exp); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+func (o *Options) readCases() (*CaseList, error) {
+	r, err := o.IOStreams.OpenFile(o.CaseFilename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := &CaseList{}
+	if err := yaml.UnmarshalStrict(data, cases); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+func (o *Options) goldenFilename(c *Case) string {
+	if c.Golden != "" && filepath.IsAbs(c.Golden) {
+		return c.Golden
+	}
+
+	// Golden files are relative to the case file (stdin is relative to the current directory)
+	dir := "."
+	if o.CaseFilename != "-" {
+		dir = filepath.Dir(o.CaseFilename)
+	}
+
+	if c.Golden != "" {
+		return filepath.Join(dir, c.Golden)
+	}
+	return filepath.Join(dir, "testdata", c.Name+".golden")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestRender(t *testing.T) {
+	exp := &redskyv1beta1.Experiment{}
+	exp.Name = "example"
+	exp.Spec.Parameters = []redskyv1beta1.Parameter{{Name: "replicas", Min: 1, Max: 5}}
+	exp.Spec.Patches = []redskyv1beta1.PatchTemplate{
+		{
+			TargetRef: &corev1.ObjectReference{Kind: "Deployment", Name: "app"},
+			Patch:     `{"spec":{"replicas":{{ .Values.replicas }}}}`,
+		},
+		{Patch: `{{ .Values.missing.value }}`},
+	}
+	exp.Spec.Metrics = []redskyv1beta1.Metric{
+		{Name: "time", Query: `{{ duration .StartTime .CompletionTime }}`},
+		{Name: "ratio", Query: `{{ index .Metrics "time" }}`},
+	}
+
+	c := &Case{
+		Name:        "three",
+		Assignments: map[string]intstr.IntOrString{"replicas": intstr.FromInt(3)},
+		Values:      map[string]float64{"time": 1.5},
+	}
+
+	r := Render(exp, c)
+	if assert.Len(t, r.Patches, 2) {
+		assert.Equal(t, "Deployment/app", r.Patches[0].Target)
+		assert.Equal(t, map[string]interface{}{"spec": map[string]interface{}{"replicas": float64(3)}}, r.Patches[0].Patch)
+		assert.Empty(t, r.Patches[0].Error)
+		assert.Equal(t, "patches[1]", r.Patches[1].Target)
+		assert.NotEmpty(t, r.Patches[1].Error)
+	}
+	if assert.Len(t, r.Metrics, 2) {
+		assert.Equal(t, "5", r.Metrics[0].Query)
+		assert.Equal(t, "1.5", r.Metrics[1].Query)
+	}
+}
@@ -264,6 +264,17 @@ func WithFS(fs filesys.FileSystem) Option {
 	}
 }
 
+// WithBase sets the directory the kustomization is written to, an empty
+// directory leaves the default.
+func WithBase(base string) Option {
+	return func(k *Kustomize) error {
+		if base != "" {
+			k.Base = base
+		}
+		return nil
+	}
+}
+
 func WithResourceNames(filenames []string) Option {
 	return func(k *Kustomize) (err error) {
 		k.kustomize.Resources = append(k.kustomize.Resources, filenames...)